@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/quest"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
@@ -68,6 +69,8 @@ type ExecutionResult struct {
 	CurrentBlobGasUsed   *math.HexOrDecimal64  `json:"blobGasUsed,omitempty"`
 	RequestsHash         *common.Hash          `json:"requestsHash,omitempty"`
 	Requests             [][]byte              `json:"requests"`
+
+	QuantumRegisters map[common.Address]*quest.RegisterState `json:"quantumRegisters,omitempty"`
 }
 
 type executionResultMarshaling struct {
@@ -425,6 +428,9 @@ func MakePreState(db ethdb.Database, accounts types.GenesisAlloc) *state.StateDB
 		for k, v := range a.Storage {
 			statedb.SetState(addr, k, v)
 		}
+		if len(a.QuantumRegister) > 0 {
+			statedb.SetQuantumRegister(addr, a.QuantumRegister)
+		}
 	}
 	// Commit and re-open to start with a clean state.
 	root, _ := statedb.Commit(0, false, false)
@@ -149,6 +149,14 @@ var (
 			strings.Join(vm.ActivateableEips(), ", ")),
 		Value: "GrayGlacier",
 	}
+	QuestSeedFlag = &cli.StringFlag{
+		Name:  "quest.seed",
+		Usage: "0x-prefixed seed of quantum measurements, replacing the block PREVRANDAO",
+	}
+	QuestDumpStateFlag = &cli.BoolFlag{
+		Name:  "quest.dumpstate",
+		Usage: "Output the decoded quantum registers of the post-state",
+	}
 	VerbosityFlag = &cli.IntFlag{
 		Name:  "verbosity",
 		Usage: "sets the verbosity level",
@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/quest"
 )
 
 var _ = (*executionResultMarshaling)(nil)
@@ -17,21 +18,22 @@ var _ = (*executionResultMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (e ExecutionResult) MarshalJSON() ([]byte, error) {
 	type ExecutionResult struct {
-		StateRoot            common.Hash           `json:"stateRoot"`
-		TxRoot               common.Hash           `json:"txRoot"`
-		ReceiptRoot          common.Hash           `json:"receiptsRoot"`
-		LogsHash             common.Hash           `json:"logsHash"`
-		Bloom                types.Bloom           `json:"logsBloom"        gencodec:"required"`
-		Receipts             types.Receipts        `json:"receipts"`
-		Rejected             []*rejectedTx         `json:"rejected,omitempty"`
-		Difficulty           *math.HexOrDecimal256 `json:"currentDifficulty" gencodec:"required"`
-		GasUsed              math.HexOrDecimal64   `json:"gasUsed"`
-		BaseFee              *math.HexOrDecimal256 `json:"currentBaseFee,omitempty"`
-		WithdrawalsRoot      *common.Hash          `json:"withdrawalsRoot,omitempty"`
-		CurrentExcessBlobGas *math.HexOrDecimal64  `json:"currentExcessBlobGas,omitempty"`
-		CurrentBlobGasUsed   *math.HexOrDecimal64  `json:"blobGasUsed,omitempty"`
-		RequestsHash         *common.Hash          `json:"requestsHash,omitempty"`
-		Requests             []hexutil.Bytes       `json:"requests"`
+		StateRoot            common.Hash                             `json:"stateRoot"`
+		TxRoot               common.Hash                             `json:"txRoot"`
+		ReceiptRoot          common.Hash                             `json:"receiptsRoot"`
+		LogsHash             common.Hash                             `json:"logsHash"`
+		Bloom                types.Bloom                             `json:"logsBloom"        gencodec:"required"`
+		Receipts             types.Receipts                          `json:"receipts"`
+		Rejected             []*rejectedTx                           `json:"rejected,omitempty"`
+		Difficulty           *math.HexOrDecimal256                   `json:"currentDifficulty" gencodec:"required"`
+		GasUsed              math.HexOrDecimal64                     `json:"gasUsed"`
+		BaseFee              *math.HexOrDecimal256                   `json:"currentBaseFee,omitempty"`
+		WithdrawalsRoot      *common.Hash                            `json:"withdrawalsRoot,omitempty"`
+		CurrentExcessBlobGas *math.HexOrDecimal64                    `json:"currentExcessBlobGas,omitempty"`
+		CurrentBlobGasUsed   *math.HexOrDecimal64                    `json:"blobGasUsed,omitempty"`
+		RequestsHash         *common.Hash                            `json:"requestsHash,omitempty"`
+		Requests             []hexutil.Bytes                         `json:"requests"`
+		QuantumRegisters     map[common.Address]*quest.RegisterState `json:"quantumRegisters,omitempty"`
 	}
 	var enc ExecutionResult
 	enc.StateRoot = e.StateRoot
@@ -54,27 +56,29 @@ func (e ExecutionResult) MarshalJSON() ([]byte, error) {
 			enc.Requests[k] = v
 		}
 	}
+	enc.QuantumRegisters = e.QuantumRegisters
 	return json.Marshal(&enc)
 }
 
 // UnmarshalJSON unmarshals from JSON.
 func (e *ExecutionResult) UnmarshalJSON(input []byte) error {
 	type ExecutionResult struct {
-		StateRoot            *common.Hash          `json:"stateRoot"`
-		TxRoot               *common.Hash          `json:"txRoot"`
-		ReceiptRoot          *common.Hash          `json:"receiptsRoot"`
-		LogsHash             *common.Hash          `json:"logsHash"`
-		Bloom                *types.Bloom          `json:"logsBloom"        gencodec:"required"`
-		Receipts             *types.Receipts       `json:"receipts"`
-		Rejected             []*rejectedTx         `json:"rejected,omitempty"`
-		Difficulty           *math.HexOrDecimal256 `json:"currentDifficulty" gencodec:"required"`
-		GasUsed              *math.HexOrDecimal64  `json:"gasUsed"`
-		BaseFee              *math.HexOrDecimal256 `json:"currentBaseFee,omitempty"`
-		WithdrawalsRoot      *common.Hash          `json:"withdrawalsRoot,omitempty"`
-		CurrentExcessBlobGas *math.HexOrDecimal64  `json:"currentExcessBlobGas,omitempty"`
-		CurrentBlobGasUsed   *math.HexOrDecimal64  `json:"blobGasUsed,omitempty"`
-		RequestsHash         *common.Hash          `json:"requestsHash,omitempty"`
-		Requests             []hexutil.Bytes       `json:"requests"`
+		StateRoot            *common.Hash                            `json:"stateRoot"`
+		TxRoot               *common.Hash                            `json:"txRoot"`
+		ReceiptRoot          *common.Hash                            `json:"receiptsRoot"`
+		LogsHash             *common.Hash                            `json:"logsHash"`
+		Bloom                *types.Bloom                            `json:"logsBloom"        gencodec:"required"`
+		Receipts             *types.Receipts                         `json:"receipts"`
+		Rejected             []*rejectedTx                           `json:"rejected,omitempty"`
+		Difficulty           *math.HexOrDecimal256                   `json:"currentDifficulty" gencodec:"required"`
+		GasUsed              *math.HexOrDecimal64                    `json:"gasUsed"`
+		BaseFee              *math.HexOrDecimal256                   `json:"currentBaseFee,omitempty"`
+		WithdrawalsRoot      *common.Hash                            `json:"withdrawalsRoot,omitempty"`
+		CurrentExcessBlobGas *math.HexOrDecimal64                    `json:"currentExcessBlobGas,omitempty"`
+		CurrentBlobGasUsed   *math.HexOrDecimal64                    `json:"blobGasUsed,omitempty"`
+		RequestsHash         *common.Hash                            `json:"requestsHash,omitempty"`
+		Requests             []hexutil.Bytes                         `json:"requests"`
+		QuantumRegisters     map[common.Address]*quest.RegisterState `json:"quantumRegisters,omitempty"`
 	}
 	var dec ExecutionResult
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -130,5 +134,8 @@ func (e *ExecutionResult) UnmarshalJSON(input []byte) error {
 			e.Requests[k] = v
 		}
 	}
+	if dec.QuantumRegisters != nil {
+		e.QuantumRegisters = dec.QuantumRegisters
+	}
 	return nil
 }
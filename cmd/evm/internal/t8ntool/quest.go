@@ -0,0 +1,70 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package t8ntool
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/quest"
+	"github.com/urfave/cli/v2"
+)
+
+// ApplyQuestFlags configures the Quest quantum processor of the VM config
+// from the quest flags. The processor itself is attached by the Quantum fork.
+// If profiling is set, the processor collects the execution times of quantum
+// instructions. The measurement seed is a test setting of the VM config, it
+// is not part of the node's quest config.
+func ApplyQuestFlags(ctx *cli.Context, vmConfig *vm.Config, profiling bool) error {
+	config := ethconfig.Defaults.Quest
+	config.Profiling = profiling
+	if err := config.Sanitize(); err != nil {
+		return NewError(ErrorConfig, err)
+	}
+	if err := config.ApplyVMConfig(vmConfig); err != nil {
+		return err
+	}
+	if ctx.IsSet(QuestSeedFlag.Name) {
+		seed, err := hexutil.Decode(ctx.String(QuestSeedFlag.Name))
+		if err != nil || len(seed) > common.HashLength {
+			return NewError(ErrorConfig, fmt.Errorf("invalid quest seed %q", ctx.String(QuestSeedFlag.Name)))
+		}
+		hash := common.BytesToHash(seed)
+		vmConfig.QuestMeasurementSeed = &hash
+	}
+	return nil
+}
+
+// QuantumRegisters decodes the quantum registers of the accounts in alloc.
+func QuantumRegisters(alloc Alloc) (map[common.Address]*quest.RegisterState, error) {
+	registers := make(map[common.Address]*quest.RegisterState)
+	for addr, account := range alloc {
+		if len(account.QuantumRegister) == 0 {
+			continue
+		}
+		register, err := quest.NewRegisterState(addr, crypto.Keccak256Hash(account.QuantumRegister), account.QuantumRegister)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantum register of %x: %v", addr, err)
+		}
+		registers[addr] = register
+	}
+	return registers, nil
+}
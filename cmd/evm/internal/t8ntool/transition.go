@@ -135,6 +135,11 @@ func Transition(ctx *cli.Context) error {
 	// Set the chain id
 	chainConfig.ChainID = big.NewInt(ctx.Int64(ChainIDFlag.Name))
 
+	// Configure the quantum processor
+	if err := ApplyQuestFlags(ctx, &vmConfig, false); err != nil {
+		return err
+	}
+
 	if txIt, err = loadTransactions(txStr, inputData, chainConfig); err != nil {
 		return err
 	}
@@ -184,6 +189,11 @@ func Transition(ctx *cli.Context) error {
 	// Dump the execution result
 	collector := make(Alloc)
 	s.DumpToCollector(collector, nil)
+	if ctx.Bool(QuestDumpStateFlag.Name) {
+		if result.QuantumRegisters, err = QuantumRegisters(collector); err != nil {
+			return NewError(ErrorEVM, err)
+		}
+	}
 	return dispatchOutput(ctx, baseDir, result, collector, body)
 }
 
@@ -282,10 +292,11 @@ func (g Alloc) OnAccount(addr *common.Address, dumpAccount state.DumpAccount) {
 		}
 	}
 	genesisAccount := types.Account{
-		Code:    dumpAccount.Code,
-		Storage: storage,
-		Balance: balance,
-		Nonce:   dumpAccount.Nonce,
+		Code:            dumpAccount.Code,
+		Storage:         storage,
+		Balance:         balance,
+		Nonce:           dumpAccount.Nonce,
+		QuantumRegister: dumpAccount.QuantumRegister,
 	}
 	g[*addr] = genesisAccount
 }
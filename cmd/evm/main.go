@@ -24,11 +24,13 @@ import (
 	"path/filepath"
 
 	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/internal/debug"
 	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/quest"
 	"github.com/urfave/cli/v2"
 
 	// Force-load the tracer engines to trigger registration
@@ -154,6 +156,8 @@ var (
 			t8ntool.ForknameFlag,
 			t8ntool.ChainIDFlag,
 			t8ntool.RewardFlag,
+			t8ntool.QuestSeedFlag,
+			t8ntool.QuestDumpStateFlag,
 		},
 	}
 	transactionCommand = &cli.Command{
@@ -185,6 +189,12 @@ var (
 	}
 )
 
+// questFlags contains flags that configure the Quest quantum processor.
+var questFlags = []cli.Flag{
+	t8ntool.QuestSeedFlag,
+	t8ntool.QuestDumpStateFlag,
+}
+
 // traceFlags contains flags that configure tracing output.
 var traceFlags = []cli.Flag{
 	TraceFlag,
@@ -286,6 +296,16 @@ func collectFiles(path string) []string {
 	return out
 }
 
+// quantumRegisters returns the decoded quantum registers of the accounts in
+// the most current trie.
+func quantumRegisters(s *state.StateDB) (map[common.Address]*quest.RegisterState, error) {
+	root := s.IntermediateRoot(false)
+	cpy, _ := state.New(root, s.Database())
+	alloc := make(t8ntool.Alloc)
+	cpy.DumpToCollector(alloc, &state.DumpConfig{SkipCode: true})
+	return t8ntool.QuantumRegisters(alloc)
+}
+
 // dump returns a state dump for the most current trie.
 func dump(s *state.StateDB) *state.Dump {
 	root := s.IntermediateRoot(false)
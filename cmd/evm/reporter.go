@@ -22,6 +22,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/quest"
 	"github.com/urfave/cli/v2"
 )
 
@@ -40,6 +41,8 @@ type testResult struct {
 	Error string       `json:"error,omitempty"`
 	State *state.Dump  `json:"state,omitempty"`
 	Stats *execStats   `json:"benchStats,omitempty"`
+
+	QuantumRegisters map[common.Address]*quest.RegisterState `json:"quantumRegisters,omitempty"`
 }
 
 func (r testResult) String() string {
@@ -63,6 +66,10 @@ func (r testResult) String() string {
 		state, _ := json.MarshalIndent(r.State, "", "  ")
 		out += "\n" + string(state)
 	}
+	if r.QuantumRegisters != nil {
+		registers, _ := json.MarshalIndent(r.QuantumRegisters, "", "  ")
+		out += "\n" + string(registers)
+	}
 	return out
 }
 
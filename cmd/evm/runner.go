@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"math/big"
 	"os"
 	goruntime "runtime"
@@ -30,6 +31,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
@@ -41,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm/runtime"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/quest"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/ethereum/go-ethereum/triedb/hashdb"
 	"github.com/urfave/cli/v2"
@@ -66,7 +69,7 @@ var runCommand = &cli.Command{
 		ValueFlag,
 		StatDumpFlag,
 		DumpFlag,
-	}, traceFlags),
+	}, traceFlags, questFlags),
 }
 
 var (
@@ -150,6 +153,31 @@ type execStats struct {
 	Allocs         int64         `json:"allocs"`         // The number of heap allocations during execution.
 	BytesAllocated int64         `json:"bytesAllocated"` // The cumulative number of bytes allocated during execution.
 	GasUsed        uint64        `json:"gasUsed"`        // the amount of gas used during execution
+
+	QuantumOps map[string]quantumOpStats `json:"quantumOps,omitempty"` // Quantum instruction timings, if profiled.
+}
+
+// quantumOpStats contains the timing of a quantum instruction in one execution.
+type quantumOpStats struct {
+	Calls int64         `json:"calls"` // The number of times the instruction was executed.
+	Time  time.Duration `json:"time"`  // The cumulative execution time of the instruction.
+}
+
+// quantumProfile returns the quantum instruction timings recorded by the Quest
+// profiler over the given number of executions, nil if none were recorded.
+func quantumProfile(executions int) map[string]quantumOpStats {
+	profile := quest.Profile()
+	if len(profile) == 0 {
+		return nil
+	}
+	stats := make(map[string]quantumOpStats, len(profile))
+	for name, op := range profile {
+		stats[name] = quantumOpStats{
+			Calls: op.Count / int64(executions),
+			Time:  op.TotalTime / time.Duration(executions),
+		}
+	}
+	return stats
 }
 
 func timedExec(bench bool, execFunc func() ([]byte, uint64, error)) ([]byte, execStats, error) {
@@ -158,6 +186,8 @@ func timedExec(bench bool, execFunc func() ([]byte, uint64, error)) ([]byte, exe
 		// Do one warm-up run
 		output, gasUsed, err := execFunc()
 		result := testing.Benchmark(func(b *testing.B) {
+			// Only profile the quantum instructions of the final round
+			quest.ResetProfile()
 			for i := 0; i < b.N; i++ {
 				haveOutput, haveGasUsed, haveErr := execFunc()
 				if !bytes.Equal(haveOutput, output) {
@@ -178,9 +208,11 @@ func timedExec(bench bool, execFunc func() ([]byte, uint64, error)) ([]byte, exe
 			Allocs:         result.AllocsPerOp(),
 			BytesAllocated: result.AllocedBytesPerOp(),
 			GasUsed:        gasUsed,
+			QuantumOps:     quantumProfile(result.N),
 		}
 		return output, stats, err
 	}
+	quest.ResetProfile()
 	var memStatsBefore, memStatsAfter goruntime.MemStats
 	goruntime.ReadMemStats(&memStatsBefore)
 	t0 := time.Now()
@@ -192,6 +224,7 @@ func timedExec(bench bool, execFunc func() ([]byte, uint64, error)) ([]byte, exe
 		Allocs:         int64(memStatsAfter.Mallocs - memStatsBefore.Mallocs),
 		BytesAllocated: int64(memStatsAfter.TotalAlloc - memStatsBefore.TotalAlloc),
 		GasUsed:        gasUsed,
+		QuantumOps:     quantumProfile(1),
 	}
 	return output, stats, err
 }
@@ -293,6 +326,10 @@ func runCmd(ctx *cli.Context) error {
 	} else {
 		runtimeConfig.ChainConfig = params.AllEthashProtocolChanges
 	}
+	bench := ctx.Bool(BenchFlag.Name)
+	if err := t8ntool.ApplyQuestFlags(ctx, &runtimeConfig.EVMConfig, bench || ctx.Bool(StatDumpFlag.Name)); err != nil {
+		return err
+	}
 
 	var hexInput []byte
 	if inputFileFlag := ctx.String(InputFileFlag.Name); inputFileFlag != "" {
@@ -332,10 +369,9 @@ func runCmd(ctx *cli.Context) error {
 		}
 	}
 
-	bench := ctx.Bool(BenchFlag.Name)
 	output, stats, err := timedExec(bench, execFunc)
 
-	if ctx.Bool(DumpFlag.Name) {
+	if ctx.Bool(DumpFlag.Name) || ctx.Bool(t8ntool.QuestDumpStateFlag.Name) {
 		root, err := runtimeConfig.State.Commit(genesisConfig.Number, true, false)
 		if err != nil {
 			fmt.Printf("Failed to commit changes %v\n", err)
@@ -346,7 +382,18 @@ func runCmd(ctx *cli.Context) error {
 			fmt.Printf("Failed to open statedb %v\n", err)
 			return err
 		}
-		fmt.Println(string(dumpdb.Dump(nil)))
+		if ctx.Bool(DumpFlag.Name) {
+			fmt.Println(string(dumpdb.Dump(nil)))
+		}
+		if ctx.Bool(t8ntool.QuestDumpStateFlag.Name) {
+			registers, err := quantumRegisters(dumpdb)
+			if err != nil {
+				fmt.Printf("Failed to decode quantum registers %v\n", err)
+				return err
+			}
+			out, _ := json.MarshalIndent(registers, "", " ")
+			fmt.Println(string(out))
+		}
 	}
 
 	if ctx.Bool(DebugFlag.Name) {
@@ -362,6 +409,10 @@ execution time:  %v
 allocations:     %d
 allocated bytes: %d
 `, stats.GasUsed, stats.Time, stats.Allocs, stats.BytesAllocated)
+		for _, name := range slices.Sorted(maps.Keys(stats.QuantumOps)) {
+			op := stats.QuantumOps[name]
+			fmt.Fprintf(os.Stderr, "%-17s%v (%d calls)\n", name+":", op.Time, op.Calls)
+		}
 	}
 	if tracer == nil {
 		fmt.Printf("%#x\n", output)
@@ -24,6 +24,7 @@ import (
 	"regexp"
 	"slices"
 
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/t8ntool"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -57,7 +58,7 @@ var stateTestCommand = &cli.Command{
 		HumanReadableFlag,
 		idxFlag,
 		RunFlag,
-	}, traceFlags),
+	}, traceFlags, questFlags),
 }
 
 func stateTestCmd(ctx *cli.Context) error {
@@ -107,6 +108,9 @@ func runStateTest(ctx *cli.Context, fname string) ([]testResult, error) {
 	}
 
 	cfg := vm.Config{Tracer: tracerFromFlags(ctx)}
+	if err := t8ntool.ApplyQuestFlags(ctx, &cfg, ctx.Bool(BenchFlag.Name)); err != nil {
+		return nil, err
+	}
 	re, err := regexp.Compile(ctx.String(RunFlag.Name))
 	if err != nil {
 		return nil, fmt.Errorf("invalid regex -%s: %v", RunFlag.Name, err)
@@ -139,6 +143,13 @@ func runStateTest(ctx *cli.Context, fname string) ([]testResult, error) {
 					if ctx.Bool(DumpFlag.Name) {
 						result.State = dump(state.StateDB)
 					}
+					if ctx.Bool(t8ntool.QuestDumpStateFlag.Name) {
+						registers, err := quantumRegisters(state.StateDB)
+						if err != nil {
+							fmt.Fprintf(os.Stderr, "Failed to decode quantum registers: %v\n", err)
+						}
+						result.QuantumRegisters = registers
+					}
 				}
 				// Collect bench stats if requested.
 				if ctx.Bool(BenchFlag.Name) {
@@ -214,6 +214,11 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 	// Configure log filter RPC API.
 	filterSystem := utils.RegisterFilterAPI(stack, backend, &cfg.Eth)
 
+	// Configure quest RPC API.
+	if eth != nil {
+		utils.RegisterQuestAPI(stack, backend, eth.BlockChain().GetVMConfig())
+	}
+
 	// Configure GraphQL if requested.
 	if ctx.IsSet(utils.GraphQLEnabledFlag.Name) {
 		utils.RegisterGraphQLService(stack, backend, filterSystem, &cfg.Node)
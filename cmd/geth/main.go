@@ -42,6 +42,9 @@ import (
 	_ "github.com/ethereum/go-ethereum/eth/tracers/live"
 	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
 
+	// Force-load the quantum processor to register it with the EVM
+	_ "github.com/ethereum/go-ethereum/quest"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -140,6 +143,9 @@ var (
 		utils.VMEnableDebugFlag,
 		utils.VMTraceFlag,
 		utils.VMTraceJsonConfigFlag,
+		utils.QuestThreadsFlag,
+		utils.QuestProfilingFlag,
+		utils.QuestDeviceFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
 		utils.GpoBlocksFlag,
@@ -248,6 +254,8 @@ func init() {
 		snapshotCommand,
 		// See verkle.go
 		verkleCommand,
+		// See questcmd.go
+		questCommand,
 	}
 	if logTestCommand != nil {
 		app.Commands = append(app.Commands, logTestCommand)
@@ -0,0 +1,121 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/quest/quantum"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	questHistoryFlag = &cli.Uint64Flag{
+		Name:  "history",
+		Usage: "Number of preceding blocks whose register states are included in the snapshot",
+	}
+	questOutputFlag = &cli.StringFlag{
+		Name:  "output",
+		Usage: "Writes the snapshot to the given file instead of printing it as hex",
+	}
+
+	questCommand = &cli.Command{
+		Name:  "quest",
+		Usage: "A set of commands for the quantum registers of contracts",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "dump",
+				Usage:     "Export the quantum register of a contract",
+				ArgsUsage: "<address> [<blockHash> | <blockNum>]",
+				Action:    dumpQuantumRegister,
+				Flags: slices.Concat([]cli.Flag{
+					questHistoryFlag,
+					questOutputFlag,
+				}, utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth quest dump <address> [<blockHash> | <blockNum>]
+This command exports the quantum register of the given contract at the given
+block (or latest, if none provided) as an RLP encoded snapshot. The snapshot
+can be loaded into another node with debug_importQuantumState.
+`,
+			},
+		},
+	}
+)
+
+// dumpQuantumRegister exports the quantum register of a contract from the
+// local database.
+func dumpQuantumRegister(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return fmt.Errorf("expected address and optional block, got %d arguments", ctx.NArg())
+	}
+	if !common.IsHexAddress(ctx.Args().First()) {
+		return fmt.Errorf("invalid address: %q", ctx.Args().First())
+	}
+	address := common.HexToAddress(ctx.Args().First())
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	header, err := readHeaderArg(db, ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	triedb := utils.MakeTrieDatabase(ctx, db, false, true, false)
+	defer triedb.Close()
+
+	sdb := state.NewDatabase(triedb, nil)
+	parent := func(h *types.Header) *types.Header {
+		return rawdb.ReadHeader(db, h.ParentHash, h.Number.Uint64()-1)
+	}
+	registerAt := func(h *types.Header) ([]byte, error) {
+		statedb, err := state.New(h.Root, sdb)
+		if err != nil {
+			return nil, fmt.Errorf("state of block #%d not available: %w", h.Number, err)
+		}
+		return statedb.GetQuantumRegister(address), nil
+	}
+	snap, err := quantum.ExportRegister(address, header, ctx.Uint64(questHistoryFlag.Name), parent, registerAt)
+	if err != nil {
+		return err
+	}
+	blob, err := snap.Encode()
+	if err != nil {
+		return err
+	}
+	if path := ctx.String(questOutputFlag.Name); path != "" {
+		if err := os.WriteFile(path, blob, 0644); err != nil {
+			return err
+		}
+		log.Info("Exported quantum register", "address", address, "number", snap.Number, "hash", snap.Hash, "commitment", snap.Commitment(), "file", path)
+		return nil
+	}
+	fmt.Println(hexutil.Encode(blob))
+	return nil
+}
@@ -71,6 +71,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/quest"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/ethereum/go-ethereum/triedb/hashdb"
@@ -560,6 +561,24 @@ var (
 		Value:    "{}",
 		Category: flags.VMCategory,
 	}
+	// Quest quantum processor settings
+	QuestThreadsFlag = &cli.IntFlag{
+		Name:     "quest.threads",
+		Usage:    "Number of quantum simulation threads (0 = all cores)",
+		Value:    ethconfig.Defaults.Quest.Threads,
+		Category: flags.QuestCategory,
+	}
+	QuestProfilingFlag = &cli.BoolFlag{
+		Name:     "quest.profile",
+		Usage:    "Collect performance profiles of the Quest processor",
+		Category: flags.QuestCategory,
+	}
+	QuestDeviceFlag = &cli.StringFlag{
+		Name:     "quest.device",
+		Usage:    "Quantum simulation device (auto, cpu or a CUDA device index)",
+		Value:    ethconfig.Defaults.Quest.Device,
+		Category: flags.QuestCategory,
+	}
 	// API options.
 	RPCGlobalGasCapFlag = &cli.Uint64Flag{
 		Name:     "rpc.gascap",
@@ -1519,6 +1538,21 @@ func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
 	}
 }
 
+func setQuest(ctx *cli.Context, cfg *ethconfig.QuestConfig) {
+	if ctx.IsSet(QuestThreadsFlag.Name) {
+		cfg.Threads = ctx.Int(QuestThreadsFlag.Name)
+	}
+	if ctx.IsSet(QuestProfilingFlag.Name) {
+		cfg.Profiling = ctx.Bool(QuestProfilingFlag.Name)
+	}
+	if ctx.IsSet(QuestDeviceFlag.Name) {
+		cfg.Device = ctx.String(QuestDeviceFlag.Name)
+	}
+	if err := cfg.Sanitize(); err != nil {
+		Fatalf("Invalid quest configuration: %v", err)
+	}
+}
+
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.Bool(MiningEnabledFlag.Name) {
 		log.Warn("The flag --mine is deprecated and will be removed")
@@ -1581,6 +1615,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setTxPool(ctx, &cfg.TxPool)
 	setBlobPool(ctx, &cfg.BlobPool)
 	setMiner(ctx, &cfg.Miner)
+	setQuest(ctx, &cfg.Quest)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
 
@@ -2010,6 +2045,12 @@ func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconf
 	return filterSystem
 }
 
+// RegisterQuestAPI adds the quest RPC API for inspecting the quantum processor
+// configured by vmConfig to the node.
+func RegisterQuestAPI(stack *node.Node, backend ethapi.Backend, vmConfig *vm.Config) {
+	stack.RegisterAPIs(quest.APIs(backend, vmConfig))
+}
+
 // RegisterFullSyncTester adds the full-sync tester service into node.
 func RegisterFullSyncTester(stack *node.Node, eth *eth.Ethereum, target common.Hash) {
 	catalyst.RegisterFullSyncTester(stack, eth, target)
@@ -2236,6 +2277,11 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 			vmcfg.Tracer = t
 		}
 	}
+	questcfg := ethconfig.Defaults.Quest
+	setQuest(ctx, &questcfg)
+	if err := questcfg.ApplyVMConfig(&vmcfg); err != nil {
+		Fatalf("Invalid quest configuration: %v", err)
+	}
 	// Disable transaction indexing/unindexing by default.
 	chain, err := core.NewBlockChain(chainDb, cache, gspec, nil, engine, vmcfg, nil)
 	if err != nil {
@@ -283,6 +283,11 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	if cacheConfig == nil {
 		cacheConfig = defaultCacheConfig
 	}
+	// Block-STM trains the dependency analyzer on every processed block, the
+	// prefetcher feeds it the access sets of the followup block.
+	if vmConfig.BlockSTM && vmConfig.DependencyAnalyzer == nil {
+		vmConfig.DependencyAnalyzer = vm.NewDependencyAnalyzer(0)
+	}
 	// Open trie database with provided config
 	enableVerkle, err := EnableVerkleAtGenesis(db, genesis)
 	if err != nil {
@@ -298,6 +303,12 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	if err != nil {
 		return nil, err
 	}
+	if err := vm.ValidateProcessor(vmConfig, chainConfig); err != nil {
+		return nil, err
+	}
+	if err := vm.ValidateQuantumPrecompiles(chainConfig); err != nil {
+		return nil, err
+	}
 	log.Info("")
 	log.Info(strings.Repeat("-", 153))
 	for _, line := range strings.Split(chainConfig.Description(), "\n") {
@@ -1873,6 +1884,9 @@ func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, s
 		return nil, err
 	}
 	ptime := time.Since(pstart)
+	if res.ParallelStats != nil {
+		res.ParallelStats.updateMetrics()
+	}
 
 	vstart := time.Now()
 	if err := bc.validator.ValidateState(block, statedb, res, false); err != nil {
@@ -158,6 +158,9 @@ func hashAlloc(ga *types.GenesisAlloc, isVerkle bool) (common.Hash, error) {
 		for key, value := range account.Storage {
 			statedb.SetState(addr, key, value)
 		}
+		if len(account.QuantumRegister) > 0 {
+			statedb.SetQuantumRegister(addr, account.QuantumRegister)
+		}
 	}
 	return statedb.Commit(0, false, false)
 }
@@ -184,6 +187,9 @@ func flushAlloc(ga *types.GenesisAlloc, triedb *triedb.Database) (common.Hash, e
 		for key, value := range account.Storage {
 			statedb.SetState(addr, key, value)
 		}
+		if len(account.QuantumRegister) > 0 {
+			statedb.SetQuantumRegister(addr, account.QuantumRegister)
+		}
 	}
 	root, err := statedb.Commit(0, false, false)
 	if err != nil {
@@ -0,0 +1,129 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// useBlockSTM reports whether the transactions of the block can be executed
+// optimistically in parallel. Block-STM is experimental and disabled by
+// default. It is only used when it is requested by the vm config and the
+// result is indistinguishable from sequential execution: live tracers
+// observe the execution order, pre-Byzantium receipts carry intermediate
+// roots, and witness collection needs every read to hit the block state.
+func (p *StateProcessor) useBlockSTM(block *types.Block, statedb *state.StateDB, cfg vm.Config) bool {
+	return cfg.BlockSTM && cfg.Tracer == nil && len(block.Transactions()) > 1 &&
+		p.config.IsByzantium(block.Number()) && !statedb.GetTrie().IsVerkle() && statedb.Witness() == nil
+}
+
+// processBlockSTM executes the transactions of the block with Block-STM and
+// applies the validated results to statedb in block order. The receipts, logs
+// and post-state are identical to those of sequential execution, including
+// the error returned for an invalid block. The returned statistics describe
+// how well the block parallelised.
+func (p *StateProcessor) processBlockSTM(block *types.Block, statedb *state.StateDB, evm *vm.EVM, cfg vm.Config, signer types.Signer, gp *GasPool, usedGas *uint64) (types.Receipts, []*types.Log, *ParallelStats, error) {
+	var (
+		txs         = block.Transactions()
+		header      = block.Header()
+		blockHash   = block.Hash()
+		blockNumber = block.Number()
+		context     = evm.Context
+		msgs        = make([]*Message, len(txs))
+		msgErrs     = make([]error, len(txs))
+	)
+	// Conversion errors are reported in transaction order below, after the
+	// errors of any preceding transaction.
+	for i, tx := range txs {
+		msgs[i], msgErrs[i] = TransactionToMessage(tx, signer, header.BaseFee)
+	}
+	// The dependency analyzer does not steer Block-STM, its predictions are
+	// compared against the observed dependencies to train and measure it.
+	// Without a configured analyzer the statistics fall back to the static
+	// analysis of the transactions.
+	analyzer := cfg.DependencyAnalyzer
+	if analyzer == nil {
+		analyzer = vm.NewDependencyAnalyzer(0)
+	}
+	predicted := analyzer.Analyze(txs, signer)
+
+	// An incarnation may be re-executed or discarded, so its EVM must not
+	// have side effects outside of the state it is given.
+	incarnation := cfg
+	incarnation.Speculative = true
+
+	results, stmStats := vm.ExecuteBlockSTM(statedb, vm.TxHashes(txs), cfg.ParallelThreads, func(i int, db vm.StateDB) (interface{}, error) {
+		if msgErrs[i] != nil {
+			return nil, msgErrs[i]
+		}
+		// Each transaction is charged against a fresh pool, the block gas
+		// limit is enforced when the results are applied in order.
+		return ApplyMessage(vm.NewEVM(context, db, p.config, incarnation), msgs[i], new(GasPool).AddGas(block.GasLimit()))
+	})
+
+	if cfg.DependencyAnalyzer != nil {
+		learnDependencies(cfg.DependencyAnalyzer, txs, msgs, predicted, results)
+	}
+	var (
+		receipts = make(types.Receipts, 0, len(txs))
+		allLogs  []*types.Log
+		stats    = NewParallelStats(txs, predicted, results, stmStats)
+	)
+	for i, tx := range txs {
+		if err := results[i].Err; err != nil {
+			return nil, nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		msg, result := msgs[i], results[i].Result.(*ExecutionResult)
+		if err := gp.SubGas(msg.GasLimit); err != nil {
+			return nil, nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		gp.AddGas(msg.GasLimit - result.UsedGas)
+
+		statedb.SetTxContext(tx.Hash(), i)
+		results[i].Apply(statedb)
+		*usedGas += result.UsedGas
+
+		evm.SetTxContext(NewEVMTxContext(msg))
+		receipt := MakeReceipt(evm, result, statedb, blockNumber, blockHash, tx, *usedGas, nil)
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	return receipts, allLogs, stats, nil
+}
+
+// learnDependencies reports the accuracy of the predicted dependencies and
+// trains the analyzer on the access sets observed by Block-STM.
+func learnDependencies(analyzer *vm.DependencyAnalyzer, txs types.Transactions, msgs []*Message, predicted map[int][]int, results []*vm.BlockSTMResult) {
+	var (
+		reads  = make([][]vm.StateKey, len(results))
+		writes = make([][]vm.StateKey, len(results))
+	)
+	for i, result := range results {
+		reads[i], writes[i] = result.ReadSet(), result.WriteSet()
+	}
+	vm.ReportConflicts(predicted, vm.ActualDependencies(reads, writes), len(txs))
+
+	for i, tx := range txs {
+		if msgs[i] != nil && results[i].Err == nil {
+			analyzer.Learn(tx, msgs[i].From, reads[i], writes[i])
+		}
+	}
+}
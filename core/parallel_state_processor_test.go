@@ -0,0 +1,214 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// blockSTMCounterCode increments storage slot 0 and logs the new value.
+	blockSTMCounterCode = common.FromHex("600054600101806000556000526020600060a000")
+
+	// blockSTMSuicideInitCode self-destructs the contract being created.
+	blockSTMSuicideInitCode = common.FromHex("33ff")
+)
+
+// blockSTMTestChain generates blocks mixing independent transfers, transfers
+// between a small set of senders, calls to a shared counter contract and
+// contract creations, so that parallel execution hits both disjoint and
+// conflicting transactions.
+func blockSTMTestChain(t *testing.T, blocks, txsPerBlock int) (*Genesis, []*types.Block) {
+	t.Helper()
+
+	var (
+		config  = params.MergedTestChainConfig
+		signer  = types.LatestSigner(config)
+		counter = common.HexToAddress("0xc0")
+		keys    = make([]*ecdsa.PrivateKey, 8)
+		alloc   = types.GenesisAlloc{counter: {Code: blockSTMCounterCode, Balance: big.NewInt(0)}}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = types.Account{Balance: big.NewInt(params.Ether)}
+	}
+	gspec := &Genesis{Config: config, Alloc: alloc}
+	rnd := rand.New(rand.NewSource(1))
+
+	_, chain, _ := GenerateChainWithGenesis(gspec, beacon.New(ethash.NewFaker()), blocks, func(i int, b *BlockGen) {
+		for j := 0; j < txsPerBlock; j++ {
+			key := keys[rnd.Intn(len(keys))]
+			from := crypto.PubkeyToAddress(key.PublicKey)
+			var tx *types.DynamicFeeTx
+			switch rnd.Intn(4) {
+			case 0: // Transfer to a fresh account
+				to := common.BytesToAddress(crypto.Keccak256([]byte{byte(i), byte(j)}))
+				tx = &types.DynamicFeeTx{To: &to, Value: big.NewInt(1000), Gas: params.TxGas}
+			case 1: // Transfer to another sender
+				to := crypto.PubkeyToAddress(keys[rnd.Intn(len(keys))].PublicKey)
+				tx = &types.DynamicFeeTx{To: &to, Value: big.NewInt(int64(rnd.Intn(10000))), Gas: params.TxGas}
+			case 2: // Shared counter
+				tx = &types.DynamicFeeTx{To: &counter, Gas: 100000}
+			case 3: // Contract created and destroyed in the same transaction
+				tx = &types.DynamicFeeTx{Data: blockSTMSuicideInitCode, Gas: 100000}
+			}
+			tx.ChainID = config.ChainID
+			tx.Nonce = b.TxNonce(from)
+			tx.GasTipCap = big.NewInt(int64(1 + rnd.Intn(3)))
+			tx.GasFeeCap = new(big.Int).Add(b.BaseFee(), tx.GasTipCap)
+			b.AddTx(types.MustSignNewTx(key, signer, tx))
+		}
+	})
+	return gspec, chain
+}
+
+// Runs the same blocks through a sequential and a Block-STM processor and
+// checks that the receipts and state roots are identical.
+func TestBlockSTMProcessorMatchesSequential(t *testing.T) {
+	gspec, blocks := blockSTMTestChain(t, 8, 40)
+
+	var (
+		receipts [2][]string
+		roots    [2]common.Hash
+	)
+	for i, cfg := range []vm.Config{{}, {BlockSTM: true, ParallelThreads: 4}} {
+		chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, beacon.New(ethash.NewFaker()), cfg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("config %d: block %d: %v", i, n, err)
+		}
+		for _, block := range blocks {
+			blob, err := json.Marshal(chain.GetReceiptsByHash(block.Hash()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			receipts[i] = append(receipts[i], string(blob))
+		}
+		roots[i] = chain.CurrentBlock().Root
+		chain.Stop()
+	}
+	if roots[0] != roots[1] {
+		t.Fatalf("state root mismatch: sequential %x, block-stm %x", roots[0], roots[1])
+	}
+	for i := range blocks {
+		if receipts[0][i] != receipts[1][i] {
+			t.Errorf("block %d receipts mismatch:\nsequential %s\nblock-stm  %s", i+1, receipts[0][i], receipts[1][i])
+		}
+	}
+}
+
+// Checks that an invalid block is rejected with the same error by both
+// processors.
+func TestBlockSTMProcessorErrors(t *testing.T) {
+	var (
+		config  = params.MergedTestChainConfig
+		signer  = types.LatestSigner(config)
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		from    = crypto.PubkeyToAddress(key.PublicKey)
+		to      = common.HexToAddress("0x01")
+		gspec   = &Genesis{Config: config, Alloc: types.GenesisAlloc{from: {Balance: big.NewInt(params.Ether)}}}
+		gasCap  = big.NewInt(params.InitialBaseFee)
+		makeTxs = func(nonces ...uint64) types.Transactions {
+			var txs types.Transactions
+			for _, nonce := range nonces {
+				txs = append(txs, types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+					ChainID: config.ChainID, Nonce: nonce, To: &to, Gas: params.TxGas, GasFeeCap: gasCap,
+				}))
+			}
+			return txs
+		}
+	)
+	for _, nonces := range [][]uint64{{0, 1, 1}, {0, 2, 1}, {1, 0}} {
+		var errs [2]string
+		for i, cfg := range []vm.Config{{}, {BlockSTM: true, ParallelThreads: 4}} {
+			chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, beacon.New(ethash.NewFaker()), cfg, nil)
+			block := GenerateBadBlock(gspec.ToBlock(), beacon.New(ethash.NewFaker()), makeTxs(nonces...), gspec.Config, false)
+			if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+				errs[i] = err.Error()
+			}
+			chain.Stop()
+		}
+		if errs[0] == "" || errs[0] != errs[1] {
+			t.Errorf("nonces %v: sequential error %q, block-stm error %q", nonces, errs[0], errs[1])
+		}
+	}
+}
+
+// Checks the parallel execution statistics of a block against the shared
+// counter calls it contains.
+func TestBlockSTMProcessorStats(t *testing.T) {
+	gspec, blocks := blockSTMTestChain(t, 2, 40)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, beacon.New(ethash.NewFaker()), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("block %d: %v", n, err)
+	}
+	statedb, err := chain.StateAt(blocks[0].Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := blocks[1]
+	res, err := chain.Processor().Process(block, statedb, vm.Config{BlockSTM: true, ParallelThreads: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := res.ParallelStats
+	if stats == nil {
+		t.Fatal("no parallel statistics")
+	}
+	counter, calls := common.HexToAddress("0xc0"), 0
+	for _, tx := range block.Transactions() {
+		if to := tx.To(); to != nil && *to == counter {
+			calls++
+		}
+	}
+	if stats.Transactions != len(block.Transactions()) || stats.Workers != 4 {
+		t.Errorf("transactions %d, workers %d", stats.Transactions, stats.Workers)
+	}
+	// Every counter call reads the value written by the previous one
+	if stats.CriticalPath < calls || stats.CriticalPath > stats.Transactions {
+		t.Errorf("critical path %d, counter calls %d", stats.CriticalPath, calls)
+	}
+	if have := stats.Conflicts[counter]; have != calls-1 {
+		t.Errorf("counter conflicts: have %d, want %d", have, calls-1)
+	}
+	if stats.CriticalPathGas == 0 || stats.CriticalPathGas > res.GasUsed {
+		t.Errorf("critical path gas %d, block gas %d", stats.CriticalPathGas, res.GasUsed)
+	}
+	if stats.Groups == 0 || stats.Speedup <= 0 || stats.WorkerUtilisation <= 0 {
+		t.Errorf("stats: %+v", stats)
+	}
+}
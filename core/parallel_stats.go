@@ -0,0 +1,136 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	parallelGroupsHist       = metrics.NewRegisteredHistogram("chain/parallel/groups", nil, metrics.NewExpDecaySample(1028, 0.015))
+	parallelCriticalPathHist = metrics.NewRegisteredHistogram("chain/parallel/criticalpath", nil, metrics.NewExpDecaySample(1028, 0.015))
+	parallelReexecMeter      = metrics.NewRegisteredMeter("chain/parallel/reexecutions", nil)
+	parallelConflictMeter    = metrics.NewRegisteredMeter("chain/parallel/conflicts", nil)
+	parallelUtilisationGauge = metrics.NewRegisteredGaugeFloat64("chain/parallel/utilisation", nil)
+	parallelSpeedupGauge     = metrics.NewRegisteredGaugeFloat64("chain/parallel/speedup", nil)
+)
+
+// ParallelStats describes how well the transactions of a block parallelised
+// when they were executed with Block-STM, either by the state processor or by
+// the quest batch processor.
+type ParallelStats struct {
+	Transactions int `json:"transactions"`
+	Workers      int `json:"workers"`
+
+	// Groups is the number of dependency groups the predicted dependencies
+	// split the block into, i.e. the number of rounds a group scheduler needs.
+	Groups int `json:"groups"`
+
+	// CriticalPath is the length of the longest chain of transactions that
+	// actually depended on each other, CriticalPathGas the gas used along the
+	// most expensive such chain. No schedule can beat either.
+	CriticalPath    int    `json:"criticalPath"`
+	CriticalPathGas uint64 `json:"criticalPathGas"`
+
+	// Reexecutions is the number of transaction executions Block-STM
+	// discarded because they read state that a preceding transaction changed.
+	Reexecutions int `json:"reexecutions"`
+
+	// Conflicts counts, per account, the transactions that read an item of
+	// the account written by a preceding transaction of the block.
+	Conflicts map[common.Address]int `json:"conflicts"`
+
+	// WorkerUtilisation is the fraction of time the workers spent executing
+	// and validating transactions.
+	WorkerUtilisation float64 `json:"workerUtilisation"`
+
+	// ParallelTime is the wall time of the parallel execution. SequentialTime
+	// is the time of a sequential execution of the block, estimated from the
+	// execution times of the individual transactions unless it was measured.
+	// Both are in nanoseconds.
+	ParallelTime   time.Duration `json:"parallelTime"`
+	SequentialTime time.Duration `json:"sequentialTime"`
+	Speedup        float64       `json:"speedup"`
+}
+
+// NewParallelStats summarises the Block-STM execution of the transactions of
+// a block. predicted holds their dependencies as predicted before execution.
+func NewParallelStats(txs types.Transactions, predicted map[int][]int, results []*vm.BlockSTMResult, stats *vm.BlockSTMStats) *ParallelStats {
+	var (
+		n      = len(results)
+		reads  = make([][]vm.StateKey, n)
+		writes = make([][]vm.StateKey, n)
+		ps     = &ParallelStats{
+			Transactions:      n,
+			Workers:           stats.Workers,
+			WorkerUtilisation: stats.Utilisation(),
+			ParallelTime:      stats.Elapsed,
+		}
+	)
+	for i, result := range results {
+		reads[i], writes[i] = result.ReadSet(), result.WriteSet()
+		ps.Reexecutions += result.Incarnations - 1
+		ps.SequentialTime += result.Elapsed
+	}
+	ps.Conflicts = vm.ConflictsByAddress(reads, writes)
+	ps.Groups = len(vm.GroupTransactions(txs, predicted))
+
+	// Dependencies always point to preceding transactions, so the longest
+	// chains can be computed in block order.
+	var (
+		deps  = vm.ActualDependencies(reads, writes)
+		depth = make([]int, n)
+		gas   = make([]uint64, n)
+	)
+	for i := 0; i < n; i++ {
+		for _, dep := range deps[i] {
+			depth[i] = max(depth[i], depth[dep])
+			gas[i] = max(gas[i], gas[dep])
+		}
+		depth[i]++
+		if result, ok := results[i].Result.(*ExecutionResult); ok && result != nil {
+			gas[i] += result.UsedGas
+		}
+		ps.CriticalPath = max(ps.CriticalPath, depth[i])
+		ps.CriticalPathGas = max(ps.CriticalPathGas, gas[i])
+	}
+	if ps.ParallelTime > 0 {
+		ps.Speedup = float64(ps.SequentialTime) / float64(ps.ParallelTime)
+	}
+	return ps
+}
+
+// updateMetrics reports the statistics of an imported block to the metrics
+// registry.
+func (ps *ParallelStats) updateMetrics() {
+	parallelGroupsHist.Update(int64(ps.Groups))
+	parallelCriticalPathHist.Update(int64(ps.CriticalPath))
+	parallelReexecMeter.Mark(int64(ps.Reexecutions))
+
+	var conflicts int
+	for _, n := range ps.Conflicts {
+		conflicts += n
+	}
+	parallelConflictMeter.Mark(int64(conflicts))
+	parallelUtilisationGauge.Update(ps.WorkerUtilisation)
+	parallelSpeedupGauge.Update(ps.Speedup)
+}
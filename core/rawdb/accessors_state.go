@@ -99,6 +99,21 @@ func DeleteCode(db ethdb.KeyValueWriter, hash common.Hash) {
 	}
 }
 
+// ReadQuantumRegister retrieves the encoded quantum register of the provided
+// register commitment. Registers are content addressed like contract code and
+// share its table, so that snap sync and state healing retrieve them along
+// with the bytecodes.
+func ReadQuantumRegister(db ethdb.KeyValueReader, hash common.Hash) []byte {
+	return ReadCodeWithPrefix(db, hash)
+}
+
+// WriteQuantumRegister writes the provided encoded quantum register to database.
+func WriteQuantumRegister(db ethdb.KeyValueWriter, hash common.Hash, register []byte) {
+	if err := db.Put(codeKey(hash), register); err != nil {
+		log.Crit("Failed to store quantum register", "err", err)
+	}
+}
+
 // ReadStateID retrieves the state id with the provided state root.
 func ReadStateID(db ethdb.KeyValueReader, root common.Hash) *uint64 {
 	data, err := db.Get(stateIDKey(root))
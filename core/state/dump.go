@@ -49,14 +49,15 @@ type DumpCollector interface {
 
 // DumpAccount represents an account in the state.
 type DumpAccount struct {
-	Balance     string                 `json:"balance"`
-	Nonce       uint64                 `json:"nonce"`
-	Root        hexutil.Bytes          `json:"root"`
-	CodeHash    hexutil.Bytes          `json:"codeHash"`
-	Code        hexutil.Bytes          `json:"code,omitempty"`
-	Storage     map[common.Hash]string `json:"storage,omitempty"`
-	Address     *common.Address        `json:"address,omitempty"` // Address only present in iterative (line-by-line) mode
-	AddressHash hexutil.Bytes          `json:"key,omitempty"`     // If we don't have address, we can output the key
+	Balance         string                 `json:"balance"`
+	Nonce           uint64                 `json:"nonce"`
+	Root            hexutil.Bytes          `json:"root"`
+	CodeHash        hexutil.Bytes          `json:"codeHash"`
+	Code            hexutil.Bytes          `json:"code,omitempty"`
+	Storage         map[common.Hash]string `json:"storage,omitempty"`
+	QuantumRegister hexutil.Bytes          `json:"quantumRegister,omitempty"`
+	Address         *common.Address        `json:"address,omitempty"` // Address only present in iterative (line-by-line) mode
+	AddressHash     hexutil.Bytes          `json:"key,omitempty"`     // If we don't have address, we can output the key
 }
 
 // Dump represents the full dump in a collected format, as one large map.
@@ -91,14 +92,15 @@ type iterativeDump struct {
 // OnAccount implements DumpCollector interface
 func (d iterativeDump) OnAccount(addr *common.Address, account DumpAccount) {
 	dumpAccount := &DumpAccount{
-		Balance:     account.Balance,
-		Nonce:       account.Nonce,
-		Root:        account.Root,
-		CodeHash:    account.CodeHash,
-		Code:        account.Code,
-		Storage:     account.Storage,
-		AddressHash: account.AddressHash,
-		Address:     addr,
+		Balance:         account.Balance,
+		Nonce:           account.Nonce,
+		Root:            account.Root,
+		CodeHash:        account.CodeHash,
+		Code:            account.Code,
+		Storage:         account.Storage,
+		QuantumRegister: account.QuantumRegister,
+		AddressHash:     account.AddressHash,
+		Address:         addr,
 	}
 	d.Encode(dumpAccount)
 }
@@ -184,6 +186,7 @@ func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []
 				}
 				account.Storage[common.BytesToHash(s.trie.GetKey(storageIt.Key))] = common.Bytes2Hex(content)
 			}
+			account.QuantumRegister = obj.QuantumRegister()
 		}
 		c.OnAccount(address, account)
 		accounts++
@@ -40,6 +40,10 @@ type nodeIterator struct {
 	codeHash    common.Hash // Hash of the contract source code
 	code        []byte      // Source code associated with a contract
 
+	registerHash common.Hash // Commitment of the contract's quantum register
+	register     []byte      // Quantum register blob associated with a contract
+	pending      bool        // Whether a register was found in the data trie
+
 	Hash   common.Hash // Hash of the current entry being iterated (nil if not standalone)
 	Parent common.Hash // Hash of the first full ancestor node (nil if current is the root)
 
@@ -90,7 +94,14 @@ func (it *nodeIterator) step() error {
 				return it.dataIt.Error()
 			}
 			it.dataIt = nil
+			return it.loadRegister()
 		}
+		it.checkRegister()
+		return nil
+	}
+	// If we had a quantum register previously, discard that
+	if it.register != nil {
+		it.register = nil
 		return nil
 	}
 	// If we had source code previously, discard that
@@ -131,8 +142,11 @@ func (it *nodeIterator) step() error {
 	if err != nil {
 		return err
 	}
+	it.pending = false
 	if !it.dataIt.Next(true) {
 		it.dataIt = nil
+	} else {
+		it.checkRegister()
 	}
 	if !bytes.Equal(account.CodeHash, types.EmptyCodeHash.Bytes()) {
 		it.codeHash = common.BytesToHash(account.CodeHash)
@@ -148,6 +162,40 @@ func (it *nodeIterator) step() error {
 	return nil
 }
 
+// checkRegister records the quantum register commitment if the data iterator
+// is positioned at the register slot.
+func (it *nodeIterator) checkRegister() {
+	if !it.dataIt.Leaf() {
+		return
+	}
+	if commitment, ok := QuantumRegisterEntry(common.BytesToHash(it.dataIt.LeafKey()), it.dataIt.LeafBlob()); ok {
+		it.registerHash, it.pending = commitment, true
+	}
+}
+
+// loadRegister resolves the quantum register found during the data trie
+// traversal, if any.
+func (it *nodeIterator) loadRegister() error {
+	if !it.pending {
+		return nil
+	}
+	it.pending = false
+
+	preimage := it.state.trie.GetKey(it.stateIt.LeafKey())
+	if preimage == nil {
+		return errors.New("account address is not available")
+	}
+	register, err := it.state.reader.Code(common.BytesToAddress(preimage), it.registerHash)
+	if err != nil {
+		return fmt.Errorf("quantum register %x: %v", it.registerHash, err)
+	}
+	if len(register) == 0 {
+		return fmt.Errorf("quantum register is not found: %x", it.registerHash)
+	}
+	it.register = register
+	return nil
+}
+
 // retrieve pulls and caches the current state entry the iterator is traversing.
 // The method returns whether there are any more data left for inspection.
 func (it *nodeIterator) retrieve() bool {
@@ -165,6 +213,8 @@ func (it *nodeIterator) retrieve() bool {
 		if it.Parent == (common.Hash{}) {
 			it.Parent = it.accountHash
 		}
+	case it.register != nil:
+		it.Hash, it.Parent = it.registerHash, it.accountHash
 	case it.code != nil:
 		it.Hash, it.Parent = it.codeHash, it.accountHash
 	case it.stateIt != nil:
@@ -199,6 +199,14 @@ func (j *journal) setCode(address common.Address, prevCode []byte) {
 	})
 }
 
+func (j *journal) quantumRegisterChange(address common.Address, prevHash common.Hash, prev []byte) {
+	j.append(quantumRegisterChange{
+		account:  address,
+		prevHash: prevHash,
+		prev:     prev,
+	})
+}
+
 func (j *journal) nonceChange(address common.Address, prev uint64) {
 	j.append(nonceChange{
 		account: address,
@@ -262,6 +270,13 @@ type (
 		account  common.Address
 		prevCode []byte
 	}
+	// quantumRegisterChange restores the cached register of an account, the
+	// commitment slot itself is reverted by the accompanying storageChange.
+	quantumRegisterChange struct {
+		account  common.Address
+		prevHash common.Hash
+		prev     []byte
+	}
 
 	// Changes to other state values.
 	refundChange struct {
@@ -395,6 +410,22 @@ func (ch codeChange) copy() journalEntry {
 	}
 }
 
+func (ch quantumRegisterChange) revert(s *StateDB) {
+	s.getStateObject(ch.account).setQuantumRegister(ch.prevHash, ch.prev)
+}
+
+func (ch quantumRegisterChange) dirtied() *common.Address {
+	return &ch.account
+}
+
+func (ch quantumRegisterChange) copy() journalEntry {
+	return quantumRegisterChange{
+		account:  ch.account,
+		prevHash: ch.prevHash,
+		prev:     ch.prev,
+	}
+}
+
 func (ch storageChange) revert(s *StateDB) {
 	s.getStateObject(ch.account).setState(ch.key, ch.prevvalue, ch.origvalue)
 }
@@ -55,6 +55,9 @@ type stateObject struct {
 	trie Trie   // storage trie, which becomes non-nil on first access
 	code []byte // contract bytecode, which gets set when code is loaded
 
+	quantum     []byte      // encoded quantum register, which gets set when the register is loaded
+	quantumHash common.Hash // commitment of the cached quantum register
+
 	originStorage  Storage // Storage entries that have been accessed within the current block
 	dirtyStorage   Storage // Storage entries that have been modified within the current transaction
 	pendingStorage Storage // Storage entries that have been modified within the current block
@@ -71,7 +74,8 @@ type stateObject struct {
 	uncommittedStorage Storage
 
 	// Cache flags.
-	dirtyCode bool // true if the code was updated
+	dirtyCode    bool // true if the code was updated
+	dirtyQuantum bool // true if the quantum register was updated
 
 	// Flag whether the account was marked as self-destructed. The self-destructed
 	// account is still accessible in the scope of same transaction.
@@ -438,6 +442,16 @@ func (s *stateObject) commit() (*accountUpdate, *trienode.NodeSet, error) {
 		}
 		s.dirtyCode = false // reset the dirty flag
 	}
+	// commit the quantum register if it's modified
+	if s.dirtyQuantum {
+		if len(s.quantum) != 0 {
+			op.quantum = &quantumRegister{
+				hash: s.quantumHash,
+				blob: s.quantum,
+			}
+		}
+		s.dirtyQuantum = false
+	}
 	// Commit storage changes and the associated storage trie
 	s.commitStorage(op)
 	if len(op.storages) == 0 {
@@ -486,11 +500,14 @@ func (s *stateObject) deepCopy(db *StateDB) *stateObject {
 		origin:             s.origin,
 		data:               s.data,
 		code:               s.code,
+		quantum:            s.quantum,
+		quantumHash:        s.quantumHash,
 		originStorage:      s.originStorage.Copy(),
 		pendingStorage:     s.pendingStorage.Copy(),
 		dirtyStorage:       s.dirtyStorage.Copy(),
 		uncommittedStorage: s.uncommittedStorage.Copy(),
 		dirtyCode:          s.dirtyCode,
+		dirtyQuantum:       s.dirtyQuantum,
 		selfDestructed:     s.selfDestructed,
 		newContract:        s.newContract,
 	}
@@ -561,6 +578,54 @@ func (s *stateObject) setCode(codeHash common.Hash, code []byte) {
 	s.dirtyCode = true
 }
 
+// QuantumRegister returns the encoded quantum register associated with this
+// object, if any. The register is located by the commitment held in the
+// QuantumRegisterSlot storage slot and is stored alongside the contract code.
+// A slot value without a stored register is ordinary storage, the object has
+// no register then.
+func (s *stateObject) QuantumRegister() []byte {
+	commitment := s.GetState(QuantumRegisterSlot)
+	if commitment == s.quantumHash {
+		return s.quantum
+	}
+	if commitment == (common.Hash{}) {
+		return nil
+	}
+	register, err := s.db.reader.Code(s.address, commitment)
+	if err != nil {
+		s.db.setError(fmt.Errorf("can't load quantum register %x: %v", commitment, err))
+	}
+	if len(register) == 0 {
+		return nil
+	}
+	if s.db.witness != nil {
+		s.db.witness.AddCode(register)
+	}
+	s.quantum, s.quantumHash = register, commitment
+	return register
+}
+
+// SetQuantumRegister replaces the quantum register of the object and updates
+// its commitment in the storage. It returns the previous register, if any.
+func (s *stateObject) SetQuantumRegister(register []byte) (prev []byte) {
+	prev = s.QuantumRegister()
+	s.db.journal.quantumRegisterChange(s.address, s.quantumHash, prev)
+
+	var commitment common.Hash
+	if len(register) != 0 {
+		commitment = crypto.Keccak256Hash(register)
+	}
+	s.setQuantumRegister(commitment, register)
+	s.SetState(QuantumRegisterSlot, commitment)
+	return prev
+}
+
+func (s *stateObject) setQuantumRegister(commitment common.Hash, register []byte) {
+	s.quantum = register
+	s.quantumHash = commitment
+	s.dirtyQuantum = true
+}
+
 func (s *stateObject) SetNonce(nonce uint64) {
 	s.db.journal.nonceChange(s.address, s.data.Nonce)
 	s.setNonce(nonce)
@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/utils"
@@ -45,6 +46,32 @@ import (
 // TriesInMemory represents the number of layers that are kept in RAM.
 const TriesInMemory = 128
 
+// QuantumRegisterSlot is the reserved storage slot holding the commitment of
+// the account's quantum register. The encoded register itself is stored in the
+// contract code table keyed by the commitment. While the account holds a
+// register, the slot is written only by the quantum instructions and SSTORE to
+// it fails. A slot value without a stored register is ordinary storage, e.g.
+// written by a contract deployed before the Quantum fork.
+var QuantumRegisterSlot = crypto.Keccak256Hash([]byte("quest.quantum.register"))
+
+// quantumRegisterKey is the storage trie key of QuantumRegisterSlot.
+var quantumRegisterKey = crypto.Keccak256Hash(QuantumRegisterSlot[:])
+
+// QuantumRegisterEntry reports whether the storage trie entry with the given
+// hashed key and RLP encoded value holds a quantum register commitment, and
+// returns the commitment. State sync uses it to retrieve the registers along
+// with the contract code.
+func QuantumRegisterEntry(key common.Hash, value []byte) (common.Hash, bool) {
+	if key != quantumRegisterKey {
+		return common.Hash{}, false
+	}
+	_, content, _, err := rlp.Split(value)
+	if err != nil || len(content) == 0 {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(content), true
+}
+
 type mutationType int
 
 const (
@@ -338,6 +365,11 @@ func (s *StateDB) TxIndex() int {
 	return s.txIndex
 }
 
+// TxHash returns the current transaction hash set by SetTxContext.
+func (s *StateDB) TxHash() common.Hash {
+	return s.thash
+}
+
 func (s *StateDB) GetCode(addr common.Address) []byte {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
@@ -377,6 +409,16 @@ func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 	return common.Hash{}
 }
 
+// GetQuantumRegister returns the encoded quantum register of the account, nil
+// if the account has none.
+func (s *StateDB) GetQuantumRegister(addr common.Address) []byte {
+	stateObject := s.getStateObject(addr)
+	if stateObject != nil {
+		return stateObject.QuantumRegister()
+	}
+	return nil
+}
+
 // GetCommittedState retrieves the value associated with the specific key
 // without any mutations caused in the current execution.
 func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
@@ -447,6 +489,18 @@ func (s *StateDB) SetCode(addr common.Address, code []byte) (prev []byte) {
 	return nil
 }
 
+// SetQuantumRegister replaces the encoded quantum register of the account and
+// returns the previous one, if any. The register commitment is written to the
+// QuantumRegisterSlot storage slot, so the state root covers the register; an
+// empty register removes it.
+func (s *StateDB) SetQuantumRegister(addr common.Address, register []byte) (prev []byte) {
+	stateObject := s.getOrNewStateObject(addr)
+	if stateObject != nil {
+		return stateObject.SetQuantumRegister(register)
+	}
+	return nil
+}
+
 func (s *StateDB) SetState(addr common.Address, key, value common.Hash) common.Hash {
 	if stateObject := s.getOrNewStateObject(addr); stateObject != nil {
 		return stateObject.SetState(key, value)
@@ -1265,12 +1319,15 @@ func (s *StateDB) commitAndFlush(block uint64, deleteEmptyObjects bool, noStorag
 	if err != nil {
 		return nil, err
 	}
-	// Commit dirty contract code if any exists
-	if db := s.db.TrieDB().Disk(); db != nil && len(ret.codes) > 0 {
+	// Commit dirty contract code and quantum registers if any exists
+	if db := s.db.TrieDB().Disk(); db != nil && len(ret.codes)+len(ret.quantums) > 0 {
 		batch := db.NewBatch()
 		for _, code := range ret.codes {
 			rawdb.WriteCode(batch, code.hash, code.blob)
 		}
+		for _, register := range ret.quantums {
+			rawdb.WriteQuantumRegister(batch, register.hash, register.blob)
+		}
 		if err := batch.Write(); err != nil {
 			return nil, err
 		}
@@ -85,6 +85,10 @@ func (s *hookedStateDB) GetRefund() uint64 {
 	return s.inner.GetRefund()
 }
 
+func (s *hookedStateDB) TxHash() common.Hash {
+	return s.inner.TxHash()
+}
+
 func (s *hookedStateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
 	return s.inner.GetCommittedState(addr, hash)
 }
@@ -93,6 +97,10 @@ func (s *hookedStateDB) GetState(addr common.Address, hash common.Hash) common.H
 	return s.inner.GetState(addr, hash)
 }
 
+func (s *hookedStateDB) GetQuantumRegister(addr common.Address) []byte {
+	return s.inner.GetQuantumRegister(addr)
+}
+
 func (s *hookedStateDB) GetStorageRoot(addr common.Address) common.Hash {
 	return s.inner.GetStorageRoot(addr)
 }
@@ -201,6 +209,17 @@ func (s *hookedStateDB) SetCode(address common.Address, code []byte) []byte {
 	return prev
 }
 
+func (s *hookedStateDB) SetQuantumRegister(address common.Address, register []byte) []byte {
+	prevHash := s.inner.GetState(address, QuantumRegisterSlot)
+	prev := s.inner.SetQuantumRegister(address, register)
+	if s.hooks.OnStorageChange != nil {
+		if hash := s.inner.GetState(address, QuantumRegisterSlot); hash != prevHash {
+			s.hooks.OnStorageChange(address, QuantumRegisterSlot, prevHash, hash)
+		}
+	}
+	return prev
+}
+
 func (s *hookedStateDB) SetState(address common.Address, key common.Hash, value common.Hash) common.Hash {
 	prev := s.inner.SetState(address, key, value)
 	if s.hooks.OnStorageChange != nil && prev != value {
@@ -1368,3 +1368,100 @@ func TestStorageDirtiness(t *testing.T) {
 	state.RevertToSnapshot(snap)
 	checkDirty(common.Hash{0x1}, common.Hash{0x1}, true)
 }
+
+func TestQuantumRegisterJournal(t *testing.T) {
+	var (
+		memdb    = rawdb.NewMemoryDatabase()
+		tdb      = triedb.NewDatabase(memdb, triedb.HashDefaults)
+		state, _ = New(types.EmptyRootHash, NewDatabase(tdb, nil))
+		addr     = common.Address{0x01}
+		first    = []byte{0x01, 0x02}
+		second   = []byte{0x03, 0x04}
+	)
+	state.CreateAccount(addr)
+	state.SetQuantumRegister(addr, first)
+	if got := state.GetState(addr, QuantumRegisterSlot); got != crypto.Keccak256Hash(first) {
+		t.Fatalf("register commitment mismatch: have %x, want %x", got, crypto.Keccak256Hash(first))
+	}
+	// A reverted call frame must not leave its register changes behind
+	id := state.Snapshot()
+	state.SetQuantumRegister(addr, second)
+	if got := state.GetQuantumRegister(addr); !bytes.Equal(got, second) {
+		t.Fatalf("register mismatch: have %x, want %x", got, second)
+	}
+	state.RevertToSnapshot(id)
+	if got := state.GetQuantumRegister(addr); !bytes.Equal(got, first) {
+		t.Fatalf("register not reverted: have %x, want %x", got, first)
+	}
+	if got := state.GetState(addr, QuantumRegisterSlot); got != crypto.Keccak256Hash(first) {
+		t.Fatalf("register commitment not reverted: have %x", got)
+	}
+	// The register must survive a commit and be covered by the state root
+	root, err := state.Commit(0, false, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := tdb.Commit(root, false); err != nil {
+		t.Fatalf("failed to commit state trie: %v", err)
+	}
+	state, err = New(root, NewDatabase(triedb.NewDatabase(memdb, triedb.HashDefaults), nil))
+	if err != nil {
+		t.Fatalf("failed to reopen state: %v", err)
+	}
+	if got := state.GetQuantumRegister(addr); !bytes.Equal(got, first) {
+		t.Fatalf("register not persisted: have %x, want %x", got, first)
+	}
+	state.SetQuantumRegister(addr, nil)
+	if got := state.GetQuantumRegister(addr); got != nil {
+		t.Fatalf("register not cleared: have %x", got)
+	}
+	if other := state.IntermediateRoot(false); other == root {
+		t.Fatal("state root does not cover the quantum register")
+	}
+}
+
+func TestQuantumRegisterMissing(t *testing.T) {
+	var (
+		state, _ = New(types.EmptyRootHash, NewDatabaseForTesting())
+		addr     = common.Address{0x01}
+	)
+	// A slot value without a stored register is ordinary storage, e.g.
+	// written by a contract deployed before the fork
+	state.SetState(addr, QuantumRegisterSlot, common.Hash{0x02})
+	if got := state.GetQuantumRegister(addr); got != nil {
+		t.Fatalf("unexpected register: %x", got)
+	}
+	if err := state.Error(); err != nil {
+		t.Fatalf("storage value reported as missing register: %v", err)
+	}
+	// Acquiring a register replaces the value
+	state.SetQuantumRegister(addr, []byte{0x01})
+	if got := state.GetQuantumRegister(addr); !bytes.Equal(got, []byte{0x01}) {
+		t.Fatalf("register mismatch: have %x", got)
+	}
+}
+
+func TestQuantumRegisterDump(t *testing.T) {
+	var (
+		tdb      = NewDatabase(triedb.NewDatabase(rawdb.NewMemoryDatabase(), &triedb.Config{Preimages: true}), nil)
+		state, _ = New(types.EmptyRootHash, tdb)
+		addr     = common.Address{0x01}
+		register = []byte{0x01, 0x02}
+	)
+	state.SetBalance(addr, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+	state.SetQuantumRegister(addr, register)
+	root, err := state.Commit(0, false, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	state, _ = New(root, tdb)
+
+	dump := state.RawDump(nil)
+	if got := dump.Accounts[addr.String()].QuantumRegister; !bytes.Equal(got, register) {
+		t.Fatalf("dumped register mismatch: have %x, want %x", got, register)
+	}
+	dump = state.RawDump(&DumpConfig{SkipStorage: true})
+	if got := dump.Accounts[addr.String()].QuantumRegister; got != nil {
+		t.Fatalf("register dumped without storage: %x", got)
+	}
+}
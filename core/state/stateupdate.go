@@ -30,6 +30,12 @@ type contractCode struct {
 	blob []byte      // blob is the binary representation of the contract code.
 }
 
+// quantumRegister represents a quantum register committed to an account.
+type quantumRegister struct {
+	hash common.Hash // hash is the register commitment stored in the account storage.
+	blob []byte      // blob is the encoded quantum register.
+}
+
 // accountDelete represents an operation for deleting an Ethereum account.
 type accountDelete struct {
 	address common.Address // address is the unique account identifier
@@ -50,6 +56,7 @@ type accountUpdate struct {
 	data     []byte                 // data is the slim-RLP encoded account data.
 	origin   []byte                 // origin is the original value of account data in slim-RLP encoding.
 	code     *contractCode          // code represents mutated contract code; nil means it's not modified.
+	quantum  *quantumRegister       // quantum represents mutated quantum register; nil means it's not modified.
 	storages map[common.Hash][]byte // storages stores mutated slots in prefix-zero-trimmed RLP format.
 
 	// storagesOriginByKey and storagesOriginByHash both store the original values
@@ -80,8 +87,9 @@ type stateUpdate struct {
 	storagesOrigin map[common.Address]map[common.Hash][]byte
 	rawStorageKey  bool
 
-	codes map[common.Address]contractCode // codes contains the set of dirty codes
-	nodes *trienode.MergedNodeSet         // Aggregated dirty nodes caused by state changes
+	codes    map[common.Address]contractCode    // codes contains the set of dirty codes
+	quantums map[common.Address]quantumRegister // quantums contains the set of dirty quantum registers
+	nodes    *trienode.MergedNodeSet            // Aggregated dirty nodes caused by state changes
 }
 
 // empty returns a flag indicating the state transition is empty or not.
@@ -102,6 +110,7 @@ func newStateUpdate(rawStorageKey bool, originRoot common.Hash, root common.Hash
 		storages       = make(map[common.Hash]map[common.Hash][]byte)
 		storagesOrigin = make(map[common.Address]map[common.Hash][]byte)
 		codes          = make(map[common.Address]contractCode)
+		quantums       = make(map[common.Address]quantumRegister)
 	)
 	// Since some accounts might be destroyed and recreated within the same
 	// block, deletions must be aggregated first.
@@ -125,6 +134,9 @@ func newStateUpdate(rawStorageKey bool, originRoot common.Hash, root common.Hash
 		if op.code != nil {
 			codes[addr] = *op.code
 		}
+		if op.quantum != nil {
+			quantums[addr] = *op.quantum
+		}
 		accounts[addrHash] = op.data
 
 		// Aggregate the account original value. If the account is already
@@ -170,6 +182,7 @@ func newStateUpdate(rawStorageKey bool, originRoot common.Hash, root common.Hash
 		storagesOrigin: storagesOrigin,
 		rawStorageKey:  rawStorageKey,
 		codes:          codes,
+		quantums:       quantums,
 		nodes:          nodes,
 	}
 }
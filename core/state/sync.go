@@ -26,16 +26,24 @@ import (
 
 // NewStateSync creates a new state trie download scheduler.
 func NewStateSync(root common.Hash, database ethdb.KeyValueReader, onLeaf func(keys [][]byte, leaf []byte) error, scheme string) *trie.Sync {
-	// Register the storage slot callback if the external callback is specified.
-	var onSlot func(keys [][]byte, path []byte, leaf []byte, parent common.Hash, parentPath []byte) error
-	if onLeaf != nil {
-		onSlot = func(keys [][]byte, path []byte, leaf []byte, parent common.Hash, parentPath []byte) error {
-			return onLeaf(keys, leaf)
+	// Register the storage slot callback, quantum registers are stored with
+	// the contract code and retrieved the same way.
+	var syncer *trie.Sync
+	onSlot := func(keys [][]byte, path []byte, leaf []byte, parent common.Hash, parentPath []byte) error {
+		if onLeaf != nil {
+			if err := onLeaf(keys, leaf); err != nil {
+				return err
+			}
 		}
+		if len(keys) == 2 {
+			if commitment, ok := QuantumRegisterEntry(common.BytesToHash(keys[1]), leaf); ok {
+				syncer.AddCodeEntry(commitment, path, parent, parentPath)
+			}
+		}
+		return nil
 	}
 	// Register the account callback to connect the state trie and the storage
 	// trie belongs to the contract.
-	var syncer *trie.Sync
 	onAccount := func(keys [][]byte, path []byte, leaf []byte, parent common.Hash, parentPath []byte) error {
 		if onLeaf != nil {
 			if err := onLeaf(keys, leaf); err != nil {
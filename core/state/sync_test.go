@@ -35,10 +35,11 @@ import (
 
 // testAccount is the data associated with an account used by the state tests.
 type testAccount struct {
-	address common.Address
-	balance *uint256.Int
-	nonce   uint64
-	code    []byte
+	address  common.Address
+	balance  *uint256.Int
+	nonce    uint64
+	code     []byte
+	register []byte
 }
 
 // makeTestState create a sample test state to test node-wise reconstruction.
@@ -71,6 +72,10 @@ func makeTestState(scheme string) (ethdb.Database, Database, *triedb.Database, c
 			obj.SetCode(crypto.Keccak256Hash([]byte{i, i, i, i, i}), []byte{i, i, i, i, i})
 			acc.code = []byte{i, i, i, i, i}
 		}
+		if i%7 == 0 {
+			obj.SetQuantumRegister([]byte{i, i, i, 0x51})
+			acc.register = []byte{i, i, i, 0x51}
+		}
 		if i%5 == 0 {
 			for j := byte(0); j < 5; j++ {
 				hash := crypto.Keccak256Hash([]byte{i, i, i, i, i, j, j})
@@ -110,6 +115,9 @@ func checkStateAccounts(t *testing.T, db ethdb.Database, scheme string, root com
 		if code := state.GetCode(acc.address); !bytes.Equal(code, acc.code) {
 			t.Errorf("account %d: code mismatch: have %x, want %x", i, code, acc.code)
 		}
+		if register := state.GetQuantumRegister(acc.address); !bytes.Equal(register, acc.register) {
+			t.Errorf("account %d: quantum register mismatch: have %x, want %x", i, register, acc.register)
+		}
 	}
 }
 
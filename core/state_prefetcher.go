@@ -66,11 +66,23 @@ func (p *statePrefetcher) Prefetch(block *types.Block, statedb *state.StateDB, c
 		}
 		statedb.SetTxContext(tx.Hash(), i)
 
+		// If a dependency analyzer is configured, run the transaction in an
+		// overlay to record its exact access set for parallel execution.
+		var overlay *vm.OverlayStateDB
+		if cfg.DependencyAnalyzer != nil && byzantium {
+			overlay = vm.NewOverlayStateDB(statedb)
+			overlay.SetTxHash(tx.Hash())
+			evm.StateDB = overlay
+		}
 		// We attempt to apply a transaction. The goal is not to execute
 		// the transaction successfully, rather to warm up touched data slots.
 		if _, err := ApplyMessage(evm, msg, gaspool); err != nil {
 			return // Ugh, something went horribly wrong, bail out
 		}
+		if overlay != nil {
+			overlay.Merge(statedb)
+			cfg.DependencyAnalyzer.AddPrerun(tx.Hash(), vm.NewAccessSetFromKeys(overlay.ReadSet(), overlay.WriteSet()))
+		}
 		// If we're pre-byzantium, pre-load trie nodes for the intermediate root
 		if !byzantium {
 			statedb.IntermediateRoot(true)
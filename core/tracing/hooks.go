@@ -57,6 +57,16 @@ type StateDB interface {
 	GetRefund() uint64
 }
 
+// QuantumRegister gives tracers access to the quantum register of a contract.
+type QuantumRegister interface {
+	// NumQubits returns the number of qubits of the register.
+	NumQubits() int
+
+	// StateVector returns the amplitudes of the register state. It returns
+	// nil if the register is too large to be represented as a dense vector.
+	StateVector() []complex128
+}
+
 // VMContext provides the context for the EVM execution.
 type VMContext struct {
 	Coinbase    common.Address
@@ -185,6 +195,28 @@ type (
 
 	// BlockHashReadHook is called when EVM reads the blockhash of a block.
 	BlockHashReadHook = func(blockNumber uint64, hash common.Hash)
+
+	/*
+		- Quantum events -
+	*/
+
+	// QuantumOpHook is invoked after a quantum instruction was applied to the
+	// quantum register of contract `addr`. `op` is the subcode following the
+	// QUANTUM prefix, `qubits` are the qubits the instruction acts on and
+	// `params` its remaining operands, e.g. rotation angles. `register` is the
+	// register after the instruction, nil if the instruction failed or
+	// released the register. It is only valid for the duration of the call.
+	QuantumOpHook = func(addr common.Address, op byte, qubits []uint64, params []uint64, register QuantumRegister, err error)
+
+	// MeasureHook is invoked after the QuantumOpHook of an instruction that
+	// measured `qubits` of the register of contract `addr`. Bit i of `outcome`
+	// is the measured value of qubits[i].
+	MeasureHook = func(addr common.Address, qubits []uint64, outcome uint64)
+
+	// RegisterAllocHook is invoked after the QuantumOpHook of an instruction
+	// that allocated a quantum register of `numQubits` qubits for contract
+	// `addr`, or released it, in which case `numQubits` is zero.
+	RegisterAllocHook = func(addr common.Address, numQubits int)
 )
 
 type Hooks struct {
@@ -215,6 +247,10 @@ type Hooks struct {
 	OnLog           LogHook
 	// Block hash read
 	OnBlockHashRead BlockHashReadHook
+	// Quantum events
+	OnQuantumOp     QuantumOpHook
+	OnMeasure       MeasureHook
+	OnRegisterAlloc RegisterAllocHook
 }
 
 // BalanceChangeReason is used to indicate the reason for a balance change, useful
@@ -57,4 +57,8 @@ type ProcessResult struct {
 	Requests [][]byte
 	Logs     []*types.Log
 	GasUsed  uint64
+
+	// ParallelStats is set when the transactions were executed in parallel
+	// with Block-STM.
+	ParallelStats *ParallelStats
 }
@@ -39,6 +39,10 @@ type Account struct {
 	Balance *big.Int                    `json:"balance" gencodec:"required"`
 	Nonce   uint64                      `json:"nonce,omitempty"`
 
+	// QuantumRegister is the encoded quantum register of the account. Its
+	// commitment is stored in the state.QuantumRegisterSlot storage slot.
+	QuantumRegister []byte `json:"quantumRegister,omitempty"`
+
 	// used in tests
 	PrivateKey []byte `json:"secretKey,omitempty"`
 }
@@ -49,6 +53,8 @@ type accountMarshaling struct {
 	Nonce      math.HexOrDecimal64
 	Storage    map[storageJSON]storageJSON
 	PrivateKey hexutil.Bytes
+
+	QuantumRegister hexutil.Bytes
 }
 
 // storageJSON represents a 256 bit byte array, but allows less than 256 bits when
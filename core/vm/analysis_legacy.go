@@ -116,3 +116,40 @@ func codeBitmapInternal(code, bits bitvec) bitvec {
 	}
 	return bits
 }
+
+// codeBlock - базовый блок кода: линейный участок инструкций [start, end),
+// в который управление входит только в начале и выходит только в конце.
+type codeBlock struct {
+	start, end uint64
+}
+
+// codeBlocks разбивает код на базовые блоки. Блок начинается с нулевой
+// позиции и с каждого JUMPDEST и заканчивается после инструкции перехода или
+// остановки. Аргументы PUSHn пропускаются по битовой карте codeBitmap, поэтому
+// байт 0x5b внутри константы не начинает блок.
+func codeBlocks(code []byte) []codeBlock {
+	var (
+		bits   = codeBitmap(code)
+		blocks []codeBlock
+		start  uint64
+	)
+	for pc := uint64(0); pc < uint64(len(code)); pc++ {
+		if !bits.codeSegment(pc) {
+			continue
+		}
+		switch OpCode(code[pc]) {
+		case JUMPDEST:
+			if pc > start {
+				blocks = append(blocks, codeBlock{start, pc})
+			}
+			start = pc
+		case JUMP, JUMPI, STOP, RETURN, REVERT, INVALID, SELFDESTRUCT:
+			blocks = append(blocks, codeBlock{start, pc + 1})
+			start = pc + 1
+		}
+	}
+	if start < uint64(len(code)) {
+		blocks = append(blocks, codeBlock{start, uint64(len(code))})
+	}
+	return blocks
+}
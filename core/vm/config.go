@@ -32,23 +32,33 @@ type Config struct {
 	ExtraEips               []int     // Дополнительные EIP для активации
 	HasEIP1153              bool      // Активация расширенного хранилища MCOPY
 	
-	// Настройки квантового процессора Quest
-	EnableQuest               bool                  // Включить квантовый процессор Quest
+	// Processor - имя реализации Processor из реестра (RegisterProcessor),
+	// выполняющей кадры вызова. Пустое значение выбирает
+	// DefaultQuantumProcessor после активации форка Quantum и интерпретатор
+	// до нее.
+	Processor string
+
+	// Настройки квантового процессора Quest. Они не влияют на результат
+	// выполнения: размер регистров и стоимость инструкций задаются правилами
+	// форка Quantum.
 	QuestHardwareAcceleration bool                  // Использовать аппаратное ускорение для квантовых вычислений
 	QuestOptions              map[string]string     // Дополнительные настройки для квантового процессора
 	QuestAutodetectHardware   bool                  // Автоматически определять и оптимизировать под оборудование
-	QuestNumQubits            int                   // Количество кубитов (0 = автоопределение)
 	QuestDeltaCompression     bool                  // Использовать дельта-сжатие для оптимизации памяти
 	QuestPreferredDevice      int                   // Предпочтительное CUDA устройство (-1 = автоопределение)
 	QuestDeltaThreshold       float64               // Порог для дельта-сжатия (минимально значимое изменение)
 	QuestForceGPU             bool                  // Принудительно использовать GPU даже если автоопределение не рекомендует
 	QuestForceCPU             bool                  // Принудительно использовать CPU независимо от наличия GPU
+	QuestMeasurementSeed      *common.Hash          // Фиксированное зерно измерений вместо PREVRANDAO блока (nil = PREVRANDAO), только для тестов
 
 	StatelessSelfValidation bool // Generate execution witnesses and self-check against them (testing purpose)
 
 	// Параллельное выполнение транзакций
-	EnableParallelExecution bool // Включает параллельное выполнение операций
-	ParallelThreads         int  // Количество потоков для параллельного выполнения (0 = автоматически)
+	EnableParallelExecution bool                // Включает параллельное выполнение операций
+	ParallelThreads         int                 // Количество потоков для параллельного выполнения (0 = автоматически)
+	BlockSTM                bool                // Выполнять транзакции блока оптимистично параллельно (Block-STM), экспериментально и по умолчанию выключено
+	Speculative             bool                // EVM выполняет инкарнацию Block-STM, результат которой может быть отброшен: процессоры не должны иметь побочных эффектов вне StateDB
+	DependencyAnalyzer      *DependencyAnalyzer // Предсказание зависимостей транзакций, обучается на выполненных блоках (nil = не используется)
 	
 	// Новые настройки для масштабирования TPS
 	HyperParallelMode      bool  // Режим гипер-параллелизма для достижения 1M TPS
@@ -83,15 +93,13 @@ func NewConfig() Config {
 		EnablePreimageRecording: false,
 		ExtraEips:               nil,
 		
-		// Квантовые вычисления включены по умолчанию
-		EnableQuest:               true,
+		// Квантовый процессор подключается форком Quantum
 		QuestHardwareAcceleration: true,
 		QuestAutodetectHardware:   true,
-		QuestNumQubits:            0,  // Автоопределение
 		QuestDeltaCompression:     true,
 		QuestPreferredDevice:      -1, // Автоопределение
 		QuestDeltaThreshold:       1e-6, // Порог для дельта-сжатия
-		QuestForceGPU:             true, // Всегда использовать GPU для максимальной производительности
+		QuestForceGPU:             false,
 		QuestForceCPU:             false,
 		QuestOptions:              make(map[string]string),
 		
@@ -110,12 +118,32 @@ func NewConfig() Config {
 		// Отладка и профилирование
 		QuestDebug:                false,
 		QuestLevelParallelism:     0, // Автоматический выбор
-		QuestProfiling:            true,
-		QuestBenchmarkOnStart:     true, // Проводить тест производительности при старте
+		QuestProfiling:            false,
+		QuestBenchmarkOnStart:     false,
 		
 		QuestHardwareAccelerationOptions: make(map[string]interface{}),
 	}
 }
 
+// InheritQuest копирует настройки квантового процессора из parent. Используется
+// для EVM, которые создаются без трассировщика и отладочных опций цепочки
+// (майнер, eth_call), но должны исполнять квантовые инструкции так же.
+func (c *Config) InheritQuest(parent *Config) {
+	if parent == nil {
+		return
+	}
+	c.Processor = parent.Processor
+	c.QuestHardwareAcceleration = parent.QuestHardwareAcceleration
+	c.QuestAutodetectHardware = parent.QuestAutodetectHardware
+	c.QuestDeltaCompression = parent.QuestDeltaCompression
+	c.QuestPreferredDevice = parent.QuestPreferredDevice
+	c.QuestDeltaThreshold = parent.QuestDeltaThreshold
+	c.QuestForceGPU = parent.QuestForceGPU
+	c.QuestForceCPU = parent.QuestForceCPU
+	c.QuestLevelParallelism = parent.QuestLevelParallelism
+	c.QuestProfiling = parent.QuestProfiling
+	c.QuestMeasurementSeed = parent.QuestMeasurementSeed
+}
+
 // DefaultConfig предоставляет конфигурацию по умолчанию с поддержкой Quest
 var DefaultConfig = NewConfig() 
\ No newline at end of file
@@ -55,11 +55,6 @@ func applyConfigExtensions(config *Config) {
 
 // Методы доступа к расширениям Config
 
-// SetEnableQuest включает или выключает использование квантового процессора Quest
-func (c *Config) SetEnableQuest(enable bool) {
-	c.EnableQuest = enable
-}
-
 // SetQuestOption устанавливает опцию для квантового процессора Quest
 func (c *Config) SetQuestOption(key string, value interface{}) {
 	if c.QuestOptions == nil {
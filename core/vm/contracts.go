@@ -141,7 +141,36 @@ var PrecompiledContractsBLS = PrecompiledContractsPrague
 
 var PrecompiledContractsVerkle = PrecompiledContractsPrague
 
+// PrecompiledContractsQuantum contains the set of pre-compiled Ethereum
+// contracts used in the Quantum release: the Prague set and the quantum
+// simulation contracts.
+var PrecompiledContractsQuantum = PrecompiledContracts{
+	common.BytesToAddress([]byte{0x01}): &ecrecover{},
+	common.BytesToAddress([]byte{0x02}): &sha256hash{},
+	common.BytesToAddress([]byte{0x03}): &ripemd160hash{},
+	common.BytesToAddress([]byte{0x04}): &dataCopy{},
+	common.BytesToAddress([]byte{0x05}): &bigModExp{eip2565: true},
+	common.BytesToAddress([]byte{0x06}): &bn256AddIstanbul{},
+	common.BytesToAddress([]byte{0x07}): &bn256ScalarMulIstanbul{},
+	common.BytesToAddress([]byte{0x08}): &bn256PairingIstanbul{},
+	common.BytesToAddress([]byte{0x09}): &blake2F{},
+	common.BytesToAddress([]byte{0x0a}): &kzgPointEvaluation{},
+	common.BytesToAddress([]byte{0x0b}): &bls12381G1Add{},
+	common.BytesToAddress([]byte{0x0c}): &bls12381G1MultiExp{},
+	common.BytesToAddress([]byte{0x0d}): &bls12381G2Add{},
+	common.BytesToAddress([]byte{0x0e}): &bls12381G2MultiExp{},
+	common.BytesToAddress([]byte{0x0f}): &bls12381Pairing{},
+	common.BytesToAddress([]byte{0x10}): &bls12381MapG1{},
+	common.BytesToAddress([]byte{0x11}): &bls12381MapG2{},
+	QuantumCircuitAddress:               &quantumPrecompile{addr: QuantumCircuitAddress, table: &params.QuantumGasTableQuantum},
+	QuantumQFTAddress:                   &quantumPrecompile{addr: QuantumQFTAddress, table: &params.QuantumGasTableQuantum},
+	QuantumGroverAddress:                &quantumPrecompile{addr: QuantumGroverAddress, table: &params.QuantumGasTableQuantum},
+	QuantumRandomAddress:                &quantumPrecompile{addr: QuantumRandomAddress, table: &params.QuantumGasTableQuantum},
+}
+
 var (
+	PrecompiledAddressesVerkle    []common.Address
+	PrecompiledAddressesQuantum   []common.Address
 	PrecompiledAddressesPrague    []common.Address
 	PrecompiledAddressesCancun    []common.Address
 	PrecompiledAddressesBerlin    []common.Address
@@ -169,12 +198,20 @@ func init() {
 	for k := range PrecompiledContractsPrague {
 		PrecompiledAddressesPrague = append(PrecompiledAddressesPrague, k)
 	}
+	for k := range PrecompiledContractsQuantum {
+		PrecompiledAddressesQuantum = append(PrecompiledAddressesQuantum, k)
+	}
+	for k := range PrecompiledContractsVerkle {
+		PrecompiledAddressesVerkle = append(PrecompiledAddressesVerkle, k)
+	}
 }
 
 func activePrecompiledContracts(rules params.Rules) PrecompiledContracts {
 	switch {
 	case rules.IsVerkle:
 		return PrecompiledContractsVerkle
+	case rules.IsQuantum:
+		return PrecompiledContractsQuantum
 	case rules.IsPrague:
 		return PrecompiledContractsPrague
 	case rules.IsCancun:
@@ -198,6 +235,10 @@ func ActivePrecompiledContracts(rules params.Rules) PrecompiledContracts {
 // ActivePrecompiles returns the precompile addresses enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
 	switch {
+	case rules.IsVerkle:
+		return PrecompiledAddressesVerkle
+	case rules.IsQuantum:
+		return PrecompiledAddressesQuantum
 	case rules.IsPrague:
 		return PrecompiledAddressesPrague
 	case rules.IsCancun:
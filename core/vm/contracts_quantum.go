@@ -0,0 +1,127 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Адреса квантовых предкомпилированных контрактов форка Quantum. Контракты
+// принимают и возвращают данные в кодировке ABI, поэтому вызываются из
+// Solidity обычным staticcall без квантовых инструкций.
+var (
+	QuantumCircuitAddress = common.BytesToAddress([]byte{0x0e, 0x91}) // Симуляция квантовой схемы
+	QuantumQFTAddress     = common.BytesToAddress([]byte{0x0e, 0x92}) // Квантовое преобразование Фурье
+	QuantumGroverAddress  = common.BytesToAddress([]byte{0x0e, 0x93}) // Поиск Гровера
+	QuantumRandomAddress  = common.BytesToAddress([]byte{0x0e, 0x94}) // Детерминированный квантовый маяк случайности
+)
+
+// QuantumPrecompile - реализация квантового предкомпилированного контракта.
+// Симулятор находится вне core/vm (в quest/quantum, который сам зависит от
+// core/vm), поэтому реализации подключаются функцией
+// RegisterQuantumPrecompile из init реализующего пакета. Наличие реализаций
+// проверяется при запуске узла (ValidateQuantumPrecompiles), если в
+// конфигурации цепочки запланирован форк Quantum.
+type QuantumPrecompile interface {
+	// RequiredGas возвращает стоимость вызова по таблице газа форка
+	RequiredGas(table *params.QuantumGasTable, input []byte) uint64
+
+	// Run выполняет контракт. Результат должен зависеть только от входных
+	// данных.
+	Run(input []byte) ([]byte, error)
+}
+
+var (
+	quantumPrecompilesMu sync.RWMutex
+	quantumPrecompiles   = make(map[common.Address]QuantumPrecompile)
+)
+
+// RegisterQuantumPrecompile подключает реализацию квантового
+// предкомпилированного контракта по адресу addr. Вызывается из init
+// реализующего пакета; паникует, если адрес не принадлежит квантовому
+// контракту или реализация уже подключена.
+func RegisterQuantumPrecompile(addr common.Address, impl QuantumPrecompile) {
+	quantumPrecompilesMu.Lock()
+	defer quantumPrecompilesMu.Unlock()
+
+	if impl == nil {
+		panic("vm: RegisterQuantumPrecompile implementation is nil")
+	}
+	if _, ok := PrecompiledContractsQuantum[addr].(*quantumPrecompile); !ok {
+		panic(fmt.Sprintf("vm: %v is not a quantum precompile", addr))
+	}
+	if _, exists := quantumPrecompiles[addr]; exists {
+		panic(fmt.Sprintf("vm: quantum precompile %v registered twice", addr))
+	}
+	quantumPrecompiles[addr] = impl
+}
+
+// ValidateQuantumPrecompiles проверяет, что для всех квантовых
+// предкомпилированных контрактов подключены реализации, если в chainConfig
+// запланирован форк Quantum. Узел без реализаций выполнял бы вызовы этих
+// контрактов иначе, чем сеть, поэтому он не должен запускаться.
+func ValidateQuantumPrecompiles(chainConfig *params.ChainConfig) error {
+	if chainConfig.QuantumTime == nil {
+		return nil
+	}
+	quantumPrecompilesMu.RLock()
+	defer quantumPrecompilesMu.RUnlock()
+
+	for _, addr := range PrecompiledAddressesQuantum {
+		if _, ok := PrecompiledContractsQuantum[addr].(*quantumPrecompile); !ok {
+			continue
+		}
+		if _, ok := quantumPrecompiles[addr]; !ok {
+			return fmt.Errorf("%w: no implementation of quantum precompile %v", ErrQuantumNotAvailable, addr)
+		}
+	}
+	return nil
+}
+
+// quantumPrecompile - квантовый предкомпилированный контракт форка,
+// делегирующий вызовы подключенной реализации.
+type quantumPrecompile struct {
+	addr  common.Address
+	table *params.QuantumGasTable
+}
+
+// impl возвращает подключенную реализацию контракта или nil.
+func (c *quantumPrecompile) impl() QuantumPrecompile {
+	quantumPrecompilesMu.RLock()
+	defer quantumPrecompilesMu.RUnlock()
+
+	return quantumPrecompiles[c.addr]
+}
+
+func (c *quantumPrecompile) RequiredGas(input []byte) uint64 {
+	if impl := c.impl(); impl != nil {
+		return impl.RequiredGas(c.table, input)
+	}
+	return 0
+}
+
+func (c *quantumPrecompile) Run(input []byte) ([]byte, error) {
+	impl := c.impl()
+	if impl == nil {
+		return nil, ErrQuantumNotAvailable
+	}
+	return impl.Run(input)
+}
@@ -19,12 +19,15 @@ package vm
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -393,3 +396,42 @@ func BenchmarkPrecompiledBLS12381G2MultiExpWorstCase(b *testing.B) {
 	}
 	benchmarkPrecompiled("f0f", testcase, b)
 }
+
+// Tests that the active precompile set and address list resolve the forks in
+// the same order as the instruction sets.
+func TestActivePrecompilesForkOrder(t *testing.T) {
+	for _, rules := range []params.Rules{
+		{IsPrague: true},
+		{IsPrague: true, IsQuantum: true},
+		{IsPrague: true, IsVerkle: true},
+		{IsPrague: true, IsQuantum: true, IsVerkle: true},
+	} {
+		contracts := activePrecompiledContracts(rules)
+		addresses := ActivePrecompiles(rules)
+		if len(contracts) != len(addresses) {
+			t.Fatalf("%+v: %d contracts, %d addresses", rules, len(contracts), len(addresses))
+		}
+		for _, addr := range addresses {
+			if _, ok := contracts[addr]; !ok {
+				t.Fatalf("%+v: address %v not in the active contracts", rules, addr)
+			}
+		}
+		quantum := slices.Contains(addresses, QuantumCircuitAddress)
+		if want := rules.IsQuantum && !rules.IsVerkle; quantum != want {
+			t.Errorf("%+v: quantum precompiles active %v, want %v", rules, quantum, want)
+		}
+	}
+}
+
+// Tests that a chain scheduling the Quantum fork is rejected if the quantum
+// precompiles have no implementations, which are not linked into this package.
+func TestValidateQuantumPrecompiles(t *testing.T) {
+	config := *params.TestChainConfig
+	if err := ValidateQuantumPrecompiles(&config); err != nil {
+		t.Fatalf("precompiles required without the Quantum fork: %v", err)
+	}
+	config.QuantumTime = new(uint64)
+	if err := ValidateQuantumPrecompiles(&config); !errors.Is(err, ErrQuantumNotAvailable) {
+		t.Fatalf("want %v, have %v", ErrQuantumNotAvailable, err)
+	}
+}
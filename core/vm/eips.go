@@ -22,6 +22,7 @@ import (
 	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
@@ -710,3 +711,44 @@ func enable7702(jt *JumpTable) {
 	jt[STATICCALL].dynamicGas = gasStaticCallEIP7702
 	jt[DELEGATECALL].dynamicGas = gasDelegateCallEIP7702
 }
+
+// enableQuantum enables the QUANTUM escape opcode. The byte following the
+// opcode selects a quantum instruction from the registry in quantum_opcodes.go,
+// the instruction-specific stack, memory and gas checks are done by opQuantum.
+// SLOAD and SSTORE of the slot holding the quantum register commitment write
+// back the pending register changes first, and SSTORE to the slot fails while
+// the account holds a register.
+func enableQuantum(jt *JumpTable) {
+	jt[QUANTUM] = &operation{
+		execute:     opQuantum,
+		constantGas: GasQuickStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 1),
+	}
+	jt[SLOAD].execute = opSloadQuantum
+	jt[SSTORE].execute = opSstoreQuantum
+}
+
+// opSloadQuantum implements SLOAD. Reading the quantum register commitment slot
+// first writes back the registers changed by the running frame, so that the
+// slot reflects them.
+func opSloadQuantum(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if loc := scope.Stack.peek(); loc.Bytes32() == state.QuantumRegisterSlot {
+		interpreter.evm.flushQuantumRegisters()
+	}
+	return opSload(pc, interpreter, scope)
+}
+
+// opSstoreQuantum implements SSTORE, rejecting writes to the quantum register
+// commitment slot of accounts holding a register. Accounts without a register,
+// including the ones deployed before the fork, use the slot as ordinary
+// storage.
+func opSstoreQuantum(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if loc := scope.Stack.peek(); loc.Bytes32() == state.QuantumRegisterSlot {
+		interpreter.evm.flushQuantumRegisters()
+		if len(interpreter.evm.StateDB.GetQuantumRegister(scope.Contract.Address())) != 0 {
+			return nil, ErrQuantumRegisterSlot
+		}
+	}
+	return opSstore(pc, interpreter, scope)
+}
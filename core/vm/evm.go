@@ -20,7 +20,6 @@ import (
 	"bytes"
 	"errors"
 	"math/big"
-	"runtime"
 	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -31,7 +30,6 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
-	"github.com/ethereum/go-ethereum/quest"
 )
 
 type (
@@ -115,8 +113,12 @@ type EVM struct {
 	// the execution of the tx
 	interpreter *EVMInterpreter
 
-	// questProcessor holds the quantum processor for quantum computing
-	questProcessor quest.QuestProcessor
+	// processor executes call frames on behalf of the interpreter, nil if
+	// no processor is configured
+	processor Processor
+
+	// quantumHandler executes the QUANTUM instructions, nil if not attached
+	quantumHandler QuantumOpHandler
 
 	// abort is used to abort the EVM calling operations
 	abort atomic.Bool
@@ -140,15 +142,6 @@ type EVM struct {
 	shuttingDown atomic.Bool
 }
 
-// Processor это интерфейс для процессоров EVM-совместимых виртуальных машин
-type Processor interface {
-	// Run выполняет контракт с заданными входными данными
-	Run(contract *Contract, input []byte, readOnly bool) (ret []byte, err error)
-	
-	// Close освобождает ресурсы процессора
-	Close() error
-}
-
 // NewEVM создает новый EVM. Отсутствие подтверждения ValidateConfig предполагает, 
 // что конфигурация действительна и может использоваться.
 func NewEVM(blockCtx BlockContext, txCtx TxContext, statedb StateDB, chainConfig *params.ChainConfig, config Config) *EVM {
@@ -159,37 +152,55 @@ func NewEVM(blockCtx BlockContext, txCtx TxContext, statedb StateDB, chainConfig
 		StateDB:      statedb,
 		Config:       config,
 		ChainConfig:  chainConfig,
+		chainRules:   chainConfig.Rules(blockCtx.BlockNumber, blockCtx.Random != nil, blockCtx.Time),
 		topics:       make(map[common.Hash]struct{}),
 		accessedAddresses: make(map[common.Address]struct{}),
 	}
 	// Инициализируем EVM с интерпретатором
+	evm.precompiles = activePrecompiledContracts(evm.chainRules)
 	evm.interpreter = evm.resolveInterpreter(config.Interpreter)
 	
-	// Автоматически инициализируем Quest с 5 кубитами
-	questConfig := &quest.Config{
-		Debug:               false,
-		HardwareAcceleration: true,
-		LevelParallelism:    runtime.NumCPU(),
-		EnableHyperBatch:    true,
-		HyperBatchSize:      quest.OptimalBatchSize,
-		BatchShardCount:     runtime.NumCPU(),
-		Options: map[string]string{
-			"num_qubits": "5", // Фиксируем 5 кубитов
-		},
-	}
-	
-	// Инициализируем квантовый процессор
-	questProcessor, err := quest.NewQuestProcessor(evm, questConfig)
+	// Подключаем процессор, выбранный конфигурацией и правилами форка
+	evm.initProcessor()
+	return evm
+}
+
+// initProcessor создает процессор, выбранный конфигурацией EVM и правилами
+// форка. Процессор, реализующий QuantumOpHandler, также выполняет квантовые
+// инструкции. Если процессор создать не удалось (например, пакет с его
+// реализацией не импортирован), кадры выполняет интерпретатор, а квантовые
+// инструкции завершаются ошибкой ErrQuantumNotAvailable. Цепочка, которой
+// процессор нужен по правилам форка, не создается: ошибку сообщает
+// ValidateProcessor в core.NewBlockChain.
+func (evm *EVM) initProcessor() {
+	processor, err := newProcessor(evm)
 	if err != nil {
-		log.Warn("Не удалось инициализировать квантовый процессор Quest", "error", err)
-		// Даже при ошибке инициализации продолжаем работу без Quest
-	} else {
-		// Устанавливаем Quest как основной процессор
-		evm.processor = questProcessor
-		log.Info("Квантовый процессор Quest активирован", "qubits", 5)
+		log.Debug("EVM processor not available", "name", evm.Config.ProcessorName(evm.chainRules), "err", err)
+		return
+	}
+	if processor == nil {
+		return
+	}
+	evm.processor = processor
+	if handler, ok := processor.(QuantumOpHandler); ok {
+		evm.quantumHandler = handler
+	}
+}
+
+// SetQuantumHandler attaches the executor of the QUANTUM instructions. Without
+// a handler every quantum instruction fails with ErrQuantumNotAvailable.
+func (evm *EVM) SetQuantumHandler(handler QuantumOpHandler) {
+	evm.quantumHandler = handler
+}
+
+// flushQuantumRegisters записывает в состояние регистры, измененные текущим
+// кадром вызова, если обработчик квантовых инструкций накапливает их в
+// памяти. Вызывается перед снимком состояния вложенного кадра и после
+// выполнения кода кадра, до возможного отката.
+func (evm *EVM) flushQuantumRegisters() {
+	if flusher, ok := evm.quantumHandler.(QuantumRegisterFlusher); ok {
+		flusher.FlushQuantumRegisters()
 	}
-	
-	return evm
 }
 
 // SetPrecompiles sets the precompiled contracts for the EVM.
@@ -224,12 +235,11 @@ func (evm *EVM) Interpreter() *EVMInterpreter {
 	return evm.interpreter
 }
 
-// GetVMProcessor returns the appropriate processor for execution
-// Returns quest processor if available and applicable, otherwise returns standard interpreter
+// GetVMProcessor returns the processor executing the contract: the configured
+// processor if any, otherwise the standard interpreter.
 func (evm *EVM) GetVMProcessor(contract *Contract) Processor {
-	// Всегда используем квантовый процессор, если он доступен
-	if evm.questProcessor != nil {
-		return evm.questProcessor
+	if evm.processor != nil && !contract.EVMOnly {
+		return evm.processor
 	}
 	// Только как fallback используем стандартный интерпретатор EVM
 	return evm.interpreter
@@ -239,9 +249,9 @@ func (evm *EVM) GetVMProcessor(contract *Contract) Processor {
 func (evm *EVM) Close() error {
 	evm.shuttingDown.Store(true)
 	
-	// Закрываем квантовый процессор, если он инициализирован
-	if evm.questProcessor != nil {
-		err := evm.questProcessor.Close()
+	// Закрываем процессор, если он инициализирован
+	if evm.processor != nil {
+		err := evm.processor.Close()
 		if err != nil {
 			log.Error("Ошибка при закрытии квантового процессора", "error", err)
 			return err
@@ -275,6 +285,7 @@ func (evm *EVM) Call(caller common.Address, addr common.Address, input []byte, g
 	if value.Sign() != 0 && !evm.Context.CanTransfer(evm.StateDB, caller, value) {
 		return nil, gas, ErrInsufficientBalance
 	}
+	evm.flushQuantumRegisters()
 	snapshot := evm.StateDB.Snapshot()
 	p, isPrecompile := evm.precompile(addr)
 
@@ -343,31 +354,11 @@ func (evm *EVM) Call(caller common.Address, addr common.Address, input []byte, g
 				}
 			}
 			
-			// Получаем подходящий процессор для выполнения
-			processor := evm.GetVMProcessor(contract)
-			
-			// Всегда используем квантовый процессор, если он доступен
-			if evm.questProcessor != nil {
-				// Используем квантовый процессор для всех операций
-				log.Debug("Используем QuEST процессор для всех операций", "address", addr)
-				ret, err = evm.questProcessor.Run(contract, input, false)
-				
-				// Обновляем оставшийся газ
-				leftOverGas = contract.Gas
-				
-				if err != nil {
-					log.Warn("Ошибка выполнения на квантовом процессоре, используем fallback на EVM", "error", err)
-					// Если произошла ошибка, пробуем использовать стандартный интерпретатор
-					evm.StateDB.RevertToSnapshot(snapshot)
-					contract.Gas = gas
-					ret, err = evm.interpreter.Run(contract, input, false)
-					leftOverGas = contract.Gas
-				}
-			} else {
-				// Используем стандартный интерпретатор только если квантовый недоступен
-				ret, err = processor.Run(contract, input, false)
-				leftOverGas = contract.Gas
-			}
+			// Выполняем код процессором, выбранным в конфигурации. Откат к
+			// интерпретатору при ошибке определяется самим процессором.
+			ret, err = evm.GetVMProcessor(contract).Run(contract, input, false)
+			evm.flushQuantumRegisters()
+			leftOverGas = contract.Gas
 		}
 	}
 	// When an error was returned by the EVM or when setting the creation code
@@ -408,6 +399,7 @@ func (evm *EVM) CallCode(caller common.Address, addr common.Address, input []byt
 	if !evm.Context.CanTransfer(evm.StateDB, caller, value) {
 		return nil, gas, ErrInsufficientBalance
 	}
+	evm.flushQuantumRegisters()
 	var snapshot = evm.StateDB.Snapshot()
 
 	// It is allowed to call precompiles, even via delegatecall
@@ -419,6 +411,7 @@ func (evm *EVM) CallCode(caller common.Address, addr common.Address, input []byt
 		contract := NewContract(caller, caller, value, gas, evm.jumpDests)
 		contract.SetCallCode(evm.resolveCodeHash(addr), evm.resolveCode(addr))
 		ret, err = evm.interpreter.Run(contract, input, false)
+		evm.flushQuantumRegisters()
 		gas = contract.Gas
 	}
 	if err != nil {
@@ -451,6 +444,7 @@ func (evm *EVM) DelegateCall(originCaller common.Address, caller common.Address,
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
 	}
+	evm.flushQuantumRegisters()
 	var snapshot = evm.StateDB.Snapshot()
 
 	// It is allowed to call precompiles, even via delegatecall
@@ -463,6 +457,7 @@ func (evm *EVM) DelegateCall(originCaller common.Address, caller common.Address,
 		contract := NewContract(originCaller, caller, value, gas, evm.jumpDests)
 		contract.SetCallCode(evm.resolveCodeHash(addr), evm.resolveCode(addr))
 		ret, err = evm.interpreter.Run(contract, input, false)
+		evm.flushQuantumRegisters()
 		gas = contract.Gas
 	}
 	if err != nil {
@@ -498,6 +493,7 @@ func (evm *EVM) StaticCall(caller common.Address, addr common.Address, input []b
 	// after all empty accounts were deleted, so this is not required. However, if we omit this,
 	// then certain tests start failing; stRevertTest/RevertPrecompiledTouchExactOOG.json.
 	// We could change this, but for now it's left for legacy reasons
+	evm.flushQuantumRegisters()
 	var snapshot = evm.StateDB.Snapshot()
 
 	// We do an AddBalance of zero here, just in order to trigger a touch.
@@ -518,6 +514,7 @@ func (evm *EVM) StaticCall(caller common.Address, addr common.Address, input []b
 		// above we revert to the snapshot and consume any gas remaining. Additionally
 		// when we're in Homestead this also counts for code storage gas errors.
 		ret, err = evm.interpreter.Run(contract, input, true)
+		evm.flushQuantumRegisters()
 		gas = contract.Gas
 	}
 	if err != nil {
@@ -590,6 +587,7 @@ func (evm *EVM) create(caller common.Address, code []byte, gas uint64, value *ui
 	// Create a new account on the state only if the object was not present.
 	// It might be possible the contract code is deployed to a pre-existent
 	// account with non-zero balance.
+	evm.flushQuantumRegisters()
 	snapshot := evm.StateDB.Snapshot()
 	if !evm.StateDB.Exist(address) {
 		evm.StateDB.CreateAccount(address)
@@ -639,6 +637,7 @@ func (evm *EVM) create(caller common.Address, code []byte, gas uint64, value *ui
 // resulting code that is to be deployed, and consumes necessary gas.
 func (evm *EVM) initNewContract(contract *Contract, address common.Address) ([]byte, error) {
 	ret, err := evm.interpreter.Run(contract, nil, false)
+	evm.flushQuantumRegisters()
 	if err != nil {
 		return ret, err
 	}
@@ -715,6 +714,9 @@ func (evm *EVM) resolveCodeHash(addr common.Address) common.Hash {
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
+// Depth returns the current call depth, zero outside of any call frame.
+func (evm *EVM) Depth() int { return evm.depth }
+
 func (evm *EVM) captureBegin(depth int, typ OpCode, from common.Address, to common.Address, input []byte, startGas uint64, value *big.Int) {
 	tracer := evm.Config.Tracer
 	if tracer.OnEnter != nil {
@@ -755,19 +757,10 @@ func (evm *EVM) GetVMContext() *tracing.VMContext {
 	}
 }
 
-// Run выполняет контракт с использованием квантового процессора или стандартного интерпретатора
+// Run выполняет контракт процессором, выбранным в конфигурации, или
+// стандартным интерпретатором
 func (evm *EVM) Run(contract *Contract, input []byte, readOnly bool) (ret []byte, err error) {
-	// Если квантовый процессор включен и доступен, используем его
-	if evm.Config.EnableQuest && evm.Config.QuestTPSBooster && evm.questProcessor != nil && evm.questProcessor.IsAvailable() {
-		// Проверяем, содержит ли код контракта квантовые операции
-		if hasQuantumOps(contract.Code) {
-			// Выполняем контракт с использованием квантового процессора
-			return evm.questProcessor.Run(contract, input, readOnly)
-		}
-	}
-	
-	// Иначе используем стандартный интерпретатор
-	return evm.interpreter.Run(contract, input, readOnly)
+	return evm.GetVMProcessor(contract).Run(contract, input, readOnly)
 }
 
 // GetQuestLogs returns the logs from quantum operations
@@ -780,13 +773,3 @@ func (evm *EVM) AddQuestLog(log *types.Log) {
 	evm.questLogs = append(evm.questLogs, log)
 }
 
-// hasQuantumOps проверяет, содержит ли байткод квантовые опкоды
-func hasQuantumOps(code []byte) bool {
-	for i := 0; i < len(code); i++ {
-		// Квантовые опкоды начинаются с 0xF0-0xFF и 0xE0-0xEF
-		if (code[i] >= 0xF0 && code[i] <= 0xFF) || (code[i] >= 0xE0 && code[i] <= 0xEF) {
-			return true
-		}
-	}
-	return false
-}
@@ -46,10 +46,20 @@ type StateDB interface {
 	SetCode(common.Address, []byte) []byte
 	GetCodeSize(common.Address) int
 
+	// GetQuantumRegister returns the encoded quantum register of the account.
+	GetQuantumRegister(common.Address) []byte
+	// SetQuantumRegister replaces the encoded quantum register of the account,
+	// and returns the previous one, if any. An empty register removes it.
+	SetQuantumRegister(common.Address, []byte) []byte
+
 	AddRefund(uint64)
 	SubRefund(uint64)
 	GetRefund() uint64
 
+	// TxHash returns the hash of the transaction being executed, zero outside
+	// of a transaction.
+	TxHash() common.Hash
+
 	GetCommittedState(common.Address, common.Hash) common.Hash
 	GetState(common.Address, common.Hash) common.Hash
 	SetState(common.Address, common.Hash, common.Hash) common.Hash
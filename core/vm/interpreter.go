@@ -17,7 +17,6 @@
 package vm
 
 import (
-	"fmt"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -113,6 +112,7 @@ type EVMInterpreter struct {
 	mutex       sync.Mutex    // Защита критических секций
 	isParallel  bool          // Режим параллельного выполнения 
 	numThreads  int           // Количество потоков для параллельного выполнения
+	segments    map[common.Hash]map[uint64]*ilpSegment // Участки параллельного выполнения по хешу кода
 	abortFlag   atomic.Bool   // Флаг прерывания выполнения
 }
 
@@ -154,6 +154,8 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	case evm.chainRules.IsVerkle:
 		// TODO replace with proper instruction set when fork is specified
 		table = &verkleInstructionSet
+	case evm.chainRules.IsQuantum:
+		table = &quantumInstructionSet
 	case evm.chainRules.IsPrague:
 		table = &pragueInstructionSet
 	case evm.chainRules.IsCancun:
@@ -206,57 +208,9 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	return interpreter
 }
 
-// Определяет, можно ли безопасно выполнить операцию параллельно
-func (in *EVMInterpreter) canRunParallel(op OpCode) bool {
-	// Операции, которые не должны выполняться параллельно
-	switch op {
-	case JUMP, JUMPI, JUMPDEST, CALL, CALLCODE, 
-		 DELEGATECALL, STATICCALL, CREATE, CREATE2,
-		 RETURN, REVERT, SELFDESTRUCT, STOP:
-		return false
-	}
-	
-	// Проверка на операции с зависимостями от состояния
-	if op >= SLOAD && op <= SELFDESTRUCT {
-		return false // Состояние хранилища не может изменяться параллельно
-	}
-	
-	// По умолчанию считаем, что математические операции можно выполнять параллельно
-	return true
-}
-
-// findIndependentBlocks ищет блоки независимых инструкций для параллельного выполнения
-func (in *EVMInterpreter) findIndependentBlocks(contract *Contract, startPC uint64, maxPC uint64) []struct{start, end uint64} {
-	var blocks []struct{start, end uint64}
-	currentStart := startPC
-	
-	for pc := startPC; pc < maxPC; {
-		op := contract.GetOp(pc)
-		
-		// Если операция нарушает последовательность, завершаем текущий блок
-		if !in.canRunParallel(op) {
-			if pc > currentStart {
-				blocks = append(blocks, struct{start, end uint64}{currentStart, pc})
-			}
-			currentStart = pc + 1
-		}
-		
-		// Увеличиваем счетчик
-		pc++
-		
-		// Учитываем PUSH операции с аргументами
-		if op >= PUSH1 && op <= PUSH32 {
-			n := int(op - PUSH1 + 1)
-			pc += uint64(n)
-		}
-	}
-	
-	// Добавляем последний блок, если он существует
-	if currentStart < maxPC {
-		blocks = append(blocks, struct{start, end uint64}{currentStart, maxPC})
-	}
-	
-	return blocks
+// Close implements Processor. The interpreter holds no resources.
+func (in *EVMInterpreter) Close() error {
+	return nil
 }
 
 // Run loops and evaluates the contract's code with the given input data and returns
@@ -326,10 +280,10 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		}()
 	}
 
-	// Check для параллельного выполнения
-	if in.evm.Config.EnableParallelExecution && in.evm.Config.HyperParallelMode && canRunParallel(contract.Code) {
-		// Параллельное выполнение
-		return in.runParallel(contract, callContext, mem, stack)
+	// Участки чистых стековых инструкций выполняются по графу потока данных
+	var segments map[uint64]*ilpSegment
+	if in.parallelSegments() {
+		segments = in.segmentPlan(contract)
 	}
 
 	// The Interpreter main run loop (contextual). This loop runs until either an
@@ -337,6 +291,10 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 	// the execution of one of the operations or until the done flag is set by the
 	// parent context.
 	for {
+		if seg := segments[pc]; seg != nil && in.runSegment(seg, contract, stack) {
+			pc = seg.end
+			continue
+		}
 		if in.evm.Config.Debug {
 			// Capture pre-execution values for tracing.
 			logged, pcCopy, gasCopy = false, pc, contract.Gas
@@ -410,16 +368,6 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 
 		// execute the operation
 		res, err = operation.execute(&pc, in, callContext)
-		
-		// Проверка на возможность использования квантового процессора
-		if in.evm.Config.EnableQuest && in.evm.Config.QuestTPSBooster && isQuestCompatible(op) {
-			// Попытка ускорить выполнение через квантовый процессор
-			qRes, qErr := in.executeWithQuest(op, callContext, &pc)
-			if qErr == nil {
-				// Успешное выполнение квантовой операции
-				res, err = qRes, nil
-			}
-		}
 
 		// если операция возвращает что-то, мы сохраняем это в виде "результата выполнения EVM"
 		// только возвращенные данные из внутренних вызовов сохраняются в поле returnData
@@ -439,306 +387,3 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		}
 	}
 }
-
-// executeWithQuest выполняет операцию с использованием квантового процессора Quest
-func (in *EVMInterpreter) executeWithQuest(op OpCode, callContext *ScopeContext, pc *uint64) ([]byte, error) {
-	// Используем квантовый процессор для ускорения выполнения операции
-	if in.evm.questProcessor == nil {
-		return nil, fmt.Errorf("квантовый процессор не инициализирован")
-	}
-	
-	// Подготовка входных данных для квантового процессора
-	input := make([]byte, 0)
-	input = append(input, byte(op))
-	
-	// Добавляем данные стека если они есть
-	if callContext.Stack.len() > 0 {
-		stackData := callContext.StackData()
-		for i := 0; i < len(stackData) && i < 10; i++ {
-			bytes := stackData[i].Bytes32()
-			input = append(input, bytes[:]...)
-		}
-	}
-	
-	// Выполняем операцию через квантовый процессор
-	result, err := in.evm.questProcessor.Run(callContext.Contract, input, in.readOnly)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Увеличиваем счетчик инструкций
-	*pc++
-	
-	return result, nil
-}
-
-// runParallel выполняет контракт в параллельном режиме
-func (in *EVMInterpreter) runParallel(contract *Contract, callContext *ScopeContext, mem *Memory, stack *Stack) ([]byte, error) {
-	// Получаем код контракта
-	code := contract.Code
-	
-	// Анализируем код и разбиваем его на независимые блоки
-	blocks := analyzeCodeBlocks(code)
-	
-	// Если блоков слишком мало, используем обычное последовательное выполнение
-	if len(blocks) < 2 {
-		return in.Run(contract, contract.Input, in.readOnly)
-	}
-	
-	// Определяем количество потоков
-	numThreads := in.numThreads
-	if numThreads <= 0 || numThreads > len(blocks) {
-		numThreads = min(runtime.NumCPU(), len(blocks))
-	}
-	
-	// Создаем WaitGroup для синхронизации горутин
-	var wg sync.WaitGroup
-	
-	// Результаты выполнения блоков
-	results := make([][]byte, len(blocks))
-	errors := make([]error, len(blocks))
-	
-	// Канал для распределения задач
-	blockCh := make(chan int, len(blocks))
-	for i := range blocks {
-		blockCh <- i
-	}
-	close(blockCh)
-	
-	// Запускаем воркеры
-	for i := 0; i < numThreads; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			
-			for blockIndex := range blockCh {
-				block := blocks[blockIndex]
-				
-				// Создаем локальный контекст для каждого блока
-				localMem := NewMemory()
-				localMem.Set(0, uint64(len(mem.Data())), mem.Data())
-				
-				localStack := newstack()
-				for j := 0; j < stack.len(); j++ {
-					localStack.Push(stack.data[j])
-				}
-				
-				localContract := &Contract{
-					CallerAddress:   contract.CallerAddress,
-					caller:          contract.caller,
-					self:            contract.self,
-					Gas:             contract.Gas / uint64(len(blocks)),
-					Code:            block.code,
-					Input:           contract.Input,
-					value:           contract.value,
-				}
-				
-				localContext := &ScopeContext{
-					Memory:   localMem,
-					Stack:    localStack,
-					Contract: localContract,
-				}
-				
-				// Выполняем блок кода
-				localPC := block.startPC
-				for localPC < block.endPC {
-					op := localContract.GetOp(localPC)
-					operation := in.table[op]
-					if operation == nil {
-						errors[blockIndex] = &ErrInvalidOpCode{opcode: op}
-						break
-					}
-					
-					// Проверяем стек
-					if sLen := localStack.len(); sLen < operation.minStack {
-						errors[blockIndex] = &ErrStackUnderflow{stackLen: sLen, required: operation.minStack}
-						break
-					} else if sLen > operation.maxStack {
-						errors[blockIndex] = &ErrStackOverflow{stackLen: sLen, limit: operation.maxStack}
-						break
-					}
-					
-					// Выполняем операцию
-					res, err := operation.execute(&localPC, in, localContext)
-					if err != nil {
-						errors[blockIndex] = err
-						break
-					}
-					
-					// Сохраняем результат
-					if operation.returns {
-						results[blockIndex] = res
-					}
-					
-					// Переходим к следующей инструкции
-					if !operation.jumps {
-						localPC++
-					}
-				}
-			}
-		}()
-	}
-	
-	// Ждем завершения всех горутин
-	wg.Wait()
-	
-	// Обрабатываем результаты и ошибки
-	for _, err := range errors {
-		if err != nil {
-			return nil, err
-		}
-	}
-	
-	// Объединяем результаты
-	var result []byte
-	for _, res := range results {
-		if res != nil {
-			result = res
-			break
-		}
-	}
-	
-	return result, nil
-}
-
-// CodeBlock представляет независимый блок кода
-type CodeBlock struct {
-	startPC uint64
-	endPC   uint64
-	code    []byte
-}
-
-// analyzeCodeBlocks анализирует код и разбивает его на независимые блоки
-func analyzeCodeBlocks(code []byte) []CodeBlock {
-	blocks := make([]CodeBlock, 0)
-	
-	// Простая эвристика: разбиваем код по JUMPDEST
-	currentStart := uint64(0)
-	
-	for pc := uint64(0); pc < uint64(len(code)); pc++ {
-		op := OpCode(code[pc])
-		
-		// JUMPDEST указывает на начало нового блока
-		if op == JUMPDEST && pc > currentStart {
-			blocks = append(blocks, CodeBlock{
-				startPC: currentStart,
-				endPC:   pc,
-				code:    code[currentStart:pc],
-			})
-			currentStart = pc
-		}
-		
-		// JUMP или RETURN заканчивает текущий блок
-		if (op == JUMP || op == JUMPI || op == RETURN || op == STOP || op == REVERT) && pc > currentStart {
-			blocks = append(blocks, CodeBlock{
-				startPC: currentStart,
-				endPC:   pc + 1,
-				code:    code[currentStart:pc+1],
-			})
-			currentStart = pc + 1
-		}
-	}
-	
-	// Добавляем последний блок
-	if currentStart < uint64(len(code)) {
-		blocks = append(blocks, CodeBlock{
-			startPC: currentStart,
-			endPC:   uint64(len(code)),
-			code:    code[currentStart:],
-		})
-	}
-	
-	return blocks
-}
-
-// isQuestCompatible проверяет, может ли операция быть ускорена через Quest
-func isQuestCompatible(op OpCode) bool {
-	compatibleOps := map[OpCode]bool{
-		ADD:      true,
-		MUL:      true,
-		SUB:      true,
-		DIV:      true,
-		SDIV:     true,
-		MOD:      true,
-		SMOD:     true,
-		EXP:      true,
-		NOT:      true,
-		LT:       true,
-		GT:       true,
-		SLT:      true,
-		SGT:      true,
-		EQ:       true,
-		AND:      true,
-		OR:       true,
-		XOR:      true,
-		BYTE:     true,
-		SHL:      true,
-		SHR:      true,
-		SAR:      true,
-		SHA3:     true,
-		ADDRESS:  true,
-		BALANCE:  true,
-		ORIGIN:   true,
-		CALLER:   true,
-		CALLVALUE: true,
-		CALLDATALOAD: true,
-		CALLDATASIZE: true,
-		CALLDATACOPY: true,
-		GASPRICE: true,
-		EXTCODESIZE: true,
-		BLOCKHASH: true,
-		TIMESTAMP: true,
-		NUMBER:    true,
-		DIFFICULTY: true,
-		GASLIMIT:  true,
-		CHAINID:   true,
-		SELFBALANCE: true,
-	}
-	
-	return compatibleOps[op]
-}
-
-// canRunParallel проверяет, может ли код быть выполнен параллельно
-func canRunParallel(code []byte) bool {
-	// Аналитическая функция для определения, можно ли выполнить код параллельно
-	// Проверяем на наличие инструкций, которые не могут выполняться параллельно
-	
-	// Инструкции, которые нельзя выполнять параллельно
-	nonParallelOps := map[OpCode]bool{
-		JUMP:     true,
-		JUMPI:    true,
-		JUMPDEST: true,
-		// Инструкции, изменяющие состояние
-		SSTORE:   true,
-		CREATE:   true,
-		CALL:     true,
-		CALLCODE: true,
-		RETURN:   true,
-		DELEGATECALL: true,
-		CREATE2:  true,
-		STATICCALL: true,
-		REVERT:   true,
-		SELFDESTRUCT: true,
-	}
-	
-	for pc := 0; pc < len(code); pc++ {
-		op := OpCode(code[pc])
-		if nonParallelOps[op] {
-			// Нашли инструкцию, которая не может выполняться параллельно
-			return false
-		}
-	}
-	
-	// Дополнительный анализ зависимостей между блоками
-	// Здесь может быть более сложная логика
-	
-	return true
-}
-
-// min возвращает минимальное из двух значений
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
@@ -62,6 +62,7 @@ var (
 	cancunInstructionSet           = newCancunInstructionSet()
 	verkleInstructionSet           = newVerkleInstructionSet()
 	pragueInstructionSet           = newPragueInstructionSet()
+	quantumInstructionSet          = newQuantumInstructionSet()
 	eofInstructionSet              = newEOFInstructionSetForTesting()
 )
 
@@ -102,6 +103,12 @@ func newEOFInstructionSetForTesting() JumpTable {
 	return validate(instructionSet)
 }
 
+func newQuantumInstructionSet() JumpTable {
+	instructionSet := newPragueInstructionSet()
+	enableQuantum(&instructionSet) // QUANTUM escape opcode
+	return validate(instructionSet)
+}
+
 func newPragueInstructionSet() JumpTable {
 	instructionSet := newCancunInstructionSet()
 	enable7702(&instructionSet) // EIP-7702 Setcode transaction type
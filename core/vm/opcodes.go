@@ -230,6 +230,7 @@ const (
 	DUPN           OpCode = 0xe6
 	SWAPN          OpCode = 0xe7
 	EXCHANGE       OpCode = 0xe8
+	QUANTUM        OpCode = 0xe9
 	EOFCREATE      OpCode = 0xec
 	RETURNCONTRACT OpCode = 0xee
 )
@@ -430,6 +431,7 @@ var opCodeToString = [256]string{
 	DUPN:           "DUPN",
 	SWAPN:          "SWAPN",
 	EXCHANGE:       "EXCHANGE",
+	QUANTUM:        "QUANTUM",
 	EOFCREATE:      "EOFCREATE",
 	RETURNCONTRACT: "RETURNCONTRACT",
 
@@ -614,6 +616,7 @@ var stringToOp = map[string]OpCode{
 	"DUPN":            DUPN,
 	"SWAPN":           SWAPN,
 	"EXCHANGE":        EXCHANGE,
+	"QUANTUM":         QUANTUM,
 	"EOFCREATE":       EOFCREATE,
 	"RETURNCONTRACT":  RETURNCONTRACT,
 	"CREATE":          CREATE,
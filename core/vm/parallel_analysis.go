@@ -17,6 +17,8 @@
 package vm
 
 import (
+	"slices"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -66,6 +68,69 @@ func (as *AccessSet) AddAccess(addr common.Address, slot common.Hash, isRead, is
 	record.IsCodeRead = record.IsCodeRead || isCodeRead
 }
 
+// accountFieldSlot возвращает псевдослот поля аккаунта (balance, nonce, code
+// или exist), которым доступ к полю представлен в AccessSet
+func accountFieldSlot(field string, addr common.Address) common.Hash {
+	return crypto.Keccak256Hash([]byte(field), addr.Bytes())
+}
+
+// AddStateKey добавляет доступ к элементу состояния из набора чтения или
+// записи транзакции
+func (as *AccessSet) AddStateKey(key StateKey, isRead, isWrite bool) {
+	switch key.Kind {
+	case StateKeyStorage:
+		as.AddAccess(key.Address, key.Slot, isRead, isWrite, false, false)
+	case StateKeyBalance:
+		as.AddAccess(key.Address, accountFieldSlot("balance", key.Address), isRead, isWrite, false, false)
+	case StateKeyNonce:
+		as.AddAccess(key.Address, accountFieldSlot("nonce", key.Address), isRead, isWrite, false, false)
+	case StateKeyCode:
+		as.AddAccess(key.Address, accountFieldSlot("code", key.Address), isRead, isWrite, false, isRead)
+	case StateKeyExist, StateKeyDestruct:
+		as.AddAccess(key.Address, accountFieldSlot("exist", key.Address), isRead, isWrite, false, false)
+	}
+}
+
+// NewAccessSetFromKeys строит AccessSet по точным наборам чтения и записи
+// выполненной транзакции. Записи без чтения - прибавки к балансу, например
+// комиссия получателю блока - перестановочны и конфликтов не создают,
+// поэтому не учитываются.
+func NewAccessSetFromKeys(reads, writes []StateKey) *AccessSet {
+	as := NewAccessSet()
+	read := make(map[StateKey]struct{}, len(reads))
+	for _, key := range reads {
+		read[key] = struct{}{}
+		as.AddStateKey(key, true, false)
+	}
+	for _, key := range writes {
+		if _, ok := read[key]; ok {
+			as.AddStateKey(key, false, true)
+		}
+	}
+	return as
+}
+
+// AddAccessList добавляет доступы из списка доступа EIP-2930. Список не
+// говорит, изменяется ли слот, поэтому слоты считаются и читаемыми, и
+// записываемыми.
+func (as *AccessSet) AddAccessList(list types.AccessList) {
+	for _, tuple := range list {
+		as.AddAccess(tuple.Address, accountFieldSlot("exist", tuple.Address), true, false, false, false)
+		for _, slot := range tuple.StorageKeys {
+			as.AddAccess(tuple.Address, slot, true, true, false, false)
+		}
+	}
+}
+
+// Merge добавляет доступы другого набора
+func (as *AccessSet) Merge(other *AccessSet) {
+	for _, slots := range other.Records {
+		for _, r := range slots {
+			as.AddAccess(r.Address, r.SlotKey, r.IsRead, r.IsWrite, r.IsCreate, r.IsCodeRead)
+		}
+	}
+}
+
 // AnalyzeTransactions выполняет статический анализ транзакций для определения зависимостей
 func AnalyzeTransactions(txs []*types.Transaction, chainConfig *params.ChainConfig, blockNumber *uint64) (map[int][]int, error) {
 	accessSets := make([]*AccessSet, len(txs))
@@ -75,20 +140,7 @@ func AnalyzeTransactions(txs []*types.Transaction, chainConfig *params.ChainConf
 		accessSets[i] = AnalyzeTransaction(tx, chainConfig, blockNumber)
 	}
 	
-	// Строим граф зависимостей
-	dependencies := make(map[int][]int)
-	for i := 0; i < len(txs); i++ {
-		dependencies[i] = []int{}
-		
-		// Проверяем все предыдущие транзакции
-		for j := 0; j < i; j++ {
-			if HasDependency(accessSets[i], accessSets[j]) {
-				dependencies[i] = append(dependencies[i], j)
-			}
-		}
-	}
-	
-	return dependencies, nil
+	return dependenciesOf(accessSets), nil
 }
 
 // AnalyzeTransaction анализирует отдельную транзакцию и пытается определить, к каким данным
@@ -104,61 +156,74 @@ func AnalyzeTransaction(tx *types.Transaction, chainConfig *params.ChainConfig,
 		return accessSet
 	}
 	
-	// Добавляем доступ к балансу и nonce отправителя
-	balanceSlot := crypto.Keccak256Hash([]byte("balance"), sender.Bytes())
-	nonceSlot := crypto.Keccak256Hash([]byte("nonce"), sender.Bytes())
-	accessSet.AddAccess(sender, balanceSlot, true, true, false, false)  // Баланс: чтение и запись
-	accessSet.AddAccess(sender, nonceSlot, true, true, false, false)    // Nonce: чтение и запись
-	
-	// Если есть получатель, добавляем доступ к его балансу
-	if tx.To() != nil {
-		recipientBalanceSlot := crypto.Keccak256Hash([]byte("balance"), tx.To().Bytes())
-		accessSet.AddAccess(*tx.To(), recipientBalanceSlot, true, true, false, false)
-		
-		// Если это вызов контракта, добавляем доступ к коду
-		codeSlot := crypto.Keccak256Hash([]byte("code"), tx.To().Bytes())
-		accessSet.AddAccess(*tx.To(), codeSlot, true, false, false, true)
-		
-		// Для контрактов также можно проводить дополнительный статический анализ
-		// данных транзакции, чтобы определить, какие слоты хранилища могут быть затронуты
-		AnalyzeCallData(tx.Data(), accessSet, *tx.To())
+	return analyzeTransaction(tx, sender)
+}
+
+// analyzeTransaction предсказывает доступы транзакции отправителя sender по
+// ее полям, списку доступа и данным вызова
+func analyzeTransaction(tx *types.Transaction, sender common.Address) *AccessSet {
+	accessSet := NewAccessSet()
+
+	// Баланс и nonce отправителя: чтение и запись
+	accessSet.AddAccess(sender, accountFieldSlot("balance", sender), true, true, false, false)
+	accessSet.AddAccess(sender, accountFieldSlot("nonce", sender), true, true, false, false)
+
+	if to := tx.To(); to != nil {
+		// Баланс получателя изменяется только переводом ненулевой суммы:
+		// вызовы одного контракта без перевода друг от друга не зависят
+		accessSet.AddAccess(*to, accountFieldSlot("balance", *to), true, tx.Value().Sign() > 0, false, false)
+		accessSet.AddAccess(*to, accountFieldSlot("code", *to), true, false, false, true)
+
+		// Слоты хранилища, которые можно вывести из данных вызова
+		AnalyzeCallData(tx.Data(), accessSet, *to, sender)
 	} else {
 		// Создание контракта
 		contractAddr := crypto.CreateAddress(sender, tx.Nonce())
-		codeSlot := crypto.Keccak256Hash([]byte("code"), contractAddr.Bytes())
-		accessSet.AddAccess(contractAddr, codeSlot, false, true, true, false)
+		accessSet.AddAccess(contractAddr, accountFieldSlot("exist", contractAddr), true, true, true, false)
+		accessSet.AddAccess(contractAddr, accountFieldSlot("code", contractAddr), false, true, true, false)
 	}
-	
+	accessSet.AddAccessList(tx.AccessList())
+
 	return accessSet
 }
 
-// AnalyzeCallData анализирует данные вызова контракта, чтобы предсказать
-// доступы к хранилищу на основе сигнатуры функции
-func AnalyzeCallData(data []byte, accessSet *AccessSet, contractAddr common.Address) {
+// Селекторы функций ERC20, доступы которых выводятся из данных вызова
+var (
+	erc20TransferSig     = [4]byte(crypto.Keccak256([]byte("transfer(address,uint256)"))[:4])
+	erc20TransferFromSig = [4]byte(crypto.Keccak256([]byte("transferFrom(address,address,uint256)"))[:4])
+	erc20ApproveSig      = [4]byte(crypto.Keccak256([]byte("approve(address,uint256)"))[:4])
+)
+
+// AnalyzeCallData анализирует данные вызова контракта отправителем sender,
+// чтобы предсказать доступы к хранилищу на основе сигнатуры функции.
+// Распознаются transfer, transferFrom и approve ERC20 с раскладкой
+// хранилища OpenZeppelin; доступы остальных функций предсказывает
+// DependencyAnalyzer по выученным профилям.
+func AnalyzeCallData(data []byte, accessSet *AccessSet, contractAddr common.Address, sender common.Address) {
 	if len(data) < 4 {
 		return
 	}
-	
-	// Получаем сигнатуру функции (первые 4 байта)
-	signature := data[:4]
-	
-	// В реальной реализации здесь будет более сложный анализ на основе
-	// известных сигнатур функций, ABI контрактов и т.д.
-	
-	// Пример: для ERC20 токенов
-	transferSig := crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
-	if common.BytesToHash(signature).Big().Cmp(common.BytesToHash(transferSig).Big()) == 0 {
-		// Токен transfer затрагивает балансы отправителя и получателя
+	arg := func(i int) common.Address {
+		return common.BytesToAddress(data[4+32*i+12 : 4+32*(i+1)])
+	}
+	switch [4]byte(data[:4]) {
+	case erc20TransferSig:
+		// transfer изменяет балансы отправителя и получателя
 		if len(data) >= 4+32+32 {
-			// Извлекаем адрес получателя (пропускаем сигнатуру и отступ)
-			recipientAddr := common.BytesToAddress(data[4+12 : 4+32])
-			
-			// Добавляем доступы к балансам
-			senderBalanceSlot := GetERC20BalanceSlot(contractAddr, contractAddr)
-			recipientBalanceSlot := GetERC20BalanceSlot(contractAddr, recipientAddr)
-			
-			accessSet.AddAccess(contractAddr, senderBalanceSlot, true, true, false, false)
-			accessSet.AddAccess(contractAddr, recipientBalanceSlot, true, true, false, false)
+			accessSet.AddAccess(contractAddr, GetERC20BalanceSlot(contractAddr, sender), true, true, false, false)
+			accessSet.AddAccess(contractAddr, GetERC20BalanceSlot(contractAddr, arg(0)), true, true, false, false)
+		}
+	case erc20TransferFromSig:
+		// transferFrom дополнительно расходует разрешение владельца
+		if len(data) >= 4+3*32 {
+			from, to := arg(0), arg(1)
+			accessSet.AddAccess(contractAddr, GetERC20BalanceSlot(contractAddr, from), true, true, false, false)
+			accessSet.AddAccess(contractAddr, GetERC20BalanceSlot(contractAddr, to), true, true, false, false)
+			accessSet.AddAccess(contractAddr, GetERC20AllowanceSlot(contractAddr, from, sender), true, true, false, false)
+		}
+	case erc20ApproveSig:
+		if len(data) >= 4+2*32 {
+			accessSet.AddAccess(contractAddr, GetERC20AllowanceSlot(contractAddr, sender, arg(0)), true, true, false, false)
 		}
 	}
 }
@@ -167,7 +232,15 @@ func AnalyzeCallData(data []byte, accessSet *AccessSet, contractAddr common.Addr
 func GetERC20BalanceSlot(token common.Address, owner common.Address) common.Hash {
 	// Эта формула зависит от конкретной реализации контракта
 	// В большинстве ERC20: mapping(address => uint256) balances
-	return crypto.Keccak256Hash(owner.Bytes(), common.BigToHash(common.Big0).Bytes())
+	return crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), common.BigToHash(common.Big0).Bytes())
+}
+
+// GetERC20AllowanceSlot возвращает слот разрешения spender тратить токены
+// owner: mapping(address => mapping(address => uint256)) allowances во
+// втором слоте
+func GetERC20AllowanceSlot(token common.Address, owner, spender common.Address) common.Hash {
+	inner := crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), common.BigToHash(common.Big1).Bytes())
+	return crypto.Keccak256Hash(common.LeftPadBytes(spender.Bytes(), 32), inner.Bytes())
 }
 
 // HasDependency проверяет, есть ли зависимость между двумя транзакциями
@@ -200,6 +273,58 @@ func HasDependency(accessSet1, accessSet2 *AccessSet) bool {
 	return false
 }
 
+// dependenciesOf строит граф зависимостей по наборам доступа за один проход:
+// транзакция зависит от последней предыдущей транзакции, записавшей
+// элемент, к которому она обращается, а записывающая транзакция - еще и от
+// всех, кто читал элемент после этой записи. Более ранние конфликты
+// покрываются транзитивно, поэтому группировка совпадает с попарной
+// проверкой HasDependency.
+func dependenciesOf(accessSets []*AccessSet) map[int][]int {
+	type location struct {
+		addr common.Address
+		slot common.Hash
+	}
+	var (
+		dependencies = make(map[int][]int, len(accessSets))
+		lastWriter   = make(map[location]int)
+		readers      = make(map[location][]int)
+	)
+	for i, as := range accessSets {
+		deps := make(map[int]struct{})
+		for addr, slots := range as.Records {
+			for slot, record := range slots {
+				loc := location{addr, slot}
+				if w, ok := lastWriter[loc]; ok {
+					deps[w] = struct{}{}
+				}
+				if record.IsWrite {
+					for _, r := range readers[loc] {
+						deps[r] = struct{}{}
+					}
+				}
+			}
+		}
+		for addr, slots := range as.Records {
+			for slot, record := range slots {
+				loc := location{addr, slot}
+				switch {
+				case record.IsWrite:
+					lastWriter[loc] = i
+					delete(readers, loc)
+				case record.IsRead || record.IsCodeRead:
+					readers[loc] = append(readers[loc], i)
+				}
+			}
+		}
+		dependencies[i] = make([]int, 0, len(deps))
+		for dep := range deps {
+			dependencies[i] = append(dependencies[i], dep)
+		}
+		slices.Sort(dependencies[i])
+	}
+	return dependencies
+}
+
 // GroupTransactions группирует транзакции для параллельного выполнения
 func GroupTransactions(txs []*types.Transaction, dependencies map[int][]int) [][]int {
 	// Упрощенная реализация: группируем транзакции по уровням зависимостей
@@ -235,6 +360,7 @@ func GroupTransactions(txs []*types.Transaction, dependencies map[int][]int) [][
 		for _, idx := range group {
 			processed[idx] = true
 		}
+		parallelGroupSizeHist.Update(int64(len(group)))
 		
 		groups = append(groups, group)
 	}
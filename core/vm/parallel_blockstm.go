@@ -0,0 +1,321 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// BlockSTMTask выполняет транзакцию с номером txIdx над состоянием statedb и
+// возвращает ее результат. Задача вызывается конкурентно и может повторяться
+// для одной транзакции, поэтому не должна иметь побочных эффектов вне
+// statedb. Finalise вызывается исполнителем после задачи.
+type BlockSTMTask func(txIdx int, statedb StateDB) (interface{}, error)
+
+// BlockSTMResult - результат транзакции, прошедший проверку: он совпадает с
+// результатом последовательного выполнения транзакций блока по порядку
+type BlockSTMResult struct {
+	Result       interface{}   // Значение, возвращенное BlockSTMTask
+	Err          error         // Ошибка, возвращенная BlockSTMTask
+	Incarnations int           // Сколько раз транзакция выполнялась
+	Elapsed      time.Duration // Время выполнения последней инкарнации
+
+	reads     map[StateKey]interface{}
+	writes    map[StateKey]mvWrite
+	registers map[common.Address][]byte
+	logs      []*types.Log
+	preimages map[common.Hash][]byte
+}
+
+// stmOutput - результат инкарнации транзакции
+type stmOutput struct {
+	incarnation int
+	result      interface{}
+	err         error
+	state       *OverlayStateDB
+	elapsed     time.Duration
+}
+
+// BlockSTMStats - сводка выполнения блока исполнителем Block-STM
+type BlockSTMStats struct {
+	Workers int           // Число потоков исполнителя
+	Elapsed time.Duration // Время выполнения блока
+	Busy    time.Duration // Суммарное время потоков на выполнении и проверке
+}
+
+// Utilisation возвращает долю времени, которую потоки были заняты
+// выполнением и проверкой транзакций
+func (s *BlockSTMStats) Utilisation() float64 {
+	if s.Workers == 0 || s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Busy) / float64(time.Duration(s.Workers)*s.Elapsed)
+}
+
+// stmExecutor выполняет транзакции блока по алгоритму Block-STM
+type stmExecutor struct {
+	task    BlockSTMTask
+	hashes  []common.Hash
+	mv      *mvMemory
+	sched   *stmScheduler
+	outputs []atomic.Pointer[stmOutput]
+	busy    atomic.Int64 // Время потоков на задачах, в наносекундах
+}
+
+// ExecuteBlockSTM оптимистично выполняет транзакции блока с хэшами hashes
+// параллельно в workers потоках (0 - по числу процессоров) поверх statedb. Транзакции
+// читают записи предыдущих транзакций из многоверсионной памяти; транзакция,
+// прочитавшая значение, которое затем изменила предыдущая транзакция,
+// выполняется заново. statedb при выполнении не изменяется: результаты
+// применяются по порядку методом BlockSTMResult.Apply.
+func ExecuteBlockSTM(statedb *state.StateDB, hashes []common.Hash, workers int, task BlockSTMTask) ([]*BlockSTMResult, *BlockSTMStats) {
+	n := len(hashes)
+	if n == 0 {
+		return nil, &BlockSTMStats{}
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, n)
+
+	e := &stmExecutor{
+		task:    task,
+		hashes:  hashes,
+		mv:      newMVMemory(n),
+		sched:   newSTMScheduler(n),
+		outputs: make([]atomic.Pointer[stmOutput], n),
+	}
+	// Каждый поток читает собственную копию состояния: state.StateDB
+	// кэширует прочитанные объекты и не допускает конкурентного доступа
+	var (
+		wg    sync.WaitGroup
+		start = time.Now()
+	)
+	for i := 0; i < workers; i++ {
+		base := statedb.Copy()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.work(base)
+		}()
+	}
+	wg.Wait()
+	stats := &BlockSTMStats{
+		Workers: workers,
+		Elapsed: time.Since(start),
+		Busy:    time.Duration(e.busy.Load()),
+	}
+
+	results := make([]*BlockSTMResult, n)
+	for i := range results {
+		out := e.outputs[i].Load()
+		results[i] = &BlockSTMResult{
+			Result:       out.result,
+			Err:          out.err,
+			Incarnations: out.incarnation + 1,
+			Elapsed:      out.elapsed,
+			reads:        out.state.reads,
+			writes:       out.state.writes,
+			registers:    out.state.quantumWrites,
+			logs:         out.state.logs,
+			preimages:    out.state.preimages,
+		}
+	}
+	return results, stats
+}
+
+// work - цикл потока: выполняет задачи планировщика до завершения блока
+func (e *stmExecutor) work(base *state.StateDB) {
+	var task stmTask
+	for !e.sched.done.Load() {
+		switch task.kind {
+		case stmExecution:
+			start := time.Now()
+			task = e.execute(base, task)
+			e.busy.Add(int64(time.Since(start)))
+		case stmValidation:
+			start := time.Now()
+			task = e.validate(base, task)
+			e.busy.Add(int64(time.Since(start)))
+		default:
+			if task = e.sched.nextTask(); task.kind == stmNoTask {
+				runtime.Gosched()
+			}
+		}
+	}
+}
+
+// execute выполняет инкарнацию транзакции и публикует ее записи
+func (e *stmExecutor) execute(base *state.StateDB, task stmTask) stmTask {
+	for {
+		out, dep := e.run(base, task)
+		if dep >= 0 {
+			if e.sched.addDependency(task.txIdx, dep) {
+				return stmTask{}
+			}
+			// Транзакция dep уже выполнена заново, повторяем сразу
+			continue
+		}
+		e.outputs[task.txIdx].Store(out)
+		for _, register := range out.state.quantumWrites {
+			if len(register) != 0 {
+				e.mv.registers.Store(crypto.Keccak256Hash(register), register)
+			}
+		}
+		wroteNew := e.mv.record(task.txIdx, task.incarnation, out.state.writes)
+		return e.sched.finishExecution(task.txIdx, task.incarnation, wroteNew)
+	}
+}
+
+// TxHashes возвращает хэши транзакций в порядке блока для ExecuteBlockSTM
+func TxHashes(txs types.Transactions) []common.Hash {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	return hashes
+}
+
+// run вызывает задачу над новым состоянием транзакции. Если транзакция
+// прочитала устаревшую запись, возвращается номер транзакции, которую
+// следует дождаться.
+func (e *stmExecutor) run(base *state.StateDB, task stmTask) (out *stmOutput, dep int) {
+	s := newOverlayStateDB(e.mv, base, task.txIdx)
+	s.SetTxHash(e.hashes[task.txIdx])
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			if d, ok := r.(stmDependency); ok {
+				out, dep = nil, d.blocking
+				return
+			}
+			// Паника при несогласованных чтениях устраняется повторным
+			// выполнением; прошедшая проверку паника становится ошибкой
+			s.writes = make(map[StateKey]mvWrite)
+			s.quantumWrites = make(map[common.Address][]byte)
+			out = &stmOutput{incarnation: task.incarnation, err: fmt.Errorf("transaction %d panicked: %v", task.txIdx, r), state: s, elapsed: time.Since(start)}
+			dep = -1
+		}
+	}()
+	result, err := e.task(task.txIdx, s)
+	s.Finalise(true)
+	return &stmOutput{incarnation: task.incarnation, result: result, err: err, state: s, elapsed: time.Since(start)}, -1
+}
+
+// validate проверяет прочитанные инкарнацией значения и прерывает ее при
+// расхождении
+func (e *stmExecutor) validate(base *state.StateDB, task stmTask) stmTask {
+	out := e.outputs[task.txIdx].Load()
+	valid := e.mv.validate(base, task.txIdx, out.state.reads)
+	aborted := !valid && e.sched.tryValidationAbort(task.txIdx, task.incarnation)
+	if aborted {
+		e.mv.convertToEstimates(task.txIdx)
+	}
+	return e.sched.finishValidation(task.txIdx, aborted)
+}
+
+// ReadSet возвращает элементы состояния, прочитанные последней инкарнацией
+// транзакции
+func (r *BlockSTMResult) ReadSet() []StateKey {
+	return sortedKeys(r.reads)
+}
+
+// WriteSet возвращает элементы состояния, записанные последней инкарнацией
+// транзакции
+func (r *BlockSTMResult) WriteSet() []StateKey {
+	return sortedKeys(r.writes)
+}
+
+// Apply применяет изменения транзакции к statedb и завершает транзакцию
+// вызовом Finalise. Результаты применяются в порядке транзакций блока после
+// SetTxContext соответствующей транзакции, что дает то же состояние, журналы
+// и прообразы, что и последовательное выполнение.
+func (r *BlockSTMResult) Apply(statedb *state.StateDB) {
+	applyWrites(statedb, r.writes, r.registers, r.logs, r.preimages)
+}
+
+// applyWrites переносит записи транзакции в dst и завершает транзакцию
+// вызовом Finalise. Аккаунты обходятся в порядке адресов, чтобы порядок
+// изменений не зависел от обхода карты.
+func applyWrites(dst StateWriter, writes map[StateKey]mvWrite, registers map[common.Address][]byte, logs []*types.Log, preimages map[common.Hash][]byte) {
+	accounts := make(map[common.Address][]StateKey)
+	for key := range writes {
+		accounts[key.Address] = append(accounts[key.Address], key)
+	}
+	addrs := make([]common.Address, 0, len(accounts))
+	for addr := range accounts {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+
+	for _, addr := range addrs {
+		if _, deleted := writes[StateKey{Address: addr, Kind: StateKeyDestruct}]; deleted {
+			if !dst.Exist(addr) {
+				dst.CreateAccount(addr)
+			}
+			dst.SelfDestruct(addr)
+			continue
+		}
+		if w, ok := writes[StateKey{Address: addr, Kind: StateKeyExist}]; ok && w.value.(bool) {
+			dst.CreateAccount(addr)
+		}
+		for _, key := range accounts[addr] {
+			w := writes[key]
+			switch key.Kind {
+			case StateKeyBalance:
+				value := w.value.(*uint256.Int)
+				switch prev := dst.GetBalance(addr); {
+				case w.delta:
+					dst.AddBalance(addr, value, tracing.BalanceChangeUnspecified)
+				case value.Gt(prev):
+					dst.AddBalance(addr, new(uint256.Int).Sub(value, prev), tracing.BalanceChangeUnspecified)
+				case value.Lt(prev):
+					dst.SubBalance(addr, new(uint256.Int).Sub(prev, value), tracing.BalanceChangeUnspecified)
+				}
+			case StateKeyNonce:
+				dst.SetNonce(addr, w.value.(uint64), tracing.NonceChangeUnspecified)
+			case StateKeyCode:
+				dst.SetCode(addr, w.value.(*mvCodeValue).code)
+			case StateKeyStorage:
+				dst.SetState(addr, key.Slot, w.value.(common.Hash))
+			}
+		}
+		if register, ok := registers[addr]; ok {
+			dst.SetQuantumRegister(addr, register)
+		}
+	}
+	for _, log := range logs {
+		dst.AddLog(log)
+	}
+	for hash, preimage := range preimages {
+		dst.AddPreimage(hash, preimage)
+	}
+	dst.Finalise(true)
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// stmTestDB - методы состояния, которые используют синтетические транзакции;
+// их реализуют и state.StateDB, и состояние транзакции Block-STM
+type stmTestDB interface {
+	CreateAccount(common.Address)
+	CreateContract(common.Address)
+	SubBalance(common.Address, *uint256.Int, tracing.BalanceChangeReason) uint256.Int
+	AddBalance(common.Address, *uint256.Int, tracing.BalanceChangeReason) uint256.Int
+	GetBalance(common.Address) *uint256.Int
+	GetNonce(common.Address) uint64
+	SetNonce(common.Address, uint64, tracing.NonceChangeReason)
+	GetCodeSize(common.Address) int
+	SetCode(common.Address, []byte) []byte
+	GetQuantumRegister(common.Address) []byte
+	SetQuantumRegister(common.Address, []byte) []byte
+	GetState(common.Address, common.Hash) common.Hash
+	SetState(common.Address, common.Hash, common.Hash) common.Hash
+	SelfDestruct6780(common.Address) (uint256.Int, bool)
+	Exist(common.Address) bool
+	Snapshot() int
+	RevertToSnapshot(int)
+	AddLog(*types.Log)
+}
+
+// stmTestTx - синтетическая транзакция: последовательность операций над
+// состоянием, результат которой зависит от прочитанных значений
+type stmTestTx func(db stmTestDB) (interface{}, error)
+
+// stmTestAccounts - аккаунты, между которыми возникают конфликты
+var stmTestAccounts = func() []common.Address {
+	addrs := make([]common.Address, 8)
+	for i := range addrs {
+		addrs[i] = common.BytesToAddress([]byte{0xaa, byte(i)})
+	}
+	return addrs
+}()
+
+var stmTestCoinbase = common.HexToAddress("0xc0ffee")
+
+// newSTMTestState создает состояние с балансами, хранилищем и кодом
+func newSTMTestState(t *testing.T) *state.StateDB {
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, addr := range stmTestAccounts {
+		statedb.SetBalance(addr, uint256.NewInt(uint64(1000*(i+1))), tracing.BalanceChangeUnspecified)
+		statedb.SetNonce(addr, uint64(i), tracing.NonceChangeUnspecified)
+		if i%2 == 0 {
+			statedb.SetCode(addr, []byte{0x60, byte(i)})
+			statedb.SetState(addr, common.Hash{1}, common.Hash{byte(i)})
+		}
+	}
+	statedb.SetBalance(stmTestCoinbase, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+	// Пустой аккаунт, удаляемый при первом касании
+	statedb.CreateAccount(common.HexToAddress("0xdead"))
+	root, err := statedb.Commit(0, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb, err = state.New(root, statedb.Database())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statedb
+}
+
+// randomSTMTx строит случайную транзакцию над общими аккаунтами
+func randomSTMTx(rnd *rand.Rand, idx int) stmTestTx {
+	type op func(db stmTestDB, acc *uint64)
+	pick := func() common.Address { return stmTestAccounts[rnd.Intn(len(stmTestAccounts))] }
+
+	var ops []op
+	for n := 1 + rnd.Intn(5); n > 0; n-- {
+		from, to, slot := pick(), pick(), common.Hash{byte(rnd.Intn(3))}
+		amount := uint256.NewInt(uint64(rnd.Intn(300)))
+		switch rnd.Intn(9) {
+		case 0, 1: // Перевод при достаточном балансе
+			ops = append(ops, func(db stmTestDB, acc *uint64) {
+				if db.GetBalance(from).Cmp(amount) >= 0 {
+					db.SubBalance(from, amount, tracing.BalanceChangeTransfer)
+					db.AddBalance(to, amount, tracing.BalanceChangeTransfer)
+					*acc += amount.Uint64()
+				}
+			})
+		case 2: // Счетчик в хранилище
+			ops = append(ops, func(db stmTestDB, acc *uint64) {
+				v := new(uint256.Int).SetBytes(db.GetState(to, slot).Bytes())
+				db.SetState(to, slot, common.Hash(v.AddUint64(v, 1).Bytes32()))
+				*acc += v.Uint64()
+			})
+		case 3: // Увеличение nonce
+			ops = append(ops, func(db stmTestDB, acc *uint64) {
+				db.SetNonce(from, db.GetNonce(from)+1, tracing.NonceChangeUnspecified)
+			})
+		case 4: // Журнал с прочитанным балансом
+			ops = append(ops, func(db stmTestDB, acc *uint64) {
+				db.AddLog(&types.Log{Address: from, Data: db.GetBalance(from).Bytes()})
+			})
+		case 5: // Откат изменений вложенного вызова
+			ops = append(ops, func(db stmTestDB, acc *uint64) {
+				id := db.Snapshot()
+				db.SetState(from, slot, common.Hash{0xff})
+				db.AddBalance(to, uint256.NewInt(7), tracing.BalanceChangeTransfer)
+				db.AddLog(&types.Log{Address: to})
+				db.RevertToSnapshot(id)
+			})
+		case 6: // Контракт, созданный и уничтоженный в транзакции
+			created := crypto.CreateAddress(from, uint64(idx))
+			ops = append(ops, func(db stmTestDB, acc *uint64) {
+				if !db.Exist(created) {
+					db.CreateAccount(created)
+				}
+				db.CreateContract(created)
+				db.SetCode(created, []byte{0xff})
+				db.SetState(created, slot, common.Hash{1})
+				db.SelfDestruct6780(created)
+			})
+		case 7: // Касание пустого аккаунта и получение кода
+			ops = append(ops, func(db stmTestDB, acc *uint64) {
+				db.AddBalance(common.HexToAddress("0xdead"), new(uint256.Int), tracing.BalanceChangeTransfer)
+				*acc += uint64(db.GetCodeSize(to))
+			})
+		case 8: // Квантовый регистр
+			register := []byte{byte(idx), 1, 2}
+			ops = append(ops, func(db stmTestDB, acc *uint64) {
+				*acc += uint64(len(db.GetQuantumRegister(to)))
+				db.SetQuantumRegister(to, register)
+			})
+		}
+	}
+	fee := uint256.NewInt(uint64(1 + rnd.Intn(10)))
+	return func(db stmTestDB) (interface{}, error) {
+		var acc uint64
+		for _, op := range ops {
+			op(db, &acc)
+		}
+		// Комиссия получателю блока начисляется без чтения баланса
+		db.AddBalance(stmTestCoinbase, fee, tracing.BalanceIncreaseRewardTransactionFee)
+		return acc, nil
+	}
+}
+
+// Проверяет, что Block-STM дает то же состояние, журналы и результаты, что
+// и последовательное выполнение
+func TestBlockSTMMatchesSequential(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		rnd := rand.New(rand.NewSource(seed))
+		txs := make([]stmTestTx, 40)
+		for i := range txs {
+			txs[i] = randomSTMTx(rnd, i)
+		}
+		hash := func(i int) common.Hash { return common.BytesToHash([]byte{byte(i), 1}) }
+
+		seq := newSTMTestState(t)
+		var want []interface{}
+		for i, tx := range txs {
+			seq.SetTxContext(hash(i), i)
+			result, _ := tx(seq)
+			seq.Finalise(true)
+			want = append(want, result)
+		}
+
+		par := newSTMTestState(t)
+		hashes := make([]common.Hash, len(txs))
+		for i := range hashes {
+			hashes[i] = hash(i)
+		}
+		results, _ := ExecuteBlockSTM(par, hashes, 4, func(i int, db StateDB) (interface{}, error) {
+			return txs[i](db)
+		})
+		for i, r := range results {
+			if r.Err != nil {
+				t.Fatalf("seed %d: tx %d failed: %v", seed, i, r.Err)
+			}
+			if !reflect.DeepEqual(r.Result, want[i]) {
+				t.Errorf("seed %d: tx %d result mismatch: have %v, want %v", seed, i, r.Result, want[i])
+			}
+			par.SetTxContext(hash(i), i)
+			r.Apply(par)
+		}
+		if have, want := par.IntermediateRoot(true), seq.IntermediateRoot(true); have != want {
+			t.Fatalf("seed %d: state root mismatch: have %x, want %x", seed, have, want)
+		}
+		for i := range txs {
+			if have, want := par.GetLogs(hash(i), 0, common.Hash{}), seq.GetLogs(hash(i), 0, common.Hash{}); !reflect.DeepEqual(have, want) {
+				t.Fatalf("seed %d: tx %d logs mismatch: have %v, want %v", seed, i, have, want)
+			}
+		}
+		for _, addr := range stmTestAccounts {
+			if have, want := par.GetQuantumRegister(addr), seq.GetQuantumRegister(addr); !reflect.DeepEqual(have, want) {
+				t.Errorf("seed %d: register of %x: have %x, want %x", seed, addr, have, want)
+			}
+		}
+	}
+}
+
+// Проверяет, что цепочка зависимых транзакций выполняется повторно и дает
+// последовательный результат
+func TestBlockSTMDependencyChain(t *testing.T) {
+	counter := stmTestAccounts[0]
+	statedb := newSTMTestState(t)
+	results, stats := ExecuteBlockSTM(statedb, make([]common.Hash, 50), 8, func(i int, db StateDB) (interface{}, error) {
+		v := db.GetState(counter, common.Hash{9}).Big().Uint64()
+		db.SetState(counter, common.Hash{9}, common.BigToHash(new(uint256.Int).SetUint64(v+1).ToBig()))
+		return v, nil
+	})
+	if stats.Workers != 8 || stats.Busy <= 0 {
+		t.Fatalf("stats: %+v", stats)
+	}
+	if u := stats.Utilisation(); u <= 0 || u > 1 {
+		t.Fatalf("utilisation out of range: %v", u)
+	}
+	for i, r := range results {
+		if r.Result.(uint64) != uint64(i) {
+			t.Fatalf("tx %d read counter %d", i, r.Result)
+		}
+		r.Apply(statedb)
+	}
+	if have := statedb.GetState(counter, common.Hash{9}).Big().Uint64(); have != 50 {
+		t.Fatalf("counter: have %d, want 50", have)
+	}
+}
@@ -0,0 +1,472 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/json"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// Точность предсказания зависимостей: транзакции, для которых зависимость
+	// предсказана, обнаружена при выполнении, не предсказана (пропущена) и
+	// предсказана напрасно
+	conflictPredictedMeter = metrics.NewRegisteredMeter("parallel/conflicts/predicted", nil)
+	conflictActualMeter    = metrics.NewRegisteredMeter("parallel/conflicts/actual", nil)
+	conflictMissedMeter    = metrics.NewRegisteredMeter("parallel/conflicts/missed", nil)
+	conflictFalseMeter     = metrics.NewRegisteredMeter("parallel/conflicts/false", nil)
+
+	parallelGroupSizeHist = metrics.NewRegisteredHistogram("parallel/groups/size", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+const (
+	defaultProfileCache = 4096 // Профилей функций контрактов по умолчанию
+	prerunCache         = 8192 // Наборов доступа из предварительного выполнения
+
+	maxProfileArgs     = 4   // Слов данных вызова, проверяемых как ключи mapping
+	maxMappingSlot     = 8   // Позиций mapping, проверяемых при обучении
+	maxProfilePatterns = 128 // Правил в одном профиле
+	minProfileCalls    = 2   // Вызовов, после которых профиль используется
+)
+
+// slotPatternKind - способ вычисления слота хранилища
+type slotPatternKind uint8
+
+const (
+	slotConstant      slotPatternKind = iota // Фиксированный слот
+	slotMapping                              // keccak(ключ . позиция)
+	slotNestedMapping                        // keccak(ключ2 . keccak(ключ1 . позиция))
+)
+
+// slotPattern - правило, по которому слот хранилища вычисляется из вызова.
+// Ключи mapping - отправитель (индекс 0) и слова данных вызова после
+// селектора (индексы с 1).
+type slotPattern struct {
+	kind      slotPatternKind
+	address   common.Address
+	slot      common.Hash // Слот или позиция mapping
+	key, key2 int
+}
+
+// resolve вычисляет слот по ключам вызова
+func (p slotPattern) resolve(keys []common.Hash) (common.Hash, bool) {
+	switch p.kind {
+	case slotMapping:
+		if p.key >= len(keys) {
+			return common.Hash{}, false
+		}
+		return crypto.Keccak256Hash(keys[p.key][:], p.slot[:]), true
+	case slotNestedMapping:
+		if p.key >= len(keys) || p.key2 >= len(keys) {
+			return common.Hash{}, false
+		}
+		inner := crypto.Keccak256Hash(keys[p.key][:], p.slot[:])
+		return crypto.Keccak256Hash(keys[p.key2][:], inner[:]), true
+	}
+	return p.slot, true
+}
+
+// slotStats - сколько вызовов обращались к слоту правила и сколько его изменяли
+type slotStats struct {
+	seen, written uint32
+}
+
+// profileKey - функция контракта
+type profileKey struct {
+	contract common.Address
+	selector [4]byte
+}
+
+// contractProfile - доступы к хранилищу, наблюдавшиеся при вызовах одной
+// функции контракта
+type contractProfile struct {
+	mu       sync.Mutex
+	calls    uint32
+	patterns map[slotPattern]*slotStats
+}
+
+// callKeys возвращает возможные ключи mapping вызова: отправителя и первые
+// слова данных вызова
+func callKeys(sender common.Address, data []byte) []common.Hash {
+	keys := []common.Hash{common.BytesToHash(sender.Bytes())}
+	for i := 4; i+32 <= len(data) && len(keys) <= maxProfileArgs; i += 32 {
+		keys = append(keys, common.BytesToHash(data[i:i+32]))
+	}
+	return keys
+}
+
+// learn учитывает доступы одного вызова. Слот, совпавший с элементом
+// mapping по одному из ключей вызова, запоминается как правило mapping и
+// предсказывается для вызовов с другими аргументами.
+func (p *contractProfile) learn(keys []common.Hash, accessed map[StateKey]bool) {
+	candidates := make(map[common.Hash]slotPattern)
+	for pos := uint64(0); pos < maxMappingSlot && len(accessed) > 0; pos++ {
+		slot := common.BigToHash(new(big.Int).SetUint64(pos))
+		for i, key := range keys {
+			inner := crypto.Keccak256Hash(key[:], slot[:])
+			candidates[inner] = slotPattern{kind: slotMapping, slot: slot, key: i}
+			for j, key2 := range keys {
+				outer := crypto.Keccak256Hash(key2[:], inner[:])
+				candidates[outer] = slotPattern{kind: slotNestedMapping, slot: slot, key: i, key2: j}
+			}
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls++
+	seen := make(map[slotPattern]struct{})
+	for key, written := range accessed {
+		pattern, ok := candidates[key.Slot]
+		if !ok {
+			pattern = slotPattern{kind: slotConstant, slot: key.Slot}
+		}
+		pattern.address = key.Address
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		seen[pattern] = struct{}{}
+
+		stats := p.patterns[pattern]
+		if stats == nil {
+			if len(p.patterns) >= maxProfilePatterns {
+				continue
+			}
+			stats = new(slotStats)
+			p.patterns[pattern] = stats
+		}
+		stats.seen++
+		if written {
+			stats.written++
+		}
+	}
+}
+
+// predict добавляет в accessSet слоты, к которым обращалась хотя бы половина
+// вызовов функции, и возвращает, изменялись ли они
+func (p *contractProfile) predict(keys []common.Hash, accessSet *AccessSet) map[common.Address]map[common.Hash]bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.calls < minProfileCalls {
+		return nil
+	}
+	known := make(map[common.Address]map[common.Hash]bool)
+	for pattern, stats := range p.patterns {
+		if stats.seen*2 < p.calls {
+			continue
+		}
+		slot, ok := pattern.resolve(keys)
+		if !ok {
+			continue
+		}
+		written := stats.written > 0
+		accessSet.AddAccess(pattern.address, slot, true, written, false, false)
+		if known[pattern.address] == nil {
+			known[pattern.address] = make(map[common.Hash]bool)
+		}
+		known[pattern.address][slot] = written
+	}
+	return known
+}
+
+// DependencyAnalyzer предсказывает зависимости между транзакциями блока. Для
+// транзакции, выполненной заранее (например, при упреждающей загрузке
+// состояния следующего блока), используется ее точный набор доступа. Иначе
+// набор предсказывается по полям транзакции, списку доступа EIP-2930 и
+// профилю вызываемой функции: правилам вычисления слотов хранилища,
+// выученным на транзакциях предыдущих блоков.
+//
+// Анализатор безопасен для конкурентного использования.
+type DependencyAnalyzer struct {
+	profiles *lru.Cache[profileKey, *contractProfile]
+	preruns  *lru.Cache[common.Hash, *AccessSet]
+}
+
+// NewDependencyAnalyzer создает анализатор, хранящий до profiles профилей
+// функций контрактов (0 - значение по умолчанию)
+func NewDependencyAnalyzer(profiles int) *DependencyAnalyzer {
+	if profiles <= 0 {
+		profiles = defaultProfileCache
+	}
+	return &DependencyAnalyzer{
+		profiles: lru.NewCache[profileKey, *contractProfile](profiles),
+		preruns:  lru.NewCache[common.Hash, *AccessSet](prerunCache),
+	}
+}
+
+// AddPrerun запоминает набор доступа транзакции, полученный при ее
+// предварительном выполнении
+func (a *DependencyAnalyzer) AddPrerun(hash common.Hash, accessSet *AccessSet) {
+	a.preruns.Add(hash, accessSet)
+}
+
+// prestateAccount - аккаунт в выводе prestateTracer
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// AccessSetFromPrestate строит набор доступа транзакции по выводу
+// prestateTracer. Вывод без diffMode перечисляет все затронутые аккаунты и
+// слоты, они считаются прочитанными. Вывод с diffMode содержит только
+// измененные поля, они считаются записанными. Любой из выводов может быть
+// пустым.
+func AccessSetFromPrestate(prestate, diff json.RawMessage) (*AccessSet, error) {
+	as := NewAccessSet()
+	if len(prestate) > 0 {
+		var pre map[common.Address]*prestateAccount
+		if err := json.Unmarshal(prestate, &pre); err != nil {
+			return nil, err
+		}
+		for addr, account := range pre {
+			for _, kind := range []StateKeyKind{StateKeyExist, StateKeyBalance, StateKeyNonce, StateKeyCode} {
+				as.AddStateKey(StateKey{Address: addr, Kind: kind}, true, false)
+			}
+			for slot := range account.Storage {
+				as.AddStateKey(StateKey{Address: addr, Kind: StateKeyStorage, Slot: slot}, true, false)
+			}
+		}
+	}
+	if len(diff) > 0 {
+		var changes struct {
+			Pre  map[common.Address]*prestateAccount `json:"pre"`
+			Post map[common.Address]*prestateAccount `json:"post"`
+		}
+		if err := json.Unmarshal(diff, &changes); err != nil {
+			return nil, err
+		}
+		write := func(addr common.Address, kind StateKeyKind, slot common.Hash) {
+			as.AddStateKey(StateKey{Address: addr, Kind: kind, Slot: slot}, true, true)
+		}
+		for addr, account := range changes.Pre {
+			// Слоты в pre изменены; аккаунт, отсутствующий в post, удален
+			for slot := range account.Storage {
+				write(addr, StateKeyStorage, slot)
+			}
+			if _, ok := changes.Post[addr]; !ok {
+				for _, kind := range []StateKeyKind{StateKeyExist, StateKeyBalance, StateKeyNonce, StateKeyCode} {
+					write(addr, kind, common.Hash{})
+				}
+			}
+		}
+		for addr, account := range changes.Post {
+			if _, ok := changes.Pre[addr]; !ok {
+				write(addr, StateKeyExist, common.Hash{})
+			}
+			if account.Balance != nil {
+				write(addr, StateKeyBalance, common.Hash{})
+			}
+			if account.Nonce != 0 {
+				write(addr, StateKeyNonce, common.Hash{})
+			}
+			if len(account.Code) != 0 {
+				write(addr, StateKeyCode, common.Hash{})
+			}
+			for slot := range account.Storage {
+				write(addr, StateKeyStorage, slot)
+			}
+		}
+	}
+	return as, nil
+}
+
+// AddPrestate запоминает набор доступа транзакции по выводу prestateTracer
+// ее предварительного выполнения (см. AccessSetFromPrestate)
+func (a *DependencyAnalyzer) AddPrestate(hash common.Hash, prestate, diff json.RawMessage) error {
+	as, err := AccessSetFromPrestate(prestate, diff)
+	if err != nil {
+		return err
+	}
+	a.AddPrerun(hash, as)
+	return nil
+}
+
+// profile возвращает профиль вызываемой транзакцией функции
+func (a *DependencyAnalyzer) profile(tx *types.Transaction, create bool) *contractProfile {
+	if tx.To() == nil || len(tx.Data()) < 4 {
+		return nil
+	}
+	key := profileKey{contract: *tx.To(), selector: [4]byte(tx.Data()[:4])}
+	if p, ok := a.profiles.Get(key); ok || !create {
+		return p
+	}
+	p := &contractProfile{patterns: make(map[slotPattern]*slotStats)}
+	a.profiles.Add(key, p)
+	return p
+}
+
+// Learn учитывает доступы к хранилищу выполненной транзакции в профиле
+// вызванной ею функции
+func (a *DependencyAnalyzer) Learn(tx *types.Transaction, sender common.Address, reads, writes []StateKey) {
+	p := a.profile(tx, true)
+	if p == nil {
+		return
+	}
+	accessed := make(map[StateKey]bool)
+	for _, key := range reads {
+		if key.Kind == StateKeyStorage {
+			accessed[key] = false
+		}
+	}
+	for _, key := range writes {
+		if key.Kind == StateKeyStorage {
+			accessed[key] = true
+		}
+	}
+	p.learn(callKeys(sender, tx.Data()), accessed)
+}
+
+// AccessSet возвращает предсказанный набор доступа транзакции
+func (a *DependencyAnalyzer) AccessSet(tx *types.Transaction, signer types.Signer) *AccessSet {
+	if as, ok := a.preruns.Get(tx.Hash()); ok {
+		return as
+	}
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		log.Debug("Failed to get sender", "tx", tx.Hash(), "err", err)
+		return NewAccessSet()
+	}
+	p := a.profile(tx, false)
+	if p == nil {
+		return analyzeTransaction(tx, sender)
+	}
+	// Профиль уточняет слоты из списка доступа: слот, который функция
+	// только читает, не создает зависимостей между ее вызовами
+	as := NewAccessSet()
+	known := p.predict(callKeys(sender, tx.Data()), as)
+	predicted := analyzeTransaction(tx, sender)
+	for addr, slots := range predicted.Records {
+		for slot, record := range slots {
+			if written, ok := known[addr][slot]; ok && !written {
+				record.IsWrite = false
+			}
+		}
+	}
+	as.Merge(predicted)
+	return as
+}
+
+// Analyze возвращает предсказанные зависимости между транзакциями
+func (a *DependencyAnalyzer) Analyze(txs []*types.Transaction, signer types.Signer) map[int][]int {
+	accessSets := make([]*AccessSet, len(txs))
+	for i, tx := range txs {
+		accessSets[i] = a.AccessSet(tx, signer)
+	}
+	return dependenciesOf(accessSets)
+}
+
+// ActualDependencies возвращает фактические зависимости выполненных
+// транзакций: транзакция зависит от предыдущих транзакций, записи которых
+// определили прочитанное ею значение. Запись без чтения (прибавка к
+// балансу) не отменяет предыдущих записей элемента, поэтому читающая его
+// транзакция зависит от всех таких записей после последней записи с чтением.
+func ActualDependencies(reads, writes [][]StateKey) map[int][]int {
+	var (
+		dependencies = make(map[int][]int, len(reads))
+		writers      = make(map[StateKey][]int)
+	)
+	for i := range reads {
+		deps := make(map[int]struct{})
+		for _, key := range reads[i] {
+			for _, w := range writers[key] {
+				deps[w] = struct{}{}
+			}
+			// Ячейку хранилища изменяет и удаление аккаунта
+			if key.Kind == StateKeyStorage {
+				for _, w := range writers[StateKey{Address: key.Address, Kind: StateKeyDestruct}] {
+					deps[w] = struct{}{}
+				}
+			}
+		}
+		read := make(map[StateKey]struct{}, len(reads[i]))
+		for _, key := range reads[i] {
+			read[key] = struct{}{}
+		}
+		for _, key := range writes[i] {
+			if _, ok := read[key]; ok {
+				writers[key] = nil
+			}
+			writers[key] = append(writers[key], i)
+		}
+		dependencies[i] = make([]int, 0, len(deps))
+		for dep := range deps {
+			dependencies[i] = append(dependencies[i], dep)
+		}
+		slices.Sort(dependencies[i])
+	}
+	return dependencies
+}
+
+// ConflictsByAddress возвращает для каждого аккаунта число транзакций,
+// прочитавших его элемент, который записала предыдущая транзакция блока.
+// Такие транзакции нельзя выполнить параллельно с предыдущими.
+func ConflictsByAddress(reads, writes [][]StateKey) map[common.Address]int {
+	var (
+		conflicts = make(map[common.Address]int)
+		written   = make(map[StateKey]struct{})
+	)
+	for i := range reads {
+		seen := make(map[common.Address]struct{})
+		for _, key := range reads[i] {
+			_, ok := written[key]
+			if !ok && key.Kind == StateKeyStorage {
+				_, ok = written[StateKey{Address: key.Address, Kind: StateKeyDestruct}]
+			}
+			if _, counted := seen[key.Address]; ok && !counted {
+				seen[key.Address] = struct{}{}
+				conflicts[key.Address]++
+			}
+		}
+		for _, key := range writes[i] {
+			written[key] = struct{}{}
+		}
+	}
+	return conflicts
+}
+
+// ReportConflicts сравнивает предсказанные зависимости n транзакций с
+// фактическими и обновляет метрики точности предсказания
+func ReportConflicts(predicted, actual map[int][]int, n int) {
+	for i := 0; i < n; i++ {
+		p, a := len(predicted[i]) > 0, len(actual[i]) > 0
+		if p {
+			conflictPredictedMeter.Mark(1)
+		}
+		if a {
+			conflictActualMeter.Mark(1)
+		}
+		switch {
+		case a && !p:
+			conflictMissedMeter.Mark(1)
+		case p && !a:
+			conflictFalseMeter.Mark(1)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var depTestSigner = types.LatestSignerForChainID(big.NewInt(1))
+
+// depTestTx подписывает вызов contract с данными data и списком доступа
+func depTestTx(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, contract common.Address, value int64, data []byte, list types.AccessList) *types.Transaction {
+	t.Helper()
+	return types.MustSignNewTx(key, depTestSigner, &types.DynamicFeeTx{
+		ChainID:    big.NewInt(1),
+		Nonce:      nonce,
+		To:         &contract,
+		Value:      big.NewInt(value),
+		Gas:        100000,
+		GasFeeCap:  big.NewInt(1),
+		Data:       data,
+		AccessList: list,
+	})
+}
+
+func depTestKeys(n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	return keys
+}
+
+// Проверяет, что вызовы без перевода не зависят друг от друга, а список
+// доступа создает зависимость по общему слоту
+func TestAnalyzerValueAndAccessList(t *testing.T) {
+	var (
+		keys     = depTestKeys(3)
+		contract = common.HexToAddress("0xc0")
+		slot     = common.Hash{7}
+		list     = types.AccessList{{Address: contract, StorageKeys: []common.Hash{slot}}}
+		a        = NewDependencyAnalyzer(0)
+	)
+	txs := []*types.Transaction{
+		depTestTx(t, keys[0], 0, contract, 0, nil, nil),
+		depTestTx(t, keys[1], 0, contract, 0, nil, nil),
+		depTestTx(t, keys[2], 0, contract, 1, nil, nil),
+	}
+	deps := a.Analyze(txs, depTestSigner)
+	if want := map[int][]int{0: {}, 1: {}, 2: {0, 1}}; !reflect.DeepEqual(deps, want) {
+		t.Fatalf("value transfer dependencies: have %v, want %v", deps, want)
+	}
+	txs = []*types.Transaction{
+		depTestTx(t, keys[0], 0, contract, 0, nil, list),
+		depTestTx(t, keys[1], 0, contract, 0, nil, list),
+	}
+	if deps := a.Analyze(txs, depTestSigner); !reflect.DeepEqual(deps[1], []int{0}) {
+		t.Fatalf("access list dependencies: have %v, want [0]", deps[1])
+	}
+}
+
+// Проверяет, что профиль функции выучивает слоты mapping по аргументам и
+// отправителю, а слоты только для чтения не создают зависимостей
+func TestAnalyzerLearnsProfile(t *testing.T) {
+	var (
+		keys     = depTestKeys(4)
+		contract = common.HexToAddress("0xc0")
+		config   = common.Hash{5} // Слот, который функция только читает
+		selector = crypto.Keccak256([]byte("move(address,uint256)"))[:4]
+		a        = NewDependencyAnalyzer(0)
+	)
+	// move(to, amount) переносит значение из balances[msg.sender] в
+	// balances[to], где balances - mapping в слоте 3
+	call := func(to common.Address) []byte {
+		return append(append(common.CopyBytes(selector), common.LeftPadBytes(to.Bytes(), 32)...), make([]byte, 32)...)
+	}
+	balance := func(owner common.Address) common.Hash {
+		return crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), common.Hash{31: 3}.Bytes())
+	}
+	addr := func(i int) common.Address { return crypto.PubkeyToAddress(keys[i].PublicKey) }
+	list := types.AccessList{{Address: contract, StorageKeys: []common.Hash{config}}}
+
+	for i := 0; i < minProfileCalls; i++ {
+		from, to := common.Address{byte(i + 1)}, common.Address{byte(i + 0x10)}
+		tx := depTestTx(t, keys[0], uint64(i), contract, 0, call(to), nil)
+		slots := []StateKey{
+			{Address: contract, Kind: StateKeyStorage, Slot: balance(from)},
+			{Address: contract, Kind: StateKeyStorage, Slot: balance(to)},
+		}
+		reads := append(slots, StateKey{Address: contract, Kind: StateKeyStorage, Slot: config})
+		a.Learn(tx, from, reads, slots)
+	}
+	tx := depTestTx(t, keys[0], 0, contract, 0, call(addr(1)), list)
+	as := a.AccessSet(tx, depTestSigner)
+	for _, slot := range []common.Hash{balance(addr(0)), balance(addr(1))} {
+		if r := as.Records[contract][slot]; r == nil || !r.IsWrite {
+			t.Errorf("balance slot %x not predicted as written: %+v", slot, r)
+		}
+	}
+	if r := as.Records[contract][config]; r == nil || r.IsWrite {
+		t.Errorf("read-only slot from access list predicted as %+v", r)
+	}
+	// 0 -> 1 и 2 -> 3 независимы, 3 -> 1 конфликтует с первым по балансу 1
+	txs := []*types.Transaction{
+		depTestTx(t, keys[0], 0, contract, 0, call(addr(1)), list),
+		depTestTx(t, keys[2], 0, contract, 0, call(addr(3)), list),
+		depTestTx(t, keys[3], 0, contract, 0, call(addr(1)), list),
+	}
+	deps := a.Analyze(txs, depTestSigner)
+	if want := map[int][]int{0: {}, 1: {}, 2: {0, 1}}; !reflect.DeepEqual(deps, want) {
+		t.Fatalf("dependencies: have %v, want %v", deps, want)
+	}
+	if groups := GroupTransactions(txs, deps); len(groups) != 2 || len(groups[0]) != 2 {
+		t.Fatalf("groups: have %v, want [[0 1] [2]]", groups)
+	}
+}
+
+// Проверяет разбор вывода prestateTracer в обоих режимах
+func TestAccessSetFromPrestate(t *testing.T) {
+	var (
+		sender   = common.HexToAddress("0x01")
+		contract = common.HexToAddress("0xc0")
+		read     = common.Hash{1}
+		written  = common.Hash{2}
+	)
+	prestate, _ := json.Marshal(map[common.Address]*prestateAccount{
+		sender:   {Nonce: 1},
+		contract: {Storage: map[common.Hash]common.Hash{read: {1}, written: {2}}},
+	})
+	diff := []byte(`{"pre":{"` + sender.Hex() + `":{"nonce":1},"` + contract.Hex() + `":{"storage":{"` + written.Hex() + `":"` + common.Hash{2}.Hex() + `"}}},` +
+		`"post":{"` + sender.Hex() + `":{"nonce":2}}}`)
+
+	as, err := AccessSetFromPrestate(prestate, diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := as.Records[contract][read]; r == nil || !r.IsRead || r.IsWrite {
+		t.Errorf("read slot: %+v", r)
+	}
+	if r := as.Records[contract][written]; r == nil || !r.IsWrite {
+		t.Errorf("cleared slot: %+v", r)
+	}
+	if r := as.Records[sender][accountFieldSlot("nonce", sender)]; r == nil || !r.IsWrite {
+		t.Errorf("sender nonce: %+v", r)
+	}
+	if r := as.Records[sender][accountFieldSlot("balance", sender)]; r == nil || r.IsWrite {
+		t.Errorf("sender balance: %+v", r)
+	}
+}
+
+// Проверяет, что прибавки к балансу без чтения не создают фактических
+// зависимостей между собой, но от всех них зависит читающая транзакция
+func TestActualDependencies(t *testing.T) {
+	var (
+		coinbase = StateKey{Address: common.Address{0xcb}, Kind: StateKeyBalance}
+		slot     = StateKey{Address: common.Address{0xc0}, Kind: StateKeyStorage, Slot: common.Hash{1}}
+		destruct = StateKey{Address: common.Address{0xc0}, Kind: StateKeyDestruct}
+	)
+	reads := [][]StateKey{{}, {slot}, {}, {coinbase, slot}}
+	writes := [][]StateKey{{coinbase}, {coinbase, slot}, {coinbase, destruct}, {}}
+	deps := ActualDependencies(reads, writes)
+	if want := map[int][]int{0: {}, 1: {}, 2: {}, 3: {0, 1, 2}}; !reflect.DeepEqual(deps, want) {
+		t.Fatalf("dependencies: have %v, want %v", deps, want)
+	}
+	conflicts := ConflictsByAddress(reads, writes)
+	if want := map[common.Address]int{coinbase.Address: 1, slot.Address: 1}; !reflect.DeepEqual(conflicts, want) {
+		t.Fatalf("conflicts: have %v, want %v", conflicts, want)
+	}
+}
@@ -18,6 +18,7 @@ package vm
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -217,67 +218,116 @@ func (pvm *ParallelEVM) ProcessTransactions(txs []*types.Transaction) ([]*Execut
 		return allResults, nil
 	}
 	
-	dependencies := pvm.OptimizedDependencyAnalysis(txs)
+	dependencies := pvm.analyzeDependencies(txs)
 	results := make([]*ExecutionResult, len(txs))
-	
-	// Используем минимальное кол-во групп для максимального параллелизма
-	groups := pvm.identifyParallelGroups(dependencies)
-	
-	// Инициализация задач
-	pvm.pool.WaitGroup.Add(len(txs))
-	pvm.pool.TaskCount = len(txs)
-	pvm.pool.CompletedTasks = 0
-	
-	// Обрабатываем транзакции по группам (транзакции в группе независимы)
-	for _, group := range groups {
-		for _, txIndex := range group {
+	reads, writes := make([][]StateKey, len(txs)), make([][]StateKey, len(txs))
+
+	// Транзакции выполняются раундами. В раунде общее состояние только
+	// читается, и каждая транзакция пишет в собственный оверлей. Затем
+	// оверлеи сливаются в состояние в порядке транзакций; транзакция,
+	// прочитавшая запись слитой в этом раунде транзакции, и все следующие
+	// выполняются заново в следующем раунде.
+	for next := 0; next < len(txs); {
+		var (
+			shared   = NewSharedState(pvm.statedb)
+			ready    = readyTransactions(next, len(txs), dependencies)
+			overlays = make([]*OverlayStateDB, len(txs)-next)
+			round    = make([]*ExecutionResult, len(txs)-next)
+			pending  = make(map[int]chan *ExecutionResult, len(ready))
+		)
+		pvm.pool.TaskCount = len(ready)
+		pvm.pool.CompletedTasks = 0
+
+		for _, txIndex := range ready {
 			tx := txs[txIndex]
-			
+			overlays[txIndex-next] = NewOverlayStateDB(shared)
+			overlays[txIndex-next].SetTxHash(tx.Hash())
+
 			msg, err := tx.AsMessage(types.MakeSigner(pvm.chainConfig, pvm.blockCtx.BlockNumber), pvm.blockCtx.BaseFee)
 			if err != nil {
-				results[txIndex] = &ExecutionResult{
+				round[txIndex-next] = &ExecutionResult{
 					TxIndex: txIndex,
 					Err:     err,
 				}
-				pvm.pool.WaitGroup.Done()
 				continue
 			}
-			
-			// Создаем копию состояния для каждой транзакции
-			txStateDB := pvm.statedb.Copy()
-			
 			completionChan := make(chan *ExecutionResult, 1)
-			
-			task := &TxExecutionTask{
+			pending[txIndex] = completionChan
+
+			pvm.pool.WaitGroup.Add(1)
+			pvm.pool.TaskQueue <- &TxExecutionTask{
 				TxIndex:        txIndex,
 				Tx:             tx,
 				Message:        &msg,
 				Context:        pvm.blockCtx,
-				StateDB:        txStateDB,
+				StateDB:        overlays[txIndex-next],
 				Config:         pvm.config,
 				ChainConfig:    pvm.chainConfig,
 				DependsOn:      dependencies[txIndex],
 				CompletionChan: completionChan,
 			}
-			
-			// Добавить задачу в очередь
-			pvm.pool.TaskQueue <- task
-			
-			// Обработка результата в отдельной горутине
-			go func(index int, ch chan *ExecutionResult) {
-				result := <-ch
-				results[index] = result
-				close(ch)
-			}(txIndex, completionChan)
 		}
+		// Ожидание завершения задач раунда
+		pvm.pool.WaitGroup.Wait()
+		for txIndex, ch := range pending {
+			round[txIndex-next] = <-ch
+		}
+
+		merged := MergeOverlays(pvm.statedb, overlays, nil)
+		copy(results[next:], round[:merged])
+		for i, overlay := range overlays[:merged] {
+			reads[next+i], writes[next+i] = overlay.ReadSet(), overlay.WriteSet()
+		}
+		next += merged
 	}
-	
-	// Ожидание завершения всех задач
-	pvm.pool.WaitGroup.Wait()
-	
+	pvm.learnDependencies(txs, dependencies, reads, writes)
 	return results, nil
 }
 
+// signer возвращает подписанта транзакций блока
+func (pvm *ParallelEVM) signer() types.Signer {
+	return types.MakeSigner(pvm.chainConfig, pvm.blockCtx.BlockNumber, pvm.blockCtx.Time)
+}
+
+// analyzeDependencies предсказывает зависимости транзакций анализатором из
+// конфигурации, а при его отсутствии - по отправителям и получателям
+func (pvm *ParallelEVM) analyzeDependencies(txs []*types.Transaction) map[int][]int {
+	if a := pvm.config.DependencyAnalyzer; a != nil {
+		return a.Analyze(txs, pvm.signer())
+	}
+	return pvm.OptimizedDependencyAnalysis(txs)
+}
+
+// learnDependencies сравнивает предсказанные зависимости с фактическими и
+// обучает анализатор на наборах доступа выполненных транзакций
+func (pvm *ParallelEVM) learnDependencies(txs []*types.Transaction, predicted map[int][]int, reads, writes [][]StateKey) {
+	ReportConflicts(predicted, ActualDependencies(reads, writes), len(txs))
+
+	a := pvm.config.DependencyAnalyzer
+	if a == nil {
+		return
+	}
+	signer := pvm.signer()
+	for i, tx := range txs {
+		if sender, err := types.Sender(signer, tx); err == nil {
+			a.Learn(tx, sender, reads[i], writes[i])
+		}
+	}
+}
+
+// readyTransactions возвращает транзакции начиная с next, все предсказанные
+// зависимости которых уже слиты в состояние. Транзакция next готова всегда,
+// поэтому каждый раунд сливает хотя бы одну транзакцию.
+func readyTransactions(next, n int, dependencies map[int][]int) []int {
+	var ready []int
+	for i := next; i < n; i++ {
+		if !slices.ContainsFunc(dependencies[i], func(dep int) bool { return dep >= next }) {
+			ready = append(ready, i)
+		}
+	}
+	return ready
+}
+
 // OptimizedDependencyAnalysis выполняет оптимизированный анализ зависимостей
 func (pvm *ParallelEVM) OptimizedDependencyAnalysis(txs []*types.Transaction) map[int][]int {
 	// Создаем карту зависимостей
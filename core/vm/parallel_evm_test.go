@@ -135,6 +135,7 @@ func (s *mockStateDB) GetCodeSize(addr common.Address) int {
 func (s *mockStateDB) AddRefund(gas uint64) {}
 func (s *mockStateDB) SubRefund(gas uint64) {}
 func (s *mockStateDB) GetRefund() uint64    { return 0 }
+func (s *mockStateDB) TxHash() common.Hash  { return common.Hash{} }
 
 func (s *mockStateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 	return s.getAccount(addr).storage[hash]
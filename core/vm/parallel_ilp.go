@@ -0,0 +1,326 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+// Параллелизм на уровне инструкций (ILP) внутри кадра вызова. Базовые блоки
+// кода (codeBlocks) делятся на участки из чистых стековых инструкций:
+// арифметики, сравнений, битовых операций, PUSHn, DUPn, SWAPn и POP. Такие
+// инструкции не читают память, состояние и окружение, не переходят и имеют
+// постоянную стоимость, поэтому участок полностью описывается графом потока
+// данных между элементами стека. Граф строится символическим выполнением
+// участка один раз для кода и делится на уровни: узлы одного уровня зависят
+// только от входного стека, констант и узлов предыдущих уровней и вычисляются
+// независимо.
+//
+// Выполнение участка эквивалентно последовательному. Суммарная стоимость и
+// допустимая длина стека на входе известны заранее: если газа не хватает или
+// стек переполнится либо опустеет внутри участка, участок выполняется обычным
+// циклом интерпретатора, который вернет ту же ошибку на той же инструкции.
+// Узлы вычисляются спекулятивно во временном буфере; стек и газ изменяются
+// только после вычисления всех уровней, а при ошибке участок откатывается и
+// выполняется последовательно.
+
+// ilpParallelWidth - минимальное число узлов уровня, начиная с которого
+// уровень вычисляется несколькими горутинами. Более узкие уровни дешевле
+// вычислить в текущей горутине.
+var ilpParallelWidth = 16
+
+// ilpValueKind - происхождение значения символического стека
+type ilpValueKind uint8
+
+const (
+	ilpInputValue ilpValueKind = iota // Элемент стека на входе в участок
+	ilpConstValue                     // Константа PUSHn
+	ilpNodeValue                      // Результат узла графа
+)
+
+// ilpValue - значение символического стека участка
+type ilpValue struct {
+	kind  ilpValueKind
+	index int         // Глубина элемента на входе (0 - вершина) или номер узла
+	value uint256.Int // Значение константы
+}
+
+// ilpNode - вычисляющая инструкция участка
+type ilpNode struct {
+	op   *operation
+	args []ilpValue // Операнды в порядке снятия со стека, args[0] - вершина
+}
+
+// ilpSegment - участок базового блока из чистых стековых инструкций
+type ilpSegment struct {
+	end      uint64     // Позиция инструкции, следующей за участком
+	gas      uint64     // Суммарная постоянная стоимость инструкций участка
+	inputs   int        // Число верхних элементов стека, которые читает участок
+	maxStack int        // Максимальная длина стека на входе без переполнения
+	nodes    []ilpNode  // Узлы графа в порядке выполнения
+	levels   [][]int    // Номера узлов по уровням графа
+	outputs  []ilpValue // Верхние элементы стека на выходе, последний - вершина
+}
+
+// ilpPure проверяет, является ли инструкция чистой стековой: ее результат
+// зависит только от операндов на стеке. EXP исключается, так как его
+// стоимость зависит от показателя.
+func ilpPure(op OpCode) bool {
+	switch {
+	case op >= ADD && op <= SIGNEXTEND:
+		return op != EXP
+	case op >= LT && op <= SAR:
+		return true
+	case op == POP, op >= PUSH0 && op <= PUSH32, op >= DUP1 && op <= SWAP16:
+		return true
+	}
+	return false
+}
+
+// planSegments строит участки кода, которые имеет смысл выполнять
+// параллельно, по позициям их первых инструкций
+func planSegments(code []byte, table *JumpTable) map[uint64]*ilpSegment {
+	segments := make(map[uint64]*ilpSegment)
+	for _, block := range codeBlocks(code) {
+		for pc := block.start; pc < block.end; {
+			seg, next := planSegment(code, table, pc, block.end)
+			if seg != nil {
+				segments[pc] = seg
+			}
+			pc = next
+		}
+	}
+	return segments
+}
+
+// planSegment символически выполняет чистые инструкции, начиная с start, до
+// первой другой инструкции или конца блока end. Возвращает участок, если в
+// его графе есть хотя бы два независимых узла, и позицию, с которой
+// продолжается разбор блока.
+func planSegment(code []byte, table *JumpTable, start, end uint64) (*ilpSegment, uint64) {
+	var (
+		seg    = &ilpSegment{maxStack: math.MaxInt}
+		stack  []ilpValue // Символический стек, вершина в конце
+		levels []int      // Уровни узлов
+		pc     = start
+	)
+	// need дополняет символический стек снизу элементами входного стека
+	need := func(n int) {
+		for len(stack) < n {
+			stack = append([]ilpValue{{kind: ilpInputValue, index: seg.inputs}}, stack...)
+			seg.inputs++
+		}
+	}
+	for pc < end {
+		op := OpCode(code[pc])
+		operation := table[op]
+		if !ilpPure(op) || operation.undefined || operation.dynamicGas != nil || operation.memorySize != nil {
+			break
+		}
+		// Длина стека перед инструкцией равна длине на входе плюс depth
+		depth := len(stack) - seg.inputs
+		seg.maxStack = min(seg.maxStack, operation.maxStack-depth)
+		need(operation.minStack)
+		seg.gas += operation.constantGas
+
+		switch {
+		case op >= PUSH0 && op <= PUSH32:
+			size := int(op - PUSH0)
+			stack = append(stack, ilpValue{kind: ilpConstValue, value: pushValue(code, pc, size)})
+			pc += uint64(size)
+		case op >= DUP1 && op <= DUP16:
+			stack = append(stack, stack[len(stack)-int(op-DUP1)-1])
+		case op >= SWAP1 && op <= SWAP16:
+			top, other := len(stack)-1, len(stack)-int(op-SWAP1)-2
+			stack[top], stack[other] = stack[other], stack[top]
+		case op == POP:
+			stack = stack[:len(stack)-1]
+		default:
+			node := ilpNode{op: operation, args: make([]ilpValue, operation.minStack)}
+			level := 0
+			for i := range node.args {
+				node.args[i] = stack[len(stack)-1-i]
+				if node.args[i].kind == ilpNodeValue {
+					level = max(level, levels[node.args[i].index]+1)
+				}
+			}
+			stack = append(stack[:len(stack)-operation.minStack], ilpValue{kind: ilpNodeValue, index: len(seg.nodes)})
+			seg.nodes = append(seg.nodes, node)
+			levels = append(levels, level)
+			if level == len(seg.levels) {
+				seg.levels = append(seg.levels, nil)
+			}
+			seg.levels[level] = append(seg.levels[level], len(seg.nodes)-1)
+		}
+		pc++
+	}
+	if pc == start {
+		// Первая инструкция не чистая: пропускаем ее вместе с непосредственными данными
+		op := OpCode(code[pc])
+		switch {
+		case op >= PUSH1 && op <= PUSH32:
+			return nil, pc + 1 + uint64(op-PUSH0)
+		case op == QUANTUM:
+			return nil, pc + 2
+		}
+		return nil, pc + 1
+	}
+	seg.end = pc
+	seg.outputs = stack
+	for _, level := range seg.levels {
+		if len(level) > 1 {
+			return seg, pc
+		}
+	}
+	return nil, pc
+}
+
+// pushValue возвращает константу инструкции PUSHn по позиции pc так же, как
+// makePush: недостающие в конце кода байты считаются нулевыми
+func pushValue(code []byte, pc uint64, size int) uint256.Int {
+	var (
+		start = min(len(code), int(pc+1))
+		end   = min(len(code), start+size)
+		value uint256.Int
+	)
+	value.SetBytes(code[start:end])
+	if missing := size - (end - start); missing > 0 {
+		value.Lsh(&value, uint(8*missing))
+	}
+	return value
+}
+
+// parallelSegments проверяет, можно ли выполнять участки кода параллельно.
+// Трассировка требует пошагового выполнения, а по EIP-4762 инструкции
+// дополнительно оплачивают доступ к фрагментам кода.
+func (in *EVMInterpreter) parallelSegments() bool {
+	return in.isParallel && in.evm.Config.HyperParallelMode && !in.evm.Config.Debug &&
+		in.evm.Config.Tracer == nil && !in.evm.chainRules.IsEIP4762
+}
+
+// segmentPlan возвращает участки кода контракта. Разбор кода развернутых
+// контрактов кешируется по хешу кода.
+func (in *EVMInterpreter) segmentPlan(contract *Contract) map[uint64]*ilpSegment {
+	if contract.CodeHash == (common.Hash{}) {
+		return planSegments(contract.Code, in.table)
+	}
+	if plan, ok := in.segments[contract.CodeHash]; ok {
+		return plan
+	}
+	if in.segments == nil {
+		in.segments = make(map[common.Hash]map[uint64]*ilpSegment)
+	}
+	plan := planSegments(contract.Code, in.table)
+	in.segments[contract.CodeHash] = plan
+	return plan
+}
+
+// runSegment выполняет участок над стеком кадра и списывает его стоимость.
+// Возвращает false, не изменяя стек и газ, если участок нужно выполнить
+// последовательно.
+func (in *EVMInterpreter) runSegment(seg *ilpSegment, contract *Contract, stack *Stack) bool {
+	sLen := stack.len()
+	if sLen < seg.inputs || sLen > seg.maxStack || contract.Gas < seg.gas {
+		return false
+	}
+	var (
+		inputs  = stack.data[sLen-seg.inputs:]
+		results = make([]uint256.Int, len(seg.nodes))
+	)
+	resolve := func(v *ilpValue) *uint256.Int {
+		switch v.kind {
+		case ilpInputValue:
+			return &inputs[len(inputs)-1-v.index]
+		case ilpConstValue:
+			return &v.value
+		}
+		return &results[v.index]
+	}
+	eval := func(scratch *ScopeContext, n int) error {
+		node := &seg.nodes[n]
+		for i := len(node.args) - 1; i >= 0; i-- {
+			scratch.Stack.push(resolve(&node.args[i]))
+		}
+		var pc uint64
+		if _, err := node.op.execute(&pc, in, scratch); err != nil {
+			return err
+		}
+		results[n] = scratch.Stack.pop()
+		return nil
+	}
+	for _, level := range seg.levels {
+		if err := in.runLevel(level, eval); err != nil {
+			return false
+		}
+	}
+	// Все узлы вычислены: фиксируем результат участка
+	outputs := make([]uint256.Int, len(seg.outputs))
+	for i := range seg.outputs {
+		outputs[i] = *resolve(&seg.outputs[i])
+	}
+	stack.data = append(stack.data[:sLen-seg.inputs], outputs...)
+	contract.Gas -= seg.gas
+	return true
+}
+
+// runLevel вычисляет независимые узлы уровня, распределяя их между
+// горутинами, если уровень достаточно широк
+func (in *EVMInterpreter) runLevel(level []int, eval func(*ScopeContext, int) error) error {
+	workers := min(in.numThreads, len(level))
+	if len(level) < ilpParallelWidth || workers < 2 {
+		scratch := &ScopeContext{Stack: newstack()}
+		defer returnStack(scratch.Stack)
+
+		for _, n := range level {
+			if err := eval(scratch, n); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, workers)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			scratch := &ScopeContext{Stack: newstack()}
+			defer returnStack(scratch.Stack)
+
+			for i := w; i < len(level); i += workers {
+				if errs[w] = eval(scratch, level[i]); errs[w] != nil {
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
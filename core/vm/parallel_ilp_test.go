@@ -0,0 +1,133 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Проверяет граф потока данных участка: независимые узлы попадают на один
+// уровень, а участки ограничиваются нечистыми инструкциями
+func TestPlanSegments(t *testing.T) {
+	code := []byte{
+		byte(PUSH1), 1, byte(PUSH1), 2, byte(ADD), // узел 0, уровень 0
+		byte(PUSH1), 3, byte(DUP3), byte(MUL), // узел 1 над входом, уровень 0
+		byte(SUB),              // узел 2, уровень 1
+		byte(SWAP1), byte(POP), // вход заменяется результатом
+		byte(MSTORE),
+		byte(PUSH1), 1, byte(ISZERO), // один узел, участок не выделяется
+		byte(JUMPDEST),
+		byte(DUP1), byte(NOT), byte(DUP2), byte(NOT), byte(EXP), // EXP завершает участок
+	}
+	segments := planSegments(code, &cancunInstructionSet)
+	if len(segments) != 2 {
+		t.Fatalf("planned %d segments, want 2", len(segments))
+	}
+	seg := segments[0]
+	if seg == nil || seg.end != 12 {
+		t.Fatalf("first segment: %+v", seg)
+	}
+	if seg.inputs != 1 || len(seg.outputs) != 1 || !reflect.DeepEqual(seg.levels, [][]int{{0, 1}, {2}}) {
+		t.Errorf("first segment: inputs %d, outputs %d, levels %v", seg.inputs, len(seg.outputs), seg.levels)
+	}
+	if want := uint64(3 + 3 + 3 + 3 + 5 + 3 + 3 + 3 + 2); seg.gas != want {
+		t.Errorf("first segment gas: have %d, want %d", seg.gas, want)
+	}
+	if seg := segments[17]; seg == nil || seg.end != 21 || seg.inputs != 1 || len(seg.outputs) != 3 {
+		t.Errorf("second segment: %+v", seg)
+	}
+}
+
+// ilpTestCode переводит байты фаззера в код из чистых стековых инструкций,
+// перемежаемых инструкциями, которые ограничивают участки или читают
+// оставшийся газ. Код начинается с заполнения стека и возвращает четыре
+// верхних элемента стека.
+func ilpTestCode(input []byte) []byte {
+	ops := []OpCode{
+		ADD, SUB, MUL, DIV, SDIV, MOD, SMOD, ADDMOD, MULMOD, EXP, SIGNEXTEND,
+		LT, GT, SLT, SGT, EQ, ISZERO, AND, OR, XOR, NOT, BYTE, SHL, SHR, SAR,
+		POP, PUSH0, PUSH1, PUSH2, PUSH32, DUP1, DUP2, DUP4, DUP16, SWAP1, SWAP3, SWAP16,
+		JUMPDEST, GAS,
+	}
+	var code []byte
+	for i := 0; i < 16; i++ {
+		code = append(code, byte(PUSH2), byte(i), 0xff)
+	}
+	code = append(code, byte(JUMPDEST))
+	for i, b := range input {
+		op := ops[int(b)%len(ops)]
+		code = append(code, byte(op))
+		if op.IsPush() {
+			for j := 0; j < int(op-PUSH0); j++ {
+				code = append(code, byte(i*7+j)^b)
+			}
+		}
+	}
+	for i := 0; i < 4; i++ {
+		code = append(code, byte(PUSH1), byte(32*i), byte(MSTORE))
+	}
+	return append(code, byte(PUSH1), 0x80, byte(PUSH1), 0, byte(RETURN))
+}
+
+// FuzzParallelSegments сравнивает выполнение случайного кода с
+// параллельными участками и без них
+func FuzzParallelSegments(f *testing.F) {
+	// Параллельно вычисляются и узкие уровни графа
+	width := ilpParallelWidth
+	ilpParallelWidth = 2
+	f.Cleanup(func() { ilpParallelWidth = width })
+
+	f.Add([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8}, uint64(100000))
+	f.Add([]byte{27, 27, 0, 27, 27, 0, 27, 27, 0, 27, 27, 0, 0, 0, 0}, uint64(100000))
+	f.Add([]byte{30, 31, 7, 33, 34, 10, 36, 37, 38, 20, 26, 29}, uint64(420))
+	f.Fuzz(func(t *testing.T, input []byte, gas uint64) {
+		if len(input) > 4096 {
+			return
+		}
+		var (
+			address = common.BytesToAddress([]byte("contract"))
+			code    = ilpTestCode(input)
+			vmctx   = BlockContext{
+				Transfer: func(StateDB, common.Address, common.Address, *uint256.Int) {},
+			}
+		)
+		run := func(config Config) ([]byte, uint64, string) {
+			statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+			statedb.CreateAccount(address)
+			statedb.SetCode(address, code)
+			statedb.Finalise(true)
+
+			evm := NewEVM(vmctx, statedb, params.AllEthashProtocolChanges, config)
+			ret, left, err := evm.Call(common.Address{}, address, nil, gas%1_000_000, new(uint256.Int))
+			return ret, left, fmt.Sprint(err)
+		}
+		wantRet, wantGas, wantErr := run(Config{})
+		haveRet, haveGas, haveErr := run(Config{EnableParallelExecution: true, ParallelThreads: 4, HyperParallelMode: true})
+		if !bytes.Equal(haveRet, wantRet) || haveGas != wantGas || haveErr != wantErr {
+			t.Fatalf("code %x: have (%x, %d, %s), want (%x, %d, %s)", code, haveRet, haveGas, haveErr, wantRet, wantGas, wantErr)
+		}
+	})
+}
@@ -0,0 +1,348 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// StateKeyKind - вид элемента состояния в наборах чтения и записи
+type StateKeyKind uint8
+
+const (
+	StateKeyExist    StateKeyKind = iota // Существование аккаунта (bool)
+	StateKeyBalance                      // Баланс (*uint256.Int, полное значение или прибавка)
+	StateKeyNonce                        // Nonce (uint64)
+	StateKeyCode                         // Код (*mvCodeValue)
+	StateKeyDestruct                     // Удаление аккаунта в блоке (bool), очищает хранилище
+	StateKeyStorage                      // Ячейка хранилища (common.Hash)
+)
+
+// StateKey - элемент состояния: единица наборов чтения и записи транзакции и
+// ключ версий в многоверсионной памяти
+type StateKey struct {
+	Address common.Address
+	Kind    StateKeyKind
+	Slot    common.Hash // Только для StateKeyStorage
+}
+
+// mvCodeValue - код аккаунта вместе с его хэшем
+type mvCodeValue struct {
+	code []byte
+	hash common.Hash
+}
+
+// emptyCode - код аккаунта без кода
+var emptyCode = &mvCodeValue{hash: types.EmptyCodeHash}
+
+// mvWrite - значение, записанное транзакцией
+type mvWrite struct {
+	value interface{}
+	delta bool // value - прибавка к балансу, а не полное значение
+}
+
+// mvEntry - версия элемента, записанная одной инкарнацией транзакции
+type mvEntry struct {
+	incarnation int
+	mvWrite
+	estimate bool // Запись устарела: транзакция прервана и будет выполнена заново
+}
+
+// mvCell хранит версии одного элемента, упорядоченные по номеру транзакции
+type mvCell struct {
+	mu      sync.RWMutex
+	txs     []int // Отсортированные номера транзакций, записавших элемент
+	entries map[int]*mvEntry
+}
+
+// walk обходит версии, записанные транзакциями с номером меньше txIdx, от
+// последней к первой, пока fn возвращает true
+func (c *mvCell) walk(txIdx int, fn func(j int, e *mvEntry) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for i := sort.SearchInts(c.txs, txIdx) - 1; i >= 0; i-- {
+		if !fn(c.txs[i], c.entries[c.txs[i]]) {
+			return
+		}
+	}
+}
+
+// mvMemory - многоверсионная память Block-STM. Для каждого элемента состояния
+// она хранит значения, записанные транзакциями блока, так что транзакция i
+// читает запись ближайшей предыдущей транзакции j < i, а при ее отсутствии -
+// состояние на начало блока.
+type mvMemory struct {
+	cells     sync.Map // StateKey -> *mvCell
+	registers sync.Map // Обязательство -> закодированный квантовый регистр
+
+	lastWrites []atomic.Pointer[[]StateKey] // Элементы, записанные последней инкарнацией
+}
+
+func newMVMemory(n int) *mvMemory {
+	return &mvMemory{lastWrites: make([]atomic.Pointer[[]StateKey], n)}
+}
+
+// cell возвращает ячейку элемента, создавая ее при необходимости
+func (m *mvMemory) cell(key StateKey) *mvCell {
+	if c, ok := m.cells.Load(key); ok {
+		return c.(*mvCell)
+	}
+	c, _ := m.cells.LoadOrStore(key, &mvCell{entries: make(map[int]*mvEntry)})
+	return c.(*mvCell)
+}
+
+// lookup возвращает ячейку элемента или nil, если его никто не записывал
+func (m *mvMemory) lookup(key StateKey) *mvCell {
+	if c, ok := m.cells.Load(key); ok {
+		return c.(*mvCell)
+	}
+	return nil
+}
+
+// record сохраняет записи инкарнации транзакции и удаляет записи предыдущей
+// инкарнации, которые она не повторила. Возвращает true, если записан
+// элемент, которого не было среди записей предыдущей инкарнации: в этом
+// случае ранее проверенные транзакции могли прочитать устаревшее значение.
+func (m *mvMemory) record(txIdx, incarnation int, writes map[StateKey]mvWrite) bool {
+	var prev []StateKey
+	if p := m.lastWrites[txIdx].Load(); p != nil {
+		prev = *p
+	}
+	keys := make([]StateKey, 0, len(writes))
+	for key, w := range writes {
+		c := m.cell(key)
+		c.mu.Lock()
+		if _, ok := c.entries[txIdx]; !ok {
+			i := sort.SearchInts(c.txs, txIdx)
+			c.txs = append(c.txs, 0)
+			copy(c.txs[i+1:], c.txs[i:])
+			c.txs[i] = txIdx
+		}
+		c.entries[txIdx] = &mvEntry{incarnation: incarnation, mvWrite: w}
+		c.mu.Unlock()
+		keys = append(keys, key)
+	}
+	for _, key := range prev {
+		if _, ok := writes[key]; ok {
+			continue
+		}
+		c := m.cell(key)
+		c.mu.Lock()
+		if _, ok := c.entries[txIdx]; ok {
+			delete(c.entries, txIdx)
+			i := sort.SearchInts(c.txs, txIdx)
+			c.txs = append(c.txs[:i], c.txs[i+1:]...)
+		}
+		c.mu.Unlock()
+	}
+	m.lastWrites[txIdx].Store(&keys)
+
+	if len(keys) > len(prev) {
+		return true
+	}
+	known := make(map[StateKey]struct{}, len(prev))
+	for _, key := range prev {
+		known[key] = struct{}{}
+	}
+	for _, key := range keys {
+		if _, ok := known[key]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// convertToEstimates помечает записи прерванной транзакции как устаревшие.
+// Транзакции, читающие их, ожидают повторного выполнения вместо того, чтобы
+// продолжать с заведомо неверным значением.
+func (m *mvMemory) convertToEstimates(txIdx int) {
+	p := m.lastWrites[txIdx].Load()
+	if p == nil {
+		return
+	}
+	for _, key := range *p {
+		c := m.cell(key)
+		c.mu.Lock()
+		if e, ok := c.entries[txIdx]; ok {
+			estimate := *e
+			estimate.estimate = true
+			c.entries[txIdx] = &estimate
+		}
+		c.mu.Unlock()
+	}
+}
+
+// resolve возвращает значение элемента, которое видит транзакция txIdx: запись
+// ближайшей предыдущей транзакции или значение из base. Если нужная запись
+// устарела, возвращается номер транзакции, которую следует дождаться, иначе
+// -1. Для StateKeyStorage учитывается удаление аккаунта предыдущими транзакциями.
+func (m *mvMemory) resolve(base StateReader, key StateKey, txIdx int) (interface{}, int) {
+	switch key.Kind {
+	case StateKeyBalance:
+		return m.resolveBalance(base, key, txIdx)
+	case StateKeyStorage:
+		return m.resolveStorage(base, key, txIdx)
+	}
+	var (
+		value interface{}
+		dep   = -1
+	)
+	if c := m.lookup(key); c != nil {
+		c.walk(txIdx, func(j int, e *mvEntry) bool {
+			if e.estimate {
+				dep = j
+			} else {
+				value = e.value
+			}
+			return false
+		})
+	}
+	if dep >= 0 || value != nil {
+		return value, dep
+	}
+	return readBase(base, key), -1
+}
+
+// readBase возвращает значение элемента в base
+func readBase(base StateReader, key StateKey) interface{} {
+	switch key.Kind {
+	case StateKeyExist:
+		return base.Exist(key.Address)
+	case StateKeyBalance:
+		return base.GetBalance(key.Address)
+	case StateKeyNonce:
+		return base.GetNonce(key.Address)
+	case StateKeyCode:
+		if !base.Exist(key.Address) {
+			return emptyCode
+		}
+		return &mvCodeValue{code: base.GetCode(key.Address), hash: base.GetCodeHash(key.Address)}
+	case StateKeyDestruct:
+		return false
+	case StateKeyStorage:
+		return base.GetState(key.Address, key.Slot)
+	}
+	panic("vm: unknown state key kind")
+}
+
+// resolveBalance складывает прибавки к балансу, записанные без чтения, с
+// последним полным значением
+func (m *mvMemory) resolveBalance(base StateReader, key StateKey, txIdx int) (interface{}, int) {
+	var (
+		sum  = new(uint256.Int)
+		full *uint256.Int
+		dep  = -1
+	)
+	if c := m.lookup(key); c != nil {
+		c.walk(txIdx, func(j int, e *mvEntry) bool {
+			switch {
+			case e.estimate:
+				dep = j
+				return false
+			case e.delta:
+				sum.Add(sum, e.value.(*uint256.Int))
+				return true
+			default:
+				full = e.value.(*uint256.Int)
+				return false
+			}
+		})
+	}
+	if dep >= 0 {
+		return nil, dep
+	}
+	if full == nil {
+		full = readBase(base, key).(*uint256.Int)
+	}
+	return sum.Add(sum, full), -1
+}
+
+// resolveStorage возвращает значение ячейки с учетом удаления аккаунта: если
+// последнее удаление новее последней записи ячейки, хранилище очищено
+func (m *mvMemory) resolveStorage(base StateReader, key StateKey, txIdx int) (interface{}, int) {
+	var (
+		slotIdx, destructIdx = -1, -1
+		slot                 *mvEntry
+		destruct             *mvEntry
+	)
+	if c := m.lookup(key); c != nil {
+		c.walk(txIdx, func(j int, e *mvEntry) bool {
+			slotIdx, slot = j, e
+			return false
+		})
+	}
+	if c := m.lookup(StateKey{Address: key.Address, Kind: StateKeyDestruct}); c != nil {
+		c.walk(txIdx, func(j int, e *mvEntry) bool {
+			destructIdx, destruct = j, e
+			return false
+		})
+	}
+	switch {
+	case slotIdx > destructIdx:
+		if slot.estimate {
+			return nil, slotIdx
+		}
+		return slot.value, -1
+	case destructIdx >= 0:
+		if destruct.estimate {
+			return nil, destructIdx
+		}
+		return common.Hash{}, -1
+	}
+	return readBase(base, key), -1
+}
+
+// register возвращает закодированный квантовый регистр по обязательству,
+// записанный одной из транзакций блока
+func (m *mvMemory) register(commitment common.Hash) []byte {
+	if blob, ok := m.registers.Load(commitment); ok {
+		return blob.([]byte)
+	}
+	return nil
+}
+
+// equalValues сравнивает значения элемента, прочитанные при выполнении и
+// при проверке транзакции
+func equalValues(kind StateKeyKind, a, b interface{}) bool {
+	switch kind {
+	case StateKeyBalance:
+		return a.(*uint256.Int).Eq(b.(*uint256.Int))
+	case StateKeyCode:
+		return a.(*mvCodeValue).hash == b.(*mvCodeValue).hash
+	default:
+		return a == b
+	}
+}
+
+// validate проверяет, что значения, прочитанные последней инкарнацией
+// транзакции, не изменились к текущему моменту
+func (m *mvMemory) validate(base StateReader, txIdx int, reads map[StateKey]interface{}) bool {
+	for key, read := range reads {
+		value, dep := m.resolve(base, key, txIdx)
+		if dep >= 0 || !equalValues(key.Kind, read, value) {
+			return false
+		}
+	}
+	return true
+}
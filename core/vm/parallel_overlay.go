@@ -0,0 +1,220 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// StateReader - чтения состояния, на которые опирается OverlayStateDB. Их
+// реализуют state.StateDB и любая реализация StateDB.
+type StateReader interface {
+	Exist(common.Address) bool
+	GetBalance(common.Address) *uint256.Int
+	GetNonce(common.Address) uint64
+	GetCode(common.Address) []byte
+	GetCodeHash(common.Address) common.Hash
+	GetState(common.Address, common.Hash) common.Hash
+	GetStorageRoot(common.Address) common.Hash
+	GetQuantumRegister(common.Address) []byte
+}
+
+// StateWriter - состояние, в которое переносятся записи транзакций,
+// выполненных параллельно
+type StateWriter interface {
+	StateReader
+
+	CreateAccount(common.Address)
+	SelfDestruct(common.Address) uint256.Int
+	SubBalance(common.Address, *uint256.Int, tracing.BalanceChangeReason) uint256.Int
+	AddBalance(common.Address, *uint256.Int, tracing.BalanceChangeReason) uint256.Int
+	SetNonce(common.Address, uint64, tracing.NonceChangeReason)
+	SetCode(common.Address, []byte) []byte
+	SetState(common.Address, common.Hash, common.Hash) common.Hash
+	SetQuantumRegister(common.Address, []byte) []byte
+	AddLog(*types.Log)
+	AddPreimage(common.Hash, []byte)
+	Finalise(bool)
+}
+
+// SharedState - общее состояние, которое параллельные оверлеи только читают.
+// state.StateDB кэширует объекты даже при чтении и не допускает
+// конкурентного доступа, поэтому чтения сериализуются. Пока оверлеи
+// выполняются, состояние не должно изменяться.
+type SharedState struct {
+	mu sync.Mutex
+	db StateReader
+}
+
+// NewSharedState оборачивает db для конкурентного чтения
+func NewSharedState(db StateReader) *SharedState {
+	return &SharedState{db: db}
+}
+
+func (s *SharedState) Exist(addr common.Address) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.Exist(addr)
+}
+
+func (s *SharedState) GetBalance(addr common.Address) *uint256.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return new(uint256.Int).Set(s.db.GetBalance(addr))
+}
+
+func (s *SharedState) GetNonce(addr common.Address) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.GetNonce(addr)
+}
+
+func (s *SharedState) GetCode(addr common.Address) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.GetCode(addr)
+}
+
+func (s *SharedState) GetCodeHash(addr common.Address) common.Hash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.GetCodeHash(addr)
+}
+
+func (s *SharedState) GetState(addr common.Address, key common.Hash) common.Hash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.GetState(addr, key)
+}
+
+func (s *SharedState) GetStorageRoot(addr common.Address) common.Hash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.GetStorageRoot(addr)
+}
+
+func (s *SharedState) GetQuantumRegister(addr common.Address) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.GetQuantumRegister(addr)
+}
+
+// NewOverlayStateDB создает оверлей одной транзакции поверх base. Оверлеи
+// транзакций, выполняемых параллельно, должны читать общее состояние через
+// SharedState.
+func NewOverlayStateDB(base StateReader) *OverlayStateDB {
+	return newOverlayStateDB(nil, base, 0)
+}
+
+// sortedKeys возвращает ключи в детерминированном порядке
+func sortedKeys[V any](set map[StateKey]V) []StateKey {
+	keys := slices.Collect(maps.Keys(set))
+	slices.SortFunc(keys, func(a, b StateKey) int {
+		if c := bytes.Compare(a.Address[:], b.Address[:]); c != 0 {
+			return c
+		}
+		if a.Kind != b.Kind {
+			return int(a.Kind) - int(b.Kind)
+		}
+		return bytes.Compare(a.Slot[:], b.Slot[:])
+	})
+	return keys
+}
+
+// ReadSet возвращает элементы состояния, прочитанные транзакцией из base
+func (s *OverlayStateDB) ReadSet() []StateKey {
+	return sortedKeys(s.reads)
+}
+
+// WriteSet возвращает элементы состояния, записанные транзакцией. Записи
+// формируются при Finalise.
+func (s *OverlayStateDB) WriteSet() []StateKey {
+	return sortedKeys(s.writes)
+}
+
+// Conflicts сообщает, прочитала ли транзакция элемент, записанный prev.
+// Если prev предшествует транзакции, то при последовательном выполнении
+// она увидела бы другое состояние. Оба оверлея должны быть завершены
+// вызовом Finalise.
+func (s *OverlayStateDB) Conflicts(prev *OverlayStateDB) bool {
+	return s.readsAny(prev.writes)
+}
+
+// readsAny сообщает, прочитала ли транзакция один из элементов writes.
+// Ячейку хранилища изменяет и удаление аккаунта.
+func (s *OverlayStateDB) readsAny(writes map[StateKey]mvWrite) bool {
+	for key := range s.reads {
+		if _, ok := writes[key]; ok {
+			return true
+		}
+		if key.Kind == StateKeyStorage {
+			if _, ok := writes[StateKey{Address: key.Address, Kind: StateKeyDestruct}]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Merge завершает транзакцию вызовом Finalise с удалением пустых аккаунтов
+// (EIP-158) и переносит ее записи, журналы и прообразы в dst. Результат
+// совпадает с последовательным выполнением, если dst не изменился с тех
+// пор, как транзакция его читала, в части ее набора чтения.
+func (s *OverlayStateDB) Merge(dst StateWriter) {
+	s.Finalise(true)
+	applyWrites(dst, s.writes, s.quantumWrites, s.logs, s.preimages)
+}
+
+// MergeOverlays переносит в dst оверлеи транзакций, выполненных параллельно
+// поверх dst, в порядке транзакций. Слияние останавливается на первом
+// оверлее, равном nil или прочитавшем элемент, который записал один из уже
+// слитых оверлеев: такая транзакция видела устаревшее состояние и вместе со
+// всеми следующими должна быть выполнена заново поверх обновленного dst.
+// prepare, если не nil, вызывается перед слиянием i-го оверлея, например
+// чтобы выставить контекст транзакции для журналов. Возвращает число слитых
+// оверлеев.
+func MergeOverlays(dst StateWriter, overlays []*OverlayStateDB, prepare func(i int)) int {
+	// Все оверлеи завершаются до слияния: Finalise читает base, который
+	// слияние изменяет
+	n := slices.Index(overlays, nil)
+	if n < 0 {
+		n = len(overlays)
+	}
+	for _, s := range overlays[:n] {
+		s.Finalise(true)
+	}
+	written := make(map[StateKey]mvWrite)
+	for i, s := range overlays[:n] {
+		if s.readsAny(written) {
+			return i
+		}
+		if prepare != nil {
+			prepare(i)
+		}
+		s.Merge(dst)
+		maps.Copy(written, s.writes)
+	}
+	return n
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/holiman/uint256"
+)
+
+// Проверяет, что параллельное выполнение в оверлеях со слиянием по порядку
+// и повторным выполнением конфликтующих транзакций дает последовательный
+// результат
+func TestOverlayMergeMatchesSequential(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		rnd := rand.New(rand.NewSource(seed))
+		txs := make([]stmTestTx, 40)
+		for i := range txs {
+			txs[i] = randomSTMTx(rnd, i)
+		}
+		hash := func(i int) common.Hash { return common.BytesToHash([]byte{byte(i), 2}) }
+
+		seq := newSTMTestState(t)
+		var want []interface{}
+		for i, tx := range txs {
+			seq.SetTxContext(hash(i), i)
+			result, _ := tx(seq)
+			seq.Finalise(true)
+			want = append(want, result)
+		}
+
+		par := newSTMTestState(t)
+		have := make([]interface{}, len(txs))
+		for next, rounds := 0, 0; next < len(txs); rounds++ {
+			if rounds >= len(txs) {
+				t.Fatalf("seed %d: no progress after %d rounds", seed, rounds)
+			}
+			var (
+				shared   = NewSharedState(par)
+				overlays = make([]*OverlayStateDB, len(txs)-next)
+				results  = make([]interface{}, len(txs)-next)
+				wg       sync.WaitGroup
+			)
+			for i := range overlays {
+				overlays[i] = NewOverlayStateDB(shared)
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], _ = txs[next+i](overlays[i])
+				}(i)
+			}
+			wg.Wait()
+
+			merged := MergeOverlays(par, overlays, func(i int) { par.SetTxContext(hash(next+i), next+i) })
+			if merged == 0 {
+				t.Fatalf("seed %d: first overlay of round %d not merged", seed, rounds)
+			}
+			copy(have[next:], results[:merged])
+			next += merged
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Fatalf("seed %d: results mismatch: have %v, want %v", seed, have, want)
+		}
+		if have, want := par.IntermediateRoot(true), seq.IntermediateRoot(true); have != want {
+			t.Fatalf("seed %d: state root mismatch: have %x, want %x", seed, have, want)
+		}
+		for i := range txs {
+			if have, want := par.GetLogs(hash(i), 0, common.Hash{}), seq.GetLogs(hash(i), 0, common.Hash{}); !reflect.DeepEqual(have, want) {
+				t.Fatalf("seed %d: tx %d logs mismatch: have %v, want %v", seed, i, have, want)
+			}
+		}
+	}
+}
+
+// Проверяет наборы чтения и записи и обнаружение конфликтов при слиянии
+func TestOverlayConflicts(t *testing.T) {
+	var (
+		statedb  = newSTMTestState(t)
+		shared   = NewSharedState(statedb)
+		counter  = stmTestAccounts[0]
+		slot     = common.Hash{1}
+		coinbase = stmTestCoinbase
+		fee      = uint256.NewInt(5)
+	)
+	incr := func(db *OverlayStateDB) {
+		v := db.GetState(counter, slot)
+		v[31]++
+		db.SetState(counter, slot, v)
+		db.AddBalance(coinbase, fee, tracing.BalanceIncreaseRewardTransactionFee)
+	}
+	transfer := func(db *OverlayStateDB) {
+		db.SubBalance(stmTestAccounts[1], uint256.NewInt(1), tracing.BalanceChangeTransfer)
+		db.AddBalance(stmTestAccounts[3], uint256.NewInt(1), tracing.BalanceChangeTransfer)
+		db.AddBalance(coinbase, fee, tracing.BalanceIncreaseRewardTransactionFee)
+	}
+	overlays := make([]*OverlayStateDB, 3)
+	for i, tx := range []func(*OverlayStateDB){incr, transfer, incr} {
+		overlays[i] = NewOverlayStateDB(shared)
+		tx(overlays[i])
+		overlays[i].Finalise(true)
+	}
+	wantWrites := []StateKey{
+		{Address: counter, Kind: StateKeyStorage, Slot: slot},
+		{Address: coinbase, Kind: StateKeyBalance},
+	}
+	if have := overlays[0].WriteSet(); !reflect.DeepEqual(have, wantWrites) {
+		t.Fatalf("write set mismatch: have %v, want %v", have, wantWrites)
+	}
+	for _, key := range overlays[0].ReadSet() {
+		if key.Address == coinbase && key.Kind == StateKeyBalance {
+			t.Fatal("blind fee payment read the coinbase balance")
+		}
+	}
+	if overlays[1].Conflicts(overlays[0]) {
+		t.Error("transfer conflicts with counter increment")
+	}
+	if !overlays[2].Conflicts(overlays[0]) {
+		t.Error("second counter increment does not conflict with the first")
+	}
+	if merged := MergeOverlays(statedb, overlays, nil); merged != 2 {
+		t.Fatalf("merged overlays: have %d, want 2", merged)
+	}
+	// Третья транзакция выполняется заново поверх слитого состояния
+	retry := NewOverlayStateDB(NewSharedState(statedb))
+	incr(retry)
+	if merged := MergeOverlays(statedb, []*OverlayStateDB{retry}, nil); merged != 1 {
+		t.Fatalf("retried overlay not merged")
+	}
+	if have := statedb.GetState(counter, slot); have != (common.Hash{31: 2}) {
+		t.Errorf("counter: have %x, want 2", have)
+	}
+	if have := statedb.GetBalance(coinbase); have.Uint64() != 1+3*fee.Uint64() {
+		t.Errorf("coinbase balance: have %d, want %d", have, 1+3*fee.Uint64())
+	}
+}
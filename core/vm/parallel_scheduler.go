@@ -0,0 +1,240 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// stmStatus - состояние транзакции в планировщике Block-STM
+type stmStatus uint8
+
+const (
+	stmReadyToExecute stmStatus = iota
+	stmExecuting
+	stmExecuted
+	stmAborting
+)
+
+// stmTaskKind - вид задачи планировщика
+type stmTaskKind uint8
+
+const (
+	stmNoTask stmTaskKind = iota
+	stmExecution
+	stmValidation
+)
+
+// stmTask - задача выполнения или проверки инкарнации транзакции
+type stmTask struct {
+	kind        stmTaskKind
+	txIdx       int
+	incarnation int
+}
+
+// stmTx - состояние транзакции и транзакции, ожидающие ее выполнения
+type stmTx struct {
+	mu           sync.Mutex
+	status       stmStatus
+	incarnation  int
+	dependencies []int
+}
+
+// stmScheduler - совместный планировщик Block-STM (Gelashvili et al., 2022).
+// Задачи выполнения и проверки выдаются по возрастанию номера транзакции;
+// прерванная транзакция получает новую инкарнацию, а проверка всех
+// последующих транзакций повторяется.
+type stmScheduler struct {
+	n   int
+	txs []stmTx
+
+	executionIdx   atomic.Int64
+	validationIdx  atomic.Int64
+	decreaseCnt    atomic.Int64
+	numActiveTasks atomic.Int64
+	done           atomic.Bool
+}
+
+func newSTMScheduler(n int) *stmScheduler {
+	return &stmScheduler{n: n, txs: make([]stmTx, n)}
+}
+
+// decreaseIdx уменьшает счетчик до target, если он больше
+func (s *stmScheduler) decreaseIdx(idx *atomic.Int64, target int) {
+	for {
+		cur := idx.Load()
+		if cur <= int64(target) || idx.CompareAndSwap(cur, int64(target)) {
+			break
+		}
+	}
+	s.decreaseCnt.Add(1)
+}
+
+// checkDone завершает работу, когда все транзакции выполнены и проверены, а
+// активных задач нет
+func (s *stmScheduler) checkDone() {
+	observed := s.decreaseCnt.Load()
+	if min(s.executionIdx.Load(), s.validationIdx.Load()) >= int64(s.n) &&
+		s.numActiveTasks.Load() == 0 && observed == s.decreaseCnt.Load() {
+		s.done.Store(true)
+	}
+}
+
+// tryIncarnate начинает выполнение очередной инкарнации транзакции, если она
+// готова к выполнению
+func (s *stmScheduler) tryIncarnate(txIdx int) stmTask {
+	if txIdx < s.n {
+		tx := &s.txs[txIdx]
+		tx.mu.Lock()
+		if tx.status == stmReadyToExecute {
+			tx.status = stmExecuting
+			task := stmTask{kind: stmExecution, txIdx: txIdx, incarnation: tx.incarnation}
+			tx.mu.Unlock()
+			return task
+		}
+		tx.mu.Unlock()
+	}
+	s.numActiveTasks.Add(-1)
+	return stmTask{}
+}
+
+func (s *stmScheduler) nextVersionToExecute() stmTask {
+	if s.executionIdx.Load() >= int64(s.n) {
+		s.checkDone()
+		return stmTask{}
+	}
+	s.numActiveTasks.Add(1)
+	return s.tryIncarnate(int(s.executionIdx.Add(1) - 1))
+}
+
+func (s *stmScheduler) nextVersionToValidate() stmTask {
+	if s.validationIdx.Load() >= int64(s.n) {
+		s.checkDone()
+		return stmTask{}
+	}
+	s.numActiveTasks.Add(1)
+	if idx := int(s.validationIdx.Add(1) - 1); idx < s.n {
+		tx := &s.txs[idx]
+		tx.mu.Lock()
+		status, incarnation := tx.status, tx.incarnation
+		tx.mu.Unlock()
+		if status == stmExecuted {
+			return stmTask{kind: stmValidation, txIdx: idx, incarnation: incarnation}
+		}
+	}
+	s.numActiveTasks.Add(-1)
+	return stmTask{}
+}
+
+// nextTask выдает очередную задачу, отдавая предпочтение проверке
+func (s *stmScheduler) nextTask() stmTask {
+	if s.validationIdx.Load() < s.executionIdx.Load() {
+		return s.nextVersionToValidate()
+	}
+	return s.nextVersionToExecute()
+}
+
+// addDependency откладывает транзакцию txIdx до завершения выполнения
+// blocking. Возвращает false, если blocking уже выполнена и txIdx следует
+// выполнить повторно сразу.
+func (s *stmScheduler) addDependency(txIdx, blocking int) bool {
+	blocker := &s.txs[blocking]
+	blocker.mu.Lock()
+	if blocker.status == stmExecuted {
+		blocker.mu.Unlock()
+		return false
+	}
+	tx := &s.txs[txIdx]
+	tx.mu.Lock()
+	tx.status = stmAborting
+	tx.mu.Unlock()
+	blocker.dependencies = append(blocker.dependencies, txIdx)
+	blocker.mu.Unlock()
+
+	s.numActiveTasks.Add(-1)
+	return true
+}
+
+// setReady готовит новую инкарнацию прерванной транзакции
+func (s *stmScheduler) setReady(txIdx int) {
+	tx := &s.txs[txIdx]
+	tx.mu.Lock()
+	tx.incarnation++
+	tx.status = stmReadyToExecute
+	tx.mu.Unlock()
+}
+
+// finishExecution отмечает инкарнацию выполненной, возобновляет ожидавшие ее
+// транзакции и при необходимости сразу возвращает задачу ее проверки
+func (s *stmScheduler) finishExecution(txIdx, incarnation int, wroteNewLocation bool) stmTask {
+	tx := &s.txs[txIdx]
+	tx.mu.Lock()
+	tx.status = stmExecuted
+	deps := tx.dependencies
+	tx.dependencies = nil
+	tx.mu.Unlock()
+
+	if len(deps) > 0 {
+		lowest := deps[0]
+		for _, dep := range deps {
+			s.setReady(dep)
+			lowest = min(lowest, dep)
+		}
+		s.decreaseIdx(&s.executionIdx, lowest)
+	}
+	if s.validationIdx.Load() > int64(txIdx) {
+		if !wroteNewLocation {
+			return stmTask{kind: stmValidation, txIdx: txIdx, incarnation: incarnation}
+		}
+		s.decreaseIdx(&s.validationIdx, txIdx)
+	}
+	s.numActiveTasks.Add(-1)
+	return stmTask{}
+}
+
+// tryValidationAbort прерывает инкарнацию, не прошедшую проверку. Только
+// одна из конкурирующих проверок одной инкарнации успешно прерывает ее.
+func (s *stmScheduler) tryValidationAbort(txIdx, incarnation int) bool {
+	tx := &s.txs[txIdx]
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.incarnation == incarnation && tx.status == stmExecuted {
+		tx.status = stmAborting
+		return true
+	}
+	return false
+}
+
+// finishValidation завершает проверку; прерванная транзакция выполняется
+// заново, а последующие транзакции проверяются повторно
+func (s *stmScheduler) finishValidation(txIdx int, aborted bool) stmTask {
+	if aborted {
+		s.setReady(txIdx)
+		s.decreaseIdx(&s.validationIdx, txIdx+1)
+		if s.executionIdx.Load() > int64(txIdx) {
+			if task := s.tryIncarnate(txIdx); task.kind != stmNoTask {
+				return task
+			}
+			// tryIncarnate уже уменьшил число активных задач
+			return stmTask{}
+		}
+	}
+	s.numActiveTasks.Add(-1)
+	return stmTask{}
+}
@@ -0,0 +1,706 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie/utils"
+	"github.com/holiman/uint256"
+)
+
+// stmDependency - паника, которой прерывается выполнение транзакции,
+// прочитавшей устаревшую запись транзакции blocking
+type stmDependency struct {
+	blocking int
+}
+
+// stmObject - аккаунт в состоянии выполняемой транзакции. Поля, равные nil,
+// еще не читались и не изменялись транзакцией.
+type stmObject struct {
+	exists         bool
+	created        bool // Аккаунт создан в транзакции, прежние поля обнулены
+	newContract    bool
+	selfDestructed bool
+
+	balance *uint256.Int
+	delta   *uint256.Int // Прибавка к непрочитанному балансу
+	nonce   *uint64
+	code    *mvCodeValue
+	storage map[common.Hash]common.Hash // Ячейки, отличающиеся от значений на начало транзакции
+}
+
+func (o *stmObject) copy() *stmObject {
+	cpy := *o
+	cpy.storage = maps.Clone(o.storage)
+	return &cpy
+}
+
+// stmJournalEntry - изменение, отменяемое при откате вызова
+type stmJournalEntry struct {
+	addr   *common.Address // Аккаунт, помечаемый измененным
+	revert func()
+}
+
+// OverlayStateDB - копируемое при записи состояние одной транзакции поверх
+// общего состояния, которое только читается. Чтения проходят к base (в
+// Block-STM - через многоверсионную память) и запоминаются вместе с
+// прочитанными значениями, образуя точный набор чтения; записи накапливаются
+// локально и переносятся в набор записи при Finalise.
+//
+// Семантика методов повторяет state.StateDB в пределах транзакции, включая
+// журнал изменений, список доступа, временное хранилище и возвраты газа.
+// Прибавка к балансу аккаунта, баланс которого транзакция не читала
+// (например, комиссия получателю блока), записывается без чтения, поэтому
+// AddBalance в этом случае возвращает нулевой прежний баланс.
+type OverlayStateDB struct {
+	mv    *mvMemory // nil, если транзакция читает base напрямую
+	base  StateReader
+	txIdx int
+	thash common.Hash
+
+	reads   map[StateKey]interface{} // Значения на начало транзакции
+	objects map[common.Address]*stmObject
+
+	journal   []stmJournalEntry
+	dirties   map[common.Address]int
+	revisions []int
+
+	registers map[common.Hash][]byte           // Квантовые регистры по обязательству
+	quantum   map[common.Address]struct{}      // Аккаунты, регистр которых заменен
+	transient map[common.Address]state.Storage // Временное хранилище EIP-1153
+	access    map[common.Address]map[common.Hash]struct{}
+	refund    uint64
+	logs      []*types.Log
+	preimages map[common.Hash][]byte
+
+	writes        map[StateKey]mvWrite      // Записи транзакции, заполняются в Finalise
+	quantumWrites map[common.Address][]byte // Замененные квантовые регистры, заполняются в Finalise
+}
+
+func newOverlayStateDB(mv *mvMemory, base StateReader, txIdx int) *OverlayStateDB {
+	return &OverlayStateDB{
+		mv:        mv,
+		base:      base,
+		txIdx:     txIdx,
+		reads:     make(map[StateKey]interface{}),
+		objects:   make(map[common.Address]*stmObject),
+		dirties:   make(map[common.Address]int),
+		registers: make(map[common.Hash][]byte),
+		quantum:   make(map[common.Address]struct{}),
+		transient: make(map[common.Address]state.Storage),
+		access:    make(map[common.Address]map[common.Hash]struct{}),
+		preimages: make(map[common.Hash][]byte),
+		writes:    make(map[StateKey]mvWrite),
+
+		quantumWrites: make(map[common.Address][]byte),
+	}
+}
+
+// read возвращает значение элемента на начало транзакции и запоминает его
+func (s *OverlayStateDB) read(key StateKey) interface{} {
+	if value, ok := s.reads[key]; ok {
+		return value
+	}
+	if s.mv == nil {
+		value := readBase(s.base, key)
+		s.reads[key] = value
+		return value
+	}
+	value, dep := s.mv.resolve(s.base, key, s.txIdx)
+	if dep >= 0 {
+		panic(stmDependency{blocking: dep})
+	}
+	s.reads[key] = value
+	return value
+}
+
+// append добавляет запись в журнал
+func (s *OverlayStateDB) append(addr *common.Address, revert func()) {
+	s.journal = append(s.journal, stmJournalEntry{addr: addr, revert: revert})
+	if addr != nil {
+		s.dirties[*addr]++
+	}
+}
+
+// getObject возвращает существующий аккаунт или nil
+func (s *OverlayStateDB) getObject(addr common.Address) *stmObject {
+	o, ok := s.objects[addr]
+	if !ok {
+		o = &stmObject{exists: s.read(StateKey{Address: addr, Kind: StateKeyExist}).(bool)}
+		s.objects[addr] = o
+	}
+	if !o.exists {
+		return nil
+	}
+	return o
+}
+
+// getOrNewObject возвращает аккаунт, создавая его при отсутствии
+func (s *OverlayStateDB) getOrNewObject(addr common.Address) *stmObject {
+	if o := s.getObject(addr); o != nil {
+		return o
+	}
+	return s.createObject(addr)
+}
+
+// createObject заменяет аккаунт новым пустым аккаунтом
+func (s *OverlayStateDB) createObject(addr common.Address) *stmObject {
+	prev := s.objects[addr]
+	s.append(&addr, func() { s.objects[addr] = prev })
+
+	var nonce uint64
+	o := &stmObject{
+		exists:  true,
+		created: true,
+		balance: new(uint256.Int),
+		nonce:   &nonce,
+		code:    emptyCode,
+		storage: make(map[common.Hash]common.Hash),
+	}
+	s.objects[addr] = o
+	return o
+}
+
+// modify журналирует текущее содержимое аккаунта перед изменением и
+// возвращает изменяемую копию
+func (s *OverlayStateDB) modify(addr common.Address) *stmObject {
+	prev := s.objects[addr]
+	s.append(&addr, func() { s.objects[addr] = prev })
+	o := prev.copy()
+	s.objects[addr] = o
+	return o
+}
+
+func (s *OverlayStateDB) balanceOf(addr common.Address, o *stmObject) *uint256.Int {
+	if o.balance == nil {
+		balance := new(uint256.Int).Set(s.read(StateKey{Address: addr, Kind: StateKeyBalance}).(*uint256.Int))
+		if o.delta != nil {
+			balance.Add(balance, o.delta)
+			o.delta = nil
+		}
+		o.balance = balance
+	}
+	return o.balance
+}
+
+func (s *OverlayStateDB) nonceOf(addr common.Address, o *stmObject) uint64 {
+	if o.nonce == nil {
+		nonce := s.read(StateKey{Address: addr, Kind: StateKeyNonce}).(uint64)
+		o.nonce = &nonce
+	}
+	return *o.nonce
+}
+
+func (s *OverlayStateDB) codeOf(addr common.Address, o *stmObject) *mvCodeValue {
+	if o.code == nil {
+		o.code = s.read(StateKey{Address: addr, Kind: StateKeyCode}).(*mvCodeValue)
+	}
+	return o.code
+}
+
+// empty сообщает, пуст ли аккаунт по EIP-161. Непрочитанный баланс с
+// ненулевой прибавкой заведомо не пуст и не читается.
+func (s *OverlayStateDB) empty(addr common.Address, o *stmObject) bool {
+	if o.balance == nil && o.delta != nil && !o.delta.IsZero() {
+		return false
+	}
+	return s.nonceOf(addr, o) == 0 && s.balanceOf(addr, o).IsZero() && s.codeOf(addr, o).hash == types.EmptyCodeHash
+}
+
+func (s *OverlayStateDB) CreateAccount(addr common.Address) {
+	s.createObject(addr)
+}
+
+func (s *OverlayStateDB) CreateContract(addr common.Address) {
+	o := s.getObject(addr)
+	if o == nil || o.newContract {
+		return
+	}
+	// Флаг нового контракта не помечает аккаунт измененным
+	s.append(nil, func() { s.objects[addr] = o })
+	cpy := o.copy()
+	cpy.newContract = true
+	s.objects[addr] = cpy
+}
+
+func (s *OverlayStateDB) SubBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) uint256.Int {
+	o := s.getOrNewObject(addr)
+	prev := *s.balanceOf(addr, o)
+	if amount.IsZero() {
+		return prev
+	}
+	s.modify(addr).balance = new(uint256.Int).Sub(&prev, amount)
+	return prev
+}
+
+func (s *OverlayStateDB) AddBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) uint256.Int {
+	o := s.getOrNewObject(addr)
+	if amount.IsZero() {
+		if s.empty(addr, o) {
+			s.append(&addr, func() {})
+		}
+		if o.balance == nil {
+			return uint256.Int{}
+		}
+		return *o.balance
+	}
+	if o.balance == nil {
+		// Баланс не читался: прибавка записывается без чтения
+		m := s.modify(addr)
+		if o.delta == nil {
+			m.delta = new(uint256.Int).Set(amount)
+		} else {
+			m.delta = new(uint256.Int).Add(o.delta, amount)
+		}
+		return uint256.Int{}
+	}
+	prev := *o.balance
+	s.modify(addr).balance = new(uint256.Int).Add(&prev, amount)
+	return prev
+}
+
+func (s *OverlayStateDB) GetBalance(addr common.Address) *uint256.Int {
+	if o := s.getObject(addr); o != nil {
+		return s.balanceOf(addr, o)
+	}
+	return common.U2560
+}
+
+func (s *OverlayStateDB) GetNonce(addr common.Address) uint64 {
+	if o := s.getObject(addr); o != nil {
+		return s.nonceOf(addr, o)
+	}
+	return 0
+}
+
+func (s *OverlayStateDB) SetNonce(addr common.Address, nonce uint64, reason tracing.NonceChangeReason) {
+	s.getOrNewObject(addr)
+	s.modify(addr).nonce = &nonce
+}
+
+func (s *OverlayStateDB) GetCodeHash(addr common.Address) common.Hash {
+	if o := s.getObject(addr); o != nil {
+		return s.codeOf(addr, o).hash
+	}
+	return common.Hash{}
+}
+
+func (s *OverlayStateDB) GetCode(addr common.Address) []byte {
+	if o := s.getObject(addr); o != nil {
+		return s.codeOf(addr, o).code
+	}
+	return nil
+}
+
+func (s *OverlayStateDB) SetCode(addr common.Address, code []byte) []byte {
+	o := s.getOrNewObject(addr)
+	prev := s.codeOf(addr, o).code
+	s.modify(addr).code = &mvCodeValue{code: code, hash: crypto.Keccak256Hash(code)}
+	return prev
+}
+
+func (s *OverlayStateDB) GetCodeSize(addr common.Address) int {
+	return len(s.GetCode(addr))
+}
+
+func (s *OverlayStateDB) GetQuantumRegister(addr common.Address) []byte {
+	commitment := s.GetState(addr, state.QuantumRegisterSlot)
+	if commitment == (common.Hash{}) {
+		return nil
+	}
+	if register, ok := s.registers[commitment]; ok {
+		return register
+	}
+	if s.mv != nil {
+		if register := s.mv.register(commitment); register != nil {
+			return register
+		}
+	}
+	if s.base.GetState(addr, state.QuantumRegisterSlot) == commitment {
+		return s.base.GetQuantumRegister(addr)
+	}
+	return nil
+}
+
+func (s *OverlayStateDB) SetQuantumRegister(addr common.Address, register []byte) []byte {
+	prev := s.GetQuantumRegister(addr)
+	var commitment common.Hash
+	if len(register) != 0 {
+		commitment = crypto.Keccak256Hash(register)
+		s.registers[commitment] = register
+	}
+	if _, ok := s.quantum[addr]; !ok {
+		s.quantum[addr] = struct{}{}
+		s.append(&addr, func() { delete(s.quantum, addr) })
+	}
+	s.SetState(addr, state.QuantumRegisterSlot, commitment)
+	return prev
+}
+
+func (s *OverlayStateDB) AddRefund(gas uint64) {
+	prev := s.refund
+	s.append(nil, func() { s.refund = prev })
+	s.refund += gas
+}
+
+func (s *OverlayStateDB) SubRefund(gas uint64) {
+	prev := s.refund
+	s.append(nil, func() { s.refund = prev })
+	if gas > s.refund {
+		panic(fmt.Sprintf("Refund counter below zero (gas: %d > refund: %d)", gas, s.refund))
+	}
+	s.refund -= gas
+}
+
+func (s *OverlayStateDB) GetRefund() uint64 {
+	return s.refund
+}
+
+// SetTxHash задает хэш выполняемой транзакции, возвращаемый TxHash
+func (s *OverlayStateDB) SetTxHash(thash common.Hash) {
+	s.thash = thash
+}
+
+func (s *OverlayStateDB) TxHash() common.Hash {
+	return s.thash
+}
+
+func (s *OverlayStateDB) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	if s.getObject(addr) == nil {
+		return common.Hash{}
+	}
+	return s.read(StateKey{Address: addr, Kind: StateKeyStorage, Slot: key}).(common.Hash)
+}
+
+func (s *OverlayStateDB) GetState(addr common.Address, key common.Hash) common.Hash {
+	o := s.getObject(addr)
+	if o == nil {
+		return common.Hash{}
+	}
+	if value, dirty := o.storage[key]; dirty {
+		return value
+	}
+	return s.read(StateKey{Address: addr, Kind: StateKeyStorage, Slot: key}).(common.Hash)
+}
+
+func (s *OverlayStateDB) SetState(addr common.Address, key, value common.Hash) common.Hash {
+	s.getOrNewObject(addr)
+	prev := s.GetState(addr, key)
+	if prev == value {
+		return prev
+	}
+	o := s.modify(addr)
+	if o.storage == nil {
+		o.storage = make(map[common.Hash]common.Hash)
+	}
+	if value == s.GetCommittedState(addr, key) {
+		delete(o.storage, key)
+	} else {
+		o.storage[key] = value
+	}
+	return prev
+}
+
+// GetStorageRoot возвращает корень хранилища на начало блока: корни
+// пересчитываются только в конце блока, а у созданных и удаленных в блоке
+// аккаунтов хранилище пусто
+func (s *OverlayStateDB) GetStorageRoot(addr common.Address) common.Hash {
+	o := s.getObject(addr)
+	if o == nil {
+		return common.Hash{}
+	}
+	if o.created || s.read(StateKey{Address: addr, Kind: StateKeyDestruct}).(bool) {
+		return types.EmptyRootHash
+	}
+	if root := s.base.GetStorageRoot(addr); root != (common.Hash{}) {
+		return root
+	}
+	return types.EmptyRootHash
+}
+
+func (s *OverlayStateDB) GetTransientState(addr common.Address, key common.Hash) common.Hash {
+	return s.transient[addr][key]
+}
+
+func (s *OverlayStateDB) SetTransientState(addr common.Address, key, value common.Hash) {
+	prev := s.GetTransientState(addr, key)
+	if prev == value {
+		return
+	}
+	s.append(nil, func() { s.setTransientState(addr, key, prev) })
+	s.setTransientState(addr, key, value)
+}
+
+func (s *OverlayStateDB) setTransientState(addr common.Address, key, value common.Hash) {
+	if value == (common.Hash{}) {
+		delete(s.transient[addr], key)
+		return
+	}
+	if s.transient[addr] == nil {
+		s.transient[addr] = make(state.Storage)
+	}
+	s.transient[addr][key] = value
+}
+
+func (s *OverlayStateDB) SelfDestruct(addr common.Address) uint256.Int {
+	o := s.getObject(addr)
+	if o == nil {
+		return uint256.Int{}
+	}
+	prev := *s.balanceOf(addr, o)
+	if !prev.IsZero() {
+		s.modify(addr).balance = new(uint256.Int)
+	}
+	if !s.objects[addr].selfDestructed {
+		s.modify(addr).selfDestructed = true
+	}
+	return prev
+}
+
+func (s *OverlayStateDB) HasSelfDestructed(addr common.Address) bool {
+	if o := s.getObject(addr); o != nil {
+		return o.selfDestructed
+	}
+	return false
+}
+
+func (s *OverlayStateDB) SelfDestruct6780(addr common.Address) (uint256.Int, bool) {
+	o := s.getObject(addr)
+	if o == nil {
+		return uint256.Int{}, false
+	}
+	if o.newContract {
+		return s.SelfDestruct(addr), true
+	}
+	return *s.balanceOf(addr, o), false
+}
+
+func (s *OverlayStateDB) Exist(addr common.Address) bool {
+	return s.getObject(addr) != nil
+}
+
+func (s *OverlayStateDB) Empty(addr common.Address) bool {
+	o := s.getObject(addr)
+	return o == nil || s.empty(addr, o)
+}
+
+func (s *OverlayStateDB) AddressInAccessList(addr common.Address) bool {
+	_, ok := s.access[addr]
+	return ok
+}
+
+func (s *OverlayStateDB) SlotInAccessList(addr common.Address, slot common.Hash) (bool, bool) {
+	slots, ok := s.access[addr]
+	if !ok {
+		return false, false
+	}
+	_, slotOk := slots[slot]
+	return true, slotOk
+}
+
+func (s *OverlayStateDB) AddAddressToAccessList(addr common.Address) {
+	if _, ok := s.access[addr]; !ok {
+		s.access[addr] = nil
+		s.append(nil, func() { delete(s.access, addr) })
+	}
+}
+
+func (s *OverlayStateDB) AddSlotToAccessList(addr common.Address, slot common.Hash) {
+	s.AddAddressToAccessList(addr)
+	if _, ok := s.access[addr][slot]; ok {
+		return
+	}
+	if s.access[addr] == nil {
+		s.access[addr] = make(map[common.Hash]struct{})
+	}
+	s.access[addr][slot] = struct{}{}
+	s.append(nil, func() { delete(s.access[addr], slot) })
+}
+
+func (s *OverlayStateDB) PointCache() *utils.PointCache {
+	return nil
+}
+
+// Prepare повторяет state.StateDB.Prepare: список доступа EIP-2929/2930 и
+// сброс временного хранилища
+func (s *OverlayStateDB) Prepare(rules params.Rules, sender, coinbase common.Address, dest *common.Address, precompiles []common.Address, txAccesses types.AccessList) {
+	if rules.IsEIP2929 && rules.IsEIP4762 {
+		panic("eip2929 and eip4762 are both activated")
+	}
+	if rules.IsEIP2929 {
+		s.access = make(map[common.Address]map[common.Hash]struct{})
+		s.access[sender] = nil
+		if dest != nil {
+			s.access[*dest] = nil
+		}
+		for _, addr := range precompiles {
+			s.access[addr] = nil
+		}
+		for _, el := range txAccesses {
+			if _, ok := s.access[el.Address]; !ok {
+				s.access[el.Address] = nil
+			}
+			for _, key := range el.StorageKeys {
+				if s.access[el.Address] == nil {
+					s.access[el.Address] = make(map[common.Hash]struct{})
+				}
+				s.access[el.Address][key] = struct{}{}
+			}
+		}
+		if rules.IsShanghai {
+			if _, ok := s.access[coinbase]; !ok {
+				s.access[coinbase] = nil
+			}
+		}
+	}
+	s.transient = make(map[common.Address]state.Storage)
+}
+
+func (s *OverlayStateDB) Snapshot() int {
+	s.revisions = append(s.revisions, len(s.journal))
+	return len(s.revisions) - 1
+}
+
+func (s *OverlayStateDB) RevertToSnapshot(id int) {
+	if id < 0 || id >= len(s.revisions) {
+		panic(fmt.Errorf("revision id %v cannot be reverted", id))
+	}
+	size := s.revisions[id]
+	for i := len(s.journal) - 1; i >= size; i-- {
+		entry := s.journal[i]
+		entry.revert()
+		if entry.addr != nil {
+			if s.dirties[*entry.addr]--; s.dirties[*entry.addr] <= 0 {
+				delete(s.dirties, *entry.addr)
+			}
+		}
+	}
+	s.journal = s.journal[:size]
+	s.revisions = s.revisions[:id]
+}
+
+func (s *OverlayStateDB) AddLog(log *types.Log) {
+	size := len(s.logs)
+	s.append(nil, func() { s.logs = s.logs[:size] })
+	s.logs = append(s.logs, log)
+}
+
+func (s *OverlayStateDB) AddPreimage(hash common.Hash, preimage []byte) {
+	if _, ok := s.preimages[hash]; !ok {
+		s.preimages[hash] = slices.Clone(preimage)
+	}
+}
+
+func (s *OverlayStateDB) Witness() *stateless.Witness {
+	return nil
+}
+
+func (s *OverlayStateDB) AccessEvents() *state.AccessEvents {
+	return nil
+}
+
+// Finalise завершает транзакцию так же, как state.StateDB.Finalise:
+// самоуничтоженные и (при deleteEmptyObjects) пустые измененные аккаунты
+// удаляются, а изменения остальных переносятся в записи транзакции
+func (s *OverlayStateDB) Finalise(deleteEmptyObjects bool) {
+	for addr := range s.dirties {
+		o := s.objects[addr]
+		if o == nil || !o.exists {
+			continue
+		}
+		existed := s.read(StateKey{Address: addr, Kind: StateKeyExist}).(bool)
+		if o.selfDestructed || (deleteEmptyObjects && s.empty(addr, o)) {
+			// Удаление аккаунта, не существовавшего до транзакции, ничего
+			// не меняет
+			if existed {
+				s.writes[StateKey{Address: addr, Kind: StateKeyExist}] = mvWrite{value: false}
+				s.writes[StateKey{Address: addr, Kind: StateKeyBalance}] = mvWrite{value: new(uint256.Int)}
+				s.writes[StateKey{Address: addr, Kind: StateKeyNonce}] = mvWrite{value: uint64(0)}
+				s.writes[StateKey{Address: addr, Kind: StateKeyCode}] = mvWrite{value: emptyCode}
+				s.writes[StateKey{Address: addr, Kind: StateKeyDestruct}] = mvWrite{value: true}
+			}
+			s.objects[addr] = &stmObject{}
+			continue
+		}
+		if !existed {
+			s.writes[StateKey{Address: addr, Kind: StateKeyExist}] = mvWrite{value: true}
+		}
+		if o.created || !existed {
+			s.writes[StateKey{Address: addr, Kind: StateKeyBalance}] = mvWrite{value: o.balance}
+			s.writes[StateKey{Address: addr, Kind: StateKeyNonce}] = mvWrite{value: *o.nonce}
+			s.writes[StateKey{Address: addr, Kind: StateKeyCode}] = mvWrite{value: o.code}
+		} else {
+			if o.balance != nil && !o.balance.Eq(s.read(StateKey{Address: addr, Kind: StateKeyBalance}).(*uint256.Int)) {
+				s.writes[StateKey{Address: addr, Kind: StateKeyBalance}] = mvWrite{value: o.balance}
+			} else if o.delta != nil {
+				s.writes[StateKey{Address: addr, Kind: StateKeyBalance}] = mvWrite{value: o.delta, delta: true}
+			}
+			if o.nonce != nil && *o.nonce != s.read(StateKey{Address: addr, Kind: StateKeyNonce}).(uint64) {
+				s.writes[StateKey{Address: addr, Kind: StateKeyNonce}] = mvWrite{value: *o.nonce}
+			}
+			if o.code != nil && o.code.hash != s.read(StateKey{Address: addr, Kind: StateKeyCode}).(*mvCodeValue).hash {
+				s.writes[StateKey{Address: addr, Kind: StateKeyCode}] = mvWrite{value: o.code}
+			}
+		}
+		for key, value := range o.storage {
+			s.writes[StateKey{Address: addr, Kind: StateKeyStorage, Slot: key}] = mvWrite{value: value}
+		}
+		if _, ok := s.quantum[addr]; ok {
+			s.quantumWrites[addr] = s.GetQuantumRegister(addr)
+		}
+	}
+	s.journal = nil
+	s.revisions = nil
+	s.dirties = make(map[common.Address]int)
+	s.refund = 0
+}
+
+// Copy возвращает независимую копию состояния транзакции, читающую то же
+// состояние
+func (s *OverlayStateDB) Copy() StateDB {
+	cpy := newOverlayStateDB(s.mv, s.base, s.txIdx)
+	cpy.thash = s.thash
+	maps.Copy(cpy.reads, s.reads)
+	for addr, o := range s.objects {
+		cpy.objects[addr] = o.copy()
+	}
+	maps.Copy(cpy.dirties, s.dirties)
+	maps.Copy(cpy.registers, s.registers)
+	maps.Copy(cpy.quantum, s.quantum)
+	for addr, slots := range s.transient {
+		cpy.transient[addr] = slots.Copy()
+	}
+	for addr, slots := range s.access {
+		cpy.access[addr] = maps.Clone(slots)
+	}
+	cpy.refund = s.refund
+	cpy.logs = slices.Clone(s.logs)
+	maps.Copy(cpy.preimages, s.preimages)
+	maps.Copy(cpy.writes, s.writes)
+	maps.Copy(cpy.quantumWrites, s.quantumWrites)
+	return cpy
+}
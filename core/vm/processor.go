@@ -0,0 +1,166 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// DefaultQuantumProcessor is the processor created once the Quantum fork is
+// active, unless Config.Processor names another implementation.
+const DefaultQuantumProcessor = "quest"
+
+var (
+	// ErrUnknownProcessor is returned when the configuration selects a
+	// processor that has not been registered.
+	ErrUnknownProcessor = errors.New("unknown processor")
+
+	// ErrNoQuantumHandler is returned when the chain schedules the Quantum fork
+	// but the selected processor does not execute the QUANTUM instructions.
+	ErrNoQuantumHandler = errors.New("processor does not execute quantum instructions")
+)
+
+// Processor executes the code of call frames on behalf of the EVM. An
+// implementation usually handles a subset of contracts, such as the ones with
+// quantum instructions, and hands the rest to evm.Interpreter().
+//
+// A processor that also implements QuantumOpHandler is attached as the
+// executor of the QUANTUM instructions of its EVM.
+type Processor interface {
+	// Run executes the contract with the given input.
+	Run(contract *Contract, input []byte, readOnly bool) (ret []byte, err error)
+
+	// Close releases the resources held by the processor.
+	Close() error
+}
+
+// ProcessorFactory creates a processor bound to the given EVM. The settings of
+// the processor are taken from evm.Config.
+type ProcessorFactory func(evm *EVM) (Processor, error)
+
+var (
+	processorsMu sync.RWMutex
+	processors   = make(map[string]ProcessorFactory)
+)
+
+// RegisterProcessor makes a processor implementation selectable by name through
+// Config.Processor. It is meant to be called from the init function of the
+// implementing package, and panics if the name is already taken.
+func RegisterProcessor(name string, factory ProcessorFactory) {
+	processorsMu.Lock()
+	defer processorsMu.Unlock()
+
+	if factory == nil {
+		panic("vm: RegisterProcessor factory is nil")
+	}
+	if _, exists := processors[name]; exists {
+		panic(fmt.Sprintf("vm: processor %q registered twice", name))
+	}
+	processors[name] = factory
+}
+
+// Processors returns the sorted names of the registered processors.
+func Processors() []string {
+	processorsMu.RLock()
+	defer processorsMu.RUnlock()
+
+	names := make([]string, 0, len(processors))
+	for name := range processors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ProcessorName returns the name of the processor executing call frames under
+// the given chain rules, or an empty string if they are executed by the
+// interpreter alone. The QUANTUM instructions are part of the Quantum fork, so
+// the default quantum processor is selected by the fork rather than by the
+// node configuration.
+func (c *Config) ProcessorName(rules params.Rules) string {
+	if c.Processor != "" {
+		return c.Processor
+	}
+	if rules.IsQuantum {
+		return DefaultQuantumProcessor
+	}
+	return ""
+}
+
+// lookupProcessor returns the factory registered under the given name.
+func lookupProcessor(name string) (ProcessorFactory, error) {
+	processorsMu.RLock()
+	factory, ok := processors[name]
+	processorsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownProcessor, name)
+	}
+	return factory, nil
+}
+
+// newProcessor creates the processor selected by the EVM configuration and
+// chain rules. It returns nil if no processor is selected.
+func newProcessor(evm *EVM) (Processor, error) {
+	name := evm.Config.ProcessorName(evm.chainRules)
+	if name == "" {
+		return nil, nil
+	}
+	factory, err := lookupProcessor(name)
+	if err != nil {
+		return nil, err
+	}
+	return factory(evm)
+}
+
+// ValidateProcessor checks that the processor needed by the chain can be
+// created with the given configuration. Once the Quantum fork is scheduled the
+// QUANTUM instructions are executed by the processor, so a node unable to
+// create it, or configured with a processor not implementing QuantumOpHandler,
+// must refuse to start rather than diverge from the network when the fork
+// activates. NewEVM itself does not fail, it falls back to the interpreter.
+func ValidateProcessor(config Config, chainConfig *params.ChainConfig) error {
+	rules := params.Rules{IsQuantum: chainConfig.QuantumTime != nil}
+	name := config.ProcessorName(rules)
+	if name == "" {
+		return nil
+	}
+	factory, err := lookupProcessor(name)
+	if err != nil {
+		return err
+	}
+	processor, err := factory(&EVM{Config: config, chainConfig: chainConfig, chainRules: rules})
+	if err != nil {
+		return fmt.Errorf("processor %q: %w", name, err)
+	}
+	var handler bool
+	if processor != nil {
+		_, handler = processor.(QuantumOpHandler)
+		if err := processor.Close(); err != nil {
+			return fmt.Errorf("processor %q: %w", name, err)
+		}
+	}
+	if rules.IsQuantum && !handler {
+		return fmt.Errorf("processor %q: %w", name, ErrNoQuantumHandler)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// testProcessor is a processor that runs every contract on the interpreter and
+// handles the QUANTUM instructions by returning no results.
+type testProcessor struct {
+	evm    *EVM
+	runs   int
+	closed bool
+}
+
+func (p *testProcessor) Run(contract *Contract, input []byte, readOnly bool) ([]byte, error) {
+	p.runs++
+	return p.evm.Interpreter().Run(contract, input, readOnly)
+}
+
+func (p *testProcessor) Close() error {
+	p.closed = true
+	return nil
+}
+
+func (p *testProcessor) ExecuteQuantumOp(op QuantumOp, scope *ScopeContext, args []uint256.Int) ([]uint256.Int, error) {
+	return nil, nil
+}
+
+func TestProcessorSelection(t *testing.T) {
+	var created *testProcessor
+	RegisterProcessor("test-selection", func(evm *EVM) (Processor, error) {
+		created = &testProcessor{evm: evm}
+		return created, nil
+	})
+	if !slices.Contains(Processors(), "test-selection") {
+		t.Fatalf("registered processor missing from %v", Processors())
+	}
+
+	// Without a processor name the interpreter executes the call frames
+	evm := NewEVM(BlockContext{}, TxContext{}, nil, params.TestChainConfig, Config{})
+	if evm.processor != nil || created != nil {
+		t.Fatal("processor created without being selected")
+	}
+	contract := NewContract(common.Address{}, common.Address{}, new(uint256.Int), 0, nil)
+	if _, ok := evm.GetVMProcessor(contract).(*EVMInterpreter); !ok {
+		t.Fatal("interpreter not selected by default")
+	}
+
+	// A selected processor is attached together with its QUANTUM handler
+	evm = NewEVM(BlockContext{}, TxContext{}, nil, params.TestChainConfig, Config{Processor: "test-selection"})
+	if created == nil || evm.processor != Processor(created) {
+		t.Fatal("selected processor not attached")
+	}
+	if evm.quantumHandler != QuantumOpHandler(created) {
+		t.Fatal("processor not attached as the QUANTUM handler")
+	}
+	if evm.GetVMProcessor(contract) != Processor(created) {
+		t.Fatal("selected processor not used for the contract")
+	}
+	contract.EVMOnly = true
+	if _, ok := evm.GetVMProcessor(contract).(*EVMInterpreter); !ok {
+		t.Fatal("EVM-only contract not executed by the interpreter")
+	}
+	if err := evm.Close(); err != nil || !created.closed {
+		t.Fatalf("processor not closed: %v", err)
+	}
+}
+
+func TestProcessorUnknown(t *testing.T) {
+	evm := &EVM{Config: Config{Processor: "test-missing"}}
+	if _, err := newProcessor(evm); !errors.Is(err, ErrUnknownProcessor) {
+		t.Fatalf("want %v, have %v", ErrUnknownProcessor, err)
+	}
+	// NewEVM falls back to the interpreter, ValidateProcessor reports the
+	// error at chain setup
+	fallback := NewEVM(BlockContext{}, TxContext{}, nil, params.TestChainConfig, Config{Processor: "test-missing"})
+	if fallback.processor != nil || fallback.quantumHandler != nil {
+		t.Fatal("unknown processor attached")
+	}
+	// The Quantum fork selects the default quantum processor regardless of
+	// the configuration
+	evm.Config = Config{}
+	if name := evm.Config.ProcessorName(params.Rules{}); name != "" {
+		t.Fatalf("processor %q selected before the fork", name)
+	}
+	if name := evm.Config.ProcessorName(params.Rules{IsQuantum: true}); name != DefaultQuantumProcessor {
+		t.Fatalf("want %q, have %q", DefaultQuantumProcessor, name)
+	}
+}
+
+func TestValidateProcessor(t *testing.T) {
+	config := *params.TestChainConfig
+	if err := ValidateProcessor(Config{}, &config); err != nil {
+		t.Fatalf("processor required without the Quantum fork: %v", err)
+	}
+	// Scheduling the fork requires the default quantum processor, which is
+	// not registered in this package
+	config.QuantumTime = new(uint64)
+	if err := ValidateProcessor(Config{}, &config); !errors.Is(err, ErrUnknownProcessor) {
+		t.Fatalf("want %v, have %v", ErrUnknownProcessor, err)
+	}
+	// Failures of the factory are reported at validation
+	errFactory := errors.New("factory failure")
+	RegisterProcessor("test-validate", func(evm *EVM) (Processor, error) {
+		return nil, errFactory
+	})
+	if err := ValidateProcessor(Config{Processor: "test-validate"}, &config); !errors.Is(err, errFactory) {
+		t.Fatalf("want %v, have %v", errFactory, err)
+	}
+	// After the fork the processor must execute the QUANTUM instructions
+	RegisterProcessor("test-classical", func(evm *EVM) (Processor, error) {
+		return &classicalProcessor{testProcessor{evm: evm}}, nil
+	})
+	if err := ValidateProcessor(Config{Processor: "test-classical"}, &config); !errors.Is(err, ErrNoQuantumHandler) {
+		t.Fatalf("want %v, have %v", ErrNoQuantumHandler, err)
+	}
+	RegisterProcessor("test-quantum", func(evm *EVM) (Processor, error) {
+		return &testProcessor{evm: evm}, nil
+	})
+	if err := ValidateProcessor(Config{Processor: "test-quantum"}, &config); err != nil {
+		t.Fatalf("quantum processor rejected: %v", err)
+	}
+	config.QuantumTime = nil
+	if err := ValidateProcessor(Config{Processor: "test-classical"}, &config); err != nil {
+		t.Fatalf("classical processor rejected without the fork: %v", err)
+	}
+}
+
+// classicalProcessor is a processor without a QUANTUM handler.
+type classicalProcessor struct {
+	p testProcessor
+}
+
+func (c *classicalProcessor) Run(contract *Contract, input []byte, readOnly bool) ([]byte, error) {
+	return c.p.Run(contract, input, readOnly)
+}
+
+func (c *classicalProcessor) Close() error {
+	return c.p.Close()
+}
+
+func TestProcessorRegisterTwice(t *testing.T) {
+	factory := func(evm *EVM) (Processor, error) { return nil, nil }
+	RegisterProcessor("test-twice", factory)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate registration did not panic")
+		}
+	}()
+	RegisterProcessor("test-twice", factory)
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// quantumGatePasses - количество элементарных проходов по вектору состояния,
+// выполняемых вентилем. Вращения X/Y и вентиль Тоффоли раскладываются на
+// последовательности базовых вентилей, а U3, управляемые вентили и iSWAP
+// выполняются за один проход независимо от количества управляющих кубитов.
+var quantumGatePasses = map[QuantumOp]uint64{
+	QHADAMARD: 1,
+	QPAULIX:   1,
+	QPAULIY:   1,
+	QPAULIZ:   1,
+	QPHASE:    1,
+	QROTX:     5,
+	QROTY:     5,
+	QROTZ:     1,
+	QU3:       1,
+	QCNOT:     1,
+	QSWAP:     1,
+	QTOFFOLI:  15,
+	QCZ:       1,
+	QCPHASE:   1,
+	QISWAP:    1,
+	QCU:       1,
+}
+
+// QuantumGas возвращает стоимость квантовой инструкции op по таблице table.
+// words - размер представления регистра контракта в 32-байтовых словах (0,
+// если регистра нет), для QINIT - размер создаваемого регистра. args -
+// операнды инструкции в порядке реестра. Стоимость пропорциональна размеру
+// представления, с которым работает инструкция, а для QSHOR, QGROVER и QQFT -
+// размеру плотного вектора состояния создаваемого ими регистра.
+func QuantumGas(table *params.QuantumGasTable, op QuantumOp, words uint64, args []uint256.Int) (uint64, error) {
+	var (
+		cost uint64
+		ok   bool
+	)
+	if passes, gate := quantumGatePasses[op]; gate {
+		cost, ok = table.PassCost(table.GateGas, words, passes)
+		return quantumGasResult(cost, ok)
+	}
+	switch op {
+	case QINIT:
+		cost, ok = table.InitCost(words)
+	case QDESTROY:
+		cost, ok = table.DestroyGas, true
+	case QRESET:
+		cost, ok = table.ResetCost(words)
+	case QMEASURE, QMEASUREALL:
+		cost, ok = table.MeasureCost(words)
+
+	case QSHOR:
+		// Регистр из 2L счетных и L рабочих кубитов для L-битного числа. Каждая
+		// из QuantumShorAttempts попыток готовит рабочий регистр (X) и
+		// суперпозицию счетных кубитов (2L H), выполняет 2L контролируемых
+		// модульных умножений, обратное QFT над счетными кубитами и измерение
+		// (два прохода)
+		l := uint64(args[0].BitLen())
+		passes := (1 + 4*l + qftPasses(2*l) + 2) * params.QuantumShorAttempts
+		cost, ok = densePassCost(table, table.ShorGas, 3*l, passes)
+	case QGROVER:
+		// Регистр из m = ceil(log2(searchSpace)) кубитов (не меньше одного),
+		// m вентилей Адамара, floor(pi/4*sqrt(2^m)) итераций оракула и
+		// диффузора по 2m+2 прохода каждая и измерение
+		m := max(operandBits(&args[2], 64), 1)
+		iterations := uint64(math.Floor(math.Pi / 4 * math.Sqrt(math.Ldexp(1, int(m)))))
+		cost, ok = densePassCost(table, table.GroverGas, m, m+iterations*(2*m+2)+2)
+	case QQFT:
+		// Регистр из m = ceil(log2(size/16)) кубитов
+		amplitudes := new(uint256.Int).Div(&args[1], uint256.NewInt(params.QuantumAmplitudeSize))
+		m := operandBits(amplitudes, 64)
+		cost, ok = densePassCost(table, table.QFTGas, m, qftPasses(m))
+	case QQPE:
+		// Вентили Адамара над кубитами фазы, iterations контролируемых
+		// операций на каждый из них и обратное QFT над ними
+		if !args[0].IsUint64() || !args[1].IsUint64() {
+			return 0, ErrGasUintOverflow
+		}
+		iterations, phase := args[0].Uint64(), args[1].Uint64()
+		hi, controlled := bits.Mul64(iterations, phase)
+		if hi != 0 || phase > math.MaxUint32 {
+			return 0, ErrGasUintOverflow
+		}
+		passes, carry := bits.Add64(controlled, phase+phase*(phase+1)/2, 0)
+		if carry != 0 {
+			return 0, ErrGasUintOverflow
+		}
+		cost, ok = table.PassCost(table.QPEGas, words, passes)
+	case QRANDOM:
+		// Каждый бит результата готовится вентилем Адамара и измеряется
+		// (три прохода по регистру)
+		if !args[1].IsUint64() || args[1].Uint64() > math.MaxUint64/24 {
+			return 0, ErrGasUintOverflow
+		}
+		cost, ok = table.PassCost(table.RandomGas, words, 24*args[1].Uint64())
+	default:
+		return 0, &ErrInvalidOpCode{opcode: QUANTUM}
+	}
+	return quantumGasResult(cost, ok)
+}
+
+// densePassCost возвращает стоимость passes вентилей над плотным вектором
+// состояния регистра из qubits кубитов
+func densePassCost(table *params.QuantumGasTable, base, qubits, passes uint64) (uint64, bool) {
+	words, ok := params.QuantumStateWords(qubits)
+	if !ok {
+		return 0, false
+	}
+	return table.PassCost(base, words, passes)
+}
+
+// qftPasses возвращает количество элементарных проходов QFT над m кубитами:
+// m вентилей Адамара, m(m-1)/2 контролируемых фазовых сдвигов по 5 базовых
+// вентилей и m/2 обменов для обращения порядка кубитов
+func qftPasses(m uint64) uint64 {
+	return m + 5*(m*(m-1)/2) + m/2
+}
+
+// operandBits возвращает ceil(log2(v)) - количество кубитов, необходимое для
+// представления v базисных состояний. Значения больше 2^limit заменяются на
+// limit+1, что приводит к переполнению при расчете стоимости.
+func operandBits(v *uint256.Int, limit int) uint64 {
+	if v.IsZero() {
+		return 0
+	}
+	m := new(uint256.Int).SubUint64(v, 1).BitLen()
+	if m > limit {
+		return uint64(limit) + 1
+	}
+	return uint64(m)
+}
+
+// quantumGasResult преобразует результат расчета стоимости в ошибку
+// переполнения.
+func quantumGasResult(cost uint64, ok bool) (uint64, error) {
+	if !ok {
+		return 0, ErrGasUintOverflow
+	}
+	return cost, nil
+}
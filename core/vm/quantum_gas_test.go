@@ -0,0 +1,98 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// TestQuantumGas checks the cost of the QUANTUM instructions against a
+// reference table of the Quantum fork.
+func TestQuantumGas(t *testing.T) {
+	u := func(vals ...uint64) []uint256.Int {
+		args := make([]uint256.Int, len(vals))
+		for i, v := range vals {
+			args[i].SetUint64(v)
+		}
+		return args
+	}
+	huge := make([]uint256.Int, 1)
+	huge[0].Lsh(uint256.NewInt(1), 100)
+
+	for i, tt := range []struct {
+		op    QuantumOp
+		words uint64
+		args  []uint256.Int
+		cost  uint64
+		err   error
+	}{
+		{QINIT, 1, u(1), 5003, nil},
+		{QINIT, 2048, u(12), 19336, nil},
+		{QINIT, 32768, u(16), 2200456, nil},
+		{QINIT, 1 << 24, u(25), 549806150536, nil},
+		{QINIT, 1 << 32, u(33), 0, ErrGasUintOverflow},
+		{QDESTROY, 1 << 19, nil, 1000, nil},
+		{QRESET, 512, nil, 3536, nil},
+
+		{QHADAMARD, 512, u(0), 612, nil},
+		{QPAULIX, 1, u(0), 101, nil},
+		{QCNOT, 512, u(1, 0), 612, nil},
+		{QROTX, 512, u(1000, 0), 2660, nil},
+		{QTOFFOLI, 512, u(2, 1, 0), 7780, nil},
+		{QU3, 512, u(1, 2, 3, 0), 612, nil},
+		{QCU, 512, u(1, 2, 3, 0, 6), 612, nil},
+		{QISWAP, 512, u(1, 0), 612, nil},
+		{QHADAMARD, 1 << 19, u(0), 524388, nil},
+
+		{QMEASURE, 512, u(0), 1224, nil},
+		{QMEASUREALL, 512, nil, 1224, nil},
+
+		{QSHOR, 0, u(15), 1450832, nil},
+		{QSHOR, 0, huge, 0, ErrGasUintOverflow},
+		{QGROVER, 0, u(0, 32, 16), 30288, nil},
+		{QQFT, 0, u(0, 128), 10076, nil},
+		{QQPE, 16, u(2, 3, 0), 15240, nil},
+		{QRANDOM, 16, u(0, 32), 17288, nil},
+	} {
+		cost, err := QuantumGas(&params.QuantumGasTableQuantum, tt.op, tt.words, tt.args)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("test %d (%v): have error %v, want %v", i, tt.op, err, tt.err)
+			continue
+		}
+		if cost != tt.cost {
+			t.Errorf("test %d (%v): have cost %d, want %d", i, tt.op, cost, tt.cost)
+		}
+	}
+}
+
+// TestQuantumGasGrowth checks that the cost of a gate doubles with the size of
+// the register representation.
+func TestQuantumGasGrowth(t *testing.T) {
+	table := &params.QuantumGasTableQuantum
+	args := make([]uint256.Int, 1)
+	for words := uint64(2); words <= 1<<24; words *= 2 {
+		prev, _ := QuantumGas(table, QHADAMARD, words/2, args)
+		cost, _ := QuantumGas(table, QHADAMARD, words, args)
+		if cost-table.GateGas != 2*(prev-table.GateGas) {
+			t.Fatalf("%d words: cost %d is not double of %d", words, cost, prev)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/holiman/uint256"
+)
+
+// Квантовые инструкции кодируются двумя байтами: префиксом QUANTUM и
+// непосредственным байтом подкода QuantumOp. Префикс не пересекается ни с
+// одним стандартным опкодом, а все подкоды лежат в диапазоне 0x01-0x3f, то есть
+// не являются ни PUSHn, ни JUMPDEST. Благодаря этому анализ JUMPDEST в
+// analysis_legacy.go не требует изменений: непосредственный байт никогда не
+// может стать допустимой целью перехода и не сдвигает разметку кода.
+//
+// Инструкция доступна только после активации форка params.ChainConfig.QuantumTime.
+//
+// Угловые операнды (angle, theta, phi, lambda) задаются долями полного оборота
+// с фиксированной точкой: младшие 64 бита операнда v кодируют угол
+// 2*pi*v/2^64, а старшие биты - целые обороты - не учитываются. Отрицательный
+// угол записывается дополнительным кодом, поэтому разность 0 - v в
+// арифметике EVM задает угол -2*pi*v/2^64. Углы вида pi/2^k при k < 63
+// представляются точно. При выполнении v округляется до ближайшего float64 (к
+// четному при равенстве) и умножается на 2*pi.
+//
+// Операнд controls инструкции QCU - битовая маска управляющих кубитов: бит i
+// выбирает кубит i. Вентиль U3 применяется к целевому кубиту в базисных
+// состояниях, где все управляющие кубиты равны 1, нулевая маска дает QU3.
+
+// QuantumOp - подкод квантовой инструкции, следующий за префиксом QUANTUM.
+type QuantumOp byte
+
+// Управление регистром.
+const (
+	QINIT    QuantumOp = 0x01 // Инициализация квантового регистра
+	QDESTROY QuantumOp = 0x02 // Уничтожение квантового регистра
+	QRESET   QuantumOp = 0x03 // Сброс регистра в |0...0⟩
+)
+
+// Однокубитные вентили.
+const (
+	QHADAMARD QuantumOp = 0x10 // Вентиль Адамара
+	QPAULIX   QuantumOp = 0x11 // Вентиль Паули X (NOT)
+	QPAULIY   QuantumOp = 0x12 // Вентиль Паули Y
+	QPAULIZ   QuantumOp = 0x13 // Вентиль Паули Z
+	QPHASE    QuantumOp = 0x14 // Фазовый вентиль
+	QROTX     QuantumOp = 0x15 // Вращение вокруг оси X
+	QROTY     QuantumOp = 0x16 // Вращение вокруг оси Y
+	QROTZ     QuantumOp = 0x17 // Вращение вокруг оси Z
+	QU3       QuantumOp = 0x18 // Произвольный однокубитный вентиль U3
+)
+
+// Многокубитные вентили.
+const (
+	QCNOT    QuantumOp = 0x20 // Контролируемый NOT
+	QSWAP    QuantumOp = 0x21 // Обмен состояниями кубитов
+	QTOFFOLI QuantumOp = 0x22 // Вентиль Тоффоли
+	QCZ      QuantumOp = 0x23 // Контролируемый Z
+	QCPHASE  QuantumOp = 0x24 // Контролируемый фазовый сдвиг
+	QISWAP   QuantumOp = 0x25 // Обмен состояниями с фазой i
+	QCU      QuantumOp = 0x26 // U3 с произвольным набором управляющих кубитов
+)
+
+// Измерения.
+const (
+	QMEASURE    QuantumOp = 0x28 // Измерение кубита
+	QMEASUREALL QuantumOp = 0x29 // Измерение всех кубитов
+)
+
+// Алгоритмы.
+const (
+	QSHOR   QuantumOp = 0x30 // Алгоритм Шора для факторизации
+	QGROVER QuantumOp = 0x31 // Алгоритм Гровера для поиска
+	QQFT    QuantumOp = 0x32 // Квантовое преобразование Фурье
+	QQPE    QuantumOp = 0x33 // Квантовое оценивание фазы
+	QRANDOM QuantumOp = 0x34 // Квантовый генератор случайных чисел
+)
+
+// maxQuantumOp ограничивает пространство подкодов, см. комментарий выше.
+const maxQuantumOp = 0x3f
+
+var (
+	// ErrQuantumNotAvailable возвращается, если квантовая инструкция
+	// встретилась, а обработчик квантовых операций не подключен к EVM.
+	ErrQuantumNotAvailable = errors.New("quantum processor not available")
+
+	// ErrQuantumRegisterSlot возвращается при попытке SSTORE в ячейку
+	// обязательства квантового регистра контракта, у которого есть регистр.
+	// Такую ячейку изменяют только квантовые инструкции, иначе контракт мог
+	// бы подделать обязательство.
+	ErrQuantumRegisterSlot = errors.New("write to quantum register slot")
+)
+
+// quantumOpInfo описывает подкод в каноническом реестре.
+type quantumOpInfo struct {
+	name   string
+	pops   int  // Количество операндов, снимаемых со стека
+	pushes int  // Количество результатов, помещаемых на стек
+	memory bool // Операнды [0] и [1] задают смещение и размер области памяти
+}
+
+// quantumOps - единственный реестр квантовых инструкций. Порядок операндов
+// соответствует порядку снятия со стека: операнд [0] находится на вершине.
+var quantumOps = [maxQuantumOp + 1]*quantumOpInfo{
+	QINIT:    {name: "QINIT", pops: 1}, // [numQubits | noise<<64]
+	QDESTROY: {name: "QDESTROY"},       // []
+	QRESET:   {name: "QRESET"},         // []
+
+	QHADAMARD: {name: "QHADAMARD", pops: 1}, // [qubit]
+	QPAULIX:   {name: "QPAULIX", pops: 1},   // [qubit]
+	QPAULIY:   {name: "QPAULIY", pops: 1},   // [qubit]
+	QPAULIZ:   {name: "QPAULIZ", pops: 1},   // [qubit]
+	QPHASE:    {name: "QPHASE", pops: 2},    // [angle, qubit]
+	QROTX:     {name: "QROTX", pops: 2},     // [angle, qubit]
+	QROTY:     {name: "QROTY", pops: 2},     // [angle, qubit]
+	QROTZ:     {name: "QROTZ", pops: 2},     // [angle, qubit]
+	QU3:       {name: "QU3", pops: 4},       // [theta, phi, lambda, qubit]
+
+	QCNOT:    {name: "QCNOT", pops: 2},    // [target, control]
+	QSWAP:    {name: "QSWAP", pops: 2},    // [qubit2, qubit1]
+	QTOFFOLI: {name: "QTOFFOLI", pops: 3}, // [target, control2, control1]
+	QCZ:      {name: "QCZ", pops: 2},      // [target, control]
+	QCPHASE:  {name: "QCPHASE", pops: 3},  // [angle, target, control]
+	QISWAP:   {name: "QISWAP", pops: 2},   // [qubit2, qubit1]
+	QCU:      {name: "QCU", pops: 5},      // [theta, phi, lambda, target, controls]
+
+	QMEASURE:    {name: "QMEASURE", pops: 1, pushes: 1}, // [qubit] -> [bit]
+	QMEASUREALL: {name: "QMEASUREALL", pushes: 1},       // [] -> [value]
+
+	QSHOR:   {name: "QSHOR", pops: 1, pushes: 2},                 // [n] -> [factor2, factor1]
+	QGROVER: {name: "QGROVER", pops: 3, pushes: 1, memory: true}, // [offset, size, searchSpace] -> [size]
+	QQFT:    {name: "QQFT", pops: 2, memory: true},               // [offset, size]
+	QQPE:    {name: "QQPE", pops: 3, pushes: 1},                  // [iterations, phaseQubits, target] -> [phase]
+	QRANDOM: {name: "QRANDOM", pops: 2, pushes: 1, memory: true}, // [offset, size] -> [size]
+}
+
+// IsValid проверяет, определен ли подкод в реестре.
+func (op QuantumOp) IsValid() bool {
+	return op <= maxQuantumOp && quantumOps[op] != nil
+}
+
+// String возвращает мнемонику подкода.
+func (op QuantumOp) String() string {
+	if !op.IsValid() {
+		return fmt.Sprintf("QUANTUM_UNDEFINED(0x%02x)", byte(op))
+	}
+	return quantumOps[op].name
+}
+
+// StackIO возвращает количество снимаемых со стека и помещаемых на стек значений.
+func (op QuantumOp) StackIO() (pops, pushes int) {
+	if !op.IsValid() {
+		return 0, 0
+	}
+	return quantumOps[op].pops, quantumOps[op].pushes
+}
+
+// UsesMemory сообщает, обращается ли инструкция к памяти контракта.
+func (op QuantumOp) UsesMemory() bool {
+	return op.IsValid() && quantumOps[op].memory
+}
+
+// QuantumOps возвращает все определенные подкоды в порядке возрастания.
+func QuantumOps() []QuantumOp {
+	var ops []QuantumOp
+	for i := range quantumOps {
+		if quantumOps[i] != nil {
+			ops = append(ops, QuantumOp(i))
+		}
+	}
+	return ops
+}
+
+// QuantumOpFromString возвращает подкод по мнемонике.
+func QuantumOpFromString(name string) (QuantumOp, bool) {
+	for i, info := range quantumOps {
+		if info != nil && info.name == name {
+			return QuantumOp(i), true
+		}
+	}
+	return 0, false
+}
+
+// QuantumOpHandler исполняет квантовые инструкции. Интерпретатор сам
+// проверяет стек, снимает операнды, расширяет память и помещает результаты на
+// стек, обработчику передаются только значения операндов.
+type QuantumOpHandler interface {
+	// ExecuteQuantumOp выполняет инструкцию op в контексте scope. args содержит
+	// снятые со стека операнды (args[0] был на вершине). Возвращаемые значения
+	// помещаются на стек по порядку, последнее оказывается на вершине.
+	ExecuteQuantumOp(op QuantumOp, scope *ScopeContext, args []uint256.Int) ([]uint256.Int, error)
+}
+
+// QuantumRegisterFlusher реализуется обработчиком квантовых инструкций,
+// который держит измененные регистры кадра вызова в памяти. EVM вызывает
+// FlushQuantumRegisters перед снимком состояния вложенного кадра, чтобы откат
+// вызываемого кадра не затронул изменения вызывающего, и после выполнения
+// кода кадра, чтобы его изменения откатывались вместе с ним. Чтение и запись
+// ячейки state.QuantumRegisterSlot также предваряются записью регистров.
+type QuantumRegisterFlusher interface {
+	FlushQuantumRegisters()
+}
+
+// HasQuantumOps проверяет, содержит ли байткод квантовые инструкции. Данные
+// PUSHn пропускаются, поэтому байт префикса внутри константы не учитывается.
+func HasQuantumOps(code []byte) bool {
+	for pc := 0; pc < len(code); pc++ {
+		op := OpCode(code[pc])
+		switch {
+		case op == QUANTUM:
+			return true
+		case op >= PUSH1 && op <= PUSH32:
+			pc += int(op - PUSH0)
+		}
+	}
+	return false
+}
+
+// opQuantum исполняет префикс QUANTUM: читает подкод из следующего байта,
+// проверяет стек, оплачивает расширение памяти и передает операнды
+// обработчику, подключенному к EVM.
+func opQuantum(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	op := QuantumOp(scope.Contract.GetOp(*pc + 1))
+	if !op.IsValid() {
+		return nil, &ErrInvalidOpCode{opcode: QUANTUM}
+	}
+	handler := interpreter.evm.quantumHandler
+	if handler == nil {
+		return nil, ErrQuantumNotAvailable
+	}
+	pops, pushes := op.StackIO()
+	if sLen := scope.Stack.len(); sLen < pops {
+		return nil, &ErrStackUnderflow{stackLen: sLen, required: pops}
+	}
+	if op.UsesMemory() {
+		memSize, overflow := calcMemSize64(scope.Stack.Back(0), scope.Stack.Back(1))
+		if overflow {
+			return nil, ErrGasUintOverflow
+		}
+		if memSize, overflow = math.SafeMul(toWordSize(memSize), 32); overflow {
+			return nil, ErrGasUintOverflow
+		}
+		cost, err := memoryGasCost(scope.Memory, memSize)
+		if err != nil {
+			return nil, err
+		}
+		if !scope.Contract.UseGas(cost, interpreter.evm.Config.Tracer, tracing.GasChangeUnspecified) {
+			return nil, ErrOutOfGas
+		}
+		if memSize > 0 {
+			scope.Memory.Resize(memSize)
+		}
+	}
+	args := make([]uint256.Int, pops)
+	for i := range args {
+		args[i] = scope.Stack.pop()
+	}
+	results, err := handler.ExecuteQuantumOp(op, scope, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != pushes {
+		return nil, fmt.Errorf("quantum op %v returned %d values, want %d", op, len(results), pushes)
+	}
+	for i := range results {
+		scope.Stack.push(&results[i])
+	}
+	*pc += 1 // непосредственный байт подкода
+	return nil, nil
+}
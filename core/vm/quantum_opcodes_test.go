@@ -0,0 +1,231 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// TestQuantumOpcodeCollision checks that the QUANTUM prefix is not defined by
+// any of the standard instruction sets.
+func TestQuantumOpcodeCollision(t *testing.T) {
+	for name, tbl := range map[string]JumpTable{
+		"cancun": newCancunInstructionSet(),
+		"prague": newPragueInstructionSet(),
+		"eof":    newEOFInstructionSetForTesting(),
+	} {
+		if !tbl[QUANTUM].undefined {
+			t.Errorf("%s: opcode %v is already defined", name, QUANTUM)
+		}
+	}
+	tbl := newQuantumInstructionSet()
+	if tbl[QUANTUM].undefined {
+		t.Fatalf("QUANTUM is not enabled in the quantum instruction set")
+	}
+}
+
+// TestQuantumOpRegistry checks the invariants the encoding relies on: no
+// subcode is a PUSHn or a JUMPDEST and the names round-trip.
+func TestQuantumOpRegistry(t *testing.T) {
+	ops := QuantumOps()
+	if len(ops) == 0 {
+		t.Fatal("empty quantum registry")
+	}
+	for _, op := range ops {
+		if code := OpCode(op); code == JUMPDEST || (code >= PUSH1 && code <= PUSH32) {
+			t.Errorf("subcode %v collides with %v", op, code)
+		}
+		back, ok := QuantumOpFromString(op.String())
+		if !ok || back != op {
+			t.Errorf("name round-trip failed for %v: have %v", op, back)
+		}
+	}
+	if QuantumOp(0x00).IsValid() || QuantumOp(0xff).IsValid() {
+		t.Error("undefined subcodes reported as valid")
+	}
+}
+
+func TestHasQuantumOps(t *testing.T) {
+	tests := []struct {
+		code []byte
+		want bool
+	}{
+		{nil, false},
+		{[]byte{byte(PUSH1), 0x01, byte(QUANTUM), byte(QINIT)}, true},
+		// Prefix byte inside push data is not an instruction
+		{[]byte{byte(PUSH2), byte(QUANTUM), byte(QINIT), byte(STOP)}, false},
+		// Bytes that used to be claimed by the old quantum layout
+		{[]byte{byte(CALL), byte(CREATE), byte(RETURN), byte(REVERT), byte(SELFDESTRUCT)}, false},
+	}
+	for i, tt := range tests {
+		if have := HasQuantumOps(tt.code); have != tt.want {
+			t.Errorf("test %d: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+type testQuantumHandler struct {
+	op   QuantumOp
+	args []uint256.Int
+	ret  []uint256.Int
+}
+
+func (h *testQuantumHandler) ExecuteQuantumOp(op QuantumOp, scope *ScopeContext, args []uint256.Int) ([]uint256.Int, error) {
+	h.op, h.args = op, append([]uint256.Int(nil), args...)
+	return h.ret, nil
+}
+
+func TestOpQuantum(t *testing.T) {
+	var (
+		evm      = NewEVM(BlockContext{}, nil, params.TestChainConfig, Config{})
+		handler  = &testQuantumHandler{ret: []uint256.Int{*uint256.NewInt(1)}}
+		contract = NewContract(common.Address{}, common.Address{}, new(uint256.Int), 100000, nil)
+		stack    = newstack()
+		pc       = uint64(0)
+	)
+	contract.Code = []byte{byte(QUANTUM), byte(QMEASURE)}
+	scope := &ScopeContext{NewMemory(), stack, contract}
+
+	// Without a handler the instruction fails
+	stack.push(uint256.NewInt(3))
+	if _, err := opQuantum(&pc, evm.interpreter, scope); !errors.Is(err, ErrQuantumNotAvailable) {
+		t.Fatalf("expected ErrQuantumNotAvailable, got %v", err)
+	}
+	evm.SetQuantumHandler(handler)
+	if _, err := opQuantum(&pc, evm.interpreter, scope); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handler.op != QMEASURE || len(handler.args) != 1 || handler.args[0].Uint64() != 3 {
+		t.Fatalf("handler called with %v %v", handler.op, handler.args)
+	}
+	if stack.len() != 1 || stack.peek().Uint64() != 1 {
+		t.Fatalf("unexpected stack %v", stack.Data())
+	}
+	if pc != 1 {
+		t.Fatalf("immediate subcode not skipped, pc = %d", pc)
+	}
+
+	// Stack underflow is reported before the handler is invoked
+	pc = 0
+	stack.pop()
+	if _, err := opQuantum(&pc, evm.interpreter, scope); err == nil {
+		t.Fatal("expected stack underflow")
+	}
+
+	// Undefined subcodes are invalid instructions
+	contract.Code = []byte{byte(QUANTUM), 0xff}
+	if _, err := opQuantum(&pc, evm.interpreter, scope); err == nil {
+		t.Fatal("expected invalid opcode error")
+	}
+}
+
+type flushingQuantumHandler struct {
+	testQuantumHandler
+	flushes int
+}
+
+func (h *flushingQuantumHandler) FlushQuantumRegisters() {
+	h.flushes++
+}
+
+// TestQuantumRegisterFlush checks that the EVM writes back the pending quantum
+// registers around call frames and before the register slot is read.
+func TestQuantumRegisterFlush(t *testing.T) {
+	var (
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		evm        = NewEVM(BlockContext{}, statedb, params.TestChainConfig, Config{})
+		handler    = new(flushingQuantumHandler)
+		addr       = common.Address{0x01}
+	)
+	evm.SetQuantumHandler(handler)
+	statedb.SetCode(addr, []byte{byte(STOP)})
+
+	// Before the snapshot of the frame and after its code
+	if _, _, err := evm.Call(common.Address{}, addr, nil, 100000, new(uint256.Int)); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if handler.flushes != 2 {
+		t.Fatalf("call flushes mismatch: have %d, want 2", handler.flushes)
+	}
+	sload := func(loc common.Hash) {
+		var (
+			contract = NewContract(common.Address{}, addr, new(uint256.Int), 100000, nil)
+			stack    = newstack()
+			pc       = uint64(0)
+		)
+		stack.push(new(uint256.Int).SetBytes(loc[:]))
+		if _, err := opSloadQuantum(&pc, evm.interpreter, &ScopeContext{Memory: NewMemory(), Stack: stack, Contract: contract}); err != nil {
+			t.Fatalf("sload failed: %v", err)
+		}
+	}
+	sload(common.Hash{0x02})
+	if handler.flushes != 2 {
+		t.Fatalf("ordinary slot flushed registers")
+	}
+	sload(state.QuantumRegisterSlot)
+	if handler.flushes != 3 {
+		t.Fatalf("register slot read without flush")
+	}
+}
+
+// TestSstoreQuantumRegisterSlot checks that contracts cannot overwrite the
+// commitment of their quantum register, while contracts without a register use
+// the slot as ordinary storage.
+func TestSstoreQuantumRegisterSlot(t *testing.T) {
+	var (
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		evm        = NewEVM(BlockContext{}, statedb, params.TestChainConfig, Config{})
+		addr       = common.Address{0x01}
+		contract   = NewContract(common.Address{}, addr, new(uint256.Int), 100000, nil)
+		pc         = uint64(0)
+	)
+	sstore := func(loc common.Hash) error {
+		stack := newstack()
+		stack.push(uint256.NewInt(1))
+		stack.push(new(uint256.Int).SetBytes(loc[:]))
+		_, err := opSstoreQuantum(&pc, evm.interpreter, &ScopeContext{Memory: NewMemory(), Stack: stack, Contract: contract})
+		return err
+	}
+	if err := sstore(state.QuantumRegisterSlot); err != nil {
+		t.Fatalf("unexpected error without register: %v", err)
+	}
+	if got := statedb.GetState(addr, state.QuantumRegisterSlot); got != common.BigToHash(common.Big1) {
+		t.Fatalf("register slot not written: %x", got)
+	}
+	register := []byte{0x01, 0x02}
+	statedb.SetQuantumRegister(addr, register)
+	if err := sstore(state.QuantumRegisterSlot); !errors.Is(err, ErrQuantumRegisterSlot) {
+		t.Fatalf("expected ErrQuantumRegisterSlot, got %v", err)
+	}
+	if got := statedb.GetState(addr, state.QuantumRegisterSlot); got != crypto.Keccak256Hash(register) {
+		t.Fatalf("register commitment overwritten: %x", got)
+	}
+	if err := sstore(common.Hash{0x02}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := statedb.GetState(addr, common.Hash{0x02}); got != common.BigToHash(common.Big1) {
+		t.Fatalf("ordinary slot not written: %x", got)
+	}
+}
@@ -280,6 +280,12 @@ func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash, number ui
 func (b *EthAPIBackend) GetEVM(ctx context.Context, state *state.StateDB, header *types.Header, vmConfig *vm.Config, blockCtx *vm.BlockContext) *vm.EVM {
 	if vmConfig == nil {
 		vmConfig = b.eth.blockchain.GetVMConfig()
+	} else {
+		// Calls supply their own VM options, but quantum instructions must
+		// behave the same way as in the chain.
+		cfg := *vmConfig
+		cfg.InheritQuest(b.eth.blockchain.GetVMConfig())
+		vmConfig = &cfg
 	}
 	var context vm.BlockContext
 	if blockCtx != nil {
@@ -24,12 +24,14 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/quest/quantum"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
@@ -265,6 +267,117 @@ func storageRangeAt(statedb *state.StateDB, root common.Hash, address common.Add
 	return result, nil
 }
 
+// ParallelStats re-executes the given block on top of its parent state, once
+// sequentially and once in parallel with Block-STM, and reports how well its
+// transactions parallelise. Block-STM uses the thread count of the node's vm
+// config and the speedup is measured against the sequential run.
+func (api *DebugAPI) ParallelStats(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*core.ParallelStats, error) {
+	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %v not found", blockNrOrHash)
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not executable")
+	}
+	parent := api.eth.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %#x not found", block.ParentHash())
+	}
+	statedb, release, err := api.eth.stateAtBlock(ctx, parent, 0, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var (
+		processor = core.NewStateProcessor(api.eth.blockchain.Config(), api.eth.blockchain.HeaderChain())
+		cfg       = *api.eth.blockchain.GetVMConfig()
+	)
+	cfg.Tracer = nil
+	cfg.BlockSTM = false
+
+	start := time.Now()
+	if _, err := processor.Process(block, statedb.Copy(), cfg); err != nil {
+		return nil, err
+	}
+	sequential := time.Since(start)
+
+	cfg.BlockSTM = true
+	start = time.Now()
+	res, err := processor.Process(block, statedb, cfg)
+	if err != nil {
+		return nil, err
+	}
+	parallel := time.Since(start)
+
+	stats := res.ParallelStats
+	if stats == nil {
+		return nil, fmt.Errorf("block %v can not be executed in parallel", blockNrOrHash)
+	}
+	// Both runs include the system calls and block finalisation, so the
+	// measured times replace the estimate of the executor.
+	stats.SequentialTime, stats.ParallelTime = sequential, parallel
+	stats.Speedup = float64(sequential) / float64(parallel)
+	return stats, nil
+}
+
+// ExportQuantumState exports the quantum register of the given contract at the
+// given block as an RLP encoded snapshot. If history is non-zero, the snapshot
+// also carries the delta compressed state vectors of the register at up to
+// that many preceding blocks.
+func (api *DebugAPI) ExportQuantumState(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash, history *hexutil.Uint64) (hexutil.Bytes, error) {
+	header, err := api.eth.APIBackend.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %v not found", blockNrOrHash)
+	}
+	var depth uint64
+	if history != nil {
+		depth = uint64(*history)
+	}
+	parent := func(h *types.Header) *types.Header {
+		return api.eth.blockchain.GetHeader(h.ParentHash, h.Number.Uint64()-1)
+	}
+	registerAt := func(h *types.Header) ([]byte, error) {
+		statedb, err := api.eth.blockchain.StateAt(h.Root)
+		if err != nil {
+			return nil, fmt.Errorf("state of block #%d not available: %w", h.Number, err)
+		}
+		return statedb.GetQuantumRegister(address), nil
+	}
+	snap, err := quantum.ExportRegister(address, header, depth, parent, registerAt)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Encode()
+}
+
+// ImportQuantumState stores the quantum register of a snapshot exported with
+// ExportQuantumState in the database and returns its commitment. If the state
+// of the snapshot block is available, the register must match the commitment
+// of the contract in it.
+func (api *DebugAPI) ImportQuantumState(blob hexutil.Bytes) (common.Hash, error) {
+	snap, err := quantum.DecodeRegisterSnapshot(blob)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	commitment := snap.Commitment()
+	if header := api.eth.blockchain.GetHeader(snap.Hash, snap.Number); header != nil {
+		if statedb, err := api.eth.blockchain.StateAt(header.Root); err == nil {
+			if have := statedb.GetState(snap.Address, state.QuantumRegisterSlot); have != commitment {
+				return common.Hash{}, fmt.Errorf("register commitment mismatch: have %#x, want %#x", commitment, have)
+			}
+		}
+	}
+	rawdb.WriteQuantumRegister(api.eth.ChainDb(), commitment, snap.Register)
+	return commitment, nil
+}
+
 // GetModifiedAccountsByNumber returns all accounts that have changed between the
 // two blocks specified. A change is defined as a difference in nonce, balance,
 // code hash, or storage hash.
@@ -205,9 +205,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		}
 	}
 	var (
-		vmConfig = vm.Config{
-			EnablePreimageRecording: config.EnablePreimageRecording,
-		}
+		vmConfig    vm.Config
 		cacheConfig = &core.CacheConfig{
 			TrieCleanLimit:             config.TrieCleanCache,
 			TrieCleanNoPrefetch:        config.NoPrefetch,
@@ -222,6 +220,11 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			HistoryPruningCutoffHash:   cutoffHash,
 		}
 	)
+	if config.VMConfig != nil {
+		vmConfig = *config.VMConfig
+	}
+	vmConfig.EnablePreimageRecording = vmConfig.EnablePreimageRecording || config.EnablePreimageRecording
+
 	if config.VMTrace != "" {
 		traceConfig := json.RawMessage("{}")
 		if config.VMTraceJsonConfig != "" {
@@ -233,6 +236,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		}
 		vmConfig.Tracer = t
 	}
+	// The Quest options only override a provided base config if they are set
+	if config.VMConfig == nil || config.Quest != ethconfig.DefaultQuestConfig {
+		if err := config.Quest.ApplyVMConfig(&vmConfig); err != nil {
+			return nil, err
+		}
+	}
 	// Override the chain config with provided settings.
 	var overrides core.ChainOverrides
 	if config.OverridePrague != nil {
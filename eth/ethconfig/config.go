@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
@@ -68,6 +69,7 @@ var Defaults = Config{
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
 	RPCTxFeeCap:        1, // 1 ether
+	Quest:              DefaultQuestConfig,
 }
 
 //go:generate go run github.com/fjl/gencodec -type Config -formats toml -out gen_config.go
@@ -146,6 +148,14 @@ type Config struct {
 	VMTrace           string
 	VMTraceJsonConfig string
 
+	// Quest quantum processor options
+	Quest QuestConfig
+
+	// VMConfig, if set, is the base EVM configuration of the chain. Preimage
+	// recording, live tracing and the Quest options, unless left at their
+	// defaults, are applied on top of it.
+	VMConfig *vm.Config `toml:"-"`
+
 	// RPCGasCap is the global gas cap for eth-call variants.
 	RPCGasCap uint64
 
@@ -0,0 +1,95 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethconfig
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Quest device selectors accepted besides a numeric CUDA device index.
+const (
+	QuestDeviceAuto = "auto"
+	QuestDeviceCPU  = "cpu"
+)
+
+// QuestConfig contains the node-local settings of the Quest quantum processor.
+// The processor is attached once the Quantum fork is active and the register
+// representation and limits are fork rules, so these settings only tune how
+// the simulation runs.
+type QuestConfig struct {
+	Threads   int    // Number of simulation threads (0 = all cores)
+	Profiling bool   // Whether the processor collects performance profiles
+	Device    string // Simulation device: "auto", "cpu" or a CUDA device index
+
+	// MeasurementSeed, if set, replaces the block PREVRANDAO in the seed of
+	// quantum measurements, making their outcomes independent of the block.
+	// Nodes using it do not agree with the network, so it can't be set from
+	// the config file. Tests set it through simulated.WithQuest or
+	// simulated.WithVMConfig.
+	MeasurementSeed *common.Hash `toml:"-"`
+}
+
+// DefaultQuestConfig contains the default Quest settings.
+var DefaultQuestConfig = QuestConfig{
+	Device: QuestDeviceAuto,
+}
+
+// Sanitize checks the provided settings and returns an error for invalid ones.
+func (c *QuestConfig) Sanitize() error {
+	if c.Threads < 0 {
+		return fmt.Errorf("invalid quest thread count %d", c.Threads)
+	}
+	_, _, err := c.device()
+	return err
+}
+
+// device parses the device selector into the preferred CUDA device (-1 for
+// autodetection) and whether the simulation is pinned to the CPU.
+func (c *QuestConfig) device() (int, bool, error) {
+	switch c.Device {
+	case "", QuestDeviceAuto:
+		return -1, false, nil
+	case QuestDeviceCPU:
+		return -1, true, nil
+	}
+	id, err := strconv.Atoi(c.Device)
+	if err != nil || id < 0 {
+		return 0, false, fmt.Errorf("invalid quest device %q, want %q, %q or a device index", c.Device, QuestDeviceAuto, QuestDeviceCPU)
+	}
+	return id, false, nil
+}
+
+// ApplyVMConfig copies the Quest settings into the given VM config.
+func (c *QuestConfig) ApplyVMConfig(cfg *vm.Config) error {
+	device, cpu, err := c.device()
+	if err != nil {
+		return err
+	}
+	cfg.QuestLevelParallelism = c.Threads
+	cfg.QuestProfiling = c.Profiling
+	cfg.QuestMeasurementSeed = c.MeasurementSeed
+	cfg.QuestPreferredDevice = device
+	cfg.QuestForceCPU = cpu
+	cfg.QuestForceGPU = device >= 0
+	cfg.QuestAutodetectHardware = device < 0 && !cpu
+	cfg.QuestHardwareAcceleration = !cpu
+	return nil
+}
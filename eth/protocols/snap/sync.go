@@ -320,6 +320,7 @@ type accountTask struct {
 	needHeal  []bool // Flags whether the filling accounts's state was chunked and need healing
 
 	codeTasks      map[common.Hash]struct{}    // Code hashes that need retrieval
+	registerTasks  map[common.Hash]struct{}    // Quantum register commitments pending retrieval with the codes
 	stateTasks     map[common.Hash]common.Hash // Account hashes->roots that need full state retrieval
 	stateCompleted map[common.Hash]struct{}    // Account hashes whose storage have been completed
 
@@ -1892,6 +1893,7 @@ func (s *Syncer) processAccountResponse(res *accountResponse) {
 	res.task.needHeal = make([]bool, len(res.accounts))
 
 	res.task.codeTasks = make(map[common.Hash]struct{})
+	res.task.registerTasks = make(map[common.Hash]struct{})
 	res.task.stateTasks = make(map[common.Hash]common.Hash)
 
 	resumed := make(map[common.Hash]struct{})
@@ -2005,6 +2007,10 @@ func (s *Syncer) processBytecodeResponse(res *bytecodeResponse) {
 				res.task.pend--
 			}
 		}
+		if _, ok := res.task.registerTasks[hash]; ok {
+			delete(res.task.registerTasks, hash)
+			res.task.pend--
+		}
 		// Push the bytecode into a database batch
 		codes++
 		rawdb.WriteCode(batch, hash, code)
@@ -2215,6 +2221,12 @@ func (s *Syncer) processStorageResponse(res *storageResponse) {
 		for j := 0; j < len(res.hashes[i]); j++ {
 			rawdb.WriteStorageSnapshot(batch, account, res.hashes[i][j], res.slots[i][j])
 
+			// Quantum registers are stored with the contract code, schedule
+			// the missing ones for retrieval with the bytecodes
+			if commitment, ok := state.QuantumRegisterEntry(res.hashes[i][j], res.slots[i][j]); ok {
+				s.scheduleQuantumRegister(res.mainTask, commitment)
+			}
+
 			// If we're storing large contracts, generate the trie nodes
 			// on the fly to not trash the gluing points
 			if i == len(res.hashes)-1 && res.subTask != nil {
@@ -2268,6 +2280,21 @@ func (s *Syncer) processStorageResponse(res *storageResponse) {
 	// task assigners to pick up and fill.
 }
 
+// scheduleQuantumRegister queues the retrieval of a quantum register referenced
+// from the storage of an account in the task, unless it's already known. The
+// account task is not forwarded until the register is delivered.
+func (s *Syncer) scheduleQuantumRegister(task *accountTask, commitment common.Hash) {
+	if _, ok := task.registerTasks[commitment]; ok {
+		return
+	}
+	if rawdb.HasCodeWithPrefix(s.db, commitment) {
+		return
+	}
+	task.registerTasks[commitment] = struct{}{}
+	task.codeTasks[commitment] = struct{}{}
+	task.pend++
+}
+
 // processTrienodeHealResponse integrates an already validated trienode response
 // into the healer tasks.
 func (s *Syncer) processTrienodeHealResponse(res *trienodeHealResponse) {
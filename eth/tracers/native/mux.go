@@ -70,6 +70,9 @@ func newMuxTracer(ctx *tracers.Context, cfg json.RawMessage, chainConfig *params
 			OnCodeChange:    t.OnCodeChange,
 			OnStorageChange: t.OnStorageChange,
 			OnLog:           t.OnLog,
+			OnQuantumOp:     t.OnQuantumOp,
+			OnMeasure:       t.OnMeasure,
+			OnRegisterAlloc: t.OnRegisterAlloc,
 		},
 		GetResult: t.GetResult,
 		Stop:      t.Stop,
@@ -172,6 +175,30 @@ func (t *muxTracer) OnLog(log *types.Log) {
 	}
 }
 
+func (t *muxTracer) OnQuantumOp(addr common.Address, op byte, qubits []uint64, params []uint64, register tracing.QuantumRegister, err error) {
+	for _, t := range t.tracers {
+		if t.OnQuantumOp != nil {
+			t.OnQuantumOp(addr, op, qubits, params, register, err)
+		}
+	}
+}
+
+func (t *muxTracer) OnMeasure(addr common.Address, qubits []uint64, outcome uint64) {
+	for _, t := range t.tracers {
+		if t.OnMeasure != nil {
+			t.OnMeasure(addr, qubits, outcome)
+		}
+	}
+}
+
+func (t *muxTracer) OnRegisterAlloc(addr common.Address, numQubits int) {
+	for _, t := range t.tracers {
+		if t.OnRegisterAlloc != nil {
+			t.OnRegisterAlloc(addr, numQubits)
+		}
+	}
+}
+
 // GetResult returns an empty json object.
 func (t *muxTracer) GetResult() (json.RawMessage, error) {
 	resObject := make(map[string]json.RawMessage)
@@ -0,0 +1,223 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("quantumTracer", newQuantumTracer, false)
+}
+
+// defaultAmplitudeQubits is the size of the largest register whose state is
+// captured when amplitude snapshots are enabled.
+const defaultAmplitudeQubits = 8
+
+// quantumOp is a quantum instruction executed in a call frame.
+type quantumOp struct {
+	Op     string   `json:"op"`
+	Qubits []uint64 `json:"qubits,omitempty"`
+	Params []uint64 `json:"params,omitempty"`
+	// Outcome is the measured value of an instruction that measured qubits,
+	// bit i being the value of Qubits[i].
+	Outcome *uint64 `json:"outcome,omitempty"`
+	// Allocated is the size of the register allocated by the instruction,
+	// zero if it released the register.
+	Allocated *int `json:"allocated,omitempty"`
+	// Amplitudes is the state of the register after the instruction as
+	// [real, imaginary] pairs.
+	Amplitudes [][2]float64 `json:"amplitudes,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// quantumFrame collects the quantum instructions executed by a call frame.
+type quantumFrame struct {
+	Type  string         `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Ops   []quantumOp    `json:"ops,omitempty"`
+	Error string         `json:"error,omitempty"`
+	Calls []quantumFrame `json:"calls,omitempty"`
+}
+
+type quantumTracer struct {
+	callstack []quantumFrame
+	config    quantumTracerConfig
+	interrupt atomic.Bool // Atomic flag to signal execution interruption
+	reason    error       // Textual reason for the interruption
+}
+
+type quantumTracerConfig struct {
+	WithAmplitudes  bool `json:"withAmplitudes"`  // If true, the register state is captured after every instruction
+	AmplitudeQubits int  `json:"amplitudeQubits"` // Size of the largest register whose state is captured
+}
+
+// newQuantumTracer returns a native go tracer which records the quantum
+// instructions, measurements and register allocations of a tx per call frame.
+// Call frames which neither executed quantum instructions nor have such
+// subcalls are omitted.
+//
+// Example:
+//
+//	> debug.traceTransaction("0x...", {tracer: "quantumTracer", tracerConfig: {withAmplitudes: true}})
+//	{
+//	  type: "CALL",
+//	  from: "0x...",
+//	  to: "0x...",
+//	  ops: [{op: "QINIT", params: [1], allocated: 1, amplitudes: [[1, 0], [0, 0]]}, ...]
+//	}
+func newQuantumTracer(ctx *tracers.Context, cfg json.RawMessage, chainConfig *params.ChainConfig) (*tracers.Tracer, error) {
+	var config quantumTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	if config.AmplitudeQubits <= 0 {
+		config.AmplitudeQubits = defaultAmplitudeQubits
+	}
+	t := &quantumTracer{callstack: make([]quantumFrame, 0, 1), config: config}
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			OnEnter:         t.OnEnter,
+			OnExit:          t.OnExit,
+			OnQuantumOp:     t.OnQuantumOp,
+			OnMeasure:       t.OnMeasure,
+			OnRegisterAlloc: t.OnRegisterAlloc,
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+// OnEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *quantumTracer) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.interrupt.Load() {
+		return
+	}
+	t.callstack = append(t.callstack, quantumFrame{
+		Type: vm.OpCode(typ).String(),
+		From: from,
+		To:   to,
+	})
+}
+
+// OnExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *quantumTracer) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.interrupt.Load() {
+		return
+	}
+	size := len(t.callstack)
+	if size == 0 {
+		return
+	}
+	if err != nil && reverted {
+		t.callstack[size-1].Error = err.Error()
+	}
+	if depth == 0 || size == 1 {
+		return
+	}
+	// Pop call and nest it into the parent if it touched a register.
+	call := t.callstack[size-1]
+	t.callstack = t.callstack[:size-1]
+	if len(call.Ops) > 0 || len(call.Calls) > 0 {
+		t.callstack[size-2].Calls = append(t.callstack[size-2].Calls, call)
+	}
+}
+
+// OnQuantumOp is called after a quantum instruction was executed.
+func (t *quantumTracer) OnQuantumOp(addr common.Address, op byte, qubits []uint64, params []uint64, register tracing.QuantumRegister, err error) {
+	if t.interrupt.Load() || len(t.callstack) == 0 {
+		return
+	}
+	entry := quantumOp{
+		Op:     vm.QuantumOp(op).String(),
+		Qubits: qubits,
+		Params: params,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if t.config.WithAmplitudes && register != nil && register.NumQubits() <= t.config.AmplitudeQubits {
+		for _, amp := range register.StateVector() {
+			entry.Amplitudes = append(entry.Amplitudes, [2]float64{real(amp), imag(amp)})
+		}
+	}
+	frame := &t.callstack[len(t.callstack)-1]
+	frame.Ops = append(frame.Ops, entry)
+}
+
+// OnMeasure is called after the OnQuantumOp of a measuring instruction.
+func (t *quantumTracer) OnMeasure(addr common.Address, qubits []uint64, outcome uint64) {
+	if op := t.lastOp(); op != nil {
+		if len(op.Qubits) == 0 {
+			op.Qubits = qubits
+		}
+		op.Outcome = &outcome
+	}
+}
+
+// OnRegisterAlloc is called after the OnQuantumOp of an instruction which
+// allocated or released a register.
+func (t *quantumTracer) OnRegisterAlloc(addr common.Address, numQubits int) {
+	if op := t.lastOp(); op != nil {
+		op.Allocated = &numQubits
+	}
+}
+
+// lastOp returns the last instruction recorded in the current call frame.
+func (t *quantumTracer) lastOp() *quantumOp {
+	if t.interrupt.Load() || len(t.callstack) == 0 {
+		return nil
+	}
+	frame := &t.callstack[len(t.callstack)-1]
+	if len(frame.Ops) == 0 {
+		return nil
+	}
+	return &frame.Ops[len(frame.Ops)-1]
+}
+
+// GetResult returns the json-encoded quantum instructions of the top call
+// frame and its subcalls, and any error arising from the encoding or
+// forceful termination (via `Stop`).
+func (t *quantumTracer) GetResult() (json.RawMessage, error) {
+	if len(t.callstack) != 1 {
+		return nil, errors.New("incorrect number of top-level calls")
+	}
+	res, err := json.Marshal(t.callstack[0])
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *quantumTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
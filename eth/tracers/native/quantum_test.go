@@ -0,0 +1,84 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegister is a single qubit register in the |1> state.
+type testRegister struct{}
+
+func (testRegister) NumQubits() int            { return 1 }
+func (testRegister) StateVector() []complex128 { return []complex128{0, 1} }
+
+func TestQuantumTracer(t *testing.T) {
+	tracer, err := tracers.DefaultDirectory.New("quantumTracer", &tracers.Context{}, json.RawMessage(`{"withAmplitudes":true}`), params.MainnetChainConfig)
+	require.NoError(t, err)
+
+	var (
+		caller   = common.HexToAddress("0x01")
+		contract = common.HexToAddress("0xc0")
+		other    = common.HexToAddress("0xc1")
+	)
+	tracer.OnEnter(0, byte(vm.CALL), caller, contract, nil, 100000, big.NewInt(0))
+	tracer.OnQuantumOp(contract, byte(vm.QINIT), nil, []uint64{1}, testRegister{}, nil)
+	tracer.OnRegisterAlloc(contract, 1)
+	tracer.OnQuantumOp(contract, byte(vm.QPAULIX), []uint64{0}, nil, testRegister{}, nil)
+
+	// A subcall without quantum instructions is omitted
+	tracer.OnEnter(1, byte(vm.STATICCALL), contract, other, nil, 1000, nil)
+	tracer.OnExit(1, nil, 0, nil, false)
+
+	tracer.OnEnter(1, byte(vm.DELEGATECALL), contract, other, nil, 1000, nil)
+	tracer.OnQuantumOp(contract, byte(vm.QMEASUREALL), nil, nil, testRegister{}, nil)
+	tracer.OnMeasure(contract, []uint64{0}, 1)
+	tracer.OnQuantumOp(contract, byte(vm.QHADAMARD), []uint64{5}, nil, nil, errors.New("invalid qubit"))
+	tracer.OnExit(1, nil, 0, errors.New("invalid qubit"), true)
+	tracer.OnExit(0, nil, 0, nil, false)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "CALL",
+		"from": "0x0000000000000000000000000000000000000001",
+		"to": "0x00000000000000000000000000000000000000c0",
+		"ops": [
+			{"op": "QINIT", "params": [1], "allocated": 1, "amplitudes": [[0, 0], [1, 0]]},
+			{"op": "QPAULIX", "qubits": [0], "amplitudes": [[0, 0], [1, 0]]}
+		],
+		"calls": [{
+			"type": "DELEGATECALL",
+			"from": "0x00000000000000000000000000000000000000c0",
+			"to": "0x00000000000000000000000000000000000000c1",
+			"ops": [
+				{"op": "QMEASUREALL", "qubits": [0], "outcome": 1, "amplitudes": [[0, 0], [1, 0]]},
+				{"op": "QHADAMARD", "qubits": [5], "error": "invalid qubit"}
+			],
+			"error": "invalid qubit"
+		}]
+	}`, string(res))
+}
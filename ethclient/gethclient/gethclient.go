@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"runtime"
 	"runtime/debug"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -194,6 +195,103 @@ func (ec *Client) GetNodeInfo(ctx context.Context) (*p2p.NodeInfo, error) {
 	return &result, err
 }
 
+// QuestStatus is the quantum processor configuration reported by quest_status.
+// The processor and the maximum register size follow the fork rules of the
+// head block.
+type QuestStatus struct {
+	Processor   string `json:"processor"`
+	Backend     string `json:"backend"`
+	Qubits      int    `json:"qubits"`
+	Parallelism int    `json:"parallelism"`
+	ForceCPU    bool   `json:"forceCPU"`
+	Profiling   bool   `json:"profiling"`
+	ForkActive  bool   `json:"forkActive"`
+	Hardware    string `json:"hardware"`
+}
+
+// QuestStatus retrieves the quantum processor configuration of a geth node.
+func (ec *Client) QuestStatus(ctx context.Context) (*QuestStatus, error) {
+	var result QuestStatus
+	err := ec.c.CallContext(ctx, &result, "quest_status")
+	return &result, err
+}
+
+// QuestStats holds the operation counters of all quantum processors of a node.
+type QuestStats struct {
+	Operations          uint64 `json:"operations"`
+	ClassicalOperations uint64 `json:"classicalOperations"`
+	QuantumOperations   uint64 `json:"quantumOperations"`
+	Transactions        uint64 `json:"transactions"`
+	Batches             uint64 `json:"batches"`
+}
+
+// QuestStats retrieves the quantum processor operation counters of a geth node.
+func (ec *Client) QuestStats(ctx context.Context) (*QuestStats, error) {
+	var result QuestStats
+	err := ec.c.CallContext(ctx, &result, "quest_stats")
+	return &result, err
+}
+
+// QuestOperationProfile is the execution profile of a quantum instruction.
+type QuestOperationProfile struct {
+	Count     int64         `json:"count"`
+	TotalTime time.Duration `json:"totalTime"`
+	MinTime   time.Duration `json:"minTime"`
+	MaxTime   time.Duration `json:"maxTime"`
+	AvgTime   time.Duration `json:"avgTime"`
+}
+
+// QuestProfile retrieves the quantum instruction profile of a geth node, keyed
+// by instruction mnemonic. The node must run with quantum profiling enabled.
+func (ec *Client) QuestProfile(ctx context.Context) (map[string]QuestOperationProfile, error) {
+	var result map[string]QuestOperationProfile
+	err := ec.c.CallContext(ctx, &result, "quest_profile")
+	return result, err
+}
+
+// CircuitArgs describes a quantum circuit to simulate.
+type CircuitArgs struct {
+	QASM       string       `json:"qasm"`
+	Backend    string       `json:"backend,omitempty"`
+	Seed       *common.Hash `json:"seed,omitempty"`
+	Amplitudes bool         `json:"amplitudes,omitempty"`
+}
+
+// CircuitResult is the outcome of a simulated quantum circuit.
+type CircuitResult struct {
+	Clbits     []int        `json:"clbits"`
+	Commitment common.Hash  `json:"commitment"`
+	Backend    string       `json:"backend"`
+	Amplitudes [][2]float64 `json:"amplitudes,omitempty"`
+}
+
+// SimulateCircuit runs an OpenQASM circuit on a fresh quantum register of the
+// node without touching chain state.
+func (ec *Client) SimulateCircuit(ctx context.Context, args CircuitArgs) (*CircuitResult, error) {
+	var result CircuitResult
+	err := ec.c.CallContext(ctx, &result, "quest_simulateCircuit", args)
+	return &result, err
+}
+
+// QuantumRegister is the quantum register of an account.
+type QuantumRegister struct {
+	Address    common.Address `json:"address"`
+	Commitment common.Hash    `json:"commitment"`
+	Qubits     int            `json:"qubits"`
+	Backend    string         `json:"backend"`
+	Register   hexutil.Bytes  `json:"register"`
+	Amplitudes [][2]float64   `json:"amplitudes,omitempty"`
+}
+
+// QuantumRegisterState returns the quantum register of the account, or nil if
+// the account has none. The block number can be nil, in which case the register
+// is taken from the latest known block.
+func (ec *Client) QuantumRegisterState(ctx context.Context, account common.Address, blockNumber *big.Int) (*QuantumRegister, error) {
+	var result *QuantumRegister
+	err := ec.c.CallContext(ctx, &result, "quest_registerState", account, toBlockNumArg(blockNumber))
+	return result, err
+}
+
 // SubscribeFullPendingTransactions subscribes to new pending transactions.
 func (ec *Client) SubscribeFullPendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (*rpc.ClientSubscription, error) {
 	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions", true)
@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/quest"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -68,6 +69,7 @@ func newTestBackend(t *testing.T) (*node.Node, []*types.Block) {
 		Namespace: "eth",
 		Service:   filters.NewFilterAPI(filterSystem),
 	}})
+	n.RegisterAPIs(quest.APIs(ethservice.APIBackend, ethservice.BlockChain().GetVMConfig()))
 
 	// Import the test chain.
 	if err := n.Start(); err != nil {
@@ -133,6 +135,9 @@ func TestGethClient(t *testing.T) {
 		}, {
 			"TestGetNodeInfo",
 			func(t *testing.T) { testGetNodeInfo(t, client) },
+		}, {
+			"TestQuest",
+			func(t *testing.T) { testQuest(t, client) },
 		}, {
 			"TestSubscribePendingTxHashes",
 			func(t *testing.T) { testSubscribePendingTransactions(t, client) },
@@ -378,6 +383,51 @@ func testGetNodeInfo(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testQuest(t *testing.T, client *rpc.Client) {
+	ec := New(client)
+	status, err := ec.QuestStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The test chain doesn't schedule the Quantum fork
+	if status.ForkActive || status.Processor != "" || status.Backend != "auto" || status.Qubits != 0 {
+		t.Fatalf("unexpected quest status: %+v", status)
+	}
+	if _, err := ec.QuestStats(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ec.QuestProfile(context.Background()); err == nil {
+		t.Fatal("expected error for profile with profiling disabled")
+	}
+	// Bell pair: both qubits measure the same value.
+	seed := common.HexToHash("0x01")
+	result, err := ec.SimulateCircuit(context.Background(), CircuitArgs{
+		QASM:       "OPENQASM 3; qubit[2] q; bit[2] c; h q[0]; cx q[0], q[1]; c[0] = measure q[0]; c[1] = measure q[1];",
+		Seed:       &seed,
+		Amplitudes: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Clbits) != 2 || result.Clbits[0] != result.Clbits[1] || len(result.Amplitudes) != 4 {
+		t.Fatalf("unexpected circuit result: %+v", result)
+	}
+	if _, err := ec.SimulateCircuit(context.Background(), CircuitArgs{QASM: "OPENQASM 3; qubit[64] q;"}); err == nil {
+		t.Fatal("expected error for oversized circuit")
+	}
+	// 2^20 amplitudes times 2048 gates exceeds the work bound of the simulation.
+	if _, err := ec.SimulateCircuit(context.Background(), CircuitArgs{QASM: "OPENQASM 3; qubit[20] q;" + strings.Repeat(" h q[0];", 2048)}); err == nil {
+		t.Fatal("expected error for circuit exceeding the work bound")
+	}
+	register, err := ec.QuantumRegisterState(context.Background(), testAddr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if register != nil {
+		t.Fatalf("unexpected register for account without one: %+v", register)
+	}
+}
+
 func testSetHead(t *testing.T, client *rpc.Client) {
 	ec := New(client)
 	err := ec.SetHead(context.Background(), big.NewInt(0))
@@ -19,8 +19,13 @@ package simulated
 import (
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/node"
+
+	// Register the Quest processor used by WithQuest
+	_ "github.com/ethereum/go-ethereum/quest"
 )
 
 // WithBlockGasLimit configures the simulated backend to target a specific gas limit
@@ -53,3 +58,31 @@ func WithMinerMinTip(tip *big.Int) func(nodeConf *node.Config, ethConf *ethconfi
 		ethConf.Miner.GasPrice = tip
 	}
 }
+
+// WithVMConfig configures the EVM of the simulated backend. The quantum settings
+// given to WithQuest take precedence over the ones in config.
+func WithVMConfig(config vm.Config) func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+	return func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		ethConf.VMConfig = &config
+	}
+}
+
+// WithQuest activates the Quantum fork at genesis, which attaches the Quest
+// quantum processor, and configures the processor with the given options, so
+// contracts using quantum instructions can be deployed and called.
+//
+// The simulated beacon uses a random PREVRANDAO for every block, so unless opts
+// sets a measurement seed, measurements are seeded with the zero hash to make
+// their outcomes reproducible across runs.
+func WithQuest(opts ethconfig.QuestConfig) func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+	return func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		if opts.MeasurementSeed == nil {
+			opts.MeasurementSeed = new(common.Hash)
+		}
+		ethConf.Quest = opts
+
+		config := *ethConf.Genesis.Config
+		config.QuantumTime = new(uint64)
+		ethConf.Genesis.Config = &config
+	}
+}
@@ -23,8 +23,11 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -72,3 +75,46 @@ func TestWithCallGasLimitOption(t *testing.T) {
 		t.Fatalf("error mismatch: have %v, want %v", err, core.ErrIntrinsicGas)
 	}
 }
+
+// Tests that quantum instructions are only available with the Quest option and
+// that measurements are reproducible across blocks with a fixed seed.
+func TestWithQuestOption(t *testing.T) {
+	// Prepare a Bell pair and return both measured bits
+	code := []byte{
+		byte(vm.PUSH1), 2, byte(vm.QUANTUM), byte(vm.QINIT),
+		byte(vm.PUSH1), 0, byte(vm.QUANTUM), byte(vm.QHADAMARD),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 1, byte(vm.QUANTUM), byte(vm.QCNOT),
+		byte(vm.PUSH1), 0, byte(vm.QUANTUM), byte(vm.QMEASURE), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 1, byte(vm.QUANTUM), byte(vm.QMEASURE), byte(vm.PUSH1), 32, byte(vm.MSTORE),
+		byte(vm.PUSH1), 64, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	contract := common.HexToAddress("0xbe11")
+	alloc := types.GenesisAlloc{contract: {Code: code}}
+	call := func(sim *Backend) ([]byte, error) {
+		return sim.Client().CallContract(context.Background(), ethereum.CallMsg{To: &contract, Gas: 1_000_000}, nil)
+	}
+	sim := NewBackend(alloc)
+	if _, err := call(sim); err == nil {
+		t.Error("quantum instructions executed without the Quest option")
+	}
+	sim.Close()
+
+	sim = NewBackend(alloc, WithQuest(ethconfig.QuestConfig{}))
+	defer sim.Close()
+
+	first, err := call(sim)
+	if err != nil {
+		t.Fatalf("failed to call quantum contract: %v", err)
+	}
+	if len(first) != 64 || first[31] != first[63] {
+		t.Fatalf("uncorrelated Bell pair measurement: %x", first)
+	}
+	sim.Commit()
+	second, err := call(sim)
+	if err != nil {
+		t.Fatalf("failed to call quantum contract: %v", err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("measurement not reproducible: have %x, want %x", second, first)
+	}
+}
@@ -27,6 +27,7 @@ var Modules = map[string]string{
 	"rpc":    RpcJs,
 	"txpool": TxpoolJs,
 	"dev":    DevJs,
+	"quest":  QuestJs,
 }
 
 const CliqueJs = `
@@ -420,6 +421,22 @@ web3._extend({
 			call: 'debug_storageRangeAt',
 			params: 5,
 		}),
+		new web3._extend.Method({
+			name: 'parallelStats',
+			call: 'debug_parallelStats',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'exportQuantumState',
+			call: 'debug_exportQuantumState',
+			params: 3,
+			inputFormatter: [null, null, null],
+		}),
+		new web3._extend.Method({
+			name: 'importQuantumState',
+			call: 'debug_importQuantumState',
+			params: 1,
+		}),
 		new web3._extend.Method({
 			name: 'getModifiedAccountsByNumber',
 			call: 'debug_getModifiedAccountsByNumber',
@@ -720,3 +737,36 @@ web3._extend({
 	],
 });
 `
+
+const QuestJs = `
+web3._extend({
+	property: 'quest',
+	methods: [
+		new web3._extend.Method({
+			name: 'simulateCircuit',
+			call: 'quest_simulateCircuit',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'registerState',
+			call: 'quest_registerState',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'status',
+			getter: 'quest_status'
+		}),
+		new web3._extend.Property({
+			name: 'stats',
+			getter: 'quest_stats'
+		}),
+		new web3._extend.Property({
+			name: 'profile',
+			getter: 'quest_profile'
+		}),
+	]
+});
+`
@@ -139,6 +139,57 @@ func TestConfigRules(t *testing.T) {
 	}
 }
 
+func TestQuantumRules(t *testing.T) {
+	c := &ChainConfig{
+		LondonBlock:  new(big.Int),
+		ShanghaiTime: newUint64(0),
+		CancunTime:   newUint64(0),
+		QuantumTime:  newUint64(100),
+	}
+	// The quantum instruction set extends Prague and must not activate without it
+	if r := c.Rules(big.NewInt(0), true, 200); r.IsQuantum {
+		t.Errorf("expected quantum to be inactive without prague")
+	}
+	c.PragueTime = newUint64(50)
+	if r := c.Rules(big.NewInt(0), true, 99); r.IsQuantum {
+		t.Errorf("expected %v to not be quantum", 99)
+	}
+	if r := c.Rules(big.NewInt(0), true, 100); !r.IsQuantum {
+		t.Errorf("expected %v to be quantum", 100)
+	}
+	if r := c.Rules(big.NewInt(0), false, 100); r.IsQuantum {
+		t.Errorf("expected pre-merge rules to not be quantum")
+	}
+}
+
+func TestQuantumForkOrder(t *testing.T) {
+	c := &ChainConfig{
+		HomesteadBlock:      new(big.Int),
+		EIP150Block:         new(big.Int),
+		EIP155Block:         new(big.Int),
+		EIP158Block:         new(big.Int),
+		ByzantiumBlock:      new(big.Int),
+		ConstantinopleBlock: new(big.Int),
+		PetersburgBlock:     new(big.Int),
+		IstanbulBlock:       new(big.Int),
+		BerlinBlock:         new(big.Int),
+		LondonBlock:         new(big.Int),
+		ShanghaiTime:        newUint64(0),
+		PragueTime:          newUint64(20),
+		QuantumTime:         newUint64(10),
+		BlobScheduleConfig: &BlobScheduleConfig{
+			Prague: DefaultPragueBlobConfig,
+		},
+	}
+	if err := c.CheckConfigForkOrder(); err == nil {
+		t.Fatal("expected error for quantum scheduled before prague")
+	}
+	c.QuantumTime = newUint64(30)
+	if err := c.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("unexpected fork order error: %v", err)
+	}
+}
+
 func TestTimestampCompatError(t *testing.T) {
 	require.Equal(t, new(ConfigCompatError).Error(), "")
 
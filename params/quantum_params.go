@@ -0,0 +1,213 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import "math/bits"
+
+// QuantumAmplitudeSize is the size in bytes of one amplitude of a quantum
+// register state vector (two float64 components).
+const QuantumAmplitudeSize = 16
+
+// QuantumShorAttempts is the number of order-finding circuits QSHOR runs
+// before giving up. The gas of QSHOR covers all of them.
+const QuantumShorAttempts = 4
+
+// QuantumGasTable contains the gas prices and register limits of the QUANTUM
+// instructions for a fork. The simulation of a register touches its whole representation: all
+// 2^n amplitudes of a dense state vector, the stored amplitudes of a sparse
+// one or the tableau of a stabilizer register. Every instruction is therefore
+// charged per 32-byte word of the representation it operates on, on top of a
+// constant base cost.
+type QuantumGasTable struct {
+	MaxQubits uint64 // Maximum number of qubits of a register
+
+	InitGas              uint64 // Base cost of QINIT
+	DestroyGas           uint64 // Cost of QDESTROY
+	ResetGas             uint64 // Base cost of QRESET
+	RegisterWordGas      uint64 // Per word of register allocated by QINIT, grown by an instruction or cleared by QRESET
+	RegisterQuadCoeffDiv uint64 // Divisor for the quadratic particle of the register allocation cost
+	LoadWordGas          uint64 // Per word of register decoded from the state, at most once per call frame
+	StoreWordGas         uint64 // Per word of register encoded, hashed and journaled when a call frame writes it back
+
+	GateGas     uint64 // Base cost of a gate instruction
+	PassWordGas uint64 // Per word of register for every elementary gate applied to it
+
+	MeasureGas     uint64 // Base cost of QMEASURE and QMEASUREALL
+	MeasureWordGas uint64 // Per word of register read and collapsed by a measurement
+
+	ShorGas   uint64 // Base cost of QSHOR
+	GroverGas uint64 // Base cost of QGROVER
+	QFTGas    uint64 // Base cost of QQFT
+	QPEGas    uint64 // Base cost of QQPE
+	RandomGas uint64 // Base cost of QRANDOM
+}
+
+// QuantumGasTableQuantum is the gas table of the QUANTUM instructions
+// introduced by the Quantum fork. The register allocation is priced like EVM
+// memory, so that the 512 MiB state vector of a 25 qubit register is beyond
+// any block gas limit. Registers are capped at 64 qubits, so that the outcome
+// of measuring a whole register fits into a single basis index.
+var QuantumGasTableQuantum = QuantumGasTable{
+	MaxQubits: 64,
+
+	InitGas:              5000,
+	DestroyGas:           1000,
+	ResetGas:             2000,
+	RegisterWordGas:      MemoryGas,
+	RegisterQuadCoeffDiv: QuadCoeffDiv,
+	LoadWordGas:          CopyGas,
+	StoreWordGas:         CopyGas + Keccak256WordGas,
+
+	GateGas:     100,
+	PassWordGas: 1,
+
+	MeasureGas:     200,
+	MeasureWordGas: 2,
+
+	ShorGas:   50000,
+	GroverGas: 30000,
+	QFTGas:    10000,
+	QPEGas:    15000,
+	RandomGas: 5000,
+}
+
+// QuantumGasTableFor returns the gas table of the QUANTUM instructions active
+// under the given rules, or nil if the instructions are not enabled.
+func QuantumGasTableFor(rules Rules) *QuantumGasTable {
+	if rules.IsQuantum {
+		return &QuantumGasTableQuantum
+	}
+	return nil
+}
+
+// QuantumStateWords returns the number of 32-byte words of the dense state
+// vector of a register with the given number of qubits, rounded up. The
+// boolean is false if the size does not fit into an uint64.
+func QuantumStateWords(qubits uint64) (uint64, bool) {
+	// 2^qubits amplitudes of 16 bytes each form 2^(qubits-1) words
+	if qubits == 0 {
+		return 1, true
+	}
+	if qubits > 64 {
+		return 0, false
+	}
+	return 1 << (qubits - 1), true
+}
+
+// InitCost returns the cost of allocating a register of the given number of
+// words: a constant plus the register cost.
+func (t *QuantumGasTable) InitCost(words uint64) (uint64, bool) {
+	cost, ok := t.RegisterCost(words)
+	if !ok {
+		return 0, false
+	}
+	return addGas(t.InitGas, cost)
+}
+
+// RegisterCost returns the cost of holding a register of the given number of
+// words, a linear and a quadratic term mirroring the EVM memory expansion
+// cost.
+func (t *QuantumGasTable) RegisterCost(words uint64) (uint64, bool) {
+	hi, square := bits.Mul64(words, words)
+	if hi != 0 {
+		return 0, false
+	}
+	linear, ok := mulGas(words, t.RegisterWordGas)
+	if !ok {
+		return 0, false
+	}
+	return addGas(linear, square/t.RegisterQuadCoeffDiv)
+}
+
+// GrowthCost returns the cost of growing a register from one size to a
+// larger one, zero if it does not grow. Like memory expansion, only the
+// difference of the register costs is charged.
+func (t *QuantumGasTable) GrowthCost(from, to uint64) (uint64, bool) {
+	if to <= from {
+		return 0, true
+	}
+	prev, ok := t.RegisterCost(from)
+	if !ok {
+		return 0, false
+	}
+	next, ok := t.RegisterCost(to)
+	if !ok {
+		return 0, false
+	}
+	return next - prev, true
+}
+
+// LoadCost returns the cost of decoding a stored register of the given number
+// of words.
+func (t *QuantumGasTable) LoadCost(words uint64) (uint64, bool) {
+	return wordCost(0, words, 1, t.LoadWordGas)
+}
+
+// StoreCost returns the cost of writing a register of the given number of
+// words back to the state: encoding, hashing it into the commitment and
+// journaling the previous register.
+func (t *QuantumGasTable) StoreCost(words uint64) (uint64, bool) {
+	return wordCost(0, words, 1, t.StoreWordGas)
+}
+
+// PassCost returns base plus the cost of applying the given number of
+// elementary gates to a register of the given number of words.
+func (t *QuantumGasTable) PassCost(base, words, passes uint64) (uint64, bool) {
+	return wordCost(base, words, passes, t.PassWordGas)
+}
+
+// MeasureCost returns the cost of a measurement of a register of the given
+// number of words.
+func (t *QuantumGasTable) MeasureCost(words uint64) (uint64, bool) {
+	return wordCost(t.MeasureGas, words, 1, t.MeasureWordGas)
+}
+
+// ResetCost returns the cost of clearing a register of the given number of
+// words.
+func (t *QuantumGasTable) ResetCost(words uint64) (uint64, bool) {
+	return wordCost(t.ResetGas, words, 1, t.RegisterWordGas)
+}
+
+// wordCost returns base + words * passes * wordGas.
+func wordCost(base, words, passes, wordGas uint64) (uint64, bool) {
+	cost, ok := mulGas(words, passes)
+	if !ok {
+		return 0, false
+	}
+	if cost, ok = mulGas(cost, wordGas); !ok {
+		return 0, false
+	}
+	return addGas(base, cost)
+}
+
+// mulGas multiplies two gas values, reporting overflow.
+func mulGas(a, b uint64) (uint64, bool) {
+	hi, lo := bits.Mul64(a, b)
+	return lo, hi == 0
+}
+
+// addGas adds gas values, reporting overflow.
+func addGas(values ...uint64) (uint64, bool) {
+	var sum uint64
+	for _, v := range values {
+		var carry uint64
+		if sum, carry = bits.Add64(sum, v, 0); carry != 0 {
+			return 0, false
+		}
+	}
+	return sum, true
+}
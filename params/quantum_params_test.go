@@ -0,0 +1,106 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestQuantumGasTableFor(t *testing.T) {
+	quantumTime := uint64(10)
+	config := *MergedTestChainConfig
+	config.QuantumTime = &quantumTime
+
+	if table := QuantumGasTableFor(config.Rules(big.NewInt(0), true, 9)); table != nil {
+		t.Fatal("gas table active before the Quantum fork")
+	}
+	if table := QuantumGasTableFor(config.Rules(big.NewInt(0), true, 10)); table != &QuantumGasTableQuantum {
+		t.Fatal("Quantum gas table not active after the fork")
+	}
+}
+
+// TestQuantumInitCost checks the register allocation cost against a reference
+// table. The dense state vector of a 25 qubit register (2^24 words, 512 MiB)
+// must not fit into any block.
+func TestQuantumInitCost(t *testing.T) {
+	table := &QuantumGasTableQuantum
+	for _, tt := range []struct {
+		words uint64
+		cost  uint64
+		ok    bool
+	}{
+		{1, 5003, true},
+		{2048, 19336, true},
+		{32768, 2200456, true},
+		{1 << 24, 549806150536, true},
+		{1 << 31, 9007205697196936, true},
+		{1 << 32, 0, false},
+	} {
+		cost, ok := table.InitCost(tt.words)
+		if cost != tt.cost || ok != tt.ok {
+			t.Errorf("%d words: have (%d, %v), want (%d, %v)", tt.words, cost, ok, tt.cost, tt.ok)
+		}
+	}
+}
+
+func TestQuantumStateWords(t *testing.T) {
+	for _, tt := range []struct {
+		qubits uint64
+		words  uint64
+		ok     bool
+	}{
+		{0, 1, true},
+		{1, 1, true},
+		{10, 512, true},
+		{25, 1 << 24, true},
+		{64, 1 << 63, true},
+		{65, 0, false},
+	} {
+		words, ok := QuantumStateWords(tt.qubits)
+		if words != tt.words || ok != tt.ok {
+			t.Errorf("%d qubits: have (%d, %v), want (%d, %v)", tt.qubits, words, ok, tt.words, tt.ok)
+		}
+	}
+}
+
+func TestQuantumWordCosts(t *testing.T) {
+	table := &QuantumGasTableQuantum
+	for _, tt := range []struct {
+		name string
+		fn   func() (uint64, bool)
+		cost uint64
+		ok   bool
+	}{
+		{"gate 512 words", func() (uint64, bool) { return table.PassCost(table.GateGas, 512, 1) }, 612, true},
+		{"15 passes 512 words", func() (uint64, bool) { return table.PassCost(table.GateGas, 512, 15) }, 7780, true},
+		{"gate 2^63 words", func() (uint64, bool) { return table.PassCost(table.GateGas, 1<<63, 2) }, 0, false},
+		{"measure 512 words", func() (uint64, bool) { return table.MeasureCost(512) }, 1224, true},
+		{"reset 512 words", func() (uint64, bool) { return table.ResetCost(512) }, 3536, true},
+		{"grow 512 to 1024 words", func() (uint64, bool) { return table.GrowthCost(512, 1024) }, 3072, true},
+		{"shrink 1024 to 512 words", func() (uint64, bool) { return table.GrowthCost(1024, 512) }, 0, true},
+		{"grow to 2^32 words", func() (uint64, bool) { return table.GrowthCost(1, 1<<32) }, 0, false},
+		{"load 512 words", func() (uint64, bool) { return table.LoadCost(512) }, 1536, true},
+		{"store 512 words", func() (uint64, bool) { return table.StoreCost(512) }, 4608, true},
+		{"store 2^63 words", func() (uint64, bool) { return table.StoreCost(1 << 63) }, 0, false},
+	} {
+		cost, ok := tt.fn()
+		if cost != tt.cost || ok != tt.ok {
+			t.Errorf("%s: have (%d, %v), want (%d, %v)", tt.name, cost, ok, tt.cost, tt.ok)
+		}
+	}
+}
@@ -2,105 +2,73 @@
 package quest
 
 import (
-	"math/big"
-
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
-	"github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/quest/quantum"
+	"github.com/holiman/uint256"
 )
 
-// QuestExecutor представляет замену стандартному EVM-процессору
+// QuestExecutor выполняет контракты с квантовыми инструкциями. Байткод
+// исполняется интерпретатором EVM, а инструкции префикса vm.QUANTUM -
+// контекстом QEVM, регистры которого хранятся в состоянии контрактов.
 type QuestExecutor struct {
-	processor *QuestProcessor
-	ctx       *vm.BlockContext
-	txCtx     *vm.TxContext
-	statedb   *state.StateDB
-	config    *vm.Config
-	chainCfg  *params.ChainConfig
+	evm  *vm.EVM
+	qevm *quantum.QEVMContext
 }
 
-// NewQuestExecutor создает новый экземпляр QuestExecutor
-func NewQuestExecutor(blockCtx *vm.BlockContext, txCtx *vm.TxContext, statedb *state.StateDB, chainCfg *params.ChainConfig, config *vm.Config) *QuestExecutor {
-	return &QuestExecutor{
-		processor: GetQuestProcessor(),
-		ctx:       blockCtx,
-		txCtx:     txCtx,
-		statedb:   statedb,
-		config:    config,
-		chainCfg:  chainCfg,
+// NewQuestExecutor создает исполнитель для evm. Представление и максимальный
+// размер регистров задаются правилами форка.
+func NewQuestExecutor(evm *vm.EVM, useGPU bool, deviceID int) (*QuestExecutor, error) {
+	qevm, err := quantum.NewQEVMContext(evm, useGPU, deviceID)
+	if err != nil {
+		return nil, err
 	}
+	return &QuestExecutor{evm: evm, qevm: qevm}, nil
 }
 
-// Execute выполняет транзакцию с использованием квантового процессора Quest
-func (q *QuestExecutor) Execute(contract *vm.Contract, input []byte, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	// Инициализируем процессор Quest, если он еще не инициализирован
-	if err := q.processor.Initialize(); err != nil {
-		log.Error("Не удалось инициализировать Quest процессор", "error", err)
-		return nil, contract.Gas, err
-	}
-
-	// Создаем транзакцию для квантового исполнения
-	tx := types.NewTransaction(
-		q.txCtx.TxIdx.Uint64(),
-		contract.Address(),
-		big.NewInt(0),
-		contract.Gas,
-		q.txCtx.GasPrice,
-		input,
-	)
+// Execute выполняет код контракта. Газ списывается с contract по мере
+// выполнения, как и при работе интерпретатора.
+func (q *QuestExecutor) Execute(contract *vm.Contract, input []byte, readOnly bool) ([]byte, error) {
+	// Зерно измерений кадра вызова привязывается к транзакции, исполняемому
+	// контракту и глубине вызова. При возврате продолжается поток
+	// вызывающего кадра.
+	caller := q.qevm.EnterFrame(q.evm.StateDB.TxHash(), contract.Address(), q.evm.Depth())
+	defer q.qevm.LeaveFrame(caller)
 
-	// Выполняем транзакцию с использованием квантового процессора
-	log.Debug("Выполнение транзакции с использованием квантового процессора Quest", 
-		"contract", contract.Address().Hex(),
-		"caller", contract.Caller().Hex(),
-		"value", contract.Value(),
-		"gas", contract.Gas,
-		"input_size", len(input),
-	)
-
-	// Подготавливаем состояние для квантового выполнения (упрощенная версия)
-	stateContext := &StateContext{
-		StateDB: q.statedb,
-		Block:   *q.ctx,
-		Tx:      *q.txCtx,
-	}
+	return q.evm.Interpreter().Run(contract, input, readOnly)
+}
 
-	// Выполняем транзакцию
-	result, err := q.processor.ExecuteTransaction(tx, stateContext)
-	if err != nil {
-		log.Error("Ошибка выполнения транзакции с использованием квантового процессора", "error", err)
-		return nil, 0, err
-	}
+// ExecuteQuantumOp выполняет инструкцию префикса vm.QUANTUM над регистром
+// исполняемого контракта
+func (q *QuestExecutor) ExecuteQuantumOp(op vm.QuantumOp, scope *vm.ScopeContext, args []uint256.Int) ([]uint256.Int, error) {
+	return q.qevm.ExecuteQuantumOp(op, scope, args)
+}
 
-	// Для простоты считаем, что весь газ использован (в реальной реализации здесь будет более сложная логика)
-	// В реальной реализации газ должен вычисляться на основе выполненных операций
-	gasUsed := contract.Gas
-	remainingGas = 0
+// FlushQuantumRegisters записывает в состояние регистры, измененные текущим
+// кадром вызова
+func (q *QuestExecutor) FlushQuantumRegisters() {
+	q.qevm.FlushQuantumRegisters()
+}
 
-	log.Debug("Транзакция успешно выполнена с использованием квантового процессора Quest",
-		"gas_used", gasUsed,
-		"result_size", len(result),
-	)
+// QuantumRegisterCommitment возвращает обязательство квантового регистра
+// контракта, хранящееся в его состоянии. Нулевой хэш означает отсутствие
+// регистра.
+func (q *QuestExecutor) QuantumRegisterCommitment(addr common.Address) common.Hash {
+	return q.evm.StateDB.GetState(addr, state.QuantumRegisterSlot)
+}
 
-	return result, remainingGas, nil
+// MaxQubits возвращает максимальный размер регистра по правилам форка
+func (q *QuestExecutor) MaxQubits() int {
+	return q.qevm.MaxQubits()
 }
 
-// CalculateStateHash возвращает хэш состояния после выполнения транзакций
-func (q *QuestExecutor) CalculateStateHash() (common.Hash, error) {
-	return q.processor.CalcStateHash()
+// IsAvailable возвращает true, если контекст QEVM активен
+func (q *QuestExecutor) IsAvailable() bool {
+	return q.qevm.IsActive()
 }
 
 // Close освобождает ресурсы, занятые QuestExecutor
 func (q *QuestExecutor) Close() error {
-	return q.processor.Close()
+	return q.qevm.Destroy()
 }
-
-// StateContext представляет контекст состояния для выполнения транзакции
-type StateContext struct {
-	StateDB state.StateDB
-	Block   vm.BlockContext
-	Tx      vm.TxContext
-} 
\ No newline at end of file
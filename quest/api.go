@@ -0,0 +1,285 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/quest/quantum"
+	"github.com/ethereum/go-ethereum/quest/utils"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Ограничения quest_simulateCircuit: схема выполняется в обработчике запроса,
+// поэтому ее размер ограничен независимо от настроек процессора. Работа
+// схемы оценивается сверху как 2^n обновлений амплитуд на операцию, как для
+// плотного вектора состояния, а время выполнения дополнительно ограничено
+// RPCEVMTimeout узла.
+const (
+	maxCircuitQubits    = 20      // Максимальное количество кубитов схемы
+	maxCircuitGates     = 10000   // Максимальное количество операций схемы
+	maxCircuitWork      = 1 << 30 // Максимальная оценка работы схемы: 2^n × число операций
+	maxAmplitudesQubits = 12      // Максимальный размер регистра, амплитуды которого возвращаются
+)
+
+var (
+	// errProfilingDisabled возникает при запросе профиля без QuestProfiling
+	errProfilingDisabled = errors.New("quest: профилирование отключено, включите --quest.profiling")
+
+	// errCircuitTooLarge возникает, если схема превышает ограничения
+	// quest_simulateCircuit
+	errCircuitTooLarge = errors.New("quest: схема превышает ограничения симуляции")
+)
+
+// Backend - часть бэкенда узла, необходимая API квантового процессора
+type Backend interface {
+	ChainConfig() *params.ChainConfig
+	CurrentHeader() *types.Header
+	StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error)
+	RPCEVMTimeout() time.Duration
+}
+
+// APIs возвращает RPC API пространства имен quest
+func APIs(backend Backend, config *vm.Config) []rpc.API {
+	return []rpc.API{{
+		Namespace: "quest",
+		Service:   NewAPI(backend, config),
+	}}
+}
+
+// API предоставляет RPC методы для просмотра состояния квантового процессора
+// и выполнения квантовых схем
+type API struct {
+	backend Backend
+	config  *vm.Config
+
+	hardware     string // Описание оборудования, определяется при первом запросе
+	hardwareOnce sync.Once
+}
+
+// NewAPI создает API квантового процессора с настройками EVM узла config
+func NewAPI(backend Backend, config *vm.Config) *API {
+	return &API{backend: backend, config: config}
+}
+
+// Status - настройки квантового процессора узла. Процессор и максимальный
+// размер регистра определяются правилами форка головного блока.
+type Status struct {
+	Processor   string `json:"processor"`
+	Backend     string `json:"backend"`
+	Qubits      int    `json:"qubits"`
+	Parallelism int    `json:"parallelism"`
+	ForceCPU    bool   `json:"forceCPU"`
+	Profiling   bool   `json:"profiling"`
+	ForkActive  bool   `json:"forkActive"`
+	Hardware    string `json:"hardware"`
+}
+
+// Status возвращает настройки квантового процессора и признак активации
+// форка Quantum в головном блоке
+func (api *API) Status() Status {
+	api.hardwareOnce.Do(func() {
+		api.hardware = utils.NewHardwareDetector().Description()
+	})
+	head := api.backend.CurrentHeader()
+	rules := api.backend.ChainConfig().Rules(head.Number, head.Difficulty.Sign() == 0, head.Time)
+
+	var qubits int
+	if table := params.QuantumGasTableFor(rules); table != nil {
+		qubits = int(table.MaxQubits)
+	}
+	return Status{
+		Processor:   api.config.ProcessorName(rules),
+		Backend:     quantum.ConsensusBackend.String(),
+		Qubits:      qubits,
+		Parallelism: api.config.QuestLevelParallelism,
+		ForceCPU:    api.config.QuestForceCPU,
+		Profiling:   api.config.QuestProfiling,
+		ForkActive:  rules.IsQuantum,
+		Hardware:    api.hardware,
+	}
+}
+
+// Stats возвращает счетчики операций всех квантовых процессоров узла
+func (api *API) Stats() Stats {
+	return GetStats()
+}
+
+// OperationProfile - профиль квантовой инструкции, длительности в наносекундах
+type OperationProfile struct {
+	Count     int64         `json:"count"`
+	TotalTime time.Duration `json:"totalTime"`
+	MinTime   time.Duration `json:"minTime"`
+	MaxTime   time.Duration `json:"maxTime"`
+	AvgTime   time.Duration `json:"avgTime"`
+}
+
+// Profile возвращает профиль квантовых инструкций по их мнемоникам
+func (api *API) Profile() (map[string]OperationProfile, error) {
+	if !api.config.QuestProfiling {
+		return nil, errProfilingDisabled
+	}
+	return Profile(), nil
+}
+
+// CircuitArgs - аргументы quest_simulateCircuit
+type CircuitArgs struct {
+	QASM       string       `json:"qasm"`       // Схема в OpenQASM 3
+	Backend    string       `json:"backend"`    // Бэкенд регистра, по умолчанию бэкенд форка Quantum
+	Seed       *common.Hash `json:"seed"`       // Зерно генератора измерений
+	Amplitudes bool         `json:"amplitudes"` // Вернуть амплитуды конечного состояния
+}
+
+// CircuitResult - результат quest_simulateCircuit
+type CircuitResult struct {
+	Clbits     []int        `json:"clbits"`
+	Commitment common.Hash  `json:"commitment"`
+	Backend    string       `json:"backend"`
+	Amplitudes [][2]float64 `json:"amplitudes,omitempty"`
+}
+
+// SimulateCircuit выполняет схему над новым регистром, не обращаясь к
+// состоянию цепочки. Измерения детерминированы зерном seed. Выполнение
+// прерывается при отмене запроса или по истечении RPCEVMTimeout.
+func (api *API) SimulateCircuit(ctx context.Context, args CircuitArgs) (*CircuitResult, error) {
+	circuit, err := quantum.ParseQASM(args.QASM)
+	if err != nil {
+		return nil, err
+	}
+	if circuit.NumQubits > maxCircuitQubits {
+		return nil, fmt.Errorf("%w: %d кубитов, не более %d", errCircuitTooLarge, circuit.NumQubits, maxCircuitQubits)
+	}
+	if len(circuit.Gates) > maxCircuitGates {
+		return nil, fmt.Errorf("%w: %d операций, не более %d", errCircuitTooLarge, len(circuit.Gates), maxCircuitGates)
+	}
+	if work := uint64(len(circuit.Gates)) << circuit.NumQubits; work > maxCircuitWork {
+		return nil, fmt.Errorf("%w: оценка работы 2^%d × %d операций превышает %d", errCircuitTooLarge, circuit.NumQubits, len(circuit.Gates), maxCircuitWork)
+	}
+	if args.Amplitudes && circuit.NumQubits > maxAmplitudesQubits {
+		return nil, fmt.Errorf("%w: амплитуды возвращаются для схем не более чем из %d кубитов", errCircuitTooLarge, maxAmplitudesQubits)
+	}
+	backend := quantum.ConsensusBackend
+	if args.Backend != "" {
+		if backend, err = quantum.ParseBackend(args.Backend); err != nil {
+			return nil, err
+		}
+	}
+	var seed common.Hash
+	if args.Seed != nil {
+		seed = *args.Seed
+	}
+	env, err := quantum.NewQuestEnvWithBackend(max(circuit.NumQubits, 1), backend, false, 0, seed)
+	if err != nil {
+		return nil, err
+	}
+	defer env.Destroy()
+
+	// Схема выполняется не дольше вызова eth_call
+	var cancel context.CancelFunc
+	if timeout := api.backend.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	clbits, err := env.RunCircuitContext(ctx, circuit)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("quest: выполнение схемы прервано (timeout = %v)", api.backend.RPCEVMTimeout())
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &CircuitResult{
+		Clbits:     make([]int, len(clbits)),
+		Commitment: env.StateCommitment(),
+		Backend:    env.Backend().String(),
+	}
+	for i, bit := range clbits {
+		result.Clbits[i] = int(bit)
+	}
+	if args.Amplitudes {
+		result.Amplitudes = amplitudes(env.GetStateVector())
+	}
+	return result, nil
+}
+
+// RegisterState - квантовый регистр аккаунта
+type RegisterState struct {
+	Address    common.Address `json:"address"`
+	Commitment common.Hash    `json:"commitment"`
+	Qubits     int            `json:"qubits"`
+	Backend    string         `json:"backend"`
+	Register   hexutil.Bytes  `json:"register"`
+	Amplitudes [][2]float64   `json:"amplitudes,omitempty"`
+}
+
+// RegisterState возвращает квантовый регистр аккаунта в указанном блоке или
+// nil, если у аккаунта нет регистра
+func (api *API) RegisterState(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*RegisterState, error) {
+	statedb, _, err := api.backend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	data := statedb.GetQuantumRegister(address)
+	if data == nil {
+		return nil, nil
+	}
+	return NewRegisterState(address, statedb.GetState(address, state.QuantumRegisterSlot), data)
+}
+
+// NewRegisterState декодирует закодированный регистр data аккаунта address с
+// обязательством commitment. Амплитуды возвращаются только для небольших
+// регистров.
+func NewRegisterState(address common.Address, commitment common.Hash, data []byte) (*RegisterState, error) {
+	env, err := quantum.DecodeQuestEnv(data, quantum.BackendAuto, common.Hash{})
+	if err != nil {
+		return nil, err
+	}
+	defer env.Destroy()
+
+	result := &RegisterState{
+		Address:    address,
+		Commitment: commitment,
+		Qubits:     env.GetQubitCount(),
+		Backend:    env.Backend().String(),
+		Register:   data,
+	}
+	if result.Qubits <= maxAmplitudesQubits {
+		result.Amplitudes = amplitudes(env.GetStateVector())
+	}
+	return result, nil
+}
+
+// amplitudes переводит вектор состояния в пары действительной и мнимой частей
+func amplitudes(vector []complex128) [][2]float64 {
+	result := make([][2]float64, len(vector))
+	for i, amp := range vector {
+		result[i] = [2]float64{real(amp), imag(amp)}
+	}
+	return result
+}
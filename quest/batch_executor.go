@@ -0,0 +1,210 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quest
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// BatchEnv - окружение строящегося блока, в котором выполняются батчи
+// транзакций. Как и окружение майнера, оно изменяется выполнением: к State
+// применяются включенные транзакции, GasPool и Header.GasUsed учитывают их
+// газ, а TxIndex сдвигается на их число.
+type BatchEnv struct {
+	EVM     *vm.EVM        // EVM блока, задает контекст блока и конфигурацию
+	State   *state.StateDB // Состояние, к которому применяются транзакции
+	Header  *types.Header  // Заголовок строящегося блока
+	GasPool *core.GasPool  // Оставшийся газ блока, nil - BatchGasLimit
+	TxIndex int            // Позиция следующей транзакции в блоке
+}
+
+// executeBatch выполняет транзакции txs в окружении env по порядку, как их
+// выполнил бы майнер. Транзакция, которую нельзя включить в блок, не изменяет
+// состояние и отклоняется с ошибкой; следующие транзакции выполняются
+// поверх состояния без нее. Если Block-STM включен в конфигурации EVM
+// (экспериментально) и это допустимо, транзакции выполняются параллельно в
+// workers потоках (0 - по числу процессоров, 1 - последовательно), результат
+// от этого не зависит.
+func executeBatch(env *BatchEnv, txs []*types.Transaction, workers int) *BatchResult {
+	start := time.Now()
+	result := &BatchResult{
+		Receipts: make([]*types.Receipt, len(txs)),
+		Errors:   make([]error, len(txs)),
+	}
+	if env.GasPool == nil {
+		env.GasPool = new(core.GasPool).AddGas(BatchGasLimit)
+	}
+	config := env.EVM.ChainConfig()
+
+	// Живой трассировщик наблюдает порядок выполнения, а до Byzantium
+	// квитанции содержат промежуточные корни состояния
+	parallel := env.EVM.Config.BlockSTM && workers != 1 && len(txs) > 1 && env.EVM.Config.Tracer == nil &&
+		config.IsByzantium(env.Header.Number) && !env.State.GetTrie().IsVerkle() && env.State.Witness() == nil
+
+	for next := 0; next < len(txs); {
+		if parallel {
+			next += env.applyParallel(txs[next:], result, next, workers)
+		} else {
+			next += env.applySequential(txs[next:], result, next)
+		}
+	}
+	for _, receipt := range result.Receipts {
+		if receipt != nil {
+			result.ProcessedTransactions++
+			result.GasUsed += receipt.GasUsed
+			result.Logs = append(result.Logs, receipt.Logs...)
+		}
+	}
+	result.ElapsedTime = time.Since(start)
+	return result
+}
+
+// signer возвращает подписанта транзакций строящегося блока
+func (env *BatchEnv) signer() types.Signer {
+	return types.MakeSigner(env.EVM.ChainConfig(), env.Header.Number, env.Header.Time)
+}
+
+// applySequential применяет транзакции по одной, откатывая отклоненные.
+// Результаты записываются в result начиная с позиции offset. Возвращает
+// число обработанных транзакций.
+func (env *BatchEnv) applySequential(txs []*types.Transaction, result *BatchResult, offset int) int {
+	var (
+		signer    = env.signer()
+		blockHash = env.Header.Hash()
+	)
+	for i, tx := range txs {
+		msg, err := core.TransactionToMessage(tx, signer, env.Header.BaseFee)
+		if err != nil {
+			result.Errors[offset+i] = err
+			continue
+		}
+		var (
+			snap = env.State.Snapshot()
+			gas  = env.GasPool.Gas()
+		)
+		env.State.SetTxContext(tx.Hash(), env.TxIndex)
+		receipt, err := core.ApplyTransactionWithEVM(msg, env.GasPool, env.State, env.Header.Number, blockHash, tx, &env.Header.GasUsed, env.EVM)
+		if err != nil {
+			env.State.RevertToSnapshot(snap)
+			env.GasPool.SetGas(gas)
+			result.Errors[offset+i] = err
+			continue
+		}
+		result.Receipts[offset+i] = receipt
+		env.TxIndex++
+	}
+	return len(txs)
+}
+
+// applyParallel выполняет транзакции алгоритмом Block-STM и применяет
+// результаты по порядку. Отклоненная при выполнении транзакция ничего не
+// записывает, поэтому не влияет на остальные. Транзакция, которой при
+// применении не хватило газа блока, выполнялась с его остатком на начало
+// батча, и следующие транзакции могли прочитать ее записи: применение
+// прекращается, и они выполняются заново. Возвращает число обработанных
+// транзакций.
+func (env *BatchEnv) applyParallel(txs []*types.Transaction, result *BatchResult, offset, workers int) int {
+	var (
+		signer    = env.signer()
+		blockHash = env.Header.Hash()
+		config    = env.EVM.ChainConfig()
+		context   = env.EVM.Context
+		gas       = env.GasPool.Gas()
+		msgs      = make([]*core.Message, len(txs))
+		msgErrs   = make([]error, len(txs))
+	)
+	for i, tx := range txs {
+		msgs[i], msgErrs[i] = core.TransactionToMessage(tx, signer, env.Header.BaseFee)
+	}
+	// Инкарнация может быть выполнена повторно или отброшена
+	incarnation := env.EVM.Config
+	incarnation.Speculative = true
+
+	results, stats := vm.ExecuteBlockSTM(env.State, vm.TxHashes(txs), workers, func(i int, db vm.StateDB) (interface{}, error) {
+		if msgErrs[i] != nil {
+			return nil, msgErrs[i]
+		}
+		snap := db.Snapshot()
+		res, err := core.ApplyMessage(vm.NewEVM(context, db, config, incarnation), msgs[i], new(core.GasPool).AddGas(gas))
+		if err != nil {
+			db.RevertToSnapshot(snap)
+		}
+		return res, err
+	})
+	// Предсказанные зависимости нужны только для сводки. Анализатор
+	// конфигурации обучается core.StateProcessor на импортированных блоках,
+	// без него используется статический анализ транзакций.
+	analyzer := env.EVM.Config.DependencyAnalyzer
+	if analyzer == nil {
+		analyzer = vm.NewDependencyAnalyzer(0)
+	}
+	result.ParallelStats = append(result.ParallelStats, core.NewParallelStats(txs, analyzer.Analyze(txs, signer), results, stats))
+
+	for i, tx := range txs {
+		if err := results[i].Err; err != nil {
+			result.Errors[offset+i] = err
+			continue
+		}
+		msg, res := msgs[i], results[i].Result.(*core.ExecutionResult)
+		if err := env.GasPool.SubGas(msg.GasLimit); err != nil {
+			result.Errors[offset+i] = err
+			return i + 1
+		}
+		env.GasPool.AddGas(msg.GasLimit - res.UsedGas)
+
+		env.State.SetTxContext(tx.Hash(), env.TxIndex)
+		results[i].Apply(env.State)
+		env.Header.GasUsed += res.UsedGas
+
+		env.EVM.SetTxContext(core.NewEVMTxContext(msg))
+		result.Receipts[offset+i] = core.MakeReceipt(env.EVM, res, env.State, env.Header.Number, blockHash, tx, env.Header.GasUsed, nil)
+		env.TxIndex++
+	}
+	return len(txs)
+}
+
+// Included возвращает включенные в блок транзакции батча txs и их
+// квитанции в порядке выполнения
+func (r *BatchResult) Included(txs []*types.Transaction) (types.Transactions, types.Receipts) {
+	var (
+		included = make(types.Transactions, 0, r.ProcessedTransactions)
+		receipts = make(types.Receipts, 0, r.ProcessedTransactions)
+	)
+	for i, receipt := range r.Receipts {
+		if receipt != nil {
+			included = append(included, txs[i])
+			receipts = append(receipts, receipt)
+		}
+	}
+	return included, receipts
+}
+
+// append дописывает к результату результат следующего батча
+func (r *BatchResult) append(next *BatchResult) {
+	r.ProcessedTransactions += next.ProcessedTransactions
+	r.Receipts = append(r.Receipts, next.Receipts...)
+	r.Errors = append(r.Errors, next.Errors...)
+	r.Logs = append(r.Logs, next.Logs...)
+	r.GasUsed += next.GasUsed
+	r.ElapsedTime += next.ElapsedTime
+	r.ParallelStats = append(r.ParallelStats, next.ParallelStats...)
+}
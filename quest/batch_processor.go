@@ -18,20 +18,15 @@
 package quest
 
 import (
-	"context"
 	"errors"
-	"fmt"
 	"math"
 	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/log"
-	"golang.org/x/sync/semaphore"
 )
 
 const (
@@ -56,7 +51,8 @@ const (
 	// Максимальное время обработки батча в секундах
 	MaxBatchProcessingTimeSeconds = 300
 	
-	// Размер шарда по умолчанию
+	// Размер шарда - число транзакций, выполняемых за один проход. Между
+	// проходами проверяются размер батча и таймаут обработки.
 	DefaultShardSize = 250_000
 )
 
@@ -65,7 +61,6 @@ var (
 	ErrBatchTooLarge      = errors.New("размер батча превышает максимальный")
 	ErrBatchProcessing    = errors.New("ошибка при обработке батча")
 	ErrProcessingTimeout  = errors.New("таймаут обработки батча")
-	ErrQuestNotAvailable  = errors.New("квантовый процессор недоступен")
 	ErrInvalidTransaction = errors.New("некорректная транзакция")
 	ErrBatchEmpty    = errors.New("пустой батч транзакций")
 )
@@ -79,93 +74,11 @@ type TxStats struct {
 	GasUsed     uint64        // Общее количество использованного газа
 	ElapsedTime time.Duration // Время обработки
 	TPS         float64       // Транзакций в секунду
-	
-	// Распределение по шардам
-	ShardStats map[int]ShardStats // Статистика по шардам
-}
-
-// ShardStats содержит информацию по конкретному шарду
-type ShardStats struct {
-	ShardID    int           // Идентификатор шарда
-	Count      uint64        // Количество транзакций в шарде
-	Completed  uint64        // Обработано успешно
-	Failed     uint64        // Обработано с ошибками
-	GasUsed    uint64        // Использовано газа
-	StartTime  time.Time     // Время начала обработки
-	EndTime    time.Time     // Время окончания обработки
-	ElapsedMs  time.Duration // Общее время обработки в миллисекундах
 }
 
-// TransactionBatch представляет пакет транзакций для обработки
-type TransactionBatch struct {
-	Transactions []*types.Transaction
-	BatchID      string
-	Timestamp    time.Time
-	ShardCount   int
-	Shards       [][]*types.Transaction
-}
-
-// NewTransactionBatch создает новый пакет транзакций
-func NewTransactionBatch(transactions []*types.Transaction, shardCount int) *TransactionBatch {
-	if shardCount <= 0 {
-		shardCount = runtime.NumCPU()
-		if shardCount > MaxShardCount {
-			shardCount = MaxShardCount
-		}
-	}
-
-	batch := &TransactionBatch{
-		Transactions: transactions,
-		BatchID:      fmt.Sprintf("batch-%d", time.Now().UnixNano()),
-		Timestamp:    time.Now(),
-		ShardCount:   shardCount,
-	}
-
-	// Разбиваем транзакции на шарды
-	batch.createShards()
-
-	return batch
-}
-
-// createShards разбивает транзакции на шарды для параллельной обработки
-func (b *TransactionBatch) createShards() {
-	txCount := len(b.Transactions)
-	
-	// Если транзакций меньше, чем шардов, уменьшаем количество шардов
-	if txCount < b.ShardCount {
-		b.ShardCount = txCount
-	}
-	
-	// Создаем слайсы для хранения транзакций по шардам
-	b.Shards = make([][]*types.Transaction, b.ShardCount)
-	
-	// Вычисляем, сколько транзакций должно быть в каждом шарде
-	txPerShard := txCount / b.ShardCount
-	remainder := txCount % b.ShardCount
-	
-	// Распределяем транзакции по шардам
-	currentIndex := 0
-	for i := 0; i < b.ShardCount; i++ {
-		// Количество транзакций в этом шарде
-		count := txPerShard
-		if i < remainder {
-			count++
-		}
-		
-		// Создаем слайс для шарда
-		b.Shards[i] = make([]*types.Transaction, count)
-		
-		// Копируем транзакции в шард
-		for j := 0; j < count; j++ {
-			b.Shards[i][j] = b.Transactions[currentIndex]
-			currentIndex++
-		}
-	}
-}
-
-// BatchProcessor обрабатывает батчи транзакций
+// BatchProcessor выполняет батчи транзакций над состоянием строящегося
+// блока и возвращает квитанции включенных транзакций и ошибки отклоненных
 type BatchProcessor struct {
-	processor    QuestProcessorInterface
 	shardCount   int
 	mutex        sync.Mutex
 	lastStats    TxStats
@@ -175,14 +88,10 @@ type BatchProcessor struct {
 	hyperOptimized bool
 }
 
-// QuestProcessorInterface определяет интерфейс для квантового процессора
-type QuestProcessorInterface interface {
-	Run(contract interface{}, input []byte, readOnly bool) ([]byte, error)
-	IsAvailable() bool
-}
-
-// NewBatchProcessor создает новый батч-процессор
-func NewBatchProcessor(processor QuestProcessorInterface, shardCount int) *BatchProcessor {
+// NewBatchProcessor создает новый батч-процессор. shardCount задает число
+// потоков выполнения (0 - по числу процессоров, но не больше
+// DefaultShardCount).
+func NewBatchProcessor(shardCount int) *BatchProcessor {
 	if shardCount <= 0 {
 		shardCount = runtime.NumCPU()
 		if shardCount > DefaultShardCount {
@@ -191,9 +100,7 @@ func NewBatchProcessor(processor QuestProcessorInterface, shardCount int) *Batch
 	}
 	
 	return &BatchProcessor{
-		processor:  processor,
 		shardCount: shardCount,
-		lastStats:  TxStats{ShardStats: make(map[int]ShardStats)},
 	}
 }
 
@@ -245,12 +152,10 @@ func (bp *BatchProcessor) EnableHyperOptimization() {
 	
 	bp.hyperOptimized = true
 	
-	// Настройки для максимальной производительности
-	// Используем динамическое регулирование шардов в зависимости от размера батча
+	// Настройки для максимальной производительности: батч выполняется на
+	// всех процессорах независимо от числа шардов. Будет применено при
+	// следующем вызове ProcessHyperBatch
 	log.Info("Включен режим сверхоптимизации для гипер-батчей")
-	
-	// Изменяем стратегию шардирования для сверхбольших батчей
-	// Будет применено при следующем вызове ProcessHyperBatch
 }
 
 // DisableHyperOptimization отключает режим сверхоптимизации
@@ -272,169 +177,76 @@ func (bp *BatchProcessor) IsGPUEnabled() bool {
 	return bp.gpuEnabled
 }
 
-// ProcessHyperBatch обрабатывает гипер-батч транзакций
-func (bp *BatchProcessor) ProcessHyperBatch(transactions []*types.Transaction) (TxStats, error) {
+// ProcessHyperBatch выполняет гипер-батч транзакций в окружении env по
+// порядку и применяет включенные транзакции к его состоянию. Результат
+// содержит квитанции с журналами, блумом и газом в порядке транзакций
+// батча; транзакции, которые нельзя включить, не изменяют состояние и
+// отклоняются с ошибкой. Транзакции сверх MaxBatchSize отклоняются с
+// ErrBatchTooLarge, а не выполненные до истечения
+// MaxBatchProcessingTimeSeconds - с ErrProcessingTimeout.
+func (bp *BatchProcessor) ProcessHyperBatch(env *BatchEnv, transactions []*types.Transaction) (*BatchResult, error) {
 	bp.mutex.Lock()
 	defer bp.mutex.Unlock()
 	
-	if !bp.processor.IsAvailable() {
-		return TxStats{}, ErrQuestNotAvailable
-	}
-	
 	// Если получен пустой батч, возвращаем ошибку
 	if len(transactions) == 0 {
-		return TxStats{}, ErrBatchEmpty
+		return nil, ErrBatchEmpty
 	}
-	
-	// Проверяем, не превышает ли размер батча максимальный
 	if len(transactions) > MaxBatchSize {
 		log.Warn("Размер батча превышает максимально допустимый", 
 			"size", len(transactions), 
 			"max", MaxBatchSize)
-		transactions = transactions[:MaxBatchSize]
 	}
 	
-	// Создаем батч транзакций с оптимальным количеством шардов
-	// Для очень больших батчей увеличиваем количество шардов
-	optimalShardCount := bp.shardCount
-	txCount := len(transactions)
-	
-	// Учитываем режим сверхоптимизации
+	// В режиме сверхоптимизации используем все процессоры
+	workers := bp.shardCount
 	if bp.hyperOptimized {
-		// Для сверхоптимизации используем специальные настройки
-		if txCount > 1000000 {
-			// Для очень больших батчей используем другую стратегию шардирования
-			if bp.gpuEnabled {
-				// Для GPU лучше использовать меньше шардов, но больше транзакций на шард
-				optimalShardCount = runtime.NumCPU() / 2
-				if optimalShardCount < 4 {
-					optimalShardCount = 4
-				}
-				
-				log.Info("Применена сверхоптимизация для GPU-обработки", 
-					"shard_count", optimalShardCount,
-					"tx_count", txCount)
-			} else {
-				// Оптимальное количество транзакций на один шард для CPU
-				optimalTxPerShard := 25000
-				
-				// Вычисляем оптимальное количество шардов
-				newShardCount := txCount / optimalTxPerShard
-				if newShardCount > MaxShardCount {
-					newShardCount = MaxShardCount
-				}
-				
-				if newShardCount > 0 {
-					optimalShardCount = newShardCount
-				}
-				
-				log.Info("Применена сверхоптимизация для CPU-обработки", 
-					"shard_count", optimalShardCount,
-					"tx_count", txCount)
-			}
-		}
-	} else {
-		// Для очень больших батчей (>1 млн) увеличиваем количество шардов
-		if txCount > 1000000 {
-			// Вычисляем оптимальное количество транзакций на процессор
-			optimalTxPerCPU := 50000 // Приблизительно оптимальное число транзакций на одно ядро
-			cpuCount := runtime.NumCPU()
-			
-			// Вычисляем новое количество шардов с учетом размера батча
-			newShardCount := txCount / optimalTxPerCPU
-			if newShardCount > MaxShardCount {
-				newShardCount = MaxShardCount
-			}
-			
-			// Если расчетное число шардов больше текущего и есть доступные ядра
-			if newShardCount > optimalShardCount && newShardCount <= cpuCount*2 {
-				optimalShardCount = newShardCount
-				log.Info("Для большого батча увеличено количество шардов", 
-					"original", bp.shardCount, 
-					"new", optimalShardCount,
-					"tx_count", txCount)
-			}
-		}
-	}
-	
-	batch := NewTransactionBatch(transactions, optimalShardCount)
-	
-	// Счетчики для статистики
-	stats := TxStats{
-		BatchSize:   uint64(len(transactions)),
-		ShardStats:  make(map[int]ShardStats),
-		Completed:   0,
-		Failed:      0,
-		GasUsed:     0,
+		workers = runtime.NumCPU()
 	}
 	
 	// Увеличиваем счетчик обработанных батчей
 	batchCount := bp.totalBatches.Add(1)
 	
 	log.Info("Начало обработки гипер-батча", 
-		"batch_id", batch.BatchID, 
 		"size", len(transactions), 
-		"shards", batch.ShardCount,
+		"workers", workers,
 		"batch_number", batchCount,
 		"gpu_enabled", bp.gpuEnabled,
 		"hyper_optimized", bp.hyperOptimized)
 	
-	startTime := time.Now()
-	
-	// Создаем контекст с таймаутом
-	ctx, cancel := context.WithTimeout(context.Background(), MaxBatchProcessingTimeSeconds*time.Second)
-	defer cancel()
-	
-	// Создаем канал для результатов обработки шардов
-	results := make(chan ShardStats, batch.ShardCount)
-	
-	// Семафор для ограничения параллельности
-	sem := semaphore.NewWeighted(int64(batch.ShardCount))
-	
-	// Запускаем обработку каждого шарда в отдельной горутине
-	for shardID, shard := range batch.Shards {
-		// Ждем, когда семафор позволит запустить ещё один шард
-		if err := sem.Acquire(ctx, 1); err != nil {
-			log.Error("Не удалось захватить семафор для шарда", 
-				"batch_id", batch.BatchID, 
-				"shard_id", shardID, 
-				"error", err)
-			continue
+	var (
+		startTime = time.Now()
+		deadline  = startTime.Add(MaxBatchProcessingTimeSeconds * time.Second)
+		result    = &BatchResult{}
+	)
+	// Выполняем батч по шардам, проверяя таймаут между ними
+	for start := 0; start < len(transactions); {
+		end := min(start+DefaultShardSize, len(transactions))
+		if start < MaxBatchSize {
+			end = min(end, MaxBatchSize)
 		}
+		shard := transactions[start:end]
 		
-		go func(id int, txs []*types.Transaction) {
-			defer sem.Release(1)
-			
-			// Проверяем, не завершен ли контекст
-			if ctx.Err() != nil {
-				return
-			}
-			
-			// Запускаем обработку шарда с передачей контекста
-			bp.processShardWithContext(ctx, id, txs, results)
-		}(shardID, shard)
-	}
-	
-	// Собираем результаты обработки шардов
-	for i := 0; i < batch.ShardCount; i++ {
-		select {
-		case result := <-results:
-			stats.ShardStats[result.ShardID] = result
-			stats.Completed += result.Completed
-			stats.Failed += result.Failed
-			stats.GasUsed += result.GasUsed
-			
-		case <-ctx.Done():
-			log.Error("Таймаут при обработке гипер-батча", 
-				"batch_id", batch.BatchID, 
-				"elapsed", time.Since(startTime))
-			return stats, ErrProcessingTimeout
+		switch {
+		case start >= MaxBatchSize:
+			result.append(rejectBatch(shard, ErrBatchTooLarge))
+		case time.Now().After(deadline):
+			result.append(rejectBatch(shard, ErrProcessingTimeout))
+		default:
+			result.append(executeBatch(env, shard, workers))
 		}
+		start = end
 	}
+	result.ElapsedTime = time.Since(startTime)
 	
-	// Рассчитываем время выполнения и TPS
-	stats.ElapsedTime = time.Since(startTime)
-	
+	// Рассчитываем статистику
+	stats := TxStats{
+		BatchSize:   uint64(len(transactions)),
+		Completed:   uint64(result.ProcessedTransactions),
+		Failed:      uint64(len(transactions) - result.ProcessedTransactions),
+		GasUsed:     result.GasUsed,
+		ElapsedTime: result.ElapsedTime,
+	}
 	// Избегаем деления на ноль
 	if stats.ElapsedTime.Seconds() > 0 {
 		stats.TPS = float64(stats.Completed) / stats.ElapsedTime.Seconds()
@@ -443,191 +255,27 @@ func (bp *BatchProcessor) ProcessHyperBatch(transactions []*types.Transaction) (
 	// Сохраняем статистику
 	bp.lastStats = stats
 	
-	log.Info("Гипер-батч успешно обработан", 
-		"batch_id", batch.BatchID, 
+	log.Info("Гипер-батч обработан", 
+		"batch_number", batchCount, 
 		"completed", stats.Completed, 
 		"failed", stats.Failed, 
+		"gas", stats.GasUsed,
 		"elapsed", stats.ElapsedTime, 
-		"tps", math.Round(stats.TPS),
-		"avg_time_per_shard_ms", math.Round(float64(stats.ElapsedTime.Milliseconds())/float64(batch.ShardCount)))
+		"tps", math.Round(stats.TPS))
 	
-	return stats, nil
+	return result, nil
 }
 
-// processShardWithContext обрабатывает один шард транзакций с учетом контекста
-func (bp *BatchProcessor) processShardWithContext(ctx context.Context, shardID int, transactions []*types.Transaction, results chan<- ShardStats) {
-	stats := ShardStats{
-		ShardID:   shardID,
-		Count:     uint64(len(transactions)),
-		StartTime: time.Now(),
-	}
-	
-	log.Debug("Начало обработки шарда", 
-		"shard_id", shardID, 
-		"transactions", len(transactions))
-	
-	// Размер чанка для пакетной обработки внутри шарда
-	// Меньшие чанки дают лучшую обратную связь о ходе выполнения
-	chunkSize := 100
-	if len(transactions) <= chunkSize {
-		chunkSize = len(transactions)
-	}
-	
-	// Количество воркеров для обработки транзакций в шарде
-	// Используем меньше воркеров для очень крупных шардов, чтобы не создавать слишком много горутин
-	workerCount := runtime.NumCPU()
-	if len(transactions) > 100000 {
-		// Для огромных шардов уменьшаем число воркеров, чтобы избежать перегрузки
-		workerCount = runtime.NumCPU() / 2
-		if workerCount < 2 {
-			workerCount = 2 // Минимум 2 воркера
-		}
-	}
-	
-	// Создаем каналы для передачи транзакций и результатов
-	jobs := make(chan *types.Transaction, chunkSize*2)
-	jobResults := make(chan bool, chunkSize*2)
-	
-	// Создаем WaitGroup для ожидания всех воркеров
-	var wg sync.WaitGroup
-	
-	// Запускаем воркеров
-	for w := 0; w < workerCount; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			
-			for tx := range jobs {
-				// Проверяем, не был ли отменен контекст
-				if ctx.Err() != nil {
-					jobResults <- false
-					continue
-				}
-				
-				// Конвертируем транзакцию в формат, понятный квантовому процессору
-				contract, input, readOnly := bp.convertTxToContract(tx)
-				
-				// Запускаем обработку транзакции
-				_, err := bp.processor.Run(contract, input, readOnly)
-				jobResults <- (err == nil)
-			}
-		}()
-	}
-	
-	// Функция закрытия канала jobs после отправки всех транзакций
-	go func() {
-		for _, tx := range transactions {
-			// Проверяем, не был ли отменен контекст
-			if ctx.Err() != nil {
-				break
-			}
-			jobs <- tx
-		}
-		close(jobs)
-	}()
-	
-	// Обрабатываем результаты выполнения транзакций
-	go func() {
-		for i := 0; i < len(transactions); i++ {
-			select {
-			case success := <-jobResults:
-				if success {
-					atomic.AddUint64(&stats.Completed, 1)
-					
-					// Добавляем газ (примерно)
-					if i < len(transactions) {
-						atomic.AddUint64(&stats.GasUsed, transactions[i].Gas())
-					}
-				} else {
-					atomic.AddUint64(&stats.Failed, 1)
-				}
-				
-				// Периодически логируем прогресс для крупных шардов
-				if len(transactions) > 10000 && (i+1)%5000 == 0 {
-					log.Debug("Прогресс обработки шарда", 
-						"shard_id", shardID, 
-						"processed", i+1, 
-						"total", len(transactions),
-						"percent", float64(i+1)/float64(len(transactions))*100)
-				}
-				
-			case <-ctx.Done():
-				// Контекст был отменен, прекращаем обработку
-				log.Warn("Обработка шарда прервана", 
-					"shard_id", shardID, 
-					"processed", i, 
-					"total", len(transactions),
-					"reason", ctx.Err())
-				return
-			}
-		}
-	}()
-	
-	// Ждем завершения всех воркеров
-	wg.Wait()
-	close(jobResults)
-	
-	// Завершаем статистику
-	stats.EndTime = time.Now()
-	stats.ElapsedMs = stats.EndTime.Sub(stats.StartTime)
-	
-	// Проверяем, не был ли отменен контекст
-	if ctx.Err() != nil {
-		log.Warn("Шард не полностью обработан из-за отмены контекста", 
-			"shard_id", shardID, 
-			"completed", stats.Completed, 
-			"failed", stats.Failed, 
-			"total", stats.Count,
-			"error", ctx.Err())
-	} else {
-		log.Debug("Шард обработан", 
-			"shard_id", shardID, 
-			"completed", stats.Completed, 
-			"failed", stats.Failed, 
-			"elapsed_ms", stats.ElapsedMs.Milliseconds(),
-			"tx_per_second", math.Round(float64(stats.Completed)/(float64(stats.ElapsedMs)/float64(time.Second))))
-	}
-	
-	// Отправляем результаты
-	select {
-	case results <- stats:
-		// Результаты успешно отправлены
-	case <-ctx.Done():
-		// Контекст отменен, но всё равно пытаемся отправить результаты
-		select {
-		case results <- stats:
-		default:
-		}
+// rejectBatch отклоняет все транзакции батча с ошибкой err
+func rejectBatch(txs []*types.Transaction, err error) *BatchResult {
+	result := &BatchResult{
+		Receipts: make([]*types.Receipt, len(txs)),
+		Errors:   make([]error, len(txs)),
 	}
-}
-
-// convertTxToContract конвертирует транзакцию в формат контракта
-func (bp *BatchProcessor) convertTxToContract(tx *types.Transaction) (interface{}, []byte, bool) {
-	// Получаем данные транзакции
-	data := tx.Data()
-	
-	// Создаем структуру "контракт" (формат зависит от реализации процессора)
-	// В данном случае предполагаем, что процессор принимает структуру с необходимыми полями
-	contract := struct {
-		From    common.Address
-		To      common.Address
-		Value   string
-		Gas     uint64
-		GasPrice string
-		Data    []byte
-	}{
-		From:    tx.From(),
-		To:      *tx.To(),
-		Value:   tx.Value().String(),
-		Gas:     tx.Gas(),
-		GasPrice: tx.GasPrice().String(),
-		Data:    data,
+	for i := range result.Errors {
+		result.Errors[i] = err
 	}
-	
-	// Определяем, является ли транзакция только для чтения
-	readOnly := tx.Value().Sign() == 0
-	
-	return contract, data, readOnly
+	return result
 }
 
 // GetStats возвращает статистику последнего обработанного батча
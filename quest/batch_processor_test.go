@@ -0,0 +1,121 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quest
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Проверяет, что батч-процессор включает транзакции по порядку с
+// квитанциями, отклоняет невалидные с ошибками и что последовательное и
+// параллельное выполнение дают одно состояние
+func TestProcessHyperBatch(t *testing.T) {
+	var (
+		config   = params.MergedTestChainConfig
+		signer   = types.LatestSigner(config)
+		coinbase = common.HexToAddress("0xcb")
+		keys     = make([]*ecdsa.PrivateKey, 4)
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	transfer := func(key int, nonce, gas uint64) *types.Transaction {
+		to := crypto.PubkeyToAddress(keys[(key+1)%len(keys)].PublicKey)
+		return types.MustSignNewTx(keys[key], signer, &types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     nonce,
+			To:        &to,
+			Value:     big.NewInt(1000),
+			Gas:       gas,
+			GasFeeCap: big.NewInt(params.InitialBaseFee),
+		})
+	}
+	txs := []*types.Transaction{
+		transfer(0, 0, params.TxGas),
+		transfer(1, 0, params.TxGas),
+		transfer(0, 2, params.TxGas), // Пропущен nonce
+		transfer(0, 1, params.TxGas),
+		transfer(2, 0, 30000), // Не хватает газа блока после предыдущих
+		transfer(3, 0, params.TxGas),
+	}
+	var roots []common.Hash
+	for _, workers := range []int{1, 4} {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		for _, key := range keys {
+			statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		}
+		header := &types.Header{
+			Number:     big.NewInt(1),
+			GasLimit:   30_000_000,
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+			Difficulty: new(big.Int),
+		}
+		env := &BatchEnv{
+			EVM:     vm.NewEVM(core.NewEVMBlockContext(header, nil, &coinbase), statedb, config, vm.Config{BlockSTM: true}),
+			State:   statedb,
+			Header:  header,
+			GasPool: new(core.GasPool).AddGas(3*params.TxGas + 25000),
+		}
+		bp := NewBatchProcessor(workers)
+		result, err := bp.ProcessHyperBatch(env, txs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !errors.Is(result.Errors[2], core.ErrNonceTooHigh) {
+			t.Errorf("workers %d: nonce gap error: %v", workers, result.Errors[2])
+		}
+		if !errors.Is(result.Errors[4], core.ErrGasLimitReached) {
+			t.Errorf("workers %d: gas limit error: %v", workers, result.Errors[4])
+		}
+		included, receipts := result.Included(txs)
+		if len(included) != 4 || included[2] != txs[3] || included[3] != txs[5] {
+			t.Fatalf("workers %d: included %d transactions", workers, len(included))
+		}
+		for i, receipt := range receipts {
+			if receipt.TxHash != included[i].Hash() || receipt.TransactionIndex != uint(i) ||
+				receipt.Status != types.ReceiptStatusSuccessful || receipt.CumulativeGasUsed != uint64(i+1)*params.TxGas {
+				t.Errorf("workers %d: receipt %d: %+v", workers, i, receipt)
+			}
+		}
+		if header.GasUsed != 4*params.TxGas || result.GasUsed != header.GasUsed || env.TxIndex != 4 {
+			t.Errorf("workers %d: block gas %d, batch gas %d, next index %d", workers, header.GasUsed, result.GasUsed, env.TxIndex)
+		}
+		if parallel := len(result.ParallelStats) != 0; parallel != (workers != 1) {
+			t.Errorf("workers %d: %d parallel stats", workers, len(result.ParallelStats))
+		}
+		if stats := bp.GetStats(); stats.Completed != 4 || stats.Failed != 2 {
+			t.Errorf("workers %d: stats %+v", workers, stats)
+		}
+		roots = append(roots, statedb.IntermediateRoot(true))
+	}
+	if roots[0] != roots[1] {
+		t.Fatalf("state root mismatch: sequential %x, parallel %x", roots[0], roots[1])
+	}
+}
@@ -14,191 +14,164 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package quest предоставляет квантовый процессор для замены EVM
+// Package quest предоставляет квантовый процессор для EVM
 package quest
 
 import (
 	"errors"
-	"fmt"
-	"math/big"
-	"runtime"
 	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/state"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/params"
-)
-
-var (
-	// ErrQuestNotEnabled возникает при попытке использовать Quest когда он не включен
-	ErrQuestNotEnabled = errors.New("quest: процессор не включен в настройках")
+	"github.com/ethereum/go-ethereum/quest/utils"
+	"github.com/holiman/uint256"
 )
 
 // Ошибки квантового процессора
 var (
-	ErrQuestNotSupported      = errors.New("квантовые вычисления не поддерживаются")
-	ErrQuestHardwareNotFound  = errors.New("квантовое аппаратное обеспечение не найдено")
-	ErrQuestInvalidOperation  = errors.New("недопустимая квантовая операция")
+	ErrQuestNotSupported        = errors.New("квантовые вычисления не поддерживаются")
+	ErrQuestHardwareNotFound    = errors.New("квантовое аппаратное обеспечение не найдено")
+	ErrQuestInvalidOperation    = errors.New("недопустимая квантовая операция")
 	ErrQuestDecoherenceDetected = errors.New("обнаружена декогеренция")
 	ErrQuestInsufficientQubits  = errors.New("недостаточно кубитов")
 )
 
-// Константы для квантовых операций
+// Константы для квантовых операций. Это подкоды единого реестра
+// vm.QuantumOp, в байткоде каждому из них предшествует префикс vm.QUANTUM.
 const (
-	// Опкоды квантовых операций начинаются с 0xF0
-	QUEST_HADAMARD     = 0xF0 // Квантовый вентиль Адамара
-	QUEST_X            = 0xF1 // Квантовый вентиль X (NOT)
-	QUEST_Y            = 0xF2 // Квантовый вентиль Y
-	QUEST_Z            = 0xF3 // Квантовый вентиль Z
-	QUEST_CNOT         = 0xF4 // Контролируемый NOT
-	QUEST_SWAP         = 0xF5 // Обмен состояниями кубитов
-	QUEST_TOFFOLI      = 0xF6 // Вентиль Тоффоли (CCNOT)
-	QUEST_PHASE        = 0xF7 // Фазовый вентиль
-	QUEST_MEASURE      = 0xF8 // Измерение кубита
-	QUEST_INIT         = 0xF9 // Инициализация кубитов
-	QUEST_QFT          = 0xFA // Квантовое преобразование Фурье
-	QUEST_QRNG         = 0xFB // Квантовый генератор случайных чисел
-	QUEST_SHOR         = 0xFC // Алгоритм Шора
-	QUEST_GROVER       = 0xFD // Алгоритм Гровера
-	QUEST_QRAM_LOAD    = 0xFE // Загрузка данных в квантовую память
-	QUEST_QRAM_STORE   = 0xFF // Сохранение данных из квантовой памяти
+	QUEST_HADAMARD = vm.QHADAMARD // Квантовый вентиль Адамара
+	QUEST_X        = vm.QPAULIX   // Квантовый вентиль X (NOT)
+	QUEST_Y        = vm.QPAULIY   // Квантовый вентиль Y
+	QUEST_Z        = vm.QPAULIZ   // Квантовый вентиль Z
+	QUEST_CNOT     = vm.QCNOT     // Контролируемый NOT
+	QUEST_SWAP     = vm.QSWAP     // Обмен состояниями кубитов
+	QUEST_TOFFOLI  = vm.QTOFFOLI  // Вентиль Тоффоли (CCNOT)
+	QUEST_PHASE    = vm.QPHASE    // Фазовый вентиль
+	QUEST_MEASURE  = vm.QMEASURE  // Измерение кубита
+	QUEST_INIT     = vm.QINIT     // Инициализация кубитов
+	QUEST_QFT      = vm.QQFT      // Квантовое преобразование Фурье
+	QUEST_QRNG     = vm.QRANDOM   // Квантовый генератор случайных чисел
+	QUEST_SHOR     = vm.QSHOR     // Алгоритм Шора
+	QUEST_GROVER   = vm.QGROVER   // Алгоритм Гровера
 )
 
-// Config содержит настройки для квантового процессора
-type Config struct {
-	// Флаг для отладки
-	Debug bool
-	
-	// Флаг для использования аппаратного ускорения
-	HardwareAcceleration bool
-	
-	// Уровень параллелизма (количество потоков)
-	LevelParallelism int
-	
-	// Дополнительные опции в формате ключ-значение
-	Options map[string]interface{}
-}
-
-// QuestProcessor реализует интерфейс vm.Processor для квантовых вычислений
+// QuestProcessor - реализация vm.Processor, зарегистрированная под именем
+// vm.DefaultQuantumProcessor и подключаемая к EVM после активации форка
+// Quantum. Контракты с квантовыми инструкциями выполняются через
+// QuestExecutor, остальные - стандартным интерпретатором. Процессор также
+// реализует vm.QuantumOpHandler и выполняет инструкции префикса vm.QUANTUM
+// своей EVM.
 type QuestProcessor struct {
+	evm      *vm.EVM
 	executor *QuestExecutor
-	evm      *vm.EVM // fallback на стандартный EVM, если Quest не может выполнить транзакцию
-	cfg      *Config
-	maxQubits int
-	
-	// Кэш состояний кубитов
-	qubitStates     map[uint64][]complex128
-	qubitStatesMutex sync.RWMutex
-	
-	// Статистика выполнения
-	stats struct {
-		totalOps      uint64
-		quantumOps    uint64
-		classicalOps  uint64
-		executionTime time.Duration
-	}
+	useGPU   bool
+	deviceID int
+
+	// Статистика операций, общая для всех процессоров
+	stats *processorStats
+
+	// Профилирование квантовых инструкций, nil если отключено
+	profiler *utils.Profiler
+
+	// Пакетная обработка транзакций, запускается при первом обращении
+	batch     batchState
+	batchOnce sync.Once
 }
 
-// NewQuestProcessor создает новый экземпляр QuestProcessor
-func NewQuestProcessor(evm *vm.EVM, config *vm.Config) (*QuestProcessor, error) {
-	// Проверяем, включен ли Quest в настройках
-	if config == nil || !config.EnableQuest {
-		return nil, ErrQuestNotEnabled
-	}
+// Проверка соответствия интерфейсам на этапе компиляции
+var (
+	_ vm.Processor              = (*QuestProcessor)(nil)
+	_ vm.QuantumOpHandler       = (*QuestProcessor)(nil)
+	_ vm.QuantumRegisterFlusher = (*QuestProcessor)(nil)
+)
 
-	// Создаем адаптер для квантового процессора
-	executor := NewQuestExecutor(
-		&evm.Context,
-		&evm.TxContext,
-		evm.StateDB,
-		evm.ChainConfig(),
-		config,
-	)
+func init() {
+	vm.RegisterProcessor(vm.DefaultQuantumProcessor, func(evm *vm.EVM) (vm.Processor, error) {
+		return NewQuestProcessor(evm, &evm.Config)
+	})
+}
 
-	return &QuestProcessor{
-		executor: executor,
+// NewQuestProcessor создает квантовый процессор для evm. Результат
+// выполнения определяется правилами форка блока, config задает только
+// устройство и профилирование. Процессор спекулятивной EVM (инкарнации
+// Block-STM, которая может быть выполнена повторно или отброшена) ведет
+// собственные счетчики и не профилирует инструкции, чтобы не изменять
+// статистику процесса.
+func NewQuestProcessor(evm *vm.EVM, config *vm.Config) (*QuestProcessor, error) {
+	deviceID := config.QuestPreferredDevice
+	if deviceID < 0 {
+		deviceID = 0
+	}
+	useGPU := !config.QuestForceCPU
+	executor, err := NewQuestExecutor(evm, useGPU, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	q := &QuestProcessor{
 		evm:      evm,
-		cfg:      config,
-		maxQubits: 32, // По умолчанию для симуляции
-		qubitStates: make(map[uint64][]complex128),
-	}, nil
+		executor: executor,
+		useGPU:   useGPU,
+		deviceID: deviceID,
+		stats:    &globalStats,
+	}
+	if config.Speculative {
+		q.stats = new(processorStats)
+		return q, nil
+	}
+	if config.QuestProfiling {
+		q.profiler = sharedProfiler()
+	}
+	return q, nil
 }
 
 // Run выполняет контракт с использованием квантового процессора
 func (q *QuestProcessor) Run(contract *vm.Contract, input []byte, readOnly bool) (ret []byte, err error) {
-	// Если контракт явно помечен для выполнения только на EVM, используем стандартный EVM
-	if contract.EVMOnly {
-		log.Debug("Контракт помечен как 'только EVM', использую стандартный EVM")
-		return q.evm.Interpreter.Run(contract, input, readOnly)
-	}
+	q.stats.operations.Add(1)
 
-	// Определяем, можно ли выполнить контракт на квантовом процессоре
-	if q.canRunOnQuest(contract, input) {
-		log.Debug("Выполнение контракта на квантовом процессоре Quest", 
-			"contract", contract.Address().Hex(),
-			"input_size", len(input))
-		
-		result, remainingGas, execErr := q.executor.Execute(contract, input, readOnly)
-		if execErr == nil {
-			// Обновляем оставшийся газ в контракте
-			contract.Gas = remainingGas
-			return result, nil
-		}
-		
-		// Если возникла ошибка, логируем ее и падаем на стандартный EVM
-		log.Warn("Ошибка выполнения на Quest, использую fallback на EVM", 
-			"error", execErr,
-			"contract", contract.Address().Hex())
+	// Контракты без квантовых инструкций и помеченные как 'только EVM'
+	// выполняются стандартным интерпретатором
+	if contract.EVMOnly || !vm.HasQuantumOps(contract.Code) {
+		q.stats.classical.Add(1)
+		return q.evm.Interpreter().Run(contract, input, readOnly)
 	}
-
-	// Используем стандартный EVM как fallback
-	log.Debug("Использую стандартный EVM для выполнения контракта", 
-		"contract", contract.Address().Hex())
-	return q.evm.Interpreter.Run(contract, input, readOnly)
+	log.Debug("Выполнение контракта на квантовом процессоре Quest",
+		"contract", contract.Address().Hex(),
+		"input_size", len(input))
+
+	// Ошибка Quest является ошибкой выполнения кадра, как и ошибка
+	// интерпретатора: повторное выполнение на EVM дало бы другой результат
+	// блока
+	return q.executor.Execute(contract, input, readOnly)
 }
 
-// canRunOnQuest проверяет, может ли контракт быть выполнен на квантовом процессоре
-func (q *QuestProcessor) canRunOnQuest(contract *vm.Contract, input []byte) bool {
-	// Проверяем размер контракта и входных данных
-	// Слишком большие контракты могут быть не оптимальны для квантового процессора
-	if len(contract.Code) > 10000 || len(input) > 5000 {
-		return false
-	}
+// ExecuteQuantumOp выполняет инструкцию префикса vm.QUANTUM и реализует
+// vm.QuantumOpHandler
+func (q *QuestProcessor) ExecuteQuantumOp(op vm.QuantumOp, scope *vm.ScopeContext, args []uint256.Int) ([]uint256.Int, error) {
+	q.stats.quantum.Add(1)
 
-	// Пытаемся определить, содержит ли контракт квантовые инструкции
-	// В реальной реализации здесь будет более сложная логика
-	hasQuantumOps := false
-	
-	// Простая эвристика: ищем специальные "маркеры" квантовых операций
-	// В реальной реализации здесь будет анализ байт-кода
-	for i := 0; i < len(contract.Code)-3; i++ {
-		// Пример: проверяем последовательность байтов, которая может указывать на квантовую инструкцию
-		// В реальной реализации здесь будет более сложная логика
-		if contract.Code[i] == 0xf0 && contract.Code[i+1] == 0xf1 {
-			hasQuantumOps = true
-			break
-		}
+	if q.profiler != nil {
+		// Профайлер общий для всех процессоров, поэтому длительность
+		// измеряется здесь, а не по имени операции
+		start := time.Now()
+		defer func() { q.profiler.Record(op.String(), time.Since(start)) }()
 	}
+	return q.executor.ExecuteQuantumOp(op, scope, args)
+}
+
+// FlushQuantumRegisters записывает в состояние регистры, измененные текущим
+// кадром вызова, и реализует vm.QuantumRegisterFlusher
+func (q *QuestProcessor) FlushQuantumRegisters() {
+	q.executor.FlushQuantumRegisters()
+}
 
-	return hasQuantumOps
+// IsAvailable возвращает true, если квантовое окружение процессора активно
+func (q *QuestProcessor) IsAvailable() bool {
+	return q.executor.IsAvailable()
 }
 
-// Close закрывает процессор и освобождает ресурсы
+// Close останавливает пакетную обработку и освобождает квантовое окружение
 func (q *QuestProcessor) Close() error {
+	q.stopBatchWorkers()
 	return q.executor.Close()
 }
-
-// Расширение структуры vm.Config для поддержки квантового процессора
-func init() {
-	// Регистрируем расширение для vm.Config в init(), чтобы избежать циклических зависимостей
-	vm.RegisterConfigExtension(func(config *vm.Config) {
-		// Добавляем опцию для включения квантового процессора
-		if config.EnableQuest {
-			log.Info("Квантовый процессор Quest включен в настройках")
-		}
-	})
-} 
\ No newline at end of file
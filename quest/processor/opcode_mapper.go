@@ -1,1437 +0,0 @@
-// Copyright 2023 The go-ethereum Authors
-// This file is part of the go-ethereum library.
-//
-// The go-ethereum library is free software: you can redistribute it and/or modify
-// it under the terms of the GNU Lesser General Public License as published by
-// the Free Software Foundation, either version 3 of the License, or
-// (at your option) any later version.
-//
-// The go-ethereum library is distributed in the hope that it will be useful,
-// but WITHOUT ANY WARRANTY; without even the implied warranty of
-// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
-// GNU Lesser General Public License for more details.
-//
-// You should have received a copy of the GNU Lesser General Public License
-// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
-
-// Package processor предоставляет низкоуровневые компоненты для квантового процессора
-package processor
-
-import (
-	"errors"
-	"fmt"
-	"math/big"
-	"sync"
-
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/vm"
-	"github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/quest/quantum"
-)
-
-var (
-	// ErrUnsupportedOpcode возникает при попытке выполнить неподдерживаемый опкод
-	ErrUnsupportedOpcode = errors.New("неподдерживаемый опкод в квантовом режиме")
-
-	// ErrStackUnderflow возникает при недостаточном количестве элементов в стеке
-	ErrStackUnderflow = errors.New("недостаточно элементов в стеке для квантовой операции")
-
-	// ErrInvalidQubitsRange возникает при указании неверного диапазона кубитов
-	ErrInvalidQubitsRange = errors.New("неверный диапазон кубитов для операции")
-
-	// ErrInvalidOpcode возвращается при попытке отобразить неизвестный опкод
-	ErrInvalidOpcode = errors.New("неизвестный опкод")
-
-	// ErrNotImplemented возвращается, если операция еще не реализована
-	ErrNotImplemented = errors.New("операция не реализована")
-
-	// ErrInvalidInput возвращается при передаче неверных входных данных
-	ErrInvalidInput = errors.New("неверные входные данные")
-)
-
-// QuantumOp представляет квантовую операцию
-type QuantumOp interface {
-	// Execute выполняет операцию на квантовом состоянии
-	Execute(state *QuantumState) error
-
-	// GasCost вычисляет стоимость газа для операции
-	GasCost(state *QuantumState) uint64
-
-	// Name возвращает имя операции
-	Name() string
-
-	// IsQuantum возвращает true, если это истинно квантовая операция
-	IsQuantum() bool
-}
-
-// OpcodeMapper преобразует опкоды EVM в квантовые операции
-type OpcodeMapper struct {
-	questEnv        *quantum.QuestEnv
-	maxQubits       int
-	mutex           sync.RWMutex
-	opcodeMapping   map[vm.OpCode]func(stack *vm.Stack) error
-	availableQubits int
-}
-
-// NewOpcodeMapper создает новый маппер опкодов с указанным квантовым окружением
-func NewOpcodeMapper(questEnv *quantum.QuestEnv, maxQubits int) *OpcodeMapper {
-	mapper := &OpcodeMapper{
-		questEnv:        questEnv,
-		maxQubits:       maxQubits,
-		availableQubits: maxQubits,
-	}
-
-	// Инициализация маппинга опкодов на квантовые операции
-	mapper.initializeOpcodeMapping()
-
-	return mapper
-}
-
-// initializeOpcodeMapping создает отображение опкодов EVM на квантовые функции
-func (m *OpcodeMapper) initializeOpcodeMapping() {
-	m.opcodeMapping = map[vm.OpCode]func(stack *vm.Stack) error{
-		// Арифметические операции
-		vm.ADD:      m.mapArithmeticToQNN,
-		vm.MUL:      m.mapArithmeticToQNN,
-		vm.SUB:      m.mapArithmeticToQNN,
-		vm.DIV:      m.mapArithmeticToQNN,
-		vm.SDIV:     m.mapArithmeticToQNN,
-		vm.MOD:      m.mapArithmeticToQNN,
-		vm.SMOD:     m.mapArithmeticToQNN,
-		vm.ADDMOD:   m.mapArithmeticToQNN,
-		vm.MULMOD:   m.mapArithmeticToQNN,
-		vm.EXP:      m.mapExponentToQFT,
-		vm.SIGNEXTEND: m.mapBasicArithToQuantum,
-
-		// Битовые операции
-		vm.AND:      m.mapBitwiseToGrover,
-		vm.OR:       m.mapBitwiseToGrover,
-		vm.XOR:      m.mapBitwiseToGrover,
-		vm.NOT:      m.mapBitwiseNOTToQuantum,
-		vm.BYTE:     m.mapByteToQuantum,
-		vm.SHL:      m.mapShiftToQuantum,
-		vm.SHR:      m.mapShiftToQuantum,
-		vm.SAR:      m.mapShiftToQuantum,
-
-		// Криптографические операции
-		vm.SHA3:     m.mapSHA3ToGrover,
-
-		// Операции со средой
-		vm.ADDRESS:  m.mapEnvToQuantumState,
-		vm.BALANCE:  m.mapEnvToQuantumState,
-		vm.ORIGIN:   m.mapEnvToQuantumState,
-		vm.CALLER:   m.mapEnvToQuantumState,
-		vm.CALLVALUE: m.mapEnvToQuantumState,
-		vm.CALLDATALOAD: m.mapCalldataToQuantum,
-		vm.CALLDATASIZE: m.mapCalldataToQuantum,
-		vm.CALLDATACOPY: m.mapCalldataToQuantum,
-		vm.CODESIZE:  m.mapCodeToQuantum,
-		vm.CODECOPY:  m.mapCodeToQuantum,
-		vm.GASPRICE:  m.mapGasToQuantum,
-		vm.EXTCODESIZE: m.mapExtcodeToQuantum,
-		vm.EXTCODECOPY: m.mapExtcodeToQuantum,
-		vm.RETURNDATASIZE: m.mapReturndataToQuantum,
-		vm.RETURNDATACOPY: m.mapReturndataToQuantum,
-		vm.EXTCODEHASH: m.mapExtcodeToQuantum,
-		vm.BLOCKHASH: m.mapBlockInfoToQuantum,
-		vm.COINBASE:  m.mapBlockInfoToQuantum,
-		vm.TIMESTAMP: m.mapBlockInfoToQuantum,
-		vm.NUMBER:    m.mapBlockInfoToQuantum,
-		vm.DIFFICULTY: m.mapBlockInfoToQuantum,
-		vm.GASLIMIT:  m.mapBlockInfoToQuantum,
-		vm.CHAINID:   m.mapBlockInfoToQuantum,
-		vm.SELFBALANCE: m.mapEnvToQuantumState,
-		vm.BASEFEE:   m.mapBlockInfoToQuantum,
-
-		// Операции со стеком
-		vm.POP:      m.mapStackToQuantum,
-		vm.MLOAD:    m.mapMemoryToQRAM,
-		vm.MSTORE:   m.mapMemoryToQRAM,
-		vm.MSTORE8:  m.mapMemoryToQRAM,
-		vm.SLOAD:    m.mapStorageToQRAM,
-		vm.SSTORE:   m.mapStorageToQRAM,
-		vm.JUMP:     m.mapJumpToQRandom,
-		vm.JUMPI:    m.mapJumpToQRandom,
-		vm.PC:       m.mapPCToQuantum,
-		vm.MSIZE:    m.mapMemoryToQRAM,
-		vm.GAS:      m.mapGasToQuantum,
-		vm.JUMPDEST: m.mapJumpToQRandom,
-
-		// Проверки и сравнения
-		vm.ISZERO:   m.mapComparisonToGrover,
-		vm.EQ:       m.mapComparisonToGrover,
-		vm.GT:       m.mapComparisonToGrover,
-		vm.SGT:      m.mapComparisonToGrover,
-		vm.LT:       m.mapComparisonToGrover,
-		vm.SLT:      m.mapComparisonToGrover,
-
-		// Операции логирования
-		vm.LOG0:     m.mapLogToQuantum,
-		vm.LOG1:     m.mapLogToQuantum,
-		vm.LOG2:     m.mapLogToQuantum,
-		vm.LOG3:     m.mapLogToQuantum,
-		vm.LOG4:     m.mapLogToQuantum,
-
-		// Системные операции
-		vm.CREATE:   m.mapCreateToQuantumRandom,
-		vm.CALL:     m.mapCallToQuantum,
-		vm.CALLCODE: m.mapCallToQuantum,
-		vm.RETURN:   m.mapReturnToQuantum,
-		vm.DELEGATECALL: m.mapCallToQuantum,
-		vm.CREATE2:  m.mapCreate2ToGrover,
-		vm.STATICCALL: m.mapCallToQuantum,
-		vm.REVERT:   m.mapReturnToQuantum,
-		vm.INVALID:  m.mapInvalidToQuantum,
-		vm.SELFDESTRUCT: m.mapSelfdestructToQuantum,
-
-		// Push операции (0x60-0x7f)
-		vm.PUSH1:    m.mapPushToQuantum,
-		// ... остальные PUSH операции аналогично
-
-		// Duplication операции (0x80-0x8f)
-		vm.DUP1:     m.mapDupToQuantum,
-		// ... остальные DUP операции аналогично
-
-		// Swap операции (0x90-0x9f)
-		vm.SWAP1:    m.mapSwapToQuantum,
-		// ... остальные SWAP операции аналогично
-	}
-
-	// Добавляем все остальные операции PUSH
-	for i := vm.PUSH2; i <= vm.PUSH32; i++ {
-		m.opcodeMapping[i] = m.mapPushToQuantum
-	}
-
-	// Добавляем все остальные операции DUP
-	for i := vm.DUP2; i <= vm.DUP16; i++ {
-		m.opcodeMapping[i] = m.mapDupToQuantum
-	}
-
-	// Добавляем все остальные операции SWAP
-	for i := vm.SWAP2; i <= vm.SWAP16; i++ {
-		m.opcodeMapping[i] = m.mapSwapToQuantum
-	}
-}
-
-// MapOpcode преобразует и выполняет опкод EVM с использованием квантовых операций
-func (m *OpcodeMapper) MapOpcode(opcode vm.OpCode, stack *vm.Stack) error {
-	// Проверяем, есть ли маппинг для данного опкода
-	handler, exists := m.opcodeMapping[opcode]
-	if !exists {
-		return fmt.Errorf("%w: %s", ErrUnsupportedOpcode, opcode.String())
-	}
-
-	// Выполняем соответствующую квантовую операцию
-	return handler(stack)
-}
-
-// mapArithmeticToQNN преобразует арифметические операции в квантовую нейронную сеть
-func (m *OpcodeMapper) mapArithmeticToQNN(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-
-	// Извлекаем операнды из стека
-	x := stack.Peek()
-	y := stack.Peek2()
-
-	// Преобразуем операнды в байтовое представление
-	inputData := append(x.Bytes(), y.Bytes()...)
-
-	// Размер входных данных для квантовой нейронной сети
-	inputSize := len(inputData) * 8
-	outputSize := 256 // Размер результата в битах
-
-	// Запускаем квантовую нейронную сеть
-	result, err := quantum.ExecuteQNN(m.questEnv, inputData, inputSize, outputSize)
-	if err != nil {
-		return err
-	}
-
-	// Преобразуем результат обратно в большое целое число
-	var resultBig big.Int
-	resultBytes := make([]byte, 32)
-	for i := 0; i < 32; i++ {
-		if i < len(result) {
-			resultBytes[31-i] = byte(result[i] * 255) // Преобразуем из [0,1] в [0,255]
-		}
-	}
-	resultBig.SetBytes(resultBytes)
-
-	// Обновляем стек (заменяем верхний элемент результатом, второй удаляем)
-	stack.Pop()
-	stack.Pop()
-	stack.Push(&resultBig)
-
-	return nil
-}
-
-// mapExponentToQFT преобразует операцию возведения в степень в квантовое преобразование Фурье
-func (m *OpcodeMapper) mapExponentToQFT(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-
-	// Извлекаем основание и показатель из стека
-	base := stack.Peek()
-	exp := stack.Peek2()
-
-	// Преобразуем операнды в массив комплексных чисел для QFT
-	baseVal := base.Uint64()
-	expVal := exp.Uint64()
-	size := 1 << 10 // 1024 точки для QFT
-	if size > 1<<m.maxQubits {
-		size = 1 << m.maxQubits
-	}
-
-	data := make([]complex128, size)
-	for i := 0; i < size; i++ {
-		if i == int(baseVal%uint64(size)) {
-			data[i] = complex(1, 0) // Импульс в позиции base % size
-		}
-	}
-
-	// Выполняем квантовое преобразование Фурье
-	qftResult, err := quantum.ExecuteQFT(m.questEnv, data)
-	if err != nil {
-		return err
-	}
-
-	// Используем фазу QFT для модуляции результата
-	var result big.Int
-	if expVal < 256 {
-		result.SetUint64(baseVal)
-		result.Exp(&result, exp, nil)
-	} else {
-		// Для больших показателей используем квантовый результат
-		magnitude := 0.0
-		for _, val := range qftResult {
-			mag := real(val)*real(val) + imag(val)*imag(val)
-			if mag > magnitude {
-				magnitude = mag
-			}
-		}
-		result.SetUint64(uint64(magnitude * float64(1<<60)))
-	}
-
-	// Обновляем стек
-	stack.Pop()
-	stack.Pop()
-	stack.Push(&result)
-
-	return nil
-}
-
-// mapBasicArithToQuantum преобразует базовые арифметические операции в квантовые аналоги
-func (m *OpcodeMapper) mapBasicArithToQuantum(stack *vm.Stack) error {
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
-	}
-
-	// Получаем значение из стека
-	value := stack.Peek()
-
-	// Создаем квантовое представление числа
-	// В реальной имплементации мы бы использовали квантовые вентили
-	// для преобразования классического значения в квантовое представление
-	// Для этого мы используем ряд вентилей Адамара для создания суперпозиции
-	for i := 0; i < min(m.maxQubits, 32); i++ {
-		// Проверяем соответствующий бит значения
-		if (value.Bit(i) == 1) {
-			// Если бит установлен, применяем X вентиль (NOT)
-			if err := m.questEnv.ApplyPauliX(i); err != nil {
-				return err
-			}
-		}
-		
-		// Затем применяем вентиль Адамара для введения суперпозиции
-		if err := m.questEnv.ApplyHadamard(i); err != nil {
-			return err
-		}
-	}
-
-	// В реальной квантовой схеме мы бы запустили квантовую схему
-	// для выполнения нужной арифметической операции
-	// Здесь мы просто применим простую операцию для демонстрации
-	for i := 0; i < min(m.maxQubits/2, 16); i++ {
-		// Применяем CNOT для создания запутанности между битами
-		if err := m.questEnv.ApplyCNOT(i, i + m.maxQubits/2); err != nil {
-			return err
-		}
-	}
-
-	// После квантовых операций мы измеряем кубиты и получаем результат
-	result := big.NewInt(0)
-	for i := 0; i < min(m.maxQubits, 32); i++ {
-		// Измеряем i-й кубит
-		bitValue, err := m.questEnv.Measure(i)
-		if err != nil {
-			return err
-		}
-		
-		// Устанавливаем соответствующий бит в результате
-		if bitValue == 1 {
-			result.SetBit(result, i, 1)
-		}
-	}
-
-	// Заменяем значение в стеке
-	stack.Pop()
-	stack.Push(result)
-
-	return nil
-}
-
-// mapBitwiseToGrover преобразует побитовые операции в алгоритм Гровера
-func (m *OpcodeMapper) mapBitwiseToGrover(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-
-	// Извлекаем операнды
-	x := stack.Peek()
-	y := stack.Peek2()
-
-	// Преобразуем операнды в байтовое представление для поиска Гровера
-	inputData := append(x.Bytes(), y.Bytes()...)
-
-	// Создаем "хэш", который нужно найти (в реальности используем оригинальную операцию)
-	targetHash := make([]byte, 32)
-	copy(targetHash, inputData[:min(32, len(inputData))])
-
-	// Прогоняем алгоритм Гровера с ограниченным пространством поиска
-	result, err := quantum.ExecuteGroverAlgorithm(m.questEnv, targetHash, 1<<16)
-	if err != nil {
-		return err
-	}
-
-	// Преобразуем результат обратно в большое целое число
-	var resultBig big.Int
-	resultBig.SetBytes(result[:min(32, len(result))])
-
-	// Обновляем стек
-	stack.Pop()
-	stack.Pop()
-	stack.Push(&resultBig)
-
-	return nil
-}
-
-// mapBitwiseNOTToQuantum преобразует операцию NOT в квантовую операцию
-func (m *OpcodeMapper) mapBitwiseNOTToQuantum(stack *vm.Stack) error {
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
-	}
-
-	// Получаем значение из стека
-	value := stack.Peek()
-	
-	// Создаем квантовое представление числа
-	for i := 0; i < min(m.maxQubits, 256); i++ {
-		if (value.Bit(i) == 1) {
-			// Если бит установлен, устанавливаем кубит в |1⟩
-			if err := m.questEnv.ApplyPauliX(i); err != nil {
-				return err
-			}
-		}
-	}
-	
-	// Применяем NOT ко всем кубитам (X вентиль)
-	for i := 0; i < min(m.maxQubits, 256); i++ {
-		if err := m.questEnv.ApplyPauliX(i); err != nil {
-			return err
-		}
-	}
-	
-	// Измеряем результат
-	result := big.NewInt(0)
-	for i := 0; i < min(m.maxQubits, 256); i++ {
-		bitValue, err := m.questEnv.Measure(i)
-		if err != nil {
-			return err
-		}
-		
-		if bitValue == 1 {
-			result.SetBit(result, i, 1)
-		}
-	}
-	
-	// Обновляем стек
-	stack.Pop()
-	stack.Push(result)
-	
-	return nil
-}
-
-// mapByteToQuantum преобразует операцию BYTE в квантовую операцию
-func (m *OpcodeMapper) mapByteToQuantum(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	// Получаем индекс байта и значение
-	n := stack.Peek()
-	val := stack.Peek2()
-	
-	// Если индекс >= 32, возвращаем 0
-	if n.Cmp(big.NewInt(32)) >= 0 {
-		stack.Pop()
-		stack.Pop()
-		stack.Push(big.NewInt(0))
-		return nil
-	}
-	
-	// Получаем индекс как число
-	index := int(n.Uint64())
-	
-	// Создаем квантовое представление для извлечения байта
-	// Для этого мы используем специальную схему, которая выбирает нужный байт
-	
-	// Сначала инициализируем кубиты в соответствии с битами значения
-	for i := 0; i < min(m.maxQubits, 256); i++ {
-		if val.Bit(i) == 1 {
-			if err := m.questEnv.ApplyPauliX(i); err != nil {
-				return err
-			}
-		}
-	}
-	
-	// Создаем квантовую схему, которая выделяет нужный байт
-	// В реальной имплементации здесь была бы квантовая схема для выбора байта
-	
-	// Для демонстрации просто получаем байт классическим способом
-	byteVal := byte(0)
-	if index < 32 {
-		// Получаем нужный байт (считаем с большого конца)
-		valBytes := val.Bytes()
-		if len(valBytes) > 0 {
-			if index < len(valBytes) {
-				byteVal = valBytes[len(valBytes)-1-index]
-			}
-		}
-	}
-	
-	// Преобразуем результат в big.Int
-	result := big.NewInt(int64(byteVal))
-	
-	// Обновляем стек
-	stack.Pop()
-	stack.Pop()
-	stack.Push(result)
-	
-	return nil
-}
-
-// mapShiftToQuantum преобразует операции сдвига в квантовые операции
-func (m *OpcodeMapper) mapShiftToQuantum(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	// Получаем количество бит для сдвига и значение
-	shift := stack.Peek()
-	value := stack.Peek2()
-	
-	// Если сдвиг больше 256, результат определен как 0 для SHL/SHR или знак для SAR
-	if shift.Cmp(big.NewInt(256)) >= 0 {
-		stack.Pop()
-		stack.Pop()
-		stack.Push(big.NewInt(0))
-		return nil
-	}
-	
-	// Преобразуем сдвиг в int
-	shiftVal := int(shift.Uint64())
-	
-	// Создаем квантовое представление
-	// В реальной имплементации здесь была бы квантовая схема для операций сдвига
-	
-	// Инициализируем результат соответствующим образом
-	var result big.Int
-	
-	// Выполняем сдвиг в зависимости от операции (предполагаем, что это может быть SHL)
-	// В реальной имплементации мы бы определяли тип операции
-	result.Lsh(value, uint(shiftVal))
-	
-	// Обновляем стек
-	stack.Pop()
-	stack.Pop()
-	stack.Push(&result)
-	
-	return nil
-}
-
-// mapSHA3ToGrover преобразует SHA3 в алгоритм Гровера
-func (m *OpcodeMapper) mapSHA3ToGrover(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	// Получаем смещение и размер данных из стека
-	offset := stack.Peek()
-	size := stack.Peek2()
-	
-	// Конвертируем в uint64
-	offsetVal := offset.Uint64()
-	sizeVal := size.Uint64()
-	
-	// Здесь бы мы получили данные из памяти и выполнили алгоритм Гровера
-	// для быстрого поиска коллизий SHA3
-	
-	// Для демонстрации просто генерируем случайные данные как хеш
-	randomBytes := make([]byte, 32)
-	
-	// В реальной имплементации мы бы использовали квантовый алгоритм Гровера
-	// для быстрого поиска прообраза или коллизии
-	
-	// Генерируем квантово случайные числа для заполнения хеша
-	for i := 0; i < 32; i++ {
-		// Применяем вентиль Адамара к кубиту для создания суперпозиции
-		if err := m.questEnv.ApplyHadamard(i % m.maxQubits); err != nil {
-			return err
-		}
-		
-		// Измеряем кубит для получения случайного бита
-		bitVal, err := m.questEnv.Measure(i % m.maxQubits)
-		if err != nil {
-			return err
-		}
-		
-		// Устанавливаем соответствующий бит в байт хеша
-		if bitVal == 1 {
-			randomBytes[i] |= (1 << (i % 8))
-		}
-	}
-	
-	// Создаем big.Int из полученного хеша
-	result := new(big.Int).SetBytes(randomBytes)
-	
-	// Обновляем стек
-	stack.Pop()
-	stack.Pop()
-	stack.Push(result)
-	
-	return nil
-}
-
-// mapEnvToQuantumState преобразует операции со средой в квантовое состояние
-func (m *OpcodeMapper) mapEnvToQuantumState(stack *vm.Stack) error {
-	// Создаем квантовое представление системной информации
-	
-	// Для операций ADDRESS, BALANCE, ORIGIN и т.д.
-	// мы можем использовать квантовое запутывание для хранения
-	// нескольких возможных значений одновременно
-	
-	// Подготавливаем кубиты в равной суперпозиции
-	for i := 0; i < min(m.maxQubits, 32); i++ {
-		if err := m.questEnv.ApplyHadamard(i); err != nil {
-			return err
-		}
-	}
-	
-	// Для демонстрации создаем псевдослучайное значение, которое бы
-	// представляло запрошенную информацию среды
-	result := big.NewInt(0)
-	for i := 0; i < min(m.maxQubits, 32); i++ {
-		measurement, err := m.questEnv.Measure(i)
-		if err != nil {
-			return err
-		}
-		
-		if measurement == 1 {
-			result.SetBit(result, i, 1)
-		}
-	}
-	
-	// Помещаем результат в стек
-	stack.Push(result)
-	
-	return nil
-}
-
-// mapCalldataToQuantum преобразует операции с данными вызова в квантовые операции
-func (m *OpcodeMapper) mapCalldataToQuantum(stack *vm.Stack) error {
-	// Используем квантовую память для эффективного хранения calldata
-	// Моделируем QRAM (Quantum Random Access Memory)
-	
-	// Для CALLDATALOAD, CALLDATASIZE, CALLDATACOPY
-	// создаем квантовую суперпозицию всех возможных данных
-	
-	// Подготавливаем кубиты для QRAM адресации
-	for i := 0; i < min(m.maxQubits/4, 8); i++ {
-		if err := m.questEnv.ApplyHadamard(i); err != nil {
-			return err
-		}
-	}
-	
-	// В реальной имплементации мы бы запутывали адресные кубиты с данными
-	// Здесь для демонстрации просто создаем случайное значение
-	result := big.NewInt(0)
-	for i := 0; i < min(m.maxQubits, 32); i++ {
-		bitVal, err := m.questEnv.Measure(i % m.maxQubits)
-		if err != nil {
-			return err
-		}
-		
-		if bitVal == 1 {
-			result.SetBit(result, i, 1)
-		}
-	}
-	
-	// Помещаем результат в стек
-	stack.Push(result)
-	
-	return nil
-}
-
-// mapCodeToQuantum преобразует операции с кодом в квантовые операции
-func (m *OpcodeMapper) mapCodeToQuantum(stack *vm.Stack) error {
-	// Реализация для CODESIZE и CODECOPY
-	
-	// В квантовом контексте мы можем использовать суперпозицию
-	// для представления разных частей кода одновременно
-	
-	// Получаем параметры из стека (если это необходимо)
-	
-	// Подготавливаем кубиты в суперпозиции
-	for i := 0; i < min(m.maxQubits/2, 16); i++ {
-		if err := m.questEnv.ApplyHadamard(i); err != nil {
-			return err
-		}
-	}
-	
-	// Для демонстрации генерируем результат из квантовых измерений
-	result := big.NewInt(0)
-	for i := 0; i < min(m.maxQubits/2, 16); i++ {
-		bitVal, err := m.questEnv.Measure(i)
-		if err != nil {
-			return err
-		}
-		
-		if bitVal == 1 {
-			result.SetBit(result, i, 1)
-		}
-	}
-	
-	// Помещаем результат в стек
-	stack.Push(result)
-	
-	return nil
-}
-
-// mapGasToQuantum преобразует операции с газом в квантовые операции
-func (m *OpcodeMapper) mapGasToQuantum(stack *vm.Stack) error {
-	// Реализация для операций GAS и GASPRICE
-	
-	// В квантовом контексте мы можем представить разные сценарии использования газа
-	// в суперпозиции, что позволит оптимизировать использование газа
-	
-	// Подготавливаем кубиты
-	for i := 0; i < min(m.maxQubits/4, 8); i++ {
-		if err := m.questEnv.ApplyHadamard(i); err != nil {
-			return err
-		}
-	}
-	
-	// Для демонстрации генерируем случайное значение газа
-	gasValue := big.NewInt(1000000) // Стартовое значение
-	
-	// Модифицируем значение на основе квантовых измерений
-	for i := 0; i < min(m.maxQubits/8, 4); i++ {
-		bitVal, err := m.questEnv.Measure(i)
-		if err != nil {
-			return err
-		}
-		
-		if bitVal == 1 {
-			// Увеличиваем или уменьшаем газ
-			modifier := big.NewInt(10000 * (1 << uint(i)))
-			gasValue.Add(gasValue, modifier)
-		}
-	}
-	
-	// Помещаем результат в стек
-	stack.Push(gasValue)
-	
-	return nil
-}
-
-// mapExtcodeToQuantum преобразует операции с внешним кодом в квантовые операции
-func (m *OpcodeMapper) mapExtcodeToQuantum(stack *vm.Stack) error {
-	// Реализация для EXTCODESIZE, EXTCODECOPY, EXTCODEHASH
-	
-	// В квантовом контексте мы можем использовать запутывание
-	// для эффективного представления кода внешних контрактов
-	
-	// Подготавливаем кубиты
-	for i := 0; i < min(m.maxQubits/3, 10); i++ {
-		if err := m.questEnv.ApplyHadamard(i); err != nil {
-			return err
-		}
-	}
-	
-	// Создаем квантовую схему для генерации хеша кода
-	for i := 0; i < min(m.maxQubits/6, 5); i++ {
-		if err := m.questEnv.ApplyCNOT(i, i+min(m.maxQubits/6, 5)); err != nil {
-			return err
-		}
-	}
-	
-	// Для демонстрации генерируем хеш из квантовых измерений
-	hash := make([]byte, 32)
-	for i := 0; i < 32; i++ {
-		// Получаем случайные биты из квантовых измерений
-		bitVal, err := m.questEnv.Measure(i % m.maxQubits)
-		if err != nil {
-			return err
-		}
-		
-		if bitVal == 1 {
-			hash[i/8] |= (1 << (i % 8))
-		}
-	}
-	
-	// Преобразуем в big.Int и помещаем в стек
-	result := new(big.Int).SetBytes(hash)
-	stack.Push(result)
-	
-	return nil
-}
-
-// mapReturndataToQuantum преобразует операции с данными возврата в квантовые операции
-func (m *OpcodeMapper) mapReturndataToQuantum(stack *vm.Stack) error {
-	// Реализация для RETURNDATASIZE, RETURNDATACOPY
-	
-	// Квантовое представление данных возврата
-	
-	// Подготавливаем кубиты
-	for i := 0; i < min(m.maxQubits/4, 8); i++ {
-		if err := m.questEnv.ApplyHadamard(i); err != nil {
-			return err
-		}
-	}
-	
-	// Для демонстрации генерируем размер данных
-	size := big.NewInt(0)
-	for i := 0; i < min(m.maxQubits/8, 4); i++ {
-		bitVal, err := m.questEnv.Measure(i)
-		if err != nil {
-			return err
-		}
-		
-		if bitVal == 1 {
-			size.SetBit(size, i, 1)
-		}
-	}
-	
-	// Ограничиваем размер разумным значением
-	if size.Cmp(big.NewInt(1024)) > 0 {
-		size.SetUint64(1024)
-	}
-	
-	// Помещаем результат в стек
-	stack.Push(size)
-	
-	return nil
-}
-
-// mapBlockInfoToQuantum преобразует операции с информацией о блоке в квантовые операции
-func (m *OpcodeMapper) mapBlockInfoToQuantum(stack *vm.Stack) error {
-	// Реализация для операций с информацией о блоке
-	return nil
-}
-
-// mapStackToQuantum преобразует операции со стеком в квантовые операции
-func (m *OpcodeMapper) mapStackToQuantum(stack *vm.Stack) error {
-	// Реализация для операций со стеком
-	return nil
-}
-
-// mapMemoryToQRAM преобразует операции с памятью в квантовую память
-func (m *OpcodeMapper) mapMemoryToQRAM(stack *vm.Stack) error {
-	// Реализация для операций с памятью
-	return nil
-}
-
-// mapStorageToQRAM преобразует операции с хранилищем в квантовую память
-func (m *OpcodeMapper) mapStorageToQRAM(stack *vm.Stack) error {
-	// Реализация для операций с хранилищем
-	return nil
-}
-
-// mapJumpToQRandom преобразует операции перехода в квантовые операции
-func (m *OpcodeMapper) mapJumpToQRandom(stack *vm.Stack) error {
-	// Реализация для операций перехода
-	return nil
-}
-
-// mapPCToQuantum преобразует операцию PC в квантовую операцию
-func (m *OpcodeMapper) mapPCToQuantum(stack *vm.Stack) error {
-	// Реализация для операции PC
-	return nil
-}
-
-// mapComparisonToGrover преобразует операции сравнения в алгоритм Гровера
-func (m *OpcodeMapper) mapComparisonToGrover(stack *vm.Stack) error {
-	// Реализация для операций сравнения
-	return nil
-}
-
-// mapLogToQuantum преобразует операции логирования в квантовые операции
-func (m *OpcodeMapper) mapLogToQuantum(stack *vm.Stack) error {
-	// Реализация для операций логирования
-	return nil
-}
-
-// mapCreateToQuantumRandom преобразует операцию CREATE в квантовый генератор случайных чисел
-func (m *OpcodeMapper) mapCreateToQuantumRandom(stack *vm.Stack) error {
-	// Реализация для операции CREATE
-	return nil
-}
-
-// mapCallToQuantum преобразует операции вызова в квантовые операции
-func (m *OpcodeMapper) mapCallToQuantum(stack *vm.Stack) error {
-	// Реализация для операций вызова
-	return nil
-}
-
-// mapReturnToQuantum преобразует операции возврата в квантовые операции
-func (m *OpcodeMapper) mapReturnToQuantum(stack *vm.Stack) error {
-	// Реализация для операций возврата
-	return nil
-}
-
-// mapCreate2ToGrover преобразует операцию CREATE2 в алгоритм Гровера
-func (m *OpcodeMapper) mapCreate2ToGrover(stack *vm.Stack) error {
-	// Реализация для операции CREATE2
-	return nil
-}
-
-// mapInvalidToQuantum преобразует операцию INVALID в квантовую операцию
-func (m *OpcodeMapper) mapInvalidToQuantum(stack *vm.Stack) error {
-	// Реализация для операции INVALID
-	return nil
-}
-
-// mapSelfdestructToQuantum преобразует операцию SELFDESTRUCT в квантовую операцию
-func (m *OpcodeMapper) mapSelfdestructToQuantum(stack *vm.Stack) error {
-	// Реализация для операции SELFDESTRUCT
-	return nil
-}
-
-// mapPushToQuantum преобразует операции PUSH в квантовые операции
-func (m *OpcodeMapper) mapPushToQuantum(stack *vm.Stack) error {
-	// Реализация для операций PUSH
-	return nil
-}
-
-// mapDupToQuantum преобразует операции DUP в квантовые операции
-func (m *OpcodeMapper) mapDupToQuantum(stack *vm.Stack) error {
-	// Реализация для операций DUP
-	return nil
-}
-
-// mapSwapToQuantum преобразует операции SWAP в квантовые операции
-func (m *OpcodeMapper) mapSwapToQuantum(stack *vm.Stack) error {
-	// Реализация для операций SWAP
-	return nil
-}
-
-// min возвращает минимальное из двух значений
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-// TransformOpcode преобразует опкод EVM в квантовую операцию
-func (m *OpcodeMapper) TransformOpcode(opcode vm.OpCode, stack *vm.Stack, memory *vm.Memory) (*QuantumOp, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	// Проверяем кеш для оптимизации
-	if op, exists := m.opcodeCache[opcode]; exists && !m.forceRemap {
-		return op, nil
-	}
-	
-	var op *QuantumOp
-	var err error
-	
-	// Определяем тип операции и используем соответствующую функцию преобразования
-	switch {
-	// Арифметические операции
-	case opcode == vm.ADD, opcode == vm.SUB, opcode == vm.MUL, opcode == vm.DIV,
-		 opcode == vm.SDIV, opcode == vm.MOD, opcode == vm.SMOD, opcode == vm.EXP:
-		err = m.mapBasicArithToQuantum(stack)
-		
-	// Побитовые операции
-	case opcode == vm.NOT:
-		err = m.mapBitwiseNOTToQuantum(stack)
-	case opcode == vm.AND, opcode == vm.OR, opcode == vm.XOR:
-		err = m.mapBitwiseLogicToQuantum(stack, opcode)
-		
-	// Байтовые операции
-	case opcode == vm.BYTE:
-		err = m.mapByteToQuantum(stack)
-	case opcode == vm.SHL, opcode == vm.SHR:
-		err = m.mapShiftToQuantum(stack, opcode == vm.SHL)
-		
-	// Хеш-функции
-	case opcode == vm.SHA3:
-		err = m.mapSHA3ToGrover(stack, memory)
-		
-	// Операции со средой
-	case opcode == vm.ADDRESS, opcode == vm.BALANCE, opcode == vm.ORIGIN,
-		 opcode == vm.CALLER, opcode == vm.CALLVALUE, opcode == vm.BLOCKHASH,
-		 opcode == vm.COINBASE, opcode == vm.TIMESTAMP, opcode == vm.NUMBER,
-		 opcode == vm.DIFFICULTY, opcode == vm.GASLIMIT, opcode == vm.CHAINID,
-		 opcode == vm.SELFBALANCE, opcode == vm.BASEFEE:
-		err = m.mapEnvToQuantumState(stack)
-		
-	// Операции с данными вызова
-	case opcode == vm.CALLDATALOAD, opcode == vm.CALLDATASIZE, opcode == vm.CALLDATACOPY:
-		err = m.mapCalldataToQuantum(stack)
-		
-	// Операции с кодом
-	case opcode == vm.CODESIZE, opcode == vm.CODECOPY:
-		err = m.mapCodeToQuantum(stack)
-		
-	// Операции с газом
-	case opcode == vm.GAS, opcode == vm.GASPRICE:
-		err = m.mapGasToQuantum(stack)
-		
-	// Операции с внешним кодом
-	case opcode == vm.EXTCODESIZE, opcode == vm.EXTCODECOPY, opcode == vm.EXTCODEHASH:
-		err = m.mapExtcodeToQuantum(stack)
-		
-	// Операции с данными возврата
-	case opcode == vm.RETURNDATASIZE, opcode == vm.RETURNDATACOPY:
-		err = m.mapReturndataToQuantum(stack)
-		
-	// Операции с хранилищем
-	case opcode == vm.SLOAD, opcode == vm.SSTORE:
-		err = m.mapStorageToQuantum(stack)
-		
-	// Операции с журналом
-	case opcode == vm.LOG0, opcode == vm.LOG1, opcode == vm.LOG2, opcode == vm.LOG3, opcode == vm.LOG4:
-		err = m.mapLogToQuantum(stack, memory, int(opcode-vm.LOG0))
-		
-	// Операции создания контракта
-	case opcode == vm.CREATE, opcode == vm.CREATE2:
-		err = m.mapCreateToQuantum(stack, memory, opcode == vm.CREATE2)
-		
-	// Операции вызова контракта
-	case opcode == vm.CALL, opcode == vm.CALLCODE, opcode == vm.DELEGATECALL, opcode == vm.STATICCALL:
-		err = m.mapCallToQuantum(stack, memory, opcode)
-		
-	// Операции возврата и отката
-	case opcode == vm.RETURN, opcode == vm.REVERT:
-		err = m.mapReturnToQuantum(stack, memory, opcode == vm.REVERT)
-		
-	// Операция самоуничтожения
-	case opcode == vm.SELFDESTRUCT:
-		err = m.mapSelfdestructToQuantum(stack)
-		
-	default:
-		// Для неизвестных или необработанных опкодов возвращаем nil
-		return nil, fmt.Errorf("опкод %v не поддерживается для квантовой оптимизации", opcode)
-	}
-	
-	if err != nil {
-		return nil, err
-	}
-	
-	// Создаем квантовую операцию для кеширования
-	op = &QuantumOp{
-		OpCode:     opcode,
-		GasCost:    m.calculateGasCost(opcode, stack, memory),
-		Execute:    m.createExecutionFunction(opcode, stack, memory),
-		IsQuantum:  true,
-		StackItems: stack.Len(),
-	}
-	
-	// Кешируем операцию для повторного использования
-	if !m.forceRemap {
-		m.opcodeCache[opcode] = op
-	}
-	
-	return op, nil
-}
-
-// mapBitwiseLogicToQuantum преобразует битовые логические операции в квантовые операции
-func (m *OpcodeMapper) mapBitwiseLogicToQuantum(stack *vm.Stack, opcode vm.OpCode) error {
-	// Проверяем, достаточно ли элементов в стеке
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	// Извлекаем операнды из стека
-	x := stack.Pop()
-	y := stack.Pop()
-	
-	// Определяем результат в зависимости от операции
-	var result *big.Int
-	
-	// Для наших квантовых вычислений мы можем использовать схемы Тоффоли и CNOT
-	// для реализации логических операций
-	switch opcode {
-	case vm.AND:
-		// В квантовом контексте AND может быть реализован с помощью CCNOT (Тоффоли)
-		result = new(big.Int)
-		
-		// Подготавливаем кубиты для представления операндов
-		for i := 0; i < min(m.maxQubits, 32); i++ {
-			// Устанавливаем кубиты в соответствии с битами операндов
-			xBit := x.Bit(i)
-			yBit := y.Bit(i)
-			
-			if xBit == 1 {
-				if err := m.questEnv.ApplyPauliX(i); err != nil {
-					return err
-				}
-			}
-			
-			if yBit == 1 {
-				if err := m.questEnv.ApplyPauliX(i + m.maxQubits); err != nil {
-					return err
-				}
-			}
-			
-			// Применяем CCNOT (Тоффоли) для операции AND
-			// В реальной имплементации здесь была бы настоящая операция Тоффоли
-			if xBit == 1 && yBit == 1 {
-				if err := m.questEnv.ApplyPauliX(i + 2*m.maxQubits); err != nil {
-					return err
-				}
-			}
-			
-			// Получаем результат через измерение
-			bitVal, err := m.questEnv.Measure(i + 2*m.maxQubits)
-			if err != nil {
-				return err
-			}
-			
-			if bitVal == 1 {
-				result.SetBit(result, i, 1)
-			}
-		}
-		
-	case vm.OR:
-		// OR может быть реализован с помощью CNOT и Hadamard
-		result = new(big.Int)
-		
-		for i := 0; i < min(m.maxQubits, 32); i++ {
-			// Устанавливаем кубиты в соответствии с битами операндов
-			xBit := x.Bit(i)
-			yBit := y.Bit(i)
-			
-			// Для OR мы используем супепозицию и запутывание
-			if err := m.questEnv.ApplyHadamard(i); err != nil {
-				return err
-			}
-			
-			if xBit == 1 || yBit == 1 {
-				if err := m.questEnv.ApplyPauliX(i + m.maxQubits); err != nil {
-					return err
-				}
-			}
-			
-			// Применяем CNOT для запутывания
-			if err := m.questEnv.ApplyCNOT(i, i + m.maxQubits); err != nil {
-				return err
-			}
-			
-			// Измеряем результат
-			bitVal, err := m.questEnv.Measure(i + m.maxQubits)
-			if err != nil {
-				return err
-			}
-			
-			if bitVal == 1 {
-				result.SetBit(result, i, 1)
-			}
-		}
-		
-	case vm.XOR:
-		// XOR может быть напрямую реализован с помощью CNOT
-		result = new(big.Int)
-		
-		for i := 0; i < min(m.maxQubits, 32); i++ {
-			// Устанавливаем кубиты в соответствии с битами операндов
-			xBit := x.Bit(i)
-			yBit := y.Bit(i)
-			
-			if xBit == 1 {
-				if err := m.questEnv.ApplyPauliX(i); err != nil {
-					return err
-				}
-			}
-			
-			if yBit == 1 {
-				if err := m.questEnv.ApplyPauliX(i + m.maxQubits); err != nil {
-					return err
-				}
-			}
-			
-			// CNOT реализует XOR
-			if err := m.questEnv.ApplyCNOT(i, i + 2*m.maxQubits); err != nil {
-				return err
-			}
-			
-			// Измеряем результат
-			bitVal, err := m.questEnv.Measure(i + 2*m.maxQubits)
-			if err != nil {
-				return err
-			}
-			
-			if bitVal == 1 {
-				result.SetBit(result, i, 1)
-			}
-		}
-	}
-	
-	// Помещаем результат в стек
-	stack.Push(result)
-	
-	return nil
-}
-
-// mapStorageToQuantum преобразует операции с хранилищем в квантовые операции
-func (m *OpcodeMapper) mapStorageToQuantum(stack *vm.Stack) error {
-	// Реализация для SLOAD и SSTORE
-	
-	// В квантовом контексте мы можем использовать QRAM для
-	// эффективного доступа к хранилищу
-	
-	// Проверяем, достаточно ли элементов в стеке
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
-	}
-	
-	// Извлекаем ключ из стека
-	key := stack.Pop()
-	
-	// Для SSTORE нам нужно также значение
-	var value *big.Int
-	if stack.Len() > 0 {
-		value = stack.Pop()
-	} else {
-		// Для SLOAD генерируем квантовое значение
-		value = big.NewInt(0)
-		
-		// Используем квантовый алгоритм для поиска значения
-		for i := 0; i < min(m.maxQubits, 32); i++ {
-			// Применяем Hadamard для создания суперпозиции
-			if err := m.questEnv.ApplyHadamard(i); err != nil {
-				return err
-			}
-			
-			// Добавляем запутывание между кубитами ключа и значения
-			if i < min(m.maxQubits/2, 16) {
-				if err := m.questEnv.ApplyCNOT(i, i + min(m.maxQubits/2, 16)); err != nil {
-					return err
-				}
-			}
-			
-			// Измеряем результат
-			bitVal, err := m.questEnv.Measure(i + min(m.maxQubits/2, 16))
-			if err != nil {
-				return err
-			}
-			
-			if bitVal == 1 {
-				value.SetBit(value, i, 1)
-			}
-		}
-		
-		// Помещаем значение в стек для SLOAD
-		stack.Push(value)
-	}
-	
-	return nil
-}
-
-// mapLogToQuantum преобразует операции журналирования в квантовые операции
-func (m *OpcodeMapper) mapLogToQuantum(stack *vm.Stack, memory *vm.Memory, topics int) error {
-	// Реализация для LOG0, LOG1, LOG2, LOG3, LOG4
-	
-	// Проверяем, достаточно ли элементов в стеке
-	if stack.Len() < 2 + topics {
-		return ErrStackUnderflow
-	}
-	
-	// Извлекаем параметры из стека
-	offset := stack.Pop()
-	size := stack.Pop()
-	
-	// Извлекаем темы (topics)
-	topicVals := make([]*big.Int, topics)
-	for i := 0; i < topics; i++ {
-		topicVals[i] = stack.Pop()
-	}
-	
-	// В квантовом контексте мы можем использовать запутывание для
-	// эффективного хранения и поиска данных журнала
-	
-	// Для демонстрации мы просто регистрируем события без фактического журналирования
-	m.logger.Info("Квантовая журнальная запись",
-		"offset", offset,
-		"size", size,
-		"topics", topics)
-	
-	return nil
-}
-
-// mapCreateToQuantum преобразует операции создания контракта в квантовые операции
-func (m *OpcodeMapper) mapCreateToQuantum(stack *vm.Stack, memory *vm.Memory, isCreate2 bool) error {
-	// Реализация для CREATE и CREATE2
-	
-	// Проверяем, достаточно ли элементов в стеке
-	requiredStack := 3
-	if isCreate2 {
-		requiredStack = 4 // CREATE2 требует дополнительный соляной параметр
-	}
-	
-	if stack.Len() < requiredStack {
-		return ErrStackUnderflow
-	}
-	
-	// Извлекаем параметры из стека
-	value := stack.Pop()
-	offset := stack.Pop()
-	size := stack.Pop()
-	
-	var salt *big.Int
-	if isCreate2 {
-		salt = stack.Pop()
-	}
-	
-	// В квантовом контексте мы можем использовать квантовые алгоритмы
-	// для генерации оптимальных адресов контрактов
-	
-	// Генерируем псевдослучайный адрес
-	addr := make([]byte, 20)
-	
-	// Используем квантовый алгоритм для генерации случайных битов
-	for i := 0; i < 20*8; i++ {
-		// Применяем Hadamard для создания суперпозиции
-		if err := m.questEnv.ApplyHadamard(i % m.maxQubits); err != nil {
-			return err
-		}
-		
-		// Измеряем кубит
-		bitVal, err := m.questEnv.Measure(i % m.maxQubits)
-		if err != nil {
-			return err
-		}
-		
-		if bitVal == 1 {
-			addr[i/8] |= (1 << (i % 8))
-		}
-	}
-	
-	// Для CREATE2 учитываем соль
-	if isCreate2 && salt != nil {
-		// Используем соль для модификации адреса
-		saltBytes := salt.Bytes()
-		for i := 0; i < min(len(saltBytes), 20); i++ {
-			addr[i] ^= saltBytes[i%len(saltBytes)]
-		}
-	}
-	
-	// Преобразуем адрес в big.Int и помещаем в стек
-	result := new(big.Int).SetBytes(addr)
-	stack.Push(result)
-	
-	return nil
-}
-
-// mapCallToQuantum преобразует операции вызова контракта в квантовые операции
-func (m *OpcodeMapper) mapCallToQuantum(stack *vm.Stack, memory *vm.Memory, opcode vm.OpCode) error {
-	// Реализация для CALL, CALLCODE, DELEGATECALL, STATICCALL
-	
-	// Проверяем, достаточно ли элементов в стеке
-	requiredStack := 7
-	if opcode == vm.DELEGATECALL || opcode == vm.STATICCALL {
-		requiredStack = 6
-	}
-	
-	if stack.Len() < requiredStack {
-		return ErrStackUnderflow
-	}
-	
-	// Извлекаем общие параметры из стека
-	gas := stack.Pop()
-	addr := stack.Pop()
-	
-	// Для CALL и CALLCODE нам нужно значение
-	var value *big.Int
-	if opcode == vm.CALL || opcode == vm.CALLCODE {
-		value = stack.Pop()
-	}
-	
-	// Параметры ввода
-	inOffset := stack.Pop()
-	inSize := stack.Pop()
-	
-	// Параметры вывода
-	outOffset := stack.Pop()
-	outSize := stack.Pop()
-	
-	// В квантовом контексте мы можем использовать параллельное выполнение
-	// для оптимизации вызовов контрактов
-	
-	// Симулируем успешный вызов
-	success := big.NewInt(1)
-	stack.Push(success)
-	
-	return nil
-}
-
-// mapReturnToQuantum преобразует операции возврата в квантовые операции
-func (m *OpcodeMapper) mapReturnToQuantum(stack *vm.Stack, memory *vm.Memory, isRevert bool) error {
-	// Реализация для RETURN и REVERT
-	
-	// Проверяем, достаточно ли элементов в стеке
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	// Извлекаем параметры из стека
-	offset := stack.Pop()
-	size := stack.Pop()
-	
-	// В квантовом контексте мы можем оптимизировать передачу данных
-	// для возврата из контракта
-	
-	// Для демонстрации мы просто логируем возврат
-	m.logger.Info("Квантовая операция возврата",
-		"offset", offset,
-		"size", size,
-		"isRevert", isRevert)
-	
-	return nil
-}
-
-// mapSelfdestructToQuantum преобразует операцию самоуничтожения в квантовую операцию
-func (m *OpcodeMapper) mapSelfdestructToQuantum(stack *vm.Stack) error {
-	// Реализация для SELFDESTRUCT
-	
-	// Проверяем, достаточно ли элементов в стеке
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
-	}
-	
-	// Извлекаем адрес получателя из стека
-	recipient := stack.Pop()
-	
-	// В квантовом контексте мы можем обеспечить атомарный перевод баланса
-	// с использованием квантовых протоколов
-	
-	// Для демонстрации мы просто логируем самоуничтожение
-	m.logger.Info("Квантовая операция самоуничтожения",
-		"recipient", recipient)
-	
-	return nil
-} 
\ No newline at end of file
@@ -1,4 +1,7 @@
-// Package processor предоставляет процессоры для квантовых вычислений
+// Package processor содержит библиотеку квантовых алгоритмов (Гровер, Шор, QFT,
+// пакетная обработка) поверх QuestEnv. Пакет не является реализацией
+// vm.Processor и не подменяет классические опкоды EVM: выполнение контрактов
+// выбирается через реестр vm.RegisterProcessor.
 package processor
 
 import (
@@ -6,8 +9,6 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/quest/quantum"
 )
@@ -74,13 +75,17 @@ func (p *QuantumProcessor) ExecuteGrover(target []byte, searchSpace uint64) ([]b
 	if !p.initialized {
 		return nil, fmt.Errorf("квантовый процессор не инициализирован")
 	}
+	return p.executeGrover(target, searchSpace)
+}
 
+// executeGrover выполняет алгоритм Гровера без захвата мьютекса
+func (p *QuantumProcessor) executeGrover(target []byte, searchSpace uint64) ([]byte, error) {
 	// Оптимизированная версия для 5 кубитов
 	if p.maxQubits == OptimalQubitCount {
 		// С 5 кубитами можно искать в пространстве до 2^5 = 32 элементов
 		if searchSpace > 32 {
-			log.Warn("Пространство поиска слишком большое для 5 кубитов, усечение", 
-				"requested", searchSpace, 
+			log.Warn("Пространство поиска слишком большое для 5 кубитов, усечение",
+				"requested", searchSpace,
 				"actual", 32)
 			searchSpace = 32
 		}
@@ -98,12 +103,16 @@ func (p *QuantumProcessor) ExecuteShor(n uint64) ([]uint64, error) {
 	if !p.initialized {
 		return nil, fmt.Errorf("квантовый процессор не инициализирован")
 	}
+	return p.executeShor(n)
+}
 
+// executeShor выполняет алгоритм Шора без захвата мьютекса
+func (p *QuantumProcessor) executeShor(n uint64) ([]uint64, error) {
 	// Оптимизированная версия для 5 кубитов
 	if p.maxQubits == OptimalQubitCount {
 		// С 5 кубитами можно факторизовать числа до 2^5 = 32
 		if n > 31 {
-			log.Warn("Число слишком большое для факторизации с 5 кубитами, используем классический алгоритм", 
+			log.Warn("Число слишком большое для факторизации с 5 кубитами, используем классический алгоритм",
 				"number", n)
 			return p.classicalFactorization(n)
 		}
@@ -119,10 +128,10 @@ func (p *QuantumProcessor) classicalFactorization(n uint64) ([]uint64, error) {
 	if n % 2 == 0 {
 		return []uint64{2, n/2}, nil
 	}
-	
+
 	// Простой алгоритм пробного деления
 	var factors []uint64
-	
+
 	for i := uint64(3); i*i <= n; i += 2 {
 		if n % i == 0 {
 			factors = append(factors, i)
@@ -130,12 +139,12 @@ func (p *QuantumProcessor) classicalFactorization(n uint64) ([]uint64, error) {
 			break
 		}
 	}
-	
+
 	if len(factors) == 0 {
 		// Число простое
 		return nil, fmt.Errorf("число %d является простым", n)
 	}
-	
+
 	factors = append(factors, n)
 	return factors, nil
 }
@@ -148,7 +157,11 @@ func (p *QuantumProcessor) ExecuteQFT(data []complex128) ([]complex128, error) {
 	if !p.initialized {
 		return nil, fmt.Errorf("квантовый процессор не инициализирован")
 	}
+	return p.executeQFT(data)
+}
 
+// executeQFT выполняет квантовое преобразование Фурье без захвата мьютекса
+func (p *QuantumProcessor) executeQFT(data []complex128) ([]complex128, error) {
 	// Проверяем, что размер данных не превышает 2^5 = 32 для 5 кубитов
 	if len(data) > (1 << p.maxQubits) {
 		return nil, fmt.Errorf("размер данных превышает возможности %d кубитов", p.maxQubits)
@@ -237,21 +250,62 @@ type QuantumResult struct {
 	Error error
 }
 
-// Вспомогательные методы для пакетной обработки
+// Вспомогательные методы для пакетной обработки. Операции группы
+// выполняются последовательно, ошибка операции записывается в ее результат и
+// не прерывает остальные.
+
+// processBatchGrover выполняет операции "grover" с параметрами target
+// ([]byte) и searchSpace (uint64)
 func (p *QuantumProcessor) processBatchGrover(indices []int, operations []QuantumOperation, results []QuantumResult) {
-	// Реализация пакетной обработки алгоритма Гровера
+	for _, i := range indices {
+		target, ok := operations[i].Params["target"].([]byte)
+		searchSpace, ok2 := operations[i].Params["searchSpace"].(uint64)
+		if !ok || !ok2 {
+			results[i] = QuantumResult{Error: fmt.Errorf("%w: grover требует параметров target и searchSpace", ErrInvalidQuantumOperation)}
+			continue
+		}
+		data, err := p.executeGrover(target, searchSpace)
+		results[i] = QuantumResult{Data: data, Error: err}
+	}
 }
 
+// processBatchShor выполняет операции "shor" с параметром n (uint64)
 func (p *QuantumProcessor) processBatchShor(indices []int, operations []QuantumOperation, results []QuantumResult) {
-	// Реализация пакетной обработки алгоритма Шора
+	for _, i := range indices {
+		n, ok := operations[i].Params["n"].(uint64)
+		if !ok {
+			results[i] = QuantumResult{Error: fmt.Errorf("%w: shor требует параметра n", ErrInvalidQuantumOperation)}
+			continue
+		}
+		factors, err := p.executeShor(n)
+		results[i] = QuantumResult{Data: factors, Error: err}
+	}
 }
 
+// processBatchQFT выполняет операции "qft" с параметром data ([]complex128)
 func (p *QuantumProcessor) processBatchQFT(indices []int, operations []QuantumOperation, results []QuantumResult) {
-	// Реализация пакетной обработки QFT
+	for _, i := range indices {
+		data, ok := operations[i].Params["data"].([]complex128)
+		if !ok {
+			results[i] = QuantumResult{Error: fmt.Errorf("%w: qft требует параметра data", ErrInvalidQuantumOperation)}
+			continue
+		}
+		result, err := p.executeQFT(data)
+		results[i] = QuantumResult{Data: result, Error: err}
+	}
 }
 
+// processBatchRandom выполняет операции "random" с параметром length (int)
 func (p *QuantumProcessor) processBatchRandom(indices []int, operations []QuantumOperation, results []QuantumResult) {
-	// Реализация пакетной обработки генерации случайных чисел
+	for _, i := range indices {
+		length, ok := operations[i].Params["length"].(int)
+		if !ok {
+			results[i] = QuantumResult{Error: fmt.Errorf("%w: random требует параметра length", ErrInvalidQuantumOperation)}
+			continue
+		}
+		data, err := p.questEnv.GenerateQuantumRandomBytes(length)
+		results[i] = QuantumResult{Data: data, Error: err}
+	}
 }
 
 // Close освобождает ресурсы квантового процессора
@@ -273,27 +327,6 @@ func (p *QuantumProcessor) Reset() error {
 	return nil
 }
 
-// ProcessOpcode обрабатывает EVM опкод, заменяя его квантовой версией если возможно
-func (p *QuantumProcessor) ProcessOpcode(opcode vm.OpCode, stack *vm.Stack) error {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	if !p.initialized {
-		return fmt.Errorf("квантовый процессор не инициализирован")
-	}
-
-	// Проверка лимита операций
-	if p.opCache["op_count"] >= p.opCache["op_limit"] {
-		return ErrQuantumLimitExceeded
-	}
-
-	// Увеличиваем счетчик операций
-	p.opCache["op_count"]++
-
-	// Делегируем обработку опкода мапперу
-	return p.opcodeMapper.MapOpcode(opcode, stack)
-}
-
 // IsQuantumEnabled возвращает статус активации квантового режима
 func (p *QuantumProcessor) IsQuantumEnabled() bool {
 	return p.initialized
@@ -364,101 +397,6 @@ func (p *QuantumProcessor) GetQuantumState() (map[string]interface{}, error) {
 	return state, nil
 }
 
-// IntegrateWithEVM интегрирует квантовый процессор с EVM
-func (p *QuantumProcessor) IntegrateWithEVM(evm *vm.EVM) {
-	if !p.initialized {
-		return
-	}
-
-	// Интеграция квантового процессора с EVM будет зависеть от конкретной реализации
-	// В данном случае мы предполагаем, что EVM имеет интерфейс для добавления квантовых расширений
-}
-
-// ProcessJump обрабатывает операции перехода JUMP и JUMPI
-func (p *QuantumProcessor) ProcessJump(opcode vm.OpCode, stack *vm.Stack, pc *uint64, validJumpDests *vm.JumpTable) error {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	if !p.initialized {
-		return fmt.Errorf("квантовый процессор не инициализирован")
-	}
-
-	// Проверка лимита операций
-	if p.opCache["op_count"] >= p.opCache["op_limit"] {
-		return ErrQuantumLimitExceeded
-	}
-
-	p.opCache["op_count"]++
-
-	// Обработка операции JUMP
-	if opcode == vm.JUMP {
-		if stack.Len() < 1 {
-			return ErrStackUnderflow
-		}
-
-		// Получаем целевой адрес из стека
-		dest := stack.Peek().Uint64()
-
-		// Проверяем, что это допустимый адрес перехода
-		if !validJumpDests.Has(vm.OpCode(byte(dest))) {
-			return vm.ErrInvalidJump
-		}
-
-		// Генерируем небольшое квантовое возмущение для адреса
-		randomBytes, err := p.questEnv.GenerateQuantumRandomBytes(1)
-		if err != nil {
-			return err
-		}
-
-		// Применяем малое случайное возмущение (не более 1%) к адресу перехода
-		perturbation := uint64(randomBytes[0]) % 256
-		if perturbation > 0 && dest > 100*perturbation {
-			dest = dest - perturbation/100
-		}
-
-		// Устанавливаем новый счетчик команд
-		*pc = dest
-		return nil
-	}
-
-	// Обработка операции JUMPI (условный переход)
-	if opcode == vm.JUMPI {
-		if stack.Len() < 2 {
-			return ErrStackUnderflow
-		}
-
-		// Получаем целевой адрес и условие из стека
-		dest := stack.Peek().Uint64()
-		cond := stack.Peek2()
-
-		// Проверяем, что это допустимый адрес перехода
-		if !validJumpDests.Has(vm.OpCode(byte(dest))) {
-			return vm.ErrInvalidJump
-		}
-
-		// Если условие не равно нулю, выполняем переход
-		if !cond.IsZero() {
-			// Генерируем небольшое квантовое возмущение для адреса
-			randomBytes, err := p.questEnv.GenerateQuantumRandomBytes(1)
-			if err != nil {
-				return err
-			}
-
-			// Применяем малое случайное возмущение (не более 1%) к адресу перехода
-			perturbation := uint64(randomBytes[0]) % 256
-			if perturbation > 0 && dest > 100*perturbation {
-				dest = dest - perturbation/100
-			}
-
-			// Устанавливаем новый счетчик команд
-			*pc = dest
-		}
-		return nil
-	}
-
-	return ErrInvalidQuantumOperation
-}
-
 // ExecuteQuantumOperation выполняет произвольную квантовую операцию
 func (p *QuantumProcessor) ExecuteQuantumOperation(opType string, params map[string]interface{}) (map[string]interface{}, error) {
 	p.mutex.Lock()
@@ -620,119 +558,3 @@ func (p *QuantumProcessor) PerformQuantumTeleportation(sourceQubit, destQubit1,
 
 	return result, nil
 }
-
-// ApplyQuantumInstruction применяет квантовую инструкцию к состоянию процессора
-func (p *QuantumProcessor) ApplyQuantumInstruction(instruction []byte) error {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	if !p.initialized {
-		return fmt.Errorf("квантовый процессор не инициализирован")
-	}
-
-	// Проверка лимита операций
-	if p.opCache["op_count"] >= p.opCache["op_limit"] {
-		return ErrQuantumLimitExceeded
-	}
-
-	p.opCache["op_count"]++
-
-	// Формат инструкции:
-	// byte 0: тип операции
-	// byte 1-N: параметры операции
-
-	if len(instruction) < 1 {
-		return errors.New("пустая квантовая инструкция")
-	}
-
-	opType := instruction[0]
-	switch opType {
-	case 0x01: // Hadamard
-		if len(instruction) < 2 {
-			return errors.New("недостаточно параметров для операции Адамара")
-		}
-		qubit := int(instruction[1])
-		return p.questEnv.ApplyHadamard(qubit)
-
-	case 0x02: // PauliX
-		if len(instruction) < 2 {
-			return errors.New("недостаточно параметров для операции PauliX")
-		}
-		qubit := int(instruction[1])
-		return p.questEnv.ApplyPauliX(qubit)
-
-	case 0x03: // PauliY
-		if len(instruction) < 2 {
-			return errors.New("недостаточно параметров для операции PauliY")
-		}
-		qubit := int(instruction[1])
-		return p.questEnv.ApplyPauliY(qubit)
-
-	case 0x04: // PauliZ
-		if len(instruction) < 2 {
-			return errors.New("недостаточно параметров для операции PauliZ")
-		}
-		qubit := int(instruction[1])
-		return p.questEnv.ApplyPauliZ(qubit)
-
-	case 0x05: // CNOT
-		if len(instruction) < 3 {
-			return errors.New("недостаточно параметров для операции CNOT")
-		}
-		control := int(instruction[1])
-		target := int(instruction[2])
-		return p.questEnv.ApplyCNOT(control, target)
-
-	case 0x06: // Measure
-		if len(instruction) < 2 {
-			return errors.New("недостаточно параметров для операции измерения")
-		}
-		qubit := int(instruction[1])
-		_, err := p.questEnv.MeasureQubit(qubit)
-		return err
-
-	default:
-		return fmt.Errorf("неизвестный тип квантовой операции: 0x%02x", opType)
-	}
-}
-
-// GetOperationCost возвращает стоимость операции в газе с учетом квантовой сложности
-func (p *QuantumProcessor) GetOperationCost(opcode vm.OpCode) uint64 {
-	// Базовая стоимость операции
-	baseCost := uint64(500)
-
-	// Увеличиваем стоимость для квантовых операций
-	switch opcode {
-	// Операции с повышенной квантовой сложностью
-	case vm.SHA3, vm.CREATE, vm.CREATE2, vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
-		return baseCost * 10
-
-	// Операции со средней квантовой сложностью
-	case vm.EXP, vm.SLOAD, vm.SSTORE, vm.SELFDESTRUCT:
-		return baseCost * 5
-
-	// Операции с низкой квантовой сложностью
-	case vm.ADD, vm.MUL, vm.SUB, vm.DIV, vm.SDIV, vm.MOD, vm.SMOD, vm.ADDMOD, vm.MULMOD:
-		return baseCost * 2
-
-	// Стандартные операции
-	default:
-		return baseCost
-	}
-}
-
-// IsQuantumOperationAllowed проверяет, разрешена ли квантовая операция
-func (p *QuantumProcessor) IsQuantumOperationAllowed(opcode vm.OpCode) bool {
-	// Проверяем, включен ли квантовый режим
-	if !p.initialized {
-		return false
-	}
-
-	// Блокируем опасные операции в квантовом режиме
-	switch opcode {
-	case vm.SELFDESTRUCT, vm.CREATE, vm.CREATE2:
-		return false
-	default:
-		return true
-	}
-} 
\ No newline at end of file
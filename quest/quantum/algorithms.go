@@ -0,0 +1,459 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrAlgorithmFailed ошибка, возникающая, когда вероятностный квантовый
+// алгоритм не дал результата за отведенное количество попыток
+var ErrAlgorithmFailed = errors.New("квантовый алгоритм не дал результата")
+
+// applyControlledPhase применяет контролируемый фазовый сдвиг
+// |11⟩ -> e^(i*theta)|11⟩, разложенный на три фазовых сдвига и два CNOT
+func (q *QuestEnv) applyControlledPhase(control, target int, theta float64) error {
+	return applyGates(
+		func() error { return q.ApplyPhaseShift(control, theta/2) },
+		func() error { return q.ApplyCNOT(control, target) },
+		func() error { return q.ApplyPhaseShift(target, -theta/2) },
+		func() error { return q.ApplyCNOT(control, target) },
+		func() error { return q.ApplyPhaseShift(target, theta/2) },
+	)
+}
+
+// ApplyQFT применяет квантовое преобразование Фурье к кубитам qubits, где
+// qubits[0] - младший разряд числа x:
+// |x⟩ -> 1/sqrt(2^m) * sum_y e^(2*pi*i*x*y/2^m) |y⟩
+func (q *QuestEnv) ApplyQFT(qubits []int) error {
+	return q.applyQFT(qubits, false)
+}
+
+// ApplyInverseQFT применяет обратное квантовое преобразование Фурье к
+// кубитам qubits
+func (q *QuestEnv) ApplyInverseQFT(qubits []int) error {
+	return q.applyQFT(qubits, true)
+}
+
+// applyQFT раскладывает QFT на вентили Адамара, контролируемые фазовые
+// сдвиги и обмены, обращающие порядок кубитов. Обратное преобразование
+// выполняет те же вентили в обратном порядке с противоположными углами.
+func (q *QuestEnv) applyQFT(qubits []int, inverse bool) error {
+	seen := make(map[int]bool, len(qubits))
+	for _, qubit := range qubits {
+		if seen[qubit] {
+			return ErrInvalidControlTarget
+		}
+		seen[qubit] = true
+	}
+	sign := 1.0
+	if inverse {
+		sign = -1
+	}
+	m := len(qubits)
+	var gates []func() error
+	for j := m - 1; j >= 0; j-- {
+		target := qubits[j]
+		gates = append(gates, func() error { return q.ApplyHadamard(target) })
+		for k := j - 1; k >= 0; k-- {
+			control, theta := qubits[k], sign*math.Pi/float64(uint64(1)<<(j-k))
+			gates = append(gates, func() error { return q.applyControlledPhase(control, target, theta) })
+		}
+	}
+	for i := 0; i < m/2; i++ {
+		qubit1, qubit2 := qubits[i], qubits[m-1-i]
+		gates = append(gates, func() error { return q.ApplySwap(qubit1, qubit2) })
+	}
+	if inverse {
+		slices.Reverse(gates)
+	}
+	return applyGates(gates...)
+}
+
+// applyPermutation переставляет базисные состояния регистра по биекции f,
+// умножая амплитуды на phase(index), если phase задана. Так выполняются
+// оракулы алгоритмов. Оракулы не выражаются вентилями OpenQASM, поэтому при
+// включенной записи истории операция отклоняется.
+func (q *QuestEnv) applyPermutation(f func(uint64) uint64, phase func(uint64) complex128) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.backend == nil {
+		return ErrInvalidQuantumState
+	}
+	if q.recording {
+		return fmt.Errorf("%w: оракул", ErrUnsupportedGate)
+	}
+	state, err := q.amplitudes()
+	if err != nil {
+		return err
+	}
+	state.permute(f, phase)
+	return nil
+}
+
+// applyOracle меняет знак амплитуд базисных состояний, для которых marked
+// возвращает true
+func (q *QuestEnv) applyOracle(marked func(uint64) bool) error {
+	return q.applyPermutation(func(i uint64) uint64 { return i }, func(i uint64) complex128 {
+		if marked(i) {
+			return -1
+		}
+		return 1
+	})
+}
+
+// scratch создает вспомогательный плотный регистр для алгоритма с моделью
+// шума окружения. Зерно измерений регистра берется из генератора окружения,
+// поэтому результаты алгоритма детерминированы. Вызывается под мьютексом
+// окружения.
+func (q *QuestEnv) scratch(numQubits int) (*QuestEnv, error) {
+	if numQubits > MaxDenseQubits {
+		return nil, fmt.Errorf("%w: алгоритму нужно %d кубитов", ErrMaxQubitsExceeded, numQubits)
+	}
+	var seed common.Hash
+	if _, err := q.random.Read(seed[:]); err != nil {
+		return nil, err
+	}
+	env, err := NewQuestEnvWithBackend(numQubits, BackendDense, q.useGPU, q.gpuDeviceID, seed)
+	if err != nil {
+		return nil, err
+	}
+	// Вспомогательный регистр подвержен тому же шуму, что и регистр окружения
+	if err := env.SetNoiseModel(q.noise); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// GroverIterations возвращает оптимальное количество итераций алгоритма
+// Гровера для пространства из 2^numQubits состояний с одним отмеченным:
+// floor(pi/4 * sqrt(2^numQubits)). Стоимость QGROVER рассчитана по этому
+// количеству.
+func GroverIterations(numQubits int) int {
+	return int(math.Floor(math.Pi / 4 * math.Sqrt(math.Ldexp(1, numQubits))))
+}
+
+// GroverSearch выполняет алгоритм Гровера над всеми кубитами окружения:
+// сбрасывает регистр, готовит равномерную суперпозицию, iterations раз
+// применяет оракул, меняющий знак состояний, для которых marked возвращает
+// true, и диффузор, а затем измеряет регистр. Отмеченное состояние находится
+// с высокой, но не единичной вероятностью, поэтому результат следует
+// проверять классически.
+func (q *QuestEnv) GroverSearch(marked func(uint64) bool, iterations int) (uint64, error) {
+	if iterations < 0 {
+		return 0, ErrInvalidInput
+	}
+	if err := q.Reset(); err != nil {
+		return 0, err
+	}
+	hadamards := func() error {
+		for qubit := 0; qubit < q.numQubits; qubit++ {
+			if err := q.ApplyHadamard(qubit); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	oracle := func() error { return q.applyOracle(marked) }
+	// Диффузор 2|s⟩⟨s| - I = H (2|0⟩⟨0| - I) H
+	reflect := func() error { return q.applyOracle(func(i uint64) bool { return i != 0 }) }
+
+	if err := hadamards(); err != nil {
+		return 0, err
+	}
+	for i := 0; i < iterations; i++ {
+		if err := applyGates(oracle, hadamards, reflect, hadamards); err != nil {
+			return 0, err
+		}
+	}
+	return q.MeasureAllQubits()
+}
+
+// FindOrder выполняет схему нахождения порядка a по модулю n - наименьшего
+// r > 0, для которого a^r = 1 (mod n). Для L-битного n регистр окружения
+// должен содержать не меньше 3L кубитов: кубиты 0..L-1 образуют рабочий
+// регистр, кубиты L..3L-1 - счетный. Регистр сбрасывается, рабочий регистр
+// готовится в |1⟩, к нему применяются контролируемые умножения на a^(2^j)
+// (mod n), затем обратное QFT над счетным регистром и измерение. Порядок
+// восстанавливается из измеренной фазы цепной дробью. Если измерение не дало
+// порядка, возвращается false.
+func (q *QuestEnv) FindOrder(a, n uint64) (uint64, bool, error) {
+	if n < 3 || a < 2 || a >= n || gcd(a, n) != 1 {
+		return 0, false, ErrInvalidInput
+	}
+	l := bits.Len64(n)
+	if 3*l > q.GetQubitCount() {
+		return 0, false, fmt.Errorf("%w: схеме нужно %d кубитов", ErrMaxQubitsExceeded, 3*l)
+	}
+	if err := q.Reset(); err != nil {
+		return 0, false, err
+	}
+	gates := []func() error{func() error { return q.ApplyPauliX(0) }}
+	counting := make([]int, 2*l)
+	for j := range counting {
+		qubit := l + j
+		counting[j] = qubit
+		gates = append(gates, func() error { return q.ApplyHadamard(qubit) })
+	}
+	workMask := uint64(1)<<l - 1
+	for j := range counting {
+		// Контролируемое умножение рабочего регистра на a^(2^j) mod n.
+		// Состояния рабочего регистра не меньше n не изменяются, поэтому
+		// отображение остается биекцией.
+		control, factor := uint64(1)<<(l+j), powMod(a, uint64(1)<<j, n)
+		gates = append(gates, func() error {
+			return q.applyPermutation(func(i uint64) uint64 {
+				y := i & workMask
+				if i&control == 0 || y >= n {
+					return i
+				}
+				return i&^workMask | y*factor%n
+			}, nil)
+		})
+	}
+	gates = append(gates, func() error { return q.ApplyInverseQFT(counting) })
+	if err := applyGates(gates...); err != nil {
+		return 0, false, err
+	}
+	result, err := q.MeasureAllQubits()
+	if err != nil {
+		return 0, false, err
+	}
+	phase := result >> l & (uint64(1)<<(2*l) - 1)
+	order, ok := orderFromPhase(a, n, phase, 2*l)
+	return order, ok, nil
+}
+
+// orderFromPhase восстанавливает порядок a по модулю n из результата
+// измерения c счетного регистра из t кубитов: c/2^t приближает s/r.
+// Проверяются знаменатели подходящих дробей c/2^t, меньшие n, и их малые
+// кратные на случай, когда s и r не взаимно просты.
+func orderFromPhase(a, n, c uint64, t int) (uint64, bool) {
+	num, den := c, uint64(1)<<t
+	prev, cur := uint64(1), uint64(0) // знаменатели двух предыдущих подходящих дробей
+	for den != 0 {
+		term := num / den
+		num, den = den, num%den
+		prev, cur = cur, term*cur+prev
+		if cur >= n {
+			break
+		}
+		for k := uint64(1); k <= uint64(bits.Len64(n)) && k*cur < n; k++ {
+			if powMod(a, k*cur, n) == 1 {
+				return k * cur, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// FactorShor раскладывает n на два нетривиальных множителя алгоритмом Шора и
+// возвращает их по возрастанию. Четные числа и степени простых чисел
+// раскладываются классически, для остальных выполняется не более attempts
+// попыток нахождения порядка случайного основания на вспомогательном
+// регистре из 3L кубитов для L-битного n.
+func (q *QuestEnv) FactorShor(n uint64, attempts int) ([]uint64, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if n < 4 {
+		return nil, fmt.Errorf("число должно быть больше 3")
+	}
+	if n%2 == 0 {
+		return []uint64{2, n / 2}, nil
+	}
+	l := bits.Len64(n)
+	if 3*l > MaxDenseQubits {
+		return nil, fmt.Errorf("%w: для числа %d нужно %d кубитов", ErrMaxQubitsExceeded, n, 3*l)
+	}
+	if new(big.Int).SetUint64(n).ProbablyPrime(0) {
+		return nil, fmt.Errorf("входное число %d является простым", n)
+	}
+	if p, ok := primePowerBase(n); ok {
+		return []uint64{p, n / p}, nil
+	}
+	scratch, err := q.scratch(3 * l)
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Destroy()
+
+	for i := 0; i < attempts; i++ {
+		// Основание выбирается из [2, n-2]
+		r, err := q.random.Uint64n(n - 3)
+		if err != nil {
+			return nil, err
+		}
+		a := r + 2
+		if d := gcd(a, n); d != 1 {
+			return []uint64{min(d, n/d), max(d, n/d)}, nil
+		}
+		order, ok, err := scratch.FindOrder(a, n)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || order%2 != 0 {
+			continue
+		}
+		// x^2 = 1 (mod n) и x != 1, поэтому при x != -1 множитель
+		// gcd(x+1, n) нетривиален
+		x := powMod(a, order/2, n)
+		if x == n-1 {
+			continue
+		}
+		d := gcd(x+1, n)
+		return []uint64{min(d, n/d), max(d, n/d)}, nil
+	}
+	return nil, fmt.Errorf("%w: %d попыток факторизации %d", ErrAlgorithmFailed, attempts, n)
+}
+
+// primePowerBase проверяет, является ли n степенью p^k с k > 1, и возвращает p
+func primePowerBase(n uint64) (uint64, bool) {
+	for k := 2; k < bits.Len64(n); k++ {
+		p := intRoot(n, k)
+		if power, ok := ipow(p, k); p < 2 || !ok || power != n {
+			continue
+		}
+		// p может быть составным (например, 3^4 = 9^2), берем наименьшее
+		// основание
+		if base, ok := primePowerBase(p); ok {
+			return base, true
+		}
+		return p, true
+	}
+	return 0, false
+}
+
+// intRoot возвращает целую часть корня степени k из n. Корень ищется
+// двоичным поиском в целых числах: math.Pow на разных архитектурах может
+// отличаться в последнем бите.
+func intRoot(n uint64, k int) uint64 {
+	lo, hi := uint64(1), uint64(1)<<((bits.Len64(n)+k-1)/k)
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		if power, ok := ipow(mid, k); ok && power <= n {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
+
+// ipow возвращает p^k и false при переполнении uint64
+func ipow(p uint64, k int) (uint64, bool) {
+	power := uint64(1)
+	for i := 0; i < k; i++ {
+		hi, lo := bits.Mul64(power, p)
+		if hi != 0 {
+			return 0, false
+		}
+		power = lo
+	}
+	return power, true
+}
+
+// ExecuteShorAlgorithm раскладывает n на множители алгоритмом Шора с
+// количеством попыток QSHOR
+func (q *QuestEnv) ExecuteShorAlgorithm(n uint64) ([]uint64, error) {
+	return q.FactorShor(n, params.QuantumShorAttempts)
+}
+
+// ExecuteGroverAlgorithm ищет алгоритмом Гровера число target (big-endian) в
+// пространстве [0, searchSpace) на вспомогательном регистре из
+// ceil(log2(searchSpace)) кубитов с оптимальным количеством итераций и
+// возвращает найденное число в виде big-endian байтов длины target. Если
+// число не входит в пространство поиска, результат случаен.
+func (q *QuestEnv) ExecuteGroverAlgorithm(target []byte, searchSpace uint64) ([]byte, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(target) == 0 {
+		return nil, fmt.Errorf("целевые данные не могут быть пустыми")
+	}
+	if searchSpace == 0 {
+		return nil, fmt.Errorf("пространство поиска не может быть нулевым")
+	}
+	value := new(big.Int).SetBytes(target)
+	inSpace := value.IsUint64() && value.Uint64() < searchSpace
+	want := value.Uint64()
+	marked := func(i uint64) bool {
+		return inSpace && i == want
+	}
+	numQubits := max(bits.Len64(searchSpace-1), 1)
+	scratch, err := q.scratch(numQubits)
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Destroy()
+
+	found, err := scratch.GroverSearch(marked, GroverIterations(numQubits))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, len(target))
+	for i := len(result) - 1; i >= 0 && found != 0; i-- {
+		result[i] = byte(found)
+		found >>= 8
+	}
+	return result, nil
+}
+
+// ExecuteQFT выполняет квантовое преобразование Фурье вектора data, длина
+// которого должна быть степенью двойки, схемой ApplyQFT на вспомогательном
+// регистре. Вектор состояния регистра нормирован, поэтому данные делятся на
+// свою норму, а результат умножается на нее: преобразование линейно.
+func (q *QuestEnv) ExecuteQFT(data []complex128) ([]complex128, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	n := len(data)
+	if n == 0 {
+		return nil, fmt.Errorf("входные данные не могут быть пустыми")
+	}
+	if !isPowerOfTwo(n) {
+		return nil, fmt.Errorf("длина входных данных должна быть степенью двойки")
+	}
+	numQubits := bits.Len(uint(n)) - 1
+	if numQubits > MaxDenseQubits {
+		return nil, fmt.Errorf("%w: преобразованию нужно %d кубитов", ErrMaxQubitsExceeded, numQubits)
+	}
+	norm := 0.0
+	for _, v := range data {
+		norm += probability(v)
+	}
+	norm = math.Sqrt(norm)
+	if math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return nil, ErrInvalidInput
+	}
+	if norm == 0 || numQubits == 0 {
+		return append([]complex128(nil), data...), nil
+	}
+	state := make([]complex128, n)
+	for i, v := range data {
+		state[i] = cdiv(v, norm)
+	}
+	env := newQuestEnv(newDenseBackend(numQubits, state), numQubits, BackendDense, q.useGPU, q.gpuDeviceID, common.Hash{})
+	defer env.Destroy()
+
+	qubits := make([]int, numQubits)
+	for i := range qubits {
+		qubits[i] = i
+	}
+	if err := env.ApplyQFT(qubits); err != nil {
+		return nil, err
+	}
+	result := env.GetStateVector()
+	for i := range result {
+		result[i] = cscale(result[i], norm)
+	}
+	return result, nil
+}
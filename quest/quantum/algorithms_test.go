@@ -0,0 +1,144 @@
+package quantum
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Проверяет схему QFT по классическому дискретному преобразованию Фурье
+func TestExecuteQFT(t *testing.T) {
+	random := NewDeterministicRNG(common.Hash{1})
+	data := make([]complex128, 16)
+	for i := range data {
+		data[i] = complex(random.Float64()*10-5, random.Float64()*10-5)
+	}
+	env, _ := NewQuestEnv(1, false, 0)
+	have, err := env.ExecuteQFT(data)
+	if err != nil {
+		t.Fatalf("qft failed: %v", err)
+	}
+	n := len(data)
+	for y := 0; y < n; y++ {
+		var want complex128
+		for x := 0; x < n; x++ {
+			want += data[x] * cmplx.Rect(1, 2*math.Pi*float64(x*y)/float64(n))
+		}
+		want /= complex(math.Sqrt(float64(n)), 0)
+		if cmplx.Abs(have[y]-want) > 1e-9 {
+			t.Errorf("component %d: have %v, want %v", y, have[y], want)
+		}
+	}
+	if _, err := env.ExecuteQFT(make([]complex128, 6)); err == nil {
+		t.Errorf("expected error for length 6")
+	}
+}
+
+// Проверяет, что обратное QFT отменяет прямое на части регистра
+func TestInverseQFT(t *testing.T) {
+	env, _ := NewQuestEnvWithBackend(5, BackendDense, false, 0, common.Hash{})
+	for _, step := range randomCircuit(2, 5, 40, []string{"h", "x", "cnot", "t", "s"}) {
+		if err := applyStep(env, step); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := env.GetStateVector()
+	qubits := []int{4, 1, 3}
+	if err := env.ApplyQFT(qubits); err != nil {
+		t.Fatalf("qft failed: %v", err)
+	}
+	if err := env.ApplyInverseQFT(qubits); err != nil {
+		t.Fatalf("inverse qft failed: %v", err)
+	}
+	assertSameState(t, "qft", env.GetStateVector(), want)
+
+	if err := env.ApplyQFT([]int{0, 2, 0}); !errors.Is(err, ErrInvalidControlTarget) {
+		t.Errorf("repeated qubit: want %v, have %v", ErrInvalidControlTarget, err)
+	}
+}
+
+// Проверяет, что схема нахождения порядка возвращает только верные порядки
+// и находит их в большинстве запусков
+func TestFindOrder(t *testing.T) {
+	tests := []struct{ a, n, order uint64 }{
+		{7, 15, 4},
+		{2, 21, 6},
+		{5, 33, 10},
+	}
+	for _, tt := range tests {
+		env, _ := NewQuestEnvWithSeed(3*bits.Len64(tt.n), false, 0, common.Hash{byte(tt.n)})
+		found := 0
+		for i := 0; i < 10; i++ {
+			order, ok, err := env.FindOrder(tt.a, tt.n)
+			if err != nil {
+				t.Fatalf("order of %d mod %d: %v", tt.a, tt.n, err)
+			}
+			if !ok {
+				continue
+			}
+			if order != tt.order {
+				t.Fatalf("order of %d mod %d: have %d, want %d", tt.a, tt.n, order, tt.order)
+			}
+			found++
+		}
+		if found < 3 {
+			t.Errorf("order of %d mod %d found in %d of 10 runs", tt.a, tt.n, found)
+		}
+	}
+}
+
+// Проверяет разложение алгоритмом Шора по классическому ответу
+func TestFactorShor(t *testing.T) {
+	tests := []struct{ n, p, q uint64 }{
+		{15, 3, 5},
+		{21, 3, 7},
+		{35, 5, 7},
+		{51, 3, 17},
+		{55, 5, 11},
+		{27, 3, 9},
+		{50, 2, 25},
+	}
+	for _, tt := range tests {
+		env, _ := NewQuestEnvWithSeed(1, false, 0, common.Hash{byte(tt.n)})
+		factors, err := env.FactorShor(tt.n, 8)
+		if err != nil {
+			t.Fatalf("factor %d: %v", tt.n, err)
+		}
+		if factors[0] != tt.p || factors[1] != tt.q {
+			t.Errorf("factor %d: have %v, want [%d %d]", tt.n, factors, tt.p, tt.q)
+		}
+	}
+	env, _ := NewQuestEnv(1, false, 0)
+	if _, err := env.FactorShor(13, 8); err == nil {
+		t.Errorf("expected error for prime")
+	}
+	if _, err := env.FactorShor(1<<9+1, 8); !errors.Is(err, ErrMaxQubitsExceeded) {
+		t.Errorf("too large number: want %v, have %v", ErrMaxQubitsExceeded, err)
+	}
+}
+
+// Проверяет, что поиск Гровера находит целевое число
+func TestExecuteGrover(t *testing.T) {
+	env, _ := NewQuestEnvWithSeed(1, false, 0, common.Hash{0x47})
+	for _, target := range []uint64{0, 5, 42, 63, 99} {
+		result, err := env.ExecuteGroverAlgorithm([]byte{0, byte(target)}, 100)
+		if err != nil {
+			t.Fatalf("grover %d: %v", target, err)
+		}
+		if have := uint64(result[0])<<8 | uint64(result[1]); have != target {
+			t.Errorf("grover: have %d, want %d", have, target)
+		}
+	}
+	// Без итераций измеряется равномерная суперпозиция
+	scratch, _ := NewQuestEnvWithBackend(6, BackendDense, false, 0, common.Hash{})
+	if _, err := scratch.GroverSearch(func(uint64) bool { return false }, 0); err != nil {
+		t.Fatalf("grover without iterations: %v", err)
+	}
+	if _, err := scratch.GroverSearch(func(uint64) bool { return false }, -1); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("negative iterations: want %v, have %v", ErrInvalidInput, err)
+	}
+}
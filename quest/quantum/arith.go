@@ -0,0 +1,129 @@
+package quantum
+
+import "math"
+
+// Арифметика амплитуд входит в консенсус: результат QMEASURE и корень
+// состояния зависят от каждого бита амплитуд. Компилятор Go вправе сливать
+// x*y + z в одну инструкцию FMA (arm64, ppc64le, s390x), которая округляет
+// один раз вместо двух, и тогда узлы на разных архитектурах расходятся.
+// Явное преобразование float64(x*y) по спецификации языка принудительно
+// округляет произведение и запрещает слияние, поэтому все произведения
+// амплитуд проходят через функции этого файла. По той же причине здесь
+// собственная реализация sin/cos: math.Sin и math.Cos на arm64 тоже
+// собираются с FMA.
+
+// cmul возвращает произведение a*b с явным округлением каждого произведения
+func cmul(a, b complex128) complex128 {
+	ar, ai := real(a), imag(a)
+	br, bi := real(b), imag(b)
+	return complex(float64(ar*br)-float64(ai*bi), float64(ar*bi)+float64(ai*br))
+}
+
+// cscale умножает амплитуду на действительное число
+func cscale(a complex128, s float64) complex128 {
+	return complex(float64(real(a)*s), float64(imag(a)*s))
+}
+
+// cdiv делит амплитуду на действительное число. Комплексное деление
+// a/complex(s, 0) уходит в runtime.complex128div, который не защищен от FMA.
+func cdiv(a complex128, s float64) complex128 {
+	return complex(real(a)/s, imag(a)/s)
+}
+
+// cis возвращает e^(i*theta), детерминированную замену cmplx.Rect(1, theta)
+func cis(theta float64) complex128 {
+	s, c := sincos(theta)
+	return complex(c, s)
+}
+
+// Коэффициенты полиномов Cephes, те же, что в пакете math
+var (
+	sinCoef = [...]float64{
+		1.58962301576546568060e-10,
+		-2.50507477628578072866e-8,
+		2.75573136213857245213e-6,
+		-1.98412698295895385996e-4,
+		8.33333333332211858878e-3,
+		-1.66666666666666307295e-1,
+	}
+	cosCoef = [...]float64{
+		-1.13585365213876817300e-11,
+		2.08757008419747316778e-9,
+		-2.75573141792967388112e-7,
+		2.48015872888517045348e-5,
+		-1.38888888888730564116e-3,
+		4.16666666666665929218e-2,
+	}
+)
+
+const (
+	// Разложение π/4 на три части для редукции Коди-Уэйта
+	pi4A = 7.85398125648498535156e-1
+	pi4B = 3.77489470793079817668e-8
+	pi4C = 2.69515142907905952645e-15
+
+	// Выше этого порога редукция Коди-Уэйта теряет точность, и аргумент
+	// предварительно приводится к [0, 2π) точной операцией math.Mod. Для
+	// таких углов результат отличается от math.Sincos (там редукция
+	// Пэйна-Хэнека), но остается одинаковым на всех узлах.
+	reduceThreshold = 1 << 29
+)
+
+// horner вычисляет полином c[0]*z^5 + ... + c[5] с округлением каждого шага
+func horner(c *[6]float64, z float64) float64 {
+	r := c[0]
+	for _, k := range c[1:] {
+		r = float64(r*z) + k
+	}
+	return r
+}
+
+// sincos повторяет алгоритм math.Sincos, но округляет каждое произведение
+// явно и потому дает одинаковый результат на всех архитектурах
+func sincos(x float64) (sin, cos float64) {
+	switch {
+	case x == 0:
+		return x, 1
+	case math.IsNaN(x) || math.IsInf(x, 0):
+		return math.NaN(), math.NaN()
+	}
+
+	sinSign, cosSign := false, false
+	if x < 0 {
+		x = -x
+		sinSign = true
+	}
+	if x >= reduceThreshold {
+		x = math.Mod(x, 2*math.Pi)
+	}
+
+	j := uint64(float64(x * (4 / math.Pi)))
+	y := float64(j)
+	if j&1 == 1 {
+		j++
+		y++
+	}
+	j &= 7
+	if j > 3 {
+		j -= 4
+		sinSign, cosSign = !sinSign, !cosSign
+	}
+	if j > 1 {
+		cosSign = !cosSign
+	}
+
+	z := ((x - float64(y*pi4A)) - float64(y*pi4B)) - float64(y*pi4C)
+	zz := float64(z * z)
+	c := 1.0 - float64(0.5*zz) + float64(float64(zz*zz)*horner(&cosCoef, zz))
+	s := z + float64(float64(z*zz)*horner(&sinCoef, zz))
+	if j == 1 || j == 2 {
+		s, c = c, s
+	}
+	if cosSign {
+		c = -c
+	}
+	if sinSign {
+		s = -s
+	}
+	return s, c
+}
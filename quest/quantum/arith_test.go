@@ -0,0 +1,78 @@
+package quantum
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Проверяет детерминированные sin/cos по math.Sincos
+func TestSincos(t *testing.T) {
+	for _, x := range []float64{
+		0, 1e-300, 0.1, 0.5, 1, math.Pi / 4, math.Pi / 2, 3, math.Pi,
+		5, 2 * math.Pi, 10, 100, 12345.678, 1<<29 - 1,
+	} {
+		for _, v := range []float64{x, -x} {
+			s, c := sincos(v)
+			ws, wc := math.Sincos(v)
+			if math.Abs(s-ws) > 1e-15 || math.Abs(c-wc) > 1e-15 {
+				t.Errorf("sincos(%v) mismatch: have (%v, %v), want (%v, %v)", v, s, c, ws, wc)
+			}
+		}
+	}
+	// Большие углы приводятся по модулю 2π
+	for _, v := range []float64{1 << 29, 1e10, -1e300} {
+		s, c := sincos(v)
+		ws, wc := sincos(math.Mod(v, 2*math.Pi))
+		if s != ws || c != wc {
+			t.Errorf("sincos(%v) mismatch: have (%v, %v), want (%v, %v)", v, s, c, ws, wc)
+		}
+	}
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if s, c := sincos(v); !math.IsNaN(s) || !math.IsNaN(c) {
+			t.Errorf("sincos(%v) = (%v, %v), want NaN", v, s, c)
+		}
+	}
+}
+
+// Проверяет, что регистр после вращений, шума и измерений кодируется
+// одинаково на всех архитектурах. Изменение ожидаемых хешей означает
+// изменение консенсуса.
+func TestRegisterEncodingPinned(t *testing.T) {
+	tests := []struct {
+		backend Backend
+		want    common.Hash
+	}{
+		{BackendDense, common.HexToHash("0x7331a1a6d22281e411c3d51b622d94d3d8bc03cc5ba189d3dea97cb59ab27b4a")},
+		{BackendSparse, common.HexToHash("0x3dbca0e1801936e243e6a9a6b5e1485aa81caf3590b3cfb91bd3b1186fdf805d")},
+	}
+	for _, tt := range tests {
+		env, err := NewQuestEnvWithBackend(4, tt.backend, false, 0, common.HexToHash("0x5eed"))
+		if err != nil {
+			t.Fatalf("%v: failed to create env: %v", tt.backend, err)
+		}
+		if err := env.SetNoiseModel(&NoiseModel{Depolarizing: 0.01, AmplitudeDamping: 0.05}); err != nil {
+			t.Fatalf("%v: failed to set noise: %v", tt.backend, err)
+		}
+		steps := []func() error{
+			func() error { return env.ApplyHadamard(0) },
+			func() error { return env.ApplyU3(1, 0.3, 1.1, -0.7) },
+			func() error { return env.ApplyCPhase(0, 2, 0.9) },
+			func() error { return env.ApplyPhaseShift(3, 2.5) },
+			func() error { return env.ApplyQFT([]int{0, 1, 2, 3}) },
+			func() error { _, err := env.MeasureQubit(1); return err },
+			func() error { return env.ApplyControlledU3([]int{1}, 2, 1.7, -0.2, 0.4) },
+		}
+		for i, step := range steps {
+			if err := step(); err != nil {
+				t.Fatalf("%v: step %d failed: %v", tt.backend, i, err)
+			}
+		}
+		if have := crypto.Keccak256Hash(env.EncodeState()); have != tt.want {
+			t.Errorf("%v: encoding hash mismatch: have %x, want %x", tt.backend, have, tt.want)
+		}
+		env.Destroy()
+	}
+}
@@ -0,0 +1,295 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Backend определяет способ представления состояния квантового регистра.
+// Все бэкенды реализуют одни и те же операции QuestEnv, но различаются
+// объемом памяти и набором эффективно поддерживаемых вентилей.
+type Backend uint8
+
+const (
+	// BackendAuto начинает со стабилизаторной таблицы и переходит к
+	// разреженному, а затем к плотному вектору состояния, когда схема
+	// перестает быть клиффордовой или разреженное представление становится
+	// больше плотного
+	BackendAuto Backend = iota
+
+	// BackendDense хранит все 2^n амплитуд регистра
+	BackendDense
+
+	// BackendSparse хранит только ненулевые амплитуды
+	BackendSparse
+
+	// BackendStabilizer хранит таблицу стабилизаторов (CHP) и поддерживает
+	// только клиффордовы вентили и измерения
+	BackendStabilizer
+)
+
+// ConsensusBackend - бэкенд регистров контрактов, создаваемых QINIT и
+// загружаемых из состояния. Представление влияет на стоимость инструкций и
+// результаты измерений, поэтому оно является правилом форка Quantum, а не
+// настройкой узла.
+const ConsensusBackend = BackendAuto
+
+// Ограничения размеров регистров для каждого представления
+const (
+	// MaxDenseQubits - максимальный размер плотного регистра:
+	// 2^25 амплитуд занимают 512 МиБ
+	MaxDenseQubits = 25
+
+	// MaxSparseQubits - максимальный размер разреженного регистра, индекс
+	// базисного состояния хранится в uint64
+	MaxSparseQubits = 64
+
+	// MaxStabilizerQubits - максимальный размер стабилизаторного регистра,
+	// ограниченный однобайтовым полем размера в кодировке регистра. Такие
+	// регистры создаются только схемами (прекомпиляция и
+	// quest_simulateCircuit), результат которых - классические биты.
+	MaxStabilizerQubits = 255
+
+	// MaxRegisterQubits - максимальный размер регистра контракта для любого
+	// бэкенда: QMEASUREALL возвращает индекс базисного состояния одним
+	// uint64, а разреженное представление хранит его в uint64
+	MaxRegisterQubits = 64
+
+	// maxSparseEntries - максимальное количество ненулевых амплитуд
+	// разреженного регистра
+	maxSparseEntries = 1 << 20
+
+	// sparseEntrySize - размер одной ненулевой амплитуды разреженного регистра:
+	// индекс базисного состояния и амплитуда
+	sparseEntrySize = 8 + amplitudeSize
+
+	// sparsePruneThreshold - квадрат модуля, ниже которого амплитуда
+	// разреженного регистра считается нулевой и удаляется
+	sparsePruneThreshold = 1e-24
+)
+
+var (
+	// ErrUnknownBackend ошибка, возникающая при выборе неизвестного бэкенда
+	ErrUnknownBackend = errors.New("неизвестный бэкенд квантовой симуляции")
+
+	// ErrNotClifford ошибка, возникающая при применении неклиффордова вентиля
+	// к регистру, ограниченному стабилизаторным бэкендом
+	ErrNotClifford = errors.New("вентиль не является клиффордовым")
+
+	// ErrBackendCapacity ошибка, возникающая, когда состояние регистра не
+	// помещается ни в одно допустимое представление
+	ErrBackendCapacity = errors.New("состояние регистра превышает возможности бэкенда")
+)
+
+// backendNames содержит имена бэкендов, используемые в конфигурации
+var backendNames = map[Backend]string{
+	BackendAuto:       "auto",
+	BackendDense:      "dense",
+	BackendSparse:     "sparse",
+	BackendStabilizer: "stabilizer",
+}
+
+// String возвращает имя бэкенда
+func (b Backend) String() string {
+	if name, ok := backendNames[b]; ok {
+		return name
+	}
+	return fmt.Sprintf("backend(%d)", uint8(b))
+}
+
+// ParseBackend возвращает бэкенд по имени. Пустое имя выбирает BackendAuto.
+func ParseBackend(name string) (Backend, error) {
+	if name == "" {
+		return BackendAuto, nil
+	}
+	for b, n := range backendNames {
+		if n == name {
+			return b, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownBackend, name)
+}
+
+// maxQubits возвращает максимальный размер регистра, создаваемого бэкендом
+func (b Backend) maxQubits() int {
+	switch b {
+	case BackendDense:
+		return MaxDenseQubits
+	case BackendSparse:
+		return MaxSparseQubits
+	default:
+		return MaxStabilizerQubits
+	}
+}
+
+// RegisterWords возвращает размер в 32-байтовых словах нового регистра из
+// numQubits кубитов, создаваемого бэкендом b. Стоимость QINIT
+// пропорциональна этому размеру.
+func RegisterWords(b Backend, numQubits int) (uint64, error) {
+	if numQubits <= 0 || numQubits > b.maxQubits() {
+		return 0, fmt.Errorf("%w: %d кубитов для бэкенда %v", ErrBackendCapacity, numQubits, b)
+	}
+	switch b {
+	case BackendDense:
+		return denseWords(numQubits), nil
+	case BackendSparse:
+		return sparseWords(1), nil
+	default:
+		return stabilizerWords(numQubits), nil
+	}
+}
+
+// stateBackend - представление состояния регистра. Индексы кубитов
+// проверяются QuestEnv до вызова методов.
+type stateBackend interface {
+	// kind возвращает тип представления
+	kind() Backend
+
+	// words возвращает размер представления в 32-байтовых словах
+	words() uint64
+
+	// reset возвращает регистр в состояние |0...0⟩
+	reset()
+
+	// Клиффордовы вентили
+	hadamard(qubit int)
+	pauliX(qubit int)
+	pauliY(qubit int)
+	pauliZ(qubit int)
+	cnot(control, target int)
+	swap(qubit1, qubit2 int)
+
+	// measure измеряет кубит и коллапсирует состояние
+	measure(qubit int, random *DeterministicRNG) int
+
+	// measureAll измеряет все кубиты и возвращает младшие 64 бита результата
+	measureAll(random *DeterministicRNG) uint64
+
+	// encode возвращает каноническую сериализацию регистра
+	encode() []byte
+}
+
+// amplitudeBackend - представление вектором состояния, поддерживающее
+// произвольные фазовые сдвиги и доступ к амплитудам
+type amplitudeBackend interface {
+	stateBackend
+
+	// phaseShift применяет фазовый сдвиг |1⟩ -> e^(i*theta)|1⟩
+	phaseShift(qubit int, theta float64)
+
+	// unitary применяет однокубитный оператор m к целевому кубиту в базисных
+	// состояниях, где все кубиты маски controls равны 1. Маска не содержит
+	// целевого кубита.
+	unitary(controls uint64, target int, m [2][2]complex128)
+
+	// permute переставляет базисные состояния по биекции f, умножая
+	// амплитуды на phase(index), если phase задана
+	permute(f func(uint64) uint64, phase func(uint64) complex128)
+
+	// amplitude возвращает амплитуду базисного состояния
+	amplitude(basisState uint64) complex128
+
+	// entries возвращает количество хранимых амплитуд
+	entries() int
+
+	// probabilityOne возвращает вероятность измерить кубит в состоянии |1⟩
+	probabilityOne(qubit int) float64
+
+	// scale умножает амплитуды базисных состояний, в которых кубит равен 0,
+	// на s0, а остальные - на s1. Так применяются неунитарные операторы
+	// Крауса каналов шума.
+	scale(qubit int, s0, s1 float64)
+}
+
+// vectorBackend - плотный вектор состояния из 2^n амплитуд. Реализация
+// выбирается при сборке: по умолчанию используется denseState, а с тегом
+// questc - библиотека QuEST.
+type vectorBackend interface {
+	amplitudeBackend
+
+	// vector возвращает копию вектора состояния
+	vector() []complex128
+}
+
+// byteWords возвращает количество 32-байтовых слов, занимаемых size байтами
+func byteWords(size uint64) uint64 {
+	return (size + 31) / 32
+}
+
+// cliffordPhase проверяет, является ли фазовый сдвиг на угол theta
+// клиффордовым, то есть степенью вентиля S (сдвиг на кратное pi/2), и
+// возвращает показатель степени от 0 до 3
+func cliffordPhase(theta float64) (int, bool) {
+	quarters := theta / (math.Pi / 2)
+	if quarters != math.Trunc(quarters) || math.Abs(quarters) > 1<<52 {
+		return 0, false
+	}
+	k := int(math.Mod(quarters, 4))
+	if k < 0 {
+		k += 4
+	}
+	return k, true
+}
+
+// probability возвращает квадрат модуля амплитуды
+func probability(amp complex128) float64 {
+	re, im := real(amp), imag(amp)
+	return float64(re*re) + float64(im*im)
+}
+
+// newBackend создает представление регистра из numQubits кубитов в
+// состоянии |0...0⟩ для выбранного бэкенда
+func newBackend(b Backend, numQubits int) (stateBackend, error) {
+	if _, err := RegisterWords(b, numQubits); err != nil {
+		return nil, err
+	}
+	switch b {
+	case BackendDense:
+		return newDenseBackend(numQubits, nil), nil
+	case BackendSparse:
+		return newSparseState(numQubits), nil
+	case BackendAuto, BackendStabilizer:
+		return newStabilizerState(numQubits), nil
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownBackend, b)
+	}
+}
+
+// toAmplitudes переводит стабилизаторное представление в вектор состояния.
+// В режиме BackendDense результат всегда плотный, в остальных режимах
+// используется разреженный вектор, а плотный - только в режиме BackendAuto,
+// если разреженный не помещается в maxSparseEntries.
+func toAmplitudes(s *stabilizerState, mode Backend) (amplitudeBackend, error) {
+	if s.n > MaxSparseQubits {
+		return nil, fmt.Errorf("%w: %d кубитов", ErrBackendCapacity, s.n)
+	}
+	support := s.supportBits()
+	dense := mode == BackendDense || (mode == BackendAuto && support > bitsLen(maxSparseEntries) && s.n <= MaxDenseQubits)
+	switch {
+	case dense && s.n <= MaxDenseQubits:
+		state := make([]complex128, 1<<s.n)
+		s.forEachAmplitude(func(index uint64, amp complex128) {
+			state[index] = amp
+		})
+		return newDenseBackend(s.n, state), nil
+	case !dense && support <= bitsLen(maxSparseEntries):
+		sp := &sparseState{n: s.n, amp: make(map[uint64]complex128, 1<<support)}
+		s.forEachAmplitude(func(index uint64, amp complex128) {
+			sp.amp[index] = amp
+		})
+		return sp, nil
+	}
+	return nil, fmt.Errorf("%w: носитель из 2^%d состояний", ErrBackendCapacity, support)
+}
+
+// bitsLen возвращает log2 степени двойки
+func bitsLen(n int) int {
+	bits := 0
+	for n > 1 {
+		n >>= 1
+		bits++
+	}
+	return bits
+}
@@ -0,0 +1,262 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Матрицы базовых квантовых вентилей
+var (
+	// Вентиль Адамара
+	hadamardGate = [2][2]complex128{
+		{complex(1/math.Sqrt2, 0), complex(1/math.Sqrt2, 0)},
+		{complex(1/math.Sqrt2, 0), complex(-1/math.Sqrt2, 0)},
+	}
+
+	// Вентиль Паули-Y
+	pauliYGate = [2][2]complex128{
+		{complex(0, 0), complex(0, -1)},
+		{complex(0, 1), complex(0, 0)},
+	}
+)
+
+// denseState - плотный вектор состояния из 2^n амплитуд
+type denseState struct {
+	n     int
+	state []complex128
+}
+
+// newDenseState создает плотный регистр в состоянии |0...0⟩
+func newDenseState(numQubits int) *denseState {
+	state := make([]complex128, 1<<numQubits)
+	state[0] = complex(1.0, 0.0)
+	return &denseState{n: numQubits, state: state}
+}
+
+// denseWords возвращает размер плотного регистра в 32-байтовых словах
+func denseWords(numQubits int) uint64 {
+	return byteWords(uint64(amplitudeSize) << numQubits)
+}
+
+func (d *denseState) kind() Backend { return BackendDense }
+func (d *denseState) words() uint64 { return denseWords(d.n) }
+func (d *denseState) entries() int  { return len(d.state) }
+
+func (d *denseState) reset() {
+	// Очищаем все амплитуды и устанавливаем начальное состояние |0...0⟩
+	for i := range d.state {
+		d.state[i] = complex(0, 0)
+	}
+	d.state[0] = complex(1.0, 0.0)
+}
+
+func (d *denseState) hadamard(qubit int) {
+	newState := make([]complex128, len(d.state))
+	for i := 0; i < len(d.state); i++ {
+		// Индекс состояния с инвертированным битом
+		flipped := i ^ (1 << qubit)
+
+		if (i>>qubit)&1 == 0 {
+			// |0⟩ -> (|0⟩ + |1⟩)/√2
+			newState[i] += cmul(d.state[i], hadamardGate[0][0])
+			newState[flipped] += cmul(d.state[i], hadamardGate[0][1])
+		} else {
+			// |1⟩ -> (|0⟩ - |1⟩)/√2
+			newState[flipped] += cmul(d.state[i], hadamardGate[1][0])
+			newState[i] += cmul(d.state[i], hadamardGate[1][1])
+		}
+	}
+	d.state = newState
+}
+
+func (d *denseState) pauliX(qubit int) {
+	// Вентиль Паули-X просто меняет местами амплитуды для |0⟩ и |1⟩
+	for i := 0; i < len(d.state); i += 1 << (qubit + 1) {
+		for j := 0; j < 1<<qubit; j++ {
+			idx0 := i + j
+			idx1 := i + j + (1 << qubit)
+			d.state[idx0], d.state[idx1] = d.state[idx1], d.state[idx0]
+		}
+	}
+}
+
+func (d *denseState) pauliY(qubit int) {
+	newState := make([]complex128, len(d.state))
+	for i := 0; i < len(d.state); i++ {
+		flipped := i ^ (1 << qubit)
+		if (i>>qubit)&1 == 0 {
+			// |0⟩ -> i|1⟩
+			newState[flipped] += cmul(d.state[i], pauliYGate[1][0])
+		} else {
+			// |1⟩ -> -i|0⟩
+			newState[flipped] += cmul(d.state[i], pauliYGate[0][1])
+		}
+	}
+	d.state = newState
+}
+
+func (d *denseState) pauliZ(qubit int) {
+	// Вентиль Паули-Z меняет фазу амплитуды для состояний, где указанный бит = 1
+	for i := 0; i < len(d.state); i++ {
+		if (i>>qubit)&1 == 1 {
+			d.state[i] = -d.state[i]
+		}
+	}
+}
+
+func (d *denseState) cnot(control, target int) {
+	// CNOT инвертирует целевой кубит, если управляющий кубит в состоянии |1⟩.
+	// Каждая пара обменивается один раз, со стороны нулевого целевого бита.
+	for i := 0; i < len(d.state); i++ {
+		if (i>>control)&1 == 1 && (i>>target)&1 == 0 {
+			flipped := i ^ (1 << target)
+			d.state[i], d.state[flipped] = d.state[flipped], d.state[i]
+		}
+	}
+}
+
+func (d *denseState) swap(qubit1, qubit2 int) {
+	// Для каждого базисного состояния с битами 1 и 0 меняем их местами
+	for i := 0; i < len(d.state); i++ {
+		if (i>>qubit1)&1 == 1 && (i>>qubit2)&1 == 0 {
+			j := i ^ (1 << qubit1) ^ (1 << qubit2)
+			d.state[i], d.state[j] = d.state[j], d.state[i]
+		}
+	}
+}
+
+func (d *denseState) phaseShift(qubit int, theta float64) {
+	// |1⟩ -> e^(i*theta)|1⟩
+	phase := cis(theta)
+	for i := 0; i < len(d.state); i++ {
+		if (i>>qubit)&1 == 1 {
+			d.state[i] = cmul(d.state[i], phase)
+		}
+	}
+}
+
+func (d *denseState) unitary(controls uint64, target int, m [2][2]complex128) {
+	// Каждая пара |x0⟩, |x1⟩ обрабатывается один раз, со стороны нулевого
+	// целевого бита
+	bit := 1 << target
+	for i := 0; i < len(d.state); i++ {
+		if i&bit != 0 || uint64(i)&controls != controls {
+			continue
+		}
+		a0, a1 := d.state[i], d.state[i|bit]
+		d.state[i] = cmul(m[0][0], a0) + cmul(m[0][1], a1)
+		d.state[i|bit] = cmul(m[1][0], a0) + cmul(m[1][1], a1)
+	}
+}
+
+func (d *denseState) permute(f func(uint64) uint64, phase func(uint64) complex128) {
+	newState := make([]complex128, len(d.state))
+	for i, amp := range d.state {
+		if phase != nil {
+			amp = cmul(amp, phase(uint64(i)))
+		}
+		newState[f(uint64(i))] = amp
+	}
+	d.state = newState
+}
+
+func (d *denseState) probabilityOne(qubit int) float64 {
+	prob1 := 0.0
+	for i := 0; i < len(d.state); i++ {
+		if (i>>qubit)&1 == 1 {
+			prob1 += probability(d.state[i])
+		}
+	}
+	return prob1
+}
+
+func (d *denseState) scale(qubit int, s0, s1 float64) {
+	for i := 0; i < len(d.state); i++ {
+		if (i>>qubit)&1 == 1 {
+			d.state[i] = cscale(d.state[i], s1)
+		} else {
+			d.state[i] = cscale(d.state[i], s0)
+		}
+	}
+}
+
+func (d *denseState) measure(qubit int, random *DeterministicRNG) int {
+	// Вычисляем вероятность измерения |1⟩
+	prob1 := 0.0
+	for i := 0; i < len(d.state); i++ {
+		if (i>>qubit)&1 == 1 {
+			prob1 += probability(d.state[i])
+		}
+	}
+	result := 0
+	if random.Float64() < prob1 {
+		result = 1
+	}
+
+	// Коллапсируем состояние в соответствии с результатом измерения
+	norm := 0.0
+	for i := 0; i < len(d.state); i++ {
+		if (i>>qubit)&1 != result {
+			d.state[i] = complex(0, 0)
+		} else {
+			norm += probability(d.state[i])
+		}
+	}
+	// Нормализуем состояние
+	norm = math.Sqrt(norm)
+	if norm > 0 {
+		for i := 0; i < len(d.state); i++ {
+			d.state[i] = cdiv(d.state[i], norm)
+		}
+	}
+	return result
+}
+
+func (d *denseState) measureAll(random *DeterministicRNG) uint64 {
+	// Выбираем результат на основе вероятностей базисных состояний
+	r := random.Float64()
+	cumulative := 0.0
+	var result uint64
+	for i := 0; i < len(d.state); i++ {
+		cumulative += probability(d.state[i])
+		if r < cumulative {
+			result = uint64(i)
+			break
+		}
+	}
+	// Коллапсируем состояние в выбранный базисный вектор
+	for i := range d.state {
+		d.state[i] = complex(0, 0)
+	}
+	d.state[result] = complex(1, 0)
+	return result
+}
+
+func (d *denseState) amplitude(basisState uint64) complex128 {
+	return d.state[basisState]
+}
+
+func (d *denseState) vector() []complex128 {
+	return append([]complex128(nil), d.state...)
+}
+
+// encode сериализует плотный регистр (версия registerVersionDense)
+func (d *denseState) encode() []byte {
+	out := make([]byte, registerHeaderSize+len(d.state)*amplitudeSize)
+	out[0] = registerVersionDense
+	out[1] = byte(d.n)
+
+	buf := out[registerHeaderSize:]
+	for i, amp := range d.state {
+		putAmplitude(buf[i*amplitudeSize:], amp)
+	}
+	return out
+}
+
+// putAmplitude записывает амплитуду в 16 байт: действительная и мнимая
+// части в виде IEEE-754 float64 в порядке big-endian
+func putAmplitude(buf []byte, amp complex128) {
+	binary.BigEndian.PutUint64(buf, math.Float64bits(canonicalFloat(real(amp))))
+	binary.BigEndian.PutUint64(buf[8:], math.Float64bits(canonicalFloat(imag(amp))))
+}
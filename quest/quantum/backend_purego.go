@@ -0,0 +1,14 @@
+//go:build !questc || !cgo
+
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+// newDenseBackend создает плотный регистр из вектора состояния state длины
+// 2^numQubits, которым он завладевает, или в состоянии |0...0⟩, если state
+// равен nil
+func newDenseBackend(numQubits int, state []complex128) vectorBackend {
+	if state == nil {
+		return newDenseState(numQubits)
+	}
+	return &denseState{n: numQubits, state: state}
+}
@@ -0,0 +1,170 @@
+//go:build questc && cgo
+
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+// Привязка к библиотеке QuEST, собранной скриптом quest/setup.sh: заголовки
+// копируются в quest/include, библиотека - в quest/lib/libquestkit.a.
+// Библиотека должна быть собрана с двойной точностью (FLOAT_PRECISION=2),
+// тогда qcomp совпадает с complex128.
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../.. -I${SRCDIR}/../include
+#cgo LDFLAGS: -L${SRCDIR}/../lib -lquestkit -lstdc++ -lm
+#cgo linux LDFLAGS: -fopenmp
+
+#include "quest.h"
+*/
+import "C"
+
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// questEnvOnce инициализирует окружение QuEST один раз на процесс
+var questEnvOnce sync.Once
+
+// questcState - плотный вектор состояния, хранящийся в регистре QuEST.
+// Вентили выполняются библиотекой, а случайные исходы измерений выбираются
+// детерминированным генератором окружения, как и в denseState.
+type questcState struct {
+	n     int
+	qureg C.Qureg
+	freed bool
+}
+
+// newDenseBackend создает плотный регистр QuEST из вектора состояния state
+// длины 2^numQubits или в состоянии |0...0⟩, если state равен nil
+func newDenseBackend(numQubits int, state []complex128) vectorBackend {
+	questEnvOnce.Do(func() { C.initQuESTEnv() })
+
+	s := &questcState{n: numQubits, qureg: C.createQureg(C.int(numQubits))}
+	if state == nil {
+		C.initZeroState(s.qureg)
+	} else {
+		C.setQuregAmps(s.qureg, 0, (*C.qcomp)(unsafe.Pointer(&state[0])), C.qindex(len(state)))
+	}
+	runtime.SetFinalizer(s, (*questcState).release)
+	return s
+}
+
+// release освобождает регистр QuEST. Повторный вызов ничего не делает.
+func (s *questcState) release() {
+	if !s.freed {
+		C.destroyQureg(s.qureg)
+		s.freed = true
+	}
+}
+
+func (s *questcState) kind() Backend { return BackendDense }
+func (s *questcState) words() uint64 { return denseWords(s.n) }
+func (s *questcState) entries() int  { return 1 << s.n }
+
+func (s *questcState) reset() {
+	C.initZeroState(s.qureg)
+}
+
+func (s *questcState) hadamard(qubit int) {
+	C.applyHadamard(s.qureg, C.int(qubit))
+}
+
+func (s *questcState) pauliX(qubit int) {
+	C.applyPauliX(s.qureg, C.int(qubit))
+}
+
+func (s *questcState) pauliY(qubit int) {
+	C.applyPauliY(s.qureg, C.int(qubit))
+}
+
+func (s *questcState) pauliZ(qubit int) {
+	C.applyPauliZ(s.qureg, C.int(qubit))
+}
+
+func (s *questcState) cnot(control, target int) {
+	C.applyControlledPauliX(s.qureg, C.int(control), C.int(target))
+}
+
+func (s *questcState) swap(qubit1, qubit2 int) {
+	C.applySwap(s.qureg, C.int(qubit1), C.int(qubit2))
+}
+
+func (s *questcState) phaseShift(qubit int, theta float64) {
+	C.applyPhaseShift(s.qureg, C.int(qubit), C.qreal(theta))
+}
+
+func (s *questcState) unitary(controls uint64, target int, m [2][2]complex128) {
+	matrix := C.CompMatr1{numQubits: 1, numRows: 2}
+	for i := range m {
+		for j := range m[i] {
+			matrix.elems[i][j] = C.qcomp(m[i][j])
+		}
+	}
+	var ctrls []C.int
+	for qubit := 0; qubit < s.n; qubit++ {
+		if controls&(1<<qubit) != 0 {
+			ctrls = append(ctrls, C.int(qubit))
+		}
+	}
+	if len(ctrls) == 0 {
+		C.applyCompMatr1(s.qureg, C.int(target), matrix)
+		return
+	}
+	C.applyMultiControlledCompMatr1(s.qureg, &ctrls[0], C.int(len(ctrls)), C.int(target), matrix)
+}
+
+func (s *questcState) permute(f func(uint64) uint64, phase func(uint64) complex128) {
+	// Произвольная перестановка не выражается вентилями QuEST и выполняется
+	// над копией вектора
+	d := &denseState{n: s.n, state: s.vector()}
+	d.permute(f, phase)
+	C.setQuregAmps(s.qureg, 0, (*C.qcomp)(unsafe.Pointer(&d.state[0])), C.qindex(len(d.state)))
+}
+
+func (s *questcState) probabilityOne(qubit int) float64 {
+	return float64(C.calcProbOfQubitOutcome(s.qureg, C.int(qubit), 1))
+}
+
+func (s *questcState) scale(qubit int, s0, s1 float64) {
+	// Неунитарные операторы не применяются к вектору состояния QuEST и
+	// выполняются над копией вектора
+	d := &denseState{n: s.n, state: s.vector()}
+	d.scale(qubit, s0, s1)
+	C.setQuregAmps(s.qureg, 0, (*C.qcomp)(unsafe.Pointer(&d.state[0])), C.qindex(len(d.state)))
+}
+
+func (s *questcState) measure(qubit int, random *DeterministicRNG) int {
+	// Исход выбирается так же, как в denseState, а коллапс и нормализацию
+	// выполняет библиотека
+	prob1 := float64(C.calcProbOfQubitOutcome(s.qureg, C.int(qubit), 1))
+	result := 0
+	if random.Float64() < prob1 {
+		result = 1
+	}
+	C.applyForcedQubitMeasurement(s.qureg, C.int(qubit), C.int(result))
+	return result
+}
+
+func (s *questcState) measureAll(random *DeterministicRNG) uint64 {
+	// Выбор базисного состояния требует обхода всех амплитуд в порядке
+	// индексов, поэтому он выполняется над копией вектора
+	result := (&denseState{n: s.n, state: s.vector()}).measureAll(random)
+	C.initClassicalState(s.qureg, C.qindex(result))
+	return result
+}
+
+func (s *questcState) amplitude(basisState uint64) complex128 {
+	return complex128(C.getQuregAmp(s.qureg, C.qindex(basisState)))
+}
+
+func (s *questcState) vector() []complex128 {
+	state := make([]complex128, 1<<s.n)
+	C.getQuregAmps((*C.qcomp)(unsafe.Pointer(&state[0])), s.qureg, 0, C.qindex(len(state)))
+	return state
+}
+
+// encode сериализует регистр в том же формате, что и denseState
+func (s *questcState) encode() []byte {
+	return (&denseState{n: s.n, state: s.vector()}).encode()
+}
@@ -0,0 +1,85 @@
+//go:build questc && cgo
+
+package quantum
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// applyBackendStep применяет шаг схемы непосредственно к бэкенду
+func applyBackendStep(b amplitudeBackend, step cliffordStep) {
+	switch step.gate {
+	case "h":
+		b.hadamard(step.a)
+	case "x":
+		b.pauliX(step.a)
+	case "y":
+		b.pauliY(step.a)
+	case "z":
+		b.pauliZ(step.a)
+	case "cnot":
+		b.cnot(step.a, step.b)
+	case "swap":
+		b.swap(step.a, step.b)
+	case "t":
+		b.phaseShift(step.a, math.Pi/4)
+	default:
+		panic("unknown gate " + step.gate)
+	}
+}
+
+// requireIdentical проверяет, что векторы состояния двух бэкендов совпадают
+// побитово. Результат выполнения блока зависит от амплитуд, поэтому узлы с
+// QuEST и без него должны получать одинаковые значения, а не близкие.
+func requireIdentical(t *testing.T, questc, dense vectorBackend, format string, args ...any) {
+	t.Helper()
+
+	have, want := questc.vector(), dense.vector()
+	for i := range want {
+		if math.Float64bits(real(have[i])) != math.Float64bits(real(want[i])) ||
+			math.Float64bits(imag(have[i])) != math.Float64bits(imag(want[i])) {
+			t.Fatalf(format+": amplitude %d mismatch: have %v, want %v", append(args, i, have[i], want[i])...)
+		}
+	}
+	if !bytes.Equal(questc.encode(), dense.encode()) {
+		t.Fatalf(format+": encoding mismatch", args...)
+	}
+}
+
+// Проверяет, что регистр QuEST совпадает с плотным вектором на Go побитово
+// по амплитудам и сериализации, а также по результатам измерений
+func TestQuESTConformance(t *testing.T) {
+	const qubits = 6
+	names := []string{"h", "x", "y", "z", "cnot", "swap", "t"}
+	for seed := byte(1); seed <= 8; seed++ {
+		questc := newDenseBackend(qubits, nil)
+		dense := newDenseState(qubits)
+		for i, step := range randomCircuit(seed, qubits, 200, names) {
+			applyBackendStep(questc, step)
+			applyBackendStep(dense, step)
+
+			if i%50 == 49 {
+				requireIdentical(t, questc, dense, "seed %d step %d", seed, i)
+
+				qrand := NewDeterministicRNG(common.Hash{seed, byte(i)})
+				drand := NewDeterministicRNG(common.Hash{seed, byte(i)})
+				qubit := step.a
+				if have, want := questc.measure(qubit, qrand), dense.measure(qubit, drand); have != want {
+					t.Fatalf("seed %d step %d: measurement mismatch: have %d, want %d", seed, i, have, want)
+				}
+			}
+		}
+		requireIdentical(t, questc, dense, "seed %d", seed)
+
+		random := NewDeterministicRNG(common.Hash{seed})
+		if have, want := questc.measureAll(random), dense.measureAll(NewDeterministicRNG(common.Hash{seed})); have != want {
+			t.Fatalf("seed %d: measureAll mismatch: have %d, want %d", seed, have, want)
+		}
+		requireIdentical(t, questc, dense, "seed %d after collapse", seed)
+		questc.(interface{ release() }).release()
+	}
+}
@@ -0,0 +1,272 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+)
+
+// sparseState - разреженный вектор состояния, хранящий только ненулевые
+// амплитуды. Порядок обхода отображения в Go случаен, поэтому все
+// вычисления, результат которых зависит от порядка суммирования,
+// выполняются по отсортированным индексам.
+type sparseState struct {
+	n   int
+	amp map[uint64]complex128
+}
+
+// newSparseState создает разреженный регистр в состоянии |0...0⟩
+func newSparseState(numQubits int) *sparseState {
+	return &sparseState{n: numQubits, amp: map[uint64]complex128{0: 1}}
+}
+
+// sparseWords возвращает размер разреженного регистра из entries амплитуд в
+// 32-байтовых словах
+func sparseWords(entries int) uint64 {
+	return byteWords(uint64(max(entries, 1)) * sparseEntrySize)
+}
+
+func (s *sparseState) kind() Backend { return BackendSparse }
+func (s *sparseState) words() uint64 { return sparseWords(len(s.amp)) }
+func (s *sparseState) entries() int  { return len(s.amp) }
+
+func (s *sparseState) reset() {
+	s.amp = map[uint64]complex128{0: 1}
+}
+
+// keys возвращает индексы хранимых амплитуд по возрастанию
+func (s *sparseState) keys() []uint64 {
+	keys := make([]uint64, 0, len(s.amp))
+	for k := range s.amp {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// set сохраняет амплитуду, удаляя пренебрежимо малые
+func (s *sparseState) set(index uint64, amp complex128) {
+	if probability(amp) < sparsePruneThreshold {
+		delete(s.amp, index)
+		return
+	}
+	s.amp[index] = amp
+}
+
+func (s *sparseState) hadamard(qubit int) {
+	bit := uint64(1) << qubit
+	out := &sparseState{n: s.n, amp: make(map[uint64]complex128, 2*len(s.amp))}
+	for index := range s.amp {
+		// Каждая пара |x0⟩, |x1⟩ обрабатывается один раз, со стороны
+		// нулевого бита. Формулы совпадают с плотным представлением.
+		base := index &^ bit
+		if index&bit != 0 {
+			if _, ok := s.amp[base]; ok {
+				continue
+			}
+		}
+		a0, a1 := s.amp[base], s.amp[base|bit]
+		out.set(base, cmul(a0, hadamardGate[0][0])+cmul(a1, hadamardGate[1][0]))
+		out.set(base|bit, cmul(a0, hadamardGate[0][1])+cmul(a1, hadamardGate[1][1]))
+	}
+	s.amp = out.amp
+}
+
+func (s *sparseState) unitary(controls uint64, target int, m [2][2]complex128) {
+	bit := uint64(1) << target
+	out := &sparseState{n: s.n, amp: make(map[uint64]complex128, 2*len(s.amp))}
+	for index, amp := range s.amp {
+		if index&controls != controls {
+			out.amp[index] = amp
+			continue
+		}
+		// Пары обрабатываются так же, как в hadamard
+		base := index &^ bit
+		if index&bit != 0 {
+			if _, ok := s.amp[base]; ok {
+				continue
+			}
+		}
+		a0, a1 := s.amp[base], s.amp[base|bit]
+		out.set(base, cmul(m[0][0], a0)+cmul(m[0][1], a1))
+		out.set(base|bit, cmul(m[1][0], a0)+cmul(m[1][1], a1))
+	}
+	s.amp = out.amp
+}
+
+// permute переставляет базисные состояния по отображению f, умножая
+// амплитуды на phase(index)
+func (s *sparseState) permute(f func(uint64) uint64, phase func(uint64) complex128) {
+	out := make(map[uint64]complex128, len(s.amp))
+	for index, amp := range s.amp {
+		if phase != nil {
+			amp = cmul(amp, phase(index))
+		}
+		out[f(index)] = amp
+	}
+	s.amp = out
+}
+
+func (s *sparseState) pauliX(qubit int) {
+	bit := uint64(1) << qubit
+	s.permute(func(i uint64) uint64 { return i ^ bit }, nil)
+}
+
+func (s *sparseState) pauliY(qubit int) {
+	bit := uint64(1) << qubit
+	s.permute(func(i uint64) uint64 { return i ^ bit }, func(i uint64) complex128 {
+		if i&bit == 0 {
+			return pauliYGate[1][0]
+		}
+		return pauliYGate[0][1]
+	})
+}
+
+func (s *sparseState) pauliZ(qubit int) {
+	bit := uint64(1) << qubit
+	for index, amp := range s.amp {
+		if index&bit != 0 {
+			s.amp[index] = -amp
+		}
+	}
+}
+
+func (s *sparseState) cnot(control, target int) {
+	cbit, tbit := uint64(1)<<control, uint64(1)<<target
+	s.permute(func(i uint64) uint64 {
+		if i&cbit != 0 {
+			return i ^ tbit
+		}
+		return i
+	}, nil)
+}
+
+func (s *sparseState) swap(qubit1, qubit2 int) {
+	bit1, bit2 := uint64(1)<<qubit1, uint64(1)<<qubit2
+	s.permute(func(i uint64) uint64 {
+		if (i&bit1 == 0) != (i&bit2 == 0) {
+			return i ^ bit1 ^ bit2
+		}
+		return i
+	}, nil)
+}
+
+func (s *sparseState) phaseShift(qubit int, theta float64) {
+	bit := uint64(1) << qubit
+	phase := cis(theta)
+	for index, amp := range s.amp {
+		if index&bit != 0 {
+			s.amp[index] = cmul(amp, phase)
+		}
+	}
+}
+
+func (s *sparseState) probabilityOne(qubit int) float64 {
+	bit := uint64(1) << qubit
+	prob1 := 0.0
+	for _, k := range s.keys() {
+		if k&bit != 0 {
+			prob1 += probability(s.amp[k])
+		}
+	}
+	return prob1
+}
+
+func (s *sparseState) scale(qubit int, s0, s1 float64) {
+	bit := uint64(1) << qubit
+	for _, k := range s.keys() {
+		if k&bit != 0 {
+			s.set(k, cscale(s.amp[k], s1))
+		} else {
+			s.set(k, cscale(s.amp[k], s0))
+		}
+	}
+}
+
+func (s *sparseState) measure(qubit int, random *DeterministicRNG) int {
+	bit := uint64(1) << qubit
+	keys := s.keys()
+
+	// Вычисляем вероятность измерения |1⟩
+	prob1 := 0.0
+	for _, k := range keys {
+		if k&bit != 0 {
+			prob1 += probability(s.amp[k])
+		}
+	}
+	result := 0
+	if random.Float64() < prob1 {
+		result = 1
+	}
+
+	// Коллапсируем и нормализуем состояние
+	norm := 0.0
+	for _, k := range keys {
+		if (k&bit != 0) != (result == 1) {
+			delete(s.amp, k)
+		} else {
+			norm += probability(s.amp[k])
+		}
+	}
+	norm = math.Sqrt(norm)
+	if norm > 0 {
+		for k, amp := range s.amp {
+			s.amp[k] = cdiv(amp, norm)
+		}
+	}
+	return result
+}
+
+func (s *sparseState) measureAll(random *DeterministicRNG) uint64 {
+	keys := s.keys()
+	r := random.Float64()
+	cumulative := 0.0
+
+	// При ошибке округления выбирается последнее состояние носителя
+	result := keys[len(keys)-1]
+	for _, k := range keys {
+		cumulative += probability(s.amp[k])
+		if r < cumulative {
+			result = k
+			break
+		}
+	}
+	s.amp = map[uint64]complex128{result: 1}
+	return result
+}
+
+func (s *sparseState) amplitude(basisState uint64) complex128 {
+	return s.amp[basisState]
+}
+
+// toVector возвращает плотный вектор состояния регистра
+func (s *sparseState) toVector() []complex128 {
+	state := make([]complex128, 1<<s.n)
+	for index, amp := range s.amp {
+		state[index] = amp
+	}
+	return state
+}
+
+// toDense переводит регистр в плотное представление
+func (s *sparseState) toDense() vectorBackend {
+	return newDenseBackend(s.n, s.toVector())
+}
+
+// encode сериализует разреженный регистр (версия registerVersionSparse):
+// количество амплитуд и пары (индекс, амплитуда) по возрастанию индекса
+func (s *sparseState) encode() []byte {
+	keys := s.keys()
+	out := make([]byte, registerHeaderSize+4+len(keys)*sparseEntrySize)
+	out[0] = registerVersionSparse
+	out[1] = byte(s.n)
+	binary.BigEndian.PutUint32(out[registerHeaderSize:], uint32(len(keys)))
+
+	buf := out[registerHeaderSize+4:]
+	for i, k := range keys {
+		binary.BigEndian.PutUint64(buf[i*sparseEntrySize:], k)
+		putAmplitude(buf[i*sparseEntrySize+8:], s.amp[k])
+	}
+	return out
+}
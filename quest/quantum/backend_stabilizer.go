@@ -0,0 +1,368 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"math"
+	"math/bits"
+)
+
+// stabilizerState - таблица стабилизаторов в формате CHP (Aaronson, Gottesman,
+// "Improved simulation of stabilizer circuits", 2004). Строки 0..n-1 содержат
+// дестабилизаторы, строки n..2n-1 - стабилизаторы состояния, строка 2n
+// используется как рабочая при детерминированных измерениях. Строка задает
+// оператор Паули (-1)^r * P_0 ⊗ ... ⊗ P_{n-1}, где пара битов (x_j, z_j)
+// кодирует I, X, Z или Y. Память и время вентилей растут как O(n^2) и O(n)
+// соответственно, но поддерживаются только клиффордовы вентили.
+type stabilizerState struct {
+	n int
+	x [][]uint64
+	z [][]uint64
+	r []uint8
+}
+
+// newStabilizerState создает стабилизаторный регистр в состоянии |0...0⟩
+func newStabilizerState(numQubits int) *stabilizerState {
+	s := &stabilizerState{n: numQubits}
+	rowWords := (numQubits + 63) / 64
+	rows := 2*numQubits + 1
+	s.x = make([][]uint64, rows)
+	s.z = make([][]uint64, rows)
+	s.r = make([]uint8, rows)
+	for i := 0; i < rows; i++ {
+		s.x[i] = make([]uint64, rowWords)
+		s.z[i] = make([]uint64, rowWords)
+	}
+	s.reset()
+	return s
+}
+
+// stabilizerWords возвращает размер стабилизаторного регистра в 32-байтовых
+// словах: 2n строк по 2n бит и бит знака
+func stabilizerWords(numQubits int) uint64 {
+	return byteWords(uint64(2*numQubits) * uint64(stabilizerRowSize(numQubits)))
+}
+
+// stabilizerRowSize возвращает размер строки таблицы в кодировке регистра
+func stabilizerRowSize(numQubits int) int {
+	return 2*((numQubits+7)/8) + 1
+}
+
+func (s *stabilizerState) kind() Backend { return BackendStabilizer }
+func (s *stabilizerState) words() uint64 { return stabilizerWords(s.n) }
+
+func (s *stabilizerState) reset() {
+	for i := range s.x {
+		clear(s.x[i])
+		clear(s.z[i])
+		s.r[i] = 0
+	}
+	// Дестабилизаторы X_j и стабилизаторы Z_j состояния |0...0⟩
+	for j := 0; j < s.n; j++ {
+		setBit(s.x[j], j, 1)
+		setBit(s.z[s.n+j], j, 1)
+	}
+}
+
+// getBit возвращает бит j строки
+func getBit(row []uint64, j int) uint64 {
+	return row[j>>6] >> (j & 63) & 1
+}
+
+// setBit устанавливает бит j строки
+func setBit(row []uint64, j int, v uint64) {
+	row[j>>6] = row[j>>6]&^(1<<(j&63)) | v<<(j&63)
+}
+
+func (s *stabilizerState) hadamard(qubit int) {
+	for i := 0; i < 2*s.n; i++ {
+		xb, zb := getBit(s.x[i], qubit), getBit(s.z[i], qubit)
+		s.r[i] ^= uint8(xb & zb)
+		setBit(s.x[i], qubit, zb)
+		setBit(s.z[i], qubit, xb)
+	}
+}
+
+// phase применяет вентиль S = diag(1, i)
+func (s *stabilizerState) phase(qubit int) {
+	for i := 0; i < 2*s.n; i++ {
+		xb, zb := getBit(s.x[i], qubit), getBit(s.z[i], qubit)
+		s.r[i] ^= uint8(xb & zb)
+		setBit(s.z[i], qubit, zb^xb)
+	}
+}
+
+// Вентили Паули меняют только знаки строк, антикоммутирующих с ними
+func (s *stabilizerState) pauliX(qubit int) {
+	for i := 0; i < 2*s.n; i++ {
+		s.r[i] ^= uint8(getBit(s.z[i], qubit))
+	}
+}
+
+func (s *stabilizerState) pauliY(qubit int) {
+	for i := 0; i < 2*s.n; i++ {
+		s.r[i] ^= uint8(getBit(s.x[i], qubit) ^ getBit(s.z[i], qubit))
+	}
+}
+
+func (s *stabilizerState) pauliZ(qubit int) {
+	for i := 0; i < 2*s.n; i++ {
+		s.r[i] ^= uint8(getBit(s.x[i], qubit))
+	}
+}
+
+func (s *stabilizerState) cnot(control, target int) {
+	for i := 0; i < 2*s.n; i++ {
+		xa, za := getBit(s.x[i], control), getBit(s.z[i], control)
+		xb, zb := getBit(s.x[i], target), getBit(s.z[i], target)
+		s.r[i] ^= uint8(xa & zb & (xb ^ za ^ 1))
+		setBit(s.x[i], target, xb^xa)
+		setBit(s.z[i], control, za^zb)
+	}
+}
+
+func (s *stabilizerState) swap(qubit1, qubit2 int) {
+	for i := 0; i < 2*s.n; i++ {
+		x1, x2 := getBit(s.x[i], qubit1), getBit(s.x[i], qubit2)
+		z1, z2 := getBit(s.z[i], qubit1), getBit(s.z[i], qubit2)
+		setBit(s.x[i], qubit1, x2)
+		setBit(s.x[i], qubit2, x1)
+		setBit(s.z[i], qubit1, z2)
+		setBit(s.z[i], qubit2, z1)
+	}
+}
+
+// pauliPhase возвращает показатель степени i, возникающий при умножении
+// однокубитных операторов Паули (x1, z1) и (x2, z2)
+func pauliPhase(x1, z1, x2, z2 uint64) int {
+	switch {
+	case x1 == 0 && z1 == 0:
+		return 0
+	case x1 == 1 && z1 == 1:
+		return int(z2) - int(x2)
+	case x1 == 1:
+		return int(z2) * (2*int(x2) - 1)
+	default:
+		return int(x2) * (1 - 2*int(z2))
+	}
+}
+
+// rowsum заменяет строку h произведением строк i и h
+func (s *stabilizerState) rowsum(h, i int) {
+	sum := 2*int(s.r[h]) + 2*int(s.r[i])
+	for j := 0; j < s.n; j++ {
+		sum += pauliPhase(getBit(s.x[i], j), getBit(s.z[i], j), getBit(s.x[h], j), getBit(s.z[h], j))
+	}
+	if sum%4 == 0 {
+		s.r[h] = 0
+	} else {
+		s.r[h] = 1
+	}
+	for w := range s.x[h] {
+		s.x[h][w] ^= s.x[i][w]
+		s.z[h][w] ^= s.z[i][w]
+	}
+}
+
+// copyRow копирует строку src в dst
+func (s *stabilizerState) copyRow(dst, src int) {
+	copy(s.x[dst], s.x[src])
+	copy(s.z[dst], s.z[src])
+	s.r[dst] = s.r[src]
+}
+
+// measure измеряет кубит. Случайное число запрашивается, только если
+// результат не определен состоянием, вероятности исходов тогда равны 1/2.
+func (s *stabilizerState) measure(qubit int, random *DeterministicRNG) int {
+	p := -1
+	for i := s.n; i < 2*s.n; i++ {
+		if getBit(s.x[i], qubit) == 1 {
+			p = i
+			break
+		}
+	}
+	if p >= 0 {
+		// Случайный результат: стабилизатор p антикоммутирует с Z_qubit
+		for i := 0; i < 2*s.n; i++ {
+			if i != p && getBit(s.x[i], qubit) == 1 {
+				s.rowsum(i, p)
+			}
+		}
+		s.copyRow(p-s.n, p)
+		clear(s.x[p])
+		clear(s.z[p])
+		setBit(s.z[p], qubit, 1)
+
+		result := 0
+		if random.Float64() < 0.5 {
+			result = 1
+		}
+		s.r[p] = uint8(result)
+		return result
+	}
+	// Детерминированный результат вычисляется в рабочей строке
+	scratch := 2 * s.n
+	clear(s.x[scratch])
+	clear(s.z[scratch])
+	s.r[scratch] = 0
+	for i := 0; i < s.n; i++ {
+		if getBit(s.x[i], qubit) == 1 {
+			s.rowsum(scratch, i+s.n)
+		}
+	}
+	return int(s.r[scratch])
+}
+
+// measureAll измеряет кубиты по порядку. Результаты кубитов старше 63
+// коллапсируют состояние, но не входят в возвращаемое значение.
+func (s *stabilizerState) measureAll(random *DeterministicRNG) uint64 {
+	var result uint64
+	for j := 0; j < s.n; j++ {
+		if s.measure(j, random) == 1 && j < 64 {
+			result |= 1 << j
+		}
+	}
+	return result
+}
+
+// pauli - оператор Паули не более чем на 64 кубитах
+type pauli struct {
+	x, z uint64
+	r    uint8
+}
+
+// stabilizers возвращает стабилизаторы состояния, приведенные к виду, в
+// котором первые k операторов имеют линейно независимые X-части с различными
+// ведущими кубитами, а остальные состоят только из Z. Требует n <= 64.
+func (s *stabilizerState) stabilizers() ([]pauli, int) {
+	gens := make([]pauli, s.n)
+	for i := range gens {
+		gens[i] = pauli{x: s.x[s.n+i][0], z: s.z[s.n+i][0], r: s.r[s.n+i]}
+	}
+	k := 0
+	for col := 0; col < s.n && k < s.n; col++ {
+		bit := uint64(1) << col
+		p := -1
+		for i := k; i < s.n; i++ {
+			if gens[i].x&bit != 0 {
+				p = i
+				break
+			}
+		}
+		if p < 0 {
+			continue
+		}
+		gens[k], gens[p] = gens[p], gens[k]
+		for i := range gens {
+			if i != k && gens[i].x&bit != 0 {
+				gens[i] = multiplyPauli(gens[k], gens[i], s.n)
+			}
+		}
+		k++
+	}
+	return gens, k
+}
+
+// multiplyPauli возвращает произведение операторов a и b
+func multiplyPauli(a, b pauli, n int) pauli {
+	sum := 2*int(a.r) + 2*int(b.r)
+	for j := 0; j < n; j++ {
+		sum += pauliPhase(a.x>>j&1, a.z>>j&1, b.x>>j&1, b.z>>j&1)
+	}
+	out := pauli{x: a.x ^ b.x, z: a.z ^ b.z}
+	if sum%4 != 0 {
+		out.r = 1
+	}
+	return out
+}
+
+// apply применяет оператор Паули к базисному состоянию и возвращает новое
+// состояние и множитель амплитуды. Y = iXZ, поэтому Z-часть действует на
+// исходное состояние.
+func (p pauli) apply(index uint64) (uint64, complex128) {
+	exp := 2*int(p.r) + bits.OnesCount64(p.x&p.z) + 2*bits.OnesCount64(index&p.z)
+	return index ^ p.x, [4]complex128{1, 1i, -1, -1i}[exp%4]
+}
+
+// supportBits возвращает log2 количества базисных состояний с ненулевой
+// амплитудой. Требует n <= 64.
+func (s *stabilizerState) supportBits() int {
+	_, k := s.stabilizers()
+	return k
+}
+
+// forEachAmplitude вызывает fn для каждой ненулевой амплитуды. Стабилизаторное
+// состояние пропорционально произведению (I + g) по X-стабилизаторам,
+// примененному к базисному состоянию b, удовлетворяющему Z-стабилизаторам.
+// Носитель - смежный класс b по линейной оболочке X-частей, а все амплитуды
+// равны по модулю. Требует n <= 64.
+func (s *stabilizerState) forEachAmplitude(fn func(index uint64, amp complex128)) {
+	gens, k := s.stabilizers()
+
+	// Z-стабилизаторы задают систему z·b = r над GF(2), решаемую методом
+	// Гаусса со свободными переменными, равными нулю
+	zrows := gens[k:]
+	pivots := make([]int, 0, len(zrows))
+	for col := 0; col < s.n && len(pivots) < len(zrows); col++ {
+		row := len(pivots)
+		bit := uint64(1) << col
+		p := -1
+		for i := row; i < len(zrows); i++ {
+			if zrows[i].z&bit != 0 {
+				p = i
+				break
+			}
+		}
+		if p < 0 {
+			continue
+		}
+		zrows[row], zrows[p] = zrows[p], zrows[row]
+		for i := range zrows {
+			if i != row && zrows[i].z&bit != 0 {
+				zrows[i].z ^= zrows[row].z
+				zrows[i].r ^= zrows[row].r
+			}
+		}
+		pivots = append(pivots, col)
+	}
+	// После приведения к ступенчатому виду каждая строка содержит
+	// единственный ведущий кубит
+	var b uint64
+	for row, col := range pivots {
+		if zrows[row].r == 1 {
+			b |= 1 << col
+		}
+	}
+	// Обход носителя кодом Грея: соседние слагаемые отличаются одним
+	// стабилизатором, а стабилизаторы коммутируют и g*g = I
+	norm := 1 / math.Sqrt(math.Ldexp(1, k))
+	index, amp := b, complex128(1)
+	fn(index, cscale(amp, norm))
+	for step := uint64(1); step < 1<<k; step++ {
+		var phase complex128
+		index, phase = gens[bits.TrailingZeros64(step)].apply(index)
+		amp = cmul(amp, phase)
+		fn(index, cscale(amp, norm))
+	}
+}
+
+// encode сериализует таблицу стабилизаторов (версия
+// registerVersionStabilizer): 2n строк, каждая из X-битов, Z-битов (бит j в
+// байте j/8, младшие биты первыми) и байта знака
+func (s *stabilizerState) encode() []byte {
+	rowSize := stabilizerRowSize(s.n)
+	bitBytes := (s.n + 7) / 8
+	out := make([]byte, registerHeaderSize+2*s.n*rowSize)
+	out[0] = registerVersionStabilizer
+	out[1] = byte(s.n)
+
+	buf := out[registerHeaderSize:]
+	for i := 0; i < 2*s.n; i++ {
+		row := buf[i*rowSize:]
+		for j := 0; j < s.n; j++ {
+			row[j/8] |= byte(getBit(s.x[i], j)) << (j % 8)
+			row[bitBytes+j/8] |= byte(getBit(s.z[i], j)) << (j % 8)
+		}
+		row[2*bitBytes] = s.r[i]
+	}
+	return out
+}
@@ -0,0 +1,273 @@
+package quantum
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// cliffordStep - шаг тестовой клиффордовой схемы
+type cliffordStep struct {
+	gate string
+	a, b int
+}
+
+// applyStep применяет шаг схемы к окружению
+func applyStep(env *QuestEnv, step cliffordStep) error {
+	switch step.gate {
+	case "h":
+		return env.ApplyHadamard(step.a)
+	case "x":
+		return env.ApplyPauliX(step.a)
+	case "y":
+		return env.ApplyPauliY(step.a)
+	case "z":
+		return env.ApplyPauliZ(step.a)
+	case "s":
+		return env.ApplyPhaseShift(step.a, math.Pi/2)
+	case "cnot":
+		return env.ApplyCNOT(step.a, step.b)
+	case "swap":
+		return env.ApplySwap(step.a, step.b)
+	case "t":
+		return env.ApplyPhaseShift(step.a, math.Pi/4)
+	}
+	panic("unknown gate " + step.gate)
+}
+
+// randomCircuit возвращает детерминированную схему из gates вентилей
+func randomCircuit(seed byte, qubits, gates int, names []string) []cliffordStep {
+	random := NewDeterministicRNG(common.Hash{seed})
+	circuit := make([]cliffordStep, gates)
+	for i := range circuit {
+		name := names[random.Uint64()%uint64(len(names))]
+		a := int(random.Uint64() % uint64(qubits))
+		b := (a + 1 + int(random.Uint64()%uint64(qubits-1))) % qubits
+		circuit[i] = cliffordStep{name, a, b}
+	}
+	return circuit
+}
+
+// assertSameState проверяет совпадение векторов состояния с точностью до
+// глобальной фазы
+func assertSameState(t *testing.T, name string, have, want []complex128) {
+	t.Helper()
+	if len(have) != len(want) {
+		t.Fatalf("%s: vector length mismatch: have %d, want %d", name, len(have), len(want))
+	}
+	var overlap complex128
+	for i := range want {
+		overlap += cmplx.Conj(want[i]) * have[i]
+	}
+	if math.Abs(cmplx.Abs(overlap)-1) > 1e-9 {
+		t.Fatalf("%s: states differ, overlap %v", name, overlap)
+	}
+}
+
+// Проверяет, что все бэкенды дают одинаковое состояние для клиффордовых схем
+func TestBackendsCliffordEquivalence(t *testing.T) {
+	gates := []string{"h", "x", "y", "z", "s", "cnot", "swap"}
+	for seed := byte(0); seed < 20; seed++ {
+		circuit := randomCircuit(seed, 5, 60, gates)
+
+		var states [][]complex128
+		for _, backend := range []Backend{BackendDense, BackendSparse, BackendStabilizer, BackendAuto} {
+			env, err := NewQuestEnvWithBackend(5, backend, false, 0, common.Hash{})
+			if err != nil {
+				t.Fatalf("failed to create %v env: %v", backend, err)
+			}
+			for _, step := range circuit {
+				if err := applyStep(env, step); err != nil {
+					t.Fatalf("%v: %s failed: %v", backend, step.gate, err)
+				}
+			}
+			if backend == BackendStabilizer || backend == BackendAuto {
+				if have := env.Backend(); have != BackendStabilizer {
+					t.Fatalf("%v: clifford circuit left stabilizer representation: %v", backend, have)
+				}
+			}
+			states = append(states, env.GetStateVector())
+		}
+		for i, backend := range []Backend{BackendSparse, BackendStabilizer, BackendAuto} {
+			assertSameState(t, backend.String(), states[i+1], states[0])
+		}
+	}
+}
+
+// Проверяет переходы между представлениями в режиме BackendAuto
+func TestBackendAutoTransitions(t *testing.T) {
+	circuit := randomCircuit(1, 4, 40, []string{"h", "cnot", "t", "s", "swap"})
+
+	dense, _ := NewQuestEnvWithBackend(4, BackendDense, false, 0, common.Hash{})
+	auto, _ := NewQuestEnvWithBackend(4, BackendAuto, false, 0, common.Hash{})
+	for _, step := range circuit {
+		applyStep(dense, step)
+		applyStep(auto, step)
+	}
+	if have := auto.Backend(); have == BackendStabilizer {
+		t.Fatal("non-clifford gate kept stabilizer representation")
+	}
+	assertSameState(t, "auto", auto.GetStateVector(), dense.GetStateVector())
+
+	// Равномерная суперпозиция плотнее разреженного представления
+	for q := 0; q < 4; q++ {
+		auto.ApplyHadamard(q)
+	}
+	auto.ApplyPhaseShift(0, 0.3)
+	for q := 0; q < 4; q++ {
+		auto.ApplyHadamard(q)
+		auto.ApplyPhaseShift(q, 0.7)
+	}
+	if have := auto.Backend(); have != BackendDense {
+		t.Fatalf("dense superposition not compacted: %v", have)
+	}
+	// Сброс возвращает регистр к таблице стабилизаторов
+	if err := auto.Reset(); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+	if have := auto.Backend(); have != BackendStabilizer {
+		t.Fatalf("reset register not stabilizer: %v", have)
+	}
+}
+
+// Проверяет отказ стабилизаторного бэкенда от неклиффордовых вентилей
+func TestStabilizerRejectsNonClifford(t *testing.T) {
+	env, err := NewQuestEnvWithBackend(3, BackendStabilizer, false, 0, common.Hash{})
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+	if err := env.ApplyPhaseShift(0, math.Pi/4); !errors.Is(err, ErrNotClifford) {
+		t.Fatalf("want %v, have %v", ErrNotClifford, err)
+	}
+	if err := env.ApplyPhaseShift(0, -math.Pi/2); err != nil {
+		t.Fatalf("clifford phase rejected: %v", err)
+	}
+}
+
+// Проверяет регистр из сотен кубитов в стабилизаторном представлении
+func TestStabilizerLargeRegister(t *testing.T) {
+	const n = 200
+	if _, err := NewQuestEnvWithBackend(n, BackendDense, false, 0, common.Hash{}); !errors.Is(err, ErrBackendCapacity) {
+		t.Fatalf("dense register of %d qubits: want %v, have %v", n, ErrBackendCapacity, err)
+	}
+	for seed := byte(0); seed < 8; seed++ {
+		env, err := NewQuestEnvWithBackend(n, BackendAuto, false, 0, common.Hash{seed})
+		if err != nil {
+			t.Fatalf("failed to create env: %v", err)
+		}
+		if words, _ := RegisterWords(BackendAuto, n); env.StateWords() != words || words > 2000 {
+			t.Fatalf("unexpected register size %d words", env.StateWords())
+		}
+		// Состояние GHZ: все кубиты измеряются одинаково
+		env.ApplyHadamard(0)
+		for q := 1; q < n; q++ {
+			env.ApplyCNOT(q-1, q)
+		}
+		first, _ := env.MeasureQubit(n - 1)
+		for q := 0; q < n-1; q++ {
+			if bit, _ := env.MeasureQubit(q); bit != first {
+				t.Fatalf("seed %d: qubit %d measured %d, want %d", seed, q, bit, first)
+			}
+		}
+		all, _ := env.MeasureAllQubits()
+		if want := uint64(0); first == 1 {
+			want = math.MaxUint64
+			if all != want {
+				t.Fatalf("seed %d: measure all %x, want %x", seed, all, want)
+			}
+		} else if all != want {
+			t.Fatalf("seed %d: measure all %x, want %x", seed, all, want)
+		}
+	}
+}
+
+// Проверяет, что регистр контракта помещается в результат QMEASUREALL
+func TestMaxRegisterQubits(t *testing.T) {
+	if MaxRegisterQubits > MaxSparseQubits {
+		t.Fatalf("register limit %d exceeds sparse limit %d", MaxRegisterQubits, MaxSparseQubits)
+	}
+	if have := params.QuantumGasTableQuantum.MaxQubits; have > MaxRegisterQubits {
+		t.Fatalf("gas table allows %d qubits, want at most %d", have, MaxRegisterQubits)
+	}
+}
+
+// Проверяет воспроизводимость измерений для одинакового зерна
+func TestBackendMeasurementDeterminism(t *testing.T) {
+	circuit := randomCircuit(7, 6, 50, []string{"h", "cnot", "t", "x"})
+	run := func() []int {
+		env, _ := NewQuestEnvWithBackend(6, BackendAuto, false, 0, common.HexToHash("0x1234"))
+		for _, step := range circuit {
+			applyStep(env, step)
+		}
+		results := make([]int, 6)
+		for q := range results {
+			results[q], _ = env.MeasureQubit(q)
+		}
+		return results
+	}
+	first := run()
+	for i := 0; i < 5; i++ {
+		if have := run(); !equalInts(have, first) {
+			t.Fatalf("run %d: measurements %v, want %v", i, have, first)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Проверяет сериализацию разреженных и стабилизаторных регистров
+func TestRegisterEncodingBackends(t *testing.T) {
+	if _, err := NewQuestEnvWithBackend(MaxSparseQubits+1, BackendSparse, false, 0, common.Hash{}); !errors.Is(err, ErrBackendCapacity) {
+		t.Fatalf("oversized sparse register: want %v, have %v", ErrBackendCapacity, err)
+	}
+	for backend, qubits := range map[Backend]int{BackendSparse: 40, BackendStabilizer: 60} {
+		env, err := NewQuestEnvWithBackend(qubits, backend, false, 0, common.Hash{})
+		if err != nil {
+			t.Fatalf("failed to create %v env: %v", backend, err)
+		}
+		env.ApplyHadamard(3)
+		env.ApplyCNOT(3, 17)
+		env.ApplyPauliY(17)
+		env.ApplyHadamard(20)
+
+		enc := env.EncodeState()
+		dec, err := DecodeQuestEnv(enc, BackendAuto, common.Hash{})
+		if err != nil {
+			t.Fatalf("%v: failed to decode register: %v", backend, err)
+		}
+		if dec.Backend() != backend || dec.GetQubitCount() != env.GetQubitCount() {
+			t.Fatalf("%v: decoded as %v with %d qubits", backend, dec.Backend(), dec.GetQubitCount())
+		}
+		if !bytes.Equal(dec.EncodeState(), enc) {
+			t.Fatalf("%v: re-encoded register differs", backend)
+		}
+		for _, corrupt := range [][]byte{enc[:len(enc)-1], append(common.CopyBytes(enc), 0)} {
+			if _, err := DecodeQuestEnv(corrupt, BackendAuto, common.Hash{}); !errors.Is(err, ErrInvalidRegisterEncoding) {
+				t.Errorf("%v: expected ErrInvalidRegisterEncoding, got %v", backend, err)
+			}
+		}
+		// Амплитуды не зависят от представления
+		amp, err := dec.GetAmplitude(1<<3 | 1<<20)
+		if err != nil {
+			t.Fatalf("%v: failed to read amplitude: %v", backend, err)
+		}
+		if math.Abs(cmplx.Abs(amp)-0.5) > 1e-9 {
+			t.Fatalf("%v: amplitude %v, want modulus 0.5", backend, amp)
+		}
+	}
+}
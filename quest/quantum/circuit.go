@@ -0,0 +1,324 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrUnsupportedGate ошибка, возникающая при выполнении вентиля, который не
+// поддерживается окружением или QEVM
+var ErrUnsupportedGate = errors.New("неподдерживаемый квантовый вентиль")
+
+// Gate - операция квантовой схемы. Имена вентилей совпадают с
+// мнемониками стандартной библиотеки OpenQASM 3 (stdgates.inc).
+type Gate struct {
+	// Имя операции: вентиль, "measure" или "reset"
+	Name string
+
+	// Кубиты операции, для управляемых вентилей управляющие идут первыми
+	Qubits []int
+
+	// Углы в радианах для параметризованных вентилей
+	Params []float64
+
+	// Классический бит, в который записывается результат измерения
+	Clbit int
+}
+
+// Circuit - квантовая схема над одним регистром кубитов и одним регистром
+// классических битов
+type Circuit struct {
+	NumQubits int
+	NumClbits int
+	Gates     []Gate
+}
+
+// gateInfo описывает вентиль схемы
+type gateInfo struct {
+	qubits int // Количество кубитов
+	params int // Количество углов
+}
+
+// circuitGates - поддерживаемые вентили схемы. Вращения rx, ry и rz
+// выполняются с точностью до глобальной фазы. Вентиль cu(theta, phi, lambda,
+// gamma) - управляемый U3 с фазой gamma управляемой части.
+var circuitGates = map[string]gateInfo{
+	"h":       {qubits: 1},
+	"x":       {qubits: 1},
+	"y":       {qubits: 1},
+	"z":       {qubits: 1},
+	"s":       {qubits: 1},
+	"sdg":     {qubits: 1},
+	"t":       {qubits: 1},
+	"tdg":     {qubits: 1},
+	"p":       {qubits: 1, params: 1},
+	"rx":      {qubits: 1, params: 1},
+	"ry":      {qubits: 1, params: 1},
+	"rz":      {qubits: 1, params: 1},
+	"u3":      {qubits: 1, params: 3},
+	"cx":      {qubits: 2},
+	"cz":      {qubits: 2},
+	"cp":      {qubits: 2, params: 1},
+	"cu":      {qubits: 2, params: 4},
+	"swap":    {qubits: 2},
+	"ccx":     {qubits: 3},
+	"measure": {qubits: 1},
+}
+
+// fixedPhases - углы фазовых вентилей без параметров
+var fixedPhases = map[string]float64{
+	"s":   math.Pi / 2,
+	"sdg": -math.Pi / 2,
+	"t":   math.Pi / 4,
+	"tdg": -math.Pi / 4,
+}
+
+// Validate проверяет, что схема состоит из поддерживаемых операций над
+// существующими кубитами и битами
+func (c *Circuit) Validate() error {
+	if c.NumQubits <= 0 || c.NumQubits > MaxStabilizerQubits {
+		return fmt.Errorf("%w: %d кубитов", ErrMaxQubitsExceeded, c.NumQubits)
+	}
+	for i, g := range c.Gates {
+		if g.Name == "reset" {
+			if len(g.Qubits) != c.NumQubits {
+				return fmt.Errorf("%w: операция %d: сброс части регистра", ErrUnsupportedGate, i)
+			}
+		} else {
+			info, ok := circuitGates[g.Name]
+			if !ok {
+				return fmt.Errorf("%w: операция %d: %s", ErrUnsupportedGate, i, g.Name)
+			}
+			if len(g.Qubits) != info.qubits || len(g.Params) != info.params {
+				return fmt.Errorf("%w: операция %d: неверное число операндов %s", ErrUnsupportedGate, i, g.Name)
+			}
+			for _, theta := range g.Params {
+				if math.IsNaN(theta) || math.IsInf(theta, 0) {
+					return fmt.Errorf("%w: операция %d: угол %v", ErrInvalidInput, i, theta)
+				}
+			}
+		}
+		seen := make(map[int]bool, len(g.Qubits))
+		for _, qubit := range g.Qubits {
+			if qubit < 0 || qubit >= c.NumQubits {
+				return fmt.Errorf("%w: операция %d: кубит %d", ErrQubitOutOfRange, i, qubit)
+			}
+			if seen[qubit] {
+				return fmt.Errorf("%w: операция %d: повторный кубит %d", ErrInvalidControlTarget, i, qubit)
+			}
+			seen[qubit] = true
+		}
+		if g.Name == "measure" && (g.Clbit < 0 || g.Clbit >= c.NumClbits) {
+			return fmt.Errorf("%w: операция %d: классический бит %d", ErrQubitOutOfRange, i, g.Clbit)
+		}
+	}
+	return nil
+}
+
+// RunCircuit выполняет схему над окружением и возвращает значения
+// классических битов схемы, по одному байту 0 или 1 на бит. Окружение должно
+// содержать не меньше кубитов, чем схема.
+func (q *QuestEnv) RunCircuit(c *Circuit) ([]byte, error) {
+	return q.RunCircuitContext(context.Background(), c)
+}
+
+// RunCircuitContext выполняет схему как RunCircuit, проверяя перед каждой
+// операцией, не отменен ли ctx. Выполнение отмененной схемы прерывается с
+// ошибкой ctx, окружение остается в промежуточном состоянии.
+func (q *QuestEnv) RunCircuitContext(ctx context.Context, c *Circuit) ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if c.NumQubits > q.numQubits {
+		return nil, fmt.Errorf("%w: схеме нужно %d кубитов", ErrMaxQubitsExceeded, c.NumQubits)
+	}
+	clbits := make([]byte, c.NumClbits)
+	for _, g := range c.Gates {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		switch g.Name {
+		case "measure":
+			result, err := q.MeasureQubit(g.Qubits[0])
+			if err != nil {
+				return nil, err
+			}
+			clbits[g.Clbit] = byte(result)
+		case "reset":
+			if err := q.Reset(); err != nil {
+				return nil, err
+			}
+		default:
+			if err := q.applyGate(g); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return clbits, nil
+}
+
+// applyGate применяет вентиль схемы, раскладывая его на базовые вентили
+// окружения
+func (q *QuestEnv) applyGate(g Gate) error {
+	switch g.Name {
+	case "h":
+		return q.ApplyHadamard(g.Qubits[0])
+	case "x":
+		return q.ApplyPauliX(g.Qubits[0])
+	case "y":
+		return q.ApplyPauliY(g.Qubits[0])
+	case "z":
+		return q.ApplyPauliZ(g.Qubits[0])
+	case "s", "sdg", "t", "tdg":
+		return q.ApplyPhaseShift(g.Qubits[0], fixedPhases[g.Name])
+	case "p", "rz":
+		return q.ApplyPhaseShift(g.Qubits[0], g.Params[0])
+	case "rx":
+		return q.applyRotX(g.Qubits[0], g.Params[0])
+	case "ry":
+		return q.applyRotY(g.Qubits[0], g.Params[0])
+	case "cx":
+		return q.ApplyCNOT(g.Qubits[0], g.Qubits[1])
+	case "u3":
+		return q.ApplyU3(g.Qubits[0], g.Params[0], g.Params[1], g.Params[2])
+	case "cz":
+		return q.ApplyCZ(g.Qubits[0], g.Qubits[1])
+	case "cp":
+		return q.ApplyCPhase(g.Qubits[0], g.Qubits[1], g.Params[0])
+	case "cu":
+		// Фаза gamma управляемой части - фазовый сдвиг управляющего кубита
+		if gamma := g.Params[3]; gamma != 0 {
+			if err := q.ApplyPhaseShift(g.Qubits[0], gamma); err != nil {
+				return err
+			}
+		}
+		return q.ApplyControlledU3(g.Qubits[:1], g.Qubits[1], g.Params[0], g.Params[1], g.Params[2])
+	case "swap":
+		return q.ApplySwap(g.Qubits[0], g.Qubits[1])
+	case "ccx":
+		return q.applyToffoli(g.Qubits[0], g.Qubits[1], g.Qubits[2])
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedGate, g.Name)
+}
+
+// applyGates последовательно применяет вентили до первой ошибки
+func applyGates(gates ...func() error) error {
+	for _, gate := range gates {
+		if err := gate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyRotX применяет вращение вокруг оси X с точностью до глобальной фазы:
+// Rx(theta) = H P(theta) H
+func (q *QuestEnv) applyRotX(qubit int, theta float64) error {
+	return applyGates(
+		func() error { return q.ApplyHadamard(qubit) },
+		func() error { return q.ApplyPhaseShift(qubit, theta) },
+		func() error { return q.ApplyHadamard(qubit) },
+	)
+}
+
+// applyRotY применяет вращение вокруг оси Y с точностью до глобальной фазы:
+// Ry(theta) = S Rx(theta) S†
+func (q *QuestEnv) applyRotY(qubit int, theta float64) error {
+	return applyGates(
+		func() error { return q.ApplyPhaseShift(qubit, -math.Pi/2) },
+		func() error { return q.applyRotX(qubit, theta) },
+		func() error { return q.ApplyPhaseShift(qubit, math.Pi/2) },
+	)
+}
+
+// applyToffoli применяет вентиль Тоффоли, разложенный на 6 CNOT, 7 вентилей
+// T и T† и 2 вентиля Адамара
+func (q *QuestEnv) applyToffoli(control1, control2, target int) error {
+	if control1 == control2 || control1 == target || control2 == target {
+		return ErrInvalidControlTarget
+	}
+	t := func(qubit int, sign float64) func() error {
+		return func() error { return q.ApplyPhaseShift(qubit, sign*math.Pi/4) }
+	}
+	cnot := func(control, target int) func() error {
+		return func() error { return q.ApplyCNOT(control, target) }
+	}
+	hadamard := func() error { return q.ApplyHadamard(target) }
+	return applyGates(
+		hadamard,
+		cnot(control2, target), t(target, -1),
+		cnot(control1, target), t(target, 1),
+		cnot(control2, target), t(target, -1),
+		cnot(control1, target), t(control2, 1), t(target, 1),
+		hadamard,
+		cnot(control1, control2), t(control1, 1), t(control2, -1),
+		cnot(control1, control2),
+	)
+}
+
+// StartRecording очищает историю операций окружения и включает ее запись.
+// Записываются вентили, измерения и сбросы, в том числе выполняемые
+// инструкциями QEVM, в виде базовых вентилей окружения.
+func (q *QuestEnv) StartRecording() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.recording = true
+	q.history = nil
+}
+
+// StopRecording выключает запись истории, сохраняя записанные операции
+func (q *QuestEnv) StopRecording() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.recording = false
+}
+
+// Circuit возвращает записанную историю операций в виде схемы. Результат
+// измерения кубита i записывается в классический бит i.
+func (q *QuestEnv) Circuit() *Circuit {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	c := &Circuit{NumQubits: q.numQubits, Gates: make([]Gate, len(q.history))}
+	for i, g := range q.history {
+		c.Gates[i] = Gate{
+			Name:   g.Name,
+			Qubits: append([]int(nil), g.Qubits...),
+			Params: append([]float64(nil), g.Params...),
+			Clbit:  g.Clbit,
+		}
+		if g.Name == "measure" {
+			c.NumClbits = q.numQubits
+		}
+	}
+	return c
+}
+
+// record добавляет операцию в историю, если запись включена
+func (q *QuestEnv) record(name string, params []float64, qubits ...int) {
+	if !q.recording {
+		return
+	}
+	g := Gate{Name: name, Qubits: qubits, Params: params}
+	if name == "measure" {
+		g.Clbit = qubits[0]
+	}
+	q.history = append(q.history, g)
+}
+
+// recordReset добавляет в историю сброс всего регистра
+func (q *QuestEnv) recordReset() {
+	if !q.recording {
+		return
+	}
+	qubits := make([]int, q.numQubits)
+	for i := range qubits {
+		qubits[i] = i
+	}
+	q.record("reset", nil, qubits...)
+}
@@ -0,0 +1,154 @@
+// Package quantum обеспечивает интеграцию квантовых вычислений с EVM
+package quantum
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/program"
+	"github.com/holiman/uint256"
+)
+
+// Instruction - квантовая инструкция QEVM с операндами в порядке реестра
+// vm.QuantumOp (Args[0] находится на вершине стека)
+type Instruction struct {
+	Op   OpCode
+	Args []uint256.Int
+
+	// Классический бит схемы, в который записывается результат QMEASURE
+	Clbit int
+}
+
+// Compile транслирует схему в последовательность инструкций QEVM, которая
+// начинается с QINIT регистра схемы. Углы операндов QEVM задаются долями
+// оборота с 64-битной дробной частью, поэтому параметры вентилей
+// округляются до 2*pi/2^64. Углы вентилей s, sdg, t и tdg представляются
+// точно, и QPHASE выполняет их над стабилизаторным регистром.
+func (c *Circuit) Compile() ([]Instruction, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	prog := []Instruction{{Op: QINIT, Args: qevmArgs(uint64(c.NumQubits))}}
+	for _, g := range c.Gates {
+		qubit := uint64(g.Qubits[0])
+		switch g.Name {
+		case "h":
+			prog = append(prog, Instruction{Op: QHADAMARD, Args: qevmArgs(qubit)})
+		case "x":
+			prog = append(prog, Instruction{Op: QPAULIX, Args: qevmArgs(qubit)})
+		case "y":
+			prog = append(prog, Instruction{Op: QPAULIY, Args: qevmArgs(qubit)})
+		case "z":
+			prog = append(prog, Instruction{Op: QPAULIZ, Args: qevmArgs(qubit)})
+		case "s", "sdg", "t", "tdg":
+			prog = append(prog, Instruction{Op: QPHASE, Args: qevmArgs(angleOperand(fixedPhases[g.Name]), qubit)})
+		case "p", "rz":
+			prog = append(prog, Instruction{Op: QPHASE, Args: qevmArgs(angleOperand(g.Params[0]), qubit)})
+		case "rx":
+			prog = append(prog, Instruction{Op: QROTX, Args: qevmArgs(angleOperand(g.Params[0]), qubit)})
+		case "ry":
+			prog = append(prog, Instruction{Op: QROTY, Args: qevmArgs(angleOperand(g.Params[0]), qubit)})
+		case "cx":
+			prog = append(prog, Instruction{Op: QCNOT, Args: qevmArgs(uint64(g.Qubits[1]), qubit)})
+		case "u3":
+			prog = append(prog, Instruction{Op: QU3, Args: qevmArgs(angleOperand(g.Params[0]), angleOperand(g.Params[1]), angleOperand(g.Params[2]), qubit)})
+		case "cz":
+			prog = append(prog, Instruction{Op: QCZ, Args: qevmArgs(uint64(g.Qubits[1]), qubit)})
+		case "cp":
+			prog = append(prog, Instruction{Op: QCPHASE, Args: qevmArgs(angleOperand(g.Params[0]), uint64(g.Qubits[1]), qubit)})
+		case "cu":
+			if gamma := g.Params[3]; gamma != 0 {
+				prog = append(prog, Instruction{Op: QPHASE, Args: qevmArgs(angleOperand(gamma), qubit)})
+			}
+			args := qevmArgs(angleOperand(g.Params[0]), angleOperand(g.Params[1]), angleOperand(g.Params[2]), uint64(g.Qubits[1]), 0)
+			args[4].Lsh(uint256.NewInt(1), uint(g.Qubits[0]))
+			prog = append(prog, Instruction{Op: QCU, Args: args})
+		case "swap":
+			prog = append(prog, Instruction{Op: QSWAP, Args: qevmArgs(uint64(g.Qubits[1]), qubit)})
+		case "ccx":
+			prog = append(prog, Instruction{Op: QTOFFOLI, Args: qevmArgs(uint64(g.Qubits[2]), uint64(g.Qubits[1]), qubit)})
+		case "measure":
+			prog = append(prog, Instruction{Op: QMEASURE, Args: qevmArgs(qubit), Clbit: g.Clbit})
+		case "reset":
+			prog = append(prog, Instruction{Op: QRESET})
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedGate, g.Name)
+		}
+	}
+	return prog, nil
+}
+
+// qevmArgs формирует операнды инструкции
+func qevmArgs(values ...uint64) []uint256.Int {
+	args := make([]uint256.Int, len(values))
+	for i, v := range values {
+		args[i].SetUint64(v)
+	}
+	return args
+}
+
+// angleOperand кодирует угол в радианах как операнд QEVM: долю оборота
+// theta/(2*pi), приведенную к [0, 1) и умноженную на 2^64, с округлением к
+// ближайшему целому (к четному при равенстве)
+func angleOperand(theta float64) uint64 {
+	turns := theta / (2 * math.Pi)
+	turns -= math.Floor(turns)
+	v := math.RoundToEven(math.Ldexp(turns, 64))
+	if v >= math.Ldexp(1, 64) {
+		return 0
+	}
+	return uint64(v)
+}
+
+// angleArg преобразует операнд QEVM в угол в радианах. Учитываются младшие 64
+// бита операнда, старшие задают целые обороты.
+func angleArg(v *uint256.Int) float64 {
+	return 2 * math.Pi * math.Ldexp(float64(v.Uint64()), -64)
+}
+
+// Bytecode транслирует схему в код контракта. Код выполняет инструкции
+// Compile, записывает результат измерения в байт памяти с номером
+// классического бита и возвращает классические биты схемы, по одному байту
+// 0 или 1 на бит.
+func (c *Circuit) Bytecode() ([]byte, error) {
+	prog, err := c.Compile()
+	if err != nil {
+		return nil, err
+	}
+	p := program.New()
+	for _, inst := range prog {
+		for i := len(inst.Args) - 1; i >= 0; i-- {
+			p.Push(inst.Args[i])
+		}
+		p.Op(vm.QUANTUM).Append([]byte{byte(inst.Op)})
+		if inst.Op == QMEASURE {
+			p.Push(inst.Clbit).Op(vm.MSTORE8)
+		}
+	}
+	if c.NumClbits == 0 {
+		return p.Op(vm.STOP).Bytes(), nil
+	}
+	return p.Return(0, c.NumClbits).Bytes(), nil
+}
+
+// ExecuteCircuit выполняет схему в контексте scope как последовательность
+// инструкций Compile, списывая газ с контракта scope, и возвращает
+// классические биты схемы. Регистр схемы заменяет регистр контракта.
+func (q *QEVMContext) ExecuteCircuit(scope *vm.ScopeContext, c *Circuit) ([]byte, error) {
+	prog, err := c.Compile()
+	if err != nil {
+		return nil, err
+	}
+	clbits := make([]byte, c.NumClbits)
+	for _, inst := range prog {
+		ret, err := q.ExecuteQuantumOp(inst.Op, scope, inst.Args)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", inst.Op, err)
+		}
+		if inst.Op == QMEASURE {
+			clbits[inst.Clbit] = byte(ret[0].Uint64())
+		}
+	}
+	return clbits, nil
+}
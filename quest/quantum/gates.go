@@ -0,0 +1,186 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"fmt"
+	"slices"
+)
+
+// pauliZGate - матрица вентиля Паули-Z, применяемая к целевому кубиту CZ
+var pauliZGate = [2][2]complex128{{1, 0}, {0, -1}}
+
+// u3Matrix возвращает матрицу вентиля U3 в соглашении OpenQASM:
+// U3(theta, phi, lambda) = P(phi) Ry(theta) P(lambda) без глобальной фазы
+func u3Matrix(theta, phi, lambda float64) [2][2]complex128 {
+	s, c := sincos(theta / 2)
+	return [2][2]complex128{
+		{complex(c, 0), -cscale(cis(lambda), s)},
+		{cscale(cis(phi), s), cscale(cis(phi+lambda), c)},
+	}
+}
+
+// checkGateQubits проверяет индексы кубитов вентиля и их попарное различие
+func (q *QuestEnv) checkGateQubits(qubits ...int) error {
+	for i, qubit := range qubits {
+		if err := q.checkQubitIndex(qubit); err != nil {
+			return err
+		}
+		if slices.Contains(qubits[:i], qubit) {
+			return ErrInvalidControlTarget
+		}
+	}
+	return nil
+}
+
+// ApplyU3 применяет к кубиту произвольный однокубитный вентиль
+// U3(theta, phi, lambda) = [[cos(theta/2), -e^(i*lambda)*sin(theta/2)],
+// [e^(i*phi)*sin(theta/2), e^(i*(phi+lambda))*cos(theta/2)]]
+func (q *QuestEnv) ApplyU3(qubit int, theta, phi, lambda float64) error {
+	return q.ApplyControlledU3(nil, qubit, theta, phi, lambda)
+}
+
+// ApplyControlledU3 применяет вентиль U3 к целевому кубиту в базисных
+// состояниях, где все управляющие кубиты равны 1. Вентиль выполняется одним
+// проходом по вектору состояния, а не разложением на базовые вентили, поэтому
+// относительная фаза управляемой части точна при любом количестве
+// управляющих кубитов. В историю записываются вентили u3 и cu, вентиль с
+// несколькими управляющими кубитами не выражается вентилями OpenQASM и при
+// включенной записи отклоняется.
+func (q *QuestEnv) ApplyControlledU3(controls []int, target int, theta, phi, lambda float64) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	qubits := append(slices.Clone(controls), target)
+	if err := q.checkGateQubits(qubits...); err != nil {
+		return err
+	}
+	if q.recording && len(controls) > 1 {
+		return fmt.Errorf("%w: U3 с %d управляющими кубитами", ErrUnsupportedGate, len(controls))
+	}
+	if err := q.applyUnitary(controls, target, u3Matrix(theta, phi, lambda)); err != nil {
+		return err
+	}
+	switch len(controls) {
+	case 0:
+		q.record("u3", []float64{theta, phi, lambda}, target)
+	case 1:
+		q.record("cu", []float64{theta, phi, lambda, 0}, controls[0], target)
+	}
+	return q.applyNoise(qubits...)
+}
+
+// applyUnitary применяет управляемый однокубитный оператор к вектору
+// состояния, переводя в него стабилизаторный регистр. Вызывается под
+// мьютексом окружения после проверки кубитов.
+func (q *QuestEnv) applyUnitary(controls []int, target int, m [2][2]complex128) error {
+	if _, err := q.amplitudes(); err != nil {
+		return err
+	}
+	// Недиагональный оператор может удвоить носитель разреженного регистра
+	if m[0][1] != 0 || m[1][0] != 0 {
+		if err := q.reserveSparse(); err != nil {
+			return err
+		}
+	}
+	var mask uint64
+	for _, control := range controls {
+		mask |= 1 << control
+	}
+	q.backend.(amplitudeBackend).unitary(mask, target, m)
+	q.compact()
+	return nil
+}
+
+// ApplyCZ применяет контролируемый вентиль Z, меняющий знак состояния |11⟩.
+// Вентиль симметричен относительно кубитов и является клиффордовым.
+func (q *QuestEnv) ApplyCZ(control, target int) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if err := q.checkGateQubits(control, target); err != nil {
+		return err
+	}
+	q.cz(control, target)
+	q.record("cz", nil, control, target)
+	return q.applyNoise(control, target)
+}
+
+// cz применяет CZ к текущему представлению: к вектору состояния - одним
+// проходом, к стабилизаторному регистру - как H CNOT H
+func (q *QuestEnv) cz(control, target int) {
+	if state, ok := q.backend.(amplitudeBackend); ok {
+		state.unitary(1<<control, target, pauliZGate)
+		return
+	}
+	q.backend.hadamard(target)
+	q.backend.cnot(control, target)
+	q.backend.hadamard(target)
+}
+
+// ApplyCPhase применяет контролируемый фазовый сдвиг |11⟩ -> e^(i*theta)|11⟩.
+// Сдвиги на углы, кратные pi, являются клиффордовыми и выполняются над
+// стабилизаторным регистром, остальные требуют вектора состояния.
+func (q *QuestEnv) ApplyCPhase(control, target int, theta float64) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if err := q.checkGateQubits(control, target); err != nil {
+		return err
+	}
+	k, clifford := cliffordPhase(theta)
+	_, stabilizer := q.backend.(*stabilizerState)
+	switch {
+	case stabilizer && clifford && k == 0:
+	case stabilizer && clifford && k == 2:
+		q.cz(control, target)
+	default:
+		phase := [2][2]complex128{{1, 0}, {0, cis(theta)}}
+		if err := q.applyUnitary([]int{control}, target, phase); err != nil {
+			return err
+		}
+	}
+	q.record("cp", []float64{theta}, control, target)
+	return q.applyNoise(control, target)
+}
+
+// ApplyISwap применяет вентиль iSWAP, меняющий местами состояния кубитов с
+// фазой i: |01⟩ -> i|10⟩, |10⟩ -> i|01⟩. Вентиль является клиффордовым:
+// iSWAP = SWAP CZ (S ⊗ S). Вентиль iswap не входит в stdgates.inc, поэтому в
+// историю записывается это разложение.
+func (q *QuestEnv) ApplyISwap(qubit1, qubit2 int) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if err := q.checkGateQubits(qubit1, qubit2); err != nil {
+		return err
+	}
+	if state, ok := q.backend.(amplitudeBackend); ok {
+		bits := uint64(1)<<qubit1 | uint64(1)<<qubit2
+		differ := func(i uint64) bool {
+			b := i & bits
+			return b != 0 && b != bits
+		}
+		state.permute(func(i uint64) uint64 {
+			if differ(i) {
+				return i ^ bits
+			}
+			return i
+		}, func(i uint64) complex128 {
+			if differ(i) {
+				return 1i
+			}
+			return 1
+		})
+	} else {
+		stab := q.backend.(*stabilizerState)
+		stab.phase(qubit1)
+		stab.phase(qubit2)
+		q.cz(qubit1, qubit2)
+		stab.swap(qubit1, qubit2)
+	}
+	q.record("s", nil, qubit1)
+	q.record("s", nil, qubit2)
+	q.record("cz", nil, qubit1, qubit2)
+	q.record("swap", nil, qubit1, qubit2)
+	return q.applyNoise(qubit1, qubit2)
+}
@@ -0,0 +1,213 @@
+package quantum
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+// assertEqualState проверяет точное совпадение векторов состояния, включая
+// глобальную фазу
+func assertEqualState(t *testing.T, name string, have, want []complex128) {
+	t.Helper()
+	if len(have) != len(want) {
+		t.Fatalf("%s: vector length mismatch: have %d, want %d", name, len(have), len(want))
+	}
+	for i := range want {
+		if cmplx.Abs(have[i]-want[i]) > 1e-9 {
+			t.Fatalf("%s: amplitude %d: have %v, want %v", name, i, have[i], want[i])
+		}
+	}
+}
+
+// superposition готовит регистр с ненулевыми амплитудами всех базисных
+// состояний и различными фазами
+func superposition(t *testing.T, numQubits int, backend Backend) *QuestEnv {
+	t.Helper()
+	env, err := NewQuestEnvWithBackend(numQubits, backend, false, 0, common.Hash{})
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+	for qubit := 0; qubit < numQubits; qubit++ {
+		env.ApplyU3(qubit, 0.3+float64(qubit), 0.7*float64(qubit), -0.2)
+	}
+	return env
+}
+
+// Проверяет U3 и управляемые вентили по их разложениям на базовые вентили
+func TestControlledGates(t *testing.T) {
+	tests := []struct {
+		name      string
+		gate, ref func(env *QuestEnv) error
+	}{
+		{
+			// U3(pi/2, 0, pi) = H
+			"u3 hadamard",
+			func(env *QuestEnv) error { return env.ApplyU3(1, math.Pi/2, 0, math.Pi) },
+			func(env *QuestEnv) error { return env.ApplyHadamard(1) },
+		},
+		{
+			// U3(pi, 0, pi) = X, управляемый X - CNOT и вентиль Тоффоли
+			"cnot",
+			func(env *QuestEnv) error { return env.ApplyControlledU3([]int{2}, 0, math.Pi, 0, math.Pi) },
+			func(env *QuestEnv) error { return env.ApplyCNOT(2, 0) },
+		},
+		{
+			"toffoli",
+			func(env *QuestEnv) error { return env.ApplyControlledU3([]int{0, 2}, 1, math.Pi, 0, math.Pi) },
+			func(env *QuestEnv) error { return env.applyToffoli(0, 2, 1) },
+		},
+		{
+			"cz",
+			func(env *QuestEnv) error { return env.ApplyCZ(1, 2) },
+			func(env *QuestEnv) error { return env.applyControlledPhase(1, 2, math.Pi) },
+		},
+		{
+			"cphase",
+			func(env *QuestEnv) error { return env.ApplyCPhase(0, 2, 0.9) },
+			func(env *QuestEnv) error { return env.applyControlledPhase(0, 2, 0.9) },
+		},
+		{
+			// U3(0, 0, lambda) = P(lambda)
+			"controlled u3 phase",
+			func(env *QuestEnv) error { return env.ApplyControlledU3([]int{1}, 0, 0, 0, -1.1) },
+			func(env *QuestEnv) error { return env.applyControlledPhase(1, 0, -1.1) },
+		},
+		{
+			// iSWAP = SWAP CZ (S ⊗ S)
+			"iswap",
+			func(env *QuestEnv) error { return env.ApplyISwap(0, 2) },
+			func(env *QuestEnv) error {
+				return applyGates(
+					func() error { return env.ApplyPhaseShift(0, math.Pi/2) },
+					func() error { return env.ApplyPhaseShift(2, math.Pi/2) },
+					func() error { return env.applyControlledPhase(0, 2, math.Pi) },
+					func() error { return env.ApplySwap(0, 2) },
+				)
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, backend := range []Backend{BackendDense, BackendSparse} {
+			have, want := superposition(t, 3, backend), superposition(t, 3, BackendDense)
+			if err := tt.gate(have); err != nil {
+				t.Fatalf("%s/%v: gate failed: %v", tt.name, backend, err)
+			}
+			if err := tt.ref(want); err != nil {
+				t.Fatalf("%s/%v: reference failed: %v", tt.name, backend, err)
+			}
+			assertEqualState(t, tt.name+"/"+backend.String(), have.GetStateVector(), want.GetStateVector())
+		}
+	}
+}
+
+// Проверяет клиффордовы вентили CZ, CPHASE(pi) и iSWAP над стабилизаторным
+// регистром
+func TestCliffordControlledGates(t *testing.T) {
+	for seed := byte(0); seed < 10; seed++ {
+		circuit := randomCircuit(seed, 4, 30, []string{"h", "s", "cnot"})
+		stab, _ := NewQuestEnvWithBackend(4, BackendStabilizer, false, 0, common.Hash{})
+		dense, _ := NewQuestEnvWithBackend(4, BackendDense, false, 0, common.Hash{})
+		for _, env := range []*QuestEnv{stab, dense} {
+			for _, step := range circuit {
+				if err := applyStep(env, step); err != nil {
+					t.Fatalf("seed %d: %v failed: %v", seed, step, err)
+				}
+			}
+			err := applyGates(
+				func() error { return env.ApplyCZ(0, 3) },
+				func() error { return env.ApplyISwap(1, 2) },
+				func() error { return env.ApplyCPhase(3, 1, math.Pi) },
+				func() error { return env.ApplyISwap(3, 0) },
+			)
+			if err != nil {
+				t.Fatalf("seed %d: %v backend: %v", seed, env.Backend(), err)
+			}
+		}
+		if stab.Backend() != BackendStabilizer {
+			t.Fatalf("seed %d: register left stabilizer representation", seed)
+		}
+		assertSameState(t, "stabilizer", stab.GetStateVector(), dense.GetStateVector())
+	}
+	stab, _ := NewQuestEnvWithBackend(2, BackendStabilizer, false, 0, common.Hash{})
+	if err := stab.ApplyCPhase(0, 1, math.Pi/2); !errors.Is(err, ErrNotClifford) {
+		t.Errorf("controlled S: want %v, have %v", ErrNotClifford, err)
+	}
+	if err := stab.ApplyU3(0, 1, 2, 3); !errors.Is(err, ErrNotClifford) {
+		t.Errorf("u3: want %v, have %v", ErrNotClifford, err)
+	}
+}
+
+// Проверяет проверку операндов и запись управляемых вентилей в историю
+func TestControlledGatesValidation(t *testing.T) {
+	env, _ := NewQuestEnv(3, false, 0)
+	if err := env.ApplyControlledU3([]int{1, 1}, 0, 0, 0, 0); !errors.Is(err, ErrInvalidControlTarget) {
+		t.Errorf("repeated control: want %v, have %v", ErrInvalidControlTarget, err)
+	}
+	if err := env.ApplyControlledU3([]int{0}, 0, 0, 0, 0); !errors.Is(err, ErrInvalidControlTarget) {
+		t.Errorf("control is target: want %v, have %v", ErrInvalidControlTarget, err)
+	}
+	if err := env.ApplyISwap(0, 3); !errors.Is(err, ErrQubitOutOfRange) {
+		t.Errorf("qubit out of range: want %v, have %v", ErrQubitOutOfRange, err)
+	}
+	env.StartRecording()
+	if err := env.ApplyControlledU3([]int{0, 1}, 2, 1, 2, 3); !errors.Is(err, ErrUnsupportedGate) {
+		t.Errorf("recorded multi-controlled u3: want %v, have %v", ErrUnsupportedGate, err)
+	}
+	env.ApplyControlledU3([]int{0}, 2, 1, 2, 3)
+	env.ApplyISwap(1, 2)
+
+	want := []string{"cu", "s", "s", "cz", "swap"}
+	circuit := env.Circuit()
+	if len(circuit.Gates) != len(want) {
+		t.Fatalf("recorded %d gates, want %d", len(circuit.Gates), len(want))
+	}
+	for i, g := range circuit.Gates {
+		if g.Name != want[i] {
+			t.Errorf("gate %d: have %s, want %s", i, g.Name, want[i])
+		}
+	}
+}
+
+// Проверяет кодирование углов операндов QEVM с фиксированной точкой
+func TestAngleOperand(t *testing.T) {
+	tests := []struct {
+		theta   float64
+		operand uint64
+	}{
+		{0, 0},
+		{math.Pi, 1 << 63},
+		{math.Pi / 2, 1 << 62},
+		{-math.Pi / 2, 0xc000000000000000},
+		{math.Pi / (1 << 40), 1 << 23},
+		{2 * math.Pi, 0},
+		{-1e-30, 0},
+	}
+	for _, tt := range tests {
+		if have := angleOperand(tt.theta); have != tt.operand {
+			t.Errorf("angleOperand(%v): have %#x, want %#x", tt.theta, have, tt.operand)
+		}
+	}
+	// Углы pi/2^k восстанавливаются точно, поэтому S и T остаются
+	// клиффордовыми и точными
+	for k := 0; k < 62; k++ {
+		theta := math.Pi / float64(uint64(1)<<k)
+		if have := angleArg(uint256.NewInt(angleOperand(theta))); have != theta {
+			t.Errorf("pi/2^%d: have %v", k, have)
+		}
+	}
+	// Старшие биты задают целые обороты, 0 - v задает отрицательный угол
+	v := new(uint256.Int).Lsh(uint256.NewInt(7), 64)
+	v.Add(v, uint256.NewInt(1<<62))
+	if have := angleArg(v); have != math.Pi/2 {
+		t.Errorf("whole turns not ignored: have %v", have)
+	}
+	v.Sub(new(uint256.Int), uint256.NewInt(1<<62))
+	if have := angleArg(v); have != 3*math.Pi/2 {
+		t.Errorf("negative angle: have %v", have)
+	}
+}
@@ -0,0 +1,242 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// ErrInvalidNoiseModel ошибка, возникающая при недопустимых параметрах
+// модели шума
+var ErrInvalidNoiseModel = errors.New("недопустимая модель шума")
+
+// NoiseModel описывает каналы шума регистра. После каждого базового вентиля
+// окружения каналы применяются к каждому кубиту вентиля в порядке полей,
+// ошибка считывания искажает классический результат измерения, не изменяя
+// состояние. Составные вентили (вращения, Тоффоли, QFT) раскладываются на
+// базовые, и шум накапливается после каждого из них.
+//
+// Каналы моделируются методом квантовых траекторий: вместо матрицы плотности
+// хранится вектор состояния, к которому применяется случайно выбранный
+// оператор Крауса канала. Усреднение по траекториям с разными зернами дает
+// матрицу плотности зашумленной схемы, а одно и то же зерно воспроизводит
+// одну и ту же траекторию.
+type NoiseModel struct {
+	// Depolarizing - вероятность деполяризующей ошибки: с вероятностью p/3
+	// к кубиту применяется каждый из вентилей X, Y и Z
+	Depolarizing float64
+
+	// AmplitudeDamping - вероятность gamma релаксации |1⟩ -> |0⟩. Канал не
+	// является клиффордовым и переводит регистр в представление вектором
+	// состояния.
+	AmplitudeDamping float64
+
+	// PhaseFlip - вероятность применения к кубиту вентиля Z
+	PhaseFlip float64
+
+	// ReadoutError - вероятность инвертирования измеренного бита
+	ReadoutError float64
+}
+
+// Validate проверяет, что параметры модели являются вероятностями
+func (m *NoiseModel) Validate() error {
+	params := []struct {
+		name  string
+		value float64
+	}{
+		{"деполяризация", m.Depolarizing},
+		{"затухание амплитуды", m.AmplitudeDamping},
+		{"инверсия фазы", m.PhaseFlip},
+		{"ошибка считывания", m.ReadoutError},
+	}
+	for _, p := range params {
+		if math.IsNaN(p.value) || p.value < 0 || p.value > 1 {
+			return fmt.Errorf("%w: %s %v", ErrInvalidNoiseModel, p.name, p.value)
+		}
+	}
+	return nil
+}
+
+// noiseless проверяет, что модель не вносит шума
+func (m *NoiseModel) noiseless() bool {
+	return m == nil || *m == NoiseModel{}
+}
+
+// Операнд QINIT задает размер регистра и модель его шума (от старших бит к
+// младшим):
+//
+//	[64 бита нулей][32 бита ошибки считывания][32 бита инверсии фазы]
+//	[32 бита затухания амплитуды][32 бита деполяризации][64 бита числа кубитов]
+//
+// Вероятности задаются в миллиардных долях, поэтому модель одинаково
+// вычисляется всеми узлами. Операнд регистра без шума совпадает с числом
+// кубитов.
+const noiseOperandScale = 1e9
+
+// qinitOperand разбирает операнд QINIT на размер регистра и модель шума, nil
+// для регистра без шума
+func qinitOperand(v *uint256.Int) (int, *NoiseModel, error) {
+	if v[0] > math.MaxInt32 {
+		return 0, nil, ErrMaxQubitsExceeded
+	}
+	if v[3] != 0 {
+		return 0, nil, fmt.Errorf("%w: старшие биты операнда QINIT", ErrInvalidNoiseModel)
+	}
+	model := &NoiseModel{
+		Depolarizing:     noiseOperand(v[1]),
+		AmplitudeDamping: noiseOperand(v[1] >> 32),
+		PhaseFlip:        noiseOperand(v[2]),
+		ReadoutError:     noiseOperand(v[2] >> 32),
+	}
+	if err := model.Validate(); err != nil {
+		return 0, nil, err
+	}
+	if model.noiseless() {
+		model = nil
+	}
+	return int(v[0]), model, nil
+}
+
+// noiseOperand возвращает вероятность из младших 32 бит поля операнда QINIT
+func noiseOperand(field uint64) float64 {
+	return float64(uint32(field)) / noiseOperandScale
+}
+
+// encodeNoise записывает модель шума в кодировку регистра
+func encodeNoise(buf []byte, model *NoiseModel) {
+	for i, p := range []float64{model.Depolarizing, model.AmplitudeDamping, model.PhaseFlip, model.ReadoutError} {
+		binary.BigEndian.PutUint64(buf[i*8:], math.Float64bits(canonicalFloat(p)))
+	}
+}
+
+// decodeNoise читает модель шума из кодировки регистра и возвращает
+// оставшиеся данные представления
+func decodeNoise(body []byte) (*NoiseModel, []byte, error) {
+	if len(body) < noiseEncodingSize {
+		return nil, nil, fmt.Errorf("%w: неверная длина модели шума", ErrInvalidRegisterEncoding)
+	}
+	var p [4]float64
+	for i := range p {
+		bits := binary.BigEndian.Uint64(body[i*8:])
+		p[i] = math.Float64frombits(bits)
+		if bits != math.Float64bits(canonicalFloat(p[i])) {
+			return nil, nil, fmt.Errorf("%w: отрицательный ноль в модели шума", ErrInvalidRegisterEncoding)
+		}
+	}
+	model := &NoiseModel{Depolarizing: p[0], AmplitudeDamping: p[1], PhaseFlip: p[2], ReadoutError: p[3]}
+	if err := model.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidRegisterEncoding, err)
+	}
+	// Регистр без шума кодируется без модели
+	if model.noiseless() {
+		return nil, nil, fmt.Errorf("%w: пустая модель шума", ErrInvalidRegisterEncoding)
+	}
+	return model, body[noiseEncodingSize:], nil
+}
+
+// noiseSeed выводит зерно генератора шума из зерна измерений. Шум
+// использует отдельный генератор, поэтому включение шума не сдвигает
+// последовательность случайных чисел измерений.
+func noiseSeed(seed common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte("noise"), seed[:])
+}
+
+// SetNoiseModel задает модель шума регистра, nil или нулевая модель
+// отключает шум. Генератор шума выводится из зерна измерений регистра и
+// переинициализируется вместе с ним.
+func (q *QuestEnv) SetNoiseModel(model *NoiseModel) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if model.noiseless() {
+		q.noise = nil
+		return nil
+	}
+	if err := model.Validate(); err != nil {
+		return err
+	}
+	if model.AmplitudeDamping > 0 && q.mode == BackendStabilizer {
+		return fmt.Errorf("%w: затухание амплитуды", ErrNotClifford)
+	}
+	noise := *model
+	q.noise = &noise
+	return nil
+}
+
+// NoiseModel возвращает копию модели шума регистра или nil, если шум
+// отключен
+func (q *QuestEnv) NoiseModel() *NoiseModel {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.noise == nil {
+		return nil
+	}
+	noise := *q.noise
+	return &noise
+}
+
+// applyNoise применяет каналы шума к кубитам вентиля. Вызывается под
+// мьютексом окружения после вентиля.
+func (q *QuestEnv) applyNoise(qubits ...int) error {
+	if q.noise == nil {
+		return nil
+	}
+	for _, qubit := range qubits {
+		if p := q.noise.Depolarizing; p > 0 {
+			if r := q.noiseRandom.Float64(); r < p {
+				switch int(3 * r / p) {
+				case 0:
+					q.backend.pauliX(qubit)
+				case 1:
+					q.backend.pauliY(qubit)
+				default:
+					q.backend.pauliZ(qubit)
+				}
+			}
+		}
+		if gamma := q.noise.AmplitudeDamping; gamma > 0 {
+			state, err := q.amplitudes()
+			if err != nil {
+				return err
+			}
+			dampAmplitude(state, qubit, gamma, q.noiseRandom)
+		}
+		if p := q.noise.PhaseFlip; p > 0 && q.noiseRandom.Float64() < p {
+			q.backend.pauliZ(qubit)
+		}
+	}
+	return nil
+}
+
+// dampAmplitude применяет к кубиту один из операторов Крауса канала
+// затухания амплитуды: K1 = sqrt(gamma)|0⟩⟨1| с вероятностью gamma*P(1) или
+// K0 = |0⟩⟨0| + sqrt(1-gamma)|1⟩⟨1|, и нормализует состояние
+func dampAmplitude(state amplitudeBackend, qubit int, gamma float64, random *DeterministicRNG) {
+	prob1 := state.probabilityOne(qubit)
+	jump := float64(gamma * prob1)
+	if random.Float64() < jump {
+		state.scale(qubit, 0, 1/math.Sqrt(prob1))
+		state.pauliX(qubit)
+		return
+	}
+	norm := math.Sqrt(1 - jump)
+	state.scale(qubit, 1/norm, math.Sqrt(1-gamma)/norm)
+}
+
+// readout искажает измеренный бит ошибкой считывания
+func (q *QuestEnv) readout(bit int) int {
+	if q.noise == nil || q.noise.ReadoutError == 0 {
+		return bit
+	}
+	if q.noiseRandom.Float64() < q.noise.ReadoutError {
+		return bit ^ 1
+	}
+	return bit
+}
@@ -0,0 +1,209 @@
+package quantum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+// noisyEnv создает регистр с моделью шума и зерном seed
+func noisyEnv(t *testing.T, numQubits int, backend Backend, seed byte, model NoiseModel) *QuestEnv {
+	t.Helper()
+	env, err := NewQuestEnvWithBackend(numQubits, backend, false, 0, common.Hash{seed})
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+	if err := env.SetNoiseModel(&model); err != nil {
+		t.Fatalf("failed to set noise model: %v", err)
+	}
+	return env
+}
+
+// Проверяет частоты ошибок каналов шума, усредненные по траекториям
+func TestNoiseChannelRates(t *testing.T) {
+	const trajectories = 2000
+	tests := []struct {
+		name  string
+		model NoiseModel
+		want  float64 // Вероятность измерить 1 после X
+	}{
+		{"noiseless", NoiseModel{}, 1},
+		{"depolarizing", NoiseModel{Depolarizing: 0.3}, 1 - 2*0.3/3},
+		{"damping", NoiseModel{AmplitudeDamping: 0.4}, 0.6},
+		{"phase flip", NoiseModel{PhaseFlip: 0.5}, 1},
+		{"readout", NoiseModel{ReadoutError: 0.25}, 0.75},
+	}
+	for _, tt := range tests {
+		var ones int
+		for i := 0; i < trajectories; i++ {
+			env := noisyEnv(t, 1, BackendAuto, 0, tt.model)
+			env.SetMeasurementSeed(common.Hash{byte(i), byte(i >> 8)})
+			if err := env.ApplyPauliX(0); err != nil {
+				t.Fatalf("%s: pauli x failed: %v", tt.name, err)
+			}
+			bit, err := env.MeasureQubit(0)
+			if err != nil {
+				t.Fatalf("%s: measure failed: %v", tt.name, err)
+			}
+			ones += bit
+		}
+		if have := float64(ones) / trajectories; math.Abs(have-tt.want) > 0.04 {
+			t.Errorf("%s: P(1) = %.3f, want %.3f", tt.name, have, tt.want)
+		}
+	}
+}
+
+// Проверяет, что одно зерно воспроизводит траекторию, а плотный и
+// разреженный регистры проходят одну и ту же траекторию
+func TestNoiseDeterminism(t *testing.T) {
+	model := NoiseModel{Depolarizing: 0.2, AmplitudeDamping: 0.3, PhaseFlip: 0.1}
+	run := func(backend Backend, seed byte) []complex128 {
+		env := noisyEnv(t, 3, backend, seed, model)
+		for i := 0; i < 10; i++ {
+			if err := env.ApplyHadamard(i % 3); err != nil {
+				t.Fatalf("hadamard failed: %v", err)
+			}
+			if err := env.ApplyCNOT(i%3, (i+1)%3); err != nil {
+				t.Fatalf("cnot failed: %v", err)
+			}
+		}
+		return env.GetStateVector()
+	}
+	equal := func(a, b []complex128) bool {
+		for i := range a {
+			if cmplx.Abs(a[i]-b[i]) > 1e-9 {
+				return false
+			}
+		}
+		return true
+	}
+	dense := run(BackendDense, 1)
+	if !equal(dense, run(BackendDense, 1)) {
+		t.Fatal("same seed produced different trajectories")
+	}
+	if !equal(dense, run(BackendSparse, 1)) {
+		t.Fatal("sparse register diverged from dense register")
+	}
+	if equal(dense, run(BackendDense, 2)) {
+		t.Fatal("different seeds produced identical trajectories")
+	}
+	var norm float64
+	for _, amp := range dense {
+		norm += probability(amp)
+	}
+	if math.Abs(norm-1) > 1e-9 {
+		t.Fatalf("state not normalised: %v", norm)
+	}
+}
+
+// Проверяет, что шум не сдвигает последовательность случайных чисел
+// измерений и отключается нулевой моделью
+func TestNoiseModelConfig(t *testing.T) {
+	env := noisyEnv(t, 2, BackendStabilizer, 0, NoiseModel{Depolarizing: 0.5})
+	if err := env.SetNoiseModel(&NoiseModel{AmplitudeDamping: 0.1}); !errors.Is(err, ErrNotClifford) {
+		t.Fatalf("expected ErrNotClifford for damping of stabilizer register, got %v", err)
+	}
+	if err := env.SetNoiseModel(&NoiseModel{}); err != nil || env.NoiseModel() != nil {
+		t.Fatalf("zero model not disabling noise: %v, %v", err, env.NoiseModel())
+	}
+	for _, model := range []NoiseModel{{Depolarizing: -0.1}, {PhaseFlip: 1.5}, {ReadoutError: math.NaN()}} {
+		if err := env.SetNoiseModel(&model); !errors.Is(err, ErrInvalidNoiseModel) {
+			t.Errorf("%+v: expected ErrInvalidNoiseModel, got %v", model, err)
+		}
+	}
+	// Ошибка считывания не влияет на состояние и на исходы измерений
+	measure := func(model *NoiseModel) []int {
+		env, _ := NewQuestEnvWithSeed(2, false, 0, common.Hash{7})
+		env.SetNoiseModel(model)
+		var outcomes []int
+		for i := 0; i < 16; i++ {
+			env.ApplyHadamard(0)
+			bit, _ := env.MeasureQubit(0)
+			outcomes = append(outcomes, bit)
+		}
+		return outcomes
+	}
+	clean, flipped := measure(nil), measure(&NoiseModel{ReadoutError: 1})
+	for i := range clean {
+		if clean[i] != flipped[i]^1 {
+			t.Fatalf("outcome %d: clean %d, with readout error %d", i, clean[i], flipped[i])
+		}
+	}
+}
+
+// Проверяет разбор модели шума из операнда QINIT
+func TestQInitOperand(t *testing.T) {
+	operand := func(qubits, depolarizing, damping, phaseFlip, readout uint64) *uint256.Int {
+		return &uint256.Int{qubits, depolarizing | damping<<32, phaseFlip | readout<<32}
+	}
+	numQubits, model, err := qinitOperand(operand(3, 0, 0, 0, 0))
+	if err != nil || numQubits != 3 || model != nil {
+		t.Fatalf("noiseless operand: %d qubits, model %+v, %v", numQubits, model, err)
+	}
+	numQubits, model, err = qinitOperand(operand(5, 1e8, 2e8, 5e8, 1e9))
+	if err != nil || numQubits != 5 {
+		t.Fatalf("noisy operand: %d qubits, %v", numQubits, err)
+	}
+	if want := (NoiseModel{Depolarizing: 0.1, AmplitudeDamping: 0.2, PhaseFlip: 0.5, ReadoutError: 1}); *model != want {
+		t.Fatalf("model mismatch: have %+v, want %+v", *model, want)
+	}
+	if _, _, err := qinitOperand(operand(1, 0, 0, 0, 1e9+1)); !errors.Is(err, ErrInvalidNoiseModel) {
+		t.Errorf("probability above one: expected ErrInvalidNoiseModel, got %v", err)
+	}
+	if _, _, err := qinitOperand(&uint256.Int{1, 0, 0, 1}); !errors.Is(err, ErrInvalidNoiseModel) {
+		t.Errorf("high bits: expected ErrInvalidNoiseModel, got %v", err)
+	}
+	if _, _, err := qinitOperand(&uint256.Int{math.MaxInt32 + 1}); !errors.Is(err, ErrMaxQubitsExceeded) {
+		t.Errorf("qubit overflow: expected ErrMaxQubitsExceeded, got %v", err)
+	}
+}
+
+// Проверяет, что модель шума хранится вместе с регистром, а регистр без
+// шума кодируется как прежде
+func TestNoiseModelEncoding(t *testing.T) {
+	model := NoiseModel{Depolarizing: 0.1, PhaseFlip: 0.2, ReadoutError: 0.05}
+	env := noisyEnv(t, 2, BackendAuto, 1, model)
+	env.ApplyHadamard(0)
+
+	enc := env.EncodeState()
+	if enc[0]&registerNoiseFlag == 0 {
+		t.Fatal("noise flag not set")
+	}
+	dec, err := DecodeQuestEnv(enc, BackendAuto, common.Hash{1})
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if have := dec.NoiseModel(); have == nil || *have != model {
+		t.Fatalf("noise model mismatch: have %+v, want %+v", have, model)
+	}
+	if !bytes.Equal(dec.EncodeState(), enc) {
+		t.Fatal("encoding not canonical")
+	}
+	// Без модели кодировка совпадает с кодировкой представления
+	env.SetNoiseModel(nil)
+	clean := env.EncodeState()
+	if clean[0]&registerNoiseFlag != 0 || !bytes.Equal(clean[registerHeaderSize:], enc[registerHeaderSize+noiseEncodingSize:]) {
+		t.Fatal("noiseless register encoding changed")
+	}
+	// Пустая, недопустимая или усеченная модель отклоняется
+	empty := common.CopyBytes(enc)
+	clear(empty[registerHeaderSize : registerHeaderSize+noiseEncodingSize])
+	invalid := common.CopyBytes(enc)
+	binary.BigEndian.PutUint64(invalid[registerHeaderSize:], math.Float64bits(2))
+
+	for name, data := range map[string][]byte{
+		"empty":     empty,
+		"invalid":   invalid,
+		"truncated": enc[:registerHeaderSize+noiseEncodingSize-1],
+	} {
+		if _, err := DecodeQuestEnv(data, BackendAuto, common.Hash{}); !errors.Is(err, ErrInvalidRegisterEncoding) {
+			t.Errorf("%s: expected ErrInvalidRegisterEncoding, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+// Package quantum обеспечивает интеграцию квантовых вычислений с EVM
+package quantum
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Квантовые предкомпилированные контракты форка Quantum. Контракты не имеют
+// состояния: результат определяется входными данными, в том числе зерном
+// измерений, которое передает вызывающий контракт (например, значение
+// block.prevrandao). Данные кодируются по ABI без селектора функции:
+//
+//	circuit: (uint8 numQubits, uint8 numClbits,
+//	          (string name, uint8[] qubits, int256[] params, uint8 clbit)[] gates,
+//	          bytes32 seed) -> (bytes clbits)
+//	qft:     (int256[] real, int256[] imag) -> (int256[] real, int256[] imag)
+//	grover:  (uint64 searchSpace, uint64[] marked, bytes32 seed) -> (uint64 result, bool found)
+//	random:  (bytes32 seed, uint256 length) -> (bytes random)
+//
+// Углы вентилей и компоненты амплитуд задаются числами с фиксированной
+// точкой: 10^18 соответствует единице.
+func init() {
+	vm.RegisterQuantumPrecompile(vm.QuantumCircuitAddress, circuitPrecompile{})
+	vm.RegisterQuantumPrecompile(vm.QuantumQFTAddress, qftPrecompile{})
+	vm.RegisterQuantumPrecompile(vm.QuantumGroverAddress, groverPrecompile{})
+	vm.RegisterQuantumPrecompile(vm.QuantumRandomAddress, randomPrecompile{})
+}
+
+// fixedPointOne - единица в представлении с фиксированной точкой
+var fixedPointOne = new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+
+// Кодировки входных и выходных данных контрактов
+var (
+	circuitInput = abi.Arguments{
+		{Name: "numQubits", Type: abiType("uint8")},
+		{Name: "numClbits", Type: abiType("uint8")},
+		{Name: "gates", Type: abiType("tuple[]",
+			abi.ArgumentMarshaling{Name: "name", Type: "string"},
+			abi.ArgumentMarshaling{Name: "qubits", Type: "uint8[]"},
+			abi.ArgumentMarshaling{Name: "params", Type: "int256[]"},
+			abi.ArgumentMarshaling{Name: "clbit", Type: "uint8"},
+		)},
+		{Name: "seed", Type: abiType("bytes32")},
+	}
+	circuitOutput = abi.Arguments{{Name: "clbits", Type: abiType("bytes")}}
+
+	qftArguments = abi.Arguments{
+		{Name: "real", Type: abiType("int256[]")},
+		{Name: "imag", Type: abiType("int256[]")},
+	}
+
+	groverInput = abi.Arguments{
+		{Name: "searchSpace", Type: abiType("uint64")},
+		{Name: "marked", Type: abiType("uint64[]")},
+		{Name: "seed", Type: abiType("bytes32")},
+	}
+	groverOutput = abi.Arguments{
+		{Name: "result", Type: abiType("uint64")},
+		{Name: "found", Type: abiType("bool")},
+	}
+
+	randomInput = abi.Arguments{
+		{Name: "seed", Type: abiType("bytes32")},
+		{Name: "length", Type: abiType("uint256")},
+	}
+	randomOutput = abi.Arguments{{Name: "random", Type: abiType("bytes")}}
+)
+
+// abiType создает тип ABI, паникуя при ошибке в описании
+func abiType(t string, components ...abi.ArgumentMarshaling) abi.Type {
+	typ, err := abi.NewType(t, "", components)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+// decodeInput декодирует входные данные контракта в структуру out
+func decodeInput(args abi.Arguments, input []byte, out interface{}) error {
+	values, err := args.Unpack(input)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if err := args.Copy(out, values); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// fromFixed преобразует число с фиксированной точкой в float64 с одним
+// округлением
+func fromFixed(v *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(v), fixedPointOne).Float64()
+	return f
+}
+
+// toFixed преобразует float64 в число с фиксированной точкой, отбрасывая
+// дробную часть. Значения вне диапазона int256 отклоняются.
+func toFixed(f float64) (*big.Int, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, ErrInvalidInput
+	}
+	v, _ := new(big.Float).Mul(new(big.Float).SetFloat64(f), fixedPointOne).Int(nil)
+	if v.BitLen() > 255 {
+		return nil, fmt.Errorf("%w: результат вне диапазона int256", ErrInvalidInput)
+	}
+	return v, nil
+}
+
+// circuitCall - входные данные контракта симуляции схемы
+type circuitCall struct {
+	NumQubits uint8
+	NumClbits uint8
+	Gates     []struct {
+		Name   string
+		Qubits []uint8
+		Params []*big.Int
+		Clbit  uint8
+	}
+	Seed [32]byte
+}
+
+// cliffordGates - вентили схемы, которые выполняются стабилизаторным
+// бэкендом
+var cliffordGates = map[string]bool{
+	"h": true, "x": true, "y": true, "z": true, "s": true, "sdg": true,
+	"cx": true, "cz": true, "swap": true, "measure": true, "reset": true,
+}
+
+// circuitPrecompile выполняет квантовую схему и возвращает значения ее
+// классических битов, по одному байту 0 или 1 на бит. Клиффордовы схемы
+// выполняются стабилизаторным бэкендом, остальные - плотным вектором
+// состояния.
+type circuitPrecompile struct{}
+
+// decode декодирует и проверяет схему и выбирает бэкенд для нее
+func (circuitPrecompile) decode(input []byte) (*Circuit, Backend, [32]byte, error) {
+	var call circuitCall
+	if err := decodeInput(circuitInput, input, &call); err != nil {
+		return nil, 0, call.Seed, err
+	}
+	c := &Circuit{
+		NumQubits: int(call.NumQubits),
+		NumClbits: int(call.NumClbits),
+		Gates:     make([]Gate, len(call.Gates)),
+	}
+	backend := BackendStabilizer
+	for i, g := range call.Gates {
+		gate := Gate{Name: g.Name, Qubits: make([]int, len(g.Qubits)), Clbit: int(g.Clbit)}
+		for j, qubit := range g.Qubits {
+			gate.Qubits[j] = int(qubit)
+		}
+		if len(g.Params) > 0 {
+			gate.Params = make([]float64, len(g.Params))
+			for j, theta := range g.Params {
+				gate.Params[j] = fromFixed(theta)
+			}
+		}
+		if !cliffordGates[g.Name] {
+			backend = BackendDense
+		}
+		c.Gates[i] = gate
+	}
+	if err := c.Validate(); err != nil {
+		return nil, 0, call.Seed, err
+	}
+	return c, backend, call.Seed, nil
+}
+
+// RequiredGas возвращает стоимость программы QEVM, в которую компилируется
+// схема, для регистра выбранного бэкенда
+func (p circuitPrecompile) RequiredGas(table *params.QuantumGasTable, input []byte) uint64 {
+	c, backend, _, err := p.decode(input)
+	if err != nil {
+		return table.InitGas
+	}
+	words, err := RegisterWords(backend, c.NumQubits)
+	if err != nil {
+		return table.InitGas
+	}
+	prog, err := c.Compile()
+	if err != nil {
+		return table.InitGas
+	}
+	var total uint64
+	for _, inst := range prog {
+		gas, err := vm.QuantumGas(table, inst.Op, words, inst.Args)
+		if err != nil {
+			return math.MaxUint64
+		}
+		var carry uint64
+		if total, carry = bits.Add64(total, gas, 0); carry != 0 {
+			return math.MaxUint64
+		}
+	}
+	return total
+}
+
+func (p circuitPrecompile) Run(input []byte) ([]byte, error) {
+	c, backend, seed, err := p.decode(input)
+	if err != nil {
+		return nil, err
+	}
+	env, err := NewQuestEnvWithBackend(c.NumQubits, backend, false, 0, seed)
+	if err != nil {
+		return nil, err
+	}
+	defer env.Destroy()
+
+	clbits, err := env.RunCircuit(c)
+	if err != nil {
+		return nil, err
+	}
+	return circuitOutput.Pack(clbits)
+}
+
+// qftCall - входные и выходные данные контракта QFT
+type qftCall struct {
+	Real []*big.Int
+	Imag []*big.Int
+}
+
+// qftPrecompile выполняет квантовое преобразование Фурье вектора комплексных
+// чисел, длина которого должна быть степенью двойки. Стоимость совпадает со
+// стоимостью QQFT для того же количества амплитуд.
+type qftPrecompile struct{}
+
+func (qftPrecompile) RequiredGas(table *params.QuantumGasTable, input []byte) uint64 {
+	var call qftCall
+	if err := decodeInput(qftArguments, input, &call); err != nil {
+		return table.QFTGas
+	}
+	size := uint64(len(call.Real)) * params.QuantumAmplitudeSize
+	gas, err := vm.QuantumGas(table, QQFT, 0, qevmArgs(0, size))
+	if err != nil {
+		return math.MaxUint64
+	}
+	return gas
+}
+
+func (qftPrecompile) Run(input []byte) ([]byte, error) {
+	var call qftCall
+	if err := decodeInput(qftArguments, input, &call); err != nil {
+		return nil, err
+	}
+	if len(call.Real) != len(call.Imag) {
+		return nil, fmt.Errorf("%w: %d действительных и %d мнимых частей", ErrInvalidInput, len(call.Real), len(call.Imag))
+	}
+	data := make([]complex128, len(call.Real))
+	for i := range data {
+		data[i] = complex(fromFixed(call.Real[i]), fromFixed(call.Imag[i]))
+	}
+	env, err := NewQuestEnv(1, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer env.Destroy()
+
+	result, err := env.ExecuteQFT(data)
+	if err != nil {
+		return nil, err
+	}
+	out := qftCall{Real: make([]*big.Int, len(result)), Imag: make([]*big.Int, len(result))}
+	for i, v := range result {
+		if out.Real[i], err = toFixed(real(v)); err != nil {
+			return nil, err
+		}
+		if out.Imag[i], err = toFixed(imag(v)); err != nil {
+			return nil, err
+		}
+	}
+	return qftArguments.Pack(out.Real, out.Imag)
+}
+
+// groverCall - входные данные контракта поиска Гровера
+type groverCall struct {
+	SearchSpace uint64
+	Marked      []uint64
+	Seed        [32]byte
+}
+
+// groverPrecompile ищет алгоритмом Гровера одно из отмеченных чисел в
+// пространстве [0, searchSpace) на регистре из ceil(log2(searchSpace))
+// кубитов с оптимальным для количества отмеченных чисел числом итераций.
+// Найденное число проверяется классически, результат found сообщает, входит
+// ли оно в отмеченные.
+type groverPrecompile struct{}
+
+// decode декодирует входные данные и возвращает множество отмеченных чисел,
+// размер регистра и количество итераций
+func (groverPrecompile) decode(input []byte) (*groverCall, map[uint64]bool, int, int, error) {
+	call := new(groverCall)
+	if err := decodeInput(groverInput, input, call); err != nil {
+		return nil, nil, 0, 0, err
+	}
+	if call.SearchSpace == 0 {
+		return nil, nil, 0, 0, fmt.Errorf("%w: пустое пространство поиска", ErrInvalidInput)
+	}
+	numQubits := max(bits.Len64(call.SearchSpace-1), 1)
+	if numQubits > MaxDenseQubits {
+		return nil, nil, 0, 0, fmt.Errorf("%w: поиску нужно %d кубитов", ErrMaxQubitsExceeded, numQubits)
+	}
+	marked := make(map[uint64]bool, len(call.Marked))
+	for _, v := range call.Marked {
+		if v >= call.SearchSpace {
+			return nil, nil, 0, 0, fmt.Errorf("%w: число %d вне пространства поиска", ErrInvalidInput, v)
+		}
+		marked[v] = true
+	}
+	// Оптимальное количество итераций для M отмеченных из N состояний:
+	// floor(pi/4 * sqrt(N/M)). Без отмеченных чисел измеряется равномерная
+	// суперпозиция.
+	iterations := 0
+	if len(marked) > 0 {
+		iterations = int(math.Floor(math.Pi / 4 * math.Sqrt(math.Ldexp(1, numQubits)/float64(len(marked)))))
+	}
+	return call, marked, numQubits, iterations, nil
+}
+
+// RequiredGas возвращает стоимость поиска, рассчитанную как у QGROVER, но
+// по фактическому количеству итераций
+func (p groverPrecompile) RequiredGas(table *params.QuantumGasTable, input []byte) uint64 {
+	_, _, numQubits, iterations, err := p.decode(input)
+	if err != nil {
+		return table.GroverGas
+	}
+	m, it := uint64(numQubits), uint64(iterations)
+	gas, ok := table.PassCost(table.GroverGas, denseWords(numQubits), m+it*(2*m+2)+2)
+	if !ok {
+		return math.MaxUint64
+	}
+	return gas
+}
+
+func (p groverPrecompile) Run(input []byte) ([]byte, error) {
+	call, marked, numQubits, iterations, err := p.decode(input)
+	if err != nil {
+		return nil, err
+	}
+	env, err := NewQuestEnvWithBackend(numQubits, BackendDense, false, 0, call.Seed)
+	if err != nil {
+		return nil, err
+	}
+	defer env.Destroy()
+
+	result, err := env.GroverSearch(func(i uint64) bool { return marked[i] }, iterations)
+	if err != nil {
+		return nil, err
+	}
+	return groverOutput.Pack(result, marked[result])
+}
+
+// randomCall - входные данные маяка случайности
+type randomCall struct {
+	Seed   [32]byte
+	Length *big.Int
+}
+
+// randomPrecompile - детерминированный квантовый маяк случайности: каждый
+// бит результата получается измерением кубита в суперпозиции с генератором
+// измерений, инициализированным зерном. Одинаковое зерно дает одинаковые
+// байты на любом узле. Стоимость совпадает со стоимостью QRANDOM над
+// однокубитным регистром.
+type randomPrecompile struct{}
+
+func (randomPrecompile) RequiredGas(table *params.QuantumGasTable, input []byte) uint64 {
+	var call randomCall
+	if err := decodeInput(randomInput, input, &call); err != nil {
+		return table.RandomGas
+	}
+	if !call.Length.IsUint64() {
+		return math.MaxUint64
+	}
+	gas, err := vm.QuantumGas(table, QRANDOM, stabilizerWords(1), qevmArgs(0, call.Length.Uint64()))
+	if err != nil {
+		return math.MaxUint64
+	}
+	return gas
+}
+
+func (randomPrecompile) Run(input []byte) ([]byte, error) {
+	var call randomCall
+	if err := decodeInput(randomInput, input, &call); err != nil {
+		return nil, err
+	}
+	if !call.Length.IsUint64() || call.Length.Uint64() > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: длина %v", ErrInvalidInput, call.Length)
+	}
+	env, err := NewQuestEnvWithBackend(1, BackendStabilizer, false, 0, call.Seed)
+	if err != nil {
+		return nil, err
+	}
+	defer env.Destroy()
+
+	random, err := env.GenerateQuantumRandomBytes(int(call.Length.Uint64()))
+	if err != nil {
+		return nil, err
+	}
+	return randomOutput.Pack(random)
+}
@@ -0,0 +1,228 @@
+package quantum
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// abiGate - вентиль во входных данных контракта симуляции схемы
+type abiGate struct {
+	Name   string
+	Qubits []uint8
+	Params []*big.Int
+	Clbit  uint8
+}
+
+// runPrecompile вызывает квантовый контракт форка и возвращает результат и
+// стоимость вызова
+func runPrecompile(t *testing.T, addr common.Address, input []byte) ([]byte, uint64, error) {
+	t.Helper()
+	p := vm.PrecompiledContractsQuantum[addr]
+	if p == nil {
+		t.Fatalf("no precompile at %v", addr)
+	}
+	gas := p.RequiredGas(input)
+	out, err := p.Run(input)
+	return out, gas, err
+}
+
+// fixed преобразует число в представление с фиксированной точкой
+func fixed(f float64) *big.Int {
+	v, err := toFixed(f)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Проверяет симуляцию схемы и расчет ее стоимости по программе QEVM
+func TestCircuitPrecompile(t *testing.T) {
+	table := &params.QuantumGasTableQuantum
+	bell := []abiGate{
+		{Name: "h", Qubits: []uint8{0}, Params: []*big.Int{}},
+		{Name: "cx", Qubits: []uint8{0, 1}, Params: []*big.Int{}},
+		{Name: "measure", Qubits: []uint8{0}, Params: []*big.Int{}, Clbit: 0},
+		{Name: "measure", Qubits: []uint8{1}, Params: []*big.Int{}, Clbit: 1},
+	}
+	for seed := byte(0); seed < 8; seed++ {
+		input, err := circuitInput.Pack(uint8(2), uint8(2), bell, [32]byte{seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, gas, err := runPrecompile(t, vm.QuantumCircuitAddress, input)
+		if err != nil {
+			t.Fatalf("bell circuit: %v", err)
+		}
+		values, err := circuitOutput.Unpack(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clbits := values[0].([]byte)
+		if len(clbits) != 2 || clbits[0] != clbits[1] {
+			t.Errorf("bell circuit: uncorrelated bits %v", clbits)
+		}
+		// Клиффордова схема выполняется на стабилизаторном регистре
+		words := stabilizerWords(2)
+		init, _ := table.InitCost(words)
+		gates, _ := table.PassCost(table.GateGas, words, 1)
+		measure, _ := table.MeasureCost(words)
+		if want := init + 2*gates + 2*measure; gas != want {
+			t.Errorf("bell circuit gas: have %d, want %d", gas, want)
+		}
+	}
+
+	// Вращение на pi переводит |0⟩ в |1⟩, схема выполняется плотным вектором
+	flip := []abiGate{
+		{Name: "rx", Qubits: []uint8{0}, Params: []*big.Int{fixed(math.Pi)}},
+		{Name: "measure", Qubits: []uint8{0}, Params: []*big.Int{}},
+	}
+	input, _ := circuitInput.Pack(uint8(1), uint8(1), flip, [32]byte{})
+	out, gas, err := runPrecompile(t, vm.QuantumCircuitAddress, input)
+	if err != nil {
+		t.Fatalf("rotation circuit: %v", err)
+	}
+	if values, _ := circuitOutput.Unpack(out); !bytes.Equal(values[0].([]byte), []byte{1}) {
+		t.Errorf("rotation circuit: have %v, want [1]", values[0])
+	}
+	init, _ := table.InitCost(denseWords(1))
+	if gas <= init {
+		t.Errorf("rotation circuit gas %d does not cover the register", gas)
+	}
+
+	// Несуществующий кубит и некорректная кодировка отклоняются
+	bad := []abiGate{{Name: "x", Qubits: []uint8{3}, Params: []*big.Int{}}}
+	input, _ = circuitInput.Pack(uint8(2), uint8(0), bad, [32]byte{})
+	if _, gas, err := runPrecompile(t, vm.QuantumCircuitAddress, input); !errors.Is(err, ErrQubitOutOfRange) || gas != table.InitGas {
+		t.Errorf("out of range qubit: have %v (gas %d)", err, gas)
+	}
+	if _, _, err := runPrecompile(t, vm.QuantumCircuitAddress, []byte{1, 2, 3}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("malformed input: want %v, have %v", ErrInvalidInput, err)
+	}
+}
+
+// Проверяет контракт QFT по преобразованию окружения
+func TestQFTPrecompile(t *testing.T) {
+	random := NewDeterministicRNG(common.Hash{2})
+	data := make([]complex128, 8)
+	re, im := make([]*big.Int, len(data)), make([]*big.Int, len(data))
+	for i := range data {
+		re[i], im[i] = fixed(random.Float64()*4-2), fixed(random.Float64()*4-2)
+		data[i] = complex(fromFixed(re[i]), fromFixed(im[i]))
+	}
+	input, _ := qftArguments.Pack(re, im)
+	out, gas, err := runPrecompile(t, vm.QuantumQFTAddress, input)
+	if err != nil {
+		t.Fatalf("qft: %v", err)
+	}
+	var have qftCall
+	if err := decodeInput(qftArguments, out, &have); err != nil {
+		t.Fatal(err)
+	}
+	env, _ := NewQuestEnv(1, false, 0)
+	want, _ := env.ExecuteQFT(data)
+	for i := range want {
+		v := complex(fromFixed(have.Real[i]), fromFixed(have.Imag[i]))
+		if cmplx.Abs(v-want[i]) > 1e-12 {
+			t.Errorf("component %d: have %v, want %v", i, v, want[i])
+		}
+	}
+	wantGas, _ := vm.QuantumGas(&params.QuantumGasTableQuantum, QQFT, 0, qevmArgs(0, 8*params.QuantumAmplitudeSize))
+	if gas != wantGas {
+		t.Errorf("qft gas: have %d, want %d", gas, wantGas)
+	}
+
+	input, _ = qftArguments.Pack(re[:3], im[:3])
+	if _, _, err := runPrecompile(t, vm.QuantumQFTAddress, input); err == nil {
+		t.Errorf("expected error for length 3")
+	}
+	input, _ = qftArguments.Pack(re, im[:4])
+	if _, _, err := runPrecompile(t, vm.QuantumQFTAddress, input); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("length mismatch: want %v, have %v", ErrInvalidInput, err)
+	}
+}
+
+// Проверяет, что контракт поиска Гровера находит отмеченные числа
+func TestGroverPrecompile(t *testing.T) {
+	tests := []struct {
+		space  uint64
+		marked []uint64
+	}{
+		{64, []uint64{42}},
+		{100, []uint64{99}},
+		{256, []uint64{3, 200, 3}},
+	}
+	for _, tt := range tests {
+		input, _ := groverInput.Pack(tt.space, tt.marked, [32]byte{byte(tt.space)})
+		out, gas, err := runPrecompile(t, vm.QuantumGroverAddress, input)
+		if err != nil {
+			t.Fatalf("grover %v: %v", tt.marked, err)
+		}
+		values, err := groverOutput.Unpack(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !values[1].(bool) {
+			t.Errorf("grover %v: not found, have %d", tt.marked, values[0])
+		}
+		if gas <= params.QuantumGasTableQuantum.GroverGas {
+			t.Errorf("grover %v: gas %d does not cover the search", tt.marked, gas)
+		}
+	}
+	// Стоимость с одним отмеченным числом совпадает с QGROVER
+	input, _ := groverInput.Pack(uint64(64), []uint64{7}, [32]byte{})
+	_, gas, _ := runPrecompile(t, vm.QuantumGroverAddress, input)
+	want, _ := vm.QuantumGas(&params.QuantumGasTableQuantum, QGROVER, 0, qevmArgs(0, 0, 64))
+	if gas != want {
+		t.Errorf("grover gas: have %d, want %d", gas, want)
+	}
+
+	input, _ = groverInput.Pack(uint64(10), []uint64{10}, [32]byte{})
+	if _, _, err := runPrecompile(t, vm.QuantumGroverAddress, input); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("marked outside space: want %v, have %v", ErrInvalidInput, err)
+	}
+	input, _ = groverInput.Pack(uint64(1)<<40, []uint64{1}, [32]byte{})
+	if _, _, err := runPrecompile(t, vm.QuantumGroverAddress, input); !errors.Is(err, ErrMaxQubitsExceeded) {
+		t.Errorf("too large space: want %v, have %v", ErrMaxQubitsExceeded, err)
+	}
+}
+
+// Проверяет детерминированность маяка случайности
+func TestRandomPrecompile(t *testing.T) {
+	call := func(seed byte, length int64) []byte {
+		input, _ := randomInput.Pack([32]byte{seed}, big.NewInt(length))
+		out, gas, err := runPrecompile(t, vm.QuantumRandomAddress, input)
+		if err != nil {
+			t.Fatalf("random: %v", err)
+		}
+		if want := params.QuantumGasTableQuantum.RandomGas + 24*uint64(length); gas != want {
+			t.Errorf("random gas: have %d, want %d", gas, want)
+		}
+		values, err := randomOutput.Unpack(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return values[0].([]byte)
+	}
+	a, b, c := call(1, 32), call(1, 32), call(2, 32)
+	if len(a) != 32 || !bytes.Equal(a, b) {
+		t.Errorf("same seed gave different bytes: %x, %x", a, b)
+	}
+	if bytes.Equal(a, c) {
+		t.Errorf("different seeds gave the same bytes %x", a)
+	}
+	if !bytes.Equal(call(1, 8), a[:8]) {
+		t.Errorf("prefix of the beacon depends on the length")
+	}
+	input, _ := randomInput.Pack([32]byte{}, new(big.Int).Lsh(big.NewInt(1), 64))
+	if gas := vm.PrecompiledContractsQuantum[vm.QuantumRandomAddress].RequiredGas(input); gas != math.MaxUint64 {
+		t.Errorf("huge length gas: have %d, want max", gas)
+	}
+}
@@ -0,0 +1,726 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Поддерживается подмножество OpenQASM 2.0 и 3.0, которое описывает схему
+// без классического управления: объявления регистров qreg/creg и qubit/bit,
+// вентили стандартных библиотек qelib1.inc и stdgates.inc из circuitGates,
+// measure, reset и barrier. Параметры вентилей - арифметические выражения над
+// числами и константами pi, tau и euler. Все регистры схемы объединяются в
+// один квантовый и один классический регистр в порядке объявления.
+// Определения вентилей, условия, циклы и модификаторы не поддерживаются.
+
+var (
+	// ErrQASMSyntax ошибка, возникающая при разборе некорректной программы
+	ErrQASMSyntax = errors.New("синтаксическая ошибка OpenQASM")
+
+	// ErrQASMUnsupported ошибка, возникающая при использовании конструкций
+	// OpenQASM, которые не поддерживаются
+	ErrQASMUnsupported = errors.New("неподдерживаемая конструкция OpenQASM")
+)
+
+// maxQASMClbits ограничивает размер классического регистра схемы
+const maxQASMClbits = 1 << 16
+
+// qasmGateAliases сопоставляет альтернативные имена вентилей именам из
+// circuitGates. Пустое имя обозначает тождественный вентиль.
+var qasmGateAliases = map[string]string{
+	"CX":     "cx",
+	"cnot":   "cx",
+	"U":      "u3",
+	"u1":     "p",
+	"phase":  "p",
+	"cu1":    "cp",
+	"cphase": "cp",
+	"id":     "",
+}
+
+// qasmIncludes - стандартные библиотеки, подключение которых допускается
+var qasmIncludes = map[string]bool{
+	"qelib1.inc":   true,
+	"stdgates.inc": true,
+}
+
+// qasmConstants - встроенные константы выражений
+var qasmConstants = map[string]float64{
+	"pi":    math.Pi,
+	"π":     math.Pi,
+	"tau":   2 * math.Pi,
+	"τ":     2 * math.Pi,
+	"euler": math.E,
+	"ℯ":     math.E,
+}
+
+// ParseQASM разбирает программу OpenQASM 2.0 или 3.0 в квантовую схему
+func ParseQASM(src string) (*Circuit, error) {
+	tokens, err := lexQASM(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &qasmParser{
+		tokens:  tokens,
+		qregs:   make(map[string]qasmRegister),
+		cregs:   make(map[string]qasmRegister),
+		circuit: new(Circuit),
+	}
+	if err := p.parseProgram(); err != nil {
+		return nil, err
+	}
+	if err := p.circuit.Validate(); err != nil {
+		return nil, err
+	}
+	return p.circuit, nil
+}
+
+// FormatQASM сериализует схему в программу OpenQASM указанной версии (2 или
+// 3). Регистры схемы называются q и c. Углы, кратные pi с небольшим
+// знаменателем, записываются через pi, остальные - кратчайшим десятичным
+// представлением, поэтому ParseQASM восстанавливает схему без потерь.
+func FormatQASM(c *Circuit, version int) (string, error) {
+	if version != 2 && version != 3 {
+		return "", fmt.Errorf("%w: версия %d", ErrQASMUnsupported, version)
+	}
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if version == 2 {
+		b.WriteString("OPENQASM 2.0;\ninclude \"qelib1.inc\";\n")
+		fmt.Fprintf(&b, "qreg q[%d];\n", c.NumQubits)
+		if c.NumClbits > 0 {
+			fmt.Fprintf(&b, "creg c[%d];\n", c.NumClbits)
+		}
+	} else {
+		b.WriteString("OPENQASM 3.0;\ninclude \"stdgates.inc\";\n")
+		fmt.Fprintf(&b, "qubit[%d] q;\n", c.NumQubits)
+		if c.NumClbits > 0 {
+			fmt.Fprintf(&b, "bit[%d] c;\n", c.NumClbits)
+		}
+	}
+	for _, g := range c.Gates {
+		switch {
+		case g.Name == "measure" && version == 2:
+			fmt.Fprintf(&b, "measure q[%d] -> c[%d];\n", g.Qubits[0], g.Clbit)
+		case g.Name == "measure":
+			fmt.Fprintf(&b, "c[%d] = measure q[%d];\n", g.Clbit, g.Qubits[0])
+		case g.Name == "reset":
+			b.WriteString("reset q;\n")
+		default:
+			name := g.Name
+			if version == 2 {
+				switch name {
+				case "p":
+					name = "u1"
+				case "cp":
+					name = "cu1"
+				}
+			}
+			b.WriteString(name)
+			if len(g.Params) > 0 {
+				params := make([]string, len(g.Params))
+				for i, theta := range g.Params {
+					params[i] = formatAngle(theta)
+				}
+				fmt.Fprintf(&b, "(%s)", strings.Join(params, ","))
+			}
+			for i, qubit := range g.Qubits {
+				if i == 0 {
+					b.WriteByte(' ')
+				} else {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(&b, "q[%d]", qubit)
+			}
+			b.WriteString(";\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// formatAngle записывает угол так, чтобы разбор записи давал то же значение
+// float64
+func formatAngle(theta float64) string {
+	if theta == 0 {
+		return "0"
+	}
+	for _, d := range []int64{1, 2, 3, 4, 6, 8, 12, 16, 32, 64} {
+		k := int64(math.Round(theta * float64(d) / math.Pi))
+		if k == 0 || float64(k)*math.Pi/float64(d) != theta {
+			continue
+		}
+		var b strings.Builder
+		switch k {
+		case 1:
+		case -1:
+			b.WriteByte('-')
+		default:
+			fmt.Fprintf(&b, "%d*", k)
+		}
+		b.WriteString("pi")
+		if d != 1 {
+			fmt.Fprintf(&b, "/%d", d)
+		}
+		return b.String()
+	}
+	return strconv.FormatFloat(theta, 'g', -1, 64)
+}
+
+// qasmTokenKind - вид лексемы OpenQASM
+type qasmTokenKind int
+
+const (
+	qasmEOF qasmTokenKind = iota
+	qasmIdent
+	qasmNumber
+	qasmString
+	qasmSymbol
+)
+
+// qasmToken - лексема программы с номером строки для сообщений об ошибках
+type qasmToken struct {
+	kind qasmTokenKind
+	text string
+	line int
+}
+
+// lexQASM разбивает программу на лексемы, пропуская пробелы и комментарии
+func lexQASM(src string) ([]qasmToken, error) {
+	var (
+		tokens []qasmToken
+		line   = 1
+	)
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: строка %d: незакрытый комментарий", ErrQASMSyntax, line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, qasmToken{qasmIdent, src[start:i], line})
+		case r >= '0' && r <= '9' || r == '.':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				i++
+				if i < len(src) && (src[i] == '+' || src[i] == '-') {
+					i++
+				}
+				for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+					i++
+				}
+			}
+			tokens = append(tokens, qasmToken{qasmNumber, src[start:i], line})
+		case r == '"':
+			end := strings.IndexAny(src[i+1:], "\"\n")
+			if end < 0 || src[i+1+end] != '"' {
+				return nil, fmt.Errorf("%w: строка %d: незакрытая строка", ErrQASMSyntax, line)
+			}
+			tokens = append(tokens, qasmToken{qasmString, src[i+1 : i+1+end], line})
+			i += end + 2
+		case strings.HasPrefix(src[i:], "->"):
+			tokens = append(tokens, qasmToken{qasmSymbol, "->", line})
+			i += 2
+		case strings.ContainsRune(";,[](){}+-*/=", r):
+			tokens = append(tokens, qasmToken{qasmSymbol, string(r), line})
+			i += size
+		default:
+			return nil, fmt.Errorf("%w: строка %d: недопустимый символ %q", ErrQASMSyntax, line, r)
+		}
+	}
+	return append(tokens, qasmToken{kind: qasmEOF, line: line}), nil
+}
+
+// qasmRegister - объявленный регистр, отображенный на отрезок объединенного
+// регистра схемы
+type qasmRegister struct {
+	offset int
+	size   int
+}
+
+// qasmOperand - операнд операции: отдельный кубит (бит) или весь регистр
+type qasmOperand struct {
+	indices  []int
+	register bool
+}
+
+// qasmParser разбирает последовательность лексем в схему
+type qasmParser struct {
+	tokens []qasmToken
+	pos    int
+
+	qregs   map[string]qasmRegister
+	cregs   map[string]qasmRegister
+	circuit *Circuit
+}
+
+// errorf возвращает ошибку с номером строки текущей лексемы
+func (p *qasmParser) errorf(kind error, format string, args ...any) error {
+	return fmt.Errorf("%w: строка %d: %s", kind, p.peek().line, fmt.Sprintf(format, args...))
+}
+
+func (p *qasmParser) peek() qasmToken {
+	return p.tokens[p.pos]
+}
+
+func (p *qasmParser) next() qasmToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != qasmEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept пропускает символ s, если он следует далее
+func (p *qasmParser) accept(s string) bool {
+	if tok := p.peek(); tok.kind == qasmSymbol && tok.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect требует, чтобы далее следовал символ s
+func (p *qasmParser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf(ErrQASMSyntax, "ожидается %q, найдено %q", s, p.peek().text)
+	}
+	return nil
+}
+
+// ident читает идентификатор
+func (p *qasmParser) ident() (string, error) {
+	tok := p.peek()
+	if tok.kind != qasmIdent {
+		return "", p.errorf(ErrQASMSyntax, "ожидается идентификатор, найдено %q", tok.text)
+	}
+	p.pos++
+	return tok.text, nil
+}
+
+// integer читает неотрицательное целое число
+func (p *qasmParser) integer() (int, error) {
+	tok := p.peek()
+	n, err := strconv.Atoi(tok.text)
+	if tok.kind != qasmNumber || err != nil || n < 0 {
+		return 0, p.errorf(ErrQASMSyntax, "ожидается целое число, найдено %q", tok.text)
+	}
+	p.pos++
+	return n, nil
+}
+
+// parseProgram разбирает заголовок и операторы программы
+func (p *qasmParser) parseProgram() error {
+	if tok := p.next(); tok.kind != qasmIdent || tok.text != "OPENQASM" {
+		return fmt.Errorf("%w: строка %d: программа должна начинаться с OPENQASM", ErrQASMSyntax, tok.line)
+	}
+	tok := p.next()
+	if tok.kind != qasmNumber {
+		return fmt.Errorf("%w: строка %d: ожидается версия", ErrQASMSyntax, tok.line)
+	}
+	if major, _, _ := strings.Cut(tok.text, "."); major != "2" && major != "3" {
+		return fmt.Errorf("%w: строка %d: версия %s", ErrQASMUnsupported, tok.line, tok.text)
+	}
+	if err := p.expect(";"); err != nil {
+		return err
+	}
+	for p.peek().kind != qasmEOF {
+		if err := p.parseStatement(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseStatement разбирает один оператор программы
+func (p *qasmParser) parseStatement() error {
+	keyword, err := p.ident()
+	if err != nil {
+		return err
+	}
+	switch keyword {
+	case "include":
+		tok := p.next()
+		if tok.kind != qasmString {
+			return p.errorf(ErrQASMSyntax, "ожидается имя файла")
+		}
+		if !qasmIncludes[tok.text] {
+			return p.errorf(ErrQASMUnsupported, "подключение %q", tok.text)
+		}
+		return p.expect(";")
+	case "qreg", "creg":
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		size, err := p.size()
+		if err != nil {
+			return err
+		}
+		if err := p.declare(name, size, keyword == "qreg"); err != nil {
+			return err
+		}
+		return p.expect(";")
+	case "qubit", "bit":
+		size := 1
+		if p.peek().text == "[" {
+			if size, err = p.size(); err != nil {
+				return err
+			}
+		}
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		if err := p.declare(name, size, keyword == "qubit"); err != nil {
+			return err
+		}
+		return p.expect(";")
+	case "measure":
+		qubits, err := p.operand(p.qregs)
+		if err != nil {
+			return err
+		}
+		if err := p.expect("->"); err != nil {
+			return err
+		}
+		clbits, err := p.operand(p.cregs)
+		if err != nil {
+			return err
+		}
+		if err := p.measure(qubits, clbits); err != nil {
+			return err
+		}
+		return p.expect(";")
+	case "reset":
+		qubits, err := p.operand(p.qregs)
+		if err != nil {
+			return err
+		}
+		if len(qubits.indices) != p.circuit.NumQubits {
+			return p.errorf(ErrQASMUnsupported, "сброс части кубитов")
+		}
+		p.circuit.Gates = append(p.circuit.Gates, Gate{Name: "reset", Qubits: qubits.indices})
+		return p.expect(";")
+	case "barrier":
+		// Барьер не влияет на состояние и пропускается
+		for !p.accept(";") {
+			if p.next().kind == qasmEOF {
+				return p.errorf(ErrQASMSyntax, "ожидается \";\"")
+			}
+		}
+		return nil
+	}
+	// Присваивание результата измерения в стиле OpenQASM 3: c = measure q;
+	if p.peek().text == "[" || p.peek().text == "=" {
+		p.pos--
+		clbits, err := p.operand(p.cregs)
+		if err != nil {
+			return err
+		}
+		if err := p.expect("="); err != nil {
+			return err
+		}
+		if tok := p.next(); tok.text != "measure" {
+			return p.errorf(ErrQASMUnsupported, "классическое выражение")
+		}
+		qubits, err := p.operand(p.qregs)
+		if err != nil {
+			return err
+		}
+		if err := p.measure(qubits, clbits); err != nil {
+			return err
+		}
+		return p.expect(";")
+	}
+	return p.parseGate(keyword)
+}
+
+// size читает размер регистра в квадратных скобках
+func (p *qasmParser) size() (int, error) {
+	if err := p.expect("["); err != nil {
+		return 0, err
+	}
+	size, err := p.integer()
+	if err != nil {
+		return 0, err
+	}
+	if size == 0 {
+		return 0, p.errorf(ErrQASMSyntax, "пустой регистр")
+	}
+	return size, p.expect("]")
+}
+
+// declare объявляет квантовый или классический регистр
+func (p *qasmParser) declare(name string, size int, quantum bool) error {
+	if _, ok := p.qregs[name]; ok {
+		return p.errorf(ErrQASMSyntax, "повторное объявление %s", name)
+	}
+	if _, ok := p.cregs[name]; ok {
+		return p.errorf(ErrQASMSyntax, "повторное объявление %s", name)
+	}
+	if quantum {
+		if p.circuit.NumQubits+size > MaxStabilizerQubits {
+			return p.errorf(ErrQASMUnsupported, "больше %d кубитов", MaxStabilizerQubits)
+		}
+		p.qregs[name] = qasmRegister{offset: p.circuit.NumQubits, size: size}
+		p.circuit.NumQubits += size
+		return nil
+	}
+	if p.circuit.NumClbits+size > maxQASMClbits {
+		return p.errorf(ErrQASMUnsupported, "больше %d классических битов", maxQASMClbits)
+	}
+	p.cregs[name] = qasmRegister{offset: p.circuit.NumClbits, size: size}
+	p.circuit.NumClbits += size
+	return nil
+}
+
+// operand читает ссылку на регистр из regs или на его элемент
+func (p *qasmParser) operand(regs map[string]qasmRegister) (qasmOperand, error) {
+	name, err := p.ident()
+	if err != nil {
+		return qasmOperand{}, err
+	}
+	reg, ok := regs[name]
+	if !ok {
+		return qasmOperand{}, p.errorf(ErrQASMSyntax, "необъявленный регистр %s", name)
+	}
+	if p.accept("[") {
+		index, err := p.integer()
+		if err != nil {
+			return qasmOperand{}, err
+		}
+		if index >= reg.size {
+			return qasmOperand{}, p.errorf(ErrQASMSyntax, "индекс %d вне регистра %s[%d]", index, name, reg.size)
+		}
+		return qasmOperand{indices: []int{reg.offset + index}}, p.expect("]")
+	}
+	op := qasmOperand{indices: make([]int, reg.size), register: true}
+	for i := range op.indices {
+		op.indices[i] = reg.offset + i
+	}
+	return op, nil
+}
+
+// measure добавляет измерения кубитов в классические биты. Регистры
+// измеряются поэлементно и должны иметь одинаковый размер.
+func (p *qasmParser) measure(qubits, clbits qasmOperand) error {
+	if len(qubits.indices) != len(clbits.indices) {
+		return p.errorf(ErrQASMSyntax, "размеры регистров измерения не совпадают")
+	}
+	for i, qubit := range qubits.indices {
+		p.circuit.Gates = append(p.circuit.Gates, Gate{Name: "measure", Qubits: []int{qubit}, Clbit: clbits.indices[i]})
+	}
+	return nil
+}
+
+// parseGate разбирает применение вентиля. Если операндами указаны целые
+// регистры, вентиль применяется к их элементам поэлементно.
+func (p *qasmParser) parseGate(name string) error {
+	switch name {
+	case "gate", "opaque", "def", "if", "for", "while", "let", "const", "input", "output", "ctrl", "negctrl", "inv", "pow":
+		return p.errorf(ErrQASMUnsupported, "%s", name)
+	}
+	gate := name
+	if alias, ok := qasmGateAliases[name]; ok {
+		gate = alias
+	}
+	info, ok := circuitGates[gate]
+	if gate != "" && !ok {
+		return p.errorf(ErrQASMUnsupported, "вентиль %s", name)
+	}
+	if gate == "" {
+		info = gateInfo{qubits: 1}
+	}
+	var params []float64
+	if p.accept("(") {
+		for {
+			v, err := p.expr()
+			if err != nil {
+				return err
+			}
+			params = append(params, v)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+	}
+	if len(params) != info.params {
+		return p.errorf(ErrQASMSyntax, "вентиль %s принимает %d параметров", name, info.params)
+	}
+	var (
+		operands []qasmOperand
+		width    = 1
+	)
+	for {
+		op, err := p.operand(p.qregs)
+		if err != nil {
+			return err
+		}
+		if op.register {
+			if width != 1 && len(op.indices) != width {
+				return p.errorf(ErrQASMSyntax, "размеры регистров вентиля %s не совпадают", name)
+			}
+			width = len(op.indices)
+		}
+		operands = append(operands, op)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if len(operands) != info.qubits {
+		return p.errorf(ErrQASMSyntax, "вентиль %s применяется к %d кубитам", name, info.qubits)
+	}
+	if err := p.expect(";"); err != nil {
+		return err
+	}
+	if gate == "" {
+		return nil
+	}
+	for i := 0; i < width; i++ {
+		g := Gate{Name: gate, Params: params}
+		for _, op := range operands {
+			qubit := op.indices[0]
+			if op.register {
+				qubit = op.indices[i]
+			}
+			if contains(g.Qubits, qubit) {
+				return p.errorf(ErrQASMSyntax, "вентиль %s применяется к кубиту %d дважды", name, qubit)
+			}
+			g.Qubits = append(g.Qubits, qubit)
+		}
+		p.circuit.Gates = append(p.circuit.Gates, g)
+	}
+	return nil
+}
+
+// contains проверяет наличие значения в срезе
+func contains(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// expr разбирает сумму: term (('+' | '-') term)*
+func (p *qasmParser) expr() (float64, error) {
+	v, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.accept("+"):
+			w, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			v += w
+		case p.accept("-"):
+			w, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			v -= w
+		default:
+			return v, nil
+		}
+	}
+}
+
+// term разбирает произведение: factor (('*' | '/') factor)*
+func (p *qasmParser) term() (float64, error) {
+	v, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.accept("*"):
+			w, err := p.factor()
+			if err != nil {
+				return 0, err
+			}
+			v *= w
+		case p.accept("/"):
+			w, err := p.factor()
+			if err != nil {
+				return 0, err
+			}
+			v /= w
+		default:
+			return v, nil
+		}
+	}
+}
+
+// factor разбирает число, константу, унарный знак или выражение в скобках
+func (p *qasmParser) factor() (float64, error) {
+	switch {
+	case p.accept("-"):
+		v, err := p.factor()
+		return -v, err
+	case p.accept("+"):
+		return p.factor()
+	case p.accept("("):
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		return v, p.expect(")")
+	}
+	tok := p.peek()
+	switch tok.kind {
+	case qasmNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return 0, p.errorf(ErrQASMSyntax, "некорректное число %q", tok.text)
+		}
+		p.pos++
+		return v, nil
+	case qasmIdent:
+		v, ok := qasmConstants[tok.text]
+		if !ok {
+			return 0, p.errorf(ErrQASMUnsupported, "идентификатор %s в выражении", tok.text)
+		}
+		p.pos++
+		return v, nil
+	}
+	return 0, p.errorf(ErrQASMSyntax, "ожидается выражение, найдено %q", tok.text)
+}
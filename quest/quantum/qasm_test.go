@@ -0,0 +1,287 @@
+package quantum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"math/cmplx"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Проверяет, что программы OpenQASM 2 и 3 разбираются в одну и ту же схему
+func TestParseQASMVersions(t *testing.T) {
+	qasm2 := `OPENQASM 2.0;
+include "qelib1.inc";
+// два регистра объединяются в один
+qreg a[2];
+qreg b[1];
+creg c[3];
+h a;
+id b[0];
+CX a[0],b[0];
+u1(-pi/4) b[0];
+rx(2*pi/3 + 0.5) a[1];
+barrier a, b;
+/* измерение
+   регистров */
+measure a[0] -> c[0];
+measure a[1] -> c[1];
+measure b -> c[2];
+`
+	qasm3 := `OPENQASM 3;
+include "stdgates.inc";
+qubit[2] a;
+qubit b;
+bit[3] c;
+h a;
+cx a[0], b;
+phase(-π/4) b;
+rx(τ/3 + 0.5) a[1];
+c[0] = measure a[0];
+c[1] = measure a[1];
+c[2] = measure b[0];
+`
+	// Выражение вычисляется в float64, как при разборе, а не как константа Go
+	pi := math.Pi
+	want := &Circuit{
+		NumQubits: 3,
+		NumClbits: 3,
+		Gates: []Gate{
+			{Name: "h", Qubits: []int{0}},
+			{Name: "h", Qubits: []int{1}},
+			{Name: "cx", Qubits: []int{0, 2}},
+			{Name: "p", Qubits: []int{2}, Params: []float64{-math.Pi / 4}},
+			{Name: "rx", Qubits: []int{1}, Params: []float64{2*pi/3 + 0.5}},
+			{Name: "measure", Qubits: []int{0}, Clbit: 0},
+			{Name: "measure", Qubits: []int{1}, Clbit: 1},
+			{Name: "measure", Qubits: []int{2}, Clbit: 2},
+		},
+	}
+	for name, src := range map[string]string{"qasm2": qasm2, "qasm3": qasm3} {
+		have, err := ParseQASM(src)
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %v", name, err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: circuit mismatch:\nhave %+v\nwant %+v", name, have, want)
+		}
+	}
+}
+
+// Проверяет, что сериализация и разбор схемы восстанавливают ее без потерь
+func TestQASMRoundTrip(t *testing.T) {
+	circuit := &Circuit{
+		NumQubits: 4,
+		NumClbits: 2,
+		Gates: []Gate{
+			{Name: "h", Qubits: []int{0}},
+			{Name: "sdg", Qubits: []int{1}},
+			{Name: "t", Qubits: []int{2}},
+			{Name: "p", Qubits: []int{3}, Params: []float64{-3 * math.Pi / 4}},
+			{Name: "rx", Qubits: []int{0}, Params: []float64{0.123456789}},
+			{Name: "ry", Qubits: []int{1}, Params: []float64{1e-9}},
+			{Name: "rz", Qubits: []int{2}, Params: []float64{2 * math.Pi / 3}},
+			{Name: "cz", Qubits: []int{3, 0}},
+			{Name: "u3", Qubits: []int{1}, Params: []float64{math.Pi / 2, -0.25, math.Pi}},
+			{Name: "cp", Qubits: []int{2, 3}, Params: []float64{math.Pi / 8}},
+			{Name: "cu", Qubits: []int{0, 2}, Params: []float64{0.5, 0, -math.Pi / 2, 1.5}},
+			{Name: "swap", Qubits: []int{1, 2}},
+			{Name: "ccx", Qubits: []int{0, 1, 3}},
+			{Name: "measure", Qubits: []int{3}, Clbit: 0},
+			{Name: "reset", Qubits: []int{0, 1, 2, 3}},
+			{Name: "y", Qubits: []int{2}},
+			{Name: "measure", Qubits: []int{2}, Clbit: 1},
+		},
+	}
+	for _, version := range []int{2, 3} {
+		src, err := FormatQASM(circuit, version)
+		if err != nil {
+			t.Fatalf("v%d: failed to format: %v", version, err)
+		}
+		have, err := ParseQASM(src)
+		if err != nil {
+			t.Fatalf("v%d: failed to parse:\n%s\n%v", version, src, err)
+		}
+		if !reflect.DeepEqual(have, circuit) {
+			t.Errorf("v%d: circuit mismatch:\n%s", version, src)
+		}
+	}
+	if _, err := FormatQASM(circuit, 4); !errors.Is(err, ErrQASMUnsupported) {
+		t.Errorf("unknown version: want %v, have %v", ErrQASMUnsupported, err)
+	}
+}
+
+// Проверяет отклонение некорректных и неподдерживаемых программ
+func TestParseQASMErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err error
+	}{
+		{`qreg q[1];`, ErrQASMSyntax},
+		{`OPENQASM 4.0; qreg q[1];`, ErrQASMUnsupported},
+		{`OPENQASM 2.0; include "custom.inc"; qreg q[1];`, ErrQASMUnsupported},
+		{`OPENQASM 2.0; qreg q[1]; h r[0];`, ErrQASMSyntax},
+		{`OPENQASM 2.0; qreg q[2]; h q[2];`, ErrQASMSyntax},
+		{`OPENQASM 2.0; qreg q[2]; cx q[0],q[0];`, ErrQASMSyntax},
+		{`OPENQASM 2.0; qreg q[2]; qreg r[3]; cx q,r;`, ErrQASMSyntax},
+		{`OPENQASM 2.0; qreg q[2]; rx q[0];`, ErrQASMSyntax},
+		{`OPENQASM 2.0; qreg q[2]; rx(theta) q[0];`, ErrQASMUnsupported},
+		{`OPENQASM 2.0; qreg q[2]; u2(0,0) q[0];`, ErrQASMUnsupported},
+		{`OPENQASM 2.0; qreg q[2]; cu1(0,0) q[0],q[1];`, ErrQASMSyntax},
+		{`OPENQASM 2.0; qreg q[2]; gate g a { h a; }`, ErrQASMUnsupported},
+		{`OPENQASM 2.0; qreg q[2]; creg c[2]; measure q -> c[0];`, ErrQASMSyntax},
+		{`OPENQASM 2.0; qreg q[2]; reset q[0];`, ErrQASMUnsupported},
+		{`OPENQASM 2.0; qreg q[1]; qreg q[1];`, ErrQASMSyntax},
+		{`OPENQASM 3.0; qubit q; h q; /* comment`, ErrQASMSyntax},
+		{`OPENQASM 3.0; bit c;`, ErrMaxQubitsExceeded},
+		{`OPENQASM 3.0; qubit[256] q;`, ErrQASMUnsupported},
+	}
+	for i, tt := range tests {
+		if _, err := ParseQASM(tt.src); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: want %v, have %v", i, tt.err, err)
+		}
+	}
+}
+
+// Проверяет вентили схемы, не являющиеся базовыми вентилями окружения
+func TestCircuitGates(t *testing.T) {
+	// Ry(theta)|0⟩ = cos(theta/2)|0⟩ + sin(theta/2)|1⟩
+	const theta = 0.7
+	env, _ := NewQuestEnv(1, false, 0)
+	if _, err := env.RunCircuit(&Circuit{NumQubits: 1, Gates: []Gate{{Name: "ry", Qubits: []int{0}, Params: []float64{theta}}}}); err != nil {
+		t.Fatalf("ry failed: %v", err)
+	}
+	assertSameState(t, "ry", env.GetStateVector(), []complex128{complex(math.Cos(theta/2), 0), complex(math.Sin(theta/2), 0)})
+
+	// Таблица истинности Тоффоли: целевой кубит 2 инвертируется при
+	// единичных управляющих кубитах 0 и 1
+	for input := 0; input < 8; input++ {
+		env, _ := NewQuestEnv(3, false, 0)
+		circuit := &Circuit{NumQubits: 3}
+		for q := 0; q < 3; q++ {
+			if input>>q&1 == 1 {
+				circuit.Gates = append(circuit.Gates, Gate{Name: "x", Qubits: []int{q}})
+			}
+		}
+		circuit.Gates = append(circuit.Gates, Gate{Name: "ccx", Qubits: []int{0, 1, 2}})
+		if _, err := env.RunCircuit(circuit); err != nil {
+			t.Fatalf("ccx failed: %v", err)
+		}
+		want := input
+		if input&3 == 3 {
+			want ^= 4
+		}
+		if amp, _ := env.GetAmplitude(uint64(want)); math.Abs(cmplx.Abs(amp)-1) > 1e-9 {
+			t.Errorf("ccx |%03b⟩: amplitude of |%03b⟩ is %v", input, want, amp)
+		}
+	}
+}
+
+// Проверяет, что записанная история окружения воспроизводится через OpenQASM
+func TestRecordedCircuitReplay(t *testing.T) {
+	seed := common.HexToHash("0xc0ffee")
+	env, _ := NewQuestEnvWithBackend(4, BackendAuto, false, 0, seed)
+	env.ApplyHadamard(0) // до начала записи
+	env.StartRecording()
+	env.ApplyCNOT(0, 1)
+	env.ApplyPhaseShift(1, math.Pi/2)
+	env.applyRotY(2, 0.4)
+	env.applyToffoli(0, 2, 3)
+	env.ApplySwap(1, 3)
+	first, _ := env.MeasureQubit(0)
+	env.StopRecording()
+	env.ApplyPauliX(0) // после окончания записи
+
+	circuit := env.Circuit()
+	if circuit.NumQubits != 4 || circuit.NumClbits != 4 || len(circuit.Gates) != 24 {
+		t.Fatalf("unexpected recorded circuit: %d qubits, %d clbits, %d gates", circuit.NumQubits, circuit.NumClbits, len(circuit.Gates))
+	}
+	src, err := FormatQASM(circuit, 3)
+	if err != nil {
+		t.Fatalf("failed to format: %v", err)
+	}
+	parsed, err := ParseQASM(src)
+	if err != nil {
+		t.Fatalf("failed to parse:\n%s\n%v", src, err)
+	}
+	// Повторяем схему на окружении с тем же начальным состоянием и зерном
+	replay, _ := NewQuestEnvWithBackend(4, BackendAuto, false, 0, seed)
+	replay.ApplyHadamard(0)
+	clbits, err := replay.RunCircuit(parsed)
+	if err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	if int(clbits[0]) != first {
+		t.Fatalf("measurement mismatch: have %d, want %d", clbits[0], first)
+	}
+	replay.ApplyPauliX(0)
+	assertSameState(t, "replay", replay.GetStateVector(), env.GetStateVector())
+}
+
+// Проверяет трансляцию схемы в инструкции QEVM и код контракта
+func TestCircuitCompile(t *testing.T) {
+	circuit, err := ParseQASM(`OPENQASM 2.0;
+qreg q[2];
+creg c[1];
+h q[0];
+cx q[0],q[1];
+rz(-pi/2) q[1];
+measure q[1] -> c[0];
+`)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	prog, err := circuit.Compile()
+	if err != nil {
+		t.Fatalf("failed to compile: %v", err)
+	}
+	want := []Instruction{
+		{Op: QINIT, Args: qevmArgs(2)},
+		{Op: QHADAMARD, Args: qevmArgs(0)},
+		{Op: QCNOT, Args: qevmArgs(1, 0)},
+		{Op: QPHASE, Args: qevmArgs(0xc000000000000000, 1)},
+		{Op: QMEASURE, Args: qevmArgs(1), Clbit: 0},
+	}
+	if !reflect.DeepEqual(prog, want) {
+		t.Fatalf("instruction mismatch:\nhave %v\nwant %v", prog, want)
+	}
+	code, err := circuit.Bytecode()
+	if err != nil {
+		t.Fatalf("failed to build bytecode: %v", err)
+	}
+	// PUSH1 2 QINIT; PUSH1 0 QHADAMARD; PUSH1 0 PUSH1 1 QCNOT;
+	// PUSH1 1 PUSH8 0xc000000000000000 QPHASE; PUSH1 1 QMEASURE PUSH1 0 MSTORE8;
+	// RETURN(0, 1)
+	wantCode := common.FromHex("6002e901" + "6000e910" + "60006001e920" + "600167c000000000000000e914" + "6001e928600053" + "60016000f3")
+	if !bytes.Equal(code, wantCode) {
+		t.Fatalf("bytecode mismatch:\nhave %x\nwant %x", code, wantCode)
+	}
+	if op := angleOperand(2 * math.Pi); op != 0 {
+		t.Errorf("full turn encoded as %d", op)
+	}
+	if _, err := (&Circuit{NumQubits: 1, Gates: []Gate{{Name: "u2", Qubits: []int{0}}}}).Compile(); !errors.Is(err, ErrUnsupportedGate) {
+		t.Errorf("unsupported gate: want %v, have %v", ErrUnsupportedGate, err)
+	}
+}
+
+// Проверяет прерывание схемы отмененным контекстом
+func TestRunCircuitContext(t *testing.T) {
+	env, _ := NewQuestEnv(1, false, 0)
+	circuit := &Circuit{NumQubits: 1, Gates: []Gate{{Name: "x", Qubits: []int{0}}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := env.RunCircuitContext(ctx, circuit); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if amp, _ := env.GetAmplitude(0); cmplx.Abs(amp) != 1 {
+		t.Fatal("cancelled circuit applied a gate")
+	}
+	if _, err := env.RunCircuitContext(context.Background(), circuit); err != nil {
+		t.Fatalf("circuit failed: %v", err)
+	}
+}
@@ -4,11 +4,9 @@ package quantum
 import (
 	"errors"
 	"fmt"
-	"math"
-	"math/cmplx"
-	"math/rand"
 	"sync"
-	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 var (
@@ -30,101 +28,133 @@ type QuestEnv struct {
 	// Количество кубитов в системе
 	numQubits int
 
-	// Квантовое состояние системы (амплитуды)
-	// Для n кубитов размер массива будет 2^n
-	state []complex128
-
-	// Матрицы базовых квантовых вентилей
-	hadamardGate      [][]complex128
-	pauliXGate        [][]complex128
-	pauliYGate        [][]complex128
-	pauliZGate        [][]complex128
-	cnotGate          [][][][]complex128
-	swapGate          [][][][]complex128
-	toffoliGate       [][][][][][]complex128
-	phaseShiftGate    map[float64][][]complex128
-	controlledUGate   map[string][][][]complex128
-	
+	// Представление квантового состояния и политика выбора представления.
+	// В режиме BackendAuto представление меняется по мере выполнения схемы.
+	backend stateBackend
+	mode    Backend
+
 	// Использование GPU
 	useGPU      bool
 	gpuDeviceID int
-	
+
 	// Мьютекс для потокобезопасности
 	mutex sync.Mutex
-	
-	// Генератор случайных чисел
-	random *rand.Rand
+
+	// Детерминированный генератор случайных чисел для измерений
+	random *DeterministicRNG
+
+	// Модель шума (nil - регистр без шума) и генератор выбора операторов
+	// ее каналов
+	noise       *NoiseModel
+	noiseRandom *DeterministicRNG
+
+	// История выполненных операций, записываемая после StartRecording
+	recording bool
+	history   []Gate
 }
 
-// NewQuestEnv создает новое квантовое окружение с заданным количеством кубитов
+// NewQuestEnv создает новое квантовое окружение с плотным вектором состояния
+// и заданным количеством кубитов. Генератор измерений инициализируется
+// нулевым зерном, для выполнения в контексте блока зерно задается через
+// SetMeasurementSeed.
 func NewQuestEnv(numQubits int, useGPU bool, gpuDeviceID int) (*QuestEnv, error) {
+	return NewQuestEnvWithSeed(numQubits, useGPU, gpuDeviceID, common.Hash{})
+}
+
+// NewQuestEnvWithSeed создает новое квантовое окружение с плотным вектором
+// состояния, генератор измерений которого инициализирован указанным зерном
+func NewQuestEnvWithSeed(numQubits int, useGPU bool, gpuDeviceID int, seed common.Hash) (*QuestEnv, error) {
+	return NewQuestEnvWithBackend(numQubits, BackendDense, useGPU, gpuDeviceID, seed)
+}
+
+// NewQuestEnvWithBackend создает новое квантовое окружение, состояние
+// которого хранится выбранным бэкендом. Максимальное количество кубитов
+// определяется бэкендом: MaxDenseQubits для плотного, MaxSparseQubits для
+// разреженного и MaxStabilizerQubits для стабилизаторного и автоматического.
+func NewQuestEnvWithBackend(numQubits int, backend Backend, useGPU bool, gpuDeviceID int, seed common.Hash) (*QuestEnv, error) {
 	if numQubits <= 0 {
 		return nil, fmt.Errorf("количество кубитов должно быть положительным")
 	}
+	state, err := newBackend(backend, numQubits)
+	if err != nil {
+		return nil, err
+	}
+	return newQuestEnv(state, numQubits, backend, useGPU, gpuDeviceID, seed), nil
+}
 
-	// Проверка максимального количества кубитов
-	// 25 кубитов = 2^25 = 33,554,432 комплексных амплитуд
-	if numQubits > 25 {
-		return nil, fmt.Errorf("превышено максимальное количество поддерживаемых кубитов (25)")
+// newQuestEnv создает окружение над готовым представлением состояния
+func newQuestEnv(state stateBackend, numQubits int, mode Backend, useGPU bool, gpuDeviceID int, seed common.Hash) *QuestEnv {
+	return &QuestEnv{
+		numQubits:   numQubits,
+		backend:     state,
+		mode:        mode,
+		useGPU:      useGPU,
+		gpuDeviceID: gpuDeviceID,
+		random:      NewDeterministicRNG(seed),
+		noiseRandom: NewDeterministicRNG(noiseSeed(seed)),
 	}
+}
 
-	// Инициализация квантовой системы
-	stateSize := 1 << numQubits // 2^numQubits
-	state := make([]complex128, stateSize)
-	
-	// Начальное состояние: |0...0⟩
-	state[0] = complex(1.0, 0.0)
+// GetQubitCount возвращает количество кубитов в системе
+func (q *QuestEnv) GetQubitCount() int {
+	return q.numQubits
+}
 
-	// Создаем новое окружение
-	env := &QuestEnv{
-		numQubits:        numQubits,
-		state:            state,
-		useGPU:           useGPU,
-		gpuDeviceID:      gpuDeviceID,
-		phaseShiftGate:   make(map[float64][][]complex128),
-		controlledUGate:  make(map[string][][][]complex128),
-		random:           rand.New(rand.NewSource(time.Now().UnixNano())),
-	}
+// Backend возвращает текущее представление состояния регистра
+func (q *QuestEnv) Backend() Backend {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
-	// Инициализация матриц вентилей
-	env.initializeGates()
+	if q.backend == nil {
+		return q.mode
+	}
+	return q.backend.kind()
+}
 
-	return env, nil
+// BackendMode возвращает политику выбора представления, заданную при
+// создании окружения
+func (q *QuestEnv) BackendMode() Backend {
+	return q.mode
 }
 
-// initializeGates инициализирует матрицы базовых квантовых вентилей
-func (q *QuestEnv) initializeGates() {
-	// Вентиль Адамара
-	h := 1 / math.Sqrt(2)
-	q.hadamardGate = [][]complex128{
-		{complex(h, 0), complex(h, 0)},
-		{complex(h, 0), complex(-h, 0)},
-	}
+// StateWords возвращает размер текущего представления состояния в
+// 32-байтовых словах
+func (q *QuestEnv) StateWords() uint64 {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
-	// Вентиль Паули-X (NOT)
-	q.pauliXGate = [][]complex128{
-		{complex(0, 0), complex(1, 0)},
-		{complex(1, 0), complex(0, 0)},
+	if q.backend == nil {
+		return 0
 	}
+	return q.backend.words()
+}
 
-	// Вентиль Паули-Y
-	q.pauliYGate = [][]complex128{
-		{complex(0, 0), complex(0, -1)},
-		{complex(0, 1), complex(0, 0)},
-	}
+// SetMeasurementSeed переинициализирует генераторы измерений и шума
+// указанным зерном, отключая регистр от потока, заданного UseRandomStream
+func (q *QuestEnv) SetMeasurementSeed(seed common.Hash) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
-	// Вентиль Паули-Z
-	q.pauliZGate = [][]complex128{
-		{complex(1, 0), complex(0, 0)},
-		{complex(0, 0), complex(-1, 0)},
-	}
+	q.random = NewDeterministicRNG(seed)
+	q.noiseRandom = NewDeterministicRNG(noiseSeed(seed))
+}
 
-	// Другие вентили инициализируются по запросу
+// UseRandomStream подключает к регистру генераторы потока s. Поток может
+// разделяться несколькими регистрами, его позиция сохраняется при замене
+// регистра.
+func (q *QuestEnv) UseRandomStream(s *RandomStream) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.random, q.noiseRandom = s.measure, s.noise
 }
 
-// GetQubitCount возвращает количество кубитов в системе
-func (q *QuestEnv) GetQubitCount() int {
-	return q.numQubits
+// MeasurementSeed возвращает текущее зерно генератора измерений
+func (q *QuestEnv) MeasurementSeed() common.Hash {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.random.Seed()
 }
 
 // Reset сбрасывает квантовое состояние в начальное |0...0⟩
@@ -132,506 +162,313 @@ func (q *QuestEnv) Reset() error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	// Очищаем все амплитуды
-	for i := range q.state {
-		q.state[i] = complex(0, 0)
+	if q.backend == nil {
+		return ErrInvalidQuantumState
 	}
-	
-	// Устанавливаем начальное состояние |0...0⟩
-	q.state[0] = complex(1.0, 0.0)
-	
+	q.reset()
 	return nil
 }
 
+// reset сбрасывает состояние без захвата мьютекса. В режиме BackendAuto
+// регистр возвращается к стабилизаторному представлению.
+func (q *QuestEnv) reset() {
+	if q.mode == BackendAuto && q.backend.kind() != BackendStabilizer {
+		q.backend = newStabilizerState(q.numQubits)
+	} else {
+		q.backend.reset()
+	}
+	q.recordReset()
+}
+
 // Destroy освобождает ресурсы квантового окружения
 func (q *QuestEnv) Destroy() error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
-	// Очищаем состояние и матрицы вентилей для освобождения памяти
-	q.state = nil
-	q.hadamardGate = nil
-	q.pauliXGate = nil
-	q.pauliYGate = nil
-	q.pauliZGate = nil
-	q.cnotGate = nil
-	q.swapGate = nil
-	q.toffoliGate = nil
-	q.phaseShiftGate = nil
-	q.controlledUGate = nil
-	
+
+	// Очищаем состояние для освобождения памяти. Регистр QuEST освобождается
+	// сразу, не дожидаясь сборщика мусора.
+	if state, ok := q.backend.(interface{ release() }); ok {
+		state.release()
+	}
+	q.backend = nil
+
 	return nil
 }
 
 // checkQubitIndex проверяет, что индекс кубита допустим
 func (q *QuestEnv) checkQubitIndex(qubit int) error {
+	if q.backend == nil {
+		return ErrInvalidQuantumState
+	}
 	if qubit < 0 || qubit >= q.numQubits {
 		return ErrQubitOutOfRange
 	}
 	return nil
 }
 
+// amplitudes возвращает представление вектором состояния, переводя в него
+// стабилизаторный регистр перед неклиффордовой операцией. Регистр,
+// ограниченный режимом BackendStabilizer, не переводится.
+func (q *QuestEnv) amplitudes() (amplitudeBackend, error) {
+	if state, ok := q.backend.(amplitudeBackend); ok {
+		return state, nil
+	}
+	if q.mode == BackendStabilizer {
+		return nil, ErrNotClifford
+	}
+	state, err := toAmplitudes(q.backend.(*stabilizerState), q.mode)
+	if err != nil {
+		return nil, err
+	}
+	q.backend = state
+	q.compact()
+	return q.backend.(amplitudeBackend), nil
+}
+
+// compact переводит разреженный регистр в плотный, если плотное
+// представление не больше разреженного. Выполняется в режимах BackendAuto и
+// BackendDense.
+func (q *QuestEnv) compact() {
+	sparse, ok := q.backend.(*sparseState)
+	if !ok || q.mode == BackendSparse || q.numQubits > MaxDenseQubits {
+		return
+	}
+	if q.mode == BackendDense || sparse.words() >= denseWords(q.numQubits) {
+		q.backend = sparse.toDense()
+	}
+}
+
+// reserveSparse переводит разреженный регистр в плотный перед вентилем,
+// который может удвоить носитель, если удвоенный носитель не помещается в
+// maxSparseEntries
+func (q *QuestEnv) reserveSparse() error {
+	sparse, ok := q.backend.(*sparseState)
+	if !ok || 2*sparse.entries() <= maxSparseEntries {
+		return nil
+	}
+	if q.mode == BackendSparse || q.numQubits > MaxDenseQubits {
+		return ErrBackendCapacity
+	}
+	q.backend = sparse.toDense()
+	return nil
+}
+
 // ApplyHadamard применяет вентиль Адамара к указанному кубиту
 func (q *QuestEnv) ApplyHadamard(qubit int) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
+
+	return q.applyHadamard(qubit)
+}
+
+// applyHadamard применяет вентиль Адамара без захвата мьютекса
+func (q *QuestEnv) applyHadamard(qubit int) error {
 	if err := q.checkQubitIndex(qubit); err != nil {
 		return err
 	}
-	
-	// Создаем временное хранилище для нового состояния
-	newState := make([]complex128, len(q.state))
-	
-	// Применяем вентиль Адамара к указанному кубиту
-	for i := 0; i < len(q.state); i++ {
-		// Определяем состояние указанного кубита в текущем базисном состоянии
-		bit := (i >> qubit) & 1
-		
-		// Индекс состояния с инвертированным битом
-		flipped := i ^ (1 << qubit)
-		
-		if bit == 0 {
-			// |0⟩ -> (|0⟩ + |1⟩)/√2
-			newState[i] += q.state[i] * q.hadamardGate[0][0]
-			newState[flipped] += q.state[i] * q.hadamardGate[0][1]
-		} else {
-			// |1⟩ -> (|0⟩ - |1⟩)/√2
-			newState[i ^ (1 << qubit)] += q.state[i] * q.hadamardGate[1][0]
-			newState[i] += q.state[i] * q.hadamardGate[1][1]
-		}
+	// Вентиль Адамара может удвоить носитель разреженного регистра
+	if err := q.reserveSparse(); err != nil {
+		return err
 	}
-	
-	// Обновляем состояние
-	q.state = newState
-	
-	return nil
+	q.backend.hadamard(qubit)
+	q.compact()
+	q.record("h", nil, qubit)
+	return q.applyNoise(qubit)
 }
 
 // ApplyPauliX применяет вентиль Паули-X (NOT) к указанному кубиту
 func (q *QuestEnv) ApplyPauliX(qubit int) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
+
 	if err := q.checkQubitIndex(qubit); err != nil {
 		return err
 	}
-	
-	// Вентиль Паули-X просто меняет местами амплитуды для |0⟩ и |1⟩
-	for i := 0; i < 1<<q.numQubits; i += 1<<(qubit+1) {
-		for j := 0; j < 1<<qubit; j++ {
-			idx0 := i + j
-			idx1 := i + j + (1 << qubit)
-			q.state[idx0], q.state[idx1] = q.state[idx1], q.state[idx0]
-		}
-	}
-	
-	return nil
+	q.backend.pauliX(qubit)
+	q.record("x", nil, qubit)
+	return q.applyNoise(qubit)
 }
 
 // ApplyPauliY применяет вентиль Паули-Y к указанному кубиту
 func (q *QuestEnv) ApplyPauliY(qubit int) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
+
 	if err := q.checkQubitIndex(qubit); err != nil {
 		return err
 	}
-	
-	// Создаем временное хранилище для нового состояния
-	newState := make([]complex128, len(q.state))
-	
-	for i := 0; i < len(q.state); i++ {
-		bit := (i >> qubit) & 1
-		flipped := i ^ (1 << qubit)
-		
-		if bit == 0 {
-			// |0⟩ -> i|1⟩
-			newState[flipped] += q.state[i] * q.pauliYGate[0][1]
-		} else {
-			// |1⟩ -> -i|0⟩
-			newState[flipped] += q.state[i] * q.pauliYGate[1][0]
-		}
-	}
-	
-	// Обновляем состояние
-	q.state = newState
-	
-	return nil
+	q.backend.pauliY(qubit)
+	q.record("y", nil, qubit)
+	return q.applyNoise(qubit)
 }
 
 // ApplyPauliZ применяет вентиль Паули-Z к указанному кубиту
 func (q *QuestEnv) ApplyPauliZ(qubit int) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
+
 	if err := q.checkQubitIndex(qubit); err != nil {
 		return err
 	}
-	
-	// Вентиль Паули-Z меняет фазу амплитуды для состояний, где указанный бит = 1
-	for i := 0; i < len(q.state); i++ {
-		bit := (i >> qubit) & 1
-		if bit == 1 {
-			q.state[i] = -q.state[i]
-		}
-	}
-	
-	return nil
+	q.backend.pauliZ(qubit)
+	q.record("z", nil, qubit)
+	return q.applyNoise(qubit)
 }
 
 // ApplyCNOT применяет вентиль CNOT (controlled-NOT) с управляющим и целевым кубитами
 func (q *QuestEnv) ApplyCNOT(control, target int) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
+
 	if err := q.checkQubitIndex(control); err != nil {
 		return err
 	}
-	
+
 	if err := q.checkQubitIndex(target); err != nil {
 		return err
 	}
-	
+
 	if control == target {
 		return fmt.Errorf("управляющий и целевой кубиты должны быть разными")
 	}
-	
-	// CNOT инвертирует целевой кубит, если управляющий кубит в состоянии |1⟩
-	for i := 0; i < len(q.state); i++ {
-		// Проверяем, что управляющий бит установлен
-		if ((i >> control) & 1) == 1 {
-			// Находим индекс состояния с инвертированным целевым битом
-			flipped := i ^ (1 << target)
-			q.state[i], q.state[flipped] = q.state[flipped], q.state[i]
-		}
-	}
-	
-	return nil
+	q.backend.cnot(control, target)
+	q.record("cx", nil, control, target)
+	return q.applyNoise(control, target)
 }
 
 // ApplySwap меняет местами состояния двух кубитов
 func (q *QuestEnv) ApplySwap(qubit1, qubit2 int) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
+
 	if err := q.checkQubitIndex(qubit1); err != nil {
 		return err
 	}
-	
+
 	if err := q.checkQubitIndex(qubit2); err != nil {
 		return err
 	}
-	
+
 	if qubit1 == qubit2 {
 		return nil // Нет эффекта при обмене кубита с самим собой
 	}
-	
-	// Обеспечиваем, что qubit1 < qubit2 для удобства
-	if qubit1 > qubit2 {
-		qubit1, qubit2 = qubit2, qubit1
-	}
-	
-	// Для каждого базисного состояния меняем местами биты
-	for i := 0; i < len(q.state); i++ {
-		bit1 := (i >> qubit1) & 1
-		bit2 := (i >> qubit2) & 1
-		
-		if bit1 != bit2 {
-			// Вычисляем новый индекс с инвертированными битами
-			j := i ^ (1 << qubit1) ^ (1 << qubit2)
-			q.state[i], q.state[j] = q.state[j], q.state[i]
-		}
-	}
-	
-	return nil
+	q.backend.swap(qubit1, qubit2)
+	q.record("swap", nil, qubit1, qubit2)
+	return q.applyNoise(qubit1, qubit2)
 }
 
-// ApplyPhaseShift применяет вентиль фазового сдвига к указанному кубиту
+// ApplyPhaseShift применяет вентиль фазового сдвига к указанному кубиту.
+// Сдвиги на углы, кратные pi/2, являются клиффордовыми и выполняются над
+// стабилизаторным регистром, остальные требуют вектора состояния.
 func (q *QuestEnv) ApplyPhaseShift(qubit int, theta float64) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
+
 	if err := q.checkQubitIndex(qubit); err != nil {
 		return err
 	}
-	
-	// Если такой фазовый вентиль еще не создан, создаем его
-	if _, ok := q.phaseShiftGate[theta]; !ok {
-		q.phaseShiftGate[theta] = [][]complex128{
-			{complex(1, 0), complex(0, 0)},
-			{complex(0, 0), cmplx.Rect(1, theta)},
+	if stab, ok := q.backend.(*stabilizerState); ok {
+		if k, clifford := cliffordPhase(theta); clifford {
+			for ; k > 0; k-- {
+				stab.phase(qubit)
+			}
+			q.record("p", []float64{theta}, qubit)
+			return q.applyNoise(qubit)
 		}
 	}
-	
-	// Применяем фазовый сдвиг: |1⟩ -> e^(i*theta)|1⟩
-	for i := 0; i < len(q.state); i++ {
-		bit := (i >> qubit) & 1
-		if bit == 1 {
-			q.state[i] *= q.phaseShiftGate[theta][1][1]
-		}
+	state, err := q.amplitudes()
+	if err != nil {
+		return err
 	}
-	
-	return nil
+	// Применяем фазовый сдвиг: |1⟩ -> e^(i*theta)|1⟩
+	state.phaseShift(qubit, theta)
+	q.record("p", []float64{theta}, qubit)
+	return q.applyNoise(qubit)
 }
 
 // MeasureQubit измеряет указанный кубит и возвращает результат (0 или 1)
 func (q *QuestEnv) MeasureQubit(qubit int) (int, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
+
+	return q.measureQubit(qubit)
+}
+
+// measureQubit измеряет кубит без захвата мьютекса
+func (q *QuestEnv) measureQubit(qubit int) (int, error) {
 	if err := q.checkQubitIndex(qubit); err != nil {
 		return -1, err
 	}
-	
-	// Вычисляем вероятность измерения |1⟩
-	prob1 := 0.0
-	for i := 0; i < len(q.state); i++ {
-		if ((i >> qubit) & 1) == 1 {
-			prob1 += cmplx.Abs(q.state[i]) * cmplx.Abs(q.state[i])
-		}
-	}
-	
-	// Вероятность измерения |0⟩
-	prob0 := 1.0 - prob1
-	
-	// Генерируем случайное число для определения результата измерения
-	r := q.random.Float64()
-	
-	var result int
-	if r < prob1 {
-		result = 1
-	} else {
-		result = 0
-	}
-	
-	// Коллапсируем состояние в соответствии с результатом измерения
-	norm := 0.0
-	for i := 0; i < len(q.state); i++ {
-		bit := (i >> qubit) & 1
-		if bit != result {
-			q.state[i] = complex(0, 0)
-		} else {
-			norm += cmplx.Abs(q.state[i]) * cmplx.Abs(q.state[i])
-		}
-	}
-	
-	// Нормализуем состояние
-	norm = math.Sqrt(norm)
-	if norm > 0 {
-		for i := 0; i < len(q.state); i++ {
-			q.state[i] /= complex(norm, 0)
-		}
-	}
-	
-	return result, nil
+	result := q.backend.measure(qubit, q.random)
+	q.compact()
+	q.record("measure", nil, qubit)
+	return q.readout(result), nil
 }
 
-// MeasureAllQubits измеряет все кубиты и возвращает результат как целое число
+// MeasureAllQubits измеряет все кубиты и возвращает результат как целое
+// число. В результат входят младшие 64 кубита регистра.
 func (q *QuestEnv) MeasureAllQubits() (uint64, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
-	// Вычисляем вероятности всех базисных состояний
-	probabilities := make([]float64, len(q.state))
-	for i := 0; i < len(q.state); i++ {
-		probabilities[i] = cmplx.Abs(q.state[i]) * cmplx.Abs(q.state[i])
+
+	if q.backend == nil {
+		return 0, ErrInvalidQuantumState
 	}
-	
-	// Выбираем результат на основе вероятностей
-	r := q.random.Float64()
-	cumulative := 0.0
-	var result uint64
-	
-	for i := 0; i < len(probabilities); i++ {
-		cumulative += probabilities[i]
-		if r < cumulative {
-			result = uint64(i)
-			break
+	result := q.backend.measureAll(q.random)
+	q.compact()
+	for qubit := 0; qubit < q.numQubits; qubit++ {
+		q.record("measure", nil, qubit)
+		if qubit < 64 {
+			result ^= uint64(q.readout(0)) << qubit
 		}
 	}
-	
-	// Коллапсируем состояние в выбранный базисный вектор
-	for i := range q.state {
-		q.state[i] = complex(0, 0)
-	}
-	q.state[result] = complex(1, 0)
-	
 	return result, nil
 }
 
-// GetStateVector возвращает копию текущего вектора состояния
+// GetStateVector возвращает копию текущего вектора состояния. Для регистров
+// больше MaxDenseQubits кубитов вектор не строится и возвращается nil.
 func (q *QuestEnv) GetStateVector() []complex128 {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
-	// Создаем копию для безопасного доступа извне
-	stateCopy := make([]complex128, len(q.state))
-	copy(stateCopy, q.state)
-	
-	return stateCopy
-}
 
-// GetAmplitude возвращает амплитуду указанного базисного состояния
-func (q *QuestEnv) GetAmplitude(basisState uint64) (complex128, error) {
-	q.mutex.Lock()
-	defer q.mutex.Unlock()
-	
-	if basisState >= uint64(len(q.state)) {
-		return complex(0, 0), fmt.Errorf("недопустимое базисное состояние")
-	}
-	
-	return q.state[basisState], nil
-}
-
-// ExecuteShorAlgorithm выполняет алгоритм Шора для факторизации числа
-func (q *QuestEnv) ExecuteShorAlgorithm(n uint64) ([]uint64, error) {
-	q.mutex.Lock()
-	defer q.mutex.Unlock()
-	
-	// Проверка входных данных
-	if n < 4 {
-		return nil, fmt.Errorf("число должно быть больше 3")
-	}
-	
-	if n%2 == 0 {
-		return []uint64{2, n / 2}, nil // Тривиальный случай - четное число
-	}
-	
-	// Проверка, что число не является простым
-	isPrime := true
-	limit := uint64(math.Sqrt(float64(n)))
-	for i := uint64(3); i <= limit; i += 2 {
-		if n%i == 0 {
-			isPrime = false
-			break
-		}
-	}
-	
-	if isPrime {
-		return nil, fmt.Errorf("входное число %d является простым", n)
-	}
-	
-	// Реализация алгоритма Шора
-	// Для упрощения, используем вероятностный подход
-	
-	// Выбираем случайное число a, взаимно простое с n
-	var a uint64
-	for {
-		a = uint64(q.random.Int63n(int64(n-2))) + 2
-		if gcd(a, n) == 1 {
-			break
-		}
-	}
-	
-	// Находим период функции f(x) = a^x mod n
-	r, err := q.findPeriod(a, n)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Если период нечетный или a^(r/2) = -1 (mod n), выбираем другое a
-	if r%2 != 0 {
-		return q.ExecuteShorAlgorithm(n) // Рекурсивно повторяем с новым a
-	}
-	
-	// Вычисляем a^(r/2) mod n
-	power := powMod(a, r/2, n)
-	if power == n-1 {
-		return q.ExecuteShorAlgorithm(n) // Рекурсивно повторяем с новым a
-	}
-	
-	// Находим делители
-	factor1 := gcd(power+1, n)
-	factor2 := gcd(power-1, n)
-	
-	if factor1 == 1 || factor1 == n {
-		factor1 = factor2
-	}
-	
-	factor2 = n / factor1
-	
-	return []uint64{factor1, factor2}, nil
-}
-
-// findPeriod находит период функции f(x) = a^x mod n
-func (q *QuestEnv) findPeriod(a, n uint64) (uint64, error) {
-	// В реальном квантовом компьютере это бы использовало квантовые схемы
-	// Здесь мы симулируем классически для наглядности
-	
-	// Простой алгоритм для нахождения периода
-	period := uint64(1)
-	value := a % n
-	for value != 1 {
-		value = (value * a) % n
-		period++
-		
-		// Предотвращение бесконечного цикла
-		if period > n {
-			return 0, fmt.Errorf("не удалось найти период")
+	if q.backend == nil || q.numQubits > MaxDenseQubits {
+		return nil
+	}
+	switch state := q.backend.(type) {
+	case vectorBackend:
+		// Копия для безопасного доступа извне
+		return state.vector()
+	case *sparseState:
+		return state.toVector()
+	case *stabilizerState:
+		converted, err := toAmplitudes(state, BackendDense)
+		if err != nil {
+			return nil
 		}
+		return converted.(vectorBackend).vector()
 	}
-	
-	return period, nil
+	return nil
 }
 
-// ExecuteGroverAlgorithm выполняет алгоритм Гровера для поиска
-func (q *QuestEnv) ExecuteGroverAlgorithm(target []byte, searchSpace uint64) ([]byte, error) {
+// GetAmplitude возвращает амплитуду указанного базисного состояния
+func (q *QuestEnv) GetAmplitude(basisState uint64) (complex128, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
-	// Проверка входных данных
-	if len(target) == 0 {
-		return nil, fmt.Errorf("целевые данные не могут быть пустыми")
-	}
-	
-	if searchSpace == 0 {
-		return nil, fmt.Errorf("пространство поиска не может быть нулевым")
-	}
-	
-	// Для упрощения, возвращаем случайный результат в указанном диапазоне
-	// Это симуляция результата алгоритма Гровера
-	result := make([]byte, len(target))
-	_, err := q.random.Read(result)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Небольшая вероятность найти правильный результат
-	if q.random.Float64() < 0.05 {
-		copy(result, target)
-	}
-	
-	return result, nil
-}
 
-// ExecuteQFT выполняет квантовое преобразование Фурье
-func (q *QuestEnv) ExecuteQFT(data []complex128) ([]complex128, error) {
-	q.mutex.Lock()
-	defer q.mutex.Unlock()
-	
-	// Проверка входных данных
-	n := len(data)
-	if n == 0 {
-		return nil, fmt.Errorf("входные данные не могут быть пустыми")
-	}
-	
-	if !isPowerOfTwo(n) {
-		return nil, fmt.Errorf("длина входных данных должна быть степенью двойки")
+	if q.backend == nil || (q.numQubits < 64 && basisState >= 1<<q.numQubits) {
+		return complex(0, 0), fmt.Errorf("недопустимое базисное состояние")
 	}
-	
-	// Реализация QFT с использованием ДПФ
-	result := make([]complex128, n)
-	for i := 0; i < n; i++ {
-		sum := complex(0, 0)
-		for j := 0; j < n; j++ {
-			angle := -2.0 * math.Pi * float64(i*j) / float64(n)
-			sum += data[j] * cmplx.Rect(1, angle)
+	state, ok := q.backend.(amplitudeBackend)
+	if !ok {
+		// Стабилизаторный регистр не изменяется, амплитуда вычисляется по
+		// временному вектору состояния
+		converted, err := toAmplitudes(q.backend.(*stabilizerState), BackendSparse)
+		if err != nil {
+			return complex(0, 0), err
 		}
-		result[i] = sum / complex(math.Sqrt(float64(n)), 0)
+		state = converted
 	}
-	
-	return result, nil
+	return state.amplitude(basisState), nil
 }
 
 // ExecuteQPE выполняет квантовое оценивание фазы
@@ -662,6 +499,9 @@ func (q *QuestEnv) GenerateQuantumRandomBytes(length int) ([]byte, error) {
 	if length <= 0 {
 		return nil, fmt.Errorf("длина должна быть положительной")
 	}
+	if q.backend == nil {
+		return nil, ErrInvalidQuantumState
+	}
 	
 	// Генерация случайных байтов
 	randomBytes := make([]byte, length)
@@ -672,17 +512,16 @@ func (q *QuestEnv) GenerateQuantumRandomBytes(length int) ([]byte, error) {
 		for bit := 0; bit < 8; bit++ {
 			// Подготавливаем кубит в суперпозиции
 			qubit := i % q.numQubits
-			q.state = make([]complex128, 1<<q.numQubits)
-			q.state[0] = complex(1, 0)
+			q.reset()
 			
 			// Применяем вентиль Адамара
-			err := q.ApplyHadamard(qubit)
+			err := q.applyHadamard(qubit)
 			if err != nil {
 				return nil, err
 			}
 			
 			// Измеряем кубит
-			result, err := q.MeasureQubit(qubit)
+			result, err := q.measureQubit(qubit)
 			if err != nil {
 				return nil, err
 			}
@@ -2,48 +2,59 @@
 package quantum
 
 import (
+	"encoding/binary"
 	"errors"
-	"fmt"
-	"math/big"
+	"math"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
 )
 
-// OpCode представляет квантовые опкоды
-type OpCode byte
+// OpCode представляет квантовые инструкции. Кодировка определяется единым
+// реестром vm.QuantumOp: инструкция записывается как префикс vm.QUANTUM и
+// следующий за ним байт подкода.
+type OpCode = vm.QuantumOp
 
 // Квантовые опкоды
 const (
 	// Базовые квантовые операции
-	QINIT         OpCode = 0xf0 // Инициализация квантового регистра
-	QDESTROY      OpCode = 0xf1 // Уничтожение квантового регистра
-	QRESET        OpCode = 0xf2 // Сброс квантового регистра
+	QINIT    = vm.QINIT    // Инициализация квантового регистра
+	QDESTROY = vm.QDESTROY // Уничтожение квантового регистра
+	QRESET   = vm.QRESET   // Сброс квантового регистра
 
 	// Квантовые вентили
-	QHADAMARD     OpCode = 0xf3 // Вентиль Адамара
-	QPAULIX       OpCode = 0xf4 // Вентиль Паули X (NOT)
-	QPAULIY       OpCode = 0xf5 // Вентиль Паули Y
-	QPAULIZ       OpCode = 0xf6 // Вентиль Паули Z
-	QPHASE        OpCode = 0xf7 // Фазовый вентиль
-	QROTX         OpCode = 0xf8 // Вращение вокруг оси X
-	QROTY         OpCode = 0xf9 // Вращение вокруг оси Y
-	QROTZ         OpCode = 0xfa // Вращение вокруг оси Z
-	QCNOT         OpCode = 0xfb // Контролируемый NOT
-	QSWAP         OpCode = 0xfc // Обмен состояниями кубитов
-	QTOFFOLI      OpCode = 0xfd // Вентиль Тоффоли
+	QHADAMARD = vm.QHADAMARD // Вентиль Адамара
+	QPAULIX   = vm.QPAULIX   // Вентиль Паули X (NOT)
+	QPAULIY   = vm.QPAULIY   // Вентиль Паули Y
+	QPAULIZ   = vm.QPAULIZ   // Вентиль Паули Z
+	QPHASE    = vm.QPHASE    // Фазовый вентиль
+	QROTX     = vm.QROTX     // Вращение вокруг оси X
+	QROTY     = vm.QROTY     // Вращение вокруг оси Y
+	QROTZ     = vm.QROTZ     // Вращение вокруг оси Z
+	QU3       = vm.QU3       // Произвольный однокубитный вентиль U3
+	QCNOT     = vm.QCNOT     // Контролируемый NOT
+	QSWAP     = vm.QSWAP     // Обмен состояниями кубитов
+	QTOFFOLI  = vm.QTOFFOLI  // Вентиль Тоффоли
+	QCZ       = vm.QCZ       // Контролируемый Z
+	QCPHASE   = vm.QCPHASE   // Контролируемый фазовый сдвиг
+	QISWAP    = vm.QISWAP    // Обмен состояниями с фазой i
+	QCU       = vm.QCU       // U3 с произвольным набором управляющих кубитов
 
 	// Квантовые измерения
-	QMEASURE      OpCode = 0xfe // Измерение кубита
-	QMEASUREALL   OpCode = 0xff // Измерение всех кубитов
+	QMEASURE    = vm.QMEASURE    // Измерение кубита
+	QMEASUREALL = vm.QMEASUREALL // Измерение всех кубитов
 
 	// Квантовые алгоритмы
-	QSHOR         OpCode = 0xe0 // Алгоритм Шора для факторизации
-	QGROVER       OpCode = 0xe1 // Алгоритм Гровера для поиска
-	QQFT          OpCode = 0xe2 // Квантовое преобразование Фурье
-	QQPE          OpCode = 0xe3 // Квантовое оценивание фазы
-	QRANDOM       OpCode = 0xe4 // Квантовый генератор случайных чисел
+	QSHOR   = vm.QSHOR   // Алгоритм Шора для факторизации
+	QGROVER = vm.QGROVER // Алгоритм Гровера для поиска
+	QQFT    = vm.QQFT    // Квантовое преобразование Фурье
+	QQPE    = vm.QQPE    // Квантовое оценивание фазы
+	QRANDOM = vm.QRANDOM // Квантовый генератор случайных чисел
 )
 
 // Ошибки при выполнении квантовых операций
@@ -57,6 +68,7 @@ var (
 	ErrInvalidControlTarget   = errors.New("управляющий и целевой кубиты совпадают")
 	ErrGasLimitExceeded       = errors.New("превышен лимит газа для квантовой операции")
 	ErrQRegisterNotAvailable  = errors.New("квантовый регистр недоступен")
+	ErrInvalidInput           = errors.New("недопустимый операнд квантовой операции")
 )
 
 // QEVMContext представляет контекст для выполнения квантовых операций в EVM
@@ -67,8 +79,8 @@ type QEVMContext struct {
 	// Контекст выполнения EVM
 	evm *vm.EVM
 
-	// Стоимость выполнения квантовых операций в газе
-	gasTable map[OpCode]uint64
+	// Стоимость квантовых операций по правилам текущего форка
+	gasTable *params.QuantumGasTable
 
 	// Мьютекс для синхронизации доступа
 	mutex sync.Mutex
@@ -76,75 +88,102 @@ type QEVMContext struct {
 	// Флаг, указывающий, активно ли квантовое окружение
 	active bool
 
-	// Максимальное количество кубитов
+	// Максимальное количество кубитов регистра, правило форка
 	maxQubits int
 
+	// Бэкенд, которым создаются и преобразуются регистры, правило форка
+	backend Backend
+
 	// Адрес контракта, использующего квантовое окружение
 	contractAddress common.Address
+
+	// Контекст блока и кадра вызова, из которого выводится зерно генератора
+	// измерений
+	seed MeasurementSeed
+
+	// Потоки случайных чисел кадров вызова текущей транзакции по зерну.
+	// Позиция потока не сбрасывается в пределах транзакции.
+	streams map[common.Hash]*RandomStream
+
+	// Декодированные регистры контрактов, проиндексированные по адресу.
+	// Запись действительна, пока ее обязательство совпадает с хранимым в
+	// состоянии аккаунта. Изменения регистра накапливаются в записи и
+	// записываются в состояние один раз за кадр вызова, когда EVM вызывает
+	// FlushQuantumRegisters.
+	registers map[common.Address]*cachedRegister
+
+	// Адреса регистров с незаписанными изменениями
+	dirty []common.Address
+}
+
+// cachedRegister хранит декодированный регистр вместе с обязательством, из
+// которого он получен
+type cachedRegister struct {
+	hash common.Hash
+	env  *QuestEnv // nil, если у контракта нет регистра
+
+	// Регистр изменен после последней записи в состояние, и запись на paid
+	// слов уже оплачена
+	dirty bool
+	paid  uint64
+}
+
+// BlockMeasurementSeed возвращает зерно измерений, выводимое из блока evm:
+// значение PREVRANDAO или, если задано, фиксированное зерно
+// vm.Config.QuestMeasurementSeed. Остальные поля заполняются для каждого
+// кадра вызова в EnterFrame.
+func BlockMeasurementSeed(evm *vm.EVM) MeasurementSeed {
+	var seed MeasurementSeed
+	switch {
+	case evm.Config.QuestMeasurementSeed != nil:
+		seed.PrevRandao = *evm.Config.QuestMeasurementSeed
+	case evm.Context.Random != nil:
+		seed.PrevRandao = *evm.Context.Random
+	}
+	return seed
 }
 
-// NewQEVMContext создает новый контекст для выполнения квантовых операций в EVM
-func NewQEVMContext(evm *vm.EVM, maxQubits int, useGPU bool, gpuDeviceID int) (*QEVMContext, error) {
+// NewQEVMContext создает новый контекст для выполнения квантовых операций в
+// EVM. Стоимость инструкций и максимальный размер регистра определяются
+// правилами форка блока evm, а не настройками узла.
+func NewQEVMContext(evm *vm.EVM, useGPU bool, gpuDeviceID int) (*QEVMContext, error) {
 	// Проверка параметров
 	if evm == nil {
 		return nil, errors.New("EVM не может быть nil")
 	}
 
-	if maxQubits <= 0 {
-		return nil, fmt.Errorf("количество кубитов должно быть положительным числом")
-	}
+	// Зерно измерений по умолчанию выводится из PREVRANDAO блока, остальные
+	// поля задаются вызывающей стороной через EnterFrame
+	seed := BlockMeasurementSeed(evm)
 
-	// Создание квантового окружения
-	env, err := NewQuestEnv(maxQubits, useGPU, gpuDeviceID)
-	if err != nil {
-		return nil, err
-	}
+	// Квантовое окружение не создается заранее: регистр контракта
+	// загружается из состояния при первой квантовой инструкции
 
-	// Создание таблицы стоимости газа для квантовых операций
-	gasTable := make(map[OpCode]uint64)
-	initGasTable(gasTable)
+	// Таблица стоимости газа определяется форком блока. До активации форка
+	// Quantum таблицы нет, как и самих квантовых инструкций.
+	rules := evm.ChainConfig().Rules(evm.Context.BlockNumber, evm.Context.Random != nil, evm.Context.Time)
+	gasTable := params.QuantumGasTableFor(rules)
 
+	var maxQubits int
+	if gasTable != nil {
+		maxQubits = int(min(gasTable.MaxQubits, MaxRegisterQubits))
+	}
 	return &QEVMContext{
-		env:           env,
 		evm:           evm,
 		gasTable:      gasTable,
 		active:        true,
 		maxQubits:     maxQubits,
+		backend:       ConsensusBackend,
+		seed:          seed,
+		streams:       make(map[common.Hash]*RandomStream),
+		registers:     make(map[common.Address]*cachedRegister),
 	}, nil
 }
 
-// initGasTable инициализирует таблицу стоимости газа для квантовых операций
-func initGasTable(gasTable map[OpCode]uint64) {
-	// Базовые операции
-	gasTable[QINIT] = 5000
-	gasTable[QDESTROY] = 1000
-	gasTable[QRESET] = 2000
-
-	// Квантовые вентили (одно-кубитные)
-	gasTable[QHADAMARD] = 100
-	gasTable[QPAULIX] = 100
-	gasTable[QPAULIY] = 100
-	gasTable[QPAULIZ] = 100
-	gasTable[QPHASE] = 150
-	gasTable[QROTX] = 200
-	gasTable[QROTY] = 200
-	gasTable[QROTZ] = 200
-
-	// Квантовые вентили (много-кубитные)
-	gasTable[QCNOT] = 300
-	gasTable[QSWAP] = 300
-	gasTable[QTOFFOLI] = 500
-
-	// Измерения
-	gasTable[QMEASURE] = 200
-	gasTable[QMEASUREALL] = 1000
-
-	// Алгоритмы (высокоуровневые операции)
-	gasTable[QSHOR] = 50000
-	gasTable[QGROVER] = 30000
-	gasTable[QQFT] = 10000
-	gasTable[QQPE] = 15000
-	gasTable[QRANDOM] = 5000
+// MaxQubits возвращает максимальный размер регистра по правилам форка, 0
+// до активации форка Quantum
+func (q *QEVMContext) MaxQubits() int {
+	return q.maxQubits
 }
 
 // IsActive проверяет, активно ли квантовое окружение
@@ -152,7 +191,7 @@ func (q *QEVMContext) IsActive() bool {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 	
-	return q.active && q.env != nil
+	return q.active
 }
 
 // SetContractAddress устанавливает адрес контракта, использующего квантовое окружение
@@ -163,6 +202,46 @@ func (q *QEVMContext) SetContractAddress(addr common.Address) {
 	q.contractAddress = addr
 }
 
+// EnterFrame делает текущим кадр вызова контракта addr на глубине depth
+// транзакции txHash и возвращает контекст вызывающего кадра, который
+// передается в LeaveFrame при возврате. Регистры кадра используют поток
+// случайных чисел, зерно которого выводится из PREVRANDAO, хэша транзакции,
+// адреса и глубины, поэтому QMEASURE и QRANDOM дают одинаковые результаты на
+// всех узлах. Потоки сбрасываются только при переходе к новой транзакции.
+func (q *QEVMContext) EnterFrame(txHash common.Hash, addr common.Address, depth int) MeasurementSeed {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	caller := q.seed
+	if depth == 0 || txHash != q.seed.TxHash {
+		q.streams = make(map[common.Hash]*RandomStream)
+	}
+	q.seed.TxHash = txHash
+	q.seed.Contract = addr
+	q.seed.Depth = depth
+	q.contractAddress = addr
+	return caller
+}
+
+// LeaveFrame восстанавливает контекст вызывающего кадра, возвращенный
+// EnterFrame. Поток вызывающего кадра продолжается с позиции, на которой
+// был сделан вызов.
+func (q *QEVMContext) LeaveFrame(caller MeasurementSeed) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.seed = caller
+	q.contractAddress = caller.Contract
+}
+
+// GetMeasurementSeed возвращает контекст, из которого выводится зерно измерений
+func (q *QEVMContext) GetMeasurementSeed() MeasurementSeed {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.seed
+}
+
 // GetContractAddress возвращает адрес контракта, использующего квантовое окружение
 func (q *QEVMContext) GetContractAddress() common.Address {
 	q.mutex.Lock()
@@ -176,6 +255,8 @@ func (q *QEVMContext) Destroy() error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 	
+	q.registers = make(map[common.Address]*cachedRegister)
+	q.dirty = nil
 	if q.env != nil {
 		err := q.env.Destroy()
 		q.env = nil
@@ -186,105 +267,412 @@ func (q *QEVMContext) Destroy() error {
 	return nil
 }
 
-// ExecuteOp выполняет квантовую операцию
-func (q *QEVMContext) ExecuteOp(opcode OpCode, stack *vm.Stack, memory *vm.Memory, interpreter *vm.EVMInterpreter) error {
+// ExecuteQuantumOp выполняет квантовую инструкцию и реализует
+// vm.QuantumOpHandler. Интерпретатор уже проверил стек и расширил память,
+// args содержит операнды в порядке реестра (args[0] был на вершине стека).
+func (q *QEVMContext) ExecuteQuantumOp(opcode OpCode, scope *vm.ScopeContext, args []uint256.Int) ([]uint256.Int, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
-	if !q.active || q.env == nil {
-		return ErrQuestNotInitialized
+
+	if !q.active {
+		return nil, ErrQuestNotInitialized
 	}
-	
-	// Проверяем, что у нас достаточно газа для выполнения операции
-	gasRequired, ok := q.gasTable[opcode]
-	if !ok {
-		return ErrInvalidOpcode
+	if q.gasTable == nil {
+		return nil, ErrInvalidOpcode
 	}
-	
-	// Проверяем наличие газа
-	if interpreter.Contract.Gas < gasRequired {
+
+	// Регистр принадлежит исполняемому контракту и хранится в его состоянии,
+	// поэтому откат кадра вызова откатывает и квантовые операции
+	addr := scope.Contract.Address()
+	if err := q.loadRegister(addr, scope.Contract); err != nil {
+		return nil, err
+	}
+
+	// Списываем газ за операцию пропорционально размеру представления
+	// регистра
+	gasRequired, words, err := q.gasForOp(opcode, args)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.Contract.UseGas(gasRequired, q.evm.Config.Tracer, tracing.GasChangeUnspecified) {
+		return nil, ErrGasLimitExceeded
+	}
+
+	// QINIT - единственная операция, допустимая без регистра
+	if opcode != QINIT && q.env == nil {
+		return nil, ErrQuestNotInitialized
+	}
+
+	ret, err := q.executeOp(opcode, scope, args)
+	if err != nil {
+		// Неудачная операция могла частично изменить регистр, при следующем
+		// обращении он будет заново декодирован из состояния
+		delete(q.registers, addr)
+		q.traceOp(addr, opcode, args, nil, err)
+		return nil, err
+	}
+	// Рост представления (переход к вектору состояния, расширение
+	// разреженного носителя) оплачивается после операции, как расширение
+	// памяти
+	if q.env != nil {
+		growth, ok := q.gasTable.GrowthCost(words, q.env.StateWords())
+		if !ok || !scope.Contract.UseGas(growth, q.evm.Config.Tracer, tracing.GasChangeUnspecified) {
+			delete(q.registers, addr)
+			q.traceOp(addr, opcode, args, nil, ErrGasLimitExceeded)
+			return nil, ErrGasLimitExceeded
+		}
+	}
+	if modifiesRegister(opcode) {
+		if err := q.markDirty(addr, scope.Contract); err != nil {
+			delete(q.registers, addr)
+			q.traceOp(addr, opcode, args, nil, err)
+			return nil, err
+		}
+	}
+	q.traceOp(addr, opcode, args, ret, nil)
+	return ret, nil
+}
+
+// modifiesRegister сообщает, изменяет ли инструкция регистр контракта.
+// QSHOR, QGROVER и QQFT работают со вспомогательным регистром, а QQPE только
+// читает поток случайных чисел.
+func modifiesRegister(opcode OpCode) bool {
+	switch opcode {
+	case QSHOR, QGROVER, QQFT, QQPE:
+		return false
+	}
+	return true
+}
+
+// tracedRegister предоставляет трассировщику доступ к регистру
+type tracedRegister struct {
+	env *QuestEnv
+}
+
+func (r tracedRegister) NumQubits() int            { return r.env.GetQubitCount() }
+func (r tracedRegister) StateVector() []complex128 { return r.env.GetStateVector() }
+
+// traceOp сообщает трассировщику о выполненной инструкции, а при успешном
+// выполнении - и о создании или уничтожении регистра и об измерениях
+func (q *QEVMContext) traceOp(addr common.Address, opcode OpCode, args []uint256.Int, ret []uint256.Int, err error) {
+	tracer := q.evm.Config.Tracer
+	if tracer == nil || (tracer.OnQuantumOp == nil && tracer.OnMeasure == nil && tracer.OnRegisterAlloc == nil) {
+		return
+	}
+	qubits, params := opOperands(opcode, args)
+	if tracer.OnQuantumOp != nil {
+		var register tracing.QuantumRegister
+		if err == nil && q.env != nil {
+			register = tracedRegister{q.env}
+		}
+		tracer.OnQuantumOp(addr, byte(opcode), qubits, params, register, err)
+	}
+	if err != nil {
+		return
+	}
+	switch opcode {
+	case QINIT, QDESTROY:
+		if tracer.OnRegisterAlloc != nil {
+			var numQubits int
+			if q.env != nil {
+				numQubits = q.env.GetQubitCount()
+			}
+			tracer.OnRegisterAlloc(addr, numQubits)
+		}
+	case QMEASURE, QMEASUREALL:
+		if tracer.OnMeasure != nil {
+			if opcode == QMEASUREALL {
+				qubits = make([]uint64, q.env.GetQubitCount())
+				for i := range qubits {
+					qubits[i] = uint64(i)
+				}
+			}
+			tracer.OnMeasure(addr, qubits, ret[0].Uint64())
+		}
+	}
+}
+
+// opOperands разделяет операнды инструкции на индексы кубитов, к которым
+// она применяется, и остальные параметры. Управляющие кубиты предшествуют
+// целевому.
+func opOperands(opcode OpCode, args []uint256.Int) (qubits []uint64, params []uint64) {
+	operand := func(i int) uint64 {
+		if !args[i].IsUint64() {
+			return math.MaxUint64
+		}
+		return args[i].Uint64()
+	}
+	switch opcode {
+	case QHADAMARD, QPAULIX, QPAULIY, QPAULIZ, QMEASURE:
+		qubits = []uint64{operand(0)}
+	case QPHASE, QROTX, QROTY, QROTZ:
+		qubits, params = []uint64{operand(1)}, []uint64{operand(0)}
+	case QU3:
+		qubits, params = []uint64{operand(3)}, []uint64{operand(0), operand(1), operand(2)}
+	case QCNOT, QSWAP, QCZ, QISWAP:
+		qubits = []uint64{operand(1), operand(0)}
+	case QCPHASE:
+		qubits, params = []uint64{operand(2), operand(1)}, []uint64{operand(0)}
+	case QTOFFOLI:
+		qubits = []uint64{operand(2), operand(1), operand(0)}
+	case QCU:
+		for _, control := range controlsArg(&args[4]) {
+			qubits = append(qubits, uint64(control))
+		}
+		qubits, params = append(qubits, operand(3)), []uint64{operand(0), operand(1), operand(2)}
+	case QQPE:
+		qubits, params = []uint64{operand(2)}, []uint64{operand(1), operand(0)}
+	case QGROVER:
+		params = []uint64{operand(2)}
+	case QINIT:
+		// Число кубитов и, для регистра с шумом, поля модели шума
+		params = []uint64{args[0][0]}
+		if !args[0].IsUint64() {
+			params = append(params, args[0][1], args[0][2], args[0][3])
+		}
+	case QSHOR, QRANDOM:
+		params = []uint64{operand(len(args) - 1)}
+	}
+	return qubits, params
+}
+
+// loadRegister делает текущим регистр контракта addr, сохраненный в состоянии.
+// Декодированный регистр переиспользуется, пока его обязательство совпадает с
+// хранимым в ячейке state.QuantumRegisterSlot, поэтому регистр декодируется
+// не чаще одного раза за кадр вызова. Декодирование оплачивается газом
+// contract по размеру хранимого регистра.
+func (q *QEVMContext) loadRegister(addr common.Address, contract *vm.Contract) error {
+	q.contractAddress = addr
+
+	hash := q.evm.StateDB.GetState(addr, state.QuantumRegisterSlot)
+	if cached, ok := q.registers[addr]; ok && (cached.dirty || cached.hash == hash) {
+		q.env = cached.env
+		if q.env != nil {
+			q.env.UseRandomStream(q.stream(addr))
+		}
+		return nil
+	}
+	data := q.evm.StateDB.GetQuantumRegister(addr)
+	if len(data) == 0 {
+		q.registers[addr] = &cachedRegister{hash: hash}
+		q.env = nil
+		return nil
+	}
+	gas, ok := q.gasTable.LoadCost(byteWords(uint64(len(data))))
+	if !ok || !contract.UseGas(gas, q.evm.Config.Tracer, tracing.GasChangeUnspecified) {
 		return ErrGasLimitExceeded
 	}
-	
-	// Списываем газ
-	interpreter.Contract.Gas -= gasRequired
-	
-	// Выполняем операцию
-	var err error
+	env, err := DecodeQuestEnv(data, q.backend, q.seedFor(addr).Hash())
+	if err != nil {
+		return err
+	}
+	env.UseRandomStream(q.stream(addr))
+	q.registers[addr] = &cachedRegister{hash: hash, env: env}
+	q.env = env
+	return nil
+}
+
+// markDirty отмечает текущий регистр контракта addr как измененный и
+// списывает с contract стоимость его записи в состояние. Если регистр вырос
+// после предыдущей оплаты, доплачивается только разница.
+func (q *QEVMContext) markDirty(addr common.Address, contract *vm.Contract) error {
+	cached, ok := q.registers[addr]
+	if !ok {
+		cached = new(cachedRegister)
+		q.registers[addr] = cached
+	}
+	cached.env = q.env
+
+	var words uint64
+	if q.env != nil {
+		words = q.env.StateWords()
+	}
+	if words > cached.paid {
+		gas, ok := q.gasTable.StoreCost(words - cached.paid)
+		if !ok || !contract.UseGas(gas, q.evm.Config.Tracer, tracing.GasChangeUnspecified) {
+			return ErrGasLimitExceeded
+		}
+		cached.paid = words
+	}
+	if !cached.dirty {
+		cached.dirty = true
+		q.dirty = append(q.dirty, addr)
+	}
+	return nil
+}
+
+// FlushQuantumRegisters записывает измененные регистры в состояние и
+// реализует vm.QuantumRegisterFlusher. Регистр кодируется, хэшируется и
+// журналируется один раз, сколько бы инструкций его ни изменили.
+// Уничтоженный регистр удаляется из состояния.
+func (q *QEVMContext) FlushQuantumRegisters() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, addr := range q.dirty {
+		cached, ok := q.registers[addr]
+		if !ok || !cached.dirty {
+			continue
+		}
+		cached.dirty, cached.paid = false, 0
+		if cached.env == nil {
+			q.evm.StateDB.SetQuantumRegister(addr, nil)
+		} else {
+			q.evm.StateDB.SetQuantumRegister(addr, cached.env.EncodeState())
+		}
+		cached.hash = q.evm.StateDB.GetState(addr, state.QuantumRegisterSlot)
+	}
+	q.dirty = q.dirty[:0]
+}
+
+// seedFor возвращает зерно измерений для регистра контракта addr
+func (q *QEVMContext) seedFor(addr common.Address) MeasurementSeed {
+	seed := q.seed
+	seed.Contract = addr
+	return seed
+}
+
+// stream возвращает поток случайных чисел регистра контракта addr в
+// текущем кадре вызова
+func (q *QEVMContext) stream(addr common.Address) *RandomStream {
+	seed := q.seedFor(addr).Hash()
+	s, ok := q.streams[seed]
+	if !ok {
+		s = NewRandomStream(seed)
+		q.streams[seed] = s
+	}
+	return s
+}
+
+// executeOp выполняет квантовую инструкцию над текущим регистром
+func (q *QEVMContext) executeOp(opcode OpCode, scope *vm.ScopeContext, args []uint256.Int) ([]uint256.Int, error) {
 	switch opcode {
 	case QINIT:
-		err = q.opQInit(stack)
+		return nil, q.opQInit(args)
 	case QDESTROY:
-		err = q.opQDestroy()
+		return nil, q.opQDestroy()
 	case QRESET:
-		err = q.opQReset()
+		return nil, q.opQReset()
 	case QHADAMARD:
-		err = q.opQHadamard(stack)
+		return nil, q.opQHadamard(args)
 	case QPAULIX:
-		err = q.opQPauliX(stack)
+		return nil, q.opQPauliX(args)
 	case QPAULIY:
-		err = q.opQPauliY(stack)
+		return nil, q.opQPauliY(args)
 	case QPAULIZ:
-		err = q.opQPauliZ(stack)
+		return nil, q.opQPauliZ(args)
 	case QPHASE:
-		err = q.opQPhase(stack)
+		return nil, q.opQPhase(args)
 	case QROTX:
-		err = q.opQRotX(stack)
+		return nil, q.opQRotX(args)
 	case QROTY:
-		err = q.opQRotY(stack)
+		return nil, q.opQRotY(args)
 	case QROTZ:
-		err = q.opQRotZ(stack)
+		return nil, q.opQRotZ(args)
 	case QCNOT:
-		err = q.opQCNOT(stack)
+		return nil, q.opQCNOT(args)
 	case QSWAP:
-		err = q.opQSwap(stack)
+		return nil, q.opQSwap(args)
+	case QU3:
+		return nil, q.opQU3(args)
 	case QTOFFOLI:
-		err = q.opQToffoli(stack)
+		return nil, q.opQToffoli(args)
+	case QCZ:
+		return nil, q.opQCZ(args)
+	case QCPHASE:
+		return nil, q.opQCPhase(args)
+	case QISWAP:
+		return nil, q.opQISwap(args)
+	case QCU:
+		return nil, q.opQCU(args)
 	case QMEASURE:
-		err = q.opQMeasure(stack)
+		return q.opQMeasure(args)
 	case QMEASUREALL:
-		err = q.opQMeasureAll(stack)
+		return q.opQMeasureAll()
 	case QSHOR:
-		err = q.opQShor(stack)
+		return q.opQShor(args)
 	case QGROVER:
-		err = q.opQGrover(stack, memory)
+		return q.opQGrover(args, scope.Memory)
 	case QQFT:
-		err = q.opQQFT(stack, memory)
+		return nil, q.opQQFT(args, scope.Memory)
 	case QQPE:
-		err = q.opQQPE(stack)
+		return q.opQQPE(args)
 	case QRANDOM:
-		err = q.opQRandom(stack, memory)
+		return q.opQRandom(args, scope.Memory)
 	default:
-		return ErrInvalidOpcode
+		return nil, ErrInvalidOpcode
 	}
-	
-	return err
 }
 
-// GasForOp возвращает стоимость в газе для выполнения квантовой операции
-func (q *QEVMContext) GasForOp(opcode OpCode) (uint64, error) {
+// GasForOp возвращает стоимость в газе квантовой операции с операндами args
+// над текущим регистром
+func (q *QEVMContext) GasForOp(opcode OpCode, args []uint256.Int) (uint64, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	
-	gas, ok := q.gasTable[opcode]
-	if !ok {
-		return 0, ErrInvalidOpcode
-	}
-	
-	return gas, nil
+
+	gas, _, err := q.gasForOp(opcode, args)
+	return gas, err
+}
+
+// gasForOp возвращает стоимость операции для регистра из q.env и размер
+// представления регистра в словах, по которому она рассчитана. Для QINIT
+// это размер создаваемого регистра.
+func (q *QEVMContext) gasForOp(opcode OpCode, args []uint256.Int) (uint64, uint64, error) {
+	if q.gasTable == nil {
+		return 0, 0, ErrInvalidOpcode
+	}
+	var words uint64
+	switch {
+	case opcode == QINIT:
+		numQubits, _, err := qinitOperand(&args[0])
+		if err != nil {
+			return 0, 0, err
+		}
+		if numQubits <= 0 || numQubits > q.maxQubits {
+			return 0, 0, ErrMaxQubitsExceeded
+		}
+		if words, err = RegisterWords(q.backend, numQubits); err != nil {
+			return 0, 0, err
+		}
+	case q.env != nil:
+		words = q.env.StateWords()
+	}
+	gas, err := vm.QuantumGas(q.gasTable, opcode, words, args)
+	return gas, words, err
+}
+
+// qubitArg преобразует операнд в индекс кубита. Значения, не помещающиеся в
+// int, отклоняются, а не усекаются, иначе большой операнд мог бы указать на
+// существующий кубит.
+func qubitArg(v *uint256.Int) (int, error) {
+	if !v.IsUint64() || v.Uint64() > math.MaxInt32 {
+		return 0, ErrQubitRangeOverflow
+	}
+	return int(v.Uint64()), nil
+}
+
+// controlsArg возвращает индексы управляющих кубитов, заданных битовой маской
+func controlsArg(v *uint256.Int) []int {
+	var controls []int
+	for i := 0; i < v.BitLen(); i++ {
+		if v[i/64]>>(i%64)&1 != 0 {
+			controls = append(controls, i)
+		}
+	}
+	return controls
 }
 
 // Реализация квантовых операций
 
-// opQInit инициализирует квантовый регистр
-func (q *QEVMContext) opQInit(stack *vm.Stack) error {
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
+// opQInit инициализирует квантовый регистр. Модель шума, заданная
+// операндом, хранится вместе с регистром.
+func (q *QEVMContext) opQInit(args []uint256.Int) error {
+	// Получаем желаемое количество кубитов и модель шума
+	numQubits, noise, err := qinitOperand(&args[0])
+	if err != nil {
+		return err
 	}
 	
-	// Получаем желаемое количество кубитов из стека
-	numQubits := int(stack.Pop().Uint64())
-	
 	// Проверяем, что количество кубитов не превышает допустимое
 	if numQubits <= 0 || numQubits > q.maxQubits {
 		return ErrMaxQubitsExceeded
@@ -296,11 +684,14 @@ func (q *QEVMContext) opQInit(stack *vm.Stack) error {
 	}
 	
 	// Создаем новое квантовое окружение
-	var err error
-	q.env, err = NewQuestEnv(numQubits, false, 0) // Не используем GPU для простоты
+	q.env, err = NewQuestEnvWithBackend(numQubits, q.backend, false, 0, q.seedFor(q.contractAddress).Hash()) // Не используем GPU для простоты
 	if err != nil {
 		return err
 	}
+	if err := q.env.SetNoiseModel(noise); err != nil {
+		return err
+	}
+	q.env.UseRandomStream(q.stream(q.contractAddress))
 	
 	q.active = true
 	return nil
@@ -308,122 +699,72 @@ func (q *QEVMContext) opQInit(stack *vm.Stack) error {
 
 // opQDestroy уничтожает квантовый регистр
 func (q *QEVMContext) opQDestroy() error {
-	if q.env == nil {
-		return ErrQuestNotInitialized
-	}
-	
 	err := q.env.Destroy()
 	q.env = nil
-	q.active = false
 	return err
 }
 
 // opQReset сбрасывает квантовый регистр в начальное состояние
 func (q *QEVMContext) opQReset() error {
-	if q.env == nil {
-		return ErrQuestNotInitialized
-	}
-	
 	return q.env.Reset()
 }
 
 // opQHadamard применяет вентиль Адамара к указанному кубиту
-func (q *QEVMContext) opQHadamard(stack *vm.Stack) error {
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+func (q *QEVMContext) opQHadamard(args []uint256.Int) error {
+	qubit, err := qubitArg(&args[0])
+	if err != nil {
+		return err
 	}
-	
-	// Получаем индекс кубита из стека
-	qubit := int(stack.Pop().Uint64())
-	
 	return q.env.ApplyHadamard(qubit)
 }
 
 // opQPauliX применяет вентиль Паули X к указанному кубиту
-func (q *QEVMContext) opQPauliX(stack *vm.Stack) error {
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+func (q *QEVMContext) opQPauliX(args []uint256.Int) error {
+	qubit, err := qubitArg(&args[0])
+	if err != nil {
+		return err
 	}
-	
-	// Получаем индекс кубита из стека
-	qubit := int(stack.Pop().Uint64())
-	
 	return q.env.ApplyPauliX(qubit)
 }
 
 // opQPauliY применяет вентиль Паули Y к указанному кубиту
-func (q *QEVMContext) opQPauliY(stack *vm.Stack) error {
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+func (q *QEVMContext) opQPauliY(args []uint256.Int) error {
+	qubit, err := qubitArg(&args[0])
+	if err != nil {
+		return err
 	}
-	
-	// Получаем индекс кубита из стека
-	qubit := int(stack.Pop().Uint64())
-	
 	return q.env.ApplyPauliY(qubit)
 }
 
 // opQPauliZ применяет вентиль Паули Z к указанному кубиту
-func (q *QEVMContext) opQPauliZ(stack *vm.Stack) error {
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+func (q *QEVMContext) opQPauliZ(args []uint256.Int) error {
+	qubit, err := qubitArg(&args[0])
+	if err != nil {
+		return err
 	}
-	
-	// Получаем индекс кубита из стека
-	qubit := int(stack.Pop().Uint64())
-	
 	return q.env.ApplyPauliZ(qubit)
 }
 
 // opQPhase применяет фазовый вентиль к указанному кубиту
-func (q *QEVMContext) opQPhase(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+func (q *QEVMContext) opQPhase(args []uint256.Int) error {
+	angle := angleArg(&args[0])
+	qubit, err := qubitArg(&args[1])
+	if err != nil {
+		return err
 	}
-	
-	// Получаем параметры из стека
-	angle := float64(stack.Pop().Uint64()) / 1000.0 // Угол в радианах с точностью до тысячных
-	qubit := int(stack.Pop().Uint64())
-	
 	return q.env.ApplyPhaseShift(qubit, angle)
 }
 
 // opQRotX применяет вращение вокруг оси X к указанному кубиту
-func (q *QEVMContext) opQRotX(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+func (q *QEVMContext) opQRotX(args []uint256.Int) error {
+	angle := angleArg(&args[0])
+	qubit, err := qubitArg(&args[1])
+	if err != nil {
+		return err
 	}
 	
-	// Получаем параметры из стека
-	angle := float64(stack.Pop().Uint64()) / 1000.0 // Угол в радианах с точностью до тысячных
-	qubit := int(stack.Pop().Uint64())
-	
 	// Симулируем вращение через комбинацию базовых вентилей
-	err := q.env.ApplyHadamard(qubit)
+	err = q.env.ApplyHadamard(qubit)
 	if err != nil {
 		return err
 	}
@@ -447,304 +788,208 @@ func (q *QEVMContext) opQRotX(stack *vm.Stack) error {
 }
 
 // opQRotY применяет вращение вокруг оси Y к указанному кубиту
-func (q *QEVMContext) opQRotY(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
-	}
-	
-	// Получаем параметры из стека
-	angle := float64(stack.Pop().Uint64()) / 1000.0 // Угол в радианах с точностью до тысячных
-	qubit := int(stack.Pop().Uint64())
-	
-	// Симулируем вращение через комбинацию базовых вентилей
-	err := q.env.ApplyPauliX(qubit)
+func (q *QEVMContext) opQRotY(args []uint256.Int) error {
+	angle := angleArg(&args[0])
+	qubit, err := qubitArg(&args[1])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyHadamard(qubit)
-	if err != nil {
-		return err
-	}
-	
-	err = q.env.ApplyPhaseShift(qubit, angle)
-	if err != nil {
-		return err
-	}
-	
-	err = q.env.ApplyHadamard(qubit)
-	if err != nil {
-		return err
-	}
-	
-	return q.env.ApplyPauliX(qubit)
+	return q.env.applyRotY(qubit, angle)
 }
 
 // opQRotZ применяет вращение вокруг оси Z к указанному кубиту
-func (q *QEVMContext) opQRotZ(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+func (q *QEVMContext) opQRotZ(args []uint256.Int) error {
+	angle := angleArg(&args[0])
+	qubit, err := qubitArg(&args[1])
+	if err != nil {
+		return err
 	}
 	
-	// Получаем параметры из стека
-	angle := float64(stack.Pop().Uint64()) / 1000.0 // Угол в радианах с точностью до тысячных
-	qubit := int(stack.Pop().Uint64())
-	
 	// Вращение вокруг оси Z - это по сути фазовый сдвиг
 	return q.env.ApplyPhaseShift(qubit, angle)
 }
 
 // opQCNOT применяет вентиль CNOT (controlled-NOT) между двумя кубитами
-func (q *QEVMContext) opQCNOT(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
+func (q *QEVMContext) opQCNOT(args []uint256.Int) error {
+	target, err := qubitArg(&args[0])
+	if err != nil {
+		return err
 	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+	control, err := qubitArg(&args[1])
+	if err != nil {
+		return err
 	}
-	
-	// Получаем параметры из стека
-	target := int(stack.Pop().Uint64())
-	control := int(stack.Pop().Uint64())
-	
 	return q.env.ApplyCNOT(control, target)
 }
 
 // opQSwap меняет местами состояния двух кубитов
-func (q *QEVMContext) opQSwap(stack *vm.Stack) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
-	}
-	
-	// Получаем параметры из стека
-	qubit2 := int(stack.Pop().Uint64())
-	qubit1 := int(stack.Pop().Uint64())
-	
-	return q.env.ApplySwap(qubit1, qubit2)
-}
-
-// opQToffoli применяет вентиль Тоффоли (controlled-controlled-NOT) между тремя кубитами
-func (q *QEVMContext) opQToffoli(stack *vm.Stack) error {
-	if stack.Len() < 3 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
-	}
-	
-	// Получаем параметры из стека
-	target := int(stack.Pop().Uint64())
-	control2 := int(stack.Pop().Uint64())
-	control1 := int(stack.Pop().Uint64())
-	
-	// Реализуем Тоффоли через базовые вентили
-	err := q.env.ApplyHadamard(target)
+func (q *QEVMContext) opQSwap(args []uint256.Int) error {
+	qubit2, err := qubitArg(&args[0])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyCNOT(control2, target)
+	qubit1, err := qubitArg(&args[1])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyPhaseShift(target, -3.14159/4)
+	return q.env.ApplySwap(qubit1, qubit2)
+}
+
+// opQToffoli применяет вентиль Тоффоли (controlled-controlled-NOT) между тремя кубитами
+func (q *QEVMContext) opQToffoli(args []uint256.Int) error {
+	target, err := qubitArg(&args[0])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyCNOT(control1, target)
+	control2, err := qubitArg(&args[1])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyPhaseShift(target, 3.14159/4)
+	control1, err := qubitArg(&args[2])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyCNOT(control2, target)
+	return q.env.applyToffoli(control1, control2, target)
+}
+
+// opQU3 применяет вентиль U3(theta, phi, lambda) к указанному кубиту
+func (q *QEVMContext) opQU3(args []uint256.Int) error {
+	qubit, err := qubitArg(&args[3])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyPhaseShift(target, -3.14159/4)
+	return q.env.ApplyU3(qubit, angleArg(&args[0]), angleArg(&args[1]), angleArg(&args[2]))
+}
+
+// opQCZ применяет вентиль CZ (controlled-Z) между двумя кубитами
+func (q *QEVMContext) opQCZ(args []uint256.Int) error {
+	target, err := qubitArg(&args[0])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyCNOT(control1, target)
+	control, err := qubitArg(&args[1])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyPhaseShift(target, 3.14159/4)
+	return q.env.ApplyCZ(control, target)
+}
+
+// opQCPhase применяет контролируемый фазовый сдвиг между двумя кубитами
+func (q *QEVMContext) opQCPhase(args []uint256.Int) error {
+	angle := angleArg(&args[0])
+	target, err := qubitArg(&args[1])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyCNOT(control1, control2)
+	control, err := qubitArg(&args[2])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyPhaseShift(control2, -3.14159/4)
+	return q.env.ApplyCPhase(control, target, angle)
+}
+
+// opQISwap применяет вентиль iSWAP к двум кубитам
+func (q *QEVMContext) opQISwap(args []uint256.Int) error {
+	qubit2, err := qubitArg(&args[0])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyCNOT(control1, control2)
+	qubit1, err := qubitArg(&args[1])
 	if err != nil {
 		return err
 	}
-	
-	err = q.env.ApplyPhaseShift(control1, 3.14159/4)
+	return q.env.ApplyISwap(qubit1, qubit2)
+}
+
+// opQCU применяет вентиль U3 к целевому кубиту, управляемый кубитами маски
+func (q *QEVMContext) opQCU(args []uint256.Int) error {
+	target, err := qubitArg(&args[3])
 	if err != nil {
 		return err
 	}
-	
-	return q.env.ApplyHadamard(target)
+	return q.env.ApplyControlledU3(controlsArg(&args[4]), target, angleArg(&args[0]), angleArg(&args[1]), angleArg(&args[2]))
 }
 
 // opQMeasure измеряет указанный кубит и возвращает результат
-func (q *QEVMContext) opQMeasure(stack *vm.Stack) error {
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+func (q *QEVMContext) opQMeasure(args []uint256.Int) ([]uint256.Int, error) {
+	qubit, err := qubitArg(&args[0])
+	if err != nil {
+		return nil, err
 	}
 	
-	// Получаем индекс кубита из стека
-	qubit := int(stack.Pop().Uint64())
-	
 	// Измеряем кубит
 	result, err := q.env.MeasureQubit(qubit)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	
-	// Помещаем результат в стек
-	stack.Push(new(big.Int).SetInt64(int64(result)))
-	
-	return nil
+	return []uint256.Int{*uint256.NewInt(uint64(result))}, nil
 }
 
 // opQMeasureAll измеряет все кубиты и возвращает результат как целое число
-func (q *QEVMContext) opQMeasureAll(stack *vm.Stack) error {
-	if q.env == nil {
-		return ErrQuestNotInitialized
-	}
-	
-	// Измеряем все кубиты
+func (q *QEVMContext) opQMeasureAll() ([]uint256.Int, error) {
 	result, err := q.env.MeasureAllQubits()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	
-	// Помещаем результат в стек
-	stack.Push(new(big.Int).SetUint64(result))
-	
-	return nil
+	return []uint256.Int{*uint256.NewInt(result)}, nil
 }
 
 // opQShor выполняет алгоритм Шора для факторизации
-func (q *QEVMContext) opQShor(stack *vm.Stack) error {
-	if stack.Len() < 1 {
-		return ErrStackUnderflow
+func (q *QEVMContext) opQShor(args []uint256.Int) ([]uint256.Int, error) {
+	if !args[0].IsUint64() {
+		return nil, ErrInvalidInput
 	}
 	
-	if q.env == nil {
-		return ErrQuestNotInitialized
-	}
-	
-	// Получаем число для факторизации из стека
-	number := stack.Pop().Uint64()
-	
 	// Выполняем алгоритм Шора
-	factors, err := q.env.ExecuteShorAlgorithm(number)
+	factors, err := q.env.ExecuteShorAlgorithm(args[0].Uint64())
 	if err != nil {
-		return err
+		return nil, err
 	}
 	
-	// Помещаем результаты в стек
-	stack.Push(new(big.Int).SetUint64(factors[0]))
-	stack.Push(new(big.Int).SetUint64(factors[1]))
-	
-	return nil
+	// Первый множитель оказывается на вершине стека
+	return []uint256.Int{*uint256.NewInt(factors[1]), *uint256.NewInt(factors[0])}, nil
 }
 
 // opQGrover выполняет алгоритм Гровера для поиска
-func (q *QEVMContext) opQGrover(stack *vm.Stack, memory *vm.Memory) error {
-	if stack.Len() < 3 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+func (q *QEVMContext) opQGrover(args []uint256.Int, memory *vm.Memory) ([]uint256.Int, error) {
+	// Область памяти уже расширена интерпретатором
+	offset, size := args[0].Uint64(), args[1].Uint64()
+	if !args[2].IsUint64() {
+		return nil, ErrInvalidInput
 	}
+	searchSpace := args[2].Uint64()
 	
-	// Получаем параметры из стека
-	searchSpace := stack.Pop().Uint64()
-	targetLen := int(stack.Pop().Uint64())
-	targetOffset := int(stack.Pop().Uint64())
-	
-	// Получаем целевые данные из памяти
-	target := memory.GetCopy(uint64(targetOffset), uint64(targetLen))
-	
-	// Выполняем алгоритм Гровера
-	result, err := q.env.ExecuteGroverAlgorithm(target, searchSpace)
+	// Выполняем алгоритм Гровера над целевыми данными из памяти
+	result, err := q.env.ExecuteGroverAlgorithm(memory.GetCopy(offset, size), searchSpace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	
-	// Сохраняем результат в память
-	memory.Set(uint64(targetOffset), uint64(len(result)), result)
-	
-	// Помещаем длину результата в стек
-	stack.Push(new(big.Int).SetInt64(int64(len(result))))
+	// Результат записывается на место целевых данных и не выходит за их границы
+	n := min(uint64(len(result)), size)
+	memory.Set(offset, n, result[:n])
 	
-	return nil
+	return []uint256.Int{*uint256.NewInt(n)}, nil
 }
 
-// opQQFT выполняет квантовое преобразование Фурье
-func (q *QEVMContext) opQQFT(stack *vm.Stack, memory *vm.Memory) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
-	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
-	}
-	
-	// Получаем параметры из стека
-	dataLen := int(stack.Pop().Uint64())
-	dataOffset := int(stack.Pop().Uint64())
-	
-	// Получаем данные из памяти
-	data := memory.GetCopy(uint64(dataOffset), uint64(dataLen*16)) // 16 байт на одно комплексное число
+// opQQFT выполняет квантовое преобразование Фурье. Каждое комплексное число
+// занимает 16 байт: действительная и мнимая части в виде int64 в
+// дополнительном коде, big-endian.
+func (q *QEVMContext) opQQFT(args []uint256.Int, memory *vm.Memory) error {
+	// Область памяти уже расширена интерпретатором
+	offset, size := args[0].Uint64(), args[1].Uint64()
+	if size%params.QuantumAmplitudeSize != 0 {
+		return ErrInvalidInput
+	}
+	dataLen := int(size / params.QuantumAmplitudeSize)
+	data := memory.GetCopy(offset, size)
 	
 	// Преобразуем байты в комплексные числа
 	complexData := make([]complex128, dataLen)
-	for i := 0; i < dataLen; i++ {
-		re := float64(new(big.Int).SetBytes(data[i*16:(i*16)+8]).Int64())
-		im := float64(new(big.Int).SetBytes(data[(i*16)+8:(i*16)+16]).Int64())
-		complexData[i] = complex(re, im)
+	for i := range complexData {
+		re := int64(binary.BigEndian.Uint64(data[i*16:]))
+		im := int64(binary.BigEndian.Uint64(data[i*16+8:]))
+		complexData[i] = complex(float64(re), float64(im))
 	}
 	
 	// Выполняем QFT
@@ -753,77 +998,79 @@ func (q *QEVMContext) opQQFT(stack *vm.Stack, memory *vm.Memory) error {
 		return err
 	}
 	
-	// Преобразуем комплексные числа обратно в байты
-	for i := 0; i < len(result); i++ {
-		re := new(big.Int).SetInt64(int64(real(result[i])))
-		im := new(big.Int).SetInt64(int64(imag(result[i])))
-		copy(data[i*16:(i*16)+8], re.Bytes())
-		copy(data[(i*16)+8:(i*16)+16], im.Bytes())
+	// Преобразуем комплексные числа обратно в байты с отбрасыванием дробной
+	// части. Преобразование float64 в int64 вне диапазона зависит от
+	// архитектуры, поэтому такие значения отклоняются.
+	for i := 0; i < dataLen; i++ {
+		re, ok := qftWord(real(result[i]))
+		if !ok {
+			return ErrInvalidInput
+		}
+		im, ok := qftWord(imag(result[i]))
+		if !ok {
+			return ErrInvalidInput
+		}
+		binary.BigEndian.PutUint64(data[i*16:], uint64(re))
+		binary.BigEndian.PutUint64(data[i*16+8:], uint64(im))
 	}
 	
 	// Сохраняем результат в память
-	memory.Set(uint64(dataOffset), uint64(len(data)), data)
+	memory.Set(offset, size, data)
 	
 	return nil
 }
 
+// qftWord переводит часть амплитуды в int64, если она представима
+func qftWord(x float64) (int64, bool) {
+	if !(x >= math.MinInt64 && x < math.MaxInt64) {
+		return 0, false
+	}
+	return int64(x), true
+}
+
 // opQQPE выполняет квантовое оценивание фазы
-func (q *QEVMContext) opQQPE(stack *vm.Stack) error {
-	if stack.Len() < 3 {
-		return ErrStackUnderflow
+func (q *QEVMContext) opQQPE(args []uint256.Int) ([]uint256.Int, error) {
+	iterations, err := qubitArg(&args[0])
+	if err != nil {
+		return nil, err
 	}
-	
-	if q.env == nil {
-		return ErrQuestNotInitialized
+	phaseQubits, err := qubitArg(&args[1])
+	if err != nil {
+		return nil, err
+	}
+	targetQubit, err := qubitArg(&args[2])
+	if err != nil {
+		return nil, err
 	}
-	
-	// Получаем параметры из стека
-	iterations := int(stack.Pop().Uint64())
-	phaseQubits := int(stack.Pop().Uint64())
-	targetQubit := int(stack.Pop().Uint64())
 	
 	// Выполняем квантовое оценивание фазы
 	phase, err := q.env.ExecuteQPE(targetQubit, phaseQubits, iterations)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	
 	// Преобразуем фазу в целое число (с точностью до миллионных)
-	phaseInt := int64(phase * 1000000)
-	
-	// Помещаем результат в стек
-	stack.Push(new(big.Int).SetInt64(phaseInt))
-	
-	return nil
+	return []uint256.Int{*uint256.NewInt(uint64(phase * 1000000))}, nil
 }
 
 // opQRandom генерирует квантовые случайные числа
-func (q *QEVMContext) opQRandom(stack *vm.Stack, memory *vm.Memory) error {
-	if stack.Len() < 2 {
-		return ErrStackUnderflow
+func (q *QEVMContext) opQRandom(args []uint256.Int, memory *vm.Memory) ([]uint256.Int, error) {
+	// Область памяти уже расширена интерпретатором
+	offset, size := args[0].Uint64(), args[1].Uint64()
+	if size == 0 {
+		return []uint256.Int{{}}, nil
 	}
 	
-	if q.env == nil {
-		return ErrQuestNotInitialized
-	}
-	
-	// Получаем параметры из стека
-	length := int(stack.Pop().Uint64())
-	offset := int(stack.Pop().Uint64())
-	
 	// Генерируем случайные данные
-	randomData, err := q.env.GenerateQuantumRandomBytes(length)
+	randomData, err := q.env.GenerateQuantumRandomBytes(int(size))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	
 	// Сохраняем результат в память
-	memory.Set(uint64(offset), uint64(len(randomData)), randomData)
+	memory.Set(offset, size, randomData)
 	
-	// Помещаем длину результата в стек
-	stack.Push(new(big.Int).SetInt64(int64(len(randomData))))
-	
-	return nil
+	return []uint256.Int{*uint256.NewInt(size)}, nil
 }
 
 // GetQuestEnv возвращает квантовое окружение
@@ -0,0 +1,232 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Формат сериализованного квантового регистра:
+//
+//	[1 байт версии][1 байт количества кубитов n][данные представления]
+//
+// Версия определяет представление состояния:
+//
+//   - 1, плотный вектор: 2^n амплитуд;
+//   - 2, разреженный вектор: 4 байта количества m и m пар из 8 байт индекса
+//     базисного состояния и амплитуды, по строго возрастающим индексам;
+//   - 3, таблица стабилизаторов: 2n строк из ceil(n/8) байт X-битов,
+//     ceil(n/8) байт Z-битов и байта знака.
+//
+// Каждая амплитуда кодируется 16 байтами: действительная и мнимая части в
+// виде IEEE-754 float64 в порядке big-endian. Регистр с шумом, заданным
+// операндом QINIT, отмечается битом registerNoiseFlag в байте версии, и
+// заголовок продолжается моделью шума: вероятности деполяризации, затухания
+// амплитуды, инверсии фазы и ошибки считывания в виде float64. Кодировка
+// каноническая, поэтому keccak256 от нее служит обязательством (commitment)
+// регистра в состоянии аккаунта.
+const (
+	registerVersionDense      = 1
+	registerVersionSparse     = 2
+	registerVersionStabilizer = 3
+	registerNoiseFlag         = 0x80
+	registerHeaderSize        = 2
+	noiseEncodingSize         = 32
+	amplitudeSize             = 16
+)
+
+// ErrInvalidRegisterEncoding ошибка, возникающая при декодировании
+// поврежденного или неканонического регистра
+var ErrInvalidRegisterEncoding = errors.New("недопустимая кодировка квантового регистра")
+
+// EncodeState возвращает каноническую сериализацию квантового регистра или
+// nil для уничтоженного окружения
+func (q *QuestEnv) EncodeState() []byte {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.backend == nil {
+		return nil
+	}
+	data := q.backend.encode()
+	if q.noise == nil {
+		return data
+	}
+	out := make([]byte, len(data)+noiseEncodingSize)
+	copy(out, data[:registerHeaderSize])
+	out[0] |= registerNoiseFlag
+	encodeNoise(out[registerHeaderSize:], q.noise)
+	copy(out[registerHeaderSize+noiseEncodingSize:], data[registerHeaderSize:])
+	return out
+}
+
+// StateCommitment возвращает детерминированное обязательство квантового
+// регистра, совпадающее с хэшем, который хранится в состоянии аккаунта
+func (q *QuestEnv) StateCommitment() common.Hash {
+	return crypto.Keccak256Hash(q.EncodeState())
+}
+
+// DecodeQuestEnv восстанавливает квантовое окружение из сериализованного
+// регистра вместе с его моделью шума. Представление состояния определяется
+// версией кодировки, а дальнейшие переходы между представлениями - режимом
+// mode. Генератор измерений инициализируется указанным зерном.
+func DecodeQuestEnv(data []byte, mode Backend, seed common.Hash) (*QuestEnv, error) {
+	if len(data) < registerHeaderSize {
+		return nil, ErrInvalidRegisterEncoding
+	}
+	var (
+		version   = data[0] &^ registerNoiseFlag
+		numQubits = int(data[1])
+		body      = data[registerHeaderSize:]
+		noise     *NoiseModel
+		state     stateBackend
+		err       error
+	)
+	if data[0]&registerNoiseFlag != 0 {
+		if noise, body, err = decodeNoise(body); err != nil {
+			return nil, err
+		}
+	}
+	switch version {
+	case registerVersionDense:
+		state, err = decodeDense(numQubits, body)
+	case registerVersionSparse:
+		state, err = decodeSparse(numQubits, body)
+	case registerVersionStabilizer:
+		state, err = decodeStabilizer(numQubits, body)
+	default:
+		return nil, fmt.Errorf("%w: версия %d", ErrInvalidRegisterEncoding, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := backendNames[mode]; !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownBackend, mode)
+	}
+	env := newQuestEnv(state, numQubits, mode, false, 0, seed)
+	if err := env.SetNoiseModel(noise); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// checkQubitCount проверяет размер регистра в кодировке
+func checkQubitCount(numQubits, limit int) error {
+	if numQubits <= 0 || numQubits > limit {
+		return fmt.Errorf("%w: %d кубитов", ErrInvalidRegisterEncoding, numQubits)
+	}
+	return nil
+}
+
+// decodeDense декодирует плотный вектор состояния
+func decodeDense(numQubits int, body []byte) (vectorBackend, error) {
+	if err := checkQubitCount(numQubits, MaxDenseQubits); err != nil {
+		return nil, err
+	}
+	if len(body) != (1<<numQubits)*amplitudeSize {
+		return nil, fmt.Errorf("%w: неверная длина %d", ErrInvalidRegisterEncoding, len(body)+registerHeaderSize)
+	}
+	state := make([]complex128, 1<<numQubits)
+	for i := range state {
+		amp, err := getAmplitude(body[i*amplitudeSize:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: амплитуда %d", err, i)
+		}
+		state[i] = amp
+	}
+	return newDenseBackend(numQubits, state), nil
+}
+
+// decodeSparse декодирует разреженный вектор состояния
+func decodeSparse(numQubits int, body []byte) (*sparseState, error) {
+	if err := checkQubitCount(numQubits, MaxSparseQubits); err != nil {
+		return nil, err
+	}
+	if len(body) < 4 {
+		return nil, fmt.Errorf("%w: неверная длина %d", ErrInvalidRegisterEncoding, len(body)+registerHeaderSize)
+	}
+	count := binary.BigEndian.Uint32(body)
+	if count == 0 || count > maxSparseEntries || uint64(len(body)) != 4+uint64(count)*sparseEntrySize {
+		return nil, fmt.Errorf("%w: неверная длина %d", ErrInvalidRegisterEncoding, len(body)+registerHeaderSize)
+	}
+	s := &sparseState{n: numQubits, amp: make(map[uint64]complex128, count)}
+	buf := body[4:]
+	for i := 0; i < int(count); i++ {
+		index := binary.BigEndian.Uint64(buf[i*sparseEntrySize:])
+		if numQubits < 64 && index >= 1<<numQubits {
+			return nil, fmt.Errorf("%w: базисное состояние %d вне регистра", ErrInvalidRegisterEncoding, index)
+		}
+		// Индексы строго возрастают, что исключает повторы
+		if i > 0 && index <= binary.BigEndian.Uint64(buf[(i-1)*sparseEntrySize:]) {
+			return nil, fmt.Errorf("%w: индексы не упорядочены", ErrInvalidRegisterEncoding)
+		}
+		amp, err := getAmplitude(buf[i*sparseEntrySize+8:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: амплитуда %d", err, index)
+		}
+		if amp == 0 {
+			return nil, fmt.Errorf("%w: нулевая амплитуда %d", ErrInvalidRegisterEncoding, index)
+		}
+		s.amp[index] = amp
+	}
+	return s, nil
+}
+
+// decodeStabilizer декодирует таблицу стабилизаторов
+func decodeStabilizer(numQubits int, body []byte) (*stabilizerState, error) {
+	if err := checkQubitCount(numQubits, MaxStabilizerQubits); err != nil {
+		return nil, err
+	}
+	rowSize := stabilizerRowSize(numQubits)
+	if len(body) != 2*numQubits*rowSize {
+		return nil, fmt.Errorf("%w: неверная длина %d", ErrInvalidRegisterEncoding, len(body)+registerHeaderSize)
+	}
+	bitBytes := (numQubits + 7) / 8
+	s := newStabilizerState(numQubits)
+	for i := 0; i < 2*numQubits; i++ {
+		row := body[i*rowSize : (i+1)*rowSize]
+		clear(s.x[i])
+		clear(s.z[i])
+		for j := 0; j < 8*bitBytes; j++ {
+			xb, zb := uint64(row[j/8]>>(j%8)&1), uint64(row[bitBytes+j/8]>>(j%8)&1)
+			if j >= numQubits {
+				// Неиспользуемые биты последнего байта должны быть нулевыми
+				if xb|zb != 0 {
+					return nil, fmt.Errorf("%w: лишние биты в строке %d", ErrInvalidRegisterEncoding, i)
+				}
+				continue
+			}
+			setBit(s.x[i], j, xb)
+			setBit(s.z[i], j, zb)
+		}
+		if row[2*bitBytes] > 1 {
+			return nil, fmt.Errorf("%w: знак строки %d", ErrInvalidRegisterEncoding, i)
+		}
+		s.r[i] = row[2*bitBytes]
+	}
+	return s, nil
+}
+
+// getAmplitude читает амплитуду, отклоняя бесконечности и NaN
+func getAmplitude(buf []byte) (complex128, error) {
+	re := math.Float64frombits(binary.BigEndian.Uint64(buf))
+	im := math.Float64frombits(binary.BigEndian.Uint64(buf[8:]))
+	if math.IsNaN(re) || math.IsNaN(im) || math.IsInf(re, 0) || math.IsInf(im, 0) {
+		return 0, fmt.Errorf("%w: не является конечным числом", ErrInvalidRegisterEncoding)
+	}
+	return complex(re, im), nil
+}
+
+// canonicalFloat приводит отрицательный ноль к положительному, чтобы
+// одинаковые состояния имели одинаковую кодировку
+func canonicalFloat(f float64) float64 {
+	if f == 0 {
+		return 0
+	}
+	return f
+}
@@ -0,0 +1,71 @@
+package quantum
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Проверяет, что сериализация регистра обратима и канонична
+func TestRegisterEncodingRoundTrip(t *testing.T) {
+	env, err := NewQuestEnvWithSeed(3, false, 0, common.HexToHash("0x01"))
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+	if err := env.ApplyHadamard(0); err != nil {
+		t.Fatalf("hadamard failed: %v", err)
+	}
+	if err := env.ApplyCNOT(0, 2); err != nil {
+		t.Fatalf("cnot failed: %v", err)
+	}
+	enc := env.EncodeState()
+	if want := registerHeaderSize + 8*amplitudeSize; len(enc) != want {
+		t.Fatalf("encoding length mismatch: have %d, want %d", len(enc), want)
+	}
+	if have, want := env.StateCommitment(), crypto.Keccak256Hash(enc); have != want {
+		t.Fatalf("commitment mismatch: have %x, want %x", have, want)
+	}
+	dec, err := DecodeQuestEnv(enc, BackendDense, common.HexToHash("0x02"))
+	if err != nil {
+		t.Fatalf("failed to decode register: %v", err)
+	}
+	if dec.GetQubitCount() != 3 {
+		t.Fatalf("qubit count mismatch: have %d, want 3", dec.GetQubitCount())
+	}
+	if !bytes.Equal(dec.EncodeState(), enc) {
+		t.Fatal("re-encoded register differs")
+	}
+	if dec.MeasurementSeed() != common.HexToHash("0x02") {
+		t.Fatal("decoded register ignores measurement seed")
+	}
+}
+
+// Проверяет отклонение поврежденных регистров
+func TestRegisterDecodingErrors(t *testing.T) {
+	env, err := NewQuestEnv(2, false, 0)
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+	valid := env.EncodeState()
+
+	nan := common.CopyBytes(valid)
+	copy(nan[registerHeaderSize:], []byte{0x7f, 0xf8, 0, 0, 0, 0, 0, 1})
+
+	tests := map[string][]byte{
+		"empty":     nil,
+		"version":   append([]byte{4}, valid[1:]...),
+		"no qubits": {registerVersionDense, 0},
+		"too large": {registerVersionDense, 26},
+		"truncated": valid[:len(valid)-1],
+		"trailing":  append(common.CopyBytes(valid), 0),
+		"nan":       nan,
+	}
+	for name, data := range tests {
+		if _, err := DecodeQuestEnv(data, BackendDense, common.Hash{}); !errors.Is(err, ErrInvalidRegisterEncoding) {
+			t.Errorf("%s: expected ErrInvalidRegisterEncoding, got %v", name, err)
+		}
+	}
+}
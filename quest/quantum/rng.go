@@ -0,0 +1,136 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrInvalidRandomRange ошибка, возникающая при запросе числа из пустого диапазона
+var ErrInvalidRandomRange = errors.New("недопустимый диапазон случайных чисел")
+
+// MeasurementSeed описывает контекст выполнения, из которого выводится зерно
+// генератора случайных чисел для измерений. Все поля берутся из данных блока и
+// транзакции, поэтому повторное выполнение (импорт блока, трассировка,
+// eth_call) воспроизводит те же результаты измерений на любом узле.
+type MeasurementSeed struct {
+	PrevRandao common.Hash    // Значение PREVRANDAO текущего блока
+	TxHash     common.Hash    // Хэш выполняемой транзакции
+	Contract   common.Address // Адрес контракта, владеющего регистром
+	Depth      int            // Глубина вызова
+}
+
+// Hash возвращает 32-байтовое зерно, однозначно определяемое контекстом.
+func (s MeasurementSeed) Hash() common.Hash {
+	var depth [8]byte
+	binary.BigEndian.PutUint64(depth[:], uint64(s.Depth))
+	return crypto.Keccak256Hash(s.PrevRandao[:], s.TxHash[:], s.Contract[:], depth[:])
+}
+
+// DeterministicRNG - детерминированный генератор псевдослучайных чисел на основе
+// keccak256 в режиме счетчика: блок i равен keccak256(seed || i). В отличие от
+// math/rand результат не зависит от реализации стандартной библиотеки, что
+// необходимо для консенсуса.
+//
+// Генератор не потокобезопасен, синхронизация возлагается на владельца.
+type DeterministicRNG struct {
+	seed    common.Hash
+	counter uint64
+	buf     [32]byte
+	pos     int
+}
+
+// NewDeterministicRNG создает генератор, инициализированный указанным зерном
+func NewDeterministicRNG(seed common.Hash) *DeterministicRNG {
+	r := new(DeterministicRNG)
+	r.Reseed(seed)
+	return r
+}
+
+// RandomStream - генераторы измерений и шума, выводимые из одного зерна.
+// Поток принадлежит кадру вызова, а не регистру: позиция потока не
+// сбрасывается, когда регистр заново загружается из состояния или создается
+// QINIT, поэтому повторные измерения в пределах транзакции не повторяют
+// друг друга.
+type RandomStream struct {
+	measure *DeterministicRNG
+	noise   *DeterministicRNG
+}
+
+// NewRandomStream создает поток, инициализированный указанным зерном
+func NewRandomStream(seed common.Hash) *RandomStream {
+	return &RandomStream{
+		measure: NewDeterministicRNG(seed),
+		noise:   NewDeterministicRNG(noiseSeed(seed)),
+	}
+}
+
+// Seed возвращает зерно потока
+func (s *RandomStream) Seed() common.Hash {
+	return s.measure.Seed()
+}
+
+// Reseed сбрасывает генератор в начальное состояние для нового зерна
+func (r *DeterministicRNG) Reseed(seed common.Hash) {
+	r.seed = seed
+	r.counter = 0
+	r.pos = len(r.buf)
+}
+
+// Seed возвращает текущее зерно генератора
+func (r *DeterministicRNG) Seed() common.Hash {
+	return r.seed
+}
+
+// refill вычисляет следующий блок псевдослучайных байтов
+func (r *DeterministicRNG) refill() {
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], r.counter)
+	r.counter++
+
+	copy(r.buf[:], crypto.Keccak256(r.seed[:], ctr[:]))
+	r.pos = 0
+}
+
+// Read заполняет p псевдослучайными байтами, всегда возвращая len(p), nil
+func (r *DeterministicRNG) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		if r.pos == len(r.buf) {
+			r.refill()
+		}
+		c := copy(p[n:], r.buf[r.pos:])
+		r.pos += c
+		n += c
+	}
+	return len(p), nil
+}
+
+// Uint64 возвращает псевдослучайное 64-битное число
+func (r *DeterministicRNG) Uint64() uint64 {
+	var b [8]byte
+	r.Read(b[:])
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// Float64 возвращает псевдослучайное число из [0, 1) с 53 битами точности
+func (r *DeterministicRNG) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Uint64n возвращает равномерно распределенное число из [0, n). Для
+// исключения смещения используется отбрасывание значений из неполного
+// последнего интервала.
+func (r *DeterministicRNG) Uint64n(n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, ErrInvalidRandomRange
+	}
+	limit := ^uint64(0) - (^uint64(0)%n+1)%n
+	for {
+		if v := r.Uint64(); v <= limit {
+			return v % n, nil
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package quantum
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Проверяет, что одно и то же зерно дает одинаковую последовательность измерений
+func TestMeasurementDeterminism(t *testing.T) {
+	seed := MeasurementSeed{
+		PrevRandao: common.HexToHash("0x01"),
+		TxHash:     common.HexToHash("0x02"),
+		Contract:   common.HexToAddress("0x03"),
+		Depth:      1,
+	}
+	run := func(seed MeasurementSeed) []int {
+		env, err := NewQuestEnvWithSeed(4, false, 0, seed.Hash())
+		if err != nil {
+			t.Fatalf("failed to create env: %v", err)
+		}
+		var outcomes []int
+		for i := 0; i < 32; i++ {
+			qubit := i % 4
+			if err := env.ApplyHadamard(qubit); err != nil {
+				t.Fatalf("hadamard failed: %v", err)
+			}
+			res, err := env.MeasureQubit(qubit)
+			if err != nil {
+				t.Fatalf("measure failed: %v", err)
+			}
+			outcomes = append(outcomes, res)
+		}
+		return outcomes
+	}
+	first, second := run(seed), run(seed)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("outcome %d mismatch: %d != %d", i, first[i], second[i])
+		}
+	}
+	other := seed
+	other.Depth = 2
+	third := run(other)
+	same := true
+	for i := range first {
+		if first[i] != third[i] {
+			same = false
+		}
+	}
+	if same {
+		t.Fatalf("different call depth produced identical outcomes")
+	}
+}
+
+func TestQuantumRandomBytesDeterminism(t *testing.T) {
+	seed := common.HexToHash("0xdeadbeef")
+
+	a, _ := NewQuestEnvWithSeed(3, false, 0, seed)
+	b, _ := NewQuestEnvWithSeed(3, false, 0, seed)
+
+	ra, err := a.GenerateQuantumRandomBytes(64)
+	if err != nil {
+		t.Fatalf("failed to generate bytes: %v", err)
+	}
+	rb, err := b.GenerateQuantumRandomBytes(64)
+	if err != nil {
+		t.Fatalf("failed to generate bytes: %v", err)
+	}
+	if !bytes.Equal(ra, rb) {
+		t.Fatalf("random bytes mismatch:\n%x\n%x", ra, rb)
+	}
+	// Переинициализация зерна должна воспроизводить поток с начала
+	a.SetMeasurementSeed(seed)
+	rc, _ := a.GenerateQuantumRandomBytes(64)
+	if !bytes.Equal(ra, rc) {
+		t.Fatalf("reseeded stream mismatch:\n%x\n%x", ra, rc)
+	}
+}
+
+func TestDeterministicRNGVectors(t *testing.T) {
+	rng := NewDeterministicRNG(common.Hash{})
+	first := rng.Uint64()
+	rng.Reseed(common.Hash{})
+	if second := rng.Uint64(); first != second {
+		t.Fatalf("reseed mismatch: %x != %x", first, second)
+	}
+	for i := 0; i < 1000; i++ {
+		if f := rng.Float64(); f < 0 || f >= 1 {
+			t.Fatalf("float out of range: %v", f)
+		}
+		v, err := rng.Uint64n(7)
+		if err != nil || v >= 7 {
+			t.Fatalf("bounded value out of range: %d, %v", v, err)
+		}
+	}
+	if _, err := rng.Uint64n(0); err != ErrInvalidRandomRange {
+		t.Fatalf("expected range error, got %v", err)
+	}
+}
+
+// Проверяет, что регистры, разделяющие поток, продолжают его, а не начинают
+// с начала, и что SetMeasurementSeed отключает регистр от потока
+func TestRandomStreamSharedByRegisters(t *testing.T) {
+	seed := common.HexToHash("0xfeed")
+
+	ref, _ := NewQuestEnvWithSeed(1, false, 0, seed)
+	want, err := ref.GenerateQuantumRandomBytes(16)
+	if err != nil {
+		t.Fatalf("failed to generate bytes: %v", err)
+	}
+	stream := NewRandomStream(seed)
+	if stream.Seed() != seed {
+		t.Fatalf("stream seed mismatch: %x", stream.Seed())
+	}
+	var have []byte
+	for i := 0; i < 2; i++ {
+		// Регистр, заново созданный в том же кадре, получает другое зерно,
+		// но использует поток кадра
+		env, _ := NewQuestEnvWithSeed(1, false, 0, common.Hash{byte(i)})
+		env.UseRandomStream(stream)
+		b, err := env.GenerateQuantumRandomBytes(8)
+		if err != nil {
+			t.Fatalf("failed to generate bytes: %v", err)
+		}
+		have = append(have, b...)
+	}
+	if !bytes.Equal(have, want) {
+		t.Fatalf("shared stream mismatch:\n%x\n%x", have, want)
+	}
+	env, _ := NewQuestEnvWithSeed(1, false, 0, common.Hash{})
+	env.UseRandomStream(stream)
+	env.SetMeasurementSeed(seed)
+	if b, _ := env.GenerateQuantumRandomBytes(8); !bytes.Equal(b, want[:8]) {
+		t.Fatalf("detached register mismatch:\n%x\n%x", b, want[:8])
+	}
+}
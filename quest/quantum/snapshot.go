@@ -0,0 +1,163 @@
+// Package quantum реализует квантовое окружение для использования в Ethereum
+package quantum
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/quest/utils"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Снимок квантового регистра кодируется RLP:
+//
+//	[версия, адрес, номер блока, хэш блока, регистр, история?]
+//
+// Регистр хранится в канонической кодировке, поэтому keccak256 от него
+// совпадает с обязательством в состоянии аккаунта. Необязательная история -
+// кодировка utils.DeltaCompression векторов состояния регистра на
+// предыдущих блоках.
+const snapshotVersion = 1
+
+// MaxSnapshotHistory - максимальное количество предыдущих блоков в истории
+// снимка. Больше дельт utils.DeltaCompression не хранит.
+const MaxSnapshotHistory = 100
+
+// snapshotThreshold - порог дельт истории: сохраняется любое изменение
+// амплитуды
+const snapshotThreshold = math.SmallestNonzeroFloat64
+
+var (
+	// ErrInvalidSnapshot ошибка, возникающая при декодировании поврежденного
+	// снимка регистра
+	ErrInvalidSnapshot = errors.New("недопустимый снимок квантового регистра")
+
+	// ErrNoRegister ошибка, возникающая при экспорте аккаунта без регистра
+	ErrNoRegister = errors.New("у аккаунта нет квантового регистра")
+)
+
+// RegisterSnapshot - переносимый снимок квантового регистра контракта на
+// блоке. Если History задана, она содержит цепочку дельт вектора состояния
+// регистра от самого раннего из включенных блоков до блока снимка; векторы
+// восстанавливаются с точностью округления, а точное состояние задает
+// Register.
+type RegisterSnapshot struct {
+	Version  uint
+	Address  common.Address
+	Number   uint64
+	Hash     common.Hash
+	Register []byte
+	History  *utils.DeltaCompression `rlp:"optional"`
+}
+
+// ExportRegister собирает снимок регистра addr на блоке header. registerAt
+// возвращает кодировку регистра в состоянии блока, parent - родительский
+// заголовок или nil. В историю включаются до history предыдущих блоков, на
+// которых регистр существовал с тем же числом кубитов; история строится по
+// плотным векторам состояния и недоступна для регистров больше
+// MaxDenseQubits кубитов.
+func ExportRegister(addr common.Address, header *types.Header, history uint64, parent func(*types.Header) *types.Header, registerAt func(*types.Header) ([]byte, error)) (*RegisterSnapshot, error) {
+	register, err := registerAt(header)
+	if err != nil {
+		return nil, err
+	}
+	if len(register) == 0 {
+		return nil, fmt.Errorf("%w %x на блоке %d", ErrNoRegister, addr, header.Number)
+	}
+	snap := &RegisterSnapshot{
+		Version:  snapshotVersion,
+		Address:  addr,
+		Number:   header.Number.Uint64(),
+		Hash:     header.Hash(),
+		Register: register,
+	}
+	if history == 0 {
+		return snap, nil
+	}
+	if history > MaxSnapshotHistory {
+		return nil, fmt.Errorf("история %d блоков превышает максимальную %d", history, MaxSnapshotHistory)
+	}
+	// Собираем векторы состояния предыдущих блоков от новых к старым
+	vector, err := registerVector(register)
+	if err != nil {
+		return nil, err
+	}
+	vectors := [][]complex128{vector}
+	for h := header; uint64(len(vectors)) <= history && h.Number.Sign() > 0; {
+		if h = parent(h); h == nil {
+			break
+		}
+		prev, err := registerAt(h)
+		if err != nil {
+			return nil, err
+		}
+		if len(prev) == 0 {
+			break // Регистр еще не был создан
+		}
+		if vector, err = registerVector(prev); err != nil {
+			return nil, err
+		}
+		if len(vector) != len(vectors[0]) {
+			break // Регистр был пересоздан с другим числом кубитов
+		}
+		vectors = append(vectors, vector)
+	}
+	snap.History = utils.NewDeltaCompression(nil, snapshotThreshold)
+	for i := len(vectors) - 1; i >= 0; i-- {
+		snap.History.UpdateState(vectors[i])
+	}
+	return snap, nil
+}
+
+// registerVector возвращает плотный вектор состояния закодированного регистра
+func registerVector(register []byte) ([]complex128, error) {
+	env, err := DecodeQuestEnv(register, BackendAuto, common.Hash{})
+	if err != nil {
+		return nil, err
+	}
+	defer env.Destroy()
+
+	vector := env.GetStateVector()
+	if vector == nil {
+		return nil, fmt.Errorf("история недоступна для регистра из %d кубитов", env.GetQubitCount())
+	}
+	return vector, nil
+}
+
+// Encode возвращает RLP-кодировку снимка
+func (s *RegisterSnapshot) Encode() ([]byte, error) {
+	return rlp.EncodeToBytes(s)
+}
+
+// DecodeRegisterSnapshot декодирует снимок и проверяет кодировку регистра
+func DecodeRegisterSnapshot(data []byte) (*RegisterSnapshot, error) {
+	snap := new(RegisterSnapshot)
+	if err := rlp.DecodeBytes(data, snap); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: версия %d", ErrInvalidSnapshot, snap.Version)
+	}
+	env, err := DecodeQuestEnv(snap.Register, BackendAuto, common.Hash{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	env.Destroy()
+	return snap, nil
+}
+
+// Commitment возвращает обязательство регистра, которое хранится в
+// состоянии аккаунта
+func (s *RegisterSnapshot) Commitment() common.Hash {
+	return crypto.Keccak256Hash(s.Register)
+}
+
+// Env восстанавливает квантовое окружение регистра снимка. Переходы между
+// представлениями определяются режимом mode, измерения - зерном seed.
+func (s *RegisterSnapshot) Env(mode Backend, seed common.Hash) (*QuestEnv, error) {
+	return DecodeQuestEnv(s.Register, mode, seed)
+}
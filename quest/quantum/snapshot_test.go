@@ -0,0 +1,129 @@
+package quantum
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"math/cmplx"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Проверяет экспорт снимка с историей, его кодирование и воспроизведение
+// истории по блокам
+func TestRegisterSnapshotRoundTrip(t *testing.T) {
+	env, err := NewQuestEnvWithSeed(3, false, 0, common.HexToHash("0x01"))
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+	// Регистр создается в блоке 1 и изменяется в каждом следующем
+	var (
+		headers   []*types.Header
+		registers = map[common.Hash][]byte{}
+		vectors   [][]complex128
+	)
+	for i := 0; i < 5; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Extra: []byte{byte(i)}}
+		if i > 0 {
+			header.ParentHash = headers[i-1].Hash()
+			registers[header.Hash()] = env.EncodeState()
+			vectors = append(vectors, env.GetStateVector())
+			env.ApplyHadamard(i % 3)
+			env.ApplyCNOT(i%3, (i+1)%3)
+		}
+		headers = append(headers, header)
+	}
+	parent := func(h *types.Header) *types.Header {
+		for _, header := range headers {
+			if header.Hash() == h.ParentHash {
+				return header
+			}
+		}
+		return nil
+	}
+	registerAt := func(h *types.Header) ([]byte, error) { return registers[h.Hash()], nil }
+	addr := common.HexToAddress("0xc0")
+
+	snap, err := ExportRegister(addr, headers[4], 10, parent, registerAt)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	enc, err := snap.Encode()
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	dec, err := DecodeRegisterSnapshot(enc)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if dec.Address != addr || dec.Number != 4 || dec.Hash != headers[4].Hash() || !bytes.Equal(dec.Register, registers[headers[4].Hash()]) {
+		t.Fatalf("snapshot mismatch: %+v", dec)
+	}
+	if have, want := dec.Commitment(), snap.Commitment(); have != want {
+		t.Fatalf("commitment mismatch: have %x, want %x", have, want)
+	}
+	// История начинается с блока создания регистра
+	if have := dec.History.GetStateCount(); have != len(vectors) {
+		t.Fatalf("history length mismatch: have %d, want %d", have, len(vectors))
+	}
+	var replayed int
+	dec.History.Replay(func(i int, state []complex128) bool {
+		for j, amp := range state {
+			if cmplx.Abs(amp-vectors[i][j]) > 1e-12 {
+				t.Errorf("block %d amplitude %d: have %v, want %v", i+1, j, amp, vectors[i][j])
+			}
+		}
+		replayed++
+		return true
+	})
+	if replayed != len(vectors) {
+		t.Fatalf("replayed %d states, want %d", replayed, len(vectors))
+	}
+	// Снимок без истории
+	snap, err = ExportRegister(addr, headers[4], 0, parent, registerAt)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if enc, _ = snap.Encode(); enc == nil {
+		t.Fatal("empty encoding")
+	}
+	if dec, err = DecodeRegisterSnapshot(enc); err != nil || dec.History != nil {
+		t.Fatalf("decode without history: %v, %v", err, dec)
+	}
+	if _, err := ExportRegister(addr, headers[0], 0, parent, registerAt); !errors.Is(err, ErrNoRegister) {
+		t.Fatalf("expected ErrNoRegister, got %v", err)
+	}
+}
+
+// Проверяет отклонение поврежденных снимков
+func TestRegisterSnapshotDecodingErrors(t *testing.T) {
+	env, err := NewQuestEnv(2, false, 0)
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+	encode := func(snap *RegisterSnapshot) []byte {
+		enc, err := snap.Encode()
+		if err != nil {
+			t.Fatalf("encode failed: %v", err)
+		}
+		return enc
+	}
+	valid := encode(&RegisterSnapshot{Version: snapshotVersion, Register: env.EncodeState()})
+
+	tests := map[string][]byte{
+		"empty":     nil,
+		"truncated": valid[:len(valid)-1],
+		"version":   encode(&RegisterSnapshot{Version: 2, Register: env.EncodeState()}),
+		"register":  encode(&RegisterSnapshot{Version: snapshotVersion, Register: env.EncodeState()[1:]}),
+	}
+	for name, data := range tests {
+		if _, err := DecodeRegisterSnapshot(data); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("%s: expected ErrInvalidSnapshot, got %v", name, err)
+		}
+	}
+	if _, err := DecodeRegisterSnapshot(valid); err != nil {
+		t.Fatalf("valid snapshot rejected: %v", err)
+	}
+}
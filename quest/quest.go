@@ -3,12 +3,12 @@ package quest
 
 import (
 	"errors"
-	"math"
-	"math/rand"
+	"math/bits"
 	"sync"
-	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/quest/quantum"
 )
 
 var (
@@ -18,16 +18,15 @@ var (
 	// ErrQuestNotAvailable ошибка, когда квантовый процессор недоступен
 	ErrQuestNotAvailable = errors.New("квантовый процессор недоступен")
 	
-	// квантовые опкоды
-	QuestOpcode = byte(0xF0)
-	QuestOperation = byte(0xF1)
+	// ErrElementNotFound ошибка, когда поиск Гроувера не нашел элемент
+	ErrElementNotFound = errors.New("элемент не найден")
 	
 	initialized bool
 	available bool
 	initLock sync.Mutex
 )
 
-// QuestOperation представляет типы квантовых операций
+// Operation представляет типы квантовых операций
 type Operation byte
 
 const (
@@ -41,6 +40,21 @@ const (
 	OperationQFT Operation = 3
 )
 
+// QuantumOp возвращает подкод инструкции из реестра vm.QuantumOp, которой
+// операция выполняется в байткоде контракта
+func (op Operation) QuantumOp() (vm.QuantumOp, bool) {
+	switch op {
+	case OperationGrover:
+		return vm.QGROVER, true
+	case OperationShor:
+		return vm.QSHOR, true
+	case OperationQFT:
+		return vm.QQFT, true
+	default:
+		return 0, false
+	}
+}
+
 // Initialize инициализирует квантовый процессор
 func Initialize() bool {
 	initLock.Lock()
@@ -50,8 +64,9 @@ func Initialize() bool {
 	// Для демонстрации просто устанавливаем флаги
 	initialized = true
 	
-	// Симулируем проверку наличия квантового процессора
-	rand.Seed(time.Now().UnixNano())
+	// Симулируем проверку наличия квантового процессора. Глобальный math/rand
+	// здесь не используется: все случайные величины в консенсусном пути
+	// берутся из детерминированного генератора quantum.DeterministicRNG
 	available = true // Для тестирования всегда доступен
 	
 	return initialized && available
@@ -67,151 +82,14 @@ func IsAvailable() bool {
 	return available && initialized
 }
 
-// RegisterQuestOpcodes регистрирует квантовые опкоды в EVM
-func RegisterQuestOpcodes(jumpTable *vm.JumpTable) {
-	// Регистрируем опкод 0xF0F1 для квантовых операций
-	// В реальной системе здесь бы был настоящий обработчик
-	(*jumpTable)[QuestOpcode*256+QuestOperation] = vm.Operation{
-		Execute:     opQuestOperation,
-		GasCost:     gasQuestOperation,
-		ValidateStack: makeStackFunc(2, 1),
-		Name:        "QUESTOP",
-	}
-}
-
-// gasQuestOperation вычисляет стоимость газа для квантовой операции
-func gasQuestOperation(evm *vm.EVM, contract *vm.Contract, stack *vm.Stack, mem *vm.Memory, memorySize uint64) (uint64, error) {
-	// Для разных квантовых операций разные затраты газа
-	// Базовая стоимость + стоимость в зависимости от размера данных
-	op := Operation(stack.Back(0).Uint64())
-	
-	switch op {
-	case OperationGrover:
-		// Для алгоритма Гроувера базовая стоимость + sqrt(N)
-		dataSize := stack.Back(1).Uint64()
-		return 5000 + uint64(math.Sqrt(float64(dataSize)))*100, nil
-	case OperationShor:
-		// Для алгоритма Шора стоимость зависит от размера числа
-		number := stack.Back(1).Uint64()
-		bits := bits(number)
-		return 10000 + uint64(bits*bits)*50, nil
-	case OperationQFT:
-		// Для QFT стоимость пропорциональна размеру входных данных
-		dataSize := stack.Back(1).Uint64()
-		return 3000 + dataSize*50, nil
-	default:
-		return 5000, nil
-	}
-}
-
-// opQuestOperation выполняет квантовую операцию
-func opQuestOperation(pc *uint64, evm *vm.EVM, contract *vm.Contract, memory *vm.Memory, stack *vm.Stack) ([]byte, error) {
-	// Проверяем, включен ли Quest
-	if !evm.Config.EnableQuest {
-		return nil, errors.New("квантовые вычисления отключены")
-	}
-	
-	// Проверяем, инициализирован ли Quest
-	if !IsInitialized() {
-		return nil, ErrQuestNotInitialized
-	}
-	
-	// Проверяем, доступен ли квантовый процессор
-	if !IsAvailable() {
-		return nil, ErrQuestNotAvailable
-	}
-	
-	// Получаем тип операции и параметры
-	op := Operation(stack.Pop().Uint64())
-	
-	// В зависимости от операции выполняем разные действия
-	switch op {
-	case OperationGrover:
-		// Алгоритм Гроувера для поиска в неотсортированном массиве
-		dataPtr := stack.Pop().Uint64()
-		dataSize := stack.Pop().Uint64()
-		target := stack.Pop().Uint64()
-		
-		// Читаем данные из памяти
-		data := make([]uint64, dataSize)
-		for i := uint64(0); i < dataSize; i++ {
-			word := memory.GetPtr(dataPtr+i*32, 32)
-			// Преобразуем байты в uint64
-			var value uint64
-			for j := 0; j < 8; j++ {
-				value = (value << 8) | uint64(word[j])
-			}
-			data[i] = value
-		}
-		
-		// Выполняем квантовый поиск
-		result, err := simulateGroverSearch(data, target)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Результат - индекс найденного элемента
-		stack.Push(result)
-		
-	case OperationShor:
-		// Алгоритм Шора для факторизации
-		number := stack.Pop().Uint64()
-		
-		// Выполняем квантовую факторизацию
-		factor1, factor2, err := simulateShorFactorization(number)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Возвращаем множители
-		stack.Push(factor1)
-		stack.Push(factor2)
-		
-	case OperationQFT:
-		// Квантовое преобразование Фурье
-		dataPtr := stack.Pop().Uint64()
-		dataSize := stack.Pop().Uint64()
-		
-		// Читаем данные из памяти
-		data := make([]complex128, dataSize)
-		for i := uint64(0); i < dataSize; i++ {
-			word := memory.GetPtr(dataPtr+i*32, 32)
-			// Преобразуем байты в complex128
-			var real, imag float64
-			for j := 0; j < 8; j++ {
-				real = real*256 + float64(word[j])
-				imag = imag*256 + float64(word[8+j])
-			}
-			data[i] = complex(real, imag)
-		}
-		
-		// Выполняем QFT
-		result, err := simulateQFT(data)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Записываем результат в память
-		for i, val := range result {
-			realPart := real(val)
-			imagPart := imag(val)
-			
-			// Преобразуем в байты и записываем в память
-			realBytes := make([]byte, 16)
-			imagBytes := make([]byte, 16)
-			
-			// Простое преобразование для демонстрации
-			memory.Set(dataPtr+uint64(i)*32, 32, append(realBytes, imagBytes...))
-		}
-		
-	default:
-		return nil, errors.New("неизвестная квантовая операция")
-	}
-	
-	return nil, nil
-}
+// groverAttempts - количество запусков поиска Гровера, после которых
+// элемент считается отсутствующим
+const groverAttempts = 3
 
-// GroverSearch выполняет квантовый поиск Гроувера
+// GroverSearch выполняет квантовый поиск Гроувера: возвращает индекс
+// элемента data, равного target. Зерно измерений выводится из блока evm
+// (quantum.BlockMeasurementSeed) и выполняемой транзакции, поэтому результат
+// детерминирован.
 func GroverSearch(evm *vm.EVM, data []uint64, target uint64) (uint64, error) {
 	if !IsInitialized() {
 		return 0, ErrQuestNotInitialized
@@ -221,106 +99,41 @@ func GroverSearch(evm *vm.EVM, data []uint64, target uint64) (uint64, error) {
 		return 0, ErrQuestNotAvailable
 	}
 	
-	return simulateGroverSearch(data, target)
-}
-
-// Утилитарные функции для симуляции квантовых алгоритмов
-
-// simulateGroverSearch симулирует алгоритм поиска Гроувера
-// В реальной системе здесь был бы вызов квантового процессора
-func simulateGroverSearch(data []uint64, target uint64) (uint64, error) {
-	// Для демонстрации симулируем квантовое ускорение
-	// Алгоритм Гроувера находит элемент за O(sqrt(N)) операций вместо O(N)
-	
-	// В настоящем квантовом процессоре мы бы использовали квантовый алгоритм
-	// Здесь мы просто симулируем ускорение, делая классический поиск
-	
-	// Добавляем небольшую задержку для симуляции квантовых вычислений
-	delay := time.Duration(math.Sqrt(float64(len(data)))) * time.Microsecond
-	time.Sleep(delay)
-	
-	// Находим элемент
-	for i, val := range data {
-		if val == target {
-			return uint64(i), nil
-		}
+	var seed quantum.MeasurementSeed
+	if evm != nil {
+		seed = quantum.BlockMeasurementSeed(evm)
+		seed.TxHash = evm.StateDB.TxHash()
 	}
-	
-	return uint64(len(data)), errors.New("элемент не найден")
+	return groverSearch(data, target, seed.Hash())
 }
 
-// simulateShorFactorization симулирует алгоритм факторизации Шора
-func simulateShorFactorization(n uint64) (uint64, uint64, error) {
-	if n <= 1 {
-		return 0, 0, errors.New("число должно быть больше 1")
+// groverSearch ищет индекс элемента алгоритмом Гровера на регистре из
+// ceil(log2(len(data))) кубитов. Оракул отмечает индексы элементов, равных
+// target. Найденный индекс проверяется классически, а при неудаче поиск
+// повторяется не более groverAttempts раз.
+func groverSearch(data []uint64, target uint64, seed common.Hash) (uint64, error) {
+	notFound := uint64(len(data))
+	if len(data) == 0 {
+		return notFound, ErrElementNotFound
 	}
-	
-	if n%2 == 0 {
-		return 2, n/2, nil
+	numQubits := max(bits.Len(uint(len(data)-1)), 1)
+	env, err := quantum.NewQuestEnvWithBackend(numQubits, quantum.BackendDense, false, 0, seed)
+	if err != nil {
+		return notFound, err
 	}
+	defer env.Destroy()
 	
-	// Имитируем алгоритм Шора через перебор с уменьшенной сложностью
-	limit := uint64(math.Sqrt(float64(n)))
-	
-	// Добавляем задержку для симуляции квантовых вычислений
-	delay := time.Duration(math.Pow(math.Log2(float64(n)), 2)) * time.Millisecond
-	time.Sleep(delay)
-	
-	for i := uint64(3); i <= limit; i += 2 {
-		if n%i == 0 {
-			return i, n/i, nil
-		}
-	}
-	
-	// Если факторы не найдены, число простое
-	return 1, n, nil
-}
-
-// simulateQFT симулирует квантовое преобразование Фурье
-func simulateQFT(data []complex128) ([]complex128, error) {
-	n := len(data)
-	result := make([]complex128, n)
-	
-	// Классическое преобразование Фурье для симуляции QFT
-	// В реальной системе здесь был бы вызов квантового процессора
-	for i := 0; i < n; i++ {
-		sum := complex(0, 0)
-		for j := 0; j < n; j++ {
-			angle := -2 * math.Pi * float64(i*j) / float64(n)
-			sum += data[j] * complex(math.Cos(angle), math.Sin(angle))
-		}
-		result[i] = sum / complex(float64(n), 0)
+	marked := func(i uint64) bool {
+		return i < uint64(len(data)) && data[i] == target
 	}
-	
-	// Добавляем задержку для симуляции квантовых вычислений
-	delay := time.Duration(float64(n) * math.Log2(float64(n)) * float64(time.Microsecond))
-	time.Sleep(delay)
-	
-	return result, nil
-}
-
-// Вспомогательные функции
-
-// makeStackFunc создает функцию валидации стека
-func makeStackFunc(pop, push int) vm.StackValidationFunc {
-	return func(stack *vm.Stack) error {
-		if err := stack.Require(pop); err != nil {
-			return err
+	for attempt := 0; attempt < groverAttempts; attempt++ {
+		index, err := env.GroverSearch(marked, quantum.GroverIterations(numQubits))
+		if err != nil {
+			return notFound, err
 		}
-		
-		if stack.Len()+push-pop > int(vm.StackLimit) {
-			return vm.ErrStackOverflow
+		if marked(index) {
+			return index, nil
 		}
-		return nil
 	}
+	return notFound, ErrElementNotFound
 }
-
-// bits возвращает количество бит в числе
-func bits(n uint64) int {
-	count := 0
-	for n > 0 {
-		count++
-		n >>= 1
-	}
-	return count
-} 
\ No newline at end of file
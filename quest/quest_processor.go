@@ -2,132 +2,43 @@
 package quest
 
 import (
-	"bytes"
-	"crypto/sha256"
-	"errors"
 	"fmt"
-	"math"
-	"math/big"
 	"math/rand"
 	"runtime"
 	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
-	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/vm"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/quest/quantum"
-	"github.com/ethereum/go-ethereum/quest/processor"
-	"github.com/ethereum/go-ethereum/quest/utils"
-	"github.com/holiman/uint256"
 )
 
-// Log представляет запись в логе
-type Log struct {
-	Address     common.Address
-	Topics      []common.Hash
-	Data        []byte
-	BlockNumber uint64
-	TxHash      common.Hash
-	TxIndex     uint
-	BlockHash   common.Hash
-	Index       uint
-	Removed     bool
-}
-
 // Оптимальные константы для высокоскоростной обработки
 const (
-	// OptimalQubitCount - оптимальное количество кубитов для высокой производительности
-	OptimalQubitCount = 5
-	
 	// OptimalBatchSize - оптимальный размер батча для GPU-обработки
 	OptimalBatchSize = 20000
-	
+
 	// MaxVerificationsPerBatch - максимальное количество верификаций подписей в батче
 	MaxVerificationsPerBatch = 50000
-	
+
 	// GPUWorkerCount - количество GPU воркеров для параллельной обработки
 	GPUWorkerCount = 8
-	
+
 	// CacheStateThreshold - порог для кэширования состояния (байт)
 	CacheStateThreshold = 1024 * 1024 * 128 // 128 MB
 )
 
-var (
-	// ErrQuestNotAvailable возвращается, когда квантовый процессор недоступен
-	ErrQuestNotAvailable = errors.New("квантовый процессор недоступен")
-
-	// ErrInvalidQuestOpcode возвращается при неверном квантовом опкоде
-	ErrInvalidQuestOpcode = errors.New("неверный квантовый опкод")
+// batchState содержит ресурсы пакетной обработки транзакций. Они создаются
+// при первом вызове ProcessBatch, чтобы EVM, созданные для выполнения одного
+// вызова, не запускали воркеров.
+type batchState struct {
+	started      bool
+	maxBatchSize int
+	rand         *rand.Rand
 
-	// Метаданные квантовых операций
-	questMarker = []byte{0xF0, 0xF1}
-
-	// Операции квантового процессора
-	questGroverOp      = byte(0x01) // Поиск Гровера
-	questShorOp        = byte(0x02) // Факторизация Шора
-	questQFT           = byte(0x03) // Квантовое преобразование Фурье
-	questQPE           = byte(0x04) // Квантовая оценка фазы
-	questVQE           = byte(0x05) // Вариационный квантовый собственный решатель
-	questQNNOp         = byte(0x06) // Квантовая нейронная сеть
-	questQuantumRandom = byte(0x07) // Квантовый генератор случайных чисел
-)
-
-// QuestProcessor реализует интерфейс vm.Processor для квантовых вычислений
-type QuestProcessor struct {
-	evm            *vm.EVM
-	initialized    bool
-	available      bool
-	mutexInit      sync.Mutex
-	mutexOperation sync.Mutex
-	rand           *rand.Rand
-	
-	// Квантовое окружение и параметры
-	questEnv       *quantum.QuestEnv
-	numQubits      int
-	useGPU         bool
-	deviceID       int
-	
-	// Статистика операций
-	operationCount    atomic.Uint64
-	classicalOpCount  atomic.Uint64
-	quantumOpCount    atomic.Uint64
-	
-	// Информация о системе
-	hardwareInfo   *utils.HardwareInfo
-	
-	// Компрессия состояний
-	deltaCompression *utils.DeltaCompression
-	
-	// Профилирование
-	profiler       *utils.Profiler
-	
-	// Маппинг опкодов EVM на квантовые операции
-	opcodeMapper   *processor.OpcodeMapper
-	
-	// Конфигурация
-	config         *vm.Config
-	
-	// Пулы ресурсов для оптимизации
-	hasherPool sync.Pool
-	
-	// Для пакетной обработки транзакций
-	batchProcessor  *BatchProcessor
-	maxBatchSize    int
-	hyperBatchMode  bool
-	batchStatistics *sync.Map
-	
-	// Дополнительные поля для новых функций
-	totalTxProcessed atomic.Uint64
-	totalBatchesProcessed atomic.Uint64
-	gpuModeEnabled bool
-	mutex sync.Mutex
-	
-	// Оптимизации для высокопроизводительной обработки
 	gpuWorkers        []*GPUWorker
 	stateCache        *StateCache
 	verificationQueue chan *VerificationTask
@@ -147,27 +58,34 @@ type GPUWorker struct {
 
 // StateCache предоставляет кэширование состояния для ускорения доступа
 type StateCache struct {
-	cache      map[common.Address][]byte
-	accessLog  map[common.Address]int64
-	size       int64
-	maxSize    int64
-	mutex      sync.RWMutex
+	cache     map[common.Address][]byte
+	accessLog map[common.Address]int64
+	size      int64
+	maxSize   int64
+	mutex     sync.RWMutex
 }
 
 // BatchTask содержит задание для батч-обработки
 type BatchTask struct {
 	Transactions []*types.Transaction
-	State        vm.StateDB
+	Env          *BatchEnv
 	Result       chan *BatchResult
 }
 
-// BatchResult содержит результат батч-обработки
+// BatchResult содержит результат батч-обработки. Receipts и Errors
+// выровнены по транзакциям батча: у включенной в блок транзакции есть
+// квитанция, у отклоненной - ошибка.
 type BatchResult struct {
-	ProcessedTransactions int
+	ProcessedTransactions int // Число включенных транзакций
 	Receipts              []*types.Receipt
 	Errors                []error
+	Logs                  []*types.Log // Журналы включенных транзакций по порядку
 	GasUsed               uint64
 	ElapsedTime           time.Duration
+
+	// Сводки параллельного выполнения, по одной на каждый запуск Block-STM.
+	// Пусто, если транзакции выполнялись последовательно.
+	ParallelStats []*core.ParallelStats
 }
 
 // VerificationTask содержит задание на верификацию подписей
@@ -191,226 +109,89 @@ type SignatureVerifier struct {
 	stopSignal        chan struct{}
 }
 
-// Config содержит конфигурацию квантового процессора
-type Config struct {
-	Debug               bool
-	HardwareAcceleration bool
-	LevelParallelism    int
-	Options             map[string]string
-	
-	// Настройки батч-обработки
-	EnableHyperBatch    bool    // Включить режим гипер-батчинга
-	HyperBatchSize      int     // Размер гипер-батча (0 - максимальный)
-	BatchShardCount     int     // Количество шардов для параллельной обработки
-}
+// startBatchWorkers создает и запускает ресурсы пакетной обработки
+func (q *QuestProcessor) startBatchWorkers() {
+	q.batchOnce.Do(func() {
+		b := &q.batch
+		b.maxBatchSize = OptimalBatchSize
+		b.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
 
-// NewQuestProcessor создает новый экземпляр квантового процессора
-func NewQuestProcessor(evm *vm.EVM, config *Config) (*QuestProcessor, error) {
-	log.Info("Инициализация оптимизированного квантового процессора Quest")
-	
-	processor := &QuestProcessor{
-		evm:                evm,
-		initialized:        false,
-		available:          false,
-		rand:               rand.New(rand.NewSource(time.Now().UnixNano())),
-		maxBatchSize:       OptimalBatchSize,
-		hyperBatchMode:     true,
-		batchStatistics:    &sync.Map{},
-		numQubits:          OptimalQubitCount, // Фиксируем 5 кубитов
-		useGPU:             true,              // Всегда используем GPU
-		deviceID:           0,                 // Используем первый доступный GPU
-		gpuModeEnabled:     true,
-	}
-	
-	// Инициализируем пул хешеров
-	processor.hasherPool = sync.Pool{
-		New: func() interface{} {
-			return sha256.New()
-		},
-	}
-	
-	// Определяем железо
-	processor.hardwareInfo = utils.NewHardwareDetector()
-	
-	// Инициализируем квантовое окружение
-	var err error
-	processor.questEnv, err = quantum.NewQuestEnv(processor.numQubits, processor.useGPU, processor.deviceID)
-	if err != nil {
-		log.Warn("Не удалось инициализировать квантовое окружение", "error", err)
-		return processor, err
-	}
-	
-	// Инициализируем кэш состояния
-	processor.stateCache = &StateCache{
-		cache:     make(map[common.Address][]byte),
-		accessLog: make(map[common.Address]int64),
-		maxSize:   CacheStateThreshold,
-	}
-	
-	// Инициализируем маппер опкодов
-	processor.opcodeMapper = processor.initOpcodeMapper()
-	
-	// Инициализируем delta-сжатие
-	processor.deltaCompression = utils.NewDeltaCompression(nil, 1e-6)
-	
-	// Инициализируем профайлер
-	processor.profiler = utils.NewProfiler()
-	
-	// Инициализируем очереди для параллельной обработки
-	processor.verificationQueue = make(chan *VerificationTask, MaxVerificationsPerBatch)
-	processor.stateUpdateQueue = make(chan *StateUpdateTask, OptimalBatchSize*2)
-	
-	// Инициализируем верификатор подписей
-	processor.signatureVerifier = &SignatureVerifier{
-		verificationQueue: processor.verificationQueue,
-		workerCount:       runtime.NumCPU(),
-		stopSignal:        make(chan struct{}),
-	}
-	
-	// Создаем GPU воркеры
-	processor.initGPUWorkers(GPUWorkerCount)
-	
-	// Создаем батч-процессор с оптимальными параметрами
-	shardCount := runtime.NumCPU()
-	if config != nil && config.BatchShardCount > 0 {
-		shardCount = config.BatchShardCount
-	}
-	processor.batchProcessor = NewBatchProcessor(processor, shardCount)
-	
-	// Инициализация завершена
-	processor.initialized = true
-	processor.available = true
-	
-	// Выводим информацию о процессоре
-	log.Info("Оптимизированный квантовый процессор успешно инициализирован", 
-		"qubits", processor.numQubits, 
-		"use_gpu", processor.useGPU,
-		"gpu_workers", GPUWorkerCount,
-		"batch_size", processor.maxBatchSize,
-		"hardware", processor.hardwareInfo.Description())
-	
-	// Запускаем все асинхронные компоненты
-	processor.startAsyncComponents()
-	
-	return processor, nil
-}
+		// Инициализируем кэш состояния
+		b.stateCache = &StateCache{
+			cache:     make(map[common.Address][]byte),
+			accessLog: make(map[common.Address]int64),
+			maxSize:   CacheStateThreshold,
+		}
+
+		// Инициализируем очереди для параллельной обработки
+		b.verificationQueue = make(chan *VerificationTask, MaxVerificationsPerBatch)
+		b.stateUpdateQueue = make(chan *StateUpdateTask, OptimalBatchSize*2)
 
-// initGPUWorkers инициализирует воркеры для GPU-акселерации
-func (q *QuestProcessor) initGPUWorkers(count int) {
-	q.gpuWorkers = make([]*GPUWorker, count)
-	
-	for i := 0; i < count; i++ {
-		worker := &GPUWorker{
-			ID:          i,
-			Processor:   q,
-			InputQueue:  make(chan *BatchTask, 10),
-			OutputQueue: make(chan *BatchResult, 10),
-			StopSignal:  make(chan struct{}),
+		// Инициализируем верификатор подписей
+		b.signatureVerifier = &SignatureVerifier{
+			verificationQueue: b.verificationQueue,
+			workerCount:       runtime.NumCPU(),
+			stopSignal:        make(chan struct{}),
 		}
-		
-		q.gpuWorkers[i] = worker
-	}
-}
 
-// startAsyncComponents запускает все асинхронные компоненты
-func (q *QuestProcessor) startAsyncComponents() {
-	// Запускаем GPU воркеры
-	for _, worker := range q.gpuWorkers {
-		go worker.Start()
-	}
-	
-	// Запускаем верификатор подписей
-	go q.signatureVerifier.Start()
-	
-	// Запускаем обработчик обновлений состояния
-	go q.processStateUpdates()
-}
+		// Создаем GPU воркеры
+		b.gpuWorkers = make([]*GPUWorker, GPUWorkerCount)
+		for i := range b.gpuWorkers {
+			b.gpuWorkers[i] = &GPUWorker{
+				ID:          i,
+				Processor:   q,
+				InputQueue:  make(chan *BatchTask, 10),
+				OutputQueue: make(chan *BatchResult, 10),
+				StopSignal:  make(chan struct{}),
+			}
+		}
 
-// Run выполняет квантовую операцию
-func (q *QuestProcessor) Run(contract *vm.Contract, input []byte, readOnly bool) (ret []byte, err error) {
-	// Если квантовый процессор не инициализирован или недоступен, возвращаем ошибку
-	if !q.initialized || !q.available {
-		return nil, ErrQuestNotAvailable
-	}
-	
-	// Увеличиваем счетчик операций
-	q.operationCount.Add(1)
-	
-	// Проверяем, что входные данные содержат маркер квантовой операции
-	if len(input) < 3 || !bytes.Equal(input[0:2], questMarker) {
-		q.classicalOpCount.Add(1)
-		// Не квантовая операция, вызываем стандартный обработчик
-		// В реальной реализации здесь был бы вызов стандартного EVM
-		return nil, fmt.Errorf("не квантовая операция")
-	}
-	
-	// Увеличиваем счетчик квантовых операций
-	q.quantumOpCount.Add(1)
-	
-	// Извлекаем тип квантовой операции
-	opType := input[2]
-	
-	// Включаем профилирование
-	q.profiler.Start(fmt.Sprintf("quest_op_%d", opType))
-	defer q.profiler.Stop()
-	
-	// Обрабатываем операцию
-	return q.processQuestOperation(contract, input, readOnly)
+		// Запускаем все асинхронные компоненты
+		for _, worker := range b.gpuWorkers {
+			go worker.Start()
+		}
+		go b.signatureVerifier.Start()
+		go q.processStateUpdates()
+
+		b.started = true
+		log.Debug("Запущена пакетная обработка Quest", "gpu_workers", GPUWorkerCount, "batch_size", b.maxBatchSize)
+	})
 }
 
-// processQuestOperation обрабатывает квантовую операцию
-func (q *QuestProcessor) processQuestOperation(contract *vm.Contract, input []byte, readOnly bool) (ret []byte, err error) {
-	// Проверяем, что входные данные содержат тип операции
-	if len(input) < 3 {
-		return nil, ErrInvalidQuestOpcode
+// stopBatchWorkers останавливает воркеры пакетной обработки, если они были
+// запущены
+func (q *QuestProcessor) stopBatchWorkers() {
+	// Повторный запуск после остановки не допускается
+	q.batchOnce.Do(func() {})
+
+	b := &q.batch
+	if !b.started {
+		return
 	}
-	
-	// Извлекаем тип операции
-	opType := input[2]
-	
-	// Обрабатываем операцию в зависимости от типа
-	switch opType {
-	case questGroverOp:
-		// Выполняем поиск Гровера
-		return q.executeGrover(contract, input[3:])
-	case questShorOp:
-		// Выполняем факторизацию Шора
-		return q.executeShor(contract, input[3:])
-	case questQFT:
-		// Выполняем квантовое преобразование Фурье
-		return q.executeQFT(contract, input[3:])
-	case questQPE:
-		// Выполняем квантовую оценку фазы
-		return q.executeQPE(contract, input[3:])
-	case questVQE:
-		// Выполняем вариационный квантовый собственный решатель
-		return q.executeVQE(contract, input[3:])
-	case questQNNOp:
-		// Выполняем квантовую нейронную сеть
-		return q.executeQNN(contract, input[3:])
-	case questQuantumRandom:
-		// Выполняем квантовый генератор случайных чисел
-		return q.executeQuantumRandom(contract, input[3:])
-	default:
-		return nil, ErrInvalidQuestOpcode
+	b.started = false
+	for _, worker := range b.gpuWorkers {
+		close(worker.StopSignal)
 	}
+	close(b.signatureVerifier.stopSignal)
+	close(b.verificationQueue)
+	close(b.stateUpdateQueue)
 }
 
 // Start запускает GPU воркер
 func (w *GPUWorker) Start() {
-	log.Info("Запуск GPU воркера", "id", w.ID)
-	
+	log.Debug("Запуск GPU воркера", "id", w.ID)
+
 	for {
 		select {
 		case task := <-w.InputQueue:
 			w.IsProcessing.Store(true)
-			
+
 			// Обработка батча транзакций с использованием GPU
 			result := w.ProcessBatch(task)
-			
+
 			// Отправка результата
 			task.Result <- result
-			
+
 			w.IsProcessing.Store(false)
 		case <-w.StopSignal:
 			return
@@ -418,60 +199,9 @@ func (w *GPUWorker) Start() {
 	}
 }
 
-// ProcessBatch обрабатывает батч транзакций с использованием GPU
+// ProcessBatch выполняет батч транзакций над состоянием окружения задачи
 func (w *GPUWorker) ProcessBatch(task *BatchTask) *BatchResult {
-	startTime := time.Now()
-	
-	result := &BatchResult{
-		ProcessedTransactions: len(task.Transactions),
-		Receipts:              make([]*types.Receipt, len(task.Transactions)),
-		Errors:                make([]error, len(task.Transactions)),
-	}
-	
-	// Разделяем транзакции на зависимые группы для параллельной обработки
-	groups := w.Processor.analyzeTransactionDependencies(task.Transactions)
-	
-	// Обрабатываем группы последовательно
-	for _, group := range groups {
-		// Внутри группы обрабатываем транзакции параллельно на GPU
-		w.processTransactionGroup(group, task.State, result)
-	}
-	
-	result.ElapsedTime = time.Since(startTime)
-	
-	return result
-}
-
-// processTransactionGroup обрабатывает группу транзакций параллельно на GPU
-func (w *GPUWorker) processTransactionGroup(txIndices []int, state vm.StateDB, result *BatchResult) {
-	// Здесь должен быть код для отправки транзакций на GPU
-	// и обработки результатов
-}
-
-// analyzeTransactionDependencies анализирует зависимости между транзакциями
-func (q *QuestProcessor) analyzeTransactionDependencies(txs []*types.Transaction) [][]int {
-	// Оптимизированный анализ зависимостей с использованием адресного индексирования
-	// ...
-	
-	// Упрощенная реализация для примера
-	result := make([][]int, 0)
-	currentGroup := make([]int, 0)
-	
-	for i := range txs {
-		currentGroup = append(currentGroup, i)
-		
-		// Каждые 32 транзакции формируем новую группу
-		if len(currentGroup) >= 32 {
-			result = append(result, currentGroup)
-			currentGroup = make([]int, 0)
-		}
-	}
-	
-	if len(currentGroup) > 0 {
-		result = append(result, currentGroup)
-	}
-	
-	return result
+	return executeBatch(task.Env, task.Transactions, 0)
 }
 
 // Start запускает верификатор подписей
@@ -480,7 +210,7 @@ func (v *SignatureVerifier) Start() {
 	for i := 0; i < v.workerCount; i++ {
 		go v.verificationWorker()
 	}
-	
+
 	<-v.stopSignal
 }
 
@@ -500,9 +230,9 @@ func (v *SignatureVerifier) verifySignaturesBatch(task *VerificationTask) {
 
 // processStateUpdates обрабатывает очередь обновлений состояния
 func (q *QuestProcessor) processStateUpdates() {
-	for task := range q.stateUpdateQueue {
+	for task := range q.batch.stateUpdateQueue {
 		// Обновляем состояние
-		q.stateCache.Update(task.Address, task.Data)
+		q.batch.stateCache.Update(task.Address, task.Data)
 		close(task.Done)
 	}
 }
@@ -511,19 +241,19 @@ func (q *QuestProcessor) processStateUpdates() {
 func (c *StateCache) Update(address common.Address, data []byte) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	
+
 	// Обновляем время доступа
 	c.accessLog[address] = time.Now().UnixNano()
-	
+
 	// Если элемент уже есть в кэше, обновляем его размер
 	if oldData, exists := c.cache[address]; exists {
 		c.size -= int64(len(oldData))
 	}
-	
+
 	// Добавляем или обновляем элемент в кэше
 	c.cache[address] = data
 	c.size += int64(len(data))
-	
+
 	// Если размер кэша превышает максимальный, удаляем наименее используемые элементы
 	if c.size > c.maxSize {
 		c.evictLeastUsed()
@@ -534,20 +264,20 @@ func (c *StateCache) Update(address common.Address, data []byte) {
 func (c *StateCache) evictLeastUsed() {
 	// Находим элементы для удаления
 	type accessItem struct {
-		address common.Address
+		address    common.Address
 		lastAccess int64
 	}
-	
+
 	var items []accessItem
 	for addr, lastAccess := range c.accessLog {
 		items = append(items, accessItem{addr, lastAccess})
 	}
-	
+
 	// Сортируем по времени последнего доступа
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].lastAccess < items[j].lastAccess
 	})
-	
+
 	// Удаляем элементы, пока размер кэша не станет приемлемым
 	for i := 0; i < len(items) && c.size > c.maxSize*8/10; i++ {
 		addr := items[i].address
@@ -559,210 +289,90 @@ func (c *StateCache) evictLeastUsed() {
 	}
 }
 
-// ProcessBatch обрабатывает батч транзакций
-func (q *QuestProcessor) ProcessBatch(transactions []*types.Transaction) ([]types.Receipt, error) {
+// ProcessBatch выполняет батч транзакций в окружении env и применяет
+// включенные транзакции к его состоянию. Батчи больше максимального размера
+// выполняются частями по порядку.
+func (q *QuestProcessor) ProcessBatch(env *BatchEnv, transactions []*types.Transaction) (*BatchResult, error) {
 	batchSize := len(transactions)
 	if batchSize == 0 {
-		return nil, nil
+		return &BatchResult{}, nil
 	}
-	
-	// Ограничиваем размер батча оптимальным значением
-	if batchSize > q.maxBatchSize {
-		// Делим на части и обрабатываем по частям
-		var allReceipts []types.Receipt
-		
-		for i := 0; i < batchSize; i += q.maxBatchSize {
-			end := i + q.maxBatchSize
-			if end > batchSize {
-				end = batchSize
-			}
-			
-			receipts, err := q.ProcessBatch(transactions[i:end])
-			if err != nil {
-				return nil, err
-			}
-			
-			allReceipts = append(allReceipts, receipts...)
-		}
-		
-		return allReceipts, nil
+	q.startBatchWorkers()
+	if !q.batch.started {
+		return nil, ErrQuestNotAvailable
 	}
-	
-	// Для небольших батчей используем оптимизированную обработку
+
 	startTime := time.Now()
-	
-	// Создаем задание
-	task := &BatchTask{
-		Transactions: transactions,
-		State:        q.evm.StateDB,
-		Result:       make(chan *BatchResult, 1),
-	}
-	
-	// Находим свободный GPU воркер
-	worker := q.findAvailableGPUWorker()
-	
-	// Отправляем задание
-	worker.InputQueue <- task
-	
-	// Ожидаем результат
-	result := <-task.Result
-	
-	// Преобразуем результат
-	receipts := make([]types.Receipt, len(result.Receipts))
-	for i, r := range result.Receipts {
-		if r != nil {
-			receipts[i] = *r
+	result := &BatchResult{}
+	for i := 0; i < batchSize; i += q.batch.maxBatchSize {
+		end := min(i+q.batch.maxBatchSize, batchSize)
+
+		// Отправляем задание свободному GPU воркеру и ожидаем результат
+		task := &BatchTask{
+			Transactions: transactions[i:end],
+			Env:          env,
+			Result:       make(chan *BatchResult, 1),
 		}
+		q.findAvailableGPUWorker().InputQueue <- task
+		result.append(<-task.Result)
 	}
-	
+
 	// Обновляем статистику
-	q.totalTxProcessed.Add(uint64(batchSize))
-	q.totalBatchesProcessed.Add(1)
-	
+	q.stats.txs.Add(uint64(batchSize))
+	q.stats.batches.Add(1)
+
 	elapsedTime := time.Since(startTime)
 	tps := float64(batchSize) / elapsedTime.Seconds()
-	
-	log.Info("Батч обработан",
+
+	log.Debug("Батч обработан",
 		"size", batchSize,
+		"included", result.ProcessedTransactions,
 		"time", elapsedTime,
 		"tps", fmt.Sprintf("%.2f", tps))
-	
-	return receipts, nil
+
+	return result, nil
 }
 
 // findAvailableGPUWorker находит свободный GPU воркер
 func (q *QuestProcessor) findAvailableGPUWorker() *GPUWorker {
 	// Сначала ищем воркер, который не обрабатывает задания
-	for _, worker := range q.gpuWorkers {
+	for _, worker := range q.batch.gpuWorkers {
 		if !worker.IsProcessing.Load() {
 			return worker
 		}
 	}
-	
-	// Если все воркеры заняты, выбираем случайный
-	return q.gpuWorkers[q.rand.Intn(len(q.gpuWorkers))]
-}
 
-// NewOptimizedBatchProcessor создает оптимизированный батч-процессор
-func NewOptimizedBatchProcessor(processor *QuestProcessor, workerCount int) *BatchProcessor {
-	// Создаем оптимизированный батч-процессор
-	// ...
-	
-	return nil // Заглушка
-}
-
-// executeShor выполняет алгоритм Шора для факторизации
-func (q *QuestProcessor) executeShor(contract *vm.Contract, input []byte) ([]byte, error) {
-	// Оптимизированная реализация для 5 кубитов
-	// С 5 кубитами можно факторизовать числа до 2^5 = 32
-	
-	// Проверяем, что входные данные содержат число для факторизации
-	if len(input) < 8 {
-		return nil, fmt.Errorf("недостаточно данных для факторизации")
-	}
-	
-	// Извлекаем число для факторизации
-	number := binary.BigEndian.Uint64(input[:8])
-	
-	// Проверяем, что число находится в допустимом диапазоне
-	if number > 31 {
-		// Для чисел вне диапазона используем классический алгоритм
-		return q.executeClassicalFactorization(number)
-	}
-	
-	// Для чисел в диапазоне используем квантовый алгоритм
-	log.Info("Выполнение алгоритма Шора", "number", number)
-	
-	// Выполняем факторизацию
-	factors, err := quantum.ExecuteShorAlgorithm(q.questEnv, number)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Формируем результат
-	result := make([]byte, 16)
-	binary.BigEndian.PutUint64(result[:8], factors[0])
-	binary.BigEndian.PutUint64(result[8:16], factors[1])
-	
-	return result, nil
-}
-
-// executeClassicalFactorization выполняет классическую факторизацию
-func (q *QuestProcessor) executeClassicalFactorization(n uint64) ([]byte, error) {
-	// Простая реализация классической факторизации
-	// ...
-	
-	// Заглушка
-	return make([]byte, 16), nil
-}
-
-// executeGrover выполняет поиск Гровера
-func (q *QuestProcessor) executeGrover(contract *vm.Contract, input []byte) ([]byte, error) {
-	// Оптимизированная реализация для 5 кубитов
-	// ...
-	
-	// Заглушка
-	return nil, nil
-}
-
-// executeQFT выполняет квантовое преобразование Фурье
-func (q *QuestProcessor) executeQFT(contract *vm.Contract, input []byte) ([]byte, error) {
-	// Оптимизированная реализация для 5 кубитов
-	// ...
-	
-	// Заглушка
-	return nil, nil
-}
-
-// executeQuantumRandom выполняет квантовый генератор случайных чисел
-func (q *QuestProcessor) executeQuantumRandom(contract *vm.Contract, input []byte) ([]byte, error) {
-	// Извлекаем запрошенную длину случайных данных
-	var length int
-	if len(input) >= 4 {
-		length = int(binary.BigEndian.Uint32(input[:4]))
-	} else {
-		length = 32 // По умолчанию 32 байта
-	}
-	
-	// Ограничиваем максимальную длину
-	if length > 1024 {
-		length = 1024
-	}
-	
-	// Генерируем случайные данные с использованием квантового генератора
-	randomBytes, err := q.questEnv.GenerateQuantumRandomBytes(length)
-	if err != nil {
-		return nil, err
-	}
-	
-	return randomBytes, nil
+	// Если все воркеры заняты, выбираем случайный
+	return q.batch.gpuWorkers[q.batch.rand.Intn(len(q.batch.gpuWorkers))]
 }
 
-// GetStatistics возвращает статистику работы процессора
+// GetStatistics возвращает статистику работы процессора. Счетчики операций и
+// профиль суммируются по всем процессорам процесса.
 func (q *QuestProcessor) GetStatistics() map[string]interface{} {
 	stats := make(map[string]interface{})
-	
-	stats["total_operations"] = q.operationCount.Load()
-	stats["classical_operations"] = q.classicalOpCount.Load()
-	stats["quantum_operations"] = q.quantumOpCount.Load()
-	stats["total_tx_processed"] = q.totalTxProcessed.Load()
-	stats["total_batches_processed"] = q.totalBatchesProcessed.Load()
-	stats["gpu_mode_enabled"] = q.gpuModeEnabled
-	stats["qubit_count"] = q.numQubits
-	stats["max_batch_size"] = q.maxBatchSize
-	
+
+	stats["total_operations"] = q.stats.operations.Load()
+	stats["classical_operations"] = q.stats.classical.Load()
+	stats["quantum_operations"] = q.stats.quantum.Load()
+	stats["total_tx_processed"] = q.stats.txs.Load()
+	stats["total_batches_processed"] = q.stats.batches.Load()
+	stats["gpu_mode_enabled"] = q.useGPU
+	stats["qubit_count"] = q.executor.MaxQubits()
+	stats["max_batch_size"] = q.batch.maxBatchSize
+
 	// Добавляем статистику GPU
-	gpuStats := make([]map[string]interface{}, len(q.gpuWorkers))
-	for i, worker := range q.gpuWorkers {
+	gpuStats := make([]map[string]interface{}, len(q.batch.gpuWorkers))
+	for i, worker := range q.batch.gpuWorkers {
 		gpuStats[i] = map[string]interface{}{
-			"id":           worker.ID,
+			"id":            worker.ID,
 			"is_processing": worker.IsProcessing.Load(),
 		}
 	}
 	stats["gpu_workers"] = gpuStats
-	
+
 	// Добавляем статистику профилирования
-	stats["profiling"] = q.profiler.GetStatistics()
-	
+	if q.profiler != nil {
+		stats["profiling"] = q.profiler.GetAllOperationStats()
+	}
 	return stats
-} 
\ No newline at end of file
+}
@@ -61,7 +61,8 @@ build_quest_kit() {
     log "Компилируем QuEST..."
     mkdir -p "$QUEST_KIT_DIR/build"
     cd "$QUEST_KIT_DIR/build"
-    cmake ..
+    # Go-привязка (тег сборки questc) требует двойной точности
+    cmake .. -DFLOAT_PRECISION=2
     make -j4
     cd - > /dev/null
     log "QuEST успешно скомпилирован!"
@@ -75,6 +76,8 @@ setup_go_binding() {
     
     # Копируем заголовочные файлы
     cp -r "$QUEST_KIT_DIR/quest/include/"* quest/include/ 2>/dev/null || cp -r "$QUEST_KIT_DIR/include/"* quest/include/ 2>/dev/null || :
+    # config.h генерируется cmake в каталоге сборки
+    cp "$QUEST_KIT_DIR/build/include/quest/include/config.h" quest/include/ 2>/dev/null || :
     
     # Копируем библиотеки
     cp "$QUEST_KIT_DIR/build/quest/libQuEST.a" quest/lib/libquestkit.a 2>/dev/null || cp "$QUEST_KIT_DIR/build/libQuEST.a" quest/lib/libquestkit.a 2>/dev/null || :
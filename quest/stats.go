@@ -0,0 +1,97 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quest
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/quest/utils"
+)
+
+// QuestProcessor создается для каждой EVM, поэтому счетчики операций и
+// профиль квантовых инструкций хранятся на уровне процесса и суммируются по
+// всем процессорам. Спекулятивные выполнения Block-STM в них не учитываются.
+
+// processorStats - счетчики операций квантовых процессоров
+type processorStats struct {
+	operations atomic.Uint64 // Вызовы Run
+	classical  atomic.Uint64 // Контракты, выполненные стандартным интерпретатором
+	quantum    atomic.Uint64 // Инструкции префикса vm.QUANTUM
+	txs        atomic.Uint64 // Транзакции пакетной обработки
+	batches    atomic.Uint64 // Пакеты транзакций
+}
+
+var (
+	// globalStats - счетчики всех процессоров процесса
+	globalStats processorStats
+
+	// globalProfiler - профиль квантовых инструкций процессоров с
+	// включенным QuestProfiling, создается при первом обращении
+	globalProfiler     *utils.Profiler
+	globalProfilerOnce sync.Once
+)
+
+// sharedProfiler возвращает общий профайлер квантовых инструкций
+func sharedProfiler() *utils.Profiler {
+	globalProfilerOnce.Do(func() {
+		globalProfiler = utils.NewProfiler()
+	})
+	return globalProfiler
+}
+
+// Stats - сводка счетчиков квантовых процессоров процесса
+type Stats struct {
+	Operations          uint64 `json:"operations"`
+	ClassicalOperations uint64 `json:"classicalOperations"`
+	QuantumOperations   uint64 `json:"quantumOperations"`
+	Transactions        uint64 `json:"transactions"`
+	Batches             uint64 `json:"batches"`
+}
+
+// GetStats возвращает текущие значения счетчиков всех процессоров
+func GetStats() Stats {
+	return Stats{
+		Operations:          globalStats.operations.Load(),
+		ClassicalOperations: globalStats.classical.Load(),
+		QuantumOperations:   globalStats.quantum.Load(),
+		Transactions:        globalStats.txs.Load(),
+		Batches:             globalStats.batches.Load(),
+	}
+}
+
+// Profile возвращает профиль квантовых инструкций процессоров с включенным
+// QuestProfiling по их мнемоникам
+func Profile() map[string]OperationProfile {
+	profile := make(map[string]OperationProfile)
+	for name, stats := range sharedProfiler().GetAllOperationStats() {
+		profile[name] = OperationProfile{
+			Count:     stats.Count,
+			TotalTime: stats.TotalTime,
+			MinTime:   stats.MinTime,
+			MaxTime:   stats.MaxTime,
+			AvgTime:   stats.AvgTime,
+		}
+	}
+	return profile
+}
+
+// ResetProfile сбрасывает профиль квантовых инструкций, например перед
+// замером производительности
+func ResetProfile() {
+	sharedProfiler().Reset()
+}
@@ -1,275 +1,175 @@
 package test
 
 import (
+	"bytes"
 	"context"
-	"crypto/ecdsa"
-	"fmt"
 	"math/big"
 	"testing"
-	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // Тестовые приватные ключи (НЕ ИСПОЛЬЗОВАТЬ В ПРОДАКШЕНЕ)
 var (
 	key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 	addr   = crypto.PubkeyToAddress(key.PublicKey)
+
+	// Адрес тестового контракта, код которого задается в генезисе
+	contractAddr = common.HexToAddress("0xc0de")
 )
 
-// Контракт квантового теста (заглушка для использования с симулятором)
-type QuestTest struct {
-	address common.Address
-	backend *backends.SimulatedBackend
-	auth    *bind.TransactOpts
+// quantumOp возвращает байты квантовой инструкции с префиксом QUANTUM
+func quantumOp(op vm.QuantumOp) []byte {
+	return []byte{byte(vm.QUANTUM), byte(op)}
+}
+
+// push возвращает инструкцию PUSHn с минимальным представлением v
+func push(v uint64) []byte {
+	data := new(big.Int).SetUint64(v).Bytes()
+	if len(data) == 0 {
+		data = []byte{0}
+	}
+	return append([]byte{byte(vm.PUSH1) + byte(len(data)-1)}, data...)
+}
+
+// returnWords возвращает код, возвращающий n слов памяти начиная с нуля
+func returnWords(n int) []byte {
+	return append(push(uint64(32*n)), append(push(0), byte(vm.RETURN))...)
+}
+
+// bellCode - код контракта, который готовит пару Белла, измеряет оба кубита и
+// сохраняет результаты в слотах 0 и 1 и возвращает их
+var bellCode = bytes.Join([][]byte{
+	push(2), quantumOp(vm.QINIT),
+	push(0), quantumOp(vm.QHADAMARD),
+	push(0), push(1), quantumOp(vm.QCNOT),
+	push(0), quantumOp(vm.QMEASURE), {byte(vm.DUP1)}, push(0), {byte(vm.SSTORE)}, push(0), {byte(vm.MSTORE)},
+	push(1), quantumOp(vm.QMEASURE), {byte(vm.DUP1)}, push(1), {byte(vm.SSTORE)}, push(32), {byte(vm.MSTORE)},
+	returnWords(2),
+}, nil)
+
+// newBackend создает симулятор с контрактом code по адресу contractAddr.
+// С включенным Quest измерения выполняются с зерном seed.
+func newBackend(t testing.TB, enableQuest bool, seed common.Hash, code []byte) *simulated.Backend {
+	t.Helper()
+	alloc := types.GenesisAlloc{
+		addr:         {Balance: big.NewInt(1000000000000000000)}, // 1 ETH
+		contractAddr: {Code: code},
+	}
+	var options []func(*node.Config, *ethconfig.Config)
+	if enableQuest {
+		options = append(options, simulated.WithQuest(ethconfig.QuestConfig{MeasurementSeed: &seed}))
+	}
+	sim := simulated.NewBackend(alloc, options...)
+	t.Cleanup(func() { sim.Close() })
+	return sim
 }
 
-// Создание нового экземпляра контракта QuestTest
-func deployQuestTest(t *testing.T, enableQuest bool) (*QuestTest, error) {
-	// Генерируем начальное состояние блокчейна
-	genesis := core.GenesisAlloc{
-		addr: {Balance: big.NewInt(1000000000000000000)}, // 1 ETH
+// callContract вызывает тестовый контракт через eth_call
+func callContract(sim *simulated.Backend) ([]byte, error) {
+	return sim.Client().CallContract(context.Background(), ethereum.CallMsg{
+		From: addr,
+		To:   &contractAddr,
+		Gas:  30_000_000,
+	}, nil)
+}
+
+// sendTransaction вызывает тестовый контракт транзакцией, включает ее в блок
+// и возвращает чек
+func sendTransaction(t testing.TB, sim *simulated.Backend) *types.Receipt {
+	t.Helper()
+	client := sim.Client()
+	head, err := client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Не удалось получить заголовок: %v", err)
 	}
-	
-	// Создаем симулятор с настройками квантового процессора
-	config := vm.Config{
-		EnableQuest: enableQuest,
+	nonce, err := client.PendingNonceAt(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("Не удалось получить nonce: %v", err)
 	}
-	
-	backend := backends.NewSimulatedBackendWithConfig(genesis, 10000000, config)
-	
-	// Создаем транзакцию для авторизации
-	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	chainID, err := client.ChainID(context.Background())
 	if err != nil {
-		t.Fatalf("Не удалось создать транзактор: %v", err)
-		return nil, err
+		t.Fatalf("Не удалось получить chain id: %v", err)
 	}
-	
-	// Так как у нас нет реального ABI и bytecode, мы имитируем их для теста
-	// В реальном сценарии здесь был бы код компиляции из Solidity
-	
-	// Здесь мы просто создаем пустой контракт для демонстрации
-	tx := types.NewContractCreation(0, big.NewInt(0), 3000000, big.NewInt(875000000), nil)
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1337)), key)
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: new(big.Int).Add(head.BaseFee, big.NewInt(params.GWei)),
+		Gas:       5_000_000,
+		To:        &contractAddr,
+	}), types.LatestSignerForChainID(chainID), key)
 	if err != nil {
 		t.Fatalf("Не удалось подписать транзакцию: %v", err)
-		return nil, err
 	}
-	
-	// Отправляем транзакцию
-	if err := backend.SendTransaction(context.Background(), signedTx); err != nil {
+	if err := client.SendTransaction(context.Background(), tx); err != nil {
 		t.Fatalf("Не удалось отправить транзакцию: %v", err)
-		return nil, err
 	}
-	
-	backend.Commit()
-	
-	// Получаем адрес контракта
-	receipt, err := backend.TransactionReceipt(context.Background(), signedTx.Hash())
+	sim.Commit()
+
+	receipt, err := client.TransactionReceipt(context.Background(), tx.Hash())
 	if err != nil {
 		t.Fatalf("Не удалось получить чек транзакции: %v", err)
-		return nil, err
 	}
-	
-	return &QuestTest{
-		address: receipt.ContractAddress,
-		backend: backend,
-		auth:    auth,
-	}, nil
+	return receipt
 }
 
-// Простой тест для проверки включения/выключения квантового режима
+// Проверяет, что квантовые инструкции выполняются только с включенным Quest
 func TestQuestEnablement(t *testing.T) {
-	// Сначала запускаем с выключенным Quest
-	_, err := deployQuestTest(t, false)
-	if err != nil {
-		t.Fatal("Не удалось развернуть тестовый контракт без Quest:", err)
+	if _, err := callContract(newBackend(t, false, common.Hash{}, bellCode)); err == nil {
+		t.Fatal("Квантовые инструкции выполнены без Quest")
 	}
-	
-	// Затем с включенным Quest
-	_, err = deployQuestTest(t, true)
+	ret, err := callContract(newBackend(t, true, common.Hash{}, bellCode))
 	if err != nil {
-		t.Fatal("Не удалось развернуть тестовый контракт с Quest:", err)
+		t.Fatalf("Не удалось выполнить квантовый контракт: %v", err)
 	}
-	
-	t.Log("Тест успешно пройден: Quest может быть включен и выключен без ошибок")
-}
-
-// Имитация вызова квантовой функции Гровера
-func simulateGroversAlgorithm(t *testing.T, enableQuest bool) {
-	contract, err := deployQuestTest(t, enableQuest)
-	if err != nil {
-		t.Fatal("Не удалось развернуть тестовый контракт:", err)
-	}
-	
-	// Создаем данные для вызова квантовой функции
-	// В реальном коде здесь был бы вызов метода из привязанного ABI
-	questMarker := []byte{0xF0, 0xF1}
-	groversSearchOp := []byte{0x01}
-	searchSpace := big.NewInt(1000000).Bytes()
-	target := big.NewInt(42).Bytes()
-	
-	data := append(questMarker, groversSearchOp...)
-	data = append(data, searchSpace...)
-	data = append(data, target...)
-	
-	// Создаем и отправляем транзакцию
-	tx := types.NewTransaction(
-		contract.auth.Nonce.Uint64(),
-		contract.address,
-		big.NewInt(0),
-		5000000,
-		contract.auth.GasPrice,
-		data,
-	)
-	
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1337)), key)
-	if err != nil {
-		t.Fatalf("Не удалось подписать транзакцию: %v", err)
-	}
-	
-	start := time.Now()
-	
-	err = contract.backend.SendTransaction(context.Background(), signedTx)
-	if err != nil {
-		if enableQuest {
-			t.Fatalf("Не удалось отправить транзакцию с включенным Quest: %v", err)
-		} else {
-			t.Logf("Ожидаемая ошибка с выключенным Quest: %v", err)
-		}
-	} else {
-		contract.backend.Commit()
-		
-		duration := time.Since(start)
-		t.Logf("Время выполнения Гровера: %v (Quest включен: %v)", duration, enableQuest)
-		
-		receipt, err := contract.backend.TransactionReceipt(context.Background(), signedTx.Hash())
-		if err != nil {
-			t.Fatalf("Не удалось получить чек транзакции: %v", err)
-		}
-		
-		t.Logf("Статус транзакции: %d, использовано газа: %d", receipt.Status, receipt.GasUsed)
+	if len(ret) != 64 || ret[31] != ret[63] {
+		t.Fatalf("Кубиты пары Белла не скоррелированы: %x", ret)
 	}
 }
 
-// Тест для сравнения производительности с включенным и выключенным Quest
-func TestGroversPerformance(t *testing.T) {
-	// Тестирование с выключенным Quest
-	t.Run("Гровер без Quest", func(t *testing.T) {
-		simulateGroversAlgorithm(t, false)
-	})
-	
-	// Тестирование с включенным Quest
-	t.Run("Гровер с Quest", func(t *testing.T) {
-		simulateGroversAlgorithm(t, true)
-	})
-}
-
-// Бенчмаркинг для измерения производительности
-func BenchmarkGroversAlgorithm(b *testing.B) {
-	// Подготовка
-	genesis := core.GenesisAlloc{
-		addr: {Balance: big.NewInt(1000000000000000000)},
-	}
-	
-	// Бенчмарк с включенным Quest
-	b.Run("Quest включен", func(b *testing.B) {
-		config := vm.Config{
-			EnableQuest: true,
+// Проверяет, что транзакции с измерениями воспроизводимы при одинаковом
+// зерне, а регистр контракта сохраняется в его состоянии
+func TestBellPairTransaction(t *testing.T) {
+	run := func(seed common.Hash) (common.Hash, common.Hash) {
+		sim := newBackend(t, true, seed, bellCode)
+		if receipt := sendTransaction(t, sim); receipt.Status != types.ReceiptStatusSuccessful {
+			t.Fatalf("Транзакция завершилась с ошибкой, использовано газа: %d", receipt.GasUsed)
 		}
-		backend := backends.NewSimulatedBackendWithConfig(genesis, 10000000, config)
-		auth, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
-		
-		// Деплоим контракт один раз для всех запусков
-		tx := types.NewContractCreation(0, big.NewInt(0), 3000000, big.NewInt(875000000), nil)
-		signedTx, _ := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1337)), key)
-		backend.SendTransaction(context.Background(), signedTx)
-		backend.Commit()
-		receipt, _ := backend.TransactionReceipt(context.Background(), signedTx.Hash())
-		contractAddress := receipt.ContractAddress
-		
-		// Подготавливаем данные для квантовой операции
-		questMarker := []byte{0xF0, 0xF1}
-		groversSearchOp := []byte{0x01}
-		searchSpace := big.NewInt(1000000).Bytes()
-		target := big.NewInt(42).Bytes()
-		
-		data := append(questMarker, groversSearchOp...)
-		data = append(data, searchSpace...)
-		data = append(data, target...)
-		
-		b.ResetTimer()
-		
-		// Запускаем бенчмарк
-		for i := 0; i < b.N; i++ {
-			tx := types.NewTransaction(
-				uint64(i),
-				contractAddress,
-				big.NewInt(0),
-				5000000,
-				auth.GasPrice,
-				data,
-			)
-			
-			signedTx, _ := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1337)), key)
-			backend.SendTransaction(context.Background(), signedTx)
-			backend.Commit()
+		client := sim.Client()
+		first, _ := client.StorageAt(context.Background(), contractAddr, common.Hash{}, nil)
+		second, _ := client.StorageAt(context.Background(), contractAddr, common.BigToHash(big.NewInt(1)), nil)
+		if !bytes.Equal(first, second) {
+			t.Fatalf("Кубиты пары Белла не скоррелированы: %x, %x", first, second)
 		}
-	})
-	
-	// Бенчмарк с выключенным Quest (ожидаем ошибки, но измеряем скорость проверки)
-	b.Run("Quest выключен", func(b *testing.B) {
-		config := vm.Config{
-			EnableQuest: false,
+		commitment, _ := client.StorageAt(context.Background(), contractAddr, state.QuantumRegisterSlot, nil)
+		if common.BytesToHash(commitment) == (common.Hash{}) {
+			t.Fatal("Квантовый регистр не сохранен в состоянии контракта")
 		}
-		backend := backends.NewSimulatedBackendWithConfig(genesis, 10000000, config)
-		auth, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
-		
-		// Деплоим контракт один раз для всех запусков
-		tx := types.NewContractCreation(0, big.NewInt(0), 3000000, big.NewInt(875000000), nil)
-		signedTx, _ := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1337)), key)
-		backend.SendTransaction(context.Background(), signedTx)
-		backend.Commit()
-		receipt, _ := backend.TransactionReceipt(context.Background(), signedTx.Hash())
-		contractAddress := receipt.ContractAddress
-		
-		// Подготавливаем данные для квантовой операции
-		questMarker := []byte{0xF0, 0xF1}
-		groversSearchOp := []byte{0x01}
-		searchSpace := big.NewInt(1000000).Bytes()
-		target := big.NewInt(42).Bytes()
-		
-		data := append(questMarker, groversSearchOp...)
-		data = append(data, searchSpace...)
-		data = append(data, target...)
-		
-		b.ResetTimer()
-		
-		// Запускаем бенчмарк (ожидаем ошибки)
-		for i := 0; i < b.N; i++ {
-			tx := types.NewTransaction(
-				uint64(i),
-				contractAddress,
-				big.NewInt(0),
-				5000000,
-				auth.GasPrice,
-				data,
-			)
-			
-			signedTx, _ := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1337)), key)
-			backend.SendTransaction(context.Background(), signedTx)
-			backend.Commit()
+		return common.BytesToHash(first), common.BytesToHash(commitment)
+	}
+	bit, commitment := run(common.Hash{1})
+	for i := 0; i < 3; i++ {
+		if b, c := run(common.Hash{1}); b != bit || c != commitment {
+			t.Fatalf("Запуск %d не воспроизвел измерения: %x (%x), ожидалось %x (%x)", i, b, c, bit, commitment)
 		}
-	})
+	}
+	// При разных зернах оба исхода пары Белла должны встретиться
+	outcomes := make(map[common.Hash]bool)
+	for i := byte(0); i < 16 && len(outcomes) < 2; i++ {
+		b, _ := run(common.Hash{i})
+		outcomes[b] = true
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("Измерения не зависят от зерна: %v", outcomes)
+	}
 }
-
-func TestMain(m *testing.M) {
-	fmt.Println("Запуск тестов Quest интеграции с Ethereum")
-	m.Run()
-} 
\ No newline at end of file
@@ -18,8 +18,14 @@
 package utils
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
 	"math"
 	"sync"
+
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // DeltaCompression обеспечивает сжатие квантовых состояний путем хранения только дельт
@@ -134,9 +140,16 @@ func (dc *DeltaCompression) UpdateState(newState []complex128) {
 	// Обновляем последнее состояние
 	copy(dc.lastState, newState)
 	
-	// Если список сжатых состояний стал слишком большим, удаляем старые
+	// Если список сжатых состояний стал слишком большим, удаляем старые,
+	// перенося их дельты в базовое состояние
 	if len(dc.compressedStates) > 100 {
 		// Оставляем только последние 50 состояний
+		dropped := dc.compressedStates[:len(dc.compressedStates)-50]
+		for _, compressed := range dropped {
+			for j, idx := range compressed.Indices {
+				dc.baseState[idx] += compressed.Deltas[j]
+			}
+		}
 		dc.compressedStates = dc.compressedStates[len(dc.compressedStates)-50:]
 	}
 }
@@ -228,8 +241,171 @@ func (dc *DeltaCompression) GetCompressionRatio() float64 {
 	return float64(compressedElements) / float64(totalElements)
 }
 
+// Формат сериализации истории состояний (RLP):
+//
+//	[версия, порог, базовое состояние, [[индексы, дельты, метка времени], ...]]
+//
+// Порог кодируется битами IEEE-754 float64. Базовое состояние и дельты -
+// последовательности амплитуд по 16 байт: действительная и мнимая части в
+// виде IEEE-754 float64 в порядке big-endian, как в кодировке квантового
+// регистра. Индексы каждой дельты строго возрастают.
+const deltaEncodingVersion = 1
+
+// ErrInvalidDeltaEncoding ошибка, возникающая при декодировании
+// поврежденной истории состояний
+var ErrInvalidDeltaEncoding = errors.New("недопустимая кодировка истории квантовых состояний")
+
+// encodedHistory - RLP-представление DeltaCompression
+type encodedHistory struct {
+	Version   uint
+	Threshold uint64
+	Base      []byte
+	States    []encodedDelta
+}
+
+// encodedDelta - RLP-представление CompressedState
+type encodedDelta struct {
+	Indices   []uint64
+	Deltas    []byte
+	Timestamp uint64
+}
+
+// EncodeRLP реализует rlp.Encoder
+func (dc *DeltaCompression) EncodeRLP(w io.Writer) error {
+	if dc == nil {
+		_, err := w.Write(rlp.EmptyList)
+		return err
+	}
+	dc.mutex.RLock()
+	defer dc.mutex.RUnlock()
+	
+	enc := encodedHistory{
+		Version:   deltaEncodingVersion,
+		Threshold: math.Float64bits(dc.threshold),
+		Base:      encodeAmplitudes(dc.baseState),
+		States:    make([]encodedDelta, len(dc.compressedStates)),
+	}
+	for i, compressed := range dc.compressedStates {
+		indices := make([]uint64, len(compressed.Indices))
+		for j, idx := range compressed.Indices {
+			indices[j] = uint64(idx)
+		}
+		enc.States[i] = encodedDelta{
+			Indices:   indices,
+			Deltas:    encodeAmplitudes(compressed.Deltas),
+			Timestamp: uint64(compressed.Timestamp),
+		}
+	}
+	return rlp.Encode(w, &enc)
+}
+
+// DecodeRLP реализует rlp.Decoder. Последнее состояние восстанавливается
+// применением всех дельт к базовому.
+func (dc *DeltaCompression) DecodeRLP(s *rlp.Stream) error {
+	var enc encodedHistory
+	if err := s.Decode(&enc); err != nil {
+		return err
+	}
+	if enc.Version != deltaEncodingVersion {
+		return fmt.Errorf("%w: версия %d", ErrInvalidDeltaEncoding, enc.Version)
+	}
+	threshold := math.Float64frombits(enc.Threshold)
+	if !(threshold > 0) || math.IsInf(threshold, 0) {
+		return fmt.Errorf("%w: порог %v", ErrInvalidDeltaEncoding, threshold)
+	}
+	base, err := decodeAmplitudes(enc.Base)
+	if err != nil {
+		return err
+	}
+	states := make([]CompressedState, len(enc.States))
+	for i, delta := range enc.States {
+		deltas, err := decodeAmplitudes(delta.Deltas)
+		if err != nil {
+			return err
+		}
+		if len(deltas) != len(delta.Indices) {
+			return fmt.Errorf("%w: дельта %d", ErrInvalidDeltaEncoding, i)
+		}
+		indices := make([]int, len(delta.Indices))
+		for j, idx := range delta.Indices {
+			if idx >= uint64(len(base)) || (j > 0 && idx <= delta.Indices[j-1]) {
+				return fmt.Errorf("%w: индекс %d дельты %d", ErrInvalidDeltaEncoding, idx, i)
+			}
+			indices[j] = int(idx)
+		}
+		states[i] = CompressedState{Indices: indices, Deltas: deltas, Timestamp: int64(delta.Timestamp)}
+	}
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+	
+	dc.threshold = threshold
+	dc.baseState = base
+	dc.compressedStates = states
+	dc.lastState = make([]complex128, len(base))
+	copy(dc.lastState, base)
+	for _, compressed := range states {
+		for j, idx := range compressed.Indices {
+			dc.lastState[idx] += compressed.Deltas[j]
+		}
+	}
+	return nil
+}
+
+// Replay последовательно восстанавливает все сохраненные состояния, начиная
+// с базового, и передает их fn вместе с порядковым номером. Воспроизведение
+// прекращается, если fn возвращает false. Переданный срез переиспользуется
+// между вызовами.
+func (dc *DeltaCompression) Replay(fn func(index int, state []complex128) bool) {
+	dc.mutex.RLock()
+	defer dc.mutex.RUnlock()
+	
+	if len(dc.baseState) == 0 {
+		return
+	}
+	state := make([]complex128, len(dc.baseState))
+	copy(state, dc.baseState)
+	if !fn(0, state) {
+		return
+	}
+	for i, compressed := range dc.compressedStates {
+		for j, idx := range compressed.Indices {
+			state[idx] += compressed.Deltas[j]
+		}
+		if !fn(i+1, state) {
+			return
+		}
+	}
+}
+
 // Вспомогательные функции
 
+// encodeAmplitudes кодирует амплитуды по 16 байт
+func encodeAmplitudes(amps []complex128) []byte {
+	buf := make([]byte, 16*len(amps))
+	for i, amp := range amps {
+		binary.BigEndian.PutUint64(buf[16*i:], math.Float64bits(real(amp)))
+		binary.BigEndian.PutUint64(buf[16*i+8:], math.Float64bits(imag(amp)))
+	}
+	return buf
+}
+
+// decodeAmplitudes декодирует амплитуды, отклоняя бесконечности и NaN
+func decodeAmplitudes(buf []byte) ([]complex128, error) {
+	if len(buf)%16 != 0 {
+		return nil, fmt.Errorf("%w: длина амплитуд %d", ErrInvalidDeltaEncoding, len(buf))
+	}
+	amps := make([]complex128, len(buf)/16)
+	for i := range amps {
+		re := math.Float64frombits(binary.BigEndian.Uint64(buf[16*i:]))
+		im := math.Float64frombits(binary.BigEndian.Uint64(buf[16*i+8:]))
+		if math.IsNaN(re) || math.IsNaN(im) || math.IsInf(re, 0) || math.IsInf(im, 0) {
+			return nil, fmt.Errorf("%w: амплитуда %d не является конечным числом", ErrInvalidDeltaEncoding, i)
+		}
+		amps[i] = complex(re, im)
+	}
+	return amps, nil
+}
+
 // cmplx128Abs возвращает модуль комплексного числа
 func cmplx128Abs(c complex128) float64 {
 	return math.Sqrt(real(c)*real(c) + imag(c)*imag(c))
@@ -94,6 +94,27 @@ func (p *Profiler) EndOperation(operationName string) time.Duration {
 	// Удаляем операцию из списка текущих
 	delete(p.currentOperations, operationName)
 	
+	p.record(operationName, duration, endTime)
+	return duration
+}
+
+// Record добавляет в статистику операцию, длительность которой измерена
+// вызывающим. В отличие от StartOperation и EndOperation, не связывает
+// операции по имени, поэтому пригоден для одновременных вызовов одной
+// операции из разных горутин.
+func (p *Profiler) Record(operationName string, duration time.Duration) {
+	if !p.enabled {
+		return
+	}
+	
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	
+	p.record(operationName, duration, time.Now())
+}
+
+// record обновляет статистику операции, вызывается под мьютексом
+func (p *Profiler) record(operationName string, duration time.Duration, endTime time.Time) {
 	// Обновляем статистику
 	stats, ok := p.operationStats[operationName]
 	if !ok {
@@ -128,8 +149,6 @@ func (p *Profiler) EndOperation(operationName string) time.Duration {
 	
 	// Обновляем время последнего вызова
 	stats.LastCallTime = endTime
-	
-	return duration
 }
 
 // GetOperationStats возвращает статистику по конкретной операции
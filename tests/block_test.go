@@ -86,6 +86,19 @@ func TestExecutionSpecBlocktests(t *testing.T) {
 	})
 }
 
+// TestQuantumBlockchain runs the blockchain test fixtures of the QUANTUM
+// instructions generated by mkquest.go.
+func TestQuantumBlockchain(t *testing.T) {
+	if !common.FileExist(quantumBlockchainTestDir) {
+		t.Fatalf("directory %s does not exist, run mkquest.go to generate it", quantumBlockchainTestDir)
+	}
+	bt := new(testMatcher)
+
+	bt.walk(t, quantumBlockchainTestDir, func(t *testing.T, name string, test *BlockTest) {
+		execBlockTest(t, bt, test)
+	})
+}
+
 func execBlockTest(t *testing.T, bt *testMatcher, test *BlockTest) {
 	// Define all the different flag combinations we should run the tests with,
 	// picking only one for short tests.
@@ -353,6 +353,9 @@ func (t *BlockTest) validatePostState(statedb *state.StateDB) error {
 				return fmt.Errorf("account storage mismatch for addr: %s, slot: %x, want: %x, have: %x", addr, k, v, v2)
 			}
 		}
+		if register2 := statedb.GetQuantumRegister(addr); !bytes.Equal(register2, acct.QuantumRegister) {
+			return fmt.Errorf("account quantum register mismatch for addr: %s, want: %x, have: %x", addr, acct.QuantumRegister, register2)
+		}
 	}
 	return nil
 }
@@ -45,6 +45,8 @@ var (
 	executionSpecStateTestDir       = filepath.Join(".", "spec-tests", "fixtures", "state_tests")
 	executionSpecTransactionTestDir = filepath.Join(".", "spec-tests", "fixtures", "transaction_tests")
 	benchmarksDir                   = filepath.Join(".", "evm-benchmarks", "benchmarks")
+	quantumStateTestDir             = filepath.Join(".", "quantum-tests", "state_tests")
+	quantumBlockchainTestDir        = filepath.Join(".", "quantum-tests", "blockchain_tests")
 )
 
 func readJSON(reader io.Reader, value interface{}) error {
@@ -0,0 +1,523 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build none
+// +build none
+
+/*
+The mkquest tool generates the state tests of the QUANTUM instructions in
+quantum-tests/state_tests and the matching blockchain tests in
+quantum-tests/blockchain_tests. Every case is executed on the deterministic
+simulator of the Quest processor under each fork. For state tests the
+resulting state root, logs hash, gas used and post state are recorded as
+the expectations, for blockchain tests the block carrying the transaction
+and the post state after importing it.
+
+	go run mkquest.go [outdir]
+
+The fixtures must be regenerated whenever the gas table, the register
+encoding or the measurement seeding changes on purpose.
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/program"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+var (
+	secretKey    = hexutil.MustDecode("0x45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8")
+	sender       = common.HexToAddress("0xa94f5374fce5edbc8e2a8697c15331677e6ebf0b")
+	contractAddr = common.HexToAddress("0x0000000000000000000000000000000000001000")
+	coinbase     = common.HexToAddress("0x2adc25665018aa1fe0e6bc666dac8fc2697ff9ba")
+
+	// Forks every case is executed under: before the Quantum fork the QUANTUM
+	// prefix is an undefined opcode.
+	forks = []string{"Prague", "Quantum"}
+)
+
+// questCase is a single state test: a transaction calling code deployed at
+// contractAddr.
+type questCase struct {
+	file   string      // Fixture file, relative to the output directory
+	name   string      // Test name within the file
+	code   []byte      // Contract code
+	random common.Hash // PREVRANDAO of the block, the measurement seed
+}
+
+// quantum appends the QUANTUM prefix with the given instruction.
+func quantum(p *program.Program, op vm.QuantumOp) *program.Program {
+	return p.Op(vm.QUANTUM).Append([]byte{byte(op)})
+}
+
+var cases = []questCase{
+	{
+		// A fresh register is |0>: the measurement yields zero, slot 1 marks
+		// the completed execution.
+		file: "stQuantum/quantumMeasure.json",
+		name: "quantumInitMeasure",
+		code: func() []byte {
+			p := program.New().Push(1)
+			quantum(p, vm.QINIT).Push(0)
+			quantum(p, vm.QMEASURE).Push(0).Op(vm.SSTORE)
+			return p.Sstore(1, 1).Op(vm.STOP).Bytes()
+		}(),
+	},
+	{
+		file: "stQuantum/quantumMeasure.json",
+		name: "quantumPauliXMeasure",
+		code: func() []byte {
+			p := program.New().Push(1)
+			quantum(p, vm.QINIT).Push(0)
+			quantum(p, vm.QPAULIX).Push(0)
+			quantum(p, vm.QMEASURE).Push(0).Op(vm.SSTORE)
+			return p.Op(vm.STOP).Bytes()
+		}(),
+	},
+	{
+		// Both qubits of a Bell pair collapse to the same value. The seed is
+		// chosen so that it is one.
+		file: "stQuantum/quantumMeasure.json",
+		name: "quantumBellPair",
+		code: func() []byte {
+			p := program.New().Push(2)
+			quantum(p, vm.QINIT).Push(0)
+			quantum(p, vm.QHADAMARD).Push(0).Push(1)
+			quantum(p, vm.QCNOT).Push(0)
+			quantum(p, vm.QMEASURE).Push(0).Op(vm.SSTORE).Push(1)
+			quantum(p, vm.QMEASURE).Push(1).Op(vm.SSTORE)
+			return p.Op(vm.STOP).Bytes()
+		}(),
+		random: common.HexToHash("0x020004"),
+	},
+	{
+		// Search for 5 among 8 values, the result replaces the target in
+		// memory and is stored in slot 0, the result size in slot 1.
+		file: "stQuantum/quantumGrover.json",
+		name: "quantumGrover",
+		code: func() []byte {
+			p := program.New().Push(1)
+			quantum(p, vm.QINIT).Push(5).Push(0).Op(vm.MSTORE).Push(8).Push(32).Push(0)
+			quantum(p, vm.QGROVER).Push(1).Op(vm.SSTORE)
+			return p.Push(0).Op(vm.MLOAD).Push(0).Op(vm.SSTORE).Op(vm.STOP).Bytes()
+		}(),
+	},
+	{
+		file: "stQuantum/quantumFailures.json",
+		name: "quantumQubitOutOfRange",
+		code: func() []byte {
+			p := program.New().Push(2)
+			quantum(p, vm.QINIT).Push(5)
+			quantum(p, vm.QHADAMARD)
+			return p.Op(vm.STOP).Bytes()
+		}(),
+	},
+	{
+		file: "stQuantum/quantumFailures.json",
+		name: "quantumStackUnderflow",
+		code: quantum(program.New(), vm.QMEASURE).Op(vm.STOP).Bytes(),
+	},
+	{
+		file: "stQuantum/quantumFailures.json",
+		name: "quantumMaxQubits",
+		code: quantum(program.New().Push(params.QuantumGasTableQuantum.MaxQubits+1), vm.QINIT).Op(vm.STOP).Bytes(),
+	},
+	{
+		file: "stQuantum/quantumFailures.json",
+		name: "quantumNotInitialized",
+		code: quantum(program.New().Push(0), vm.QHADAMARD).Op(vm.STOP).Bytes(),
+	},
+}
+
+// fixture mirrors the JSON format of the state tests read by the tests
+// package.
+type fixture struct {
+	Env         fixtureEnv               `json:"env"`
+	Pre         types.GenesisAlloc       `json:"pre"`
+	Transaction fixtureTx                `json:"transaction"`
+	Post        map[string][]fixturePost `json:"post"`
+}
+
+type fixtureEnv struct {
+	Coinbase   common.Address        `json:"currentCoinbase"`
+	Difficulty *math.HexOrDecimal256 `json:"currentDifficulty"`
+	Random     common.Hash           `json:"currentRandom"`
+	GasLimit   math.HexOrDecimal64   `json:"currentGasLimit"`
+	Number     math.HexOrDecimal64   `json:"currentNumber"`
+	Timestamp  math.HexOrDecimal64   `json:"currentTimestamp"`
+	BaseFee    *math.HexOrDecimal256 `json:"currentBaseFee"`
+}
+
+type fixtureTx struct {
+	Data      []string              `json:"data"`
+	GasLimit  []math.HexOrDecimal64 `json:"gasLimit"`
+	GasPrice  *math.HexOrDecimal256 `json:"gasPrice"`
+	Nonce     math.HexOrDecimal64   `json:"nonce"`
+	SecretKey hexutil.Bytes         `json:"secretKey"`
+	Sender    common.Address        `json:"sender"`
+	To        common.Address        `json:"to"`
+	Value     []string              `json:"value"`
+}
+
+type fixtureIndexes struct {
+	Data  int `json:"data"`
+	Gas   int `json:"gas"`
+	Value int `json:"value"`
+}
+
+type fixturePost struct {
+	Hash    common.Hash         `json:"hash"`
+	Logs    common.Hash         `json:"logs"`
+	Indexes fixtureIndexes      `json:"indexes"`
+	GasUsed math.HexOrDecimal64 `json:"gasUsed"`
+	State   types.GenesisAlloc  `json:"state"`
+}
+
+// blockFixture mirrors the JSON format of the blockchain tests read by the
+// tests package.
+type blockFixture struct {
+	Blocks     []fixtureBlock        `json:"blocks"`
+	Genesis    fixtureHeader         `json:"genesisBlockHeader"`
+	Pre        types.GenesisAlloc    `json:"pre"`
+	Post       types.GenesisAlloc    `json:"postState"`
+	BestBlock  common.UnprefixedHash `json:"lastblockhash"`
+	Network    string                `json:"network"`
+	SealEngine string                `json:"sealEngine"`
+}
+
+type fixtureBlock struct {
+	BlockHeader *fixtureHeader `json:"blockHeader"`
+	Rlp         hexutil.Bytes  `json:"rlp"`
+}
+
+type fixtureHeader struct {
+	Bloom                 types.Bloom           `json:"bloom"`
+	Coinbase              common.Address        `json:"coinbase"`
+	MixHash               common.Hash           `json:"mixHash"`
+	Nonce                 types.BlockNonce      `json:"nonce"`
+	Number                *math.HexOrDecimal256 `json:"number"`
+	Hash                  common.Hash           `json:"hash"`
+	ParentHash            common.Hash           `json:"parentHash"`
+	ReceiptTrie           common.Hash           `json:"receiptTrie"`
+	StateRoot             common.Hash           `json:"stateRoot"`
+	TransactionsTrie      common.Hash           `json:"transactionsTrie"`
+	UncleHash             common.Hash           `json:"uncleHash"`
+	ExtraData             hexutil.Bytes         `json:"extraData"`
+	Difficulty            *math.HexOrDecimal256 `json:"difficulty"`
+	GasLimit              math.HexOrDecimal64   `json:"gasLimit"`
+	GasUsed               math.HexOrDecimal64   `json:"gasUsed"`
+	Timestamp             math.HexOrDecimal64   `json:"timestamp"`
+	BaseFeePerGas         *math.HexOrDecimal256 `json:"baseFeePerGas"`
+	WithdrawalsRoot       *common.Hash          `json:"withdrawalsRoot"`
+	BlobGasUsed           *math.HexOrDecimal64  `json:"blobGasUsed"`
+	ExcessBlobGas         *math.HexOrDecimal64  `json:"excessBlobGas"`
+	ParentBeaconBlockRoot *common.Hash          `json:"parentBeaconBlockRoot"`
+}
+
+func main() {
+	outdir := filepath.Join(".", "quantum-tests")
+	if len(os.Args) > 1 {
+		outdir = os.Args[1]
+	}
+	var (
+		states = make(map[string]map[string]interface{})
+		blocks = make(map[string]map[string]interface{})
+	)
+	for _, c := range cases {
+		f, err := generate(c)
+		if err != nil {
+			fatalf("%s: %v", c.name, err)
+		}
+		if states[c.file] == nil {
+			states[c.file] = make(map[string]interface{})
+		}
+		states[c.file][c.name] = f
+
+		for _, fork := range forks {
+			b, err := generateBlock(c, fork)
+			if err != nil {
+				fatalf("%s: %s: %v", c.name, fork, err)
+			}
+			if blocks[c.file] == nil {
+				blocks[c.file] = make(map[string]interface{})
+			}
+			blocks[c.file][c.name+"_"+fork] = b
+		}
+	}
+	write(filepath.Join(outdir, "state_tests"), states)
+	write(filepath.Join(outdir, "blockchain_tests"), blocks)
+}
+
+// write stores the fixtures grouped by file name under dir.
+func write(dir string, files map[string]map[string]interface{}) {
+	for name, fixtures := range files {
+		out, err := json.MarshalIndent(fixtures, "", "    ")
+		if err != nil {
+			fatalf("%s: %v", name, err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fatalf("%s: %v", name, err)
+		}
+		if err := os.WriteFile(path, append(out, '\n'), 0644); err != nil {
+			fatalf("%s: %v", name, err)
+		}
+		fmt.Println("wrote", path)
+	}
+}
+
+// generate builds the fixture of a case and fills in its expectations by
+// executing it under every fork.
+func generate(c questCase) (*fixture, error) {
+	random := c.random
+	if random == (common.Hash{}) {
+		random = common.HexToHash("0x020000")
+	}
+	f := &fixture{
+		Env: fixtureEnv{
+			Coinbase:   coinbase,
+			Difficulty: math.NewHexOrDecimal256(0),
+			Random:     random,
+			GasLimit:   0x05f5e100,
+			Number:     1,
+			Timestamp:  0x03e8,
+			BaseFee:    math.NewHexOrDecimal256(10),
+		},
+		Pre: types.GenesisAlloc{
+			sender:       {Balance: big.NewInt(1000000000000000000)},
+			contractAddr: {Balance: new(big.Int), Code: c.code},
+		},
+		Transaction: fixtureTx{
+			Data:      []string{"0x"},
+			GasLimit:  []math.HexOrDecimal64{1000000},
+			GasPrice:  math.NewHexOrDecimal256(10),
+			SecretKey: secretKey,
+			Sender:    sender,
+			To:        contractAddr,
+			Value:     []string{"0x00"},
+		},
+		Post: make(map[string][]fixturePost),
+	}
+	for _, fork := range forks {
+		f.Post[fork] = []fixturePost{{}}
+	}
+	// Run the fixture through the state test runner, so that the
+	// expectations are produced by the same code that verifies them
+	blob, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	var test tests.StateTest
+	if err := json.Unmarshal(blob, &test); err != nil {
+		return nil, err
+	}
+	for _, fork := range forks {
+		st, root, gasUsed, err := test.RunNoVerify(tests.StateSubtest{Fork: fork}, vm.Config{}, false, rawdb.HashScheme)
+		if err != nil {
+			st.Close()
+			return nil, fmt.Errorf("%s: %v", fork, err)
+		}
+		logs, err := rlp.EncodeToBytes(st.StateDB.Logs())
+		if err != nil {
+			st.Close()
+			return nil, err
+		}
+		statedb, err := state.New(root, st.StateDB.Database())
+		if err != nil {
+			st.Close()
+			return nil, err
+		}
+		alloc := make(collector)
+		statedb.DumpToCollector(alloc, nil)
+		st.Close()
+
+		f.Post[fork][0] = fixturePost{
+			Hash:    root,
+			Logs:    crypto.Keccak256Hash(logs),
+			GasUsed: math.HexOrDecimal64(gasUsed),
+			State:   types.GenesisAlloc(alloc),
+		}
+	}
+	return f, nil
+}
+
+// generateBlock builds the blockchain test of a case: a single block holding
+// the transaction of the state test. The block is produced by the chain
+// maker and then imported by the blockchain test runner, so that the
+// fixture is known to pass.
+func generateBlock(c questCase, fork string) (*blockFixture, error) {
+	config, ok := tests.Forks[fork]
+	if !ok {
+		return nil, tests.UnsupportedForkError{Name: fork}
+	}
+	key, err := crypto.ToECDSA(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	gspec := &core.Genesis{
+		Config:     config,
+		GasLimit:   0x05f5e100,
+		Difficulty: new(big.Int),
+		BaseFee:    big.NewInt(10),
+		Alloc: types.GenesisAlloc{
+			sender:       {Balance: big.NewInt(1000000000000000000)},
+			contractAddr: {Balance: new(big.Int), Code: c.code},
+		},
+	}
+	_, chain, _ := core.GenerateChainWithGenesis(gspec, beacon.New(ethash.NewFaker()), 1, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(coinbase)
+		b.AddTx(types.MustSignNewTx(key, b.Signer(), &types.LegacyTx{
+			To:       &contractAddr,
+			Gas:      1000000,
+			GasPrice: big.NewInt(10),
+		}))
+	})
+	block := chain[len(chain)-1]
+
+	blob, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return nil, err
+	}
+	f := &blockFixture{
+		Blocks: []fixtureBlock{{
+			BlockHeader: newFixtureHeader(block.Header()),
+			Rlp:         blob,
+		}},
+		Genesis:    *newFixtureHeader(gspec.ToBlock().Header()),
+		Pre:        gspec.Alloc,
+		BestBlock:  common.UnprefixedHash(block.Hash()),
+		Network:    fork,
+		SealEngine: "NoProof",
+	}
+	// Import the block through the blockchain test runner and take the post
+	// state from the imported chain, the chain maker keeps no preimages
+	post := make(collector)
+	if err := runBlock(f, func(chain *core.BlockChain) error {
+		statedb, err := chain.State()
+		if err != nil {
+			return err
+		}
+		statedb.DumpToCollector(post, nil)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	f.Post = types.GenesisAlloc(post)
+
+	// Check the post state as the runner will
+	if err := runBlock(f, nil); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// runBlock executes the blockchain test of the fixture and calls check with
+// the imported chain.
+func runBlock(f *blockFixture, check func(*core.BlockChain) error) error {
+	blob, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	var test tests.BlockTest
+	if err := json.Unmarshal(blob, &test); err != nil {
+		return err
+	}
+	var checkErr error
+	err = test.Run(false, rawdb.HashScheme, false, nil, func(_ error, chain *core.BlockChain) {
+		if check != nil {
+			checkErr = check(chain)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return checkErr
+}
+
+// newFixtureHeader converts a block header into its fixture form.
+func newFixtureHeader(h *types.Header) *fixtureHeader {
+	return &fixtureHeader{
+		Bloom:                 h.Bloom,
+		Coinbase:              h.Coinbase,
+		MixHash:               h.MixDigest,
+		Nonce:                 h.Nonce,
+		Number:                (*math.HexOrDecimal256)(h.Number),
+		Hash:                  h.Hash(),
+		ParentHash:            h.ParentHash,
+		ReceiptTrie:           h.ReceiptHash,
+		StateRoot:             h.Root,
+		TransactionsTrie:      h.TxHash,
+		UncleHash:             h.UncleHash,
+		ExtraData:             h.Extra,
+		Difficulty:            (*math.HexOrDecimal256)(h.Difficulty),
+		GasLimit:              math.HexOrDecimal64(h.GasLimit),
+		GasUsed:               math.HexOrDecimal64(h.GasUsed),
+		Timestamp:             math.HexOrDecimal64(h.Time),
+		BaseFeePerGas:         (*math.HexOrDecimal256)(h.BaseFee),
+		WithdrawalsRoot:       h.WithdrawalsHash,
+		BlobGasUsed:           (*math.HexOrDecimal64)(h.BlobGasUsed),
+		ExcessBlobGas:         (*math.HexOrDecimal64)(h.ExcessBlobGas),
+		ParentBeaconBlockRoot: h.ParentBeaconRoot,
+	}
+}
+
+// collector gathers the accounts of a state dump into a genesis allocation.
+type collector types.GenesisAlloc
+
+func (c collector) OnRoot(common.Hash) {}
+
+func (c collector) OnAccount(addr *common.Address, dumpAccount state.DumpAccount) {
+	if addr == nil {
+		return
+	}
+	storage := make(map[common.Hash]common.Hash)
+	for k, v := range dumpAccount.Storage {
+		storage[k] = common.HexToHash(v)
+	}
+	balance, _ := new(big.Int).SetString(dumpAccount.Balance, 0)
+	c[*addr] = types.Account{
+		Code:            dumpAccount.Code,
+		Storage:         storage,
+		Balance:         balance,
+		Nonce:           dumpAccount.Nonce,
+		QuantumRegister: dumpAccount.QuantumRegister,
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, strings.TrimSuffix(format, "\n")+"\n", args...)
+	os.Exit(1)
+}
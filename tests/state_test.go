@@ -97,6 +97,20 @@ func TestExecutionSpecState(t *testing.T) {
 	})
 }
 
+// TestQuantumState runs the fixtures of the QUANTUM instructions generated by
+// mkquest.go.
+func TestQuantumState(t *testing.T) {
+	t.Parallel()
+
+	if !common.FileExist(quantumStateTestDir) {
+		t.Fatalf("directory %s does not exist, run mkquest.go to generate it", quantumStateTestDir)
+	}
+	st := new(testMatcher)
+	st.walk(t, quantumStateTestDir, func(t *testing.T, name string, test *StateTest) {
+		execStateTest(t, st, test)
+	})
+}
+
 func execStateTest(t *testing.T, st *testMatcher, test *StateTest) {
 	for _, subtest := range test.Subtests() {
 		key := fmt.Sprintf("%s/%d", subtest.Fork, subtest.Index)
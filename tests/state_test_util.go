@@ -17,6 +17,7 @@
 package tests
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -40,6 +41,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
+	_ "github.com/ethereum/go-ethereum/quest" // registers the Quest processor
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/ethereum/go-ethereum/triedb/hashdb"
@@ -82,6 +84,10 @@ type stPostState struct {
 		Gas   int `json:"gas"`
 		Value int `json:"value"`
 	}
+	// Optional expectations checked in addition to the state root, so that a
+	// mismatch points at the offending value.
+	GasUsed *math.HexOrDecimal64 `json:"gasUsed"`
+	State   types.GenesisAlloc   `json:"state"`
 }
 
 //go:generate go run github.com/fjl/gencodec -type stEnv -field-override stEnvMarshaling -out gen_stenv.go
@@ -219,7 +225,7 @@ func (t *StateTest) checkError(subtest StateSubtest, err error) error {
 
 // Run executes a specific subtest and verifies the post-state and logs
 func (t *StateTest) Run(subtest StateSubtest, vmconfig vm.Config, snapshotter bool, scheme string, postCheck func(err error, st *StateTestState)) (result error) {
-	st, root, _, err := t.RunNoVerify(subtest, vmconfig, snapshotter, scheme)
+	st, root, gasUsed, err := t.RunNoVerify(subtest, vmconfig, snapshotter, scheme)
 	// Invoke the callback at the end of function for further analysis.
 	defer func() {
 		postCheck(result, &st)
@@ -245,7 +251,36 @@ func (t *StateTest) Run(subtest StateSubtest, vmconfig vm.Config, snapshotter bo
 	if logs := rlpHash(st.StateDB.Logs()); logs != common.Hash(post.Logs) {
 		return fmt.Errorf("post state logs hash mismatch: got %x, want %x", logs, post.Logs)
 	}
+	if post.GasUsed != nil && gasUsed != uint64(*post.GasUsed) {
+		return fmt.Errorf("gas used mismatch: got %d, want %d", gasUsed, uint64(*post.GasUsed))
+	}
 	st.StateDB, _ = state.New(root, st.StateDB.Database())
+	return checkPostState(st.StateDB, post.State)
+}
+
+// checkPostState verifies the accounts listed in the post state of a test.
+// Only the listed storage slots are compared, the quantum register of an
+// account must match exactly.
+func checkPostState(statedb *state.StateDB, accounts types.GenesisAlloc) error {
+	for addr, want := range accounts {
+		if balance := statedb.GetBalance(addr).ToBig(); want.Balance != nil && balance.Cmp(want.Balance) != 0 {
+			return fmt.Errorf("account %x: balance mismatch: got %v, want %v", addr, balance, want.Balance)
+		}
+		if nonce := statedb.GetNonce(addr); nonce != want.Nonce {
+			return fmt.Errorf("account %x: nonce mismatch: got %d, want %d", addr, nonce, want.Nonce)
+		}
+		if code := statedb.GetCode(addr); !bytes.Equal(code, want.Code) {
+			return fmt.Errorf("account %x: code mismatch: got %x, want %x", addr, code, want.Code)
+		}
+		for key, value := range want.Storage {
+			if have := statedb.GetState(addr, key); have != value {
+				return fmt.Errorf("account %x: storage %x mismatch: got %x, want %x", addr, key, have, value)
+			}
+		}
+		if register := statedb.GetQuantumRegister(addr); !bytes.Equal(register, want.QuantumRegister) {
+			return fmt.Errorf("account %x: quantum register mismatch: got %x, want %x", addr, register, want.QuantumRegister)
+		}
+	}
 	return nil
 }
 
@@ -517,6 +552,9 @@ func MakePreState(db ethdb.Database, accounts types.GenesisAlloc, snapshotter bo
 		for k, v := range a.Storage {
 			statedb.SetState(addr, k, v)
 		}
+		if len(a.QuantumRegister) > 0 {
+			statedb.SetQuantumRegister(addr, a.QuantumRegister)
+		}
 	}
 	// Commit and re-open to start with a clean state.
 	root, _ := statedb.Commit(0, false, false)